@@ -34,6 +34,12 @@ func alphaCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service)
 		vizCommand(p, dockerCli, backend),
 		publishCommand(p, dockerCli, backend),
 		generateCommand(p, backend),
+		importK8sCommand(p, backend),
+		healthCommand(p, dockerCli, backend),
+		snapshotCommand(p, dockerCli, backend),
+		benchCommand(p, dockerCli, backend),
+		dnsCheckCommand(p, dockerCli, backend),
+		chaosCommand(p, dockerCli, backend),
 	)
 	return cmd
 }
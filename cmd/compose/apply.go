@@ -0,0 +1,94 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/spf13/cobra"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+func applyCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *cobra.Command {
+	create := createOptions{}
+	build := buildOptions{ProjectOptions: p}
+	cmd := &cobra.Command{
+		Use:   "apply [OPTIONS] SERVICE",
+		Short: "Recreate a single service, without checking dependencies and project resources",
+		Args:  cli.ExactArgs(1),
+		PreRunE: AdaptCmd(func(ctx context.Context, cmd *cobra.Command, args []string) error {
+			create.pullChanged = cmd.Flags().Changed("pull")
+			create.timeChanged = cmd.Flags().Changed("timeout")
+			if create.Build && create.noBuild {
+				return fmt.Errorf("--build and --no-build are incompatible")
+			}
+			return nil
+		}),
+		RunE: Adapt(func(ctx context.Context, args []string) error {
+			return runApply(ctx, dockerCli, backend, p, create, build, args[0])
+		}),
+		ValidArgsFunction: completeServiceNames(dockerCli, p),
+	}
+	flags := cmd.Flags()
+	flags.BoolVar(&create.Build, "build", false, "Build image before starting container")
+	flags.BoolVar(&create.noBuild, "no-build", false, "Don't build an image, even if it's policy")
+	flags.StringVar(&create.Pull, "pull", "policy", `Pull image before running ("always"|"missing"|"never"|"build")`)
+	flags.BoolVar(&create.quietPull, "quiet-pull", false, "Pull without printing progress information")
+	flags.BoolVar(&create.forceRecreate, "force-recreate", false, "Recreate containers even if their configuration and image haven't changed")
+	flags.IntVarP(&create.timeout, "timeout", "t", 0, "Use this timeout in seconds for container shutdown when containers are recreated")
+	return cmd
+}
+
+func runApply(ctx context.Context, dockerCli command.Cli, backend api.Service, p *ProjectOptions, create createOptions, build buildOptions, service string) error {
+	services, err := p.resolveServiceGroups(ctx, dockerCli, []string{service})
+	if err != nil {
+		return err
+	}
+	if len(services) != 1 {
+		return fmt.Errorf("apply requires a single service, %q selects %d", service, len(services))
+	}
+	service = services[0]
+
+	project, _, err := p.ToProject(ctx, dockerCli, services)
+	if err != nil {
+		return err
+	}
+	if err := create.Apply(project); err != nil {
+		return err
+	}
+
+	var bo *api.BuildOptions
+	if !create.noBuild {
+		b, err := build.toAPIBuildOptions(services)
+		if err != nil {
+			return err
+		}
+		bo = &b
+	}
+
+	return backend.Apply(ctx, project, api.ApplyOptions{
+		Build:     bo,
+		Service:   service,
+		Recreate:  create.recreateStrategy(),
+		Timeout:   create.GetTimeout(),
+		QuietPull: create.quietPull,
+	})
+}
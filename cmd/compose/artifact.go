@@ -0,0 +1,67 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"strings"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/remote"
+	"github.com/spf13/cobra"
+)
+
+// artifactCommand groups subcommands managing published compose OCI artifacts
+func artifactCommand(dockerCli command.Cli, backend api.Service) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "artifact [COMMAND]",
+		Short: "Manage Compose OCI artifacts",
+	}
+	cmd.AddCommand(
+		artifactCopyCommand(dockerCli, backend),
+	)
+	return cmd
+}
+
+type artifactCopyOptions struct {
+	includeImages bool
+}
+
+func artifactCopyCommand(dockerCli command.Cli, backend api.Service) *cobra.Command {
+	opts := artifactCopyOptions{}
+	cmd := &cobra.Command{
+		Use:   "copy [OPTIONS] SOURCE DESTINATION",
+		Short: "Copy a published compose application to another repository",
+		RunE: Adapt(func(ctx context.Context, args []string) error {
+			return runArtifactCopy(ctx, dockerCli, backend, opts, args[0], args[1])
+		}),
+		Args: cli.ExactArgs(2),
+	}
+	cmd.Flags().BoolVar(&opts.includeImages, "include-images", false, "Also copy images referenced by services to the destination registry")
+	return cmd
+}
+
+func runArtifactCopy(ctx context.Context, _ command.Cli, backend api.Service, opts artifactCopyOptions, source, destination string) error {
+	return backend.CopyArtifact(ctx,
+		strings.TrimPrefix(source, remote.OciPrefix),
+		strings.TrimPrefix(destination, remote.OciPrefix),
+		api.CopyArtifactOptions{
+			IncludeImages: opts.includeImages,
+		})
+}
@@ -0,0 +1,115 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/dotenv"
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/compose/v2/pkg/prompt"
+	"github.com/docker/compose/v2/pkg/remote"
+	"github.com/spf13/cobra"
+)
+
+// withArtifactSetup runs setup steps of compose applications published as OCI artifacts and set by --file, before
+// project is loaded so the generated .env file applies
+func (o *ProjectOptions) withArtifactSetup(dockerCli command.Cli, answersFile *string, fn func(cmd *cobra.Command, args []string) error) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if err := o.runArtifactSetup(cmd.Context(), dockerCli, *answersFile); err != nil {
+			return err
+		}
+		return fn(cmd, args)
+	}
+}
+
+func (o *ProjectOptions) runArtifactSetup(ctx context.Context, dockerCli command.Cli, answersFile string) error {
+	var answers map[string]string
+	if answersFile != "" {
+		var err error
+		answers, err = dotenv.Read(answersFile)
+		if err != nil {
+			return fmt.Errorf("reading answers: %w", err)
+		}
+	}
+
+	var oci []string
+	for _, path := range o.ConfigPaths {
+		if strings.HasPrefix(path, remote.OciPrefix) {
+			oci = append(oci, path)
+		}
+	}
+	if len(oci) == 0 || o.Offline {
+		return nil
+	}
+
+	workingDir := o.ProjectDir
+	if workingDir == "" {
+		var err error
+		workingDir, err = os.Getwd()
+		if err != nil {
+			return err
+		}
+	}
+
+	var ask func(remote.SetupPrompt) (string, error)
+	if dockerCli.In().IsTerminal() {
+		ui := prompt.NewPrompt(dockerCli.In(), dockerCli.Out())
+		ask = func(p remote.SetupPrompt) (string, error) {
+			message := p.Message
+			if message == "" {
+				message = p.Name
+			}
+			if !p.Secret {
+				return ui.Input(message, p.Default)
+			}
+			value, err := ui.Password(message)
+			if value == "" {
+				value = p.Default
+			}
+			return value, err
+		}
+	}
+
+	for _, l := range o.remoteLoaders(dockerCli) {
+		for _, path := range oci {
+			if !l.Accept(path) {
+				continue
+			}
+			local, err := l.Load(ctx, path)
+			if err != nil {
+				return err
+			}
+			setup, err := remote.LoadSetup(filepath.Dir(local))
+			if err != nil {
+				return err
+			}
+			if setup == nil {
+				continue
+			}
+			if err := setup.Run(workingDir, types.NewMapping(os.Environ()), answers, ask); err != nil {
+				return fmt.Errorf("setting up %s: %w", path, err)
+			}
+		}
+	}
+	return nil
+}
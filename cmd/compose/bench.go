@@ -0,0 +1,348 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/errdefs"
+	"github.com/spf13/cobra"
+
+	"github.com/docker/compose/v2/cmd/formatter"
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/progress"
+)
+
+type benchOptions struct {
+	*ProjectOptions
+	iterations  int
+	pull        string
+	keepVolumes bool
+	waitTimeout int
+	format      string
+}
+
+func benchCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *cobra.Command {
+	opts := benchOptions{
+		ProjectOptions: p,
+	}
+	cmd := &cobra.Command{
+		Use:   "bench [OPTIONS] [SERVICE...]",
+		Short: "EXPERIMENTAL - Measure project startup time over repeated up and down cycles",
+		RunE: p.withServiceGroups(dockerCli, Adapt(func(ctx context.Context, args []string) error {
+			return runBench(ctx, dockerCli, backend, opts, args)
+		})),
+		ValidArgsFunction: completeServiceNames(dockerCli, p),
+	}
+	flags := cmd.Flags()
+	flags.IntVarP(&opts.iterations, "iterations", "n", 5, "Number of up and down cycles")
+	flags.StringVar(&opts.pull, "pull", "", `Pull image before running each cycle ("always"|"missing"|"never")`)
+	flags.BoolVar(&opts.keepVolumes, "keep-volumes", false, "Keep volumes between cycles")
+	flags.IntVar(&opts.waitTimeout, "wait-timeout", 0, "Maximum duration in seconds to wait for the project to be running|healthy")
+	flags.StringVar(&opts.format, "format", "table", "Format the output. Values: [table | json]")
+	return cmd
+}
+
+func runBench(ctx context.Context, dockerCli command.Cli, backend api.Service, opts benchOptions, services []string) error {
+	if opts.iterations < 1 {
+		return errors.New("--iterations must be at least 1")
+	}
+	project, _, err := opts.ToProject(ctx, dockerCli, services)
+	if err != nil {
+		return err
+	}
+	if opts.pull != "" {
+		if !api.IsValidPullPolicy(opts.pull) {
+			return fmt.Errorf("invalid --pull option %q", opts.pull)
+		}
+		for name, service := range project.Services {
+			if service.Image != "" {
+				service.PullPolicy = opts.pull
+				project.Services[name] = service
+			}
+		}
+	}
+
+	// benchmark tears down the project after each cycle, don't let it destroy a running one
+	containers, err := backend.Ps(ctx, project.Name, api.PsOptions{All: true})
+	if err != nil {
+		return err
+	}
+	if len(containers) > 0 {
+		return fmt.Errorf("project %q has existing containers, run 'docker compose down' before benchmarking it", project.Name)
+	}
+	if !opts.keepVolumes {
+		// volumes are removed after each cycle, don't let it destroy data of the project
+		volumes, err := existingProjectVolumes(ctx, dockerCli, project)
+		if err != nil {
+			return err
+		}
+		if len(volumes) > 0 {
+			return fmt.Errorf("project %q has existing volumes %s, remove them or use --keep-volumes before benchmarking it",
+				project.Name, strings.Join(volumes, ", "))
+		}
+	}
+
+	build, err := buildOptions{ProjectOptions: opts.ProjectOptions}.toAPIBuildOptions(nil)
+	if err != nil {
+		return err
+	}
+
+	recorder := newBenchRecorder(project)
+	for i := 1; i <= opts.iterations; i++ {
+		run := recorder.newRun()
+		start := time.Now()
+		err := backend.Up(progress.WithEventObserver(ctx, run.observe), project, api.UpOptions{
+			Create: api.CreateOptions{
+				Build:                &build,
+				Services:             services,
+				Recreate:             api.RecreateForce,
+				RecreateDependencies: api.RecreateForce,
+				QuietPull:            true,
+			},
+			Start: api.StartOptions{
+				Project:     project,
+				Services:    services,
+				Wait:        true,
+				WaitTimeout: time.Duration(opts.waitTimeout) * time.Second,
+			},
+		})
+		run.total = time.Since(start)
+		downErr := backend.Down(ctx, project.Name, api.DownOptions{
+			Project:       project,
+			RemoveOrphans: true,
+			Volumes:       !opts.keepVolumes,
+		})
+		if err != nil {
+			return fmt.Errorf("cycle %d: %w", i, err)
+		}
+		if downErr != nil {
+			return fmt.Errorf("cycle %d: %w", i, downErr)
+		}
+		_, _ = fmt.Fprintf(dockerCli.Err(), "Cycle %d/%d: %s\n", i, opts.iterations, run.total.Round(time.Millisecond))
+	}
+
+	results := recorder.results()
+	return formatter.Print(results, opts.format, dockerCli.Out(), func(w io.Writer) {
+		for _, r := range results {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", r.Service, r.Phase, r.Samples,
+				r.P50.Round(time.Millisecond), r.P95.Round(time.Millisecond))
+		}
+	}, "SERVICE", "PHASE", "SAMPLES", "P50", "P95")
+}
+
+// existingProjectVolumes lists volumes labeled for project, or declared by project and removed by down, which already
+// exist on the engine
+func existingProjectVolumes(ctx context.Context, dockerCli command.Cli, project *types.Project) ([]string, error) {
+	list, err := dockerCli.Client().VolumeList(ctx, volume.ListOptions{
+		Filters: filters.NewArgs(filters.Arg("label", fmt.Sprintf("%s=%s", api.ProjectLabel, project.Name))),
+	})
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, v := range list.Volumes {
+		names = append(names, v.Name)
+	}
+	for _, v := range project.Volumes {
+		if bool(v.External) || slices.Contains(names, v.Name) {
+			continue
+		}
+		_, err := dockerCli.Client().VolumeInspect(ctx, v.Name)
+		if errdefs.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, v.Name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// benchPhases lists phases in the order they happen, with the progress status starting and completing each one
+var benchPhases = []struct {
+	name       string
+	start, end string
+}{
+	{name: "pull", start: "Pulling", end: "Pulled"},
+	{name: "create", start: "Creating", end: "Created"},
+	{name: "start", start: "Starting", end: "Started"},
+	// health checks start running once the container has started
+	{name: "healthy", start: "Started", end: "Healthy"},
+}
+
+type benchResult struct {
+	Service string
+	Phase   string
+	Samples int
+	P50     time.Duration
+	P95     time.Duration
+}
+
+// benchRecorder collects phase timings from progress events over multiple cycles
+type benchRecorder struct {
+	project *types.Project
+	now     func() time.Time
+	runs    []*benchRun
+}
+
+type benchSpan struct {
+	start, end time.Time
+}
+
+type benchRun struct {
+	recorder *benchRecorder
+	mu       sync.Mutex
+	spans    map[string]map[string]benchSpan
+	total    time.Duration
+}
+
+func newBenchRecorder(project *types.Project) *benchRecorder {
+	return &benchRecorder{
+		project: project,
+		now:     time.Now,
+	}
+}
+
+func (r *benchRecorder) newRun() *benchRun {
+	run := &benchRun{
+		recorder: r,
+		spans:    map[string]map[string]benchSpan{},
+	}
+	r.runs = append(r.runs, run)
+	return run
+}
+
+// serviceFor resolves the service a progress event is about, either an image pull (using the service name) or a
+// container
+func (r *benchRecorder) serviceFor(id string) (string, bool) {
+	if _, ok := r.project.Services[id]; ok {
+		return id, true
+	}
+	name, ok := strings.CutPrefix(id, "Container ")
+	if !ok {
+		return "", false
+	}
+	for _, service := range r.project.Services {
+		if service.ContainerName != "" {
+			if service.ContainerName == name {
+				return service.Name, true
+			}
+			continue
+		}
+		number, ok := strings.CutPrefix(name, r.project.Name+api.Separator+service.Name+api.Separator)
+		if _, err := strconv.Atoi(number); ok && err == nil {
+			return service.Name, true
+		}
+	}
+	return "", false
+}
+
+func (run *benchRun) observe(e progress.Event) {
+	service, ok := run.recorder.serviceFor(e.ID)
+	if !ok {
+		return
+	}
+	status := e.StatusText
+	if status == "" {
+		status = e.Text
+	}
+	now := run.recorder.now()
+
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	phases, ok := run.spans[service]
+	if !ok {
+		phases = map[string]benchSpan{}
+		run.spans[service] = phases
+	}
+	// with multiple replicas, a phase spans from the first container entering it to the last one completing it
+	for _, phase := range benchPhases {
+		span := phases[phase.name]
+		if status == phase.start && (span.start.IsZero() || now.Before(span.start)) {
+			span.start = now
+		}
+		if status == phase.end && now.After(span.end) {
+			span.end = now
+		}
+		phases[phase.name] = span
+	}
+}
+
+func (r *benchRecorder) results() []benchResult {
+	var results []benchResult
+	totals := make([]time.Duration, 0, len(r.runs))
+	for _, run := range r.runs {
+		totals = append(totals, run.total)
+	}
+	results = append(results, newBenchResult(r.project.Name, "total", totals))
+
+	services := r.project.ServiceNames()
+	sort.Strings(services)
+	for _, service := range services {
+		for _, phase := range benchPhases {
+			var samples []time.Duration
+			for _, run := range r.runs {
+				span := run.spans[service][phase.name]
+				if span.start.IsZero() || span.end.Before(span.start) {
+					continue
+				}
+				samples = append(samples, span.end.Sub(span.start))
+			}
+			if len(samples) > 0 {
+				results = append(results, newBenchResult(service, phase.name, samples))
+			}
+		}
+	}
+	return results
+}
+
+func newBenchResult(service, phase string, samples []time.Duration) benchResult {
+	slices.Sort(samples)
+	return benchResult{
+		Service: service,
+		Phase:   phase,
+		Samples: len(samples),
+		P50:     percentile(samples, 50),
+		P95:     percentile(samples, 95),
+	}
+}
+
+// percentile uses the nearest-rank method on sorted samples
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank-1, 0)]
+}
@@ -0,0 +1,113 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/errdefs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/docker/compose/v2/pkg/mocks"
+	"github.com/docker/compose/v2/pkg/progress"
+)
+
+func TestBenchRecorder(t *testing.T) {
+	project := &types.Project{
+		Name: "bench",
+		Services: types.Services{
+			"web":     {Name: "web", Image: "nginx"},
+			"web-api": {Name: "web-api"},
+			"db":      {Name: "db", ContainerName: "database"},
+		},
+	}
+	recorder := newBenchRecorder(project)
+	clock := time.Unix(0, 0)
+	recorder.now = func() time.Time { return clock }
+	at := func(run *benchRun, offset time.Duration, e progress.Event) {
+		clock = time.Unix(0, 0).Add(offset)
+		run.observe(e)
+	}
+
+	for i := 1; i <= 3; i++ {
+		step := time.Duration(i) * time.Second
+		run := recorder.newRun()
+		at(run, 0, progress.Event{ID: "web", Text: "Pulling"})
+		at(run, step, progress.Event{ID: "web", Text: "Pulled"})
+		at(run, step, progress.CreatingEvent("Container bench-web-1"))
+		at(run, step, progress.CreatingEvent("Container bench-web-2"))
+		at(run, 2*step, progress.CreatedEvent("Container bench-web-1"))
+		at(run, 3*step, progress.CreatedEvent("Container bench-web-2"))
+		at(run, 3*step, progress.StartingEvent("Container bench-web-api-1"))
+		at(run, 4*step, progress.StartedEvent("Container bench-web-api-1"))
+		at(run, 4*step, progress.Healthy("Container bench-web-api-1"))
+		at(run, 0, progress.StartingEvent("Container database"))
+		at(run, step, progress.StartedEvent("Container database"))
+		at(run, 5*step, progress.Healthy("Container database"))
+		at(run, 0, progress.CreatingEvent("Network bench_default"))
+		run.total = 5 * step
+	}
+
+	assert.Equal(t, []benchResult{
+		{Service: "bench", Phase: "total", Samples: 3, P50: 10 * time.Second, P95: 15 * time.Second},
+		{Service: "db", Phase: "start", Samples: 3, P50: 2 * time.Second, P95: 3 * time.Second},
+		{Service: "db", Phase: "healthy", Samples: 3, P50: 8 * time.Second, P95: 12 * time.Second},
+		{Service: "web", Phase: "pull", Samples: 3, P50: 2 * time.Second, P95: 3 * time.Second},
+		{Service: "web", Phase: "create", Samples: 3, P50: 4 * time.Second, P95: 6 * time.Second},
+		{Service: "web-api", Phase: "start", Samples: 3, P50: 2 * time.Second, P95: 3 * time.Second},
+		{Service: "web-api", Phase: "healthy", Samples: 3, P50: 0, P95: 0},
+	}, recorder.results())
+}
+
+func TestPercentile(t *testing.T) {
+	samples := []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	assert.Equal(t, time.Duration(5), percentile(samples, 50))
+	assert.Equal(t, time.Duration(10), percentile(samples, 95))
+	assert.Equal(t, time.Duration(0), percentile(nil, 50))
+}
+
+func TestExistingProjectVolumes(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	apiClient := mocks.NewMockAPIClient(mockCtrl)
+	cli := mocks.NewMockCli(mockCtrl)
+	cli.EXPECT().Client().Return(apiClient).AnyTimes()
+
+	project := &types.Project{
+		Name: "bench",
+		Volumes: types.Volumes{
+			"data":   {Name: "bench_data"},
+			"cache":  {Name: "shared_cache"},
+			"logs":   {Name: "bench_logs"},
+			"extern": {Name: "extern", External: true},
+		},
+	}
+	apiClient.EXPECT().VolumeList(gomock.Any(), gomock.Any()).Return(volume.ListResponse{
+		Volumes: []*volume.Volume{{Name: "bench_data"}},
+	}, nil)
+	apiClient.EXPECT().VolumeInspect(gomock.Any(), "shared_cache").Return(volume.Volume{Name: "shared_cache"}, nil)
+	apiClient.EXPECT().VolumeInspect(gomock.Any(), "bench_logs").Return(volume.Volume{}, errdefs.NotFound(assert.AnError))
+
+	volumes, err := existingProjectVolumes(context.Background(), cli, project)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bench_data", "shared_cache"}, volumes)
+}
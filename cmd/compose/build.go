@@ -18,6 +18,7 @@ package compose
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -46,6 +47,9 @@ type buildOptions struct {
 	deps    bool
 	print   bool
 	check   bool
+
+	changedSince  string
+	pullUnchanged bool
 }
 
 func (opts buildOptions) toAPIBuildOptions(services []string) (api.BuildOptions, error) {
@@ -104,7 +108,7 @@ func buildCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service)
 			}
 			return nil
 		}),
-		RunE: AdaptCmd(func(ctx context.Context, cmd *cobra.Command, args []string) error {
+		RunE: p.withServiceGroups(dockerCli, AdaptCmd(func(ctx context.Context, cmd *cobra.Command, args []string) error {
 			if cmd.Flags().Changed("ssh") && opts.ssh == "" {
 				opts.ssh = "default"
 			}
@@ -112,7 +116,7 @@ func buildCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service)
 				fmt.Fprint(os.Stderr, "--progress is a global compose flag, better use `docker compose --progress xx build ...\n")
 			}
 			return runBuild(ctx, dockerCli, backend, opts, args)
-		}),
+		})),
 		ValidArgsFunction: completeServiceNames(dockerCli, p),
 	}
 	flags := cmd.Flags()
@@ -138,11 +142,16 @@ func buildCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service)
 	flags.MarkHidden("progress") //nolint:errcheck
 	flags.BoolVar(&opts.print, "print", false, "Print equivalent bake file")
 	flags.BoolVar(&opts.check, "check", false, "Check build configuration")
+	flags.StringVar(&opts.changedSince, "services-changed-since", "", "Only build services whose build context, Dockerfile or additional contexts changed since a git revision")
+	flags.BoolVar(&opts.pullUnchanged, "pull-unchanged", false, "Pull images of services not built with --services-changed-since")
 
 	return cmd
 }
 
 func runBuild(ctx context.Context, dockerCli command.Cli, backend api.Service, opts buildOptions, services []string) error {
+	if opts.pullUnchanged && opts.changedSince == "" {
+		return errors.New("--pull-unchanged requires --services-changed-since")
+	}
 	project, _, err := opts.ToProject(ctx, dockerCli, nil, cli.WithResolvedPaths(true), cli.WithoutEnvironmentResolution)
 	if err != nil {
 		return err
@@ -152,6 +161,27 @@ func runBuild(ctx context.Context, dockerCli command.Cli, backend api.Service, o
 		return err
 	}
 
+	if opts.changedSince != "" {
+		var unchanged []string
+		services, unchanged, err = selectChangedServices(ctx, project, services, opts.changedSince)
+		if err != nil {
+			return err
+		}
+		if opts.pullUnchanged && len(unchanged) > 0 {
+			pulled, err := project.WithSelectedServices(unchanged)
+			if err != nil {
+				return err
+			}
+			if err := backend.Pull(ctx, pulled, api.PullOptions{Quiet: opts.quiet}); err != nil {
+				return err
+			}
+		}
+		if len(services) == 0 {
+			_, _ = fmt.Fprintf(dockerCli.Err(), "No service build changed since %s\n", opts.changedSince)
+			return nil
+		}
+	}
+
 	apiBuildOptions, err := opts.toAPIBuildOptions(services)
 	if err != nil {
 		return err
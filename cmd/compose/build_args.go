@@ -0,0 +1,166 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"fmt"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// commonBuildArgsExtension declares named build args sets, services select by name in their build section
+const commonBuildArgsExtension = "x-build-args-common"
+
+// applyCommonBuildArgs merges build args sets declared by `x-build-args-common` into the build section of services
+// selecting those by name, in order. Args explicitly set by service take precedence
+func applyCommonBuildArgs(project *types.Project) error {
+	sets, err := commonBuildArgSets(project.Extensions)
+	if err != nil {
+		return err
+	}
+	for name, service := range project.Services {
+		if service.Build == nil {
+			continue
+		}
+		args, ok, err := selectedBuildArgs(name, service.Build.Extensions, sets)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		service.Build.Args = args.OverrideBy(service.Build.Args)
+		project.Services[name] = service
+	}
+	return nil
+}
+
+// applyModelCommonBuildArgs merges build args sets declared by `x-build-args-common` into the build section of
+// services in model, as applyCommonBuildArgs does for a project
+func applyModelCommonBuildArgs(model map[string]any) error {
+	sets, err := commonBuildArgSets(model)
+	if err != nil {
+		return err
+	}
+	services, _ := model["services"].(map[string]any)
+	for name, s := range services {
+		service, _ := s.(map[string]any)
+		build, ok := service["build"].(map[string]any)
+		if !ok {
+			continue
+		}
+		args, ok, err := selectedBuildArgs(name, build, sets)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if declared, ok := build["args"]; ok {
+			explicit, err := toBuildArgs(declared)
+			if err != nil {
+				return fmt.Errorf("service %q: build.args %w", name, err)
+			}
+			args.OverrideBy(explicit)
+		}
+		merged := map[string]any{}
+		for key, value := range args {
+			if value == nil {
+				merged[key] = nil
+				continue
+			}
+			merged[key] = *value
+		}
+		build["args"] = merged
+	}
+	return nil
+}
+
+// commonBuildArgSets returns the build args sets declared by the `x-build-args-common` extension, by name
+func commonBuildArgSets(extensions map[string]any) (map[string]types.MappingWithEquals, error) {
+	sets := map[string]types.MappingWithEquals{}
+	v, ok := extensions[commonBuildArgsExtension]
+	if !ok {
+		return sets, nil
+	}
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s must be a mapping", commonBuildArgsExtension)
+	}
+	for name, args := range m {
+		set, err := toBuildArgs(args)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s %w", commonBuildArgsExtension, name, err)
+		}
+		sets[name] = set
+	}
+	return sets, nil
+}
+
+// selectedBuildArgs merges, in order, the build args sets service selects by `x-build-args-common` in its build
+// section extensions. It returns false when service doesn't select any
+func selectedBuildArgs(service string, extensions map[string]any, sets map[string]types.MappingWithEquals) (types.MappingWithEquals, bool, error) {
+	v, ok := extensions[commonBuildArgsExtension]
+	if !ok {
+		return nil, false, nil
+	}
+	var selected []string
+	switch v := v.(type) {
+	case string:
+		selected = []string{v}
+	case []any:
+		for _, s := range v {
+			selected = append(selected, fmt.Sprint(s))
+		}
+	default:
+		return nil, false, fmt.Errorf("service %q: build.%s must be a name or a list of names", service, commonBuildArgsExtension)
+	}
+	args := types.MappingWithEquals{}
+	for _, set := range selected {
+		common, ok := sets[set]
+		if !ok {
+			return nil, false, fmt.Errorf("service %q: undefined build args set %q", service, set)
+		}
+		args.OverrideBy(common)
+	}
+	return args, true, nil
+}
+
+// toBuildArgs converts a build args set, declared as a mapping or a list of KEY=VALUE, to MappingWithEquals
+func toBuildArgs(v any) (types.MappingWithEquals, error) {
+	switch v := v.(type) {
+	case map[string]any:
+		args := types.MappingWithEquals{}
+		for key, value := range v {
+			if value == nil {
+				args[key] = nil
+				continue
+			}
+			s := fmt.Sprint(value)
+			args[key] = &s
+		}
+		return args, nil
+	case []any:
+		var values []string
+		for _, value := range v {
+			values = append(values, fmt.Sprint(value))
+		}
+		return types.NewMappingWithEquals(values), nil
+	default:
+		return nil, fmt.Errorf("must be a mapping or a list")
+	}
+}
@@ -0,0 +1,94 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+)
+
+func TestApplyCommonBuildArgs(t *testing.T) {
+	goVersion := "1.22"
+	p := &types.Project{
+		Extensions: types.Extensions{
+			commonBuildArgsExtension: map[string]any{
+				"go":    map[string]any{"GO_VERSION": goVersion, "CGO_ENABLED": 0},
+				"proxy": []any{"HTTP_PROXY"},
+			},
+		},
+		Services: types.Services{
+			"api": {
+				Name: "api",
+				Build: &types.BuildConfig{
+					Args:       types.NewMappingWithEquals([]string{"CGO_ENABLED=1"}),
+					Extensions: types.Extensions{commonBuildArgsExtension: []any{"go", "proxy"}},
+				},
+			},
+			"worker": {
+				Name: "worker",
+				Build: &types.BuildConfig{
+					Extensions: types.Extensions{commonBuildArgsExtension: "go"},
+				},
+			},
+			"db": {
+				Name:  "db",
+				Image: "postgres",
+			},
+		},
+	}
+	assert.NilError(t, applyCommonBuildArgs(p))
+	assert.DeepEqual(t, p.Services["api"].Build.Args, types.NewMappingWithEquals([]string{"GO_VERSION=1.22", "CGO_ENABLED=1", "HTTP_PROXY"}))
+	assert.DeepEqual(t, p.Services["worker"].Build.Args, types.NewMappingWithEquals([]string{"GO_VERSION=1.22", "CGO_ENABLED=0"}))
+
+	p.Services["db"] = types.ServiceConfig{
+		Name:  "db",
+		Build: &types.BuildConfig{Extensions: types.Extensions{commonBuildArgsExtension: "missing"}},
+	}
+	assert.ErrorContains(t, applyCommonBuildArgs(p), `undefined build args set "missing"`)
+}
+
+func TestApplyModelCommonBuildArgs(t *testing.T) {
+	model := map[string]any{
+		commonBuildArgsExtension: map[string]any{
+			"go": map[string]any{"GO_VERSION": "1.22", "CGO_ENABLED": "0"},
+		},
+		"services": map[string]any{
+			"api": map[string]any{
+				"build": map[string]any{
+					"context":                "api",
+					"args":                   map[string]any{"CGO_ENABLED": "1", "HTTP_PROXY": nil},
+					commonBuildArgsExtension: "go",
+				},
+			},
+			"web": map[string]any{
+				"build": map[string]any{"context": "web"},
+			},
+			"db": map[string]any{"image": "postgres"},
+		},
+	}
+	assert.NilError(t, applyModelCommonBuildArgs(model))
+	services := model["services"].(map[string]any)
+	assert.DeepEqual(t, services["api"].(map[string]any)["build"].(map[string]any)["args"], map[string]any{
+		"GO_VERSION":  "1.22",
+		"CGO_ENABLED": "1",
+		"HTTP_PROXY":  nil,
+	})
+	_, ok := services["web"].(map[string]any)["build"].(map[string]any)["args"]
+	assert.Check(t, !ok)
+}
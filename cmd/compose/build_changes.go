@@ -0,0 +1,157 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/docker/builder/remotecontext/urlutil"
+)
+
+// buildInputs returns the local paths a service build depends on: the build context, the Dockerfile and additional
+// contexts. remote is set if the build depends on remote content, which can't be compared to a git revision
+func buildInputs(build *types.BuildConfig) (paths []string, remote bool) {
+	if urlutil.IsGitURL(build.Context) || urlutil.IsURL(build.Context) {
+		return nil, true
+	}
+	paths = append(paths, build.Context)
+	if build.Dockerfile != "" && build.DockerfileInline == "" {
+		dockerfile := build.Dockerfile
+		if !filepath.IsAbs(dockerfile) {
+			dockerfile = filepath.Join(build.Context, dockerfile)
+		}
+		paths = append(paths, dockerfile)
+	}
+	for _, name := range slices.Sorted(maps.Keys(build.AdditionalContexts)) {
+		// other additional contexts designate images, stages or remote content
+		if c := build.AdditionalContexts[name]; filepath.IsAbs(c) {
+			paths = append(paths, c)
+		}
+	}
+	return paths, remote
+}
+
+// servicesChanged selects the services among names whose build depends on one of the changed files
+func servicesChanged(project *types.Project, names []string, changed []string) []string {
+	var selected []string
+	for _, name := range names {
+		service := project.Services[name]
+		if service.Build == nil {
+			continue
+		}
+		paths, remote := buildInputs(service.Build)
+		for i, path := range paths {
+			// git reports paths of the working tree with symlinks resolved
+			if real, err := filepath.EvalSymlinks(path); err == nil {
+				paths[i] = real
+			}
+		}
+		if remote || slices.ContainsFunc(changed, func(file string) bool {
+			return slices.ContainsFunc(paths, func(path string) bool {
+				return file == path || strings.HasPrefix(file, path+string(filepath.Separator))
+			})
+		}) {
+			selected = append(selected, name)
+		}
+	}
+	return selected
+}
+
+// gitChangedFiles lists files changed since revision in the git working trees the build of services depend on,
+// including changes not committed yet and untracked files, as absolute paths
+func gitChangedFiles(ctx context.Context, project *types.Project, names []string, revision string) ([]string, error) {
+	git := func(dir string, args ...string) ([]string, error) {
+		cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+		out, err := cmd.Output()
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("git %s: %s", strings.Join(args, " "), strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		if err != nil {
+			return nil, err
+		}
+		var lines []string
+		for _, line := range strings.Split(string(out), "\n") {
+			if line != "" {
+				lines = append(lines, line)
+			}
+		}
+		return lines, nil
+	}
+	var changed []string
+	trees := map[string]bool{}
+	for _, name := range names {
+		service := project.Services[name]
+		if service.Build == nil {
+			continue
+		}
+		paths, _ := buildInputs(service.Build)
+		for _, path := range paths {
+			dir := path
+			if path != service.Build.Context {
+				dir = filepath.Dir(path)
+			}
+			top, err := git(dir, "rev-parse", "--show-toplevel")
+			if err != nil {
+				return nil, fmt.Errorf("service %q builds from %s, which can't be compared to %s: %w", name, path, revision, err)
+			}
+			if len(top) != 1 || trees[top[0]] {
+				continue
+			}
+			trees[top[0]] = true
+			diff, err := git(top[0], "diff", "--name-only", revision, "--")
+			if err != nil {
+				return nil, err
+			}
+			untracked, err := git(top[0], "ls-files", "--others", "--exclude-standard")
+			if err != nil {
+				return nil, err
+			}
+			for _, file := range append(diff, untracked...) {
+				changed = append(changed, filepath.Join(top[0], filepath.FromSlash(file)))
+			}
+		}
+	}
+	return changed, nil
+}
+
+// selectChangedServices splits services with a build section, or all of them if none is selected, between the ones
+// whose build changed since revision and the others
+func selectChangedServices(ctx context.Context, project *types.Project, services []string, revision string) (changed, unchanged []string, err error) {
+	if len(services) == 0 {
+		services = project.ServiceNames()
+	}
+	files, err := gitChangedFiles(ctx, project, services, revision)
+	if err != nil {
+		return nil, nil, err
+	}
+	changed = servicesChanged(project, services, files)
+	for _, name := range services {
+		if project.Services[name].Build != nil && !slices.Contains(changed, name) {
+			unchanged = append(unchanged, name)
+		}
+	}
+	return changed, unchanged, nil
+}
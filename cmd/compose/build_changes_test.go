@@ -0,0 +1,47 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServicesChanged(t *testing.T) {
+	project := &types.Project{
+		Services: types.Services{
+			"api": {Name: "api", Build: &types.BuildConfig{Context: "/repo/api", Dockerfile: "Dockerfile"}},
+			"web": {Name: "web", Build: &types.BuildConfig{Context: "/repo/web", Dockerfile: "/repo/docker/web.Dockerfile"}},
+			"worker": {Name: "worker", Build: &types.BuildConfig{
+				Context:            "/repo/worker",
+				Dockerfile:         "Dockerfile",
+				AdditionalContexts: types.Mapping{"shared": "/repo/shared", "base": "docker-image://alpine"},
+			}},
+			"remote": {Name: "remote", Build: &types.BuildConfig{Context: "https://github.com/acme/app.git#main"}},
+			"db":     {Name: "db", Image: "postgres"},
+		},
+	}
+	names := []string{"api", "db", "remote", "web", "worker"}
+
+	assert.Equal(t, []string{"remote"}, servicesChanged(project, names, nil))
+	assert.Equal(t, []string{"api", "remote"}, servicesChanged(project, names, []string{"/repo/api/main.go", "/repo/apis/main.go"}))
+	assert.Equal(t, []string{"remote", "web"}, servicesChanged(project, names, []string{"/repo/docker/web.Dockerfile"}))
+	assert.Equal(t, []string{"remote", "worker"}, servicesChanged(project, names, []string{"/repo/shared/lib.go"}))
+	assert.Equal(t, []string{"api"}, servicesChanged(project, []string{"api", "web"}, []string{"/repo/api/Dockerfile"}))
+}
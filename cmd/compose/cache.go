@@ -0,0 +1,187 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/go-units"
+	"github.com/spf13/cobra"
+
+	"github.com/docker/compose/v2/cmd/formatter"
+	"github.com/docker/compose/v2/pkg/prompt"
+	"github.com/docker/compose/v2/pkg/remote"
+)
+
+// cacheCommand groups subcommands managing the local cache of remote resources
+func cacheCommand(dockerCli command.Cli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache [COMMAND]",
+		Short: "Manage the cache of remote compose resources",
+	}
+	cmd.AddCommand(
+		cacheListCommand(dockerCli),
+		cacheRemoveCommand(dockerCli),
+		cachePruneCommand(dockerCli),
+	)
+	return cmd
+}
+
+type cacheListOptions struct {
+	format string
+	quiet  bool
+}
+
+func cacheListCommand(dockerCli command.Cli) *cobra.Command {
+	opts := cacheListOptions{}
+	cmd := &cobra.Command{
+		Use:     "ls [OPTIONS]",
+		Aliases: []string{"list"},
+		Short:   "List remote resources stored in the cache",
+		Args:    cobra.NoArgs,
+		RunE: Adapt(func(ctx context.Context, args []string) error {
+			return runCacheList(ctx, dockerCli, opts)
+		}),
+	}
+	cmd.Flags().StringVar(&opts.format, "format", "table", "Format the output. Values: [table | json]")
+	cmd.Flags().BoolVarP(&opts.quiet, "quiet", "q", false, "Only display names")
+	return cmd
+}
+
+func runCacheList(_ context.Context, dockerCli command.Cli, opts cacheListOptions) error {
+	entries, err := remote.ListCache()
+	if err != nil {
+		return err
+	}
+	if opts.quiet {
+		for _, entry := range entries {
+			_, _ = fmt.Fprintln(dockerCli.Out(), entry.Name)
+		}
+		return nil
+	}
+	return formatter.Print(entries, opts.format, dockerCli.Out(), func(w io.Writer) {
+		for _, entry := range entries {
+			name := entry.Name
+			if len(name) > 12 {
+				name = name[:12]
+			}
+			source := entry.Source
+			if source == "" {
+				source = "<unknown>"
+			}
+			age := units.HumanDuration(time.Since(entry.Created)) + " ago"
+			used := units.HumanDuration(time.Since(entry.LastUsed)) + " ago"
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", name, entry.Type, source, units.HumanSizeWithPrecision(float64(entry.Size), 3), age, used)
+		}
+	}, "NAME", "TYPE", "SOURCE", "SIZE", "CREATED", "LAST USED")
+}
+
+func cacheRemoveCommand(dockerCli command.Cli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rm REF...",
+		Short: "Remove remote resources from the cache",
+		Long:  "Remove remote resources from the cache, designated by source reference or by name prefix",
+		Args:  cli.RequiresMinArgs(1),
+		RunE: Adapt(func(ctx context.Context, args []string) error {
+			return runCacheRemove(ctx, dockerCli, args)
+		}),
+	}
+	return cmd
+}
+
+func runCacheRemove(_ context.Context, dockerCli command.Cli, refs []string) error {
+	entries, err := remote.ListCache()
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		found, err := remote.FindCache(entries, ref)
+		if err != nil {
+			return err
+		}
+		if len(found) == 0 {
+			return fmt.Errorf("no cached resource matches %q", ref)
+		}
+		for _, entry := range found {
+			if err := remote.RemoveCache(entry); err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintln(dockerCli.Out(), entry.Name)
+		}
+	}
+	return nil
+}
+
+type cachePruneOptions struct {
+	force     bool
+	olderThan time.Duration
+}
+
+func cachePruneCommand(dockerCli command.Cli) *cobra.Command {
+	opts := cachePruneOptions{}
+	cmd := &cobra.Command{
+		Use:   "prune [OPTIONS]",
+		Short: "Remove all remote resources from the cache",
+		Args:  cobra.NoArgs,
+		RunE: Adapt(func(ctx context.Context, args []string) error {
+			return runCachePrune(ctx, dockerCli, opts)
+		}),
+	}
+	cmd.Flags().BoolVarP(&opts.force, "force", "f", false, "Do not prompt for confirmation")
+	cmd.Flags().DurationVar(&opts.olderThan, "older-than", 0, "Only remove resources cached for longer than this duration (e.g. 720h)")
+	return cmd
+}
+
+func runCachePrune(_ context.Context, dockerCli command.Cli, opts cachePruneOptions) error {
+	entries, err := remote.ListCache()
+	if err != nil {
+		return err
+	}
+	var prune []remote.CacheEntry
+	for _, entry := range entries {
+		if opts.olderThan == 0 || time.Since(entry.Created) > opts.olderThan {
+			prune = append(prune, entry)
+		}
+	}
+	if len(prune) == 0 {
+		return nil
+	}
+	if !opts.force {
+		msg := fmt.Sprintf("This will remove %d cached remote resources. Are you sure you want to continue? [y/N]: ", len(prune))
+		confirmed, err := prompt.NewPrompt(dockerCli.In(), dockerCli.Out()).Confirm(msg, false)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			return fmt.Errorf("operation cancelled by user")
+		}
+	}
+	var reclaimed int64
+	for _, entry := range prune {
+		if err := remote.RemoveCache(entry); err != nil {
+			return err
+		}
+		reclaimed += entry.Size
+	}
+	_, _ = fmt.Fprintf(dockerCli.Out(), "Total reclaimed space: %s\n", units.HumanSize(float64(reclaimed)))
+	return nil
+}
@@ -0,0 +1,175 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+type chaosOptions struct {
+	*ProjectOptions
+	image string
+}
+
+func chaosCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *cobra.Command {
+	opts := chaosOptions{
+		ProjectOptions: p,
+	}
+	cmd := &cobra.Command{
+		Use:   "chaos [COMMAND]",
+		Short: "EXPERIMENTAL - Inject faults into project containers",
+	}
+	cmd.PersistentFlags().StringVar(&opts.image, "image", "", "Image used to partition services network, must provide iptables")
+
+	var signal string
+	kill := &cobra.Command{
+		Use:   "kill [OPTIONS] SERVICE",
+		Short: "Kill a random running container of a service",
+		Args:  cli.ExactArgs(1),
+		RunE: Adapt(func(ctx context.Context, args []string) error {
+			return runChaos(ctx, dockerCli, backend, opts, api.ChaosStep{Action: api.ChaosKill, Services: args, Signal: signal})
+		}),
+		ValidArgsFunction: completeServiceNames(dockerCli, p),
+	}
+	kill.Flags().StringVarP(&signal, "signal", "s", "SIGKILL", "SIGNAL to send to the container")
+
+	var pauseDuration time.Duration
+	pause := &cobra.Command{
+		Use:   "pause [OPTIONS] SERVICE",
+		Short: "Pause the containers of a service for a duration",
+		Args:  cli.ExactArgs(1),
+		RunE: Adapt(func(ctx context.Context, args []string) error {
+			return runChaos(ctx, dockerCli, backend, opts, api.ChaosStep{Action: api.ChaosPause, Services: args, Duration: pauseDuration})
+		}),
+		ValidArgsFunction: completeServiceNames(dockerCli, p),
+	}
+	pause.Flags().DurationVarP(&pauseDuration, "duration", "d", 10*time.Second, "Time containers are paused for")
+
+	var partitionDuration time.Duration
+	partition := &cobra.Command{
+		Use:   "partition [OPTIONS] SERVICE SERVICE",
+		Short: "Drop network traffic between two services for a duration",
+		Args:  cli.ExactArgs(2),
+		RunE: Adapt(func(ctx context.Context, args []string) error {
+			return runChaos(ctx, dockerCli, backend, opts, api.ChaosStep{Action: api.ChaosPartition, Services: args, Duration: partitionDuration})
+		}),
+		ValidArgsFunction: completeServiceNames(dockerCli, p),
+	}
+	partition.Flags().DurationVarP(&partitionDuration, "duration", "d", 30*time.Second, "Time services are partitioned for")
+
+	run := &cobra.Command{
+		Use:   "run PLAN_FILE",
+		Short: "Run the fault injection steps of a plan file",
+		Args:  cli.ExactArgs(1),
+		RunE: Adapt(func(ctx context.Context, args []string) error {
+			data, err := os.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+			steps, err := parseChaosPlan(data)
+			if err != nil {
+				return fmt.Errorf("%s: %w", args[0], err)
+			}
+			return runChaos(ctx, dockerCli, backend, opts, steps...)
+		}),
+	}
+
+	cmd.AddCommand(kill, pause, partition, run)
+	return cmd
+}
+
+func runChaos(ctx context.Context, dockerCli command.Cli, backend api.Service, opts chaosOptions, steps ...api.ChaosStep) error {
+	project, _, err := opts.ToProject(ctx, dockerCli, nil)
+	if err != nil {
+		return err
+	}
+	return backend.Chaos(ctx, project, api.ChaosOptions{
+		Steps: steps,
+		Image: opts.image,
+	})
+}
+
+// chaosPlanStep is a step of a plan file, which sets exactly one action
+type chaosPlanStep struct {
+	Kill      string   `yaml:"kill"`
+	Pause     string   `yaml:"pause"`
+	Partition []string `yaml:"partition"`
+	Wait      string   `yaml:"wait"`
+	Duration  string   `yaml:"duration"`
+	Signal    string   `yaml:"signal"`
+}
+
+// parseChaosPlan reads the steps of a plan file
+func parseChaosPlan(data []byte) ([]api.ChaosStep, error) {
+	var plan struct {
+		Steps []chaosPlanStep `yaml:"steps"`
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&plan); err != nil {
+		return nil, err
+	}
+	if len(plan.Steps) == 0 {
+		return nil, errors.New("plan has no steps")
+	}
+	steps := make([]api.ChaosStep, 0, len(plan.Steps))
+	for i, s := range plan.Steps {
+		step := api.ChaosStep{Signal: s.Signal}
+		duration := s.Duration
+		actions := 0
+		if s.Kill != "" {
+			actions++
+			step.Action, step.Services = api.ChaosKill, []string{s.Kill}
+		}
+		if s.Pause != "" {
+			actions++
+			step.Action, step.Services = api.ChaosPause, []string{s.Pause}
+		}
+		if len(s.Partition) > 0 {
+			actions++
+			step.Action, step.Services = api.ChaosPartition, s.Partition
+		}
+		if s.Wait != "" {
+			actions++
+			step.Action, duration = api.ChaosWait, s.Wait
+		}
+		if actions != 1 {
+			return nil, fmt.Errorf("step %d: exactly one of kill, pause, partition or wait must be set", i+1)
+		}
+		if duration != "" {
+			d, err := time.ParseDuration(duration)
+			if err != nil {
+				return nil, fmt.Errorf("step %d: %w", i+1, err)
+			}
+			step.Duration = d
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
@@ -0,0 +1,56 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+func TestParseChaosPlan(t *testing.T) {
+	steps, err := parseChaosPlan([]byte(`
+steps:
+  - kill: web
+    signal: SIGTERM
+  - wait: 5s
+  - pause: db
+    duration: 10s
+  - partition: [web, db]
+    duration: 1m
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []api.ChaosStep{
+		{Action: api.ChaosKill, Services: []string{"web"}, Signal: "SIGTERM"},
+		{Action: api.ChaosWait, Duration: 5 * time.Second},
+		{Action: api.ChaosPause, Services: []string{"db"}, Duration: 10 * time.Second},
+		{Action: api.ChaosPartition, Services: []string{"web", "db"}, Duration: time.Minute},
+	}, steps)
+
+	_, err = parseChaosPlan([]byte("steps:\n  - kill: web\n    pause: db\n"))
+	assert.EqualError(t, err, "step 1: exactly one of kill, pause, partition or wait must be set")
+
+	_, err = parseChaosPlan([]byte("steps:\n  - reboot: web\n"))
+	assert.ErrorContains(t, err, "field reboot not found")
+
+	_, err = parseChaosPlan([]byte("steps: []\n"))
+	assert.EqualError(t, err, "plan has no steps")
+}
@@ -31,7 +31,6 @@ import (
 
 	"github.com/compose-spec/compose-go/v2/cli"
 	"github.com/compose-spec/compose-go/v2/dotenv"
-	"github.com/compose-spec/compose-go/v2/loader"
 	"github.com/compose-spec/compose-go/v2/types"
 	composegoutils "github.com/compose-spec/compose-go/v2/utils"
 	"github.com/docker/buildx/util/logutil"
@@ -42,6 +41,7 @@ import (
 	"github.com/docker/compose/v2/cmd/formatter"
 	"github.com/docker/compose/v2/internal/desktop"
 	"github.com/docker/compose/v2/internal/experimental"
+	"github.com/docker/compose/v2/internal/features"
 	"github.com/docker/compose/v2/internal/tracing"
 	"github.com/docker/compose/v2/pkg/api"
 	ui "github.com/docker/compose/v2/pkg/progress"
@@ -71,6 +71,38 @@ const (
 	ComposeMenu = "COMPOSE_MENU"
 	// ComposeProgress defines type of progress output, if --progress isn't used
 	ComposeProgress = "COMPOSE_PROGRESS"
+	// ComposePullPolicy defines the default pull policy for services which don't declare one
+	ComposePullPolicy = "COMPOSE_PULL_POLICY"
+	// ComposeRegistryRewrites defines registry rewrites, as a comma-separated list of REGISTRY=LOCATION
+	ComposeRegistryRewrites = "COMPOSE_REGISTRY_REWRITES"
+	// ComposeRegistryMirrors defines mirrors oci:// compose artifacts are pulled from, as a comma-separated list of REGISTRY=MIRROR
+	ComposeRegistryMirrors = "COMPOSE_REGISTRY_MIRRORS"
+	// ComposeAuditLog defines the file or oci:// repository run and exec transcripts are recorded to, if --audit-log isn't used
+	ComposeAuditLog = "COMPOSE_AUDIT_LOG"
+	// ComposeProjectGroup defines the project group file, if --project-group isn't used
+	ComposeProjectGroup = "COMPOSE_PROJECT_GROUP"
+	// ComposeIncludeAuth defines credential helpers used to load remote resources, as a comma-separated list of PREFIX=HELPER
+	ComposeIncludeAuth = "COMPOSE_INCLUDE_AUTH"
+	// ComposeRegistryAuth defines credentials used to pull oci:// resources, as a comma-separated list of HOST=helper:NAME or HOST=token:TOKEN
+	ComposeRegistryAuth = "COMPOSE_REGISTRY_AUTH"
+	// ComposeRemoteCacheTTL defines how long oci:// artifacts pulled by tag are used from the cache before resolving the tag again
+	ComposeRemoteCacheTTL = "COMPOSE_REMOTE_CACHE_TTL"
+	// ComposeRemoteCacheMaxSize defines the disk usage above which least recently used remote resources are pruned from the cache, 0 to disable
+	ComposeRemoteCacheMaxSize = "COMPOSE_REMOTE_CACHE_MAX_SIZE"
+	// ComposeRestartLimit defines, as RESTARTS/WINDOW, how often services can restart while attached before they are stopped as crash looping
+	ComposeRestartLimit = "COMPOSE_RESTART_LIMIT"
+	// ComposeOCIVerify defines the cosign policy oci:// compose artifacts must satisfy to be loaded
+	ComposeOCIVerify = "COMPOSE_OCI_VERIFY"
+	// ComposeOCIVariant defines, as a comma-separated list, the environment variants of oci:// compose artifacts to apply
+	ComposeOCIVariant = "COMPOSE_OCI_VARIANT"
+	// ComposeReadOnly makes commands changing the state of the engine fail, so only ps, logs, config and alike can run
+	ComposeReadOnly = "COMPOSE_READONLY"
+	// ComposeRemoteSHA256 defines the expected checksum of Compose files loaded from http(s) URLs
+	ComposeRemoteSHA256 = "COMPOSE_REMOTE_SHA256"
+	// ComposeExtensionSchemas defines files and directories declaring JSON schemas for x- extensions
+	ComposeExtensionSchemas = "COMPOSE_EXTENSION_SCHEMAS"
+	// ComposeDebugImage defines the toolbox image run by `debug`, if --image isn't used
+	ComposeDebugImage = "COMPOSE_DEBUG_IMAGE"
 )
 
 // rawEnv load a dot env file using docker/cli key=value parser, without attempt to interpolate or evaluate values
@@ -151,6 +183,25 @@ type ProjectOptions struct {
 	Progress      string
 	Offline       bool
 	All           bool
+	DockerContext string
+	ProjectGroup  string
+	Frozen        bool
+	Refresh       bool
+	Platform      string
+	UseContext    string
+
+	// useDockerContext switches the engine targeted by backend
+	useDockerContext func(name string) error
+	// remoteInputs records versions remote resources resolved to while loading the project
+	remoteInputs *remote.Inputs
+	// locked pins remote resources and images to the versions recorded by the lock file, if any
+	locked *lockFile
+	// relock ignores the lock file, to resolve remote resources and images again
+	relock bool
+	// recordInputs saves the versions remote resources resolved to, for a later --frozen run to compare with
+	recordInputs bool
+	// features resolves feature flags, from project environment once loaded
+	features *features.Flags
 }
 
 // ProjectFunc does stuff within a types.Project
@@ -232,6 +283,12 @@ func (o *ProjectOptions) addProjectFlags(f *pflag.FlagSet) {
 	f.BoolVar(&o.Compatibility, "compatibility", false, "Run compose in backward compatibility mode")
 	f.StringVar(&o.Progress, "progress", defaultStringVar(ComposeProgress, string(buildkit.AutoMode)), fmt.Sprintf(`Set type of progress output (%s)`, strings.Join(printerModes, ", ")))
 	f.BoolVar(&o.All, "all-resources", false, "Include all resources, even those not used by services")
+	f.StringVar(&o.ProjectGroup, "project-group", os.Getenv(ComposeProjectGroup), "Manage projects declared by a project group file together")
+	f.BoolVar(&o.Frozen, "frozen", false, "Refuse to run if remote resources resolve to another version than on last run")
+	f.BoolVar(&o.Offline, "offline", false, "Load OCI artifacts from the cache without accessing registries")
+	f.BoolVar(&o.Refresh, "refresh", false, "Resolve OCI artifacts again, even if cached for less than COMPOSE_REMOTE_CACHE_TTL")
+	f.StringVar(&o.UseContext, "use-context", "", "Use the Compose files, profiles, env files, project directory and name saved with config --save-context")
+	f.StringVar(&o.Platform, "platform", "", "Set platform services without a platform attribute run on, and select the matching OCI artifact variant")
 	_ = f.MarkHidden("workdir")
 }
 
@@ -290,17 +347,50 @@ func (o *ProjectOptions) ToModel(ctx context.Context, dockerCli command.Cli, ser
 	for _, r := range remotes {
 		po = append(po, cli.WithResourceLoader(r))
 	}
+	if err := o.applyLockFile(); err != nil {
+		return nil, err
+	}
 
 	options, err := o.toProjectOptions(po...)
 	if err != nil {
 		return nil, err
 	}
+	o.featureFlags().WithEnvironment(options.Environment)
 
 	if o.Compatibility || utils.StringToBool(options.Environment[ComposeCompatibility]) {
 		api.Separator = "_"
 	}
 
-	return options.LoadModel(ctx)
+	model, err := options.LoadModel(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if name, ok := model["name"].(string); ok {
+		if err := o.checkRemoteInputs(name); err != nil {
+			return nil, err
+		}
+	}
+	o.pruneRemoteCache()
+
+	if services, ok := model["services"].(map[string]any); ok {
+		for _, s := range services {
+			if service, ok := s.(map[string]any); ok {
+				if image, ok := service["image"].(string); ok {
+					service["image"] = o.locked.pinImage(image)
+				}
+			}
+		}
+	}
+
+	schemas, err := loadExtensionSchemas()
+	if err != nil {
+		return nil, err
+	}
+	if err := schemas.validate(model); err != nil {
+		return nil, err
+	}
+	return model, applyModelCommonBuildArgs(model)
 }
 
 func (o *ProjectOptions) ToProject(ctx context.Context, dockerCli command.Cli, services []string, po ...cli.ProjectOptionsFn) (*types.Project, tracing.Metrics, error) { //nolint:gocyclo
@@ -309,11 +399,15 @@ func (o *ProjectOptions) ToProject(ctx context.Context, dockerCli command.Cli, s
 	for _, r := range remotes {
 		po = append(po, cli.WithResourceLoader(r))
 	}
+	if err := o.applyLockFile(); err != nil {
+		return nil, metrics, err
+	}
 
 	options, err := o.toProjectOptions(po...)
 	if err != nil {
 		return nil, metrics, err
 	}
+	o.featureFlags().WithEnvironment(options.Environment)
 
 	options.WithListeners(func(event string, metadata map[string]any) {
 		switch event {
@@ -347,16 +441,32 @@ func (o *ProjectOptions) ToProject(ctx context.Context, dockerCli command.Cli, s
 		return nil, metrics, err
 	}
 
+	schemas, err := loadExtensionSchemas()
+	if err != nil {
+		return nil, metrics, err
+	}
+	if err = schemas.validateProject(project); err != nil {
+		return nil, metrics, err
+	}
+
 	if project.Name == "" {
 		return nil, metrics, errors.New("project name can't be empty. Use `--project-name` to set a valid name")
 	}
 
+	if err := o.checkRemoteInputs(project.Name); err != nil {
+		return nil, metrics, err
+	}
+	o.pruneRemoteCache()
+
 	project, err = project.WithServicesEnabled(services...)
 	if err != nil {
 		return nil, metrics, err
 	}
 
 	for name, s := range project.Services {
+		if s.Build == nil {
+			s.Image = o.locked.pinImage(s.Image)
+		}
 		s.CustomLabels = map[string]string{
 			api.ProjectLabel:     project.Name,
 			api.ServiceLabel:     name,
@@ -376,19 +486,43 @@ func (o *ProjectOptions) ToProject(ctx context.Context, dockerCli command.Cli, s
 		return nil, tracing.Metrics{}, err
 	}
 
+	if err = applyDefaultPullPolicy(project); err != nil {
+		return nil, metrics, err
+	}
+
+	if err = applyRegistryRewrites(project); err != nil {
+		return nil, metrics, err
+	}
+
+	if err = applyCommonBuildArgs(project); err != nil {
+		return nil, metrics, err
+	}
+
 	if !o.All {
 		project = project.WithoutUnnecessaryResources()
 	}
+
+	err = o.applyDockerContext(project)
 	return project, metrics, err
 }
 
-func (o *ProjectOptions) remoteLoaders(dockerCli command.Cli) []loader.ResourceLoader {
-	if o.Offline {
+// applyDefaultPullPolicy sets pull policy defined by COMPOSE_PULL_POLICY for services which don't declare one
+func applyDefaultPullPolicy(project *types.Project) error {
+	policy := project.Environment[ComposePullPolicy]
+	if policy == "" {
 		return nil
 	}
-	git := remote.NewGitRemoteLoader(dockerCli, o.Offline)
-	oci := remote.NewOCIRemoteLoader(dockerCli, o.Offline)
-	return []loader.ResourceLoader{git, oci}
+	if !api.IsValidPullPolicy(policy) {
+		return fmt.Errorf("invalid %s value %q", ComposePullPolicy, policy)
+	}
+	for name, service := range project.Services {
+		if service.PullPolicy != "" || service.Image == "" {
+			continue
+		}
+		service.PullPolicy = policy
+		project.Services[name] = service
+	}
+	return nil
 }
 
 func (o *ProjectOptions) toProjectOptions(po ...cli.ProjectOptionsFn) (*cli.ProjectOptions, error) {
@@ -415,11 +549,20 @@ func (o *ProjectOptions) toProjectOptions(po ...cli.ProjectOptionsFn) (*cli.Proj
 			// .. and then, a project directory != PWD maybe has been set so let's load .env file
 			cli.WithEnvFiles(o.EnvFiles...),
 			cli.WithDotEnv,
+			// --platform takes precedence over the default platform set by environment
+			withDefaultPlatform(o.Platform),
 			// eventually COMPOSE_PROFILES should have been set
 			cli.WithDefaultProfiles(o.Profiles...),
 			cli.WithName(o.ProjectName))...)
 }
 
+func withDefaultPlatform(platform string) cli.ProjectOptionsFn {
+	if platform == "" {
+		return func(*cli.ProjectOptions) error { return nil }
+	}
+	return cli.WithEnv([]string{api.ComposeDefaultPlatform + "=" + platform})
+}
+
 // PluginName is the name of the plugin
 const PluginName = "compose"
 
@@ -430,6 +573,10 @@ func RunningAsStandalone() bool {
 
 // RootCommand returns the compose command with its child commands
 func RootCommand(dockerCli command.Cli, backend Backend) *cobra.Command { //nolint:gocyclo
+	contexts, switchable := backend.(contextBackend)
+	if switchable {
+		dockerCli = backendCli{Cli: dockerCli, backend: contexts}
+	}
 	// filter out useless commandConn.CloseWrite warning message that can occur
 	// when using a remote context that is unreachable: "commandConn.CloseWrite: commandconn: failed to wait: signal: killed"
 	// https://github.com/docker/cli/blob/e1f24d3c93df6752d3c27c8d61d18260f141310c/cli/connhelper/commandconn/commandconn.go#L203-L215
@@ -487,6 +634,12 @@ func RootCommand(dockerCli command.Cli, backend Backend) *cobra.Command { //noli
 				logrus.SetLevel(logrus.TraceLevel)
 			}
 
+			if opts.UseContext != "" {
+				if err := useContext(savedContextsFile(), opts.UseContext, &opts); err != nil {
+					return err
+				}
+			}
+
 			err := setEnvWithDotEnv(opts)
 			if err != nil {
 				return err
@@ -566,6 +719,12 @@ func RootCommand(dockerCli command.Cli, backend Backend) *cobra.Command { //noli
 				composeCmd = composeCmd.Parent()
 			}
 
+			if utils.StringToBool(os.Getenv(ComposeReadOnly)) && !dryRun {
+				if err := checkReadOnly(cmd); err != nil {
+					return err
+				}
+			}
+
 			if v, ok := os.LookupEnv(ComposeParallelLimit); ok && !composeCmd.Flags().Changed("parallel") {
 				i, err := strconv.Atoi(v)
 				if err != nil {
@@ -578,6 +737,16 @@ func RootCommand(dockerCli command.Cli, backend Backend) *cobra.Command { //noli
 				backend.MaxConcurrency(parallel)
 			}
 
+			// docker context selection, applies before dry-run so the dry-run client wraps the selected engine
+			if switchable {
+				if err := contexts.UseDockerContext(opts.DockerContext); err != nil {
+					return err
+				}
+				opts.useDockerContext = contexts.UseDockerContext
+			} else if opts.DockerContext != "" {
+				return errors.New("--context is not supported by this compose backend")
+			}
+
 			// dry run detection
 			ctx, err = backend.DryRunMode(ctx, dryRun)
 			if err != nil {
@@ -612,6 +781,7 @@ func RootCommand(dockerCli command.Cli, backend Backend) *cobra.Command { //noli
 
 	c.AddCommand(
 		upCommand(&opts, dockerCli, backend),
+		applyCommand(&opts, dockerCli, backend),
 		downCommand(&opts, dockerCli, backend),
 		startCommand(&opts, dockerCli, backend),
 		restartCommand(&opts, dockerCli, backend),
@@ -620,7 +790,11 @@ func RootCommand(dockerCli command.Cli, backend Backend) *cobra.Command { //noli
 		listCommand(dockerCli, backend),
 		logsCommand(&opts, dockerCli, backend),
 		configCommand(&opts, dockerCli),
+		featuresCommand(&opts, dockerCli),
+		inspectCommand(&opts, dockerCli, backend),
+		envCommand(&opts, dockerCli, backend),
 		killCommand(&opts, dockerCli, backend),
+		signalCommand(&opts, dockerCli, backend),
 		runCommand(&opts, dockerCli, backend),
 		removeCommand(&opts, dockerCli, backend),
 		execCommand(&opts, dockerCli, backend),
@@ -632,6 +806,8 @@ func RootCommand(dockerCli command.Cli, backend Backend) *cobra.Command { //noli
 		topCommand(&opts, dockerCli, backend),
 		eventsCommand(&opts, dockerCli, backend),
 		portCommand(&opts, dockerCli, backend),
+		portForwardCommand(&opts, dockerCli, backend),
+		diffCommand(&opts, dockerCli, backend),
 		imagesCommand(&opts, dockerCli, backend),
 		versionCommand(dockerCli),
 		buildCommand(&opts, dockerCli, backend),
@@ -644,6 +820,12 @@ func RootCommand(dockerCli command.Cli, backend Backend) *cobra.Command { //noli
 		statsCommand(&opts, dockerCli),
 		watchCommand(&opts, dockerCli, backend),
 		publishCommand(&opts, dockerCli, backend),
+		artifactCommand(dockerCli, backend),
+		cacheCommand(dockerCli),
+		prefetchCommand(&opts, dockerCli),
+		lockCommand(&opts, dockerCli),
+		previewCommand(&opts, dockerCli, backend),
+		debugCommand(&opts, dockerCli, backend),
 		alphaCommand(&opts, dockerCli, backend),
 	)
 
@@ -672,6 +854,7 @@ func RootCommand(dockerCli command.Cli, backend Backend) *cobra.Command { //noli
 
 	c.Flags().StringVar(&ansi, "ansi", "auto", `Control when to print ANSI control characters ("never"|"always"|"auto")`)
 	c.Flags().IntVar(&parallel, "parallel", -1, `Control max parallelism, -1 for unlimited`)
+	c.Flags().StringVar(&opts.DockerContext, "context", "", "Name of the docker context to use, overriding the current one and x-context set by Compose file")
 	c.Flags().BoolVarP(&version, "version", "v", false, "Show the Docker Compose version information")
 	c.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Execute command in dry run mode")
 	c.Flags().MarkHidden("version") //nolint:errcheck
@@ -679,6 +862,7 @@ func RootCommand(dockerCli command.Cli, backend Backend) *cobra.Command { //noli
 	c.Flags().MarkHidden("no-ansi") //nolint:errcheck
 	c.Flags().BoolVar(&verbose, "verbose", false, "Show more output")
 	c.Flags().MarkHidden("verbose") //nolint:errcheck
+
 	return c
 }
 
@@ -697,6 +881,10 @@ func setEnvWithDotEnv(opts ProjectOptions) error {
 		return nil
 	}
 	for k, v := range envFromFile {
+		// COMPOSE_FEATURES enables features running commands on the host, which the project must not enable for itself
+		if k == features.EnvFeatures {
+			continue
+		}
 		if _, ok := os.LookupEnv(k); !ok {
 			if err = os.Setenv(k, v); err != nil {
 				return nil
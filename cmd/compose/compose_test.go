@@ -17,10 +17,14 @@
 package compose
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/compose-spec/compose-go/v2/types"
 	"gotest.tools/v3/assert"
+
+	"github.com/docker/compose/v2/internal/features"
 )
 
 func TestFilterServices(t *testing.T) {
@@ -53,3 +57,25 @@ func TestFilterServices(t *testing.T) {
 	_, err = p.GetService("zot")
 	assert.NilError(t, err)
 }
+
+func TestSetEnvWithDotEnvIgnoresFeatures(t *testing.T) {
+	dir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "compose.yaml"), []byte("services: {}\n"), 0o600))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, ".env"), []byte("COMPOSE_FEATURES=host-process,hooks\nFROM_DOTENV=1\n"), 0o600))
+	t.Setenv(features.EnvFeatures, "")
+	assert.NilError(t, os.Unsetenv(features.EnvFeatures))
+	t.Setenv("FROM_DOTENV", "")
+	assert.NilError(t, os.Unsetenv("FROM_DOTENV"))
+
+	err := setEnvWithDotEnv(ProjectOptions{ProjectDir: dir})
+	assert.NilError(t, err)
+	assert.Equal(t, os.Getenv("FROM_DOTENV"), "1")
+	_, ok := os.LookupEnv(features.EnvFeatures)
+	assert.Check(t, !ok)
+
+	for _, feature := range []features.Feature{features.HostProcess, features.ProjectHooks} {
+		enabled, err := features.NewFlags(nil).Enabled(feature)
+		assert.NilError(t, err)
+		assert.Check(t, !enabled, feature.Name)
+	}
+}
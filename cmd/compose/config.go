@@ -22,15 +22,21 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
 	"os"
+	"slices"
 	"sort"
 	"strings"
 
 	"github.com/compose-spec/compose-go/v2/cli"
 	"github.com/compose-spec/compose-go/v2/template"
 	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/containerd/platforms"
+	dockercli "github.com/docker/cli/cli"
 	"github.com/docker/cli/cli/command"
 	"github.com/docker/compose/v2/cmd/formatter"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 
@@ -53,9 +59,16 @@ type configOptions struct {
 	profiles            bool
 	images              bool
 	hash                string
+	hashContent         bool
+	hashDiff            string
 	noConsistency       bool
 	variables           bool
 	environment         bool
+	startOrder          bool
+	inputs              bool
+	artifactInfo        bool
+	platforms           bool
+	saveContext         string
 }
 
 func (o *configOptions) ToProject(ctx context.Context, dockerCli command.Cli, services []string, po ...cli.ProjectOptionsFn) (*types.Project, error) {
@@ -101,7 +114,10 @@ func configCommand(p *ProjectOptions, dockerCli command.Cli) *cobra.Command {
 			}
 			return nil
 		}),
-		RunE: Adapt(func(ctx context.Context, args []string) error {
+		RunE: p.withServiceGroups(dockerCli, Adapt(func(ctx context.Context, args []string) error {
+			if opts.saveContext != "" {
+				return runSaveContext(ctx, dockerCli, opts, args)
+			}
 			if opts.services {
 				return runServices(ctx, dockerCli, opts)
 			}
@@ -123,12 +139,24 @@ func configCommand(p *ProjectOptions, dockerCli command.Cli) *cobra.Command {
 			if opts.environment {
 				return runEnvironment(ctx, dockerCli, opts, args)
 			}
+			if opts.startOrder {
+				return runStartOrder(ctx, dockerCli, opts, args)
+			}
+			if opts.inputs {
+				return runInputs(ctx, dockerCli, opts, args)
+			}
+			if opts.platforms {
+				return runPlatforms(ctx, dockerCli, opts, args)
+			}
+			if opts.artifactInfo {
+				return runArtifactInfo(ctx, dockerCli, opts, args)
+			}
 
 			if opts.Format == "" {
 				opts.Format = "yaml"
 			}
 			return runConfig(ctx, dockerCli, opts, args)
-		}),
+		})),
 		ValidArgsFunction: completeServiceNames(dockerCli, p),
 	}
 	flags := cmd.Flags()
@@ -146,14 +174,35 @@ func configCommand(p *ProjectOptions, dockerCli command.Cli) *cobra.Command {
 	flags.BoolVar(&opts.profiles, "profiles", false, "Print the profile names, one per line.")
 	flags.BoolVar(&opts.images, "images", false, "Print the image names, one per line.")
 	flags.StringVar(&opts.hash, "hash", "", "Print the service config hash, one per line.")
+	flags.BoolVar(&opts.hashContent, "hash-content", false, "With --hash, also hash the content of bind mounts, configs and secrets.")
+	flags.StringVar(&opts.hashDiff, "hash-diff", "", "With --hash, compare against hashes previously saved in JSON format and exit with status 1 if they differ.")
 	flags.BoolVar(&opts.variables, "variables", false, "Print model variables and default values.")
 	flags.BoolVar(&opts.environment, "environment", false, "Print environment used for interpolation.")
+	flags.BoolVar(&opts.startOrder, "start-order", false, "Print the service names in the order they get started, one per line.")
+	flags.BoolVar(&opts.inputs, "inputs", false, "Print remote resources the model was loaded from, and the version they resolved to.")
+	flags.BoolVar(&opts.artifactInfo, "artifact-info", false, "Print the version, description and license of OCI artifacts the model was loaded from.")
+	flags.BoolVar(&opts.platforms, "platforms", false, "Print the platform each service runs on, and where it is set.")
 	flags.StringVarP(&opts.Output, "output", "o", "", "Save to file (default to stdout)")
+	flags.StringVar(&opts.saveContext, "save-context", "", "Save the Compose files, profiles, env files, project directory and name in use under a name, to run with --use-context")
 
 	return cmd
 }
 
+// runSaveContext validates the project can be loaded, then saves the project flags in use
+func runSaveContext(ctx context.Context, dockerCli command.Cli, opts configOptions, services []string) error {
+	project, err := opts.ToProject(ctx, dockerCli, services)
+	if err != nil {
+		return err
+	}
+	if err := saveContext(savedContextsFile(), opts.saveContext, opts.ProjectOptions, project); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(dockerCli.Out(), "Context %q saved, run it with docker compose --use-context %s\n", opts.saveContext, opts.saveContext)
+	return err
+}
+
 func runConfig(ctx context.Context, dockerCli command.Cli, opts configOptions, services []string) (err error) {
+
 	var content []byte
 	if opts.noInterpolate {
 		content, err = runConfigNoInterpolate(ctx, dockerCli, opts, services)
@@ -274,7 +323,7 @@ func resolveImageDigests(ctx context.Context, dockerCli command.Cli, model map[s
 	return nil
 }
 
-func formatModel(model map[string]any, format string) (content []byte, err error) {
+func formatModel(model any, format string) (content []byte, err error) {
 	switch format {
 	case "json":
 		content, err = json.MarshalIndent(model, "", "  ")
@@ -319,6 +368,21 @@ func runServices(ctx context.Context, dockerCli command.Cli, opts configOptions)
 	return err
 }
 
+func runStartOrder(ctx context.Context, dockerCli command.Cli, opts configOptions, services []string) error {
+	project, err := opts.ToProject(ctx, dockerCli, services, cli.WithoutEnvironmentResolution)
+	if err != nil {
+		return err
+	}
+	order, err := compose.StartOrder(project)
+	if err != nil {
+		return err
+	}
+	for _, name := range order {
+		_, _ = fmt.Fprintln(dockerCli.Out(), name)
+	}
+	return nil
+}
+
 func runVolumes(ctx context.Context, dockerCli command.Cli, opts configOptions) error {
 	project, err := opts.ToProject(ctx, dockerCli, nil, cli.WithoutEnvironmentResolution)
 	if err != nil {
@@ -330,6 +394,16 @@ func runVolumes(ctx context.Context, dockerCli command.Cli, opts configOptions)
 	return nil
 }
 
+// serviceHash is the hash record of a service, printed by `config --hash` and compared by --hash-diff
+type serviceHash struct {
+	// Hash is the configuration hash, as set on containers by the com.docker.compose.config-hash label
+	Hash string `json:"hash"`
+	// Image is the digest the service image resolved to, with --resolve-image-digests
+	Image string `json:"image,omitempty"`
+	// Content is the hash of the local content mounted by the service, with --hash-content
+	Content string `json:"content,omitempty"`
+}
+
 func runHash(ctx context.Context, dockerCli command.Cli, opts configOptions) error {
 	var services []string
 	if opts.hash != "*" {
@@ -353,21 +427,120 @@ func runHash(ctx context.Context, dockerCli command.Cli, opts configOptions) err
 		return sorted[i] < sorted[j]
 	})
 
+	var previous map[string]serviceHash
+	if opts.hashDiff != "" {
+		content, err := os.ReadFile(opts.hashDiff)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(content, &previous); err != nil {
+			return fmt.Errorf("reading hashes from %s: %w", opts.hashDiff, err)
+		}
+		// compare all attributes recorded in the previous run
+		for _, h := range previous {
+			opts.resolveImageDigests = opts.resolveImageDigests || h.Image != ""
+			opts.hashContent = opts.hashContent || h.Content != ""
+		}
+	}
+
+	resolved := project
+	if opts.resolveImageDigests {
+		resolved, err = project.WithImagesResolved(compose.ImageDigestResolver(ctx, dockerCli.ConfigFile(), dockerCli.Client()))
+		if err != nil {
+			return err
+		}
+	}
+
+	hashes := make(map[string]serviceHash, len(sorted))
 	for _, name := range sorted {
 		s, err := project.GetService(name)
 		if err != nil {
 			return err
 		}
 
-		hash, err := compose.ServiceHash(s)
+		var h serviceHash
+		h.Hash, err = compose.ServiceHash(s)
 		if err != nil {
 			return err
 		}
-		_, _ = fmt.Fprintf(dockerCli.Out(), "%s %s\n", name, hash)
+		if opts.resolveImageDigests {
+			_, h.Image, _ = strings.Cut(resolved.Services[name].Image, "@")
+		}
+		if opts.hashContent {
+			h.Content, err = compose.ServiceContentHash(project, s)
+			if err != nil {
+				return err
+			}
+		}
+		hashes[name] = h
+	}
+
+	if opts.hashDiff != "" {
+		if opts.hash != "*" {
+			maps.DeleteFunc(previous, func(name string, _ serviceHash) bool {
+				return !slices.Contains(sorted, name)
+			})
+		}
+		changes := diffServiceHashes(previous, hashes)
+		for _, c := range changes {
+			_, _ = fmt.Fprintln(dockerCli.Out(), c)
+		}
+		if len(changes) > 0 {
+			return dockercli.StatusError{StatusCode: 1}
+		}
+		return nil
+	}
+
+	if opts.Format == "json" {
+		return formatter.Print(hashes, formatter.JSON, dockerCli.Out(), nil)
+	}
+	for _, name := range sorted {
+		h := hashes[name]
+		line := []string{name, h.Hash}
+		if h.Image != "" {
+			line = append(line, h.Image)
+		}
+		if h.Content != "" {
+			line = append(line, h.Content)
+		}
+		_, _ = fmt.Fprintln(dockerCli.Out(), strings.Join(line, " "))
 	}
 	return nil
 }
 
+// diffServiceHashes lists services whose hashes changed since previous, one line per service, only comparing attributes
+// both records have
+func diffServiceHashes(previous, current map[string]serviceHash) []string {
+	var changes []string
+	for _, name := range slices.Sorted(maps.Keys(current)) {
+		h := current[name]
+		p, ok := previous[name]
+		if !ok {
+			changes = append(changes, name+" added")
+			continue
+		}
+		var changed []string
+		if p.Hash != h.Hash {
+			changed = append(changed, "config")
+		}
+		if p.Image != "" && h.Image != "" && p.Image != h.Image {
+			changed = append(changed, "image")
+		}
+		if p.Content != "" && h.Content != "" && p.Content != h.Content {
+			changed = append(changed, "content")
+		}
+		if len(changed) > 0 {
+			changes = append(changes, fmt.Sprintf("%s changed (%s)", name, strings.Join(changed, ", ")))
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(previous)) {
+		if _, ok := current[name]; !ok {
+			changes = append(changes, name+" removed")
+		}
+	}
+	return changes
+}
+
 func runProfiles(ctx context.Context, dockerCli command.Cli, opts configOptions, services []string) error {
 	set := map[string]struct{}{}
 	project, err := opts.ToProject(ctx, dockerCli, services, cli.WithoutEnvironmentResolution)
@@ -439,6 +612,94 @@ func runEnvironment(ctx context.Context, dockerCli command.Cli, opts configOptio
 	return nil
 }
 
+func runInputs(ctx context.Context, dockerCli command.Cli, opts configOptions, services []string) error {
+	_, err := opts.ToProject(ctx, dockerCli, services, cli.WithoutEnvironmentResolution)
+	if err != nil {
+		return err
+	}
+
+	inputs := opts.remoteInputs.Resolved()
+	return formatter.Print(inputs, opts.Format, dockerCli.Out(), func(w io.Writer) {
+		for _, path := range slices.Sorted(maps.Keys(inputs)) {
+			_, _ = fmt.Fprintf(w, "%s\t%s\n", path, inputs[path])
+		}
+	}, "INPUT", "DIGEST")
+}
+
+func runArtifactInfo(ctx context.Context, dockerCli command.Cli, opts configOptions, services []string) error {
+	_, err := opts.ToProject(ctx, dockerCli, services, cli.WithoutEnvironmentResolution)
+	if err != nil {
+		return err
+	}
+
+	artifacts := opts.remoteInputs.Artifacts()
+	return formatter.Print(artifacts, opts.Format, dockerCli.Out(), func(w io.Writer) {
+		for _, a := range artifacts {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", a.Reference, a.Version, a.License, a.MinVersion, a.Description)
+		}
+	}, "ARTIFACT", "VERSION", "LICENSE", "MIN COMPOSE VERSION", "DESCRIPTION")
+}
+
+// servicePlatform is the platform a service runs on, and where it is set
+type servicePlatform struct {
+	Service  string `json:"service"`
+	Platform string `json:"platform,omitempty"`
+	// Source is either `service`, the variable setting the default platform, or `native` for the engine platform
+	Source string `json:"source"`
+	// Emulated is unknown if the engine can't be reached
+	Emulated *bool `json:"emulated,omitempty"`
+}
+
+func runPlatforms(ctx context.Context, dockerCli command.Cli, opts configOptions, services []string) error {
+	project, err := opts.ToProject(ctx, dockerCli, services, cli.WithoutEnvironmentResolution)
+	if err != nil {
+		return err
+	}
+
+	var native *specs.Platform
+	if version, err := dockerCli.Client().ServerVersion(ctx); err == nil {
+		native = &specs.Platform{OS: version.Os, Architecture: version.Arch}
+	} else {
+		logrus.Debugf("can't get engine platform: %v", err)
+	}
+
+	resolved := resolvePlatforms(project, native)
+	return formatter.Print(resolved, opts.Format, dockerCli.Out(), func(w io.Writer) {
+		for _, p := range resolved {
+			emulated := "unknown"
+			if p.Emulated != nil {
+				emulated = fmt.Sprint(*p.Emulated)
+			}
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", p.Service, p.Platform, p.Source, emulated)
+		}
+	}, "SERVICE", "PLATFORM", "SOURCE", "EMULATED")
+}
+
+// resolvePlatforms resolves the platform services run on: service `platform`, then COMPOSE_DEFAULT_PLATFORM, then
+// DOCKER_DEFAULT_PLATFORM, then the engine native platform if known
+func resolvePlatforms(project *types.Project, native *specs.Platform) []servicePlatform {
+	var resolved []servicePlatform
+	for _, name := range slices.Sorted(maps.Keys(project.Services)) {
+		platform, source := api.ServicePlatform(project, project.Services[name])
+		p := servicePlatform{
+			Service:  name,
+			Platform: platform,
+			Source:   source,
+		}
+		if native != nil {
+			if p.Platform == "" {
+				p.Platform = platforms.Format(*native)
+			}
+			if parsed, err := platforms.Parse(p.Platform); err == nil {
+				emulated := !platforms.Only(*native).Match(parsed)
+				p.Emulated = &emulated
+			}
+		}
+		resolved = append(resolved, p)
+	}
+	return resolved
+}
+
 func escapeDollarSign(marshal []byte) []byte {
 	dollar := []byte{'$'}
 	escDollar := []byte{'$', '$'}
@@ -0,0 +1,69 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffServiceHashes(t *testing.T) {
+	previous := map[string]serviceHash{
+		"api":    {Hash: "a1", Image: "sha256:aaa", Content: "c1"},
+		"db":     {Hash: "d1", Image: "sha256:ddd"},
+		"legacy": {Hash: "l1"},
+		"web":    {Hash: "w1"},
+	}
+	current := map[string]serviceHash{
+		"api":    {Hash: "a1", Image: "sha256:bbb", Content: "c2"},
+		"db":     {Hash: "d2", Image: "sha256:ddd"},
+		"web":    {Hash: "w1", Content: "c3"},
+		"worker": {Hash: "k1"},
+	}
+	assert.Equal(t, []string{
+		"api changed (image, content)",
+		"db changed (config)",
+		"worker added",
+		"legacy removed",
+	}, diffServiceHashes(previous, current))
+
+	assert.Empty(t, diffServiceHashes(current, current))
+}
+
+func TestResolvePlatforms(t *testing.T) {
+	project := &types.Project{
+		Services: types.Services{
+			"db":  {Name: "db", Platform: "linux/amd64"},
+			"web": {Name: "web"},
+		},
+		Environment: types.Mapping{},
+	}
+	assert.Equal(t, []servicePlatform{
+		{Service: "db", Platform: "linux/amd64", Source: "service"},
+		{Service: "web", Source: "native"},
+	}, resolvePlatforms(project, nil))
+
+	project.Environment["COMPOSE_DEFAULT_PLATFORM"] = "linux/arm64"
+	emulated, native := true, false
+	assert.Equal(t, []servicePlatform{
+		{Service: "db", Platform: "linux/amd64", Source: "service", Emulated: &emulated},
+		{Service: "web", Platform: "linux/arm64", Source: "COMPOSE_DEFAULT_PLATFORM", Emulated: &native},
+	}, resolvePlatforms(project, &specs.Platform{OS: "linux", Architecture: "arm64"}))
+}
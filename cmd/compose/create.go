@@ -19,7 +19,6 @@ package compose
 import (
 	"context"
 	"fmt"
-	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -34,21 +33,24 @@ import (
 )
 
 type createOptions struct {
-	Build         bool
-	noBuild       bool
-	Pull          string
-	pullChanged   bool
-	removeOrphans bool
-	ignoreOrphans bool
-	forceRecreate bool
-	noRecreate    bool
-	recreateDeps  bool
-	noInherit     bool
-	timeChanged   bool
-	timeout       int
-	quietPull     bool
-	scale         []string
-	AssumeYes     bool
+	Build          bool
+	noBuild        bool
+	Pull           string
+	pullChanged    bool
+	removeOrphans  bool
+	previewOrphans bool
+	ignoreOrphans  bool
+	forceRecreate  bool
+	noRecreate     bool
+	recreateDeps   bool
+	noInherit      bool
+	timeChanged    bool
+	timeout        int
+	quietPull      bool
+	scale          []string
+	AssumeYes      bool
+	explain        bool
+	pullStreaming  bool
 }
 
 func createCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *cobra.Command {
@@ -67,21 +69,26 @@ func createCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service
 			if opts.forceRecreate && opts.noRecreate {
 				return fmt.Errorf("--force-recreate and --no-recreate are incompatible")
 			}
+			if opts.previewOrphans && !opts.removeOrphans {
+				return fmt.Errorf("--preview-orphans requires --remove-orphans")
+			}
 			return nil
 		}),
-		RunE: p.WithServices(dockerCli, func(ctx context.Context, project *types.Project, services []string) error {
+		RunE: p.withServiceGroups(dockerCli, p.WithServices(dockerCli, func(ctx context.Context, project *types.Project, services []string) error {
 			return runCreate(ctx, dockerCli, backend, opts, buildOpts, project, services)
-		}),
+		})),
 		ValidArgsFunction: completeServiceNames(dockerCli, p),
 	}
 	flags := cmd.Flags()
 	flags.BoolVar(&opts.Build, "build", false, "Build images before starting containers")
 	flags.BoolVar(&opts.noBuild, "no-build", false, "Don't build an image, even if it's policy")
-	flags.StringVar(&opts.Pull, "pull", "policy", `Pull image before running ("always"|"missing"|"never"|"build")`)
+	flags.StringVar(&opts.Pull, "pull", "policy", `Pull image before running ("always"|"missing"|"missing+digest-check"|"never"|"build")`)
 	flags.BoolVar(&opts.quietPull, "quiet-pull", false, "Pull without printing progress information")
 	flags.BoolVar(&opts.forceRecreate, "force-recreate", false, "Recreate containers even if their configuration and image haven't changed")
 	flags.BoolVar(&opts.noRecreate, "no-recreate", false, "If containers already exist, don't recreate them. Incompatible with --force-recreate.")
+	flags.BoolVar(&opts.explain, "explain-recreate", false, "Explain which configuration changes cause containers to be recreated")
 	flags.BoolVar(&opts.removeOrphans, "remove-orphans", false, "Remove containers for services not defined in the Compose file")
+	flags.BoolVar(&opts.previewOrphans, "preview-orphans", false, "List orphan containers and ask for confirmation before removing them")
 	flags.StringArrayVar(&opts.scale, "scale", []string{}, "Scale SERVICE to NUM instances. Overrides the `scale` setting in the Compose file if present.")
 	flags.BoolVarP(&opts.AssumeYes, "yes", "y", false, `Assume "yes" as answer to all prompts and run non-interactively`)
 	flags.SetNormalizeFunc(func(f *pflag.FlagSet, name string) pflag.NormalizedName {
@@ -113,6 +120,7 @@ func runCreate(ctx context.Context, _ command.Cli, backend api.Service, createOp
 		Build:                build,
 		Services:             services,
 		RemoveOrphans:        createOpts.removeOrphans,
+		ConfirmRemoveOrphans: createOpts.previewOrphans,
 		IgnoreOrphans:        createOpts.ignoreOrphans,
 		Recreate:             createOpts.recreateStrategy(),
 		RecreateDependencies: createOpts.dependenciesRecreateStrategy(),
@@ -120,6 +128,7 @@ func runCreate(ctx context.Context, _ command.Cli, backend api.Service, createOp
 		Timeout:              createOpts.GetTimeout(),
 		QuietPull:            createOpts.quietPull,
 		AssumeYes:            createOpts.AssumeYes,
+		ExplainRecreate:      createOpts.explain,
 	})
 }
 
@@ -208,9 +217,5 @@ func applyScaleOpts(project *types.Project, opts []string) error {
 }
 
 func (opts createOptions) isPullPolicyValid() bool {
-	pullPolicies := []string{
-		types.PullPolicyAlways, types.PullPolicyNever, types.PullPolicyBuild,
-		types.PullPolicyMissing, types.PullPolicyIfNotPresent,
-	}
-	return slices.Contains(pullPolicies, opts.Pull)
+	return api.IsValidPullPolicy(opts.Pull)
 }
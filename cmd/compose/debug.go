@@ -0,0 +1,99 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/spf13/cobra"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+type debugOptions struct {
+	*composeOptions
+	service    string
+	command    []string
+	image      string
+	index      int
+	noTty      bool
+	privileged bool
+}
+
+func debugCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *cobra.Command {
+	opts := debugOptions{
+		composeOptions: &composeOptions{
+			ProjectOptions: p,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:   "debug [OPTIONS] SERVICE [COMMAND] [ARGS...]",
+		Short: "Run a toolbox container sharing the network, processes and volumes of a service container",
+		Args:  cobra.MinimumNArgs(1),
+		PreRunE: Adapt(func(ctx context.Context, args []string) error {
+			opts.service = args[0]
+			opts.command = args[1:]
+			return nil
+		}),
+		RunE: Adapt(func(ctx context.Context, args []string) error {
+			return runDebug(ctx, dockerCli, backend, opts)
+		}),
+		ValidArgsFunction: completeServiceNames(dockerCli, p),
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&opts.image, "image", "", "Toolbox image to run (default: busybox:stable)")
+	flags.IntVar(&opts.index, "index", 0, "Index of the container if service has multiple replicas")
+	flags.BoolVarP(&opts.noTty, "no-TTY", "T", !dockerCli.Out().IsTerminal(), "Disable pseudo-TTY allocation")
+	flags.BoolVar(&opts.privileged, "privileged", false, "Give extended privileges to the toolbox container")
+	flags.SetInterspersed(false)
+	return cmd
+}
+
+func runDebug(ctx context.Context, dockerCli command.Cli, backend api.Service, opts debugOptions) error {
+	projectName, err := opts.toProjectName(ctx, dockerCli)
+	if err != nil {
+		return err
+	}
+	image := opts.image
+	if image == "" {
+		projectOptions, err := opts.composeOptions.toProjectOptions() //nolint:staticcheck
+		if err != nil {
+			return err
+		}
+		image = projectOptions.Environment[ComposeDebugImage]
+	}
+
+	exitCode, err := backend.Debug(ctx, projectName, api.DebugOptions{
+		Service:     opts.service,
+		Index:       opts.index,
+		Image:       image,
+		Command:     opts.command,
+		Tty:         !opts.noTty,
+		Interactive: true,
+		Privileged:  opts.privileged,
+	})
+	if exitCode != 0 {
+		errMsg := ""
+		if err != nil {
+			errMsg = err.Error()
+		}
+		return cli.StatusError{StatusCode: exitCode, Status: errMsg}
+	}
+	return err
+}
@@ -0,0 +1,147 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/spf13/cobra"
+
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/utils"
+)
+
+type diffOptions struct {
+	*ProjectOptions
+	index    int
+	kinds    []string
+	paths    []string
+	format   string
+	exitCode bool
+}
+
+// diffKinds maps accepted --kind values to the change kind reported by engine
+var diffKinds = map[string]string{
+	"a": "A", "added": "A",
+	"c": "C", "changed": "C",
+	"d": "D", "deleted": "D",
+}
+
+func diffCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *cobra.Command {
+	opts := diffOptions{
+		ProjectOptions: p,
+	}
+	cmd := &cobra.Command{
+		Use:   "diff [OPTIONS] SERVICE",
+		Short: "Inspect changes to files or directories on a service container's filesystem",
+		Args:  cli.ExactArgs(1),
+		PreRunE: Adapt(func(ctx context.Context, args []string) error {
+			for i, kind := range opts.kinds {
+				k, ok := diffKinds[strings.ToLower(kind)]
+				if !ok {
+					return fmt.Errorf("invalid --kind value %q, must be one of added, changed or deleted", kind)
+				}
+				opts.kinds[i] = k
+			}
+			if opts.format != "table" && opts.format != "json" {
+				return fmt.Errorf("unsupported format %q", opts.format)
+			}
+			return nil
+		}),
+		RunE: Adapt(func(ctx context.Context, args []string) error {
+			return runDiff(ctx, dockerCli, backend, opts, args[0])
+		}),
+		ValidArgsFunction: completeServiceNames(dockerCli, p),
+	}
+	flags := cmd.Flags()
+	flags.IntVar(&opts.index, "index", 0, "Index of the container if service has multiple replicas")
+	flags.StringArrayVar(&opts.kinds, "kind", nil, `Only show changes of this kind ("added"|"changed"|"deleted")`)
+	flags.StringArrayVar(&opts.paths, "path", nil, "Only show changes under this path")
+	flags.StringVar(&opts.format, "format", "table", `Format the output. Values: [table | json]`)
+	flags.BoolVar(&opts.exitCode, "exit-code", false, "Exit with status 1 if changes are found")
+	return cmd
+}
+
+func runDiff(ctx context.Context, dockerCli command.Cli, backend api.Service, opts diffOptions, service string) error {
+	projectName, err := opts.toProjectName(ctx, dockerCli)
+	if err != nil {
+		return err
+	}
+	changes, err := backend.Diff(ctx, projectName, service, api.DiffOptions{
+		Index: opts.index,
+	})
+	if err != nil {
+		return err
+	}
+	changes = filterChanges(changes, opts.kinds, opts.paths)
+
+	err = writeChanges(dockerCli.Out(), changes, opts.format)
+	if err != nil {
+		return err
+	}
+	if opts.exitCode && len(changes) > 0 {
+		return cli.StatusError{StatusCode: 1}
+	}
+	return nil
+}
+
+// filterChanges selects changes matching one of kinds, under one of paths
+func filterChanges(changes []api.FileChange, kinds []string, paths []string) []api.FileChange {
+	filtered := make([]api.FileChange, 0, len(changes))
+	for _, change := range changes {
+		if len(kinds) > 0 && !utils.StringContains(kinds, change.Kind) {
+			continue
+		}
+		if len(paths) > 0 && !underAny(change.Path, paths) {
+			continue
+		}
+		filtered = append(filtered, change)
+	}
+	return filtered
+}
+
+func underAny(p string, parents []string) bool {
+	for _, parent := range parents {
+		parent = path.Clean("/" + parent)
+		if p == parent || parent == "/" || strings.HasPrefix(p, parent+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func writeChanges(out io.Writer, changes []api.FileChange, format string) error {
+	if format == "json" {
+		b, err := json.MarshalIndent(changes, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(b))
+		return err
+	}
+	for _, change := range changes {
+		_, _ = fmt.Fprintf(out, "%s %s\n", change.Kind, change.Path)
+	}
+	return nil
+}
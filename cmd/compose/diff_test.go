@@ -0,0 +1,47 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+func TestFilterChanges(t *testing.T) {
+	changes := []api.FileChange{
+		{Kind: "C", Path: "/var"},
+		{Kind: "A", Path: "/var/cache/app.db"},
+		{Kind: "C", Path: "/etc"},
+		{Kind: "D", Path: "/etc/motd"},
+		{Kind: "A", Path: "/variable"},
+	}
+	assert.Equal(t, changes, filterChanges(changes, nil, nil))
+	assert.Equal(t, []api.FileChange{
+		{Kind: "A", Path: "/var/cache/app.db"},
+		{Kind: "A", Path: "/variable"},
+	}, filterChanges(changes, []string{"A"}, nil))
+	assert.Equal(t, []api.FileChange{
+		{Kind: "C", Path: "/var"},
+		{Kind: "A", Path: "/var/cache/app.db"},
+	}, filterChanges(changes, nil, []string{"/var/"}))
+	assert.Equal(t, []api.FileChange{
+		{Kind: "D", Path: "/etc/motd"},
+	}, filterChanges(changes, []string{"D", "A"}, []string{"etc"}))
+}
@@ -0,0 +1,100 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/spf13/cobra"
+
+	"github.com/docker/compose/v2/cmd/formatter"
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+type dnsCheckOptions struct {
+	*ProjectOptions
+	lookup string
+	image  string
+	format string
+}
+
+func dnsCheckCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *cobra.Command {
+	opts := dnsCheckOptions{
+		ProjectOptions: p,
+	}
+	cmd := &cobra.Command{
+		Use:   "dns-check [OPTIONS] [SERVICE...]",
+		Short: "EXPERIMENTAL - Validate services DNS configuration and probe name resolution from their networks",
+		RunE: p.withServiceGroups(dockerCli, Adapt(func(ctx context.Context, args []string) error {
+			return runDNSCheck(ctx, dockerCli, backend, opts, args)
+		})),
+		ValidArgsFunction: completeServiceNames(dockerCli, p),
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&opts.lookup, "lookup", "docker.com", "Host name to resolve")
+	flags.StringVar(&opts.image, "image", "busybox:stable", "Image used to run probes, must provide nslookup")
+	flags.StringVar(&opts.format, "format", "table", "Format the output. Values: [table | json]")
+	return cmd
+}
+
+func runDNSCheck(ctx context.Context, dockerCli command.Cli, backend api.Service, opts dnsCheckOptions, services []string) error {
+	project, _, err := opts.ToProject(ctx, dockerCli, services)
+	if err != nil {
+		return err
+	}
+	results, err := backend.DNSCheck(ctx, project, api.DNSCheckOptions{
+		Services: services,
+		Lookup:   opts.lookup,
+		Image:    opts.image,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = formatter.Print(results, opts.format, dockerCli.Out(), func(w io.Writer) {
+		for _, r := range results {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.Service, r.Network, dnsServerLabel(r.Server), r.Status, r.Duration.Round(time.Millisecond))
+		}
+	}, "SERVICE", "NETWORK", "SERVER", "STATUS", "DURATION")
+	if err != nil {
+		return err
+	}
+
+	failed := false
+	for _, r := range results {
+		failed = failed || r.Status == api.DNSCheckFailed
+		if r.Status != api.DNSCheckOK && opts.format == formatter.TABLE {
+			_, _ = fmt.Fprintf(dockerCli.Err(), "%s %s %s: %s\n", r.Service, dnsServerLabel(r.Server), r.Status, r.Output)
+		}
+	}
+	if failed {
+		return cli.StatusError{StatusCode: 1}
+	}
+	return nil
+}
+
+func dnsServerLabel(server string) string {
+	if server == "" {
+		return "(configured)"
+	}
+	return server
+}
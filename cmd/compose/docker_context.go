@@ -0,0 +1,115 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/context/docker"
+	"github.com/docker/docker/client"
+	"github.com/sirupsen/logrus"
+)
+
+// dockerContextExtension is the compose file extension to select the docker context used to manage services
+const dockerContextExtension = "x-context"
+
+// contextBackend is implemented by backends able to target the engine of another docker context than the CLI one
+type contextBackend interface {
+	UseDockerContext(name string) error
+	// DockerCli returns the CLI reaching the engine the backend targets
+	DockerCli() command.Cli
+}
+
+// backendCli makes commands querying the engine directly reach the one targeted by backend
+type backendCli struct {
+	command.Cli
+	backend contextBackend
+}
+
+func (c backendCli) Client() client.APIClient {
+	return c.backend.DockerCli().Client()
+}
+
+func (c backendCli) CurrentContext() string {
+	return c.backend.DockerCli().CurrentContext()
+}
+
+func (c backendCli) DockerEndpoint() docker.Endpoint {
+	return c.backend.DockerCli().DockerEndpoint()
+}
+
+// applyDockerContext targets the docker context declared by selected services with `x-context`, unless one has
+// been explicitly set by --context
+func (o *ProjectOptions) applyDockerContext(project *types.Project) error {
+	if o.DockerContext != "" || o.useDockerContext == nil {
+		return nil
+	}
+	name, err := projectDockerContext(project)
+	if err != nil || name == "" {
+		return err
+	}
+	logrus.Debugf("Using docker context %q declared by compose file", name)
+	return o.useDockerContext(name)
+}
+
+// projectDockerContext returns the docker context selected services declare by `x-context`, as a service
+// attribute or at project level as default value
+func projectDockerContext(project *types.Project) (string, error) {
+	var defaultContext string
+	if v, ok := project.Extensions[dockerContextExtension]; ok {
+		s, ok := v.(string)
+		if !ok {
+			return "", fmt.Errorf("%s must be a string", dockerContextExtension)
+		}
+		defaultContext = s
+	}
+
+	contexts := map[string][]string{}
+	for _, service := range project.Services {
+		name := defaultContext
+		if v, ok := service.Extensions[dockerContextExtension]; ok {
+			s, ok := v.(string)
+			if !ok {
+				return "", fmt.Errorf("service %q: %s must be a string", service.Name, dockerContextExtension)
+			}
+			name = s
+		}
+		if name != "" {
+			contexts[name] = append(contexts[name], service.Name)
+		}
+	}
+
+	switch len(contexts) {
+	case 0:
+		return "", nil
+	case 1:
+		for name := range contexts {
+			return name, nil
+		}
+	}
+	var groups []string
+	for name, services := range contexts {
+		sort.Strings(services)
+		groups = append(groups, fmt.Sprintf("%s: %s", name, strings.Join(services, ", ")))
+	}
+	sort.Strings(groups)
+	return "", fmt.Errorf("selected services target distinct docker contexts (%s), run compose for each group of services", strings.Join(groups, "; "))
+}
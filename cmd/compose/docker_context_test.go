@@ -0,0 +1,85 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/compose/v2/pkg/mocks"
+	"github.com/docker/docker/client"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+)
+
+func TestProjectDockerContext(t *testing.T) {
+	p := &types.Project{
+		Extensions: types.Extensions{"x-context": "staging"},
+		Services: types.Services{
+			"foo": {
+				Name: "foo",
+			},
+			"bar": {
+				Name:       "bar",
+				Extensions: types.Extensions{"x-context": "staging"},
+			},
+		},
+	}
+	name, err := projectDockerContext(p)
+	assert.NilError(t, err)
+	assert.Equal(t, name, "staging")
+
+	p.Services["zot"] = types.ServiceConfig{
+		Name:       "zot",
+		Extensions: types.Extensions{"x-context": "local"},
+	}
+	_, err = projectDockerContext(p)
+	assert.Error(t, err, "selected services target distinct docker contexts (local: zot; staging: bar, foo), run compose for each group of services")
+
+	p.Extensions = nil
+	delete(p.Services, "bar")
+	delete(p.Services, "zot")
+	name, err = projectDockerContext(p)
+	assert.NilError(t, err)
+	assert.Equal(t, name, "")
+}
+
+type fakeContextBackend struct {
+	cli command.Cli
+}
+
+func (f fakeContextBackend) UseDockerContext(string) error {
+	return nil
+}
+
+func (f fakeContextBackend) DockerCli() command.Cli {
+	return f.cli
+}
+
+func TestBackendCli(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	original := mocks.NewMockCli(mockCtrl)
+	selected := mocks.NewMockCli(mockCtrl)
+	apiClient := mocks.NewMockAPIClient(mockCtrl)
+	selected.EXPECT().Client().Return(apiClient)
+	selected.EXPECT().CurrentContext().Return("remote")
+
+	cli := backendCli{Cli: original, backend: fakeContextBackend{cli: selected}}
+	assert.Equal(t, cli.Client(), client.APIClient(apiClient))
+	assert.Equal(t, cli.CurrentContext(), "remote")
+}
@@ -18,6 +18,7 @@ package compose
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"time"
@@ -38,6 +39,7 @@ type downOptions struct {
 	timeout       int
 	volumes       bool
 	images        string
+	force         bool
 }
 
 func downCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *cobra.Command {
@@ -49,6 +51,9 @@ func downCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service)
 		Short: "Stop and remove containers, networks",
 		PreRunE: AdaptCmd(func(ctx context.Context, cmd *cobra.Command, args []string) error {
 			opts.timeChanged = cmd.Flags().Changed("timeout")
+			if opts.force && opts.timeChanged {
+				return errors.New("cannot combine --force and --timeout")
+			}
 			if opts.images != "" {
 				if opts.images != "all" && opts.images != "local" {
 					return fmt.Errorf("invalid value for --rmi: %q", opts.images)
@@ -56,9 +61,9 @@ func downCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service)
 			}
 			return nil
 		}),
-		RunE: Adapt(func(ctx context.Context, args []string) error {
+		RunE: p.withServiceGroups(dockerCli, p.withProjectGroup(true, Adapt(func(ctx context.Context, args []string) error {
 			return runDown(ctx, dockerCli, backend, opts, args)
-		}),
+		}))),
 		ValidArgsFunction: noCompletion(),
 	}
 	flags := downCmd.Flags()
@@ -66,6 +71,7 @@ func downCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service)
 	flags.BoolVar(&opts.removeOrphans, "remove-orphans", removeOrphans, "Remove containers for services not defined in the Compose file")
 	flags.IntVarP(&opts.timeout, "timeout", "t", 0, "Specify a shutdown timeout in seconds")
 	flags.BoolVarP(&opts.volumes, "volumes", "v", false, `Remove named volumes declared in the "volumes" section of the Compose file and anonymous volumes attached to containers`)
+	flags.BoolVar(&opts.force, "force", false, "Remove containers without stopping them gracefully first")
 	flags.StringVar(&opts.images, "rmi", "", `Remove images used by services. "local" remove only images that don't have a custom tag ("local"|"all")`)
 	flags.SetNormalizeFunc(func(f *pflag.FlagSet, name string) pflag.NormalizedName {
 		if name == "volume" {
@@ -95,5 +101,6 @@ func runDown(ctx context.Context, dockerCli command.Cli, backend api.Service, op
 		Images:        opts.images,
 		Volumes:       opts.volumes,
 		Services:      services,
+		Force:         opts.force,
 	})
 }
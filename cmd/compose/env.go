@@ -0,0 +1,161 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/cli/cli/command"
+	"github.com/spf13/cobra"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+type envOptions struct {
+	*ProjectOptions
+	format string
+}
+
+func envCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *cobra.Command {
+	opts := envOptions{
+		ProjectOptions: p,
+	}
+	cmd := &cobra.Command{
+		Use:   "env [OPTIONS] [SERVICE...]",
+		Short: "Export connection details of running services as environment variables",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			switch opts.format {
+			case "shell", "dotenv", "json":
+				return nil
+			default:
+				return fmt.Errorf("unsupported format %q", opts.format)
+			}
+		},
+		RunE: p.withServiceGroups(dockerCli, Adapt(func(ctx context.Context, args []string) error {
+			return runEnv(ctx, dockerCli, backend, opts, args)
+		})),
+		ValidArgsFunction: completeServiceNames(dockerCli, p),
+	}
+	cmd.Flags().StringVar(&opts.format, "format", "shell", "Format the output. Values: [shell | dotenv | json]")
+	return cmd
+}
+
+func runEnv(ctx context.Context, dockerCli command.Cli, backend api.Service, opts envOptions, services []string) error {
+	project, _, err := opts.ToProject(ctx, dockerCli, services)
+	if err != nil {
+		return err
+	}
+	containers, err := backend.Ps(ctx, project.Name, api.PsOptions{
+		Project:  project,
+		Services: services,
+	})
+	if err != nil {
+		return err
+	}
+	return writeEnv(dockerCli.Out(), projectEnv(project, containers), opts.format)
+}
+
+// projectEnv computes variables exposing coordinates of services to host: hostname on project networks, and
+// address of published ports for the first running container of each service. Host path of file-based secrets
+// are also exposed
+func projectEnv(project *types.Project, containers []api.ContainerSummary) map[string]string {
+	env := map[string]string{}
+	sort.Slice(containers, func(i, j int) bool {
+		left, _ := strconv.Atoi(containers[i].Labels[api.ContainerNumberLabel])
+		right, _ := strconv.Atoi(containers[j].Labels[api.ContainerNumberLabel])
+		return left < right
+	})
+	seen := map[string]bool{}
+	for _, ctr := range containers {
+		if seen[ctr.Service] {
+			continue
+		}
+		seen[ctr.Service] = true
+
+		prefix := envName(ctr.Service)
+		env[prefix+"_HOSTNAME"] = ctr.Service
+		for _, p := range ctr.Publishers {
+			if p.PublishedPort == 0 {
+				continue
+			}
+			key := fmt.Sprintf("%s_PORT_%d", prefix, p.TargetPort)
+			if p.Protocol != "" && p.Protocol != "tcp" {
+				key += "_" + envName(p.Protocol)
+			}
+			host := p.URL
+			if host == "" || host == "0.0.0.0" || host == "::" {
+				host = "localhost"
+			}
+			env[key] = strconv.Itoa(p.PublishedPort)
+			env[key+"_ADDR"] = net.JoinHostPort(host, strconv.Itoa(p.PublishedPort))
+		}
+	}
+	for name, secret := range project.Secrets {
+		if secret.File != "" {
+			env["SECRET_"+envName(name)+"_FILE"] = secret.File
+		}
+	}
+	return env
+}
+
+// envName converts name into a valid environment variable name
+func envName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+func writeEnv(out io.Writer, env map[string]string, format string) error {
+	if format == "json" {
+		content, err := json.MarshalIndent(env, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(content))
+		return err
+	}
+
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		switch format {
+		case "shell":
+			_, _ = fmt.Fprintf(out, "export %s='%s'\n", k, strings.ReplaceAll(env[k], "'", `'\''`))
+		case "dotenv":
+			_, _ = fmt.Fprintf(out, "%s=%s\n", k, strconv.Quote(env[k]))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+func TestProjectEnv(t *testing.T) {
+	project := &types.Project{
+		Secrets: types.Secrets{
+			"db-password": {File: "/project/secrets/db.txt"},
+			"external":    {External: true},
+		},
+	}
+	containers := []api.ContainerSummary{
+		{
+			Service: "web-app",
+			Labels:  map[string]string{api.ContainerNumberLabel: "2"},
+			Publishers: api.PortPublishers{
+				{URL: "0.0.0.0", TargetPort: 80, PublishedPort: 8081, Protocol: "tcp"},
+			},
+		},
+		{
+			Service: "web-app",
+			Labels:  map[string]string{api.ContainerNumberLabel: "1"},
+			Publishers: api.PortPublishers{
+				{URL: "0.0.0.0", TargetPort: 80, PublishedPort: 8080, Protocol: "tcp"},
+				{URL: "127.0.0.1", TargetPort: 53, PublishedPort: 5353, Protocol: "udp"},
+				{URL: "::1", TargetPort: 443, PublishedPort: 8443, Protocol: "tcp"},
+				{TargetPort: 9000},
+			},
+		},
+	}
+
+	env := projectEnv(project, containers)
+	assert.Equal(t, map[string]string{
+		"WEB_APP_HOSTNAME":         "web-app",
+		"WEB_APP_PORT_80":          "8080",
+		"WEB_APP_PORT_80_ADDR":     "localhost:8080",
+		"WEB_APP_PORT_53_UDP":      "5353",
+		"WEB_APP_PORT_53_UDP_ADDR": "127.0.0.1:5353",
+		"WEB_APP_PORT_443":         "8443",
+		"WEB_APP_PORT_443_ADDR":    "[::1]:8443",
+		"SECRET_DB_PASSWORD_FILE":  "/project/secrets/db.txt",
+	}, env)
+}
+
+func TestWriteEnv(t *testing.T) {
+	env := map[string]string{
+		"WEB_PORT_80": "8080",
+		"SECRET_FILE": "/path/with 'quote'",
+	}
+
+	tests := map[string]string{
+		"shell": `export SECRET_FILE='/path/with '\''quote'\'''
+export WEB_PORT_80='8080'
+`,
+		"dotenv": `SECRET_FILE="/path/with 'quote'"
+WEB_PORT_80="8080"
+`,
+		"json": `{
+  "SECRET_FILE": "/path/with 'quote'",
+  "WEB_PORT_80": "8080"
+}
+`,
+	}
+	for format, expected := range tests {
+		t.Run(format, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			require.NoError(t, writeEnv(buf, env, format))
+			assert.Equal(t, expected, buf.String())
+		})
+	}
+}
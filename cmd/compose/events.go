@@ -41,9 +41,9 @@ func eventsCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service
 	cmd := &cobra.Command{
 		Use:   "events [OPTIONS] [SERVICE...]",
 		Short: "Receive real time events from containers",
-		RunE: Adapt(func(ctx context.Context, args []string) error {
+		RunE: p.withServiceGroups(dockerCli, Adapt(func(ctx context.Context, args []string) error {
 			return runEvents(ctx, dockerCli, backend, opts, args)
-		}),
+		})),
 		ValidArgsFunction: completeServiceNames(dockerCli, p),
 	}
 
@@ -18,6 +18,7 @@ package compose
 
 import (
 	"context"
+	"errors"
 
 	"github.com/compose-spec/compose-go/v2/types"
 	"github.com/docker/cli/cli"
@@ -41,6 +42,10 @@ type execOpts struct {
 	index       int
 	privileged  bool
 	interactive bool
+	auditLog    string
+
+	session       string
+	attachSession string
 }
 
 func execCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *cobra.Command {
@@ -52,8 +57,19 @@ func execCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service)
 	runCmd := &cobra.Command{
 		Use:   "exec [OPTIONS] SERVICE COMMAND [ARGS...]",
 		Short: "Execute a command in a running container",
-		Args:  cobra.MinimumNArgs(2),
+		Args: func(cmd *cobra.Command, args []string) error {
+			if opts.attachSession != "" {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.MinimumNArgs(2)(cmd, args)
+		},
 		PreRunE: Adapt(func(ctx context.Context, args []string) error {
+			if opts.attachSession != "" {
+				if opts.session != "" || opts.detach {
+					return errors.New("--attach-session can't be combined with --session or --detach")
+				}
+				return nil
+			}
 			opts.service = args[0]
 			opts.command = args[1:]
 			return nil
@@ -71,6 +87,9 @@ func execCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service)
 	runCmd.Flags().StringVarP(&opts.user, "user", "u", "", "Run the command as this user")
 	runCmd.Flags().BoolVarP(&opts.noTty, "no-TTY", "T", !dockerCli.Out().IsTerminal(), "Disable pseudo-TTY allocation. By default `docker compose exec` allocates a TTY.")
 	runCmd.Flags().StringVarP(&opts.workingDir, "workdir", "w", "", "Path to workdir directory for this command")
+	runCmd.Flags().StringVar(&opts.auditLog, "audit-log", "", "Record command transcript to this file or oci:// repository")
+	runCmd.Flags().StringVar(&opts.session, "session", "", "Run the command in a named session which survives client disconnects")
+	runCmd.Flags().StringVar(&opts.attachSession, "attach-session", "", "Attach to a running session")
 
 	runCmd.Flags().BoolVarP(&opts.interactive, "interactive", "i", true, "Keep STDIN open even if not attached")
 	runCmd.Flags().MarkHidden("interactive") //nolint:errcheck
@@ -105,6 +124,13 @@ func runExec(ctx context.Context, dockerCli command.Cli, backend api.Service, op
 		Detach:      opts.detach,
 		WorkingDir:  opts.workingDir,
 		Interactive: opts.interactive,
+		AuditLog:    opts.auditLog,
+
+		Session:       opts.session,
+		AttachSession: opts.attachSession,
+	}
+	if execOpts.AuditLog == "" {
+		execOpts.AuditLog = projectOptions.Environment[ComposeAuditLog]
 	}
 
 	exitCode, err := backend.Exec(ctx, projectName, execOpts)
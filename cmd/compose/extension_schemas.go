@@ -0,0 +1,151 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/cli/cli/config"
+	"github.com/xeipuuv/gojsonschema"
+	"gopkg.in/yaml.v3"
+)
+
+// extensionSchemas maps an extension name (x-*) to the JSON schema its values must comply with
+type extensionSchemas map[string]*gojsonschema.Schema
+
+// loadExtensionSchemas registers schemas from the compose/schemas directory of the docker configuration, then from
+// files and directories listed by COMPOSE_EXTENSION_SCHEMAS. A schema file is named after the extension it applies
+// to, for example x-team.json
+func loadExtensionSchemas() (extensionSchemas, error) {
+	schemas := extensionSchemas{}
+	defaultDir := filepath.Join(config.Dir(), "compose", "schemas")
+	if _, err := os.Stat(defaultDir); err == nil {
+		if err := schemas.register(defaultDir); err != nil {
+			return nil, err
+		}
+	}
+	for _, path := range filepath.SplitList(os.Getenv(ComposeExtensionSchemas)) {
+		if path == "" {
+			continue
+		}
+		if err := schemas.register(path); err != nil {
+			return nil, err
+		}
+	}
+	return schemas, nil
+}
+
+func (s extensionSchemas) register(path string) error {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("loading extension schemas: %w", err)
+	}
+	files := []string{path}
+	if stat.IsDir() {
+		files, err = filepath.Glob(filepath.Join(path, "x-*.json"))
+		if err != nil {
+			return err
+		}
+	}
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		if !strings.HasPrefix(name, "x-") {
+			return fmt.Errorf("extension schema %s must be named after an x- extension", file)
+		}
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		schema, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(b))
+		if err != nil {
+			return fmt.Errorf("invalid extension schema %s: %w", file, err)
+		}
+		s[name] = schema
+	}
+	return nil
+}
+
+// validateProject checks extensions declared by project against registered schemas
+func (s extensionSchemas) validateProject(project *types.Project) error {
+	if len(s) == 0 {
+		return nil
+	}
+	// project JSON marshalling doesn't render service extensions
+	b, err := project.MarshalYAML()
+	if err != nil {
+		return err
+	}
+	var model map[string]any
+	if err := yaml.Unmarshal(b, &model); err != nil {
+		return err
+	}
+	return s.validate(model)
+}
+
+// validate checks all extensions found in model against registered schemas
+func (s extensionSchemas) validate(model map[string]any) error {
+	if len(s) == 0 {
+		return nil
+	}
+	var errs []error
+	s.walk(nil, model, &errs)
+	return errors.Join(errs...)
+}
+
+func (s extensionSchemas) walk(path []string, value any, errs *[]error) {
+	switch v := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			p := append(path[:len(path):len(path)], k)
+			if schema, ok := s[k]; ok {
+				*errs = append(*errs, validateExtension(schema, strings.Join(p, "."), v[k])...)
+			}
+			s.walk(p, v[k], errs)
+		}
+	case []any:
+		for i, item := range v {
+			s.walk(append(path[:len(path):len(path)], fmt.Sprint(i)), item, errs)
+		}
+	}
+}
+
+func validateExtension(schema *gojsonschema.Schema, path string, value any) []error {
+	result, err := schema.Validate(gojsonschema.NewGoLoader(value))
+	if err != nil {
+		return []error{fmt.Errorf("%s: %w", path, err)}
+	}
+	var errs []error
+	for _, e := range result.Errors() {
+		field := path
+		if e.Field() != gojsonschema.STRING_CONTEXT_ROOT {
+			field = path + "." + e.Field()
+		}
+		errs = append(errs, fmt.Errorf("%s: %s", field, e.Description()))
+	}
+	return errs
+}
@@ -0,0 +1,66 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestExtensionSchemas(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "x-team.json"), []byte(`{
+  "type": "object",
+  "required": ["name"],
+  "properties": {
+    "name": {"type": "string"},
+    "oncall": {"type": "boolean"}
+  },
+  "additionalProperties": false
+}`), 0o600)
+	assert.NilError(t, err)
+	t.Setenv("DOCKER_CONFIG", t.TempDir())
+	t.Setenv(ComposeExtensionSchemas, dir)
+
+	schemas, err := loadExtensionSchemas()
+	assert.NilError(t, err)
+
+	err = schemas.validate(map[string]any{
+		"x-team": map[string]any{"name": "platform"},
+		"services": map[string]any{
+			"web": map[string]any{
+				"image":  "nginx",
+				"x-team": map[string]any{"name": "web", "oncall": true},
+			},
+		},
+	})
+	assert.NilError(t, err)
+
+	err = schemas.validate(map[string]any{
+		"services": map[string]any{
+			"web": map[string]any{
+				"image":  "nginx",
+				"x-team": map[string]any{"nmae": "web"},
+			},
+		},
+	})
+	assert.ErrorContains(t, err, "services.web.x-team: name is required")
+	assert.ErrorContains(t, err, "services.web.x-team: Additional property nmae is not allowed")
+}
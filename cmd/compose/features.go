@@ -0,0 +1,66 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/compose/v2/cmd/formatter"
+	"github.com/docker/compose/v2/internal/features"
+	"github.com/spf13/cobra"
+)
+
+type featuresOptions struct {
+	*ProjectOptions
+	format string
+}
+
+func featuresCommand(p *ProjectOptions, dockerCli command.Cli) *cobra.Command {
+	opts := featuresOptions{
+		ProjectOptions: p,
+	}
+	cmd := &cobra.Command{
+		Use:   "features [OPTIONS]",
+		Short: "List feature flags, their state and stability",
+		Args:  cobra.NoArgs,
+		RunE: Adapt(func(ctx context.Context, args []string) error {
+			return runFeatures(ctx, dockerCli, opts)
+		}),
+	}
+	cmd.Flags().StringVar(&opts.format, "format", "table", "Format the output. Values: [table | json]")
+	return cmd
+}
+
+func runFeatures(_ context.Context, dockerCli command.Cli, opts featuresOptions) error {
+	flags := features.NewFlags(nil)
+	// project environment, including .env file, may override features, but a project isn't required
+	if options, err := opts.toProjectOptions(); err == nil {
+		flags = features.NewFlags(options.Environment)
+	}
+	states, err := flags.List()
+	if err != nil {
+		return err
+	}
+	return formatter.Print(states, opts.format, dockerCli.Out(), func(w io.Writer) {
+		for _, state := range states {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%t\t%s\t%s\n", state.Name, state.Stability, state.Enabled, state.Source, state.Description)
+		}
+	}, "NAME", "STABILITY", "ENABLED", "SOURCE", "DESCRIPTION")
+}
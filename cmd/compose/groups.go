@@ -0,0 +1,148 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/cli"
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/cli/cli/command"
+	"github.com/spf13/cobra"
+
+	"github.com/docker/compose/v2/pkg/remote"
+	"github.com/docker/compose/v2/pkg/utils"
+)
+
+const (
+	// serviceGroupExtension declares the group(s) a service belongs to
+	serviceGroupExtension = "x-group"
+	// serviceGroupPrefix marks a command argument as a service group name
+	serviceGroupPrefix = "@"
+)
+
+// resolveServiceGroups replaces `@group` entries in services by the names of services declaring this group with
+// `x-group`. The project model is only loaded if at least one group is used, and without the side effects of
+// ToProject: docker context set by the project isn't applied, lock file isn't read, remote inputs are neither checked
+// nor recorded and the remote resources cache isn't pruned
+func (o *ProjectOptions) resolveServiceGroups(ctx context.Context, dockerCli command.Cli, services []string) ([]string, error) {
+	if !hasServiceGroup(services) {
+		return services, nil
+	}
+	// remote resources resolved to find groups are not the inputs of the command
+	inputs := o.remoteInputs
+	o.remoteInputs = remote.NewInputs()
+	loaders := o.remoteLoaders(dockerCli)
+	o.remoteInputs = inputs
+
+	po := []cli.ProjectOptionsFn{cli.WithDiscardEnvFile}
+	for _, r := range loaders {
+		po = append(po, cli.WithResourceLoader(r))
+	}
+	options, err := o.toProjectOptions(po...)
+	if err != nil {
+		return nil, err
+	}
+	project, err := options.LoadProject(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return expandServiceGroups(project, services)
+}
+
+// withServiceGroups expands `@group` arguments of a command accepting a list of services before fn runs
+func (o *ProjectOptions) withServiceGroups(dockerCli command.Cli, fn func(cmd *cobra.Command, args []string) error) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		args, err := o.resolveServiceGroups(cmd.Context(), dockerCli, args)
+		if err != nil {
+			return err
+		}
+		return fn(cmd, args)
+	}
+}
+
+func hasServiceGroup(services []string) bool {
+	for _, s := range services {
+		if strings.HasPrefix(s, serviceGroupPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// expandServiceGroups replaces `@group` entries in services by the sorted names of group members
+func expandServiceGroups(project *types.Project, services []string) ([]string, error) {
+	groups, err := serviceGroups(project)
+	if err != nil {
+		return nil, err
+	}
+	var expanded []string
+	for _, s := range services {
+		names := []string{s}
+		if group, ok := strings.CutPrefix(s, serviceGroupPrefix); ok {
+			names, ok = groups[group]
+			if !ok {
+				return nil, fmt.Errorf("no such service group: %s", group)
+			}
+		}
+		for _, name := range names {
+			if !utils.StringContains(expanded, name) {
+				expanded = append(expanded, name)
+			}
+		}
+	}
+	return expanded, nil
+}
+
+// serviceGroups indexes service names by the groups they declare, including services disabled by profiles
+func serviceGroups(project *types.Project) (map[string][]string, error) {
+	groups := map[string][]string{}
+	all := []types.Services{project.Services, project.DisabledServices}
+	for _, services := range all {
+		for name, service := range services {
+			v, ok := service.Extensions[serviceGroupExtension]
+			if !ok {
+				continue
+			}
+			var names []string
+			switch g := v.(type) {
+			case string:
+				names = []string{g}
+			case []any:
+				for _, e := range g {
+					s, ok := e.(string)
+					if !ok {
+						return nil, fmt.Errorf("service %q: %s must be a string or a list of strings", name, serviceGroupExtension)
+					}
+					names = append(names, s)
+				}
+			default:
+				return nil, fmt.Errorf("service %q: %s must be a string or a list of strings", name, serviceGroupExtension)
+			}
+			for _, group := range names {
+				groups[group] = append(groups[group], name)
+			}
+		}
+	}
+	for _, members := range groups {
+		sort.Strings(members)
+	}
+	return groups, nil
+}
@@ -0,0 +1,106 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/compose/v2/pkg/mocks"
+	"github.com/spf13/cobra"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+)
+
+func TestExpandServiceGroups(t *testing.T) {
+	p := &types.Project{
+		Services: types.Services{
+			"api": {
+				Name:       "api",
+				Extensions: types.Extensions{serviceGroupExtension: "backend"},
+			},
+			"worker": {
+				Name:       "worker",
+				Extensions: types.Extensions{serviceGroupExtension: []any{"backend", "jobs"}},
+			},
+			"web": {
+				Name: "web",
+			},
+		},
+		DisabledServices: types.Services{
+			"cron": {
+				Name:       "cron",
+				Extensions: types.Extensions{serviceGroupExtension: "jobs"},
+			},
+		},
+	}
+
+	services, err := expandServiceGroups(p, []string{"web", "@backend", "@jobs"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, services, []string{"web", "api", "worker", "cron"})
+
+	_, err = expandServiceGroups(p, []string{"@frontend"})
+	assert.Error(t, err, "no such service group: frontend")
+
+	p.Services["web"] = types.ServiceConfig{
+		Name:       "web",
+		Extensions: types.Extensions{serviceGroupExtension: 42},
+	}
+	_, err = expandServiceGroups(p, []string{"@backend"})
+	assert.Error(t, err, `service "web": x-group must be a string or a list of strings`)
+}
+
+func TestWithServiceGroups(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "compose.yaml")
+	assert.NilError(t, os.WriteFile(file, []byte(`
+services:
+  api:
+    image: api
+    x-group: backend
+    x-context: remote
+  worker:
+    image: worker
+    x-group: backend
+    x-context: other
+  web:
+    image: web
+`), 0o644))
+
+	var received []string
+	record := func(cmd *cobra.Command, args []string) error {
+		received = args
+		return nil
+	}
+	mockCtrl := gomock.NewController(t)
+	cli := mocks.NewMockCli(mockCtrl)
+	cli.EXPECT().ConfigFile().Return(&configfile.ConfigFile{}).AnyTimes()
+	p := &ProjectOptions{ConfigPaths: []string{file}, Offline: true, useDockerContext: func(name string) error {
+		t.Fatalf("unexpected switch to docker context %q", name)
+		return nil
+	}}
+	stop := &cobra.Command{Use: "stop [OPTIONS] [SERVICE...]", RunE: p.withServiceGroups(cli, record)}
+
+	// services targeting distinct docker contexts don't prevent resolving groups, nor switch context
+	stop.SetContext(context.Background())
+	assert.NilError(t, stop.RunE(stop, []string{"web", "@backend"}))
+	assert.DeepEqual(t, received, []string{"web", "api", "worker"})
+}
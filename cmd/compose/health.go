@@ -0,0 +1,142 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/spf13/cobra"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+type healthOptions struct {
+	*ProjectOptions
+	index    int
+	interval time.Duration
+	count    int
+}
+
+func healthCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *cobra.Command {
+	opts := healthOptions{
+		ProjectOptions: p,
+	}
+	cmd := &cobra.Command{
+		Use:   "health [OPTIONS] SERVICE",
+		Short: "EXPERIMENTAL - Run the service healthcheck command and report its result",
+		Args:  cli.ExactArgs(1),
+		RunE: Adapt(func(ctx context.Context, args []string) error {
+			return runHealth(ctx, dockerCli, backend, opts, args[0])
+		}),
+		ValidArgsFunction: completeServiceNames(dockerCli, p),
+	}
+	flags := cmd.Flags()
+	flags.IntVar(&opts.index, "index", 0, "Index of the container if service has multiple replicas")
+	flags.DurationVar(&opts.interval, "interval", 0, "Run healthcheck repeatedly, waiting this duration between runs")
+	flags.IntVar(&opts.count, "count", 0, "Number of runs when --interval is set (default: until interrupted)")
+	return cmd
+}
+
+func runHealth(ctx context.Context, dockerCli command.Cli, backend api.Service, opts healthOptions, service string) error {
+	project, _, err := opts.ToProject(ctx, dockerCli, []string{service})
+	if err != nil {
+		return err
+	}
+	s, err := project.GetService(service)
+	if err != nil {
+		return err
+	}
+	command, err := healthcheckCommand(s.HealthCheck)
+	if err != nil {
+		return fmt.Errorf("service %q: %w", service, err)
+	}
+
+	var exitCode int
+	for run := 1; ; run++ {
+		exitCode, err = runHealthcheck(ctx, dockerCli, backend, project.Name, s, opts.index, command, run)
+		if err != nil {
+			return err
+		}
+		if opts.interval == 0 || run == opts.count {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(opts.interval):
+		}
+	}
+	if exitCode != 0 {
+		return cli.StatusError{StatusCode: exitCode}
+	}
+	return nil
+}
+
+func runHealthcheck(ctx context.Context, dockerCli command.Cli, backend api.Service, projectName string, service types.ServiceConfig, index int, command []string, run int) (int, error) {
+	if service.HealthCheck.Timeout != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(*service.HealthCheck.Timeout))
+		defer cancel()
+	}
+
+	start := time.Now()
+	exitCode, err := backend.Exec(ctx, projectName, api.RunOptions{
+		Service: service.Name,
+		Command: command,
+		Index:   index,
+	})
+	elapsed := time.Since(start).Round(time.Millisecond)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		_, _ = fmt.Fprintf(dockerCli.Err(), "#%d: unhealthy - timed out after %s\n", run, elapsed)
+		return 1, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	status := "healthy"
+	if exitCode != 0 {
+		status = "unhealthy"
+	}
+	_, _ = fmt.Fprintf(dockerCli.Err(), "#%d: %s - exit code %d in %s\n", run, status, exitCode, elapsed)
+	return exitCode, nil
+}
+
+// healthcheckCommand converts healthcheck test into the command to run in container, the way engine does
+func healthcheckCommand(healthcheck *types.HealthCheckConfig) ([]string, error) {
+	if healthcheck == nil || healthcheck.Disable || len(healthcheck.Test) == 0 {
+		return nil, fmt.Errorf("no healthcheck declared")
+	}
+	test := healthcheck.Test
+	switch test[0] {
+	case "CMD":
+		return test[1:], nil
+	case "CMD-SHELL":
+		return []string{"/bin/sh", "-c", strings.Join(test[1:], " ")}, nil
+	case "NONE":
+		return nil, fmt.Errorf("healthcheck is disabled")
+	default:
+		return nil, fmt.Errorf("unsupported healthcheck test %q", test[0])
+	}
+}
@@ -0,0 +1,44 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthcheckCommand(t *testing.T) {
+	command, err := healthcheckCommand(&types.HealthCheckConfig{Test: []string{"CMD", "curl", "-f", "http://localhost"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"curl", "-f", "http://localhost"}, command)
+
+	command, err = healthcheckCommand(&types.HealthCheckConfig{Test: []string{"CMD-SHELL", "pg_isready || exit 1"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/bin/sh", "-c", "pg_isready || exit 1"}, command)
+
+	_, err = healthcheckCommand(&types.HealthCheckConfig{Test: []string{"NONE"}})
+	assert.ErrorContains(t, err, "disabled")
+
+	_, err = healthcheckCommand(&types.HealthCheckConfig{Disable: true})
+	assert.ErrorContains(t, err, "no healthcheck")
+
+	_, err = healthcheckCommand(nil)
+	assert.ErrorContains(t, err, "no healthcheck")
+}
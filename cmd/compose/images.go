@@ -46,9 +46,9 @@ func imagesCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service
 	imgCmd := &cobra.Command{
 		Use:   "images [OPTIONS] [SERVICE...]",
 		Short: "List images used by the created containers",
-		RunE: Adapt(func(ctx context.Context, args []string) error {
+		RunE: p.withServiceGroups(dockerCli, Adapt(func(ctx context.Context, args []string) error {
 			return runImages(ctx, dockerCli, backend, opts, args)
-		}),
+		})),
 		ValidArgsFunction: completeServiceNames(dockerCli, p),
 	}
 	imgCmd.Flags().StringVar(&opts.Format, "format", "table", "Format the output. Values: [table | json]")
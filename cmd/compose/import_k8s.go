@@ -0,0 +1,82 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/spf13/cobra"
+)
+
+type importK8sOptions struct {
+	*ProjectOptions
+	Format string
+	Output string
+}
+
+func importK8sCommand(p *ProjectOptions, backend api.Service) *cobra.Command {
+	opts := importK8sOptions{
+		ProjectOptions: p,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "import-k8s [OPTIONS] MANIFEST...",
+		Short: "EXPERIMENTAL - Generate a Compose file from Kubernetes manifests",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: Adapt(func(ctx context.Context, args []string) error {
+			return runImportK8s(ctx, backend, opts, args)
+		}),
+	}
+
+	cmd.Flags().StringVar(&opts.ProjectName, "name", "", "Project name to set in the Compose file")
+	cmd.Flags().StringVar(&opts.Format, "format", "yaml", "Format the output. Values: [yaml | json]")
+	cmd.Flags().StringVarP(&opts.Output, "output", "o", "", "Save to file (default to stdout)")
+	return cmd
+}
+
+func runImportK8s(ctx context.Context, backend api.Service, opts importK8sOptions, manifests []string) error {
+	_, _ = fmt.Fprintln(os.Stderr, "import-k8s command is EXPERIMENTAL")
+	project, err := backend.ImportK8s(ctx, api.ImportK8sOptions{
+		Manifests:   manifests,
+		ProjectName: opts.ProjectName,
+	})
+	if err != nil {
+		return err
+	}
+	var content []byte
+	// configmaps are converted into configs with inline content
+	switch opts.Format {
+	case "json":
+		content, err = project.MarshalJSON(types.WithSecretContent)
+	case "yaml":
+		content, err = project.MarshalYAML(types.WithSecretContent)
+	default:
+		return fmt.Errorf("unsupported format %q", opts.Format)
+	}
+	if err != nil {
+		return err
+	}
+	if opts.Output != "" {
+		return os.WriteFile(opts.Output, content, 0o666)
+	}
+	fmt.Println(string(content))
+	return nil
+}
@@ -0,0 +1,178 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/spf13/cobra"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+type inspectOptions struct {
+	*ProjectOptions
+	format string
+	path   string
+}
+
+// serviceInspect is the model rendered by `compose inspect`
+type serviceInspect struct {
+	Name       string                 `json:"name"`
+	Config     types.ServiceConfig    `json:"config"`
+	Containers []api.ContainerSummary `json:"containers,omitempty"`
+}
+
+func inspectCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *cobra.Command {
+	opts := inspectOptions{
+		ProjectOptions: p,
+	}
+	cmd := &cobra.Command{
+		Use:   "inspect [OPTIONS] SERVICE",
+		Short: "Display the resolved configuration and runtime state of a service",
+		Args:  cli.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if opts.format != "json" && opts.format != "yaml" {
+				return fmt.Errorf("unsupported format %q", opts.format)
+			}
+			return nil
+		},
+		RunE: Adapt(func(ctx context.Context, args []string) error {
+			return runInspect(ctx, dockerCli, backend, opts, args[0])
+		}),
+		ValidArgsFunction: completeServiceNames(dockerCli, p),
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&opts.format, "format", "json", "Format the output. Values: [json | yaml]")
+	flags.StringVar(&opts.path, "path", "", "Only display the value at JSONPath (e.g. $.config.ports[0].published)")
+	return cmd
+}
+
+func runInspect(ctx context.Context, dockerCli command.Cli, backend api.Service, opts inspectOptions, name string) error {
+	project, _, err := opts.ToProject(ctx, dockerCli, []string{name})
+	if err != nil {
+		return err
+	}
+	project, err = project.WithServicesEnvironmentResolved(true)
+	if err != nil {
+		return err
+	}
+	service, err := project.GetService(name)
+	if err != nil {
+		return err
+	}
+
+	containers, err := backend.Ps(ctx, project.Name, api.PsOptions{
+		Project:  project,
+		All:      true,
+		Services: []string{name},
+	})
+	if err != nil {
+		return err
+	}
+
+	// render through a generic model, so that path can address any attribute by its serialized name
+	content, err := json.Marshal(serviceInspect{
+		Name:       name,
+		Config:     service,
+		Containers: containers,
+	})
+	if err != nil {
+		return err
+	}
+	var model any
+	err = json.Unmarshal(content, &model)
+	if err != nil {
+		return err
+	}
+
+	if opts.path != "" {
+		model, err = extractPath(model, opts.path)
+		if err != nil {
+			return err
+		}
+	}
+
+	content, err = formatModel(model, opts.format)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(dockerCli.Out(), strings.TrimSuffix(string(content), "\n"))
+	return err
+}
+
+// extractPath selects the value at path within model. Supported syntax is a subset of JSONPath, made of
+// dot-separated attribute names and [index] array subscripts, with an optional leading `$`
+func extractPath(model any, path string) (any, error) {
+	expr := strings.TrimPrefix(path, "$")
+	if expr != "" && expr[0] != '.' && expr[0] != '[' {
+		// allow first attribute name to omit the leading dot
+		expr = "." + expr
+	}
+	full := expr
+	for expr != "" {
+		parent := "$" + strings.TrimSuffix(full, expr)
+		switch expr[0] {
+		case '.':
+			expr = expr[1:]
+			end := strings.IndexAny(expr, ".[")
+			if end < 0 {
+				end = len(expr)
+			}
+			key := expr[:end]
+			expr = expr[end:]
+			if key == "" {
+				return nil, fmt.Errorf("invalid path %q: empty attribute name", path)
+			}
+			m, ok := model.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("invalid path %q: %s is not an object", path, parent)
+			}
+			if model, ok = m[key]; !ok {
+				return nil, fmt.Errorf("invalid path %q: no such attribute %q", path, key)
+			}
+		case '[':
+			end := strings.IndexByte(expr, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path %q: missing ']'", path)
+			}
+			index, err := strconv.Atoi(expr[1:end])
+			if err != nil {
+				return nil, fmt.Errorf("invalid path %q: %q is not a valid index", path, expr[1:end])
+			}
+			expr = expr[end+1:]
+			l, ok := model.([]any)
+			if !ok {
+				return nil, fmt.Errorf("invalid path %q: %s is not an array", path, parent)
+			}
+			if index < 0 || index >= len(l) {
+				return nil, fmt.Errorf("invalid path %q: index %d out of range", path, index)
+			}
+			model = l[index]
+		default:
+			return nil, fmt.Errorf("invalid path %q: unexpected %q", path, expr)
+		}
+	}
+	return model, nil
+}
@@ -0,0 +1,63 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractPath(t *testing.T) {
+	var model any
+	err := json.Unmarshal([]byte(`{
+  "name": "web",
+  "config": {
+    "image": "nginx",
+    "ports": [{"target": 80, "published": "8080"}]
+  }
+}`), &model)
+	require.NoError(t, err)
+
+	tests := []struct {
+		path string
+		want any
+		err  string
+	}{
+		{path: "$.name", want: "web"},
+		{path: ".config.image", want: "nginx"},
+		{path: "config.ports[0].published", want: "8080"},
+		{path: "$.config.ports[0]", want: map[string]any{"target": float64(80), "published": "8080"}},
+		{path: "$.config.volumes", err: `invalid path "$.config.volumes": no such attribute "volumes"`},
+		{path: "$.config.ports[1]", err: `invalid path "$.config.ports[1]": index 1 out of range`},
+		{path: "$.name[0]", err: `invalid path "$.name[0]": $.name is not an array`},
+		{path: "$.config.ports[x]", err: `invalid path "$.config.ports[x]": "x" is not a valid index`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := extractPath(model, tt.path)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -18,8 +18,12 @@ package compose
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"sort"
+	"strings"
 
+	"github.com/docker/cli/cli"
 	"github.com/docker/cli/cli/command"
 	"github.com/spf13/cobra"
 
@@ -27,10 +31,12 @@ import (
 	"github.com/docker/compose/v2/pkg/utils"
 )
 
+const defaultKillSignal = "SIGKILL"
+
 type killOptions struct {
 	*ProjectOptions
 	removeOrphans bool
-	signal        string
+	signals       []string
 }
 
 func killCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *cobra.Command {
@@ -40,30 +46,89 @@ func killCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service)
 	cmd := &cobra.Command{
 		Use:   "kill [OPTIONS] [SERVICE...]",
 		Short: "Force stop service containers",
-		RunE: Adapt(func(ctx context.Context, args []string) error {
+		RunE: p.withServiceGroups(dockerCli, Adapt(func(ctx context.Context, args []string) error {
 			return runKill(ctx, dockerCli, backend, opts, args)
-		}),
+		})),
 		ValidArgsFunction: completeServiceNames(dockerCli, p),
 	}
 
 	flags := cmd.Flags()
 	removeOrphans := utils.StringToBool(os.Getenv(ComposeRemoveOrphans))
 	flags.BoolVar(&opts.removeOrphans, "remove-orphans", removeOrphans, "Remove containers for services not defined in the Compose file")
-	flags.StringVarP(&opts.signal, "signal", "s", "SIGKILL", "SIGNAL to send to the container")
+	flags.StringArrayVarP(&opts.signals, "signal", "s", []string{defaultKillSignal}, "SIGNAL to send to the container, or SERVICE=SIGNAL to send a distinct signal to a service")
+
+	return cmd
+}
 
+// signalCommand is a lightweight alternative to kill, dedicated to sending signals to selected services
+func signalCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "signal [OPTIONS] SERVICE=SIGNAL [SERVICE=SIGNAL...]",
+		Short: "Send signals to service containers",
+		Args:  cli.RequiresMinArgs(1),
+		RunE: Adapt(func(ctx context.Context, args []string) error {
+			for _, arg := range args {
+				if !strings.Contains(arg, "=") {
+					return fmt.Errorf("invalid argument %q, expected SERVICE=SIGNAL", arg)
+				}
+			}
+			return runKill(ctx, dockerCli, backend, killOptions{
+				ProjectOptions: p,
+				signals:        args,
+			}, nil)
+		}),
+		ValidArgsFunction: completeServiceNames(dockerCli, p),
+	}
 	return cmd
 }
 
 func runKill(ctx context.Context, dockerCli command.Cli, backend api.Service, opts killOptions, services []string) error {
+	signal, serviceSignals, err := parseKillSignals(opts.signals)
+	if err != nil {
+		return err
+	}
+	if len(serviceSignals) > 0 && (len(services) > 0 || signal == "") {
+		// services with a dedicated signal are implicitly selected
+		for service := range serviceSignals {
+			if !utils.StringContains(services, service) {
+				services = append(services, service)
+			}
+		}
+		sort.Strings(services)
+	}
+	if signal == "" {
+		signal = defaultKillSignal
+	}
+
 	project, name, err := opts.projectOrName(ctx, dockerCli, services...)
 	if err != nil {
 		return err
 	}
 
 	return backend.Kill(ctx, name, api.KillOptions{
-		RemoveOrphans: opts.removeOrphans,
-		Project:       project,
-		Services:      services,
-		Signal:        opts.signal,
+		RemoveOrphans:  opts.removeOrphans,
+		Project:        project,
+		Services:       services,
+		Signal:         signal,
+		ServiceSignals: serviceSignals,
 	})
 }
+
+// parseKillSignals splits SIGNAL and SERVICE=SIGNAL values into the default signal and per-service signals.
+// The returned default signal is empty if none was set
+func parseKillSignals(values []string) (string, map[string]string, error) {
+	var signal string
+	serviceSignals := map[string]string{}
+	for _, value := range values {
+		service, sig, ok := strings.Cut(value, "=")
+		if !ok {
+			signal = value
+			continue
+		}
+		if service == "" || sig == "" {
+			return "", nil, fmt.Errorf("invalid signal %q, expected SERVICE=SIGNAL", value)
+		}
+		serviceSignals[service] = sig
+	}
+	return signal, serviceSignals, nil
+}
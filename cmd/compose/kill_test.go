@@ -0,0 +1,97 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"testing"
+
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestParseKillSignals(t *testing.T) {
+	signal, serviceSignals, err := parseKillSignals([]string{"SIGTERM", "web=SIGHUP", "worker=SIGUSR1"})
+	require.NoError(t, err)
+	assert.Equal(t, "SIGTERM", signal)
+	assert.Equal(t, map[string]string{"web": "SIGHUP", "worker": "SIGUSR1"}, serviceSignals)
+
+	_, _, err = parseKillSignals([]string{"web="})
+	assert.EqualError(t, err, `invalid signal "web=", expected SERVICE=SIGNAL`)
+}
+
+func TestKillServiceSignals(t *testing.T) {
+	tests := []struct {
+		name     string
+		signals  []string
+		services []string
+		expected api.KillOptions
+	}{
+		{
+			name:    "default signal",
+			signals: []string{defaultKillSignal},
+			expected: api.KillOptions{
+				Signal:         defaultKillSignal,
+				ServiceSignals: map[string]string{},
+			},
+		},
+		{
+			name:    "only selects services with a signal",
+			signals: []string{"worker=SIGUSR1", "web=SIGHUP"},
+			expected: api.KillOptions{
+				Services:       []string{"web", "worker"},
+				Signal:         defaultKillSignal,
+				ServiceSignals: map[string]string{"web": "SIGHUP", "worker": "SIGUSR1"},
+			},
+		},
+		{
+			name:    "default signal applies to all other services",
+			signals: []string{"SIGTERM", "web=SIGHUP"},
+			expected: api.KillOptions{
+				Signal:         "SIGTERM",
+				ServiceSignals: map[string]string{"web": "SIGHUP"},
+			},
+		},
+		{
+			name:     "adds services with a signal to selection",
+			signals:  []string{"web=SIGHUP"},
+			services: []string{"db"},
+			expected: api.KillOptions{
+				Services:       []string{"db", "web"},
+				Signal:         defaultKillSignal,
+				ServiceSignals: map[string]string{"web": "SIGHUP"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			backend := mocks.NewMockService(ctrl)
+			backend.EXPECT().Kill(gomock.Any(), "test", tt.expected).Return(nil)
+
+			opts := killOptions{
+				ProjectOptions: &ProjectOptions{ProjectName: "test"},
+				signals:        tt.signals,
+			}
+			err := runKill(context.Background(), mocks.NewMockCli(ctrl), backend, opts, tt.services)
+			require.NoError(t, err)
+		})
+	}
+}
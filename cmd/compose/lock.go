@@ -0,0 +1,202 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/compose-spec/compose-go/v2/cli"
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/distribution/reference"
+	"github.com/docker/cli/cli/command"
+	"github.com/opencontainers/go-digest"
+	"github.com/spf13/cobra"
+
+	"github.com/docker/compose/v2/pkg/compose"
+)
+
+// LockFile is the name of the file, in the project directory, pinning remote resources and images to digests
+const LockFile = "compose.lock"
+
+// lockFile pins remote resources and service images, so the project is loaded and run in the same versions until
+// locked again
+type lockFile struct {
+	// Inputs are the versions remote resources resolved to, indexed by path
+	Inputs map[string]string `json:"inputs,omitempty"`
+	// Images are the digested references service images resolved to, indexed by image
+	Images map[string]string `json:"images,omitempty"`
+}
+
+type lockOptions struct {
+	*ProjectOptions
+	quiet bool
+}
+
+func lockCommand(p *ProjectOptions, dockerCli command.Cli) *cobra.Command {
+	opts := lockOptions{
+		ProjectOptions: p,
+	}
+	cmd := &cobra.Command{
+		Use:   "lock [OPTIONS] [SERVICE...]",
+		Short: "Pin remote resources and images used by the project to digests in " + LockFile,
+		RunE: p.withServiceGroups(dockerCli, Adapt(func(ctx context.Context, args []string) error {
+			return runLock(ctx, dockerCli, opts, args)
+		})),
+		ValidArgsFunction: completeServiceNames(dockerCli, p),
+	}
+	cmd.Flags().BoolVarP(&opts.quiet, "quiet", "q", false, "Don't list locked resources")
+	return cmd
+}
+
+func runLock(ctx context.Context, dockerCli command.Cli, opts lockOptions, services []string) error {
+	if opts.Offline {
+		return errors.New("lock resolves remote resources, it can't run with --offline")
+	}
+	path, err := opts.lockFilePath()
+	if err != nil {
+		return err
+	}
+	// resolve everything again, rather than loading versions locked so far
+	opts.relock = true
+	opts.recordInputs = true
+	project, _, err := opts.ToProject(ctx, dockerCli, services, cli.WithoutEnvironmentResolution)
+	if err != nil {
+		return err
+	}
+	images, err := lockImages(project, compose.ImageDigestResolver(ctx, dockerCli.ConfigFile(), dockerCli.Client()))
+	if err != nil {
+		return err
+	}
+	lock := lockFile{
+		Inputs: opts.remoteInputs.Resolved(),
+		Images: images,
+	}
+	if err := writeLockFile(path, lock); err != nil {
+		return err
+	}
+	if opts.quiet {
+		return nil
+	}
+	resolved := maps.Clone(lock.Inputs)
+	maps.Copy(resolved, lock.Images)
+	return printPrefetched(dockerCli.Out(), resolved)
+}
+
+// lockImages resolves images of services to digested references. Images services build are not, as a registry may
+// not serve them
+func lockImages(project *types.Project, resolve func(named reference.Named) (digest.Digest, error)) (map[string]string, error) {
+	images := map[string]string{}
+	for _, name := range slices.Sorted(maps.Keys(project.Services)) {
+		service := project.Services[name]
+		if service.Image == "" || service.Build != nil {
+			continue
+		}
+		if _, ok := images[service.Image]; ok {
+			continue
+		}
+		named, err := reference.ParseDockerRef(service.Image)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := named.(reference.Canonical); !ok {
+			d, err := resolve(named)
+			if err != nil {
+				return nil, err
+			}
+			if named, err = reference.WithDigest(named, d); err != nil {
+				return nil, err
+			}
+		}
+		images[service.Image] = named.String()
+	}
+	return images, nil
+}
+
+// lockFilePath returns the path of the lock file in the project directory: the one set by --project-directory, or
+// the directory of the first Compose file, or the current directory
+func (o *ProjectOptions) lockFilePath() (string, error) {
+	dir := o.ProjectDir
+	if dir == "" && len(o.ConfigPaths) > 0 {
+		if _, err := os.Stat(o.ConfigPaths[0]); err == nil {
+			dir = filepath.Dir(o.ConfigPaths[0])
+		}
+	}
+	if dir == "" {
+		dir = "."
+	}
+	return filepath.Abs(filepath.Join(dir, LockFile))
+}
+
+// readLockFile returns the lock file at path, or nil if there is none
+func readLockFile(path string) (*lockFile, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var lock lockFile
+	if err := json.Unmarshal(b, &lock); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	return &lock, nil
+}
+
+func writeLockFile(path string, lock lockFile) error {
+	b, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
+
+// applyLockFile pins remote resources and images to the versions locked in the project directory, unless locking
+// them again
+func (o *ProjectOptions) applyLockFile() error {
+	if o.relock {
+		return nil
+	}
+	path, err := o.lockFilePath()
+	if err != nil {
+		return err
+	}
+	o.locked, err = readLockFile(path)
+	if err != nil || o.locked == nil {
+		return err
+	}
+	o.remoteInputs.Lock(o.locked.Inputs)
+	return nil
+}
+
+// pinImage returns the digested reference image is locked to, or image if it isn't locked
+func (l *lockFile) pinImage(image string) string {
+	if l == nil {
+		return image
+	}
+	if pinned, ok := l.Images[image]; ok {
+		return pinned
+	}
+	return image
+}
@@ -0,0 +1,79 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockImages(t *testing.T) {
+	sum := digest.SHA256.FromString("nginx")
+	project := &types.Project{
+		Services: types.Services{
+			"web":   {Name: "web", Image: "nginx:1.25"},
+			"proxy": {Name: "proxy", Image: "nginx:1.25"},
+			"app":   {Name: "app", Image: "example/app", Build: &types.BuildConfig{Context: "."}},
+			"db":    {Name: "db", Image: "postgres@" + sum.String()},
+		},
+	}
+	var resolved []string
+	images, err := lockImages(project, func(named reference.Named) (digest.Digest, error) {
+		resolved = append(resolved, named.String())
+		if named.Name() != "docker.io/library/nginx" {
+			return "", fmt.Errorf("unexpected image %s", named)
+		}
+		return sum, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"docker.io/library/nginx:1.25"}, resolved)
+	assert.Equal(t, map[string]string{
+		"nginx:1.25":               "docker.io/library/nginx:1.25@" + sum.String(),
+		"postgres@" + sum.String(): "docker.io/library/postgres@" + sum.String(),
+	}, images)
+}
+
+func TestLockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), LockFile)
+	lock, err := readLockFile(path)
+	require.NoError(t, err)
+	assert.Nil(t, lock)
+	assert.Equal(t, "nginx", lock.pinImage("nginx"))
+
+	err = writeLockFile(path, lockFile{
+		Inputs: map[string]string{"oci://example.com/app:1.0": "sha256:aaa"},
+		Images: map[string]string{"nginx": "docker.io/library/nginx@sha256:bbb"},
+	})
+	require.NoError(t, err)
+	lock, err = readLockFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:aaa", lock.Inputs["oci://example.com/app:1.0"])
+	assert.Equal(t, "docker.io/library/nginx@sha256:bbb", lock.pinImage("nginx"))
+	assert.Equal(t, "redis", lock.pinImage("redis"))
+
+	o := ProjectOptions{ProjectDir: filepath.Dir(path)}
+	lockPath, err := o.lockFilePath()
+	require.NoError(t, err)
+	assert.Equal(t, path, lockPath)
+}
@@ -38,6 +38,8 @@ type logsOptions struct {
 	noColor    bool
 	noPrefix   bool
 	timestamps bool
+	merge      bool
+	level      string
 }
 
 func logsCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *cobra.Command {
@@ -47,9 +49,9 @@ func logsCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service)
 	logsCmd := &cobra.Command{
 		Use:   "logs [OPTIONS] [SERVICE...]",
 		Short: "View output from containers",
-		RunE: Adapt(func(ctx context.Context, args []string) error {
+		RunE: p.withServiceGroups(dockerCli, Adapt(func(ctx context.Context, args []string) error {
 			return runLogs(ctx, dockerCli, backend, opts, args)
-		}),
+		})),
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			if opts.index > 0 && len(args) != 1 {
 				return errors.New("--index requires one service to be selected")
@@ -66,6 +68,8 @@ func logsCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service)
 	flags.BoolVar(&opts.noColor, "no-color", false, "Produce monochrome output")
 	flags.BoolVar(&opts.noPrefix, "no-log-prefix", false, "Don't print prefix in logs")
 	flags.BoolVarP(&opts.timestamps, "timestamps", "t", false, "Show timestamps")
+	flags.BoolVar(&opts.merge, "merge-by-timestamp", false, "Interleave log lines from all containers by their timestamp rather than arrival order")
+	flags.StringVar(&opts.level, "level", "", "Only show log lines of this level or higher (trace, debug, info, warn, error, fatal), as detected from JSON, logfmt or syslog formats")
 	flags.StringVarP(&opts.tail, "tail", "n", "all", "Number of lines to show from the end of the logs for each container")
 	return logsCmd
 }
@@ -76,6 +80,9 @@ func runLogs(ctx context.Context, dockerCli command.Cli, backend api.Service, op
 		return err
 	}
 
+	// host processes are only shown when services are not explicitly selected
+	hostProcesses := len(services) == 0 && opts.index == 0
+
 	// exclude services configured to ignore output (attach: false), until explicitly selected
 	if project != nil && len(services) == 0 {
 		for n, service := range project.Services {
@@ -85,15 +92,21 @@ func runLogs(ctx context.Context, dockerCli command.Cli, backend api.Service, op
 		}
 	}
 
-	consumer := formatter.NewLogConsumer(ctx, dockerCli.Out(), dockerCli.Err(), !opts.noColor, !opts.noPrefix, false)
+	level, err := formatter.ParseLogLevel(opts.level)
+	if err != nil {
+		return err
+	}
+	consumer := formatter.NewLevelLogConsumer(ctx, dockerCli.Out(), dockerCli.Err(), !opts.noColor, !opts.noPrefix, false, level)
 	return backend.Logs(ctx, name, consumer, api.LogOptions{
-		Project:    project,
-		Services:   services,
-		Follow:     opts.follow,
-		Index:      opts.index,
-		Tail:       opts.tail,
-		Since:      opts.since,
-		Until:      opts.until,
-		Timestamps: opts.timestamps,
+		Project:          project,
+		Services:         services,
+		Follow:           opts.follow,
+		Index:            opts.index,
+		Tail:             opts.tail,
+		Since:            opts.since,
+		Until:            opts.until,
+		Timestamps:       opts.timestamps,
+		MergeByTimestamp: opts.merge,
+		HostProcesses:    hostProcesses,
 	})
 }
@@ -30,13 +30,14 @@ import (
 	"github.com/compose-spec/compose-go/v2/types"
 	"github.com/docker/cli/cli/command"
 	"github.com/docker/compose/v2/internal/tracing"
+	"github.com/docker/compose/v2/pkg/api"
 	ui "github.com/docker/compose/v2/pkg/progress"
 	"github.com/docker/compose/v2/pkg/prompt"
 	"github.com/docker/compose/v2/pkg/utils"
 )
 
 func applyPlatforms(project *types.Project, buildForSinglePlatform bool) error {
-	defaultPlatform := project.Environment["DOCKER_DEFAULT_PLATFORM"]
+	defaultPlatform, variable := api.DefaultPlatform(project.Environment)
 	for name, service := range project.Services {
 		if service.Build == nil {
 			continue
@@ -45,7 +46,7 @@ func applyPlatforms(project *types.Project, buildForSinglePlatform bool) error {
 		// default platform only applies if the service doesn't specify
 		if defaultPlatform != "" && service.Platform == "" {
 			if len(service.Build.Platforms) > 0 && !utils.StringContains(service.Build.Platforms, defaultPlatform) {
-				return fmt.Errorf("service %q build.platforms does not support value set by DOCKER_DEFAULT_PLATFORM: %s", name, defaultPlatform)
+				return fmt.Errorf("service %q build.platforms does not support value set by %s: %s", name, variable, defaultPlatform)
 			}
 			service.Platform = defaultPlatform
 		}
@@ -36,9 +36,9 @@ func pauseCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service)
 	cmd := &cobra.Command{
 		Use:   "pause [SERVICE...]",
 		Short: "Pause services",
-		RunE: Adapt(func(ctx context.Context, args []string) error {
+		RunE: p.withServiceGroups(dockerCli, Adapt(func(ctx context.Context, args []string) error {
 			return runPause(ctx, dockerCli, backend, opts, args)
-		}),
+		})),
 		ValidArgsFunction: completeServiceNames(dockerCli, p),
 	}
 	return cmd
@@ -67,9 +67,9 @@ func unpauseCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Servic
 	cmd := &cobra.Command{
 		Use:   "unpause [SERVICE...]",
 		Short: "Unpause services",
-		RunE: Adapt(func(ctx context.Context, args []string) error {
+		RunE: p.withServiceGroups(dockerCli, Adapt(func(ctx context.Context, args []string) error {
 			return runUnPause(ctx, dockerCli, backend, opts, args)
-		}),
+		})),
 		ValidArgsFunction: completeServiceNames(dockerCli, p),
 	}
 	return cmd
@@ -0,0 +1,106 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+type portForwardOptions struct {
+	*ProjectOptions
+	forwards []api.PortForwardOptions
+	address  string
+	index    int
+	image    string
+}
+
+func portForwardCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *cobra.Command {
+	opts := portForwardOptions{
+		ProjectOptions: p,
+	}
+	cmd := &cobra.Command{
+		Use:   "port-forward [OPTIONS] SERVICE:PORT[:LOCAL_PORT]...",
+		Short: "Forward local ports to service ports, published or not",
+		Args:  cobra.MinimumNArgs(1),
+		PreRunE: Adapt(func(ctx context.Context, args []string) error {
+			for _, arg := range args {
+				forward, err := parsePortForward(arg)
+				if err != nil {
+					return err
+				}
+				opts.forwards = append(opts.forwards, forward)
+			}
+			return nil
+		}),
+		RunE: Adapt(func(ctx context.Context, args []string) error {
+			return runPortForward(ctx, dockerCli, backend, opts)
+		}),
+		ValidArgsFunction: completeServiceNames(dockerCli, p),
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&opts.address, "address", "127.0.0.1", "Local address to listen on")
+	flags.IntVar(&opts.index, "index", 0, "Index of the container if service has multiple replicas")
+	flags.StringVar(&opts.image, "image", "", "Image running socat to relay connections (default: nicolaka/netshoot:v0.13)")
+	return cmd
+}
+
+// parsePortForward parses a SERVICE:PORT[:LOCAL_PORT] forward
+func parsePortForward(spec string) (api.PortForwardOptions, error) {
+	parts := strings.Split(spec, ":")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" {
+		return api.PortForwardOptions{}, fmt.Errorf("invalid port forward %q, expected SERVICE:PORT[:LOCAL_PORT]", spec)
+	}
+	port, err := strconv.ParseUint(parts[1], 10, 16)
+	if err != nil || port == 0 {
+		return api.PortForwardOptions{}, fmt.Errorf("invalid port %q in %s", parts[1], spec)
+	}
+	forward := api.PortForwardOptions{Service: parts[0], Port: uint16(port)}
+	if len(parts) == 3 {
+		local, err := strconv.ParseUint(parts[2], 10, 16)
+		if err != nil {
+			return api.PortForwardOptions{}, fmt.Errorf("invalid local port %q in %s", parts[2], spec)
+		}
+		forward.LocalPort = uint16(local)
+	}
+	return forward, nil
+}
+
+func runPortForward(ctx context.Context, dockerCli command.Cli, backend api.Service, opts portForwardOptions) error {
+	projectName, err := opts.toProjectName(ctx, dockerCli)
+	if err != nil {
+		return err
+	}
+	eg, ctx := errgroup.WithContext(ctx)
+	for _, forward := range opts.forwards {
+		forward.Address = opts.address
+		forward.Index = opts.index
+		forward.Image = opts.image
+		eg.Go(func() error {
+			return backend.PortForward(ctx, projectName, forward)
+		})
+	}
+	return eg.Wait()
+}
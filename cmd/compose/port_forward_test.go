@@ -0,0 +1,43 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+func TestParsePortForward(t *testing.T) {
+	forward, err := parsePortForward("db:5432")
+	require.NoError(t, err)
+	assert.Equal(t, api.PortForwardOptions{Service: "db", Port: 5432}, forward)
+
+	forward, err = parsePortForward("web:80:8080")
+	require.NoError(t, err)
+	assert.Equal(t, api.PortForwardOptions{Service: "web", Port: 80, LocalPort: 8080}, forward)
+
+	_, err = parsePortForward("web")
+	require.EqualError(t, err, `invalid port forward "web", expected SERVICE:PORT[:LOCAL_PORT]`)
+	_, err = parsePortForward("web:http")
+	require.EqualError(t, err, `invalid port "http" in web:http`)
+	_, err = parsePortForward("web:80:-1")
+	require.EqualError(t, err, `invalid local port "-1" in web:80:-1`)
+}
@@ -0,0 +1,85 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/compose-spec/compose-go/v2/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/spf13/cobra"
+)
+
+type prefetchOptions struct {
+	*ProjectOptions
+	quiet bool
+}
+
+func prefetchCommand(p *ProjectOptions, dockerCli command.Cli) *cobra.Command {
+	opts := prefetchOptions{
+		ProjectOptions: p,
+	}
+	cmd := &cobra.Command{
+		Use:   "prefetch [OPTIONS]",
+		Short: "Download remote resources referenced by the project into the cache",
+		Args:  cobra.NoArgs,
+		RunE: Adapt(func(ctx context.Context, args []string) error {
+			return runPrefetch(ctx, dockerCli, opts)
+		}),
+	}
+	cmd.Flags().BoolVarP(&opts.quiet, "quiet", "q", false, "Don't list fetched resources")
+	return cmd
+}
+
+func runPrefetch(ctx context.Context, dockerCli command.Cli, opts prefetchOptions) error {
+	if opts.Offline {
+		return errors.New("prefetch downloads remote resources, it can't run with --offline")
+	}
+	// loading the project resolves includes and extends, pulling remote resources into the cache
+	_, _, err := opts.ToProject(ctx, dockerCli, nil, cli.WithoutEnvironmentResolution)
+	if err != nil {
+		return err
+	}
+	if opts.quiet {
+		return nil
+	}
+	return printPrefetched(dockerCli.Out(), opts.remoteInputs.Resolved())
+}
+
+// printPrefetched lists remote resources with the version they resolved to
+func printPrefetched(out io.Writer, resolved map[string]string) error {
+	if len(resolved) == 0 {
+		_, err := fmt.Fprintln(out, "No remote resource referenced by the project")
+		return err
+	}
+	paths := make([]string, 0, len(resolved))
+	for path := range resolved {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	_, _ = fmt.Fprintln(w, "RESOURCE\tVERSION")
+	for _, path := range paths {
+		_, _ = fmt.Fprintf(w, "%s\t%s\n", path, resolved[path])
+	}
+	return w.Flush()
+}
@@ -0,0 +1,41 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintPrefetched(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, printPrefetched(&out, map[string]string{
+		"oci://docker.io/acme/stack:1.0": "sha256:8e0f",
+		"git@github.com:acme/base.git":   "5f2c1a9",
+	}))
+	assert.Equal(t, `RESOURCE                         VERSION
+git@github.com:acme/base.git     5f2c1a9
+oci://docker.io/acme/stack:1.0   sha256:8e0f
+`, out.String())
+
+	out.Reset()
+	require.NoError(t, printPrefetched(&out, map[string]string{}))
+	assert.Equal(t, "No remote resource referenced by the project\n", out.String())
+}
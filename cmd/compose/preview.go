@@ -0,0 +1,277 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/compose-spec/compose-go/v2/cli"
+	"github.com/compose-spec/compose-go/v2/loader"
+	"github.com/compose-spec/compose-go/v2/types"
+	dockercli "github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/spf13/cobra"
+
+	"github.com/docker/compose/v2/cmd/formatter"
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+const (
+	// ComposePreviewID is set for interpolation to the identifier of the preview environment being deployed
+	ComposePreviewID = "COMPOSE_PREVIEW_ID"
+	// ComposePreviewProject is set for interpolation to the project name of the preview environment being deployed
+	ComposePreviewProject = "COMPOSE_PREVIEW_PROJECT"
+)
+
+type previewOptions struct {
+	*ProjectOptions
+	build       bool
+	keepPorts   bool
+	host        string
+	format      string
+	waitTimeout int
+}
+
+func previewCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *cobra.Command {
+	opts := previewOptions{
+		ProjectOptions: p,
+	}
+	cmd := &cobra.Command{
+		Use:   "preview [OPTIONS] ID",
+		Short: "Deploy an ephemeral preview environment, for example per pull request",
+		Args:  dockercli.ExactArgs(1),
+		RunE: Adapt(func(ctx context.Context, args []string) error {
+			return runPreview(ctx, dockerCli, backend, opts, args[0])
+		}),
+	}
+	flags := cmd.Flags()
+	flags.BoolVar(&opts.build, "build", false, "Build images before starting containers")
+	flags.BoolVar(&opts.keepPorts, "keep-ports", false, "Publish ports on the host ports declared by services, rather than on random ones")
+	flags.StringVar(&opts.host, "host", "", "Template of the hostname services are reached at through an ingress, e.g. {{.Service}}-{{.ID}}.preview.example.com")
+	flags.StringVar(&opts.format, "format", "table", "Format the output. Values: [table | json]")
+	flags.IntVar(&opts.waitTimeout, "wait-timeout", 0, "Maximum duration in seconds to wait for the environment to be running|healthy")
+	cmd.AddCommand(previewDestroyCommand(p, dockerCli, backend))
+	return cmd
+}
+
+func previewDestroyCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *cobra.Command {
+	return &cobra.Command{
+		Use:   "destroy ID",
+		Short: "Remove a preview environment, including its volumes and images",
+		Args:  dockercli.ExactArgs(1),
+		RunE: Adapt(func(ctx context.Context, args []string) error {
+			return runPreviewDestroy(ctx, dockerCli, backend, p, args[0])
+		}),
+	}
+}
+
+// previewSummary describes a deployed preview environment
+type previewSummary struct {
+	ID        string            `json:"id"`
+	Project   string            `json:"project"`
+	Endpoints []previewEndpoint `json:"endpoints"`
+}
+
+// previewEndpoint is an address a service of a preview environment is reached at
+type previewEndpoint struct {
+	Service  string `json:"service"`
+	URL      string `json:"url"`
+	Target   int    `json:"target,omitempty"`
+	Protocol string `json:"protocol,omitempty"`
+}
+
+func runPreview(ctx context.Context, dockerCli command.Cli, backend api.Service, opts previewOptions, raw string) error {
+	var host *template.Template
+	if opts.host != "" {
+		var err error
+		host, err = template.New("host").Option("missingkey=error").Parse(opts.host)
+		if err != nil {
+			return fmt.Errorf("invalid --host template: %w", err)
+		}
+	}
+
+	project, id, err := loadPreviewProject(ctx, dockerCli, opts.ProjectOptions, raw)
+	if err != nil {
+		return err
+	}
+	if !opts.keepPorts {
+		randomizePublishedPorts(project)
+	}
+
+	err = runUp(ctx, dockerCli, backend,
+		createOptions{Build: opts.build, removeOrphans: true, AssumeYes: true},
+		upOptions{composeOptions: &composeOptions{ProjectOptions: opts.ProjectOptions}, Detach: true, wait: true, waitTimeout: opts.waitTimeout},
+		buildOptions{ProjectOptions: opts.ProjectOptions},
+		project, nil)
+	if err != nil {
+		return err
+	}
+
+	containers, err := backend.Ps(ctx, project.Name, api.PsOptions{Project: project})
+	if err != nil {
+		return err
+	}
+	summary, err := previewEndpoints(project, id, containers, host)
+	if err != nil {
+		return err
+	}
+	return formatter.Print(summary, opts.format, dockerCli.Out(), func(w io.Writer) {
+		for _, endpoint := range summary.Endpoints {
+			_, _ = fmt.Fprintf(w, "%s\t%s\n", endpoint.Service, endpoint.URL)
+		}
+	}, "SERVICE", "ENDPOINT")
+}
+
+func runPreviewDestroy(ctx context.Context, dockerCli command.Cli, backend api.Service, p *ProjectOptions, raw string) error {
+	project, _, err := loadPreviewProject(ctx, dockerCli, p, raw)
+	if err != nil {
+		return err
+	}
+	return backend.Down(ctx, project.Name, api.DownOptions{
+		Project:       project,
+		RemoveOrphans: true,
+		Volumes:       true,
+		Images:        "local",
+	})
+}
+
+// loadPreviewProject loads the project deployed as preview environment raw, named after the project and the preview
+// identifier so that environments don't conflict
+func loadPreviewProject(ctx context.Context, dockerCli command.Cli, p *ProjectOptions, raw string) (*types.Project, string, error) {
+	id := previewID(raw)
+	if id == "" {
+		return nil, "", fmt.Errorf("invalid preview identifier %q", raw)
+	}
+	base := p.ProjectName
+	if base == "" {
+		base = os.Getenv(ComposeProjectName)
+	}
+	if base == "" {
+		project, _, err := p.ToProject(ctx, dockerCli, nil, cli.WithEnv([]string{ComposePreviewID + "=" + id}))
+		if err != nil {
+			return nil, "", err
+		}
+		base = project.Name
+	}
+	p.ProjectName = previewProjectName(base, id)
+	project, _, err := p.ToProject(ctx, dockerCli, nil, cli.WithEnv([]string{
+		ComposePreviewID + "=" + id,
+		ComposePreviewProject + "=" + p.ProjectName,
+	}))
+	return project, id, err
+}
+
+// previewID converts an identifier, like a pull request number or a branch name, into a valid project name suffix
+func previewID(raw string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(raw) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && b.Len() > 0 {
+			b.WriteRune('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+func previewProjectName(base, id string) string {
+	return loader.NormalizeProjectName(base + "-" + id)
+}
+
+// randomizePublishedPorts lets the engine select host ports, so that preview environments of the same project can run
+// side by side
+func randomizePublishedPorts(project *types.Project) {
+	for name, service := range project.Services {
+		for i := range service.Ports {
+			service.Ports[i].Published = ""
+		}
+		project.Services[name] = service
+	}
+}
+
+// previewEndpoints lists addresses ports published by containers are reached at, and hostnames rendered from host for
+// services declaring ports
+func previewEndpoints(project *types.Project, id string, containers []api.ContainerSummary, host *template.Template) (previewSummary, error) {
+	summary := previewSummary{
+		ID:        id,
+		Project:   project.Name,
+		Endpoints: []previewEndpoint{},
+	}
+	seen := map[string]bool{}
+	for _, c := range containers {
+		for _, publisher := range c.Publishers {
+			if publisher.PublishedPort == 0 {
+				continue
+			}
+			address := publisher.URL
+			if ip := net.ParseIP(address); address == "" || (ip != nil && ip.IsUnspecified()) {
+				address = "localhost"
+			}
+			endpoint := previewEndpoint{
+				Service:  c.Service,
+				URL:      net.JoinHostPort(address, strconv.Itoa(publisher.PublishedPort)),
+				Target:   publisher.TargetPort,
+				Protocol: publisher.Protocol,
+			}
+			key := endpoint.Service + " " + endpoint.URL + " " + endpoint.Protocol
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			summary.Endpoints = append(summary.Endpoints, endpoint)
+		}
+	}
+	if host != nil {
+		for _, name := range project.ServiceNames() {
+			service := project.Services[name]
+			if len(service.Ports) == 0 && len(service.Expose) == 0 {
+				continue
+			}
+			var b bytes.Buffer
+			err := host.Execute(&b, map[string]string{
+				"ID":      id,
+				"Project": project.Name,
+				"Service": name,
+			})
+			if err != nil {
+				return summary, fmt.Errorf("invalid --host template: %w", err)
+			}
+			summary.Endpoints = append(summary.Endpoints, previewEndpoint{
+				Service: name,
+				URL:     b.String(),
+			})
+		}
+	}
+	slices.SortStableFunc(summary.Endpoints, func(a, b previewEndpoint) int {
+		return strings.Compare(a.Service, b.Service)
+	})
+	return summary, nil
+}
@@ -0,0 +1,68 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"testing"
+	"text/template"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+func TestPreviewID(t *testing.T) {
+	assert.Equal(t, "123", previewID("123"))
+	assert.Equal(t, "feature-login-form", previewID("Feature/Login__Form/"))
+	assert.Equal(t, "", previewID("//"))
+	assert.Equal(t, "myapp-feature-x", previewProjectName("MyApp", previewID("feature/x")))
+}
+
+func TestPreviewEndpoints(t *testing.T) {
+	project := &types.Project{
+		Name: "myapp-42",
+		Services: types.Services{
+			"api": {Name: "api", Expose: types.StringOrNumberList{"8080"}},
+			"db":  {Name: "db"},
+			"web": {Name: "web", Ports: []types.ServicePortConfig{{Target: 80, Published: "8080"}}},
+		},
+	}
+	randomizePublishedPorts(project)
+	assert.Equal(t, "", project.Services["web"].Ports[0].Published)
+
+	containers := []api.ContainerSummary{
+		{Service: "web", Publishers: api.PortPublishers{
+			{URL: "0.0.0.0", TargetPort: 80, PublishedPort: 32768, Protocol: "tcp"},
+			{URL: "::", TargetPort: 80, PublishedPort: 32768, Protocol: "tcp"},
+		}},
+		{Service: "db", Publishers: api.PortPublishers{{TargetPort: 5432, Protocol: "tcp"}}},
+	}
+	host := template.Must(template.New("host").Parse("{{.Service}}-{{.ID}}.preview.example.com"))
+	summary, err := previewEndpoints(project, "42", containers, host)
+	require.NoError(t, err)
+	assert.Equal(t, previewSummary{
+		ID:      "42",
+		Project: "myapp-42",
+		Endpoints: []previewEndpoint{
+			{Service: "api", URL: "api-42.preview.example.com"},
+			{Service: "web", URL: "localhost:32768", Target: 80, Protocol: "tcp"},
+			{Service: "web", URL: "web-42.preview.example.com"},
+		},
+	}, summary)
+}
@@ -0,0 +1,179 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/docker/compose/v2/pkg/remote"
+)
+
+// projectGroup is a set of compose projects, typically from several repositories, managed together
+type projectGroup struct {
+	Projects map[string]groupProject `yaml:"projects"`
+}
+
+type groupProject struct {
+	// Path is the project directory, a compose file or an OCI artifact reference
+	Path string `yaml:"path,omitempty"`
+	// Files are the compose files for this project, as an alternative to Path
+	Files []string `yaml:"files,omitempty"`
+	// DependsOn lists projects from the group to be started before this one
+	DependsOn []string `yaml:"depends_on,omitempty"`
+}
+
+func loadProjectGroup(file string) (*projectGroup, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var group projectGroup
+	if err := yaml.Unmarshal(b, &group); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	if len(group.Projects) == 0 {
+		return nil, fmt.Errorf("%s: no project declared", file)
+	}
+
+	base := filepath.Dir(file)
+	resolve := func(path string) string {
+		if strings.HasPrefix(path, remote.OciPrefix) || filepath.IsAbs(path) {
+			return path
+		}
+		return filepath.Join(base, path)
+	}
+	for name, p := range group.Projects {
+		if (p.Path == "") == (len(p.Files) == 0) {
+			return nil, fmt.Errorf("%s: project %s must set either path or files", file, name)
+		}
+		if p.Path != "" {
+			p.Path = resolve(p.Path)
+		}
+		for i, f := range p.Files {
+			p.Files[i] = resolve(f)
+		}
+		for _, dep := range p.DependsOn {
+			if _, ok := group.Projects[dep]; !ok {
+				return nil, fmt.Errorf("%s: project %s depends on undefined project %s", file, name, dep)
+			}
+		}
+		group.Projects[name] = p
+	}
+	return &group, nil
+}
+
+// order returns the group projects, dependencies first
+func (g *projectGroup) order() ([]string, error) {
+	names := make([]string, 0, len(g.Projects))
+	for name := range g.Projects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var (
+		ordered []string
+		visit   func(name string, path []string) error
+		done    = map[string]bool{}
+	)
+	visit = func(name string, path []string) error {
+		if done[name] {
+			return nil
+		}
+		if slices.Contains(path, name) {
+			return fmt.Errorf("dependency cycle between projects: %s", strings.Join(append(path, name), " -> "))
+		}
+		deps := slices.Clone(g.Projects[name].DependsOn)
+		sort.Strings(deps)
+		for _, dep := range deps {
+			if err := visit(dep, append(path, name)); err != nil {
+				return err
+			}
+		}
+		done[name] = true
+		ordered = append(ordered, name)
+		return nil
+	}
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// configure makes project options target a project from the group
+func (p groupProject) configure(o *ProjectOptions) {
+	o.ConfigPaths = p.Files
+	o.ProjectDir = ""
+	if p.Path == "" {
+		return
+	}
+	if fi, err := os.Stat(p.Path); err == nil && fi.IsDir() {
+		o.ConfigPaths = nil
+		o.ProjectDir = p.Path
+		return
+	}
+	o.ConfigPaths = []string{p.Path}
+}
+
+// withProjectGroup runs fn for each project of the group set by --project-group, in dependency order, or
+// reverse order to tear down. Without a project group, fn runs once for the project set by other flags
+func (o *ProjectOptions) withProjectGroup(reverse bool, fn func(cmd *cobra.Command, args []string) error) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if o.ProjectGroup == "" {
+			return fn(cmd, args)
+		}
+		if len(o.ConfigPaths) > 0 || o.ProjectName != "" || o.ProjectDir != "" {
+			return errors.New("--project-group can't be combined with --file, --project-name or --project-directory")
+		}
+		if len(args) > 0 {
+			return errors.New("services can't be selected with --project-group")
+		}
+		group, err := loadProjectGroup(o.ProjectGroup)
+		if err != nil {
+			return err
+		}
+		names, err := group.order()
+		if err != nil {
+			return err
+		}
+		if reverse {
+			slices.Reverse(names)
+		}
+
+		defer func() {
+			o.ConfigPaths = nil
+			o.ProjectDir = ""
+		}()
+		for _, name := range names {
+			group.Projects[name].configure(o)
+			if err := fn(cmd, args); err != nil {
+				return fmt.Errorf("project %s: %w", name, err)
+			}
+		}
+		return nil
+	}
+}
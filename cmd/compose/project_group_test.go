@@ -0,0 +1,74 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectGroup(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "backend"), 0o700))
+	groupFile := filepath.Join(dir, "group.yaml")
+	require.NoError(t, os.WriteFile(groupFile, []byte(`
+projects:
+  frontend:
+    files: [frontend/compose.yaml, frontend/compose.dev.yaml]
+    depends_on: [backend]
+  backend:
+    path: backend
+    depends_on: [infra]
+  infra:
+    path: oci://registry.example.com/infra:1.0
+`), 0o600))
+
+	group, err := loadProjectGroup(groupFile)
+	require.NoError(t, err)
+	order, err := group.order()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"infra", "backend", "frontend"}, order)
+
+	o := &ProjectOptions{ProjectGroup: groupFile}
+	var loaded [][]string
+	run := o.withProjectGroup(true, func(cmd *cobra.Command, args []string) error {
+		loaded = append(loaded, append([]string{o.ProjectDir}, o.ConfigPaths...))
+		return nil
+	})
+	require.NoError(t, run(nil, nil))
+	assert.Equal(t, [][]string{
+		{"", filepath.Join(dir, "frontend/compose.yaml"), filepath.Join(dir, "frontend/compose.dev.yaml")},
+		{filepath.Join(dir, "backend")},
+		{"", "oci://registry.example.com/infra:1.0"},
+	}, loaded)
+
+	assert.ErrorContains(t, run(nil, []string{"web"}), "services can't be selected")
+}
+
+func TestProjectGroupCycle(t *testing.T) {
+	group := &projectGroup{Projects: map[string]groupProject{
+		"a": {Path: "a", DependsOn: []string{"b"}},
+		"b": {Path: "b", DependsOn: []string{"a"}},
+	}}
+	_, err := group.order()
+	assert.ErrorContains(t, err, "dependency cycle between projects: a -> b -> a")
+}
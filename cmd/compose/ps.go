@@ -18,11 +18,13 @@ package compose
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"sort"
 	"strings"
 
+	"github.com/compose-spec/compose-go/v2/types"
 	"github.com/docker/compose/v2/cmd/formatter"
 	"github.com/docker/compose/v2/pkg/api"
 	"github.com/docker/compose/v2/pkg/utils"
@@ -43,6 +45,7 @@ type psOptions struct {
 	Status   []string
 	noTrunc  bool
 	Orphans  bool
+	Watch    bool
 }
 
 func (p *psOptions) parseFilter() error {
@@ -72,11 +75,14 @@ func psCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *c
 		Use:   "ps [OPTIONS] [SERVICE...]",
 		Short: "List containers",
 		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if opts.Watch && p.ProjectGroup != "" {
+				return errors.New("--watch can't be combined with --project-group")
+			}
 			return opts.parseFilter()
 		},
-		RunE: Adapt(func(ctx context.Context, args []string) error {
+		RunE: p.withServiceGroups(dockerCli, p.withProjectGroup(false, Adapt(func(ctx context.Context, args []string) error {
 			return runPs(ctx, dockerCli, backend, args, opts)
-		}),
+		}))),
 		ValidArgsFunction: completeServiceNames(dockerCli, p),
 	}
 	flags := psCmd.Flags()
@@ -88,6 +94,7 @@ func psCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *c
 	flags.BoolVar(&opts.Orphans, "orphans", true, "Include orphaned services (not declared by project)")
 	flags.BoolVarP(&opts.All, "all", "a", false, "Show all stopped containers (including those created by the run command)")
 	flags.BoolVar(&opts.noTrunc, "no-trunc", false, "Don't truncate output")
+	flags.BoolVar(&opts.Watch, "watch", false, "Watch containers and refresh output as they change state or health. With --format json, emit change events")
 	return psCmd
 }
 
@@ -111,13 +118,27 @@ func runPs(ctx context.Context, dockerCli command.Cli, backend api.Service, serv
 		}
 	}
 
+	if opts.Watch {
+		return watchPs(ctx, dockerCli, backend, name, project, services, opts)
+	}
+
+	containers, err := listPs(ctx, backend, name, project, services, opts)
+	if err != nil {
+		return err
+	}
+	return writePs(dockerCli, containers, opts)
+}
+
+func listPs(ctx context.Context, backend api.Service, name string, project *types.Project, services []string, opts psOptions) ([]api.ContainerSummary, error) {
 	containers, err := backend.Ps(ctx, name, api.PsOptions{
 		Project:  project,
 		All:      opts.All || len(opts.Status) != 0,
 		Services: services,
+		// host processes have no container ID
+		HostProcesses: !opts.Quiet && len(services) == 0,
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if len(opts.Status) != 0 {
@@ -127,7 +148,10 @@ func runPs(ctx context.Context, dockerCli command.Cli, backend api.Service, serv
 	sort.Slice(containers, func(i, j int) bool {
 		return containers[i].Name < containers[j].Name
 	})
+	return containers, nil
+}
 
+func writePs(dockerCli command.Cli, containers []api.ContainerSummary, opts psOptions) error {
 	if opts.Quiet {
 		for _, c := range containers {
 			_, _ = fmt.Fprintln(dockerCli.Out(), c.ID)
@@ -159,6 +183,94 @@ func runPs(ctx context.Context, dockerCli command.Cli, backend api.Service, serv
 	return formatter.ContainerWrite(containerCtx, containers)
 }
 
+// psChange is emitted by `ps --watch --format json` each time a container is added, removed or changes state
+type psChange struct {
+	Change string
+	api.ContainerSummary
+}
+
+const (
+	psChangeAdded   = "added"
+	psChangeUpdated = "updated"
+	psChangeRemoved = "removed"
+)
+
+// watchPs renders containers, then refreshes output each time engine reports an event for project's containers.
+// With JSON format, a stream of psChange is emitted instead of the full list
+func watchPs(ctx context.Context, dockerCli command.Cli, backend api.Service, name string, project *types.Project, services []string, opts psOptions) error {
+	var previous []api.ContainerSummary
+	refreshed := false
+	refresh := func() error {
+		containers, err := listPs(ctx, backend, name, project, services, opts)
+		if err != nil {
+			return err
+		}
+		if opts.Format == formatter.JSON {
+			for _, change := range psChanges(previous, containers) {
+				marshal, err := json.Marshal(change)
+				if err != nil {
+					return err
+				}
+				_, _ = fmt.Fprintln(dockerCli.Out(), string(marshal))
+			}
+		} else {
+			switch {
+			case dockerCli.Out().IsTerminal():
+				// clear screen and move cursor to top-left before rendering the refreshed list
+				_, _ = fmt.Fprint(dockerCli.Out(), "\033[2J\033[H")
+			case refreshed:
+				// output is piped or redirected, separate refreshed lists with an empty line
+				_, _ = fmt.Fprintln(dockerCli.Out())
+			}
+			refreshed = true
+			if err := writePs(dockerCli, containers, opts); err != nil {
+				return err
+			}
+		}
+		previous = containers
+		return nil
+	}
+
+	if err := refresh(); err != nil {
+		return err
+	}
+	return backend.Events(ctx, name, api.EventsOptions{
+		Services: services,
+		Consumer: func(event api.Event) error {
+			if strings.HasPrefix(event.Status, "exec_") {
+				// exec events don't change container state
+				return nil
+			}
+			return refresh()
+		},
+	})
+}
+
+// psChanges computes the changes from previous to current containers list
+func psChanges(previous, current []api.ContainerSummary) []psChange {
+	known := map[string]api.ContainerSummary{}
+	for _, c := range previous {
+		known[c.ID] = c
+	}
+	var changes []psChange
+	for _, c := range current {
+		before, ok := known[c.ID]
+		delete(known, c.ID)
+		switch {
+		case !ok:
+			changes = append(changes, psChange{Change: psChangeAdded, ContainerSummary: c})
+		case before.State != c.State || before.Health != c.Health || before.ExitCode != c.ExitCode:
+			changes = append(changes, psChange{Change: psChangeUpdated, ContainerSummary: c})
+		}
+	}
+	for _, c := range previous {
+		if _, ok := known[c.ID]; ok {
+			changes = append(changes, psChange{Change: psChangeRemoved, ContainerSummary: c})
+		}
+	}
+	return changes
+}
+
 func filterByStatus(containers []api.ContainerSummary, statuses []string) []api.ContainerSummary {
 	var filtered []api.ContainerSummary
 	for _, c := range containers {
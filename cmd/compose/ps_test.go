@@ -17,9 +17,11 @@
 package compose
 
 import (
+	"bytes"
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/docker/cli/cli/config/configfile"
@@ -85,3 +87,51 @@ func TestPsTable(t *testing.T) {
 
 	assert.Contains(t, string(output), "8080/tcp, 8443/tcp")
 }
+
+func TestWatchPsPiped(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	backend := mocks.NewMockService(ctrl)
+	backend.EXPECT().Ps(gomock.Any(), "test", gomock.Any()).
+		Return([]api.ContainerSummary{{ID: "abc123", Name: "ABC", Image: "foo/bar"}}, nil).Times(2)
+	backend.EXPECT().Events(gomock.Any(), "test", gomock.Any()).
+		DoAndReturn(func(ctx context.Context, projectName string, options api.EventsOptions) error {
+			return options.Consumer(api.Event{Status: "start"})
+		})
+
+	var buf bytes.Buffer
+	cli := mocks.NewMockCli(ctrl)
+	cli.EXPECT().Out().Return(streams.NewOut(&buf)).AnyTimes()
+	cli.EXPECT().ConfigFile().Return(&configfile.ConfigFile{}).AnyTimes()
+	opts := psOptions{ProjectOptions: &ProjectOptions{ProjectName: "test"}}
+	require.NoError(t, watchPs(ctx, cli, backend, "test", nil, nil, opts))
+
+	// screen isn't cleared when output isn't a terminal
+	output := buf.String()
+	assert.NotContains(t, output, "\033[2J")
+	tables := strings.Split(output, "\n\n")
+	require.Len(t, tables, 2)
+	assert.Equal(t, tables[0]+"\n", tables[1])
+}
+
+func TestPsChanges(t *testing.T) {
+	previous := []api.ContainerSummary{
+		{ID: "1", Name: "app-web-1", State: "running", Health: "starting"},
+		{ID: "2", Name: "app-db-1", State: "running"},
+		{ID: "3", Name: "app-worker-1", State: "running"},
+	}
+	current := []api.ContainerSummary{
+		{ID: "1", Name: "app-web-1", State: "running", Health: "healthy"},
+		{ID: "2", Name: "app-db-1", State: "running", Status: "Up 2 minutes"},
+		{ID: "4", Name: "app-worker-2", State: "created"},
+	}
+
+	changes := psChanges(previous, current)
+	assert.Equal(t, []psChange{
+		{Change: psChangeUpdated, ContainerSummary: current[0]},
+		{Change: psChangeAdded, ContainerSummary: current[2]},
+		{Change: psChangeRemoved, ContainerSummary: previous[2]},
+	}, changes)
+
+	assert.Empty(t, psChanges(current, current))
+}
@@ -19,6 +19,11 @@ package compose
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
 
 	"github.com/docker/cli/cli"
 	"github.com/docker/cli/cli/command"
@@ -34,6 +39,15 @@ type publishOptions struct {
 	ociVersion          string
 	withEnvironment     bool
 	assumeYes           bool
+	setup               string
+	tagOnly             bool
+	sign                bool
+	signKey             string
+	provenance          bool
+	withContent         bool
+	includeBuildContext bool
+	annotations         []string
+	variants            []string
 }
 
 func publishCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *cobra.Command {
@@ -53,6 +67,15 @@ func publishCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Servic
 	flags.StringVar(&opts.ociVersion, "oci-version", "", "OCI image/artifact specification version (automatically determined by default)")
 	flags.BoolVar(&opts.withEnvironment, "with-env", false, "Include environment variables in the published OCI artifact")
 	flags.BoolVarP(&opts.assumeYes, "yes", "y", false, `Assume "yes" as answer to all prompts`)
+	flags.StringVar(&opts.setup, "setup", "", "Include setup steps to run when the application is pulled")
+	flags.BoolVar(&opts.tagOnly, "tag-only", false, "Tag an artifact already published, designated as REPOSITORY:TAG@DIGEST, without pushing content")
+	flags.BoolVar(&opts.sign, "sign", false, "Sign the published artifact with cosign, keyless unless --sign-key is set")
+	flags.StringVar(&opts.signKey, "sign-key", "", "Private key (path, URL or KMS reference) to sign the published artifact with. Implies --sign")
+	flags.BoolVar(&opts.provenance, "provenance", false, "Attach a SLSA provenance layer describing the source repository, builder and published files")
+	flags.BoolVar(&opts.withContent, "with-content", false, "Include build contexts, bind mounts sources and config files in the published OCI artifact")
+	flags.BoolVar(&opts.includeBuildContext, "include-build-context", false, "Include build contexts in the published OCI artifact so services can be rebuilt from the published source")
+	flags.StringArrayVar(&opts.annotations, "annotation", nil, "Add an annotation to the published OCI manifest (format: KEY=VALUE)")
+	flags.StringArrayVar(&opts.variants, "variant", nil, "Include a Compose file applied when the artifact is loaded for a platform or an environment (format: NAME=FILE)")
 	flags.SetNormalizeFunc(func(f *pflag.FlagSet, name string) pflag.NormalizedName {
 		// assumeYes was introduced by mistake as `--y`
 		if name == "y" {
@@ -66,6 +89,33 @@ func publishCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Servic
 }
 
 func runPublish(ctx context.Context, dockerCli command.Cli, backend api.Service, opts publishOptions, repository string) error {
+	annotations, err := parseKeyValues("annotation", "KEY=VALUE", opts.annotations)
+	if err != nil {
+		return err
+	}
+	variants, err := parseKeyValues("variant", "NAME=FILE", opts.variants)
+	if err != nil {
+		return err
+	}
+	if opts.tagOnly {
+		if len(annotations) > 0 || len(variants) > 0 {
+			return errors.New("--annotation and --variant can't be used with --tag-only, as the published manifest is not modified")
+		}
+		return backend.Publish(ctx, nil, repository, api.PublishOptions{TagOnly: true})
+	}
+	var stdin []byte
+	if slices.Contains(opts.ConfigPaths, "-") {
+		if !opts.assumeYes {
+			// stdin is consumed by the Compose file, confirmation prompts can't be answered
+			return errors.New("--yes is required to publish a Compose file read from stdin")
+		}
+		var restore func()
+		stdin, restore, err = teeStdin()
+		if err != nil {
+			return err
+		}
+		defer restore()
+	}
 	project, metrics, err := opts.ToProject(ctx, dockerCli, nil)
 	if err != nil {
 		return err
@@ -80,5 +130,50 @@ func runPublish(ctx context.Context, dockerCli command.Cli, backend api.Service,
 		OCIVersion:          api.OCIVersion(opts.ociVersion),
 		WithEnvironment:     opts.withEnvironment,
 		AssumeYes:           opts.assumeYes,
+		SetupFile:           opts.setup,
+		Sign:                opts.sign,
+		SignKey:             opts.signKey,
+		Provenance:          opts.provenance,
+		WithContent:         opts.withContent,
+		IncludeBuildContext: opts.includeBuildContext,
+		Annotations:         annotations,
+		Variants:            variants,
+		Stdin:               stdin,
 	})
 }
+
+// parseKeyValues parses the values of a repeatable flag, as KEY=VALUE pairs
+func parseKeyValues(kind, format string, args []string) (map[string]string, error) {
+	values := map[string]string{}
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid %s %q, expected %s", kind, arg, format)
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+// teeStdin reads stdin, and replaces it with a pipe serving the same content to the project loader, so the raw
+// Compose file read from stdin can be published. The returned function restores stdin
+func teeStdin() ([]byte, func(), error) {
+	content, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return nil, nil, err
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	go func() {
+		_, _ = w.Write(content)
+		_ = w.Close()
+	}()
+	stdin := os.Stdin
+	os.Stdin = r
+	return content, func() {
+		os.Stdin = stdin
+		_ = r.Close()
+	}, nil
+}
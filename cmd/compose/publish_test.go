@@ -0,0 +1,67 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseKeyValues(t *testing.T) {
+	annotations, err := parseKeyValues("annotation", "KEY=VALUE", []string{"com.example.team=payments", "com.example.ticket=OPS-42", "com.example.empty="})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"com.example.team":   "payments",
+		"com.example.ticket": "OPS-42",
+		"com.example.empty":  "",
+	}, annotations)
+
+	_, err = parseKeyValues("annotation", "KEY=VALUE", []string{"com.example.team"})
+	assert.ErrorContains(t, err, `invalid annotation "com.example.team"`)
+	_, err = parseKeyValues("annotation", "KEY=VALUE", []string{"=payments"})
+	assert.ErrorContains(t, err, "expected KEY=VALUE")
+
+	_, err = parseKeyValues("variant", "NAME=FILE", []string{"linux/arm64"})
+	assert.ErrorContains(t, err, `invalid variant "linux/arm64", expected NAME=FILE`)
+}
+
+func TestTeeStdin(t *testing.T) {
+	content := "services:\n  web:\n    image: nginx:${TAG}\n"
+	file := filepath.Join(t.TempDir(), "compose.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+	f, err := os.Open(file)
+	require.NoError(t, err)
+	defer f.Close() //nolint:errcheck
+	stdin := os.Stdin
+	os.Stdin = f
+	defer func() { os.Stdin = stdin }()
+
+	raw, restore, err := teeStdin()
+	require.NoError(t, err)
+	assert.Equal(t, content, string(raw))
+	// the project loader still reads the Compose file from stdin
+	loaded, err := io.ReadAll(os.Stdin)
+	require.NoError(t, err)
+	assert.Equal(t, content, string(loaded))
+	restore()
+	assert.Equal(t, f, os.Stdin)
+}
@@ -39,6 +39,7 @@ type pullOptions struct {
 	ignorePullFailures bool
 	noBuildable        bool
 	policy             string
+	fromHost           string
 }
 
 func pullCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *cobra.Command {
@@ -57,9 +58,9 @@ func pullCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service)
 			}
 			return nil
 		},
-		RunE: Adapt(func(ctx context.Context, args []string) error {
+		RunE: p.withServiceGroups(dockerCli, Adapt(func(ctx context.Context, args []string) error {
 			return runPull(ctx, dockerCli, backend, opts, args)
-		}),
+		})),
 		ValidArgsFunction: completeServiceNames(dockerCli, p),
 	}
 	flags := cmd.Flags()
@@ -71,7 +72,8 @@ func pullCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service)
 	flags.MarkHidden("no-parallel") //nolint:errcheck
 	cmd.Flags().BoolVar(&opts.ignorePullFailures, "ignore-pull-failures", false, "Pull what it can and ignores images with pull failures")
 	cmd.Flags().BoolVar(&opts.noBuildable, "ignore-buildable", false, "Ignore images that can be built")
-	cmd.Flags().StringVar(&opts.policy, "policy", "", `Apply pull policy ("missing"|"always")`)
+	cmd.Flags().StringVar(&opts.policy, "policy", "", `Apply pull policy ("missing"|"missing+digest-check"|"always"), overrides COMPOSE_PULL_POLICY`)
+	cmd.Flags().StringVar(&opts.fromHost, "from-host", "", "Load images from another docker engine (e.g. ssh://user@peer) before falling back to registry")
 	return cmd
 }
 
@@ -85,6 +87,9 @@ func (opts pullOptions) apply(project *types.Project, services []string) (*types
 	}
 
 	if opts.policy != "" {
+		if !api.IsValidPullPolicy(opts.policy) {
+			return nil, fmt.Errorf("invalid --policy option %q", opts.policy)
+		}
 		for i, service := range project.Services {
 			if service.Image == "" {
 				continue
@@ -111,5 +116,6 @@ func runPull(ctx context.Context, dockerCli command.Cli, backend api.Service, op
 		Quiet:           opts.quiet,
 		IgnoreFailures:  opts.ignorePullFailures,
 		IgnoreBuildable: opts.noBuildable,
+		FromHost:        opts.fromHost,
 	})
 }
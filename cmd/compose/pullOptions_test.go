@@ -17,10 +17,13 @@
 package compose
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/compose-spec/compose-go/v2/types"
 	"gotest.tools/v3/assert"
+
+	"github.com/docker/compose/v2/pkg/api"
 )
 
 func TestApplyPullOptions(t *testing.T) {
@@ -55,3 +58,60 @@ func TestApplyPullOptions(t *testing.T) {
 	assert.Equal(t, project.Services["has-build"].PullPolicy, types.PullPolicyMissing)
 	assert.Equal(t, project.Services["must-pull"].PullPolicy, types.PullPolicyMissing)
 }
+
+func TestApplyPullOptionsInvalidPolicy(t *testing.T) {
+	project := &types.Project{
+		Services: types.Services{
+			"must-pull": {
+				Name:  "must-pull",
+				Image: "registry.example.com/another-service",
+			},
+		},
+	}
+	_, err := pullOptions{
+		policy: "sometimes",
+	}.apply(project, nil)
+	assert.Error(t, err, `invalid --policy option "sometimes"`)
+}
+
+func TestApplyDefaultPullPolicy(t *testing.T) {
+	project := &types.Project{
+		Environment: types.Mapping{
+			ComposePullPolicy: api.PullPolicyMissingDigestCheck,
+		},
+		Services: types.Services{
+			"must-build": {
+				Name: "must-build",
+				Build: &types.BuildConfig{
+					Context: ".",
+				},
+			},
+			"explicit": {
+				Name:       "explicit",
+				Image:      "registry.example.com/myservice",
+				PullPolicy: types.PullPolicyAlways,
+			},
+			"default": {
+				Name:  "default",
+				Image: "registry.example.com/another-service",
+			},
+		},
+	}
+	err := applyDefaultPullPolicy(project)
+	assert.NilError(t, err)
+	assert.Equal(t, project.Services["must-build"].PullPolicy, "")
+	assert.Equal(t, project.Services["explicit"].PullPolicy, types.PullPolicyAlways)
+	assert.Equal(t, project.Services["default"].PullPolicy, api.PullPolicyMissingDigestCheck)
+
+	for _, policy := range []string{"daily", "weekly", "every_12h", "every_1w2d", types.PullPolicyRefresh} {
+		project.Services["default"] = types.ServiceConfig{Name: "default", Image: "registry.example.com/another-service"}
+		project.Environment[ComposePullPolicy] = policy
+		assert.NilError(t, applyDefaultPullPolicy(project), policy)
+		assert.Equal(t, project.Services["default"].PullPolicy, policy)
+	}
+
+	for _, policy := range []string{"sometimes", "every_", "every_often"} {
+		project.Environment[ComposePullPolicy] = policy
+		assert.Error(t, applyDefaultPullPolicy(project), fmt.Sprintf("invalid COMPOSE_PULL_POLICY value %q", policy))
+	}
+}
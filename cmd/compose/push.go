@@ -41,9 +41,9 @@ func pushCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service)
 	pushCmd := &cobra.Command{
 		Use:   "push [OPTIONS] [SERVICE...]",
 		Short: "Push service images",
-		RunE: Adapt(func(ctx context.Context, args []string) error {
+		RunE: p.withServiceGroups(dockerCli, Adapt(func(ctx context.Context, args []string) error {
 			return runPush(ctx, dockerCli, backend, opts, args)
-		}),
+		})),
 		ValidArgsFunction: completeServiceNames(dockerCli, p),
 	}
 	pushCmd.Flags().BoolVar(&opts.Ignorefailures, "ignore-push-failures", false, "Push what it can and ignores images with push failures")
@@ -0,0 +1,83 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// readOnlyCommands lists the commands which don't change the state of the engine, allowed when COMPOSE_READONLY is
+// set. Commands running processes in containers, like exec or health, aren't as they can change anything
+var readOnlyCommands = []string{
+	"alpha generate",
+	"alpha import-k8s",
+	"alpha viz",
+	"cache ls",
+	"completion",
+	"config",
+	"diff",
+	"env",
+	"events",
+	"export",
+	"features",
+	"help",
+	"images",
+	"inspect",
+	"lock",
+	"logs",
+	"ls",
+	"port",
+	"prefetch",
+	"ps",
+	"stats",
+	"top",
+	"version",
+	"wait",
+}
+
+// readOnlyCommandsWritingFlags lists the flags which make a command otherwise allowed by readOnlyCommands change the
+// state of the engine
+var readOnlyCommandsWritingFlags = map[string][]string{
+	"wait": {"down-project"},
+}
+
+// checkReadOnly refuses to run cmd if it can change the state of the engine
+func checkReadOnly(cmd *cobra.Command) error {
+	var path []string
+	for c := cmd; c.HasParent() && c.Name() != PluginName; c = c.Parent() {
+		path = append([]string{c.Name()}, path...)
+	}
+	name := strings.Join(path, " ")
+	if name == "" || strings.HasPrefix(name, cobra.ShellCompRequestCmd) {
+		return nil
+	}
+	if slices.ContainsFunc(readOnlyCommands, func(c string) bool {
+		return name == c || strings.HasPrefix(name, c+" ")
+	}) {
+		for _, flag := range readOnlyCommandsWritingFlags[name] {
+			if f := cmd.Flags().Lookup(flag); f != nil && f.Changed && f.Value.String() != "false" {
+				return fmt.Errorf("%q with --%s is not allowed as %s is set, as it changes the state of the engine", name, flag, ComposeReadOnly)
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("%q is not allowed as %s is set, only commands which don't change the state of the engine can run (use --dry-run to preview changes)", name, ComposeReadOnly)
+}
@@ -0,0 +1,55 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckReadOnly(t *testing.T) {
+	root := &cobra.Command{Use: PluginName}
+	commands := map[string]*cobra.Command{}
+	for _, name := range []string{"ps", "logs", "down", "exec", "config", "wait"} {
+		commands[name] = &cobra.Command{Use: name}
+		root.AddCommand(commands[name])
+	}
+	cache := &cobra.Command{Use: "cache"}
+	root.AddCommand(cache)
+	for _, name := range []string{"ls", "prune"} {
+		commands["cache "+name] = &cobra.Command{Use: name}
+		cache.AddCommand(commands["cache "+name])
+	}
+
+	require.NoError(t, checkReadOnly(root))
+	for _, name := range []string{"ps", "logs", "config", "cache ls"} {
+		assert.NoError(t, checkReadOnly(commands[name]), name)
+	}
+	for _, name := range []string{"down", "exec", "cache prune"} {
+		assert.ErrorContains(t, checkReadOnly(commands[name]), `"`+name+`" is not allowed as COMPOSE_READONLY is set`)
+	}
+
+	wait := commands["wait"]
+	var downProject bool
+	wait.Flags().BoolVar(&downProject, "down-project", false, "")
+	assert.NoError(t, checkReadOnly(wait))
+	require.NoError(t, wait.Flags().Set("down-project", "true"))
+	assert.ErrorContains(t, checkReadOnly(wait), `"wait" with --down-project is not allowed as COMPOSE_READONLY is set`)
+}
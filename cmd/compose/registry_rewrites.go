@@ -0,0 +1,223 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/distribution/reference"
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/docker/builder/remotecontext/urlutil"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+const (
+	// registryRewritesExtension is the compose file extension mapping registries to the location to retrieve images from
+	registryRewritesExtension = "x-registry-rewrites"
+	// dockerImagePrefix marks a build additional context as an image
+	dockerImagePrefix = "docker-image://"
+)
+
+// registryRewrites merges the rewrites declared by the `x-registry-rewrites` extension with COMPOSE_REGISTRY_REWRITES,
+// the latter taking precedence
+func registryRewrites(extensions types.Extensions, env string) (api.RegistryRewrites, error) {
+	rewrites := api.RegistryRewrites{}
+	if v, ok := extensions[registryRewritesExtension]; ok {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s must be a mapping", registryRewritesExtension)
+		}
+		for registry, location := range m {
+			s, ok := location.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s: %s must be a string", registryRewritesExtension, registry)
+			}
+			rewrites[registry] = s
+		}
+	}
+	overrides, err := api.ParseRegistryRewrites(env)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s value: %w", ComposeRegistryRewrites, err)
+	}
+	for registry, location := range overrides {
+		rewrites[registry] = location
+	}
+	return rewrites, nil
+}
+
+// applyRegistryRewrites makes services use images from the registries set by `x-registry-rewrites` and
+// COMPOSE_REGISTRY_REWRITES, the latter taking precedence
+func applyRegistryRewrites(project *types.Project) error {
+	rewrites, err := registryRewrites(project.Extensions, project.Environment[ComposeRegistryRewrites])
+	if err != nil {
+		return err
+	}
+	if len(rewrites) == 0 {
+		return nil
+	}
+
+	for name, service := range project.Services {
+		// image set for a service with build section is the one we tag, not to be retrieved from registry
+		if service.Image != "" && service.Build == nil {
+			service.Image, err = rewrites.Rewrite(service.Image)
+			if err != nil {
+				return fmt.Errorf("service %q: %w", name, err)
+			}
+		}
+		if service.Build != nil {
+			if err = rewriteBuildImages(service.Build, rewrites); err != nil {
+				return fmt.Errorf("service %q: %w", name, err)
+			}
+		}
+		project.Services[name] = service
+	}
+	return nil
+}
+
+// rewriteBuildImages applies rewrites to the images a build retrieves from registries: additional contexts using
+// `docker-image://`, cache sources, and Dockerfile base images, the latter being overridden by named contexts
+func rewriteBuildImages(build *types.BuildConfig, rewrites api.RegistryRewrites) error {
+	for key, source := range build.AdditionalContexts {
+		image, ok := strings.CutPrefix(source, dockerImagePrefix)
+		if !ok {
+			continue
+		}
+		image, err := rewrites.Rewrite(image)
+		if err != nil {
+			return fmt.Errorf("additional context %s: %w", key, err)
+		}
+		build.AdditionalContexts[key] = dockerImagePrefix + image
+	}
+
+	for i, source := range build.CacheFrom {
+		rewritten, err := rewriteCacheSource(source, rewrites)
+		if err != nil {
+			return fmt.Errorf("cache_from %s: %w", source, err)
+		}
+		build.CacheFrom[i] = rewritten
+	}
+
+	images, err := dockerfileBaseImages(build)
+	if err != nil {
+		return err
+	}
+	for _, image := range images {
+		named, err := reference.ParseNormalizedNamed(image)
+		if err != nil {
+			return fmt.Errorf("base image %s: %w", image, err)
+		}
+		rewritten, err := rewrites.RewriteNamed(named)
+		if err != nil {
+			return fmt.Errorf("base image %s: %w", image, err)
+		}
+		if rewritten.String() == named.String() {
+			continue
+		}
+		// BuildKit looks up the named context overriding a base image by its familiar name
+		key := strings.TrimSuffix(reference.FamiliarString(named), ":latest")
+		if _, ok := build.AdditionalContexts[key]; ok {
+			// explicitly set by user
+			continue
+		}
+		if build.AdditionalContexts == nil {
+			build.AdditionalContexts = types.Mapping{}
+		}
+		build.AdditionalContexts[key] = dockerImagePrefix + reference.FamiliarString(rewritten)
+	}
+	return nil
+}
+
+// rewriteCacheSource applies rewrites to a cache_from entry, either an image reference or a registry cache
+// declared as `type=registry,ref=IMAGE`
+func rewriteCacheSource(source string, rewrites api.RegistryRewrites) (string, error) {
+	if !strings.Contains(source, "=") {
+		return rewrites.Rewrite(source)
+	}
+	attrs := strings.Split(source, ",")
+	if !slices.Contains(attrs, "type=registry") {
+		return source, nil
+	}
+	for i, attr := range attrs {
+		ref, ok := strings.CutPrefix(attr, "ref=")
+		if !ok {
+			continue
+		}
+		ref, err := rewrites.Rewrite(ref)
+		if err != nil {
+			return "", err
+		}
+		attrs[i] = "ref=" + ref
+	}
+	return strings.Join(attrs, ","), nil
+}
+
+// dockerfileBaseImages returns the images used by FROM instructions of a local Dockerfile, as named contexts would
+// reference them. Stages, `scratch`, and images set by build arguments are ignored
+func dockerfileBaseImages(build *types.BuildConfig) ([]string, error) {
+	var content io.Reader
+	switch {
+	case build.DockerfileInline != "":
+		content = strings.NewReader(build.DockerfileInline)
+	case strings.Contains(build.Context, "://") || urlutil.IsGitURL(build.Context):
+		return nil, nil
+	default:
+		dockerfile := build.Dockerfile
+		if dockerfile == "" {
+			dockerfile = "Dockerfile"
+		}
+		if !filepath.IsAbs(dockerfile) {
+			dockerfile = filepath.Join(build.Context, dockerfile)
+		}
+		f, err := os.Open(dockerfile)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close() //nolint:errcheck
+		content = f
+	}
+	parsed, err := parser.Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Dockerfile: %w", err)
+	}
+
+	var images []string
+	stages := map[string]bool{"scratch": true}
+	for _, node := range parsed.AST.Children {
+		if !strings.EqualFold(node.Value, "from") || node.Next == nil {
+			continue
+		}
+		base := node.Next.Value
+		if next := node.Next.Next; next != nil && strings.EqualFold(next.Value, "as") && next.Next != nil {
+			stages[strings.ToLower(next.Next.Value)] = true
+		}
+		if strings.Contains(base, "$") || stages[strings.ToLower(base)] || slices.Contains(images, base) {
+			continue
+		}
+		images = append(images, base)
+	}
+	return images, nil
+}
@@ -0,0 +1,125 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+)
+
+func TestApplyRegistryRewrites(t *testing.T) {
+	p := &types.Project{
+		Extensions: types.Extensions{
+			registryRewritesExtension: map[string]any{
+				"docker.io": "mirror.local",
+				"ghcr.io":   "mirror.local/ghcr",
+			},
+		},
+		Environment: types.Mapping{
+			ComposeRegistryRewrites: "ghcr.io=registry.corp.local",
+		},
+		Services: types.Services{
+			"db": {
+				Name:  "db",
+				Image: "postgres:16",
+			},
+			"api": {
+				Name:  "api",
+				Image: "ghcr.io/acme/api",
+			},
+			"web": {
+				Name:  "web",
+				Image: "acme/web",
+				Build: &types.BuildConfig{
+					AdditionalContexts: types.Mapping{
+						"base": "docker-image://node:22",
+						"src":  "./src",
+					},
+				},
+			},
+		},
+	}
+	assert.NilError(t, applyRegistryRewrites(p))
+	assert.Equal(t, p.Services["db"].Image, "mirror.local/library/postgres:16")
+	assert.Equal(t, p.Services["api"].Image, "registry.corp.local/acme/api")
+	assert.Equal(t, p.Services["web"].Image, "acme/web")
+	assert.DeepEqual(t, p.Services["web"].Build.AdditionalContexts, types.Mapping{
+		"base": "docker-image://mirror.local/library/node:22",
+		"src":  "./src",
+	})
+}
+
+func TestApplyRegistryRewritesBuild(t *testing.T) {
+	dir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte(`FROM --platform=$BUILDPLATFORM golang:1.23 AS builder
+FROM builder AS test
+FROM ${BASE}
+FROM scratch
+FROM docker.io/library/alpine
+FROM ghcr.io/acme/base:1 AS base
+`), 0o644))
+	p := &types.Project{
+		Extensions: types.Extensions{
+			registryRewritesExtension: map[string]any{
+				"docker.io": "mirror.local",
+			},
+		},
+		Services: types.Services{
+			"web": {
+				Name:  "web",
+				Image: "acme/web",
+				Build: &types.BuildConfig{
+					Context:    dir,
+					Dockerfile: "Dockerfile",
+					CacheFrom: []string{
+						"acme/web:cache",
+						"type=registry,ref=acme/web:buildcache",
+						"type=local,src=/tmp/cache",
+					},
+				},
+			},
+			"inline": {
+				Name: "inline",
+				Build: &types.BuildConfig{
+					DockerfileInline: "FROM node:22\n",
+					AdditionalContexts: types.Mapping{
+						"node:22": "docker-image://node:22-slim",
+					},
+				},
+			},
+		},
+	}
+	assert.NilError(t, applyRegistryRewrites(p))
+	web := p.Services["web"].Build
+	assert.DeepEqual(t, web.AdditionalContexts, types.Mapping{
+		"golang:1.23": "docker-image://mirror.local/library/golang:1.23",
+		"alpine":      "docker-image://mirror.local/library/alpine",
+	})
+	assert.DeepEqual(t, web.CacheFrom, types.StringList{
+		"mirror.local/acme/web:cache",
+		"type=registry,ref=mirror.local/acme/web:buildcache",
+		"type=local,src=/tmp/cache",
+	})
+	assert.Equal(t, p.Services["web"].Image, "acme/web")
+	assert.DeepEqual(t, p.Services["inline"].Build.AdditionalContexts, types.Mapping{
+		"node:22": "docker-image://mirror.local/library/node:22-slim",
+	})
+}
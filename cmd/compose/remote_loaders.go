@@ -0,0 +1,227 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/loader"
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/config"
+	"github.com/docker/compose/v2/internal/features"
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/remote"
+	"github.com/docker/go-units"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+func (o *ProjectOptions) remoteLoaders(dockerCli command.Cli) []loader.ResourceLoader {
+	auth, err := remote.ParseIncludeAuth(os.Getenv(ComposeIncludeAuth))
+	if err != nil {
+		logrus.Warnf("ignoring %s: %v", ComposeIncludeAuth, err)
+	}
+	registryAuth, err := remote.ParseRegistryAuth(os.Getenv(ComposeRegistryAuth))
+	if err != nil {
+		logrus.Warnf("ignoring %s: %v", ComposeRegistryAuth, err)
+	}
+	if o.remoteInputs == nil {
+		o.remoteInputs = remote.NewInputs()
+	}
+	rewrites, err := o.declaredRegistryRewrites()
+	if err != nil {
+		logrus.Warnf("ignoring registry rewrites: %v", err)
+	}
+	mirrors, err := registryMirrors()
+	if err != nil {
+		logrus.Warnf("ignoring registry mirrors: %v", err)
+	}
+	transports, err := api.LoadRegistryTransports(filepath.Join(config.Dir(), "compose", "registries.json"))
+	if err != nil {
+		logrus.Warnf("ignoring registry transport settings: %v", err)
+	}
+	var cacheTTL time.Duration
+	if ttl, ok := os.LookupEnv(ComposeRemoteCacheTTL); ok && !o.Refresh {
+		cacheTTL, err = time.ParseDuration(ttl)
+		if err != nil {
+			logrus.Warnf("ignoring %s: %v", ComposeRemoteCacheTTL, err)
+		}
+	}
+	variants := remote.VariantSelector{
+		Platform: o.Platform,
+		Names:    defaultStringArrayVar(ComposeOCIVariant),
+	}
+	if variants.Platform == "" {
+		variants.Platform, _ = api.DefaultPlatform(types.NewMapping(os.Environ()))
+	}
+	oci := remote.NewOCIRemoteLoader(dockerCli, remote.OCIRemoteLoaderOptions{
+		Offline:      o.Offline,
+		CacheTTL:     cacheTTL,
+		Rewrites:     rewrites,
+		Mirrors:      mirrors,
+		Transports:   transports,
+		Auth:         auth,
+		RegistryAuth: registryAuth,
+		Verify:       os.Getenv(ComposeOCIVerify),
+		Variants:     variants,
+		Inputs:       o.remoteInputs,
+		Flags:        o.featureFlags(),
+	})
+	if o.Offline {
+		// OCI artifacts are served from the cache when offline, other remote resources are not supported
+		return []loader.ResourceLoader{oci}
+	}
+	git := remote.NewGitRemoteLoader(dockerCli, o.Offline, auth, o.remoteInputs, o.featureFlags())
+	http := remote.NewHTTPRemoteLoader(o.Offline, os.Getenv(ComposeRemoteSHA256), o.ConfigPaths, o.remoteInputs, o.featureFlags())
+	bucket := remote.NewBucketRemoteLoader(o.Offline, o.remoteInputs, o.featureFlags())
+	return append([]loader.ResourceLoader{git, oci, http, bucket}, remote.RegisteredLoaders(dockerCli)...)
+}
+
+// declaredRegistryRewrites reads the registry rewrites declared by the Compose files the project is loaded from, and
+// by COMPOSE_REGISTRY_REWRITES, before the project is loaded so they also apply to the remote resources it includes
+func (o *ProjectOptions) declaredRegistryRewrites() (api.RegistryRewrites, error) {
+	options, err := o.toProjectOptions()
+	if err != nil {
+		return nil, err
+	}
+	extensions := types.Extensions{}
+	for _, file := range options.ConfigPaths {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			// stdin and remote Compose files are not read ahead
+			continue
+		}
+		var declared map[string]any
+		if err := yaml.Unmarshal(b, &declared); err != nil {
+			continue
+		}
+		for key, value := range declared {
+			if !strings.HasPrefix(key, "x-") {
+				continue
+			}
+			// mappings are merged by following files, as loading the project does
+			previous, ok := extensions[key].(map[string]any)
+			override, isMap := value.(map[string]any)
+			if ok && isMap {
+				maps.Copy(previous, override)
+				continue
+			}
+			extensions[key] = value
+		}
+	}
+	return registryRewrites(extensions, options.Environment[ComposeRegistryRewrites])
+}
+
+// registryMirrors reads the mirrors declared by the compose/registry-mirrors.json file of the docker configuration,
+// mapping registries to a list of mirrors, then by COMPOSE_REGISTRY_MIRRORS which takes precedence
+func registryMirrors() (api.RegistryMirrors, error) {
+	mirrors := api.RegistryMirrors{}
+	file := filepath.Join(config.Dir(), "compose", "registry-mirrors.json")
+	if b, err := os.ReadFile(file); err == nil {
+		var declared map[string][]string
+		if err := json.Unmarshal(b, &declared); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		for registry, hosts := range declared {
+			for _, mirror := range hosts {
+				if err := mirrors.Add(registry, mirror); err != nil {
+					return nil, fmt.Errorf("%s: %w", file, err)
+				}
+			}
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	env, err := api.ParseRegistryMirrors(os.Getenv(ComposeRegistryMirrors))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s value: %w", ComposeRegistryMirrors, err)
+	}
+	for registry, hosts := range env {
+		mirrors[registry] = hosts
+	}
+	return mirrors, nil
+}
+
+func (o *ProjectOptions) featureFlags() *features.Flags {
+	if o.features == nil {
+		o.features = features.NewFlags(nil)
+	}
+	return o.features
+}
+
+// checkRemoteInputs checks versions remote resources resolved to didn't change since last run with --frozen, otherwise
+// records them for commands running the project, so that read-only commands don't update the record
+func (o *ProjectOptions) checkRemoteInputs(projectName string) error {
+	if o.Offline {
+		return nil
+	}
+	inputs := o.remoteInputs.Resolved()
+	if o.Frozen {
+		return remote.CheckFrozenInputs(projectName, inputs)
+	}
+	if !o.recordInputs {
+		return nil
+	}
+	if len(inputs) == 0 {
+		// only update a previous record, if any
+		recorded, err := remote.RecordedInputs(projectName)
+		if err != nil || len(recorded) == 0 {
+			return err
+		}
+	}
+	return remote.RecordInputs(projectName, inputs)
+}
+
+// defaultRemoteCacheMaxSize is the disk usage of the remote resource cache above which it gets pruned, unless set by
+// COMPOSE_REMOTE_CACHE_MAX_SIZE
+const defaultRemoteCacheMaxSize = 1 << 30
+
+// pruneRemoteCache removes the least recently used remote resources from the cache once it exceeds its maximum size,
+// keeping the ones the project was just loaded from. Nothing is pruned offline, as resources can't be downloaded again.
+// Pruning only reclaims disk space, so failures don't prevent the project from loading
+func (o *ProjectOptions) pruneRemoteCache() {
+	inputs := o.remoteInputs.Resolved()
+	if o.Offline || len(inputs) == 0 {
+		return
+	}
+	maxSize := int64(defaultRemoteCacheMaxSize)
+	if value, ok := os.LookupEnv(ComposeRemoteCacheMaxSize); ok {
+		size, err := units.RAMInBytes(value)
+		if err != nil {
+			logrus.Warnf("ignoring %s: %v", ComposeRemoteCacheMaxSize, err)
+		} else {
+			maxSize = size
+		}
+	}
+	if maxSize <= 0 {
+		return
+	}
+	pruned, err := remote.PruneCache(maxSize, inputs)
+	if err != nil {
+		logrus.Warnf("pruning remote resource cache: %v", err)
+	}
+	for _, entry := range pruned {
+		logrus.Debugf("pruned %s from the remote resource cache, last used %s", entry.Source, entry.LastUsed.Format(time.RFC3339))
+	}
+}
@@ -0,0 +1,63 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/compose/v2/pkg/api"
+	"gotest.tools/v3/assert"
+)
+
+func TestDeclaredRegistryRewrites(t *testing.T) {
+	dir := t.TempDir()
+	compose := filepath.Join(dir, "compose.yaml")
+	override := filepath.Join(dir, "compose.override.yaml")
+	assert.NilError(t, os.WriteFile(compose, []byte(`
+x-registry-rewrites:
+  docker.io: mirror.local
+  ghcr.io: mirror.local/ghcr
+services:
+  app:
+    image: alpine
+`), 0o644))
+	assert.NilError(t, os.WriteFile(override, []byte(`
+x-registry-rewrites:
+  ghcr.io: registry.corp.local
+`), 0o644))
+	t.Setenv(ComposeRegistryRewrites, "")
+
+	// override file is discovered along with the default Compose file
+	o := &ProjectOptions{ProjectDir: dir}
+	rewrites, err := o.declaredRegistryRewrites()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, rewrites, api.RegistryRewrites{
+		"docker.io": "mirror.local",
+		"ghcr.io":   "registry.corp.local",
+	})
+
+	t.Setenv(ComposeRegistryRewrites, "docker.io=registry.corp.local/hub")
+	o = &ProjectOptions{ConfigPaths: []string{compose, "-"}}
+	rewrites, err = o.declaredRegistryRewrites()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, rewrites, api.RegistryRewrites{
+		"docker.io": "registry.corp.local/hub",
+		"ghcr.io":   "mirror.local/ghcr",
+	})
+}
@@ -69,6 +69,7 @@ type runOptions struct {
 	removeOrphans bool
 	quiet         bool
 	quietPull     bool
+	auditLog      string
 }
 
 func (options runOptions) apply(project *types.Project) (*types.Project, error) {
@@ -230,10 +231,11 @@ func runCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *
 	flags.StringArrayVarP(&options.publish, "publish", "p", []string{}, "Publish a container's port(s) to the host")
 	flags.BoolVar(&options.useAliases, "use-aliases", false, "Use the service's network useAliases in the network(s) the container connects to")
 	flags.BoolVarP(&options.servicePorts, "service-ports", "P", false, "Run command with all service's ports enabled and mapped to the host")
-	flags.StringVar(&createOpts.Pull, "pull", "policy", `Pull image before running ("always"|"missing"|"never")`)
+	flags.StringVar(&createOpts.Pull, "pull", "policy", `Pull image before running ("always"|"missing"|"missing+digest-check"|"never")`)
 	flags.BoolVarP(&options.quiet, "quiet", "q", false, "Don't print anything to STDOUT")
 	flags.BoolVar(&buildOpts.quiet, "quiet-build", false, "Suppress progress output from the build process")
 	flags.BoolVar(&options.quietPull, "quiet-pull", false, "Pull without printing progress information")
+	flags.StringVar(&options.auditLog, "audit-log", "", "Record command transcript to this file or oci:// repository")
 	flags.BoolVar(&createOpts.Build, "build", false, "Build image before starting container")
 	flags.BoolVar(&options.removeOrphans, "remove-orphans", false, "Remove containers for services not defined in the Compose file")
 
@@ -333,6 +335,10 @@ func runRun(ctx context.Context, backend api.Service, project *types.Project, op
 		NoDeps:            options.noDeps,
 		Index:             0,
 		QuietPull:         options.quietPull,
+		AuditLog:          options.auditLog,
+	}
+	if runOpts.AuditLog == "" {
+		runOpts.AuditLog = project.Environment[ComposeAuditLog]
 	}
 
 	for name, service := range project.Services {
@@ -0,0 +1,142 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/cli/cli/config"
+)
+
+// savedContext is a named set of project flags, saved by `config --save-context` and applied by `--use-context`
+type savedContext struct {
+	ConfigPaths []string `json:"files,omitempty"`
+	Profiles    []string `json:"profiles,omitempty"`
+	EnvFiles    []string `json:"env_files,omitempty"`
+	ProjectDir  string   `json:"project_directory,omitempty"`
+	ProjectName string   `json:"project_name,omitempty"`
+}
+
+// savedContextsFile stores saved contexts by name
+func savedContextsFile() string {
+	return filepath.Join(config.Dir(), "compose", "contexts.json")
+}
+
+func loadSavedContexts(file string) (map[string]savedContext, error) {
+	contexts := map[string]savedContext{}
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return contexts, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &contexts); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", file, err)
+	}
+	return contexts, nil
+}
+
+// saveContext records the project flags of o under name, with paths made absolute so the context can be used from
+// any directory. Compose files and project directory which weren't set by flags are saved as resolved for project
+func saveContext(file, name string, o *ProjectOptions, project *types.Project) error {
+	contexts, err := loadSavedContexts(file)
+	if err != nil {
+		return err
+	}
+	saved := savedContext{
+		Profiles:    o.Profiles,
+		ProjectName: o.ProjectName,
+	}
+	if slices.Contains(o.ConfigPaths, "-") {
+		return errors.New("a Compose file read from stdin can't be saved in a context")
+	}
+	remote := false
+	for _, path := range o.ConfigPaths {
+		// remote resources, like oci:// artifacts, are saved as is
+		if _, err := os.Stat(path); err == nil {
+			if path, err = filepath.Abs(path); err != nil {
+				return err
+			}
+		} else {
+			remote = true
+		}
+		saved.ConfigPaths = append(saved.ConfigPaths, path)
+	}
+	if len(saved.ConfigPaths) == 0 {
+		// files discovered from the current directory, or set by COMPOSE_FILE
+		saved.ConfigPaths = project.ComposeFiles
+	}
+	for _, path := range o.EnvFiles {
+		if path, err = filepath.Abs(path); err != nil {
+			return err
+		}
+		saved.EnvFiles = append(saved.EnvFiles, path)
+	}
+	switch {
+	case o.ProjectDir != "":
+		if saved.ProjectDir, err = filepath.Abs(o.ProjectDir); err != nil {
+			return err
+		}
+	case !remote:
+		// the working directory of a remote project is in the cache, resolved again when loading the resource
+		saved.ProjectDir = project.WorkingDir
+	}
+	contexts[name] = saved
+	data, err := json.MarshalIndent(contexts, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0o600)
+}
+
+// useContext applies the project flags saved under name, unless they are set on the command line
+func useContext(file, name string, o *ProjectOptions) error {
+	contexts, err := loadSavedContexts(file)
+	if err != nil {
+		return err
+	}
+	saved, ok := contexts[name]
+	if !ok {
+		return fmt.Errorf("no context saved as %q, save one with `docker compose config --save-context %s`", name, name)
+	}
+	if len(o.ConfigPaths) == 0 {
+		o.ConfigPaths = saved.ConfigPaths
+	}
+	if len(o.Profiles) == 0 {
+		o.Profiles = saved.Profiles
+	}
+	if len(o.EnvFiles) == 0 {
+		o.EnvFiles = saved.EnvFiles
+	}
+	if o.ProjectDir == "" {
+		o.ProjectDir = saved.ProjectDir
+	}
+	if o.ProjectName == "" {
+		o.ProjectName = saved.ProjectName
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSavedContext(t *testing.T) {
+	dir := t.TempDir()
+	composeFile := filepath.Join(dir, "compose.yaml")
+	require.NoError(t, os.WriteFile(composeFile, []byte("services: {}"), 0o600))
+	envFile, err := filepath.Abs("staging.env")
+	require.NoError(t, err)
+	file := filepath.Join(dir, "config", "contexts.json")
+
+	err = saveContext(file, "staging", &ProjectOptions{
+		ConfigPaths: []string{composeFile, "oci://example/app:1.0"},
+		Profiles:    []string{"debug"},
+		EnvFiles:    []string{"staging.env"},
+		ProjectName: "app",
+	}, &types.Project{ComposeFiles: []string{composeFile, "/cache/app/compose.yaml"}, WorkingDir: "/cache/app"})
+	require.NoError(t, err)
+
+	var o ProjectOptions
+	require.NoError(t, useContext(file, "staging", &o))
+	assert.Equal(t, []string{composeFile, "oci://example/app:1.0"}, o.ConfigPaths)
+	assert.Equal(t, []string{"debug"}, o.Profiles)
+	assert.Equal(t, []string{envFile}, o.EnvFiles)
+	assert.Equal(t, "app", o.ProjectName)
+	assert.Equal(t, "", o.ProjectDir)
+
+	// files discovered by Compose are saved as resolved, with the project directory
+	err = saveContext(file, "discovered", &ProjectOptions{}, &types.Project{ComposeFiles: []string{composeFile}, WorkingDir: dir})
+	require.NoError(t, err)
+	o = ProjectOptions{}
+	require.NoError(t, useContext(file, "discovered", &o))
+	assert.Equal(t, []string{composeFile}, o.ConfigPaths)
+	assert.Equal(t, dir, o.ProjectDir)
+
+	o = ProjectOptions{Profiles: []string{"frontend"}, ProjectName: "other"}
+	require.NoError(t, useContext(file, "staging", &o))
+	assert.Equal(t, []string{"frontend"}, o.Profiles)
+	assert.Equal(t, "other", o.ProjectName)
+
+	err = useContext(file, "prod", &o)
+	assert.ErrorContains(t, err, `no context saved as "prod"`)
+
+	err = saveContext(file, "stdin", &ProjectOptions{ConfigPaths: []string{"-"}}, &types.Project{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,77 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/spf13/cobra"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+type snapshotOptions struct {
+	*ProjectOptions
+	output string
+}
+
+func snapshotCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *cobra.Command {
+	opts := snapshotOptions{
+		ProjectOptions: p,
+	}
+	cmd := &cobra.Command{
+		Use:   "snapshot [OPTIONS]",
+		Short: "EXPERIMENTAL - Save containers filesystem changes, volumes content and configuration of the project",
+		Args:  cli.NoArgs,
+		RunE: Adapt(func(ctx context.Context, args []string) error {
+			return runSnapshot(ctx, dockerCli, backend, opts)
+		}),
+	}
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "Directory to write the snapshot bundle to (default: PROJECT-snapshot-TIMESTAMP)")
+	cmd.AddCommand(snapshotRestoreCommand(p, dockerCli, backend))
+	return cmd
+}
+
+func snapshotRestoreCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *cobra.Command {
+	return &cobra.Command{
+		Use:   "restore SNAPSHOT",
+		Short: "EXPERIMENTAL - Restore project containers and volumes from a snapshot bundle",
+		Args:  cli.ExactArgs(1),
+		RunE: Adapt(func(ctx context.Context, args []string) error {
+			projectName, err := p.toProjectName(ctx, dockerCli)
+			if err != nil {
+				return err
+			}
+			return backend.RestoreSnapshot(ctx, projectName, api.RestoreSnapshotOptions{
+				Input: args[0],
+			})
+		}),
+	}
+}
+
+func runSnapshot(ctx context.Context, dockerCli command.Cli, backend api.Service, opts snapshotOptions) error {
+	project, name, err := opts.projectOrName(ctx, dockerCli)
+	if err != nil {
+		return err
+	}
+	return backend.Snapshot(ctx, name, api.SnapshotOptions{
+		Project: project,
+		Output:  opts.output,
+	})
+}
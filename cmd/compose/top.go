@@ -41,9 +41,9 @@ func topCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *
 	topCmd := &cobra.Command{
 		Use:   "top [SERVICES...]",
 		Short: "Display the running processes",
-		RunE: Adapt(func(ctx context.Context, args []string) error {
+		RunE: p.withServiceGroups(dockerCli, Adapt(func(ctx context.Context, args []string) error {
 			return runTop(ctx, dockerCli, backend, opts, args)
-		}),
+		})),
 		ValidArgsFunction: completeServiceNames(dockerCli, p),
 	}
 	return topCmd
@@ -42,6 +42,9 @@ type composeOptions struct {
 	*ProjectOptions
 }
 
+// defaultRestartLimit stops restarting services crash looping while attached, unless COMPOSE_RESTART_LIMIT is set
+const defaultRestartLimit = "5/1m"
+
 type upOptions struct {
 	*composeOptions
 	Detach                bool
@@ -56,11 +59,15 @@ type upOptions struct {
 	attach                []string
 	noAttach              []string
 	timestamp             bool
+	level                 string
 	wait                  bool
 	waitTimeout           int
 	watch                 bool
 	navigationMenu        bool
 	navigationMenuChanged bool
+	answers               string
+	stdin                 string
+	ttl                   time.Duration
 }
 
 func (opts upOptions) apply(project *types.Project, services []string) (*types.Project, error) {
@@ -120,12 +127,17 @@ func upCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *c
 			create.pullChanged = cmd.Flags().Changed("pull")
 			create.timeChanged = cmd.Flags().Changed("timeout")
 			up.navigationMenuChanged = cmd.Flags().Changed("menu")
+			p.recordInputs = true
 			if !cmd.Flags().Changed("remove-orphans") {
 				create.removeOrphans = utils.StringToBool(os.Getenv(ComposeRemoveOrphans))
 			}
+			if p.ProjectGroup != "" {
+				// projects depending on another one are started once it's running|healthy
+				up.wait = true
+			}
 			return validateFlags(&up, &create)
 		}),
-		RunE: p.WithServices(dockerCli, func(ctx context.Context, project *types.Project, services []string) error {
+		RunE: p.withServiceGroups(dockerCli, p.withProjectGroup(false, p.withArtifactSetup(dockerCli, &up.answers, p.WithServices(dockerCli, func(ctx context.Context, project *types.Project, services []string) error {
 			create.ignoreOrphans = utils.StringToBool(project.Environment[ComposeIgnoreOrphans])
 			if create.ignoreOrphans && create.removeOrphans {
 				return fmt.Errorf("cannot combine %s and --remove-orphans", ComposeIgnoreOrphans)
@@ -141,37 +153,44 @@ func upCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service) *c
 			}
 
 			return runUp(ctx, dockerCli, backend, create, up, build, project, services)
-		}),
+		})))),
 		ValidArgsFunction: completeServiceNames(dockerCli, p),
 	}
 	flags := upCmd.Flags()
 	flags.BoolVarP(&up.Detach, "detach", "d", false, "Detached mode: Run containers in the background")
 	flags.BoolVar(&create.Build, "build", false, "Build images before starting containers")
 	flags.BoolVar(&create.noBuild, "no-build", false, "Don't build an image, even if it's policy")
-	flags.StringVar(&create.Pull, "pull", "policy", `Pull image before running ("always"|"missing"|"never")`)
+	flags.StringVar(&create.Pull, "pull", "policy", `Pull image before running ("always"|"missing"|"missing+digest-check"|"never")`)
+	flags.BoolVar(&create.pullStreaming, "pull-streaming", false, "Create containers as soon as their image is pulled, pulling images in start order")
 	flags.BoolVar(&create.removeOrphans, "remove-orphans", false, "Remove containers for services not defined in the Compose file")
+	flags.BoolVar(&create.previewOrphans, "preview-orphans", false, "List orphan containers and ask for confirmation before removing them")
 	flags.StringArrayVar(&create.scale, "scale", []string{}, "Scale SERVICE to NUM instances. Overrides the `scale` setting in the Compose file if present.")
 	flags.BoolVar(&up.noColor, "no-color", false, "Produce monochrome output")
 	flags.BoolVar(&up.noPrefix, "no-log-prefix", false, "Don't print prefix in logs")
 	flags.BoolVar(&create.forceRecreate, "force-recreate", false, "Recreate containers even if their configuration and image haven't changed")
 	flags.BoolVar(&create.noRecreate, "no-recreate", false, "If containers already exist, don't recreate them. Incompatible with --force-recreate.")
+	flags.BoolVar(&create.explain, "explain-recreate", false, "Explain which configuration changes cause containers to be recreated")
 	flags.BoolVar(&up.noStart, "no-start", false, "Don't start the services after creating them")
 	flags.BoolVar(&up.cascadeStop, "abort-on-container-exit", false, "Stops all containers if any container was stopped. Incompatible with -d")
 	flags.BoolVar(&up.cascadeFail, "abort-on-container-failure", false, "Stops all containers if any container exited with failure. Incompatible with -d")
 	flags.StringVar(&up.exitCodeFrom, "exit-code-from", "", "Return the exit code of the selected service container. Implies --abort-on-container-exit")
 	flags.IntVarP(&create.timeout, "timeout", "t", 0, "Use this timeout in seconds for container shutdown when attached or when containers are already running")
 	flags.BoolVar(&up.timestamp, "timestamps", false, "Show timestamps")
+	flags.StringVar(&up.level, "level", "", "Only show log lines of this level or higher (trace, debug, info, warn, error, fatal), as detected from JSON, logfmt or syslog formats")
 	flags.BoolVar(&up.noDeps, "no-deps", false, "Don't start linked services")
 	flags.BoolVar(&create.recreateDeps, "always-recreate-deps", false, "Recreate dependent containers. Incompatible with --no-recreate.")
 	flags.BoolVarP(&create.noInherit, "renew-anon-volumes", "V", false, "Recreate anonymous volumes instead of retrieving data from the previous containers")
 	flags.BoolVar(&create.quietPull, "quiet-pull", false, "Pull without printing progress information")
 	flags.StringArrayVar(&up.attach, "attach", []string{}, "Restrict attaching to the specified services. Incompatible with --attach-dependencies.")
 	flags.StringArrayVar(&up.noAttach, "no-attach", []string{}, "Do not attach (stream logs) to the specified services")
+	flags.StringVar(&up.answers, "answers", "", "Read answers to the setup prompts of an application published as OCI artifact from an env file")
 	flags.BoolVar(&up.attachDependencies, "attach-dependencies", false, "Automatically attach to log output of dependent services")
 	flags.BoolVar(&up.wait, "wait", false, "Wait for services to be running|healthy. Implies detached mode.")
 	flags.IntVar(&up.waitTimeout, "wait-timeout", 0, "Maximum duration in seconds to wait for the project to be running|healthy")
 	flags.BoolVarP(&up.watch, "watch", "w", false, "Watch source code and rebuild/refresh containers when files are updated.")
 	flags.BoolVar(&up.navigationMenu, "menu", false, "Enable interactive shortcuts when running attached. Incompatible with --detach. Can also be enable/disable by setting COMPOSE_MENU environment var.")
+	flags.StringVar(&up.stdin, "stdin", "", "Send terminal input to the specified service while attached. Service must set stdin_open.")
+	flags.DurationVar(&up.ttl, "ttl", 0, "Remove the project containers and networks once this duration is elapsed")
 	flags.BoolVarP(&create.AssumeYes, "yes", "y", false, `Assume "yes" as answer to all prompts and run non-interactively`)
 	flags.SetNormalizeFunc(func(f *pflag.FlagSet, name string) pflag.NormalizedName {
 		// assumeYes was introduced by mistake as `--y`
@@ -208,6 +227,12 @@ func validateFlags(up *upOptions, create *createOptions) error {
 			return fmt.Errorf("--detach cannot be combined with --abort-on-container-exit, --abort-on-container-failure, --attach, --attach-dependencies or --watch")
 		}
 	}
+	if up.Detach && up.stdin != "" {
+		return fmt.Errorf("--stdin cannot be combined with --detach or --wait")
+	}
+	if create.previewOrphans && !create.removeOrphans {
+		return fmt.Errorf("--preview-orphans requires --remove-orphans")
+	}
 	if create.noInherit && create.noRecreate {
 		return fmt.Errorf("--no-recreate and --renew-anon-volumes are incompatible")
 	}
@@ -220,6 +245,9 @@ func validateFlags(up *upOptions, create *createOptions) error {
 	if create.noBuild && up.watch {
 		return fmt.Errorf("--no-build and --watch are incompatible")
 	}
+	if up.ttl < 0 {
+		return fmt.Errorf("--ttl must be a positive duration")
+	}
 	return nil
 }
 
@@ -267,6 +295,7 @@ func runUp(
 		Build:                build,
 		Services:             services,
 		RemoveOrphans:        createOptions.removeOrphans,
+		ConfirmRemoveOrphans: createOptions.previewOrphans,
 		IgnoreOrphans:        createOptions.ignoreOrphans,
 		Recreate:             createOptions.recreateStrategy(),
 		RecreateDependencies: createOptions.dependenciesRecreateStrategy(),
@@ -274,6 +303,8 @@ func runUp(
 		Timeout:              createOptions.GetTimeout(),
 		QuietPull:            createOptions.quietPull,
 		AssumeYes:            createOptions.AssumeYes,
+		ExplainRecreate:      createOptions.explain,
+		PullStreaming:        createOptions.pullStreaming,
 	}
 
 	if upOptions.noStart {
@@ -283,7 +314,11 @@ func runUp(
 	var consumer api.LogConsumer
 	var attach []string
 	if !upOptions.Detach {
-		consumer = formatter.NewLogConsumer(ctx, dockerCli.Out(), dockerCli.Err(), !upOptions.noColor, !upOptions.noPrefix, upOptions.timestamp)
+		level, err := formatter.ParseLogLevel(upOptions.level)
+		if err != nil {
+			return err
+		}
+		consumer = formatter.NewLevelLogConsumer(ctx, dockerCli.Out(), dockerCli.Err(), !upOptions.noColor, !upOptions.noPrefix, upOptions.timestamp, level)
 
 		var attachSet utils.Set[string]
 		if len(upOptions.attach) != 0 {
@@ -313,6 +348,28 @@ func runUp(
 		// filter out any services that have been explicitly marked for ignore with `--no-attach`
 		attachSet.RemoveAll(upOptions.noAttach...)
 		attach = attachSet.Elements()
+
+		if upOptions.stdin != "" {
+			service, err := project.GetService(upOptions.stdin)
+			if err != nil {
+				return err
+			}
+			if !service.StdinOpen {
+				return fmt.Errorf("service %q must set stdin_open to receive input", upOptions.stdin)
+			}
+			if !attachSet.Has(upOptions.stdin) {
+				return fmt.Errorf("cannot send input to service %q, which is not attached", upOptions.stdin)
+			}
+		}
+	}
+
+	limit, ok := project.Environment[ComposeRestartLimit]
+	if !ok {
+		limit = defaultRestartLimit
+	}
+	restartLimit, err := api.ParseRestartLimit(limit)
+	if err != nil {
+		return fmt.Errorf("invalid %s value: %w", ComposeRestartLimit, err)
 	}
 
 	timeout := time.Duration(upOptions.waitTimeout) * time.Second
@@ -329,7 +386,10 @@ func runUp(
 			Watch:          upOptions.watch,
 			Services:       services,
 			NavigationMenu: upOptions.navigationMenu && ui.Mode != "plain",
+			Stdin:          upOptions.stdin,
+			RestartLimit:   restartLimit,
 		},
+		TTL: upOptions.ttl,
 	})
 }
 
@@ -45,11 +45,11 @@ func waitCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service)
 		Use:   "wait SERVICE [SERVICE...] [OPTIONS]",
 		Short: "Block until containers of all (or specified) services stop.",
 		Args:  cli.RequiresMinArgs(1),
-		RunE: Adapt(func(ctx context.Context, services []string) error {
+		RunE: p.withServiceGroups(dockerCli, Adapt(func(ctx context.Context, services []string) error {
 			opts.services = services
 			statusCode, err = runWait(ctx, dockerCli, backend, &opts)
 			return err
-		}),
+		})),
 		PostRun: func(cmd *cobra.Command, args []string) {
 			os.Exit(int(statusCode))
 		},
@@ -49,12 +49,12 @@ func watchCommand(p *ProjectOptions, dockerCli command.Cli, backend api.Service)
 		PreRunE: Adapt(func(ctx context.Context, args []string) error {
 			return nil
 		}),
-		RunE: AdaptCmd(func(ctx context.Context, cmd *cobra.Command, args []string) error {
+		RunE: p.withServiceGroups(dockerCli, AdaptCmd(func(ctx context.Context, cmd *cobra.Command, args []string) error {
 			if cmd.Parent().Name() == "alpha" {
 				logrus.Warn("watch command is now available as a top level command")
 			}
 			return runWatch(ctx, dockerCli, backend, watchOpts, buildOpts, args)
-		}),
+		})),
 		ValidArgsFunction: completeServiceNames(dockerCli, p),
 	}
 
@@ -0,0 +1,154 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package formatter
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// LogLevel is the severity of a log line, as detected from common log formats
+type LogLevel int
+
+const (
+	// LevelUnknown is set on lines which don't declare their level
+	LevelUnknown LogLevel = iota
+	LevelTrace
+	LevelDebug
+	LevelInfo
+	LevelWarn
+	LevelError
+	LevelFatal
+)
+
+var levelNames = map[string]LogLevel{
+	"trace":     LevelTrace,
+	"debug":     LevelDebug,
+	"info":      LevelInfo,
+	"notice":    LevelInfo,
+	"warn":      LevelWarn,
+	"warning":   LevelWarn,
+	"error":     LevelError,
+	"err":       LevelError,
+	"fatal":     LevelFatal,
+	"critical":  LevelFatal,
+	"crit":      LevelFatal,
+	"panic":     LevelFatal,
+	"alert":     LevelFatal,
+	"emergency": LevelFatal,
+	"emerg":     LevelFatal,
+}
+
+// ParseLogLevel parses the minimum level of log lines to show
+func ParseLogLevel(s string) (LogLevel, error) {
+	if s == "" {
+		return LevelUnknown, nil
+	}
+	level, ok := levelNames[strings.ToLower(s)]
+	if !ok {
+		return LevelUnknown, fmt.Errorf("invalid log level %q, expected one of trace, debug, info, warn, error or fatal", s)
+	}
+	return level, nil
+}
+
+// jsonLevelKeys are the fields structured loggers commonly set the level of JSON log lines in
+var jsonLevelKeys = []string{"level", "lvl", "severity", "log.level", "loglevel"}
+
+var (
+	logfmtLevel    = regexp.MustCompile(`(?:^|\s)(?:level|lvl|severity)="?([a-zA-Z]+)"?(?:\s|$)`)
+	syslogPriority = regexp.MustCompile(`^<(\d{1,3})>`)
+)
+
+// detectLevel detects the level of a log line formatted as JSON, logfmt or with a syslog priority
+func detectLevel(line string) LogLevel {
+	if strings.HasPrefix(line, "{") {
+		var fields map[string]any
+		if err := json.Unmarshal([]byte(line), &fields); err == nil {
+			for _, key := range jsonLevelKeys {
+				switch value := fields[key].(type) {
+				case string:
+					return levelNames[strings.ToLower(value)]
+				case float64:
+					return numericLevel(value)
+				}
+			}
+			return LevelUnknown
+		}
+	}
+	if match := syslogPriority.FindStringSubmatch(line); match != nil {
+		priority, err := strconv.Atoi(match[1])
+		if err == nil && priority <= 191 {
+			return syslogLevel(priority % 8)
+		}
+	}
+	if match := logfmtLevel.FindStringSubmatch(line); match != nil {
+		return levelNames[strings.ToLower(match[1])]
+	}
+	return LevelUnknown
+}
+
+// numericLevel maps numeric levels, as set by bunyan and pino loggers, to a LogLevel
+func numericLevel(value float64) LogLevel {
+	switch {
+	case value >= 60:
+		return LevelFatal
+	case value >= 50:
+		return LevelError
+	case value >= 40:
+		return LevelWarn
+	case value >= 30:
+		return LevelInfo
+	case value >= 20:
+		return LevelDebug
+	case value >= 10:
+		return LevelTrace
+	}
+	return LevelUnknown
+}
+
+// syslogLevel maps a syslog severity to a LogLevel
+func syslogLevel(severity int) LogLevel {
+	switch severity {
+	case 0, 1, 2:
+		return LevelFatal
+	case 3:
+		return LevelError
+	case 4:
+		return LevelWarn
+	case 5, 6:
+		return LevelInfo
+	}
+	return LevelDebug
+}
+
+// levelColor highlights log lines by level: errors in red, warnings in yellow, debug and trace lines faint
+func levelColor(level LogLevel) colorFunc {
+	switch level {
+	case LevelFatal:
+		return makeColorFunc("31;1")
+	case LevelError:
+		return makeColorFunc("31")
+	case LevelWarn:
+		return makeColorFunc("33")
+	case LevelDebug, LevelTrace:
+		return makeColorFunc(FAINT)
+	}
+	return monochrome
+}
@@ -0,0 +1,83 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package formatter
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestDetectLevel(t *testing.T) {
+	for line, expected := range map[string]LogLevel{
+		`{"level":"warn","msg":"disk almost full"}`:              LevelWarn,
+		`{"severity":"ERROR","message":"connection refused"}`:    LevelError,
+		`{"level":30,"msg":"listening"}`:                         LevelInfo,
+		`{"msg":"no level"}`:                                     LevelUnknown,
+		`time=2024-01-01T00:00:00Z level=debug msg="cache miss"`: LevelDebug,
+		`ts=1 lvl="error" msg=failed`:                            LevelError,
+		`<11>Jan  1 00:00:00 app[1]: failed`:                     LevelError,
+		`<14>Jan  1 00:00:00 app[1]: started`:                    LevelInfo,
+		`<12>Jan  1 00:00:00 app[1]: slow`:                       LevelWarn,
+		`plain text mentioning level=warn`:                       LevelWarn,
+		`plain text`:                                             LevelUnknown,
+		`{not json level=error`:                                  LevelError,
+	} {
+		assert.Equal(t, detectLevel(line), expected, line)
+	}
+}
+
+func TestParseLogLevel(t *testing.T) {
+	level, err := ParseLogLevel("WARNING")
+	assert.NilError(t, err)
+	assert.Equal(t, level, LevelWarn)
+	level, err = ParseLogLevel("")
+	assert.NilError(t, err)
+	assert.Equal(t, level, LevelUnknown)
+	_, err = ParseLogLevel("verbose")
+	assert.ErrorContains(t, err, `invalid log level "verbose"`)
+}
+
+func TestLogConsumerLevel(t *testing.T) {
+	var out bytes.Buffer
+	consumer := NewLevelLogConsumer(context.TODO(), &out, &out, false, false, false, LevelWarn)
+	consumer.Log("app", `{"level":"info","msg":"started"}`)
+	consumer.Log("app", `{"level":"error","msg":"failed"}`+"\n\tat main.go:12")
+	consumer.Log("app", "level=debug msg=retrying\nretry details")
+	consumer.Log("db", "plain output")
+	assert.Equal(t, out.String(), `{"level":"error","msg":"failed"}`+"\n\tat main.go:12\nplain output\n")
+}
+
+func TestLogConsumerLevelConcurrent(t *testing.T) {
+	consumer := NewLevelLogConsumer(context.TODO(), io.Discard, io.Discard, false, false, false, LevelWarn)
+	consumer.Register("app")
+	var wg sync.WaitGroup
+	for _, write := range []func(string, string){consumer.Log, consumer.Err} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				write("app", "level=error msg=failed\nstack trace")
+			}
+		}()
+	}
+	wg.Wait()
+}
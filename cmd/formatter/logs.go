@@ -40,10 +40,17 @@ type logConsumer struct {
 	color      bool
 	prefix     bool
 	timestamp  bool
+	level      LogLevel
 }
 
 // NewLogConsumer creates a new LogConsumer
 func NewLogConsumer(ctx context.Context, stdout, stderr io.Writer, color, prefix, timestamp bool) api.LogConsumer {
+	return NewLevelLogConsumer(ctx, stdout, stderr, color, prefix, timestamp, LevelUnknown)
+}
+
+// NewLevelLogConsumer creates a new LogConsumer which skips lines detected with a level lower than level, unless
+// LevelUnknown which shows all lines
+func NewLevelLogConsumer(ctx context.Context, stdout, stderr io.Writer, color, prefix, timestamp bool, level LogLevel) api.LogConsumer {
 	return &logConsumer{
 		ctx:        ctx,
 		presenters: sync.Map{},
@@ -53,6 +60,7 @@ func NewLogConsumer(ctx context.Context, stdout, stderr io.Writer, color, prefix
 		color:      color,
 		prefix:     prefix,
 		timestamp:  timestamp,
+		level:      level,
 	}
 }
 
@@ -125,6 +133,13 @@ func (l *logConsumer) write(w io.Writer, container, message string) {
 	p := l.getPresenter(container)
 	timestamp := time.Now().Format(jsonmessage.RFC3339NanoFixed)
 	for _, line := range strings.Split(message, "\n") {
+		level := p.lineLevel(line)
+		if level != LevelUnknown && level < l.level {
+			continue
+		}
+		if l.color && !disableAnsi {
+			line = levelColor(level)(line)
+		}
 		if l.timestamp {
 			_, _ = fmt.Fprintf(w, "%s%s%s\n", p.prefix, timestamp, line)
 		} else {
@@ -159,6 +174,23 @@ type presenter struct {
 	colors colorFunc
 	name   string
 	prefix string
+	// level of the last line the container logged with a detected level, guarded by mu as stdout and stderr
+	// are consumed concurrently
+	mu    sync.Mutex
+	level LogLevel
+}
+
+// lineLevel returns the level detected for line, or the one of the previous line for lines without level, like
+// stack traces
+func (p *presenter) lineLevel(line string) LogLevel {
+	level := detectLevel(line)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if level == LevelUnknown {
+		return p.level
+	}
+	p.level = level
+	return level
 }
 
 func (p *presenter) setPrefix(width int) {
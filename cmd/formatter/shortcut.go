@@ -22,6 +22,8 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"slices"
+	"sync"
 	"syscall"
 	"time"
 
@@ -93,6 +95,62 @@ func (kw *KeyboardWatch) newContext(ctx context.Context) context.CancelFunc {
 	return cancel
 }
 
+type KeyboardPause struct {
+	// mu guards Paused and switching, as pause is switched from the keyboard goroutine while the menu is printed
+	// along with logs
+	mu        sync.Mutex
+	Paused    bool
+	switching bool
+	PauseFn   func(ctx context.Context, projectName string, options api.PauseOptions) error
+	UnPauseFn func(ctx context.Context, projectName string, options api.PauseOptions) error
+}
+
+func (kp *KeyboardPause) isPaused() bool {
+	kp.mu.Lock()
+	defer kp.mu.Unlock()
+	return kp.Paused
+}
+
+// beginSwitch returns the function to pause or unpause services, or false if a switch is already in progress
+func (kp *KeyboardPause) beginSwitch() (func(ctx context.Context, projectName string, options api.PauseOptions) error, string, bool) {
+	kp.mu.Lock()
+	defer kp.mu.Unlock()
+	if kp.switching {
+		return nil, "", false
+	}
+	kp.switching = true
+	if kp.Paused {
+		return kp.UnPauseFn, "Unpause", true
+	}
+	return kp.PauseFn, "Pause", true
+}
+
+// endSwitch records the outcome of a switch started by beginSwitch
+func (kp *KeyboardPause) endSwitch(err error) {
+	kp.mu.Lock()
+	defer kp.mu.Unlock()
+	kp.switching = false
+	if err == nil {
+		kp.Paused = !kp.Paused
+	}
+}
+
+type KeyboardStdin struct {
+	Services []string
+	Target   string
+	Selected string
+	WriteFn  func(ctx context.Context, service string, data []byte) error
+}
+
+// nextService selects the service following the last one which received input
+func (ks *KeyboardStdin) nextService() string {
+	if ks.Selected == "" {
+		return ks.Services[0]
+	}
+	i := slices.Index(ks.Services, ks.Selected)
+	return ks.Services[(i+1)%len(ks.Services)]
+}
+
 type KEYBOARD_LOG_LEVEL int
 
 const (
@@ -104,6 +162,8 @@ const (
 type LogKeyboard struct {
 	kError                KeyboardError
 	Watch                 KeyboardWatch
+	Pause                 KeyboardPause
+	Stdin                 KeyboardStdin
 	IsDockerDesktopActive bool
 	IsWatchConfigured     bool
 	logLevel              KEYBOARD_LOG_LEVEL
@@ -123,6 +183,7 @@ func NewKeyboardManager(ctx context.Context, isDockerDesktopActive, isWatchConfi
 		services []string,
 		options api.WatchOptions,
 	) error,
+	pauseFn, unPauseFn func(ctx context.Context, projectName string, options api.PauseOptions) error,
 ) {
 	km := LogKeyboard{}
 	km.IsDockerDesktopActive = isDockerDesktopActive
@@ -132,11 +193,22 @@ func NewKeyboardManager(ctx context.Context, isDockerDesktopActive, isWatchConfi
 	km.Watch.Watching = false
 	km.Watch.WatchFn = watchFn
 
+	km.Pause.PauseFn = pauseFn
+	km.Pause.UnPauseFn = unPauseFn
+
 	km.signalChannel = sc
 
 	KeyboardManager = &km
 }
 
+// EnableStdin lets the menu route terminal input to services, starting with target if set
+func (lk *LogKeyboard) EnableStdin(services []string, target string, writeFn func(ctx context.Context, service string, data []byte) error) {
+	lk.Stdin.Services = services
+	lk.Stdin.Target = target
+	lk.Stdin.Selected = target
+	lk.Stdin.WriteFn = writeFn
+}
+
 func (lk *LogKeyboard) ClearKeyboardInfo() {
 	lk.clearNavigationMenu()
 }
@@ -213,7 +285,20 @@ func (lk *LogKeyboard) navigationMenu() string {
 		isEnabled = " Disable"
 	}
 	watchInfo = watchInfo + shortcutKeyColor("w") + navColor(isEnabled+" Watch")
-	return openDDInfo + openDDUI + watchInfo
+
+	pauseInfo := navColor("   ") + shortcutKeyColor("p") + navColor(" Pause")
+	if lk.Pause.isPaused() {
+		pauseInfo = navColor("   ") + shortcutKeyColor("p") + navColor(" Unpause")
+	}
+
+	var stdinInfo string
+	switch {
+	case lk.Stdin.Target != "":
+		stdinInfo = navColor("   ") + shortcutKeyColor("esc") + navColor(" Stop sending input to "+lk.Stdin.Target)
+	case len(lk.Stdin.Services) > 0:
+		stdinInfo = navColor("   ") + shortcutKeyColor("i") + navColor(" Send input to "+lk.Stdin.nextService())
+	}
+	return openDDInfo + openDDUI + watchInfo + pauseInfo + stdinInfo
 }
 
 func (lk *LogKeyboard) clearNavigationMenu() {
@@ -320,7 +405,58 @@ func (lk *LogKeyboard) StartWatch(ctx context.Context, doneCh chan bool, project
 	}
 }
 
+func (lk *LogKeyboard) switchPause(ctx context.Context, project *types.Project, options api.UpOptions) {
+	eg.Go(tracing.EventWrapFuncForErrGroup(ctx, "menu/pause", tracing.SpanOptions{},
+		func(ctx context.Context) error {
+			fn, action, ok := lk.Pause.beginSwitch()
+			if !ok {
+				return nil
+			}
+			err := fn(ctx, project.Name, api.PauseOptions{
+				Services: options.Start.Services,
+				Project:  project,
+			})
+			lk.Pause.endSwitch(err)
+			if err != nil {
+				lk.keyboardError(action, err)
+				return err
+			}
+			lk.printNavigationMenu()
+			return nil
+		}),
+	)
+}
+
+// forwardInput sends a key to the service receiving input, and returns false if the key is to be handled by the menu
+func (lk *LogKeyboard) forwardInput(ctx context.Context, event keyboard.KeyEvent) bool {
+	var data []byte
+	switch {
+	case event.Key == keyboard.KeyCtrlC:
+		return false
+	case event.Key == keyboard.KeyEsc:
+		lk.Stdin.Target = ""
+		lk.printNavigationMenu()
+		return true
+	case event.Key == keyboard.KeyEnter:
+		data = []byte("\n")
+	case event.Key == 0:
+		data = []byte(string(event.Rune))
+	case event.Key <= keyboard.KeySpace || event.Key == keyboard.KeyBackspace2:
+		data = []byte{byte(event.Key)}
+	default:
+		// other special keys can't be translated back into terminal input
+		return true
+	}
+	if err := lk.Stdin.WriteFn(ctx, lk.Stdin.Target, data); err != nil {
+		lk.keyboardError("Input", err)
+	}
+	return true
+}
+
 func (lk *LogKeyboard) HandleKeyEvents(event keyboard.KeyEvent, ctx context.Context, doneCh chan bool, project *types.Project, options api.UpOptions) {
+	if lk.Stdin.Target != "" && lk.forwardInput(ctx, event) {
+		return
+	}
 	switch kRune := event.Rune; kRune {
 	case 'v':
 		lk.openDockerDesktop(ctx, project)
@@ -342,6 +478,14 @@ func (lk *LogKeyboard) HandleKeyEvents(event keyboard.KeyEvent, ctx context.Cont
 		lk.StartWatch(ctx, doneCh, project, options)
 	case 'o':
 		lk.openDDComposeUI(ctx, project)
+	case 'p':
+		lk.switchPause(ctx, project, options)
+	case 'i':
+		if len(lk.Stdin.Services) > 0 {
+			lk.Stdin.Target = lk.Stdin.nextService()
+			lk.Stdin.Selected = lk.Stdin.Target
+			lk.printNavigationMenu()
+		}
 	}
 	switch key := event.Key; key {
 	case keyboard.KeyCtrlC:
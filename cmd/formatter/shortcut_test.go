@@ -0,0 +1,101 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package formatter
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/eiannone/keyboard"
+	"gotest.tools/v3/assert"
+)
+
+func TestForwardInput(t *testing.T) {
+	var sent []string
+	lk := LogKeyboard{}
+	lk.EnableStdin([]string{"repl", "shell"}, "repl", func(ctx context.Context, service string, data []byte) error {
+		sent = append(sent, service+":"+string(data))
+		return nil
+	})
+
+	assert.Check(t, lk.forwardInput(context.TODO(), keyboard.KeyEvent{Rune: 'p'}))
+	assert.Check(t, lk.forwardInput(context.TODO(), keyboard.KeyEvent{Key: keyboard.KeyEnter}))
+	assert.Check(t, lk.forwardInput(context.TODO(), keyboard.KeyEvent{Key: keyboard.KeyCtrlD}))
+	assert.Check(t, lk.forwardInput(context.TODO(), keyboard.KeyEvent{Key: keyboard.KeyArrowUp}))
+	assert.Check(t, !lk.forwardInput(context.TODO(), keyboard.KeyEvent{Key: keyboard.KeyCtrlC}))
+	assert.DeepEqual(t, sent, []string{"repl:p", "repl:\n", "repl:\x04"})
+}
+
+func TestStdinNextService(t *testing.T) {
+	ks := KeyboardStdin{Services: []string{"repl", "shell"}}
+	assert.Equal(t, ks.nextService(), "repl")
+	ks.Selected = "repl"
+	assert.Equal(t, ks.nextService(), "shell")
+	ks.Selected = "shell"
+	assert.Equal(t, ks.nextService(), "repl")
+}
+
+func TestKeyboardPauseSwitch(t *testing.T) {
+	var calls []string
+	kp := KeyboardPause{
+		PauseFn: func(ctx context.Context, projectName string, options api.PauseOptions) error {
+			calls = append(calls, "pause")
+			return nil
+		},
+		UnPauseFn: func(ctx context.Context, projectName string, options api.PauseOptions) error {
+			calls = append(calls, "unpause")
+			return errors.New("failed")
+		},
+	}
+
+	fn, action, ok := kp.beginSwitch()
+	assert.Check(t, ok)
+	assert.Equal(t, action, "Pause")
+	_, _, ok = kp.beginSwitch()
+	assert.Check(t, !ok, "switch already in progress")
+	kp.endSwitch(fn(context.TODO(), "test", api.PauseOptions{}))
+	assert.Check(t, kp.isPaused())
+
+	fn, action, ok = kp.beginSwitch()
+	assert.Check(t, ok)
+	assert.Equal(t, action, "Unpause")
+	kp.endSwitch(fn(context.TODO(), "test", api.PauseOptions{}))
+	assert.Check(t, kp.isPaused(), "failed unpause keeps services paused")
+	assert.DeepEqual(t, calls, []string{"pause", "unpause"})
+}
+
+func TestKeyboardPauseConcurrent(t *testing.T) {
+	kp := KeyboardPause{}
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, _, ok := kp.beginSwitch(); ok {
+				kp.endSwitch(nil)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			kp.isPaused()
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,223 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package features
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/sirupsen/logrus"
+)
+
+// EnvFeatures lists features to enable, or to disable when prefixed by `-`, separated by commas
+const EnvFeatures = "COMPOSE_FEATURES"
+
+// Stability of a feature
+type Stability string
+
+const (
+	Stable       Stability = "stable"
+	Experimental Stability = "experimental"
+	Deprecated   Stability = "deprecated"
+)
+
+// Feature is a behavior of Compose which can be turned on or off
+type Feature struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Stability   Stability `json:"stability"`
+	Default     bool      `json:"default"`
+	// Env is the legacy environment variable which used to control the feature
+	Env string `json:"-"`
+}
+
+var (
+	GitRemote = Feature{
+		Name:        "git-remote",
+		Description: "Load Compose files from git repositories",
+		Stability:   Experimental,
+		Default:     true,
+		Env:         "COMPOSE_EXPERIMENTAL_GIT_REMOTE",
+	}
+	OCIRemote = Feature{
+		Name:        "oci-remote",
+		Description: "Load Compose files from OCI artifacts",
+		Stability:   Experimental,
+		Default:     true,
+		Env:         "COMPOSE_EXPERIMENTAL_OCI_REMOTE",
+	}
+	HTTPRemote = Feature{
+		Name:        "http-remote",
+		Description: "Load Compose files from http(s) URLs",
+		Stability:   Experimental,
+		Default:     true,
+		Env:         "COMPOSE_EXPERIMENTAL_HTTP_REMOTE",
+	}
+	BucketRemote = Feature{
+		Name:        "bucket-remote",
+		Description: "Load Compose files from s3:// and gcs:// object store URLs",
+		Stability:   Experimental,
+		Default:     true,
+	}
+	HostProcess = Feature{
+		Name:        "host-process",
+		Description: "Run commands declared by x-host-process on the host alongside containers",
+		Stability:   Experimental,
+		Default:     false,
+	}
+	ProjectHooks = Feature{
+		Name:        "hooks",
+		Description: "Run commands declared by x-hooks on the host around project lifecycle events",
+		Stability:   Experimental,
+		Default:     false,
+	}
+	WatchTar = Feature{
+		Name:        "watch-tar",
+		Description: "Sync files to containers with tar archives on watch",
+		Stability:   Experimental,
+		Default:     true,
+		Env:         "COMPOSE_EXPERIMENTAL_WATCH_TAR",
+	}
+)
+
+// All lists features known to Compose
+var All = []Feature{GitRemote, OCIRemote, HTTPRemote, BucketRemote, HostProcess, ProjectHooks, WatchTar}
+
+// State of a feature, and the setting it comes from
+type State struct {
+	Feature
+	Enabled bool   `json:"enabled"`
+	Source  string `json:"source"`
+}
+
+// Flags resolves features state from an environment, the process environment by default. A project environment,
+// including variables set by the project .env file, can be set to resolve features for this project
+type Flags struct {
+	mutex       sync.Mutex
+	environment types.Mapping
+}
+
+// NewFlags resolves features from environment, or from the process environment when nil
+func NewFlags(environment types.Mapping) *Flags {
+	return &Flags{environment: environment}
+}
+
+// WithEnvironment sets the environment to resolve features from
+func (f *Flags) WithEnvironment(environment types.Mapping) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.environment = environment
+}
+
+func (f *Flags) lookup(key string) (string, bool) {
+	if f != nil {
+		f.mutex.Lock()
+		defer f.mutex.Unlock()
+		if f.environment != nil {
+			v, ok := f.environment[key]
+			return v, ok
+		}
+	}
+	return os.LookupEnv(key)
+}
+
+// Enabled tells if feature is enabled
+func (f *Flags) Enabled(feature Feature) (bool, error) {
+	state, err := f.State(feature)
+	return state.Enabled, err
+}
+
+// State resolves feature state. An entry in COMPOSE_FEATURES takes precedence over the legacy environment variable
+func (f *Flags) State(feature Feature) (State, error) {
+	state := State{Feature: feature, Enabled: feature.Default, Source: "default"}
+	if v, ok := f.lookup(EnvFeatures); ok {
+		for _, name := range strings.Split(v, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			enabled := !strings.HasPrefix(name, "-")
+			name = strings.TrimPrefix(name, "-")
+			if !isKnown(name) {
+				return state, fmt.Errorf("%s: unknown feature %q", EnvFeatures, name)
+			}
+			if name == feature.Name {
+				state.Enabled, state.Source = enabled, EnvFeatures
+			}
+		}
+		if state.Source == EnvFeatures {
+			warnDeprecated(state)
+			return state, nil
+		}
+	}
+	if feature.Env != "" {
+		if v, ok := f.lookup(feature.Env); ok && v != "" {
+			enabled, err := strconv.ParseBool(v)
+			if err != nil {
+				return state, fmt.Errorf("%s environment variable expects boolean value: %w", feature.Env, err)
+			}
+			state.Enabled, state.Source = enabled, feature.Env
+			setting := feature.Name
+			if !enabled {
+				setting = "-" + setting
+			}
+			warnOnce(feature.Env, "%s is deprecated, use %s=%s instead", feature.Env, EnvFeatures, setting)
+		}
+	}
+	warnDeprecated(state)
+	return state, nil
+}
+
+// List resolves state of all features
+func (f *Flags) List() ([]State, error) {
+	states := make([]State, 0, len(All))
+	for _, feature := range All {
+		state, err := f.State(feature)
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+func isKnown(name string) bool {
+	for _, feature := range All {
+		if feature.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func warnDeprecated(state State) {
+	if state.Enabled && state.Stability == Deprecated {
+		warnOnce(state.Name, "feature %s is deprecated and will be removed in a future release", state.Name)
+	}
+}
+
+var warned sync.Map
+
+func warnOnce(key string, format string, args ...any) {
+	if _, loaded := warned.LoadOrStore(key, true); !loaded {
+		logrus.Warnf(format, args...)
+	}
+}
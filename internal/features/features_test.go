@@ -0,0 +1,52 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package features
+
+import (
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+)
+
+func TestFlags(t *testing.T) {
+	state, err := NewFlags(types.Mapping{}).State(OCIRemote)
+	assert.NilError(t, err)
+	assert.Equal(t, state.Enabled, true)
+	assert.Equal(t, state.Source, "default")
+
+	state, err = NewFlags(types.Mapping{OCIRemote.Env: "false"}).State(OCIRemote)
+	assert.NilError(t, err)
+	assert.Equal(t, state.Enabled, false)
+	assert.Equal(t, state.Source, OCIRemote.Env)
+
+	// COMPOSE_FEATURES takes precedence over legacy variable
+	state, err = NewFlags(types.Mapping{OCIRemote.Env: "false", EnvFeatures: "git-remote, oci-remote"}).State(OCIRemote)
+	assert.NilError(t, err)
+	assert.Equal(t, state.Enabled, true)
+	assert.Equal(t, state.Source, EnvFeatures)
+
+	enabled, err := NewFlags(types.Mapping{EnvFeatures: "-watch-tar"}).Enabled(WatchTar)
+	assert.NilError(t, err)
+	assert.Equal(t, enabled, false)
+
+	_, err = NewFlags(types.Mapping{EnvFeatures: "unknown"}).Enabled(WatchTar)
+	assert.ErrorContains(t, err, `unknown feature "unknown"`)
+
+	_, err = NewFlags(types.Mapping{GitRemote.Env: "maybe"}).Enabled(GitRemote)
+	assert.ErrorContains(t, err, "COMPOSE_EXPERIMENTAL_GIT_REMOTE environment variable expects boolean value")
+}
@@ -0,0 +1,153 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package ocipush
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	// ComposeProvenanceMediaType is the media type for the layer holding the in-toto provenance statement of the
+	// published artifact
+	ComposeProvenanceMediaType = "application/vnd.in-toto+json"
+	// ProvenancePredicateType is the SLSA provenance predicate type of the statement
+	ProvenancePredicateType = "https://slsa.dev/provenance/v1"
+	// ProvenanceBuildType identifies how the artifact was built in the provenance statement
+	ProvenanceBuildType = "https://docs.docker.com/reference/cli/docker/compose/publish/"
+)
+
+// ProvenanceSource describes the source repository the published Compose files were read from
+type ProvenanceSource struct {
+	Repository string `json:"repository,omitempty"`
+	Commit     string `json:"commit,omitempty"`
+	// Dirty is set when the working tree had uncommitted changes
+	Dirty bool `json:"dirty,omitempty"`
+}
+
+type provenanceStatement struct {
+	Type          string              `json:"_type"`
+	Subject       []provenanceSubject `json:"subject"`
+	PredicateType string              `json:"predicateType"`
+	Predicate     provenancePredicate `json:"predicate"`
+}
+
+type provenanceSubject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+type provenancePredicate struct {
+	BuildDefinition provenanceBuildDefinition `json:"buildDefinition"`
+	RunDetails      provenanceRunDetails      `json:"runDetails"`
+}
+
+type provenanceBuildDefinition struct {
+	BuildType            string                 `json:"buildType"`
+	ExternalParameters   map[string]any         `json:"externalParameters"`
+	ResolvedDependencies []provenanceDependency `json:"resolvedDependencies,omitempty"`
+}
+
+type provenanceDependency struct {
+	URI    string            `json:"uri"`
+	Digest map[string]string `json:"digest,omitempty"`
+}
+
+type provenanceRunDetails struct {
+	Builder  provenanceBuilder  `json:"builder"`
+	Metadata provenanceMetadata `json:"metadata"`
+}
+
+type provenanceBuilder struct {
+	ID      string            `json:"id"`
+	Version map[string]string `json:"version"`
+}
+
+type provenanceMetadata struct {
+	StartedOn time.Time `json:"startedOn"`
+}
+
+// ProvenanceLayer creates a layer holding an in-toto statement with a SLSA provenance predicate, listing the
+// published layers as subjects and source as the resolved dependency, if set
+func ProvenanceLayer(source *ProvenanceSource, layers []Pushable, now time.Time) (Pushable, error) {
+	statement := provenanceStatement{
+		Type:          "https://in-toto.io/Statement/v1",
+		Subject:       []provenanceSubject{},
+		PredicateType: ProvenancePredicateType,
+		Predicate: provenancePredicate{
+			BuildDefinition: provenanceBuildDefinition{
+				BuildType:          ProvenanceBuildType,
+				ExternalParameters: map[string]any{},
+			},
+			RunDetails: provenanceRunDetails{
+				Builder: provenanceBuilder{
+					ID:      "https://github.com/docker/compose",
+					Version: map[string]string{"docker-compose": api.ComposeVersion},
+				},
+				Metadata: provenanceMetadata{StartedOn: now.UTC()},
+			},
+		},
+	}
+	for _, layer := range layers {
+		statement.Subject = append(statement.Subject, provenanceSubject{
+			Name:   LayerName(layer.Descriptor),
+			Digest: map[string]string{layer.Descriptor.Digest.Algorithm().String(): layer.Descriptor.Digest.Encoded()},
+		})
+	}
+	if source != nil {
+		statement.Predicate.BuildDefinition.ExternalParameters["source"] = source
+		if source.Repository != "" && source.Commit != "" {
+			statement.Predicate.BuildDefinition.ResolvedDependencies = []provenanceDependency{{
+				URI:    "git+" + source.Repository + "@" + source.Commit,
+				Digest: map[string]string{"gitCommit": source.Commit},
+			}}
+		}
+	}
+	data, err := json.Marshal(statement)
+	if err != nil {
+		return Pushable{}, err
+	}
+	return Pushable{
+		Descriptor: v1.Descriptor{
+			MediaType: ComposeProvenanceMediaType,
+			Digest:    digest.FromBytes(data),
+			Size:      int64(len(data)),
+			Annotations: map[string]string{
+				"com.docker.compose.version": api.ComposeVersion,
+				"in-toto.io/predicate-type":  ProvenancePredicateType,
+			},
+		},
+		Data: data,
+	}, nil
+}
+
+// LayerName returns the file name a layer was published from
+func LayerName(descriptor v1.Descriptor) string {
+	for _, annotation := range []string{"com.docker.compose.file", "com.docker.compose.envfile", "com.docker.compose.content"} {
+		if name, ok := descriptor.Annotations[annotation]; ok {
+			return name
+		}
+	}
+	if descriptor.MediaType == ComposeSetupMediaType {
+		return "compose-setup.yaml"
+	}
+	return descriptor.MediaType
+}
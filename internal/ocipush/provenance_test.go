@@ -0,0 +1,52 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package ocipush
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	"gotest.tools/v3/assert"
+)
+
+func TestProvenanceLayer(t *testing.T) {
+	compose := []byte("services:\n  web:\n    image: nginx\n")
+	layers := []Pushable{
+		{Descriptor: DescriptorForComposeFile("/project/compose.yaml", compose), Data: compose},
+		{Descriptor: DescriptorForSetupFile([]byte("prompts: []")), Data: []byte("prompts: []")},
+	}
+	source := &ProvenanceSource{Repository: "https://github.com/acme/app.git", Commit: "0123456789abcdef"}
+
+	layer, err := ProvenanceLayer(source, layers, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert.NilError(t, err)
+	assert.Equal(t, layer.Descriptor.MediaType, ComposeProvenanceMediaType)
+	assert.Equal(t, layer.Descriptor.Size, int64(len(layer.Data)))
+	assert.Equal(t, layer.Descriptor.Digest, digest.FromBytes(layer.Data))
+
+	var statement provenanceStatement
+	assert.NilError(t, json.Unmarshal(layer.Data, &statement))
+	assert.Equal(t, statement.PredicateType, ProvenancePredicateType)
+	assert.DeepEqual(t, statement.Subject, []provenanceSubject{
+		{Name: "compose.yaml", Digest: map[string]string{"sha256": layers[0].Descriptor.Digest.Encoded()}},
+		{Name: "compose-setup.yaml", Digest: map[string]string{"sha256": layers[1].Descriptor.Digest.Encoded()}},
+	})
+	assert.DeepEqual(t, statement.Predicate.BuildDefinition.ResolvedDependencies, []provenanceDependency{
+		{URI: "git+https://github.com/acme/app.git@0123456789abcdef", Digest: map[string]string{"gitCommit": "0123456789abcdef"}},
+	})
+}
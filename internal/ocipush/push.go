@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"net/http"
 	"path/filepath"
 	"time"
@@ -56,6 +57,17 @@ const (
 	ComposeEmptyConfigMediaType = "application/vnd.docker.compose.config.empty.v1+json"
 	// ComposeEnvFileMediaType is the media type for each Env File layer in the image manifest.
 	ComposeEnvFileMediaType = "application/vnd.docker.compose.envfile"
+	// ComposeSetupMediaType is the media type for the layer describing setup steps to run after the artifact is pulled.
+	ComposeSetupMediaType = "application/vnd.docker.compose.setup+yaml"
+	// ComposeContentMediaType is the media type for layers packaging local files referenced by the Compose files,
+	// as a gzipped tar archive to extract relative to the project directory.
+	ComposeContentMediaType = "application/vnd.docker.compose.content.v1.tar+gzip"
+	// ComposeVariantMediaType is the media type for Compose files overriding the base model for a platform or an
+	// environment. Loaders which don't support variants ignore these layers
+	ComposeVariantMediaType = "application/vnd.docker.compose.variant+yaml"
+	// ComposeBundleMediaType is the media type for a layer packaging the whole project directory, as a gzipped tar
+	// archive. The com.docker.compose.file annotation designates the entry Compose file at the root of the archive
+	ComposeBundleMediaType = "application/vnd.docker.compose.bundle.v1.tar+gzip"
 )
 
 // clientAuthStatusCodes are client (4xx) errors that are authentication
@@ -71,6 +83,15 @@ type Pushable struct {
 	Data       []byte
 }
 
+// IsComposeArtifact checks manifest describes a compose project, either by OCI 1.1 artifact type or by the
+// OCI 1.0 config media type fallback
+func IsComposeArtifact(manifest v1.Manifest) bool {
+	if manifest.ArtifactType != "" {
+		return manifest.ArtifactType == ComposeProjectArtifactType
+	}
+	return manifest.Config.MediaType == ComposeEmptyConfigMediaType
+}
+
 func DescriptorForComposeFile(path string, content []byte) v1.Descriptor {
 	return v1.Descriptor{
 		MediaType: ComposeYAMLMediaType,
@@ -95,13 +116,55 @@ func DescriptorForEnvFile(path string, content []byte) v1.Descriptor {
 	}
 }
 
+func DescriptorForSetupFile(content []byte) v1.Descriptor {
+	return v1.Descriptor{
+		MediaType: ComposeSetupMediaType,
+		Digest:    digest.FromString(string(content)),
+		Size:      int64(len(content)),
+		Annotations: map[string]string{
+			"com.docker.compose.version": api.ComposeVersion,
+		},
+	}
+}
+
+// DescriptorForContent describes a layer packaging local files, path is the directory relative to the project
+// directory the archive is extracted to
+func DescriptorForContent(path string, content []byte) v1.Descriptor {
+	return v1.Descriptor{
+		MediaType: ComposeContentMediaType,
+		Digest:    digest.FromBytes(content),
+		Size:      int64(len(content)),
+		Annotations: map[string]string{
+			"com.docker.compose.version": api.ComposeVersion,
+			"com.docker.compose.content": path,
+		},
+	}
+}
+
+// DescriptorForVariant describes a Compose file merged on top of the base model when variant is selected
+func DescriptorForVariant(variant string, path string, content []byte) v1.Descriptor {
+	return v1.Descriptor{
+		MediaType: ComposeVariantMediaType,
+		Digest:    digest.FromString(string(content)),
+		Size:      int64(len(content)),
+		Annotations: map[string]string{
+			"com.docker.compose.version": api.ComposeVersion,
+			"com.docker.compose.file":    filepath.Base(path),
+			"com.docker.compose.variant": variant,
+		},
+	}
+}
+
+// PushManifest pushes layers and the compose artifact manifest referencing them to the repository, and returns the
+// manifest descriptor
 func PushManifest(
 	ctx context.Context,
 	resolver *imagetools.Resolver,
 	named reference.Named,
 	layers []Pushable,
 	ociVersion api.OCIVersion,
-) error {
+	annotations map[string]string,
+) (v1.Descriptor, error) {
 	// Check if we need an extra empty layer for the manifest config
 	if ociVersion == api.OCIVersion1_1 || ociVersion == "" {
 		layers = append(layers, Pushable{Descriptor: v1.DescriptorEmptyJSON, Data: []byte("{}")})
@@ -110,46 +173,64 @@ func PushManifest(
 	layerDescriptors := make([]v1.Descriptor, len(layers))
 	for i := range layers {
 		layerDescriptors[i] = layers[i].Descriptor
+		if layerExists(ctx, resolver, named, layers[i].Descriptor) {
+			// identical content was already published to the repository
+			continue
+		}
 		if err := resolver.Push(ctx, named, layers[i].Descriptor, layers[i].Data); err != nil {
-			return err
+			return v1.Descriptor{}, err
 		}
 	}
 
 	if ociVersion != "" {
 		// if a version was explicitly specified, use it
-		return createAndPushManifest(ctx, resolver, named, layerDescriptors, ociVersion)
+		return createAndPushManifest(ctx, resolver, named, layerDescriptors, ociVersion, annotations)
 	}
 
 	// try to push in the OCI 1.1 format but fallback to OCI 1.0 on 4xx errors
 	// (other than auth) since it's most likely the result of the registry not
 	// having support
-	err := createAndPushManifest(ctx, resolver, named, layerDescriptors, api.OCIVersion1_1)
+	descriptor, err := createAndPushManifest(ctx, resolver, named, layerDescriptors, api.OCIVersion1_1, annotations)
 	var pushErr pusherrors.ErrUnexpectedStatus
 	if errors.As(err, &pushErr) && isNonAuthClientError(pushErr.StatusCode) {
 		// TODO(milas): show a warning here (won't work with logrus)
-		return createAndPushManifest(ctx, resolver, named, layerDescriptors, api.OCIVersion1_0)
+		return createAndPushManifest(ctx, resolver, named, layerDescriptors, api.OCIVersion1_0, annotations)
 	}
-	return err
+	return descriptor, err
 }
 
+// layerExists checks if the repository already holds a blob with the descriptor digest, so it doesn't need to be
+// uploaded again
+func layerExists(ctx context.Context, resolver *imagetools.Resolver, named reference.Named, descriptor v1.Descriptor) bool {
+	ref, err := reference.WithDigest(reference.TrimNamed(named), descriptor.Digest)
+	if err != nil {
+		return false
+	}
+	_, _, err = resolver.Resolve(ctx, ref.String())
+	return err == nil
+}
+
+// createAndPushManifest pushes the manifest referencing layers and returns its descriptor
 func createAndPushManifest(
 	ctx context.Context,
 	resolver *imagetools.Resolver,
 	named reference.Named,
 	layers []v1.Descriptor,
 	ociVersion api.OCIVersion,
-) error {
-	toPush, err := generateManifest(layers, ociVersion)
+	annotations map[string]string,
+) (v1.Descriptor, error) {
+	toPush, err := generateManifest(layers, ociVersion, annotations)
 	if err != nil {
-		return err
+		return v1.Descriptor{}, err
 	}
 	for _, p := range toPush {
 		err = resolver.Push(ctx, named, p.Descriptor, p.Data)
 		if err != nil {
-			return err
+			return v1.Descriptor{}, err
 		}
 	}
-	return nil
+	// generateManifest lists the manifest last
+	return toPush[len(toPush)-1].Descriptor, nil
 }
 
 func isNonAuthClientError(statusCode int) bool {
@@ -167,7 +248,7 @@ func isNonAuthClientError(statusCode int) bool {
 	return true
 }
 
-func generateManifest(layers []v1.Descriptor, ociCompat api.OCIVersion) ([]Pushable, error) {
+func generateManifest(layers []v1.Descriptor, ociCompat api.OCIVersion, annotations map[string]string) ([]Pushable, error) {
 	var toPush []Pushable
 	var config v1.Descriptor
 	var artifactType string
@@ -202,15 +283,17 @@ func generateManifest(layers []v1.Descriptor, ociCompat api.OCIVersion) ([]Pusha
 		return nil, fmt.Errorf("unsupported OCI version: %s", ociCompat)
 	}
 
+	manifestAnnotations := map[string]string{
+		v1.AnnotationCreated: time.Now().Format(time.RFC3339),
+	}
+	maps.Copy(manifestAnnotations, annotations)
 	manifest, err := json.Marshal(v1.Manifest{
 		Versioned:    specs.Versioned{SchemaVersion: 2},
 		MediaType:    v1.MediaTypeImageManifest,
 		ArtifactType: artifactType,
 		Config:       config,
 		Layers:       layers,
-		Annotations: map[string]string{
-			"org.opencontainers.image.created": time.Now().Format(time.RFC3339),
-		},
+		Annotations:  manifestAnnotations,
 	})
 	if err != nil {
 		return nil, err
@@ -0,0 +1,60 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package ocipush
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+
+	"github.com/distribution/reference"
+	"github.com/sirupsen/logrus"
+)
+
+// SignatureReference returns the tag cosign stores the signature of the artifact referenced by ref under
+func SignatureReference(ref reference.Canonical) (reference.NamedTagged, error) {
+	dgst := ref.Digest()
+	return reference.WithTag(reference.TrimNamed(ref), fmt.Sprintf("%s-%s.sig", dgst.Algorithm(), dgst.Encoded()))
+}
+
+// signArgs returns the cosign command line signing ref, with key or keyless if key is empty
+func signArgs(ref reference.Canonical, key string) []string {
+	args := []string{"sign", "--yes"}
+	if key != "" {
+		args = append(args, "--key", key)
+	}
+	return append(args, ref.String())
+}
+
+// Sign signs the artifact referenced by digest using the cosign CLI, and returns the signature reference.
+// cosign output is streamed to stderr, as keyless signing prompts the user to authenticate
+func Sign(ctx context.Context, ref reference.Canonical, key string, stderr io.Writer) (reference.NamedTagged, error) {
+	if _, err := exec.LookPath("cosign"); err != nil {
+		return nil, fmt.Errorf("cosign is required to sign %s: %w", ref.String(), err)
+	}
+	cmd := exec.CommandContext(ctx, "cosign", signArgs(ref, key)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = stderr
+	cmd.Stderr = stderr
+	logrus.Debugf("Executing cosign with args: %v", cmd.Args[1:])
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to sign %s: %w", ref.String(), err)
+	}
+	return SignatureReference(ref)
+}
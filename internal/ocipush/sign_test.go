@@ -0,0 +1,37 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package ocipush
+
+import (
+	"testing"
+
+	"github.com/distribution/reference"
+	"gotest.tools/v3/assert"
+)
+
+func TestSignatureReference(t *testing.T) {
+	named, err := reference.ParseNormalizedNamed("registry.example.com/acme/app:v1@sha256:2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824")
+	assert.NilError(t, err)
+	ref := named.(reference.Canonical)
+
+	sig, err := SignatureReference(ref)
+	assert.NilError(t, err)
+	assert.Equal(t, sig.String(), "registry.example.com/acme/app:sha256-2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824.sig")
+
+	assert.DeepEqual(t, signArgs(ref, ""), []string{"sign", "--yes", ref.String()})
+	assert.DeepEqual(t, signArgs(ref, "cosign.key"), []string{"sign", "--yes", "--key", "cosign.key", ref.String()})
+}
@@ -19,6 +19,7 @@ package api
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -45,6 +46,8 @@ type Service interface {
 	Stop(ctx context.Context, projectName string, options StopOptions) error
 	// Up executes the equivalent to a `compose up`
 	Up(ctx context.Context, project *types.Project, options UpOptions) error
+	// Apply recreates a single service with minimal engine calls
+	Apply(ctx context.Context, project *types.Project, options ApplyOptions) error
 	// Down executes the equivalent to a `compose down`
 	Down(ctx context.Context, projectName string, options DownOptions) error
 	// Logs executes the equivalent to a `compose logs`
@@ -75,7 +78,12 @@ type Service interface {
 	Events(ctx context.Context, projectName string, options EventsOptions) error
 	// Port executes the equivalent to a `compose port`
 	Port(ctx context.Context, projectName string, service string, port uint16, options PortOptions) (string, int, error)
-	// Publish executes the equivalent to a `compose publish`
+	// PortForward executes the equivalent to a `compose port-forward`
+	PortForward(ctx context.Context, projectName string, options PortForwardOptions) error
+	// Diff executes the equivalent to a `compose diff`
+	Diff(ctx context.Context, projectName string, service string, options DiffOptions) ([]FileChange, error)
+	// Publish executes the equivalent to a `compose publish`. A project without Compose files on disk, read from
+	// stdin or built in memory, is published as a single Compose file serialized from its model
 	Publish(ctx context.Context, project *types.Project, repository string, options PublishOptions) error
 	// Images executes the equivalent of a `compose images`
 	Images(ctx context.Context, projectName string, options ImagesOptions) ([]ImageSummary, error)
@@ -97,6 +105,20 @@ type Service interface {
 	Commit(ctx context.Context, projectName string, options CommitOptions) error
 	// Generate generates a Compose Project from existing containers
 	Generate(ctx context.Context, options GenerateOptions) (*types.Project, error)
+	// ImportK8s generates a Compose Project from Kubernetes manifests
+	ImportK8s(ctx context.Context, options ImportK8sOptions) (*types.Project, error)
+	// CopyArtifact copies a published compose OCI artifact to another repository
+	CopyArtifact(ctx context.Context, source string, destination string, options CopyArtifactOptions) error
+	// Snapshot saves project containers filesystem changes, volumes content and configuration into a bundle
+	Snapshot(ctx context.Context, projectName string, options SnapshotOptions) error
+	// RestoreSnapshot brings a project back to the state saved in a snapshot bundle
+	RestoreSnapshot(ctx context.Context, projectName string, options RestoreSnapshotOptions) error
+	// DNSCheck validates services DNS configuration and probes name resolution from their networks
+	DNSCheck(ctx context.Context, project *types.Project, options DNSCheckOptions) ([]DNSCheckResult, error)
+	// Debug runs a toolbox container sharing the namespaces of a service container, and returns its exit code
+	Debug(ctx context.Context, projectName string, options DebugOptions) (int, error)
+	// Chaos injects faults into project containers step by step, restoring them once each step is over
+	Chaos(ctx context.Context, project *types.Project, options ChaosOptions) error
 }
 
 type ScaleOptions struct {
@@ -163,7 +185,7 @@ type BuildOptions struct {
 
 // Apply mutates project according to build options
 func (o BuildOptions) Apply(project *types.Project) error {
-	platform := project.Environment["DOCKER_DEFAULT_PLATFORM"]
+	platform, variable := DefaultPlatform(project.Environment)
 	for name, service := range project.Services {
 		if service.Image == "" && service.Build == nil {
 			return fmt.Errorf("invalid service %q. Must specify either image or build", name)
@@ -174,7 +196,7 @@ func (o BuildOptions) Apply(project *types.Project) error {
 		}
 		if platform != "" {
 			if len(service.Build.Platforms) > 0 && !utils.StringContains(service.Build.Platforms, platform) {
-				return fmt.Errorf("service %q build.platforms does not support value set by DOCKER_DEFAULT_PLATFORM: %s", name, platform)
+				return fmt.Errorf("service %q build.platforms does not support value set by %s: %s", name, variable, platform)
 			}
 			service.Platform = platform
 		}
@@ -199,6 +221,8 @@ type CreateOptions struct {
 	Services []string
 	// Remove legacy containers for services that are not defined in the project
 	RemoveOrphans bool
+	// ConfirmRemoveOrphans lists orphan containers and asks for confirmation before removing them
+	ConfirmRemoveOrphans bool
 	// Ignore legacy containers for services that are not defined in the project
 	IgnoreOrphans bool
 	// Recreate define the strategy to apply on existing containers
@@ -213,6 +237,23 @@ type CreateOptions struct {
 	QuietPull bool
 	// AssumeYes assume "yes" as answer to all prompts and run non-interactively
 	AssumeYes bool
+	// ExplainRecreate reports the reasons containers get recreated
+	ExplainRecreate bool
+	// PullStreaming creates containers as soon as their own image has been pulled
+	PullStreaming bool
+}
+
+// ApplyOptions group options of the Apply API
+type ApplyOptions struct {
+	Build *BuildOptions
+	// Service is the service to converge
+	Service string
+	// Recreate define the strategy to apply on existing containers
+	Recreate string
+	// Timeout set delay to wait for container to gracefully stop before sending SIGKILL
+	Timeout *time.Duration
+	// QuietPull makes the pulling process quiet
+	QuietPull bool
 }
 
 // StartOptions group options of the Start API
@@ -234,6 +275,39 @@ type StartOptions struct {
 	Services       []string
 	Watch          bool
 	NavigationMenu bool
+	// Stdin is the service receiving terminal input while attached
+	Stdin string
+	// RestartLimit stops restarting crash looping services while attached
+	RestartLimit RestartLimit
+}
+
+// RestartLimit is the number of restarts of a service containers within a time window beyond which the service is
+// considered crash looping. A zero RestartLimit doesn't limit restarts
+type RestartLimit struct {
+	Restarts int
+	Window   time.Duration
+}
+
+// ParseRestartLimit parses a RESTARTS/WINDOW limit, such as 5/1m. 0 disables the limit
+func ParseRestartLimit(value string) (RestartLimit, error) {
+	if value == "0" {
+		return RestartLimit{}, nil
+	}
+	restarts, window, ok := strings.Cut(value, "/")
+	if !ok {
+		return RestartLimit{}, fmt.Errorf("invalid restart limit %q, expected RESTARTS/WINDOW", value)
+	}
+	var limit RestartLimit
+	var err error
+	limit.Restarts, err = strconv.Atoi(restarts)
+	if err != nil || limit.Restarts <= 0 {
+		return RestartLimit{}, fmt.Errorf("invalid restart limit %q, restarts must be a positive number", value)
+	}
+	limit.Window, err = time.ParseDuration(window)
+	if err != nil || limit.Window <= 0 {
+		return RestartLimit{}, fmt.Errorf("invalid restart limit %q, window must be a positive duration", value)
+	}
+	return limit, nil
 }
 
 type Cascade int
@@ -270,6 +344,8 @@ type StopOptions struct {
 type UpOptions struct {
 	Create CreateOptions
 	Start  StartOptions
+	// TTL schedules the removal of the project containers and networks once elapsed
+	TTL time.Duration
 }
 
 // DownOptions group options of the Down API
@@ -286,6 +362,8 @@ type DownOptions struct {
 	Volumes bool
 	// Services passed in the command line to be stopped
 	Services []string
+	// Force removes containers without stopping them gracefully, regardless of dependencies
+	Force bool
 }
 
 // ConfigOptions group options of the Config API
@@ -310,6 +388,8 @@ type PullOptions struct {
 	Quiet           bool
 	IgnoreFailures  bool
 	IgnoreBuildable bool
+	// FromHost is the address of a peer docker engine, typically ssh://, to load images from before using registry
+	FromHost string
 }
 
 // ImagesOptions group options of the Images API
@@ -327,6 +407,8 @@ type KillOptions struct {
 	Services []string
 	// Signal to send to containers
 	Signal string
+	// ServiceSignals overrides Signal for containers of the listed services
+	ServiceSignals map[string]string
 	// All can be set to true to try to kill all found containers, independently of their state
 	All bool
 }
@@ -371,6 +453,12 @@ type RunOptions struct {
 	QuietPull bool
 	// used by exec
 	Index int
+	// AuditLog, when set, records a transcript of the invocation to this file or oci:// repository
+	AuditLog string
+	// Session, when set, runs the exec command in a named session which survives client disconnects
+	Session string
+	// AttachSession is the name of a running exec session to attach to, instead of running a command
+	AttachSession string
 }
 
 // AttachOptions group options of the Attach API
@@ -404,6 +492,32 @@ type PortOptions struct {
 	Index    int
 }
 
+// PortForwardOptions group options of the PortForward API
+type PortForwardOptions struct {
+	Service string
+	Index   int
+	// Port is the port of the service container connections are forwarded to
+	Port uint16
+	// LocalPort is the host port to listen on, defaulting to Port
+	LocalPort uint16
+	// Address is the host address to listen on, defaulting to 127.0.0.1
+	Address string
+	// Image runs socat in the container network namespace to relay connections
+	Image string
+}
+
+// DiffOptions group options of the Diff API
+type DiffOptions struct {
+	Index int
+}
+
+// FileChange is a change in a container filesystem compared to its image
+type FileChange struct {
+	// Kind is A for added, C for changed and D for deleted
+	Kind string `json:"kind"`
+	Path string `json:"path"`
+}
+
 // OCIVersion controls manifest generation to ensure compatibility
 // with different registries.
 //
@@ -426,10 +540,39 @@ type PublishOptions struct {
 	ResolveImageDigests bool
 	WithEnvironment     bool
 	AssumeYes           bool
+	// SetupFile declares setup steps to run when the published application is pulled
+	SetupFile string
+	// TagOnly tags an artifact already published to the repository, designated as REPOSITORY:TAG@DIGEST, without
+	// pushing content. No project is required
+	TagOnly bool
+	// Sign signs the published artifact with cosign, keyless unless SignKey is set
+	Sign bool
+	// SignKey is the private key (path, URL or KMS reference) to sign the published artifact with
+	SignKey string
+	// Provenance attaches a SLSA provenance layer describing the source repository, builder and published files
+	Provenance bool
+	// WithContent packages build contexts, bind mounts sources and config files the project references
+	WithContent bool
+	// IncludeBuildContext packages build contexts, respecting .dockerignore, so services can be rebuilt from the artifact
+	IncludeBuildContext bool
+	// Annotations are added to the published OCI manifest
+	Annotations map[string]string
+	// Variants maps platforms, like linux/arm64, or environment names to Compose files merged on top of the project
+	// Compose files when the variant is selected by the loader
+	Variants map[string]string
+	// Stdin is the raw content of the Compose file read from stdin, if any, published as is rather than the
+	// interpolated model
+	Stdin []byte
 
 	OCIVersion OCIVersion
 }
 
+// CopyArtifactOptions group options of the CopyArtifact API
+type CopyArtifactOptions struct {
+	// IncludeImages also copies images referenced by the compose files to the destination registry
+	IncludeImages bool
+}
+
 func (e Event) String() string {
 	t := e.Timestamp.Format("2006-01-02 15:04:05.000000")
 	var attr []string
@@ -449,6 +592,8 @@ type PsOptions struct {
 	Project  *types.Project
 	All      bool
 	Services []string
+	// HostProcesses also lists processes declared by x-host-process, when no service is selected
+	HostProcesses bool
 }
 
 // CopyOptions group options of the cp API
@@ -561,6 +706,10 @@ type LogOptions struct {
 	Until      string
 	Follow     bool
 	Timestamps bool
+	// MergeByTimestamp interleaves log lines from all containers by their timestamp rather than arrival order
+	MergeByTimestamp bool
+	// HostProcesses also shows logs of processes declared by x-host-process
+	HostProcesses bool
 }
 
 // PauseOptions group options of the Pause API
@@ -578,6 +727,93 @@ type ExportOptions struct {
 	Output  string
 }
 
+// SnapshotOptions group options of the Snapshot API
+type SnapshotOptions struct {
+	// Project is the compose project used to define this app. Might be nil if user ran command just with project name
+	Project *types.Project
+	// Output is the directory to write the snapshot bundle to
+	Output string
+}
+
+// RestoreSnapshotOptions group options of the RestoreSnapshot API
+type RestoreSnapshotOptions struct {
+	// Input is the directory of the snapshot bundle
+	Input string
+}
+
+// DNSCheckOptions group options of the DNSCheck API
+type DNSCheckOptions struct {
+	// Services to check, all project services when empty
+	Services []string
+	// Lookup is the host name probes resolve
+	Lookup string
+	// Image is the image used to run probes, it must provide nslookup
+	Image string
+}
+
+// DNSCheckResult is the outcome of a name resolution probe run for a service
+type DNSCheckResult struct {
+	Service string
+	Network string
+	// Server is the DNS server queried, empty when the resolver configured for the service is used
+	Server   string
+	Status   string
+	Output   string
+	Duration time.Duration
+}
+
+const (
+	// DNSCheckOK means the probe resolved the lookup name
+	DNSCheckOK = "ok"
+	// DNSCheckFailed means the probe couldn't resolve the lookup name
+	DNSCheckFailed = "failed"
+	// DNSCheckSkipped means no probe could be run for the service
+	DNSCheckSkipped = "skipped"
+)
+
+// DebugOptions group options of the Debug API
+type DebugOptions struct {
+	Service string
+	// Index of the service container to debug, the first one when 0
+	Index int
+	// Image is the toolbox image run alongside the service container
+	Image string
+	// Command run in the toolbox container, the image default when empty
+	Command     []string
+	Tty         bool
+	Interactive bool
+	Privileged  bool
+}
+
+// ChaosOptions group options of the Chaos API
+type ChaosOptions struct {
+	Steps []ChaosStep
+	// Image is the helper image used to partition services network, it must provide iptables
+	Image string
+}
+
+// ChaosStep is a fault injected into project containers
+type ChaosStep struct {
+	// Action is one of ChaosKill, ChaosPause, ChaosPartition or ChaosWait
+	Action   string
+	Services []string
+	// Duration the fault lasts before it is restored, or the time to wait for ChaosWait
+	Duration time.Duration
+	// Signal sent by ChaosKill, SIGKILL when empty
+	Signal string
+}
+
+const (
+	// ChaosKill kills a random running container of a service
+	ChaosKill = "kill"
+	// ChaosPause pauses all containers of a service for a duration
+	ChaosPause = "pause"
+	// ChaosPartition drops network traffic between two services for a duration
+	ChaosPartition = "partition"
+	// ChaosWait waits for a duration before the next step
+	ChaosWait = "wait"
+)
+
 // CommitOptions group options of the Commit API
 type CommitOptions struct {
 	Service   string
@@ -598,6 +834,14 @@ type GenerateOptions struct {
 	Containers []string
 }
 
+// ImportK8sOptions group options of the ImportK8s API
+type ImportK8sOptions struct {
+	// ProjectName to set in the Compose file
+	ProjectName string
+	// Manifests are the files, or directories of files, declaring Kubernetes resources
+	Manifests []string
+}
+
 const (
 	// STARTING indicates that stack is being deployed
 	STARTING string = "Starting"
@@ -622,6 +866,25 @@ const (
 	RecreateNever = "never"
 )
 
+// PullPolicyMissingDigestCheck pulls image if missing, or if registry serves a distinct digest than the one local
+// image was pulled with
+const PullPolicyMissingDigestCheck = "missing+digest-check"
+
+// IsValidPullPolicy checks policy is supported by compose commands: any pull_policy of the compose specification,
+// including refresh policies like daily or every_12h, or missing+digest-check
+func IsValidPullPolicy(policy string) bool {
+	switch policy {
+	case types.PullPolicyAlways, types.PullPolicyNever, types.PullPolicyBuild, types.PullPolicyMissing,
+		types.PullPolicyIfNotPresent, types.PullPolicyRefresh, "daily", "weekly", PullPolicyMissingDigestCheck:
+		return true
+	}
+	if strings.HasPrefix(policy, "every_") {
+		_, _, err := types.ServiceConfig{PullPolicy: policy}.GetPullPolicy()
+		return err == nil
+	}
+	return false
+}
+
 // Stack holds the name and state of a compose application/stack
 type Stack struct {
 	ID          string
@@ -17,7 +17,10 @@
 package api
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/compose-spec/compose-go/v2/types"
 	"gotest.tools/v3/assert"
@@ -36,3 +39,112 @@ func TestRunOptionsEnvironmentMap(t *testing.T) {
 	assert.Equal(t, *env["ZOT"], "")
 	assert.Check(t, env["QIX"] == nil)
 }
+
+func TestRegistryRewrites(t *testing.T) {
+	rewrites, err := ParseRegistryRewrites("docker.io=registry.corp.local/hub, ghcr.io=registry.corp.local")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, rewrites, RegistryRewrites{
+		"docker.io": "registry.corp.local/hub",
+		"ghcr.io":   "registry.corp.local",
+	})
+
+	tests := map[string]string{
+		"nginx":                      "registry.corp.local/hub/library/nginx",
+		"bitnami/redis:7.2":          "registry.corp.local/hub/bitnami/redis:7.2",
+		"ghcr.io/acme/api:1.0":       "registry.corp.local/acme/api:1.0",
+		"quay.io/prometheus/node:v1": "quay.io/prometheus/node:v1",
+		"alpine@sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae": "registry.corp.local/hub/library/alpine@sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae",
+	}
+	for image, expected := range tests {
+		rewritten, err := rewrites.Rewrite(image)
+		assert.NilError(t, err)
+		assert.Equal(t, rewritten, expected)
+	}
+
+	_, err = ParseRegistryRewrites("docker.io")
+	assert.Error(t, err, `invalid registry rewrite "docker.io", expected REGISTRY=LOCATION`)
+}
+
+func TestRegistryMirrors(t *testing.T) {
+	mirrors, err := ParseRegistryMirrors("docker.io=mirror.corp.local:5000, docker.io=https://mirror.gcr.io/, ghcr.io=http://cache.corp.local")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, mirrors, RegistryMirrors{
+		"docker.io": {"mirror.corp.local:5000", "https://mirror.gcr.io"},
+		"ghcr.io":   {"http://cache.corp.local"},
+	})
+
+	_, err = ParseRegistryMirrors("docker.io")
+	assert.Error(t, err, `invalid registry mirror "docker.io", expected REGISTRY=MIRROR`)
+	_, err = ParseRegistryMirrors("docker.io=mirror.corp.local/hub")
+	assert.ErrorContains(t, err, `invalid mirror "mirror.corp.local/hub" for registry docker.io`)
+	_, err = ParseRegistryMirrors("docker.io=ftp://mirror.corp.local")
+	assert.ErrorContains(t, err, `invalid mirror "ftp://mirror.corp.local" for registry docker.io`)
+}
+
+func TestLoadRegistryTransports(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "registries.json")
+	transports, err := LoadRegistryTransports(file)
+	assert.NilError(t, err)
+	assert.Assert(t, transports == nil)
+
+	assert.NilError(t, os.WriteFile(file, []byte(`{
+  "registry.corp.local": {"ca": "corp-ca.pem", "proxy": "http://proxy.corp.local:3128"},
+  "mtls.corp.local:5000": {"cert": "/etc/certs/client.cert", "key": "client.key"}
+}`), 0o600))
+	transports, err = LoadRegistryTransports(file)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, transports, RegistryTransports{
+		"registry.corp.local":  {CA: filepath.Join(dir, "corp-ca.pem"), Proxy: "http://proxy.corp.local:3128"},
+		"mtls.corp.local:5000": {Cert: "/etc/certs/client.cert", Key: filepath.Join(dir, "client.key")},
+	})
+
+	assert.NilError(t, os.WriteFile(file, []byte(`{"registry.corp.local": {"cert": "client.cert"}}`), 0o600))
+	_, err = LoadRegistryTransports(file)
+	assert.ErrorContains(t, err, "registry registry.corp.local must set both cert and key")
+
+	assert.NilError(t, os.WriteFile(file, []byte(`{"registry.corp.local": {"proxy": "proxy.corp.local"}}`), 0o600))
+	_, err = LoadRegistryTransports(file)
+	assert.ErrorContains(t, err, `invalid proxy "proxy.corp.local" for registry registry.corp.local`)
+}
+
+func TestParseRestartLimit(t *testing.T) {
+	limit, err := ParseRestartLimit("5/1m")
+	assert.NilError(t, err)
+	assert.Equal(t, limit, RestartLimit{Restarts: 5, Window: time.Minute})
+
+	limit, err = ParseRestartLimit("0")
+	assert.NilError(t, err)
+	assert.Equal(t, limit, RestartLimit{})
+
+	_, err = ParseRestartLimit("5")
+	assert.Error(t, err, `invalid restart limit "5", expected RESTARTS/WINDOW`)
+	_, err = ParseRestartLimit("-1/1m")
+	assert.ErrorContains(t, err, "restarts must be a positive number")
+	_, err = ParseRestartLimit("5/forever")
+	assert.ErrorContains(t, err, "window must be a positive duration")
+}
+
+func TestServicePlatform(t *testing.T) {
+	project := &types.Project{
+		Environment: types.Mapping{
+			DockerDefaultPlatform: "linux/arm64",
+		},
+	}
+	platform, source := ServicePlatform(project, types.ServiceConfig{Platform: "linux/amd64"})
+	assert.Equal(t, platform, "linux/amd64")
+	assert.Equal(t, source, PlatformSourceService)
+
+	platform, source = ServicePlatform(project, types.ServiceConfig{})
+	assert.Equal(t, platform, "linux/arm64")
+	assert.Equal(t, source, DockerDefaultPlatform)
+
+	project.Environment[ComposeDefaultPlatform] = "linux/amd64"
+	platform, source = ServicePlatform(project, types.ServiceConfig{})
+	assert.Equal(t, platform, "linux/amd64")
+	assert.Equal(t, source, ComposeDefaultPlatform)
+
+	platform, source = ServicePlatform(&types.Project{}, types.ServiceConfig{})
+	assert.Equal(t, platform, "")
+	assert.Equal(t, source, PlatformSourceNative)
+}
@@ -17,6 +17,8 @@
 package api
 
 import (
+	"archive/tar"
+	"bufio"
 	"bytes"
 	"context"
 	"crypto/rand"
@@ -30,6 +32,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/distribution/reference"
 	"github.com/docker/buildx/builder"
 	"github.com/docker/buildx/util/imagetools"
 	"github.com/docker/cli/cli/command"
@@ -68,6 +71,7 @@ type DryRunClient struct {
 type execDetails struct {
 	container string
 	command   []string
+	attached  bool
 }
 
 // NewDryRunClient produces a DryRunClient
@@ -202,6 +206,16 @@ func (d *DryRunClient) CopyToContainer(ctx context.Context, container, path stri
 	if _, err := d.ContainerStatPath(ctx, container, path); err != nil {
 		return fmt.Errorf(" %s Could not find the file %s in container %s", DRYRUN_PREFIX, path, container)
 	}
+	// report the archived files, and consume the archive as the engine would so producers don't block
+	archive := tar.NewReader(content)
+	for {
+		header, err := archive.Next()
+		if err != nil {
+			break
+		}
+		fmt.Printf("%sCopying %s to %s:%s\n", DRYRUN_PREFIX, header.Name, container, path)
+	}
+	_, _ = io.Copy(io.Discard, content)
 	return nil
 }
 
@@ -250,8 +264,9 @@ func (d *DryRunClient) ImagePull(ctx context.Context, ref string, options image.
 	return rc, nil
 }
 
+// ImagePush simulates the push without contacting the registry, as the image may not have been pushed yet
 func (d *DryRunClient) ImagePush(ctx context.Context, ref string, options image.PushOptions) (io.ReadCloser, error) {
-	if _, _, err := d.resolver.Resolve(ctx, ref); err != nil {
+	if _, err := reference.ParseNormalizedNamed(ref); err != nil {
 		return nil, err
 	}
 	jsonMessage, err := json.Marshal(&jsonmessage.JSONMessage{
@@ -323,15 +338,61 @@ func (d *DryRunClient) ContainerExecCreate(ctx context.Context, container string
 }
 
 func (d *DryRunClient) ContainerExecStart(ctx context.Context, execID string, config containerType.ExecStartOptions) error {
-	v, ok := d.execs.LoadAndDelete(execID)
+	v, ok := d.execs.Load(execID)
 	if !ok {
 		return fmt.Errorf("invalid exec ID %q", execID)
 	}
 	details := v.(execDetails)
-	fmt.Printf("%sExecuting command %q in %s (detached mode)\n", DRYRUN_PREFIX, details.command, details.container)
+	if !details.attached {
+		d.execs.Delete(execID)
+		fmt.Printf("%sExecuting command %q in %s (detached mode)\n", DRYRUN_PREFIX, details.command, details.container)
+	}
 	return nil
 }
 
+func (d *DryRunClient) ContainerExecAttach(ctx context.Context, execID string, config containerType.ExecStartOptions) (moby.HijackedResponse, error) {
+	v, ok := d.execs.Load(execID)
+	if !ok {
+		return moby.HijackedResponse{}, fmt.Errorf("invalid exec ID %q", execID)
+	}
+	details := v.(execDetails)
+	details.attached = true
+	d.execs.Store(execID, details)
+	fmt.Printf("%sExecuting command %q in %s\n", DRYRUN_PREFIX, details.command, details.container)
+
+	// the simulated process produces no output and discards input until the connection is closed
+	conn, process := net.Pipe()
+	go func() {
+		_, _ = io.Copy(io.Discard, process)
+		_ = process.Close()
+	}()
+	return moby.HijackedResponse{
+		Conn:   conn,
+		Reader: bufio.NewReader(strings.NewReader("")),
+	}, nil
+}
+
+func (d *DryRunClient) ContainerExecInspect(ctx context.Context, execID string) (containerType.ExecInspect, error) {
+	v, ok := d.execs.LoadAndDelete(execID)
+	if !ok {
+		return d.apiClient.ContainerExecInspect(ctx, execID)
+	}
+	details := v.(execDetails)
+	return containerType.ExecInspect{
+		ExecID:      execID,
+		ContainerID: details.container,
+		Running:     false,
+		ExitCode:    0,
+	}, nil
+}
+
+func (d *DryRunClient) ContainerExecResize(ctx context.Context, execID string, options containerType.ResizeOptions) error {
+	if _, ok := d.execs.Load(execID); ok {
+		return nil
+	}
+	return d.apiClient.ContainerExecResize(ctx, execID, options)
+}
+
 // Functions delegated to original APIClient (not used by Compose or not modifying the Compose stack
 
 func (d *DryRunClient) ConfigList(ctx context.Context, options moby.ConfigListOptions) ([]swarm.Config, error) {
@@ -362,18 +423,6 @@ func (d *DryRunClient) ContainerDiff(ctx context.Context, container string) ([]c
 	return d.apiClient.ContainerDiff(ctx, container)
 }
 
-func (d *DryRunClient) ContainerExecAttach(ctx context.Context, execID string, config containerType.ExecStartOptions) (moby.HijackedResponse, error) {
-	return moby.HijackedResponse{}, errors.New("interactive exec is not supported in dry-run mode")
-}
-
-func (d *DryRunClient) ContainerExecInspect(ctx context.Context, execID string) (containerType.ExecInspect, error) {
-	return d.apiClient.ContainerExecInspect(ctx, execID)
-}
-
-func (d *DryRunClient) ContainerExecResize(ctx context.Context, execID string, options containerType.ResizeOptions) error {
-	return d.apiClient.ContainerExecResize(ctx, execID, options)
-}
-
 func (d *DryRunClient) ContainerExport(ctx context.Context, container string) (io.ReadCloser, error) {
 	return d.apiClient.ContainerExport(ctx, container)
 }
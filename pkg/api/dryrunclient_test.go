@@ -0,0 +1,66 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	containerType "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"gotest.tools/v3/assert"
+)
+
+func TestDryRunAttachedExec(t *testing.T) {
+	ctx := context.Background()
+	d := &DryRunClient{}
+
+	created, err := d.ContainerExecCreate(ctx, "web", containerType.ExecOptions{Cmd: []string{"tar", "-x"}})
+	assert.NilError(t, err)
+
+	resp, err := d.ContainerExecAttach(ctx, created.ID, containerType.ExecStartOptions{})
+	assert.NilError(t, err)
+	_, err = io.Copy(resp.Conn, strings.NewReader("archive content"))
+	assert.NilError(t, err)
+	out, err := io.ReadAll(resp.Reader)
+	assert.NilError(t, err)
+	assert.Equal(t, len(out), 0)
+	resp.Close()
+
+	assert.NilError(t, d.ContainerExecStart(ctx, created.ID, containerType.ExecStartOptions{}))
+	assert.NilError(t, d.ContainerExecResize(ctx, created.ID, containerType.ResizeOptions{}))
+
+	inspect, err := d.ContainerExecInspect(ctx, created.ID)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, inspect, containerType.ExecInspect{ExecID: created.ID, ContainerID: "web"})
+}
+
+func TestDryRunImagePush(t *testing.T) {
+	// a nil resolver would fail the test if the registry was contacted
+	d := &DryRunClient{}
+
+	rc, err := d.ImagePush(context.Background(), "registry.example.com/app:1.0", image.PushOptions{})
+	assert.NilError(t, err)
+	out, err := io.ReadAll(rc)
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(string(out), `"status":"Pushed"`))
+
+	_, err = d.ImagePush(context.Background(), "Invalid:Reference", image.PushOptions{})
+	assert.ErrorContains(t, err, "invalid reference format")
+}
@@ -31,6 +31,8 @@ const (
 	ServiceLabel = "com.docker.compose.service"
 	// ConfigHashLabel stores configuration hash for a compose service
 	ConfigHashLabel = "com.docker.compose.config-hash"
+	// ConfigFieldsHashLabel stores hashes of the service configuration attributes, to explain a configuration change
+	ConfigFieldsHashLabel = "com.docker.compose.config-hash.fields"
 	// ContainerNumberLabel stores the container index of a replicated service
 	ContainerNumberLabel = "com.docker.compose.container-number"
 	// VolumeLabel allow to track resource related to a compose volume
@@ -47,6 +49,8 @@ const (
 	OneoffLabel = "com.docker.compose.oneoff"
 	// SlugLabel stores unique slug used for one-off container identity
 	SlugLabel = "com.docker.compose.slug"
+	// SessionLabel stores the name of the exec session a one-off container runs
+	SessionLabel = "com.docker.compose.session"
 	// ImageDigestLabel stores digest of the container image used to run service
 	ImageDigestLabel = "com.docker.compose.image"
 	// DependenciesLabel stores service dependencies
@@ -57,6 +61,8 @@ const (
 	ImageBuilderLabel = "com.docker.compose.image.builder"
 	// ContainerReplaceLabel is set when container is created to replace another container (recreated)
 	ContainerReplaceLabel = "com.docker.compose.replace"
+	// ProtectLabel set to "true" exempts a container from orphan cleanup
+	ProtectLabel = "com.docker.compose.protect"
 )
 
 // ComposeVersion is the compose tool version as declared by label VersionLabel
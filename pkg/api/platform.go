@@ -0,0 +1,55 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package api
+
+import (
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+const (
+	// ComposeDefaultPlatform sets the platform services of a project run on when they don't declare one. It can be
+	// set in the project .env file, and takes precedence over DockerDefaultPlatform
+	ComposeDefaultPlatform = "COMPOSE_DEFAULT_PLATFORM"
+	// DockerDefaultPlatform is the docker CLI default platform
+	DockerDefaultPlatform = "DOCKER_DEFAULT_PLATFORM"
+	// PlatformSourceService means platform is set by service `platform` attribute
+	PlatformSourceService = "service"
+	// PlatformSourceNative means service runs on the engine native platform
+	PlatformSourceNative = "native"
+)
+
+// DefaultPlatform returns the platform services without a `platform` attribute run on, and the variable setting it
+func DefaultPlatform(env types.Mapping) (string, string) {
+	for _, variable := range []string{ComposeDefaultPlatform, DockerDefaultPlatform} {
+		if platform := env[variable]; platform != "" {
+			return platform, variable
+		}
+	}
+	return "", ""
+}
+
+// ServicePlatform returns the platform service runs on, and its source: PlatformSourceService, the variable setting
+// the default platform, or PlatformSourceNative with an empty platform when the engine decides
+func ServicePlatform(project *types.Project, service types.ServiceConfig) (string, string) {
+	if service.Platform != "" {
+		return service.Platform, PlatformSourceService
+	}
+	if platform, variable := DefaultPlatform(project.Environment); platform != "" {
+		return platform, variable
+	}
+	return "", PlatformSourceNative
+}
@@ -0,0 +1,179 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/distribution/reference"
+)
+
+// RegistryRewrites maps a registry domain to the location images from this registry must be retrieved from,
+// typically a mirror in an air-gapped environment. Location can include a repository path prefix
+type RegistryRewrites map[string]string
+
+// ParseRegistryRewrites parses a comma-separated list of REGISTRY=LOCATION rewrites
+func ParseRegistryRewrites(value string) (RegistryRewrites, error) {
+	rewrites := RegistryRewrites{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		registry, location, ok := strings.Cut(entry, "=")
+		if !ok || registry == "" || location == "" {
+			return nil, fmt.Errorf("invalid registry rewrite %q, expected REGISTRY=LOCATION", entry)
+		}
+		rewrites[registry] = location
+	}
+	return rewrites, nil
+}
+
+// RewriteNamed returns the reference to use for named, keeping its tag and digest
+func (r RegistryRewrites) RewriteNamed(named reference.Named) (reference.Named, error) {
+	location, ok := r[reference.Domain(named)]
+	if !ok {
+		return named, nil
+	}
+	rewritten, err := reference.ParseNormalizedNamed(strings.TrimSuffix(location, "/") + "/" + reference.Path(named))
+	if err != nil {
+		return nil, fmt.Errorf("invalid registry rewrite for %s: %w", reference.Domain(named), err)
+	}
+	if tagged, ok := named.(reference.Tagged); ok {
+		rewritten, err = reference.WithTag(rewritten, tagged.Tag())
+		if err != nil {
+			return nil, err
+		}
+	}
+	if digested, ok := named.(reference.Digested); ok {
+		rewritten, err = reference.WithDigest(rewritten, digested.Digest())
+		if err != nil {
+			return nil, err
+		}
+	}
+	return rewritten, nil
+}
+
+// Rewrite returns the image reference to use for image
+func (r RegistryRewrites) Rewrite(image string) (string, error) {
+	if len(r) == 0 {
+		return image, nil
+	}
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := r[reference.Domain(named)]; !ok {
+		return image, nil
+	}
+	rewritten, err := r.RewriteNamed(named)
+	if err != nil {
+		return "", err
+	}
+	return reference.FamiliarString(rewritten), nil
+}
+
+// RegistryMirrors maps a registry domain to the mirrors tried, in order, before the registry itself to pull content
+// from this registry. A mirror is a host, optionally with a port and an http:// or https:// scheme
+type RegistryMirrors map[string][]string
+
+// ParseRegistryMirrors parses a comma-separated list of REGISTRY=MIRROR entries. A registry can be listed multiple
+// times to declare several mirrors
+func ParseRegistryMirrors(value string) (RegistryMirrors, error) {
+	mirrors := RegistryMirrors{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		registry, mirror, ok := strings.Cut(entry, "=")
+		if !ok || registry == "" || mirror == "" {
+			return nil, fmt.Errorf("invalid registry mirror %q, expected REGISTRY=MIRROR", entry)
+		}
+		if err := mirrors.Add(registry, mirror); err != nil {
+			return nil, err
+		}
+	}
+	return mirrors, nil
+}
+
+// Add registers mirror for registry, after the mirrors already declared
+func (m RegistryMirrors) Add(registry string, mirror string) error {
+	if strings.Contains(mirror, "://") {
+		u, err := url.Parse(mirror)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || strings.Trim(u.Path, "/") != "" {
+			return fmt.Errorf("invalid mirror %q for registry %s, expected a host with an optional http:// or https:// scheme", mirror, registry)
+		}
+	} else if strings.Contains(mirror, "/") {
+		return fmt.Errorf("invalid mirror %q for registry %s, expected a host with an optional http:// or https:// scheme", mirror, registry)
+	}
+	m[registry] = append(m[registry], strings.TrimSuffix(mirror, "/"))
+	return nil
+}
+
+// RegistryTransport configures the connection to a registry serving oci:// compose artifacts
+type RegistryTransport struct {
+	// CA is a PEM bundle of certificate authorities trusted to serve the registry, in addition to the system ones
+	CA string `json:"ca,omitempty"`
+	// Cert and Key are the PEM client certificate and private key presented to a registry requiring mutual TLS
+	Cert string `json:"cert,omitempty"`
+	Key  string `json:"key,omitempty"`
+	// Proxy is the URL of the proxy used to reach the registry, overriding HTTP_PROXY and HTTPS_PROXY
+	Proxy string `json:"proxy,omitempty"`
+}
+
+// RegistryTransports maps a registry host, optionally with a port, to the transport settings used to reach it
+type RegistryTransports map[string]RegistryTransport
+
+// LoadRegistryTransports reads transport settings declared by file, if it exists. Relative paths to certificates are
+// resolved from the file location
+func LoadRegistryTransports(file string) (RegistryTransports, error) {
+	b, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var transports RegistryTransports
+	if err := json.Unmarshal(b, &transports); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	for registry, transport := range transports {
+		if (transport.Cert == "") != (transport.Key == "") {
+			return nil, fmt.Errorf("%s: registry %s must set both cert and key for client authentication", file, registry)
+		}
+		if transport.Proxy != "" {
+			u, err := url.Parse(transport.Proxy)
+			if err != nil || u.Scheme == "" || u.Host == "" {
+				return nil, fmt.Errorf("%s: invalid proxy %q for registry %s", file, transport.Proxy, registry)
+			}
+		}
+		for _, path := range []*string{&transport.CA, &transport.Cert, &transport.Key} {
+			if *path != "" && !filepath.IsAbs(*path) {
+				*path = filepath.Join(filepath.Dir(file), *path)
+			}
+		}
+		transports[registry] = transport
+	}
+	return transports, nil
+}
@@ -0,0 +1,129 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
+
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/progress"
+)
+
+func (s *composeService) Apply(ctx context.Context, project *types.Project, options api.ApplyOptions) error {
+	return progress.RunWithTitle(ctx, func(ctx context.Context) error {
+		return s.apply(ctx, project, options)
+	}, s.stdinfo(), "Applying")
+}
+
+// apply converges a single service, skipping dependencies, and project resources checks when service is already
+// running and those exist
+func (s *composeService) apply(ctx context.Context, project *types.Project, options api.ApplyOptions) error {
+	project, err := project.WithSelectedServices([]string{options.Service}, types.IgnoreDependencies)
+	if err != nil {
+		return err
+	}
+	project = project.WithoutUnnecessaryResources()
+
+	err = s.ensureImagesExists(ctx, project, options.Build, options.QuietPull, nil)
+	if err != nil {
+		return err
+	}
+
+	observedState, err := s.getContainers(ctx, project.Name, oneOffExclude, true)
+	if err != nil {
+		return err
+	}
+
+	// networks and volumes were created along with existing service containers, and as nil maps, won't be
+	// checked for divergence by convergence. Only a first deployment, or resources added to the model since
+	// containers were created, require those to be ensured.
+	ensure := len(observedState.filter(isService(options.Service))) == 0
+	if !ensure {
+		ensure, err = s.missingProjectResources(ctx, project)
+		if err != nil {
+			return err
+		}
+	}
+	var networks, volumes map[string]string
+	if ensure {
+		prepareNetworks(project)
+		networks, err = s.ensureNetworks(ctx, project)
+		if err != nil {
+			return err
+		}
+		volumes, err = s.ensureProjectVolumes(ctx, project, true)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = newConvergence([]string{options.Service}, observedState, networks, volumes, s).apply(ctx, project, api.CreateOptions{
+		Services: []string{options.Service},
+		Recreate: options.Recreate,
+		Inherit:  true,
+		Timeout:  options.Timeout,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.start(ctx, project.Name, api.StartOptions{
+		Project:  project,
+		Services: []string{options.Service},
+	}, nil)
+}
+
+// missingProjectResources tells if a network or volume declared by project, other than external ones, doesn't exist
+func (s *composeService) missingProjectResources(ctx context.Context, project *types.Project) (bool, error) {
+	networks, err := s.apiClient().NetworkList(ctx, network.ListOptions{
+		Filters: filters.NewArgs(projectFilter(project.Name)),
+	})
+	if err != nil {
+		return false, err
+	}
+	existing := map[string]bool{}
+	for _, n := range networks {
+		existing[n.Name] = true
+	}
+	for _, n := range project.Networks {
+		if !bool(n.External) && !existing[n.Name] {
+			return true, nil
+		}
+	}
+
+	volumes, err := s.apiClient().VolumeList(ctx, volume.ListOptions{
+		Filters: filters.NewArgs(projectFilter(project.Name)),
+	})
+	if err != nil {
+		return false, err
+	}
+	existing = map[string]bool{}
+	for _, v := range volumes.Volumes {
+		existing[v.Name] = true
+	}
+	for _, v := range project.Volumes {
+		if !bool(v.External) && !existing[v.Name] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,134 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+func applyTestProject() *types.Project {
+	return &types.Project{
+		Name: "test",
+		Services: types.Services{
+			"web": {
+				Name:     "web",
+				Image:    "nginx",
+				Networks: map[string]*types.ServiceNetworkConfig{"default": nil},
+			},
+			"db": {Name: "db", Image: "postgres"},
+		},
+		Networks: types.Networks{"default": {Name: "test_default"}},
+	}
+}
+
+func TestApplyUpToDate(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	apiClient, cli := prepareMocks(mockCtrl)
+	cli.EXPECT().ConfigFile().Return(&configfile.ConfigFile{}).AnyTimes()
+	tested := composeService{dockerCli: cli}
+	project := applyTestProject()
+
+	apiClient.EXPECT().ImageInspect(gomock.Any(), "nginx").Return(image.InspectResponse{ID: "sha256:nginx"}, nil).AnyTimes()
+	hash, err := ServiceHash(project.Services["web"])
+	assert.NilError(t, err)
+	web := testContainer("web", "f5a2b3c4d5e6f7a8", false)
+	web.State = ContainerRunning
+	web.Labels[api.ConfigHashLabel] = hash
+	web.Labels[api.ContainerNumberLabel] = "1"
+	apiClient.EXPECT().ContainerList(gomock.Any(), gomock.Any()).Return([]container.Summary{web}, nil).AnyTimes()
+	apiClient.EXPECT().NetworkList(gomock.Any(), gomock.Any()).Return([]network.Summary{{Name: "test_default"}}, nil)
+	apiClient.EXPECT().VolumeList(gomock.Any(), gomock.Any()).Return(volume.ListResponse{}, nil)
+
+	// the running container is up to date: networks and volumes exist so aren't ensured, nor the container
+	// recreated, and other services are left untouched
+	err = tested.apply(context.TODO(), project, api.ApplyOptions{Service: "web"})
+	assert.NilError(t, err)
+}
+
+func TestApplyFirstDeployment(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	apiClient, cli := prepareMocks(mockCtrl)
+	tested := composeService{dockerCli: cli}
+	project := applyTestProject()
+
+	apiClient.EXPECT().ImageInspect(gomock.Any(), "nginx").Return(image.InspectResponse{ID: "sha256:nginx"}, nil).AnyTimes()
+	apiClient.EXPECT().ContainerList(gomock.Any(), gomock.Any()).Return([]container.Summary{}, nil).AnyTimes()
+	// without a container for the service, project networks must be ensured before the container is created
+	apiClient.EXPECT().NetworkInspect(gomock.Any(), "test_default", gomock.Any()).Return(network.Inspect{}, errors.New("not found"))
+	apiClient.EXPECT().NetworkList(gomock.Any(), gomock.Any()).Return(nil, errors.New("network check"))
+
+	err := tested.apply(context.TODO(), project, api.ApplyOptions{Service: "web"})
+	assert.ErrorContains(t, err, "network check")
+}
+
+func TestApplyNewNetwork(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	apiClient, cli := prepareMocks(mockCtrl)
+	tested := composeService{dockerCli: cli}
+	project := applyTestProject()
+	project.Networks["backend"] = types.NetworkConfig{Name: "test_backend"}
+	project.Services["web"].Networks["backend"] = nil
+
+	apiClient.EXPECT().ImageInspect(gomock.Any(), "nginx").Return(image.InspectResponse{ID: "sha256:nginx"}, nil).AnyTimes()
+	web := testContainer("web", "f5a2b3c4d5e6f7a8", false)
+	web.State = ContainerRunning
+	web.Labels[api.ContainerNumberLabel] = "1"
+	apiClient.EXPECT().ContainerList(gomock.Any(), gomock.Any()).Return([]container.Summary{web}, nil).AnyTimes()
+	// the network added to the model since the container was created must be ensured
+	gomock.InOrder(
+		apiClient.EXPECT().NetworkList(gomock.Any(), gomock.Any()).Return([]network.Summary{{Name: "test_default"}}, nil),
+		apiClient.EXPECT().NetworkInspect(gomock.Any(), gomock.Any(), gomock.Any()).Return(network.Inspect{}, errors.New("not found")),
+		apiClient.EXPECT().NetworkList(gomock.Any(), gomock.Any()).Return(nil, errors.New("network check")),
+	)
+
+	err := tested.apply(context.TODO(), project, api.ApplyOptions{Service: "web"})
+	assert.ErrorContains(t, err, "network check")
+}
+
+func TestApplyRecreateNever(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	apiClient, cli := prepareMocks(mockCtrl)
+	cli.EXPECT().ConfigFile().Return(&configfile.ConfigFile{}).AnyTimes()
+	tested := composeService{dockerCli: cli}
+	project := applyTestProject()
+
+	apiClient.EXPECT().ImageInspect(gomock.Any(), "nginx").Return(image.InspectResponse{ID: "sha256:nginx"}, nil).AnyTimes()
+	web := testContainer("web", "f5a2b3c4d5e6f7a8", false)
+	web.State = ContainerRunning
+	web.Labels[api.ConfigHashLabel] = "outdated"
+	web.Labels[api.ContainerNumberLabel] = "1"
+	apiClient.EXPECT().ContainerList(gomock.Any(), gomock.Any()).Return([]container.Summary{web}, nil).AnyTimes()
+	apiClient.EXPECT().NetworkList(gomock.Any(), gomock.Any()).Return([]network.Summary{{Name: "test_default"}}, nil)
+	apiClient.EXPECT().VolumeList(gomock.Any(), gomock.Any()).Return(volume.ListResponse{}, nil)
+
+	// the configuration changed, but the recreate policy keeps the running container
+	err := tested.apply(context.TODO(), project, api.ApplyOptions{Service: "web", Recreate: api.RecreateNever})
+	assert.NilError(t, err)
+}
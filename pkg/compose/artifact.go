@@ -0,0 +1,183 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/distribution/reference"
+	"github.com/docker/buildx/util/imagetools"
+	"github.com/docker/compose/v2/internal/ocipush"
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/progress"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+func (s *composeService) CopyArtifact(ctx context.Context, source string, destination string, options api.CopyArtifactOptions) error {
+	return progress.RunWithTitle(ctx, func(ctx context.Context) error {
+		return s.copyArtifact(ctx, source, destination, options)
+	}, s.stdinfo(), "Copying")
+}
+
+func (s *composeService) copyArtifact(ctx context.Context, source string, destination string, options api.CopyArtifactOptions) error {
+	src, err := reference.ParseDockerRef(source)
+	if err != nil {
+		return err
+	}
+	dst, err := reference.ParseDockerRef(destination)
+	if err != nil {
+		return err
+	}
+
+	resolver := imagetools.New(imagetools.Opt{
+		Auth: s.configFile(),
+	})
+
+	content, _, err := resolver.Get(ctx, src.String())
+	if err != nil {
+		return err
+	}
+	var manifest v1.Manifest
+	err = json.Unmarshal(content, &manifest)
+	if err != nil {
+		return err
+	}
+	if !ocipush.IsComposeArtifact(manifest) {
+		return fmt.Errorf("%s is not a compose project OCI artifact", src.String())
+	}
+
+	if options.IncludeImages {
+		images, err := artifactImages(ctx, resolver, src, manifest)
+		if err != nil {
+			return err
+		}
+		for _, image := range images {
+			target, err := retargetReference(image, reference.Domain(dst))
+			if err != nil {
+				return err
+			}
+			err = s.copyReference(ctx, resolver, image, target)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return s.copyReference(ctx, resolver, src, dst)
+}
+
+// copyReference copies manifest and blobs for src to dst, preserving digests
+func (s *composeService) copyReference(ctx context.Context, resolver *imagetools.Resolver, src reference.Named, dst reference.Named) error {
+	w := progress.ContextWriter(ctx)
+	eventName := fmt.Sprintf("%s -> %s", reference.FamiliarString(src), reference.FamiliarString(dst))
+	w.Event(progress.Event{
+		ID:     eventName,
+		Text:   "Copying",
+		Status: progress.Working,
+	})
+	if !s.dryRun {
+		content, descriptor, err := resolver.Get(ctx, src.String())
+		if err == nil {
+			err = resolver.Copy(ctx, &imagetools.Source{Ref: src, Desc: descriptor}, dst)
+		}
+		if _, ok := dst.(reference.Tagged); ok && err == nil {
+			// Copy pushes by digest, we also need the manifest to be tagged
+			err = resolver.Push(ctx, dst, descriptor, content)
+		}
+		if err != nil {
+			w.Event(progress.ErrorEvent(eventName))
+			return err
+		}
+	}
+	w.Event(progress.Event{
+		ID:     eventName,
+		Text:   "Copied",
+		Status: progress.Done,
+	})
+	return nil
+}
+
+// artifactImages collects the images referenced by services in the compose files packaged as manifest layers
+func artifactImages(ctx context.Context, resolver *imagetools.Resolver, ref reference.Named, manifest v1.Manifest) ([]reference.Named, error) {
+	images := map[string]reference.Named{}
+	for _, layer := range manifest.Layers {
+		if layer.MediaType != ocipush.ComposeYAMLMediaType {
+			continue
+		}
+		digested, err := reference.WithDigest(ref, layer.Digest)
+		if err != nil {
+			return nil, err
+		}
+		content, _, err := resolver.Get(ctx, digested.String())
+		if err != nil {
+			return nil, err
+		}
+		var model struct {
+			Services map[string]struct {
+				Image string `yaml:"image"`
+			} `yaml:"services"`
+		}
+		if err := yaml.Unmarshal(content, &model); err != nil {
+			return nil, err
+		}
+		for name, service := range model.Services {
+			if service.Image == "" {
+				continue
+			}
+			if strings.Contains(service.Image, "$") {
+				logrus.Warnf("service %q: image %q relies on interpolation and can't be copied", name, service.Image)
+				continue
+			}
+			named, err := reference.ParseNormalizedNamed(service.Image)
+			if err != nil {
+				return nil, fmt.Errorf("service %q: %w", name, err)
+			}
+			images[named.String()] = reference.TagNameOnly(named)
+		}
+	}
+
+	var names []string
+	for name := range images {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	refs := make([]reference.Named, 0, len(names))
+	for _, name := range names {
+		refs = append(refs, images[name])
+	}
+	return refs, nil
+}
+
+// retargetReference moves ref to another registry domain, keeping repository path, tag and digest
+func retargetReference(ref reference.Named, domain string) (reference.Named, error) {
+	target, err := reference.ParseNormalizedNamed(domain + "/" + reference.Path(ref))
+	if err != nil {
+		return nil, err
+	}
+	if digested, ok := ref.(reference.Digested); ok {
+		return reference.WithDigest(target, digested.Digest())
+	}
+	if tagged, ok := ref.(reference.Tagged); ok {
+		return reference.WithTag(target, tagged.Tag())
+	}
+	return target, nil
+}
@@ -0,0 +1,57 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"testing"
+
+	"github.com/distribution/reference"
+	"gotest.tools/v3/assert"
+)
+
+func TestRetargetReference(t *testing.T) {
+	tests := []struct {
+		image  string
+		domain string
+		want   string
+	}{
+		{
+			image:  "nginx",
+			domain: "registry.example.com",
+			want:   "registry.example.com/library/nginx:latest",
+		},
+		{
+			image:  "ghcr.io/acme/api:1.2",
+			domain: "localhost:5000",
+			want:   "localhost:5000/acme/api:1.2",
+		},
+		{
+			image:  "ghcr.io/acme/api@sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae",
+			domain: "docker.io",
+			want:   "docker.io/acme/api@sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			named, err := reference.ParseNormalizedNamed(tt.image)
+			assert.NilError(t, err)
+			got, err := retargetReference(reference.TagNameOnly(named), tt.domain)
+			assert.NilError(t, err)
+			assert.Equal(t, got.String(), tt.want)
+		})
+	}
+}
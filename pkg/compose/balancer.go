@@ -0,0 +1,241 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/sirupsen/logrus"
+)
+
+// portModeBalanced makes a published port served by compose, balancing connections across service replicas
+const portModeBalanced = "balanced"
+
+// balancerBackendIP is the host address replicas publish balanced ports on, when the engine is local
+const balancerBackendIP = "127.0.0.1"
+
+// balancerRefresh is how long the list of replicas serving a balanced port is reused before being listed again
+const balancerRefresh = time.Second
+
+// balancerBackend returns the address replicas publish balanced ports on, and the host compose dials to reach them.
+// A local engine keeps replicas on loopback, a remote one reached over tcp has them publish on all interfaces.
+func (s *composeService) balancerBackend() (bindIP string, dialHost string, err error) {
+	host := s.dockerCli.DockerEndpoint().Host
+	if isLocalEngine(host) {
+		return balancerBackendIP, balancerBackendIP, nil
+	}
+	u, err := url.Parse(host)
+	if err != nil || u.Scheme != "tcp" || u.Hostname() == "" {
+		return "", "", fmt.Errorf("port mode %s requires a local engine or one reached over tcp://, not %s", portModeBalanced, host)
+	}
+	return "", u.Hostname(), nil
+}
+
+// balancedPorts returns the ports service publishes with mode balanced
+func balancedPorts(service types.ServiceConfig) []types.ServicePortConfig {
+	var ports []types.ServicePortConfig
+	for _, port := range service.Ports {
+		if port.Mode == portModeBalanced {
+			ports = append(ports, port)
+		}
+	}
+	return ports
+}
+
+func hasBalancedPorts(project *types.Project) bool {
+	for _, service := range project.Services {
+		if len(balancedPorts(service)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// checkBalancedPorts refuses balanced ports compose can't serve, before any container is created
+func (s *composeService) checkBalancedPorts(project *types.Project, options api.UpOptions) error {
+	if !hasBalancedPorts(project) {
+		return nil
+	}
+	if options.Start.Attach == nil {
+		return fmt.Errorf("ports with mode %s are only served while compose is attached, use `up` without --detach", portModeBalanced)
+	}
+	_, _, err := s.balancerBackend()
+	return err
+}
+
+// portBalancer forwards connections on a published port to the replicas of a service, in turn
+type portBalancer struct {
+	s       *composeService
+	project string
+	service string
+	port    types.ServicePortConfig
+	// bindIP is the address replicas publish the port on, dialHost the one compose reaches them at
+	bindIP   string
+	dialHost string
+	next     atomic.Uint64
+
+	mu       sync.Mutex
+	cached   []string
+	cachedAt time.Time
+}
+
+// startBalancers listens on balanced ports until ctx is done
+func (s *composeService) startBalancers(ctx context.Context, project *types.Project) error {
+	if !hasBalancedPorts(project) {
+		return nil
+	}
+	bindIP, dialHost, err := s.balancerBackend()
+	if err != nil {
+		return err
+	}
+	var listeners []net.Listener
+	for _, service := range project.Services {
+		for _, port := range balancedPorts(service) {
+			if port.Protocol != "" && port.Protocol != "tcp" {
+				return fmt.Errorf("service %q: port mode %s only supports tcp", service.Name, portModeBalanced)
+			}
+			l, err := net.Listen("tcp", net.JoinHostPort(port.HostIP, port.Published))
+			if err != nil {
+				for _, l := range listeners {
+					_ = l.Close()
+				}
+				return fmt.Errorf("service %q: %w", service.Name, err)
+			}
+			listeners = append(listeners, l)
+			b := &portBalancer{
+				s:        s,
+				project:  project.Name,
+				service:  service.Name,
+				port:     port,
+				bindIP:   bindIP,
+				dialHost: dialHost,
+			}
+			go b.serve(ctx, l)
+		}
+	}
+	go func() {
+		<-ctx.Done()
+		for _, l := range listeners {
+			_ = l.Close()
+		}
+	}()
+	return nil
+}
+
+func (b *portBalancer) serve(ctx context.Context, l net.Listener) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				logrus.Warnf("service %q: balanced port %s: %v", b.service, b.port.Published, err)
+			}
+			return
+		}
+		go b.forward(ctx, conn)
+	}
+}
+
+// forward proxies conn to the next running replica, until both directions are done
+func (b *portBalancer) forward(ctx context.Context, conn net.Conn) {
+	defer conn.Close() //nolint:errcheck
+	backends, err := b.backends(ctx)
+	if err != nil {
+		logrus.Warnf("service %q: %v", b.service, err)
+		return
+	}
+	if len(backends) == 0 {
+		logrus.Warnf("service %q: no running replica to serve port %s", b.service, b.port.Published)
+		return
+	}
+	backend := backends[(b.next.Add(1)-1)%uint64(len(backends))]
+	var dialer net.Dialer
+	upstream, err := dialer.DialContext(ctx, "tcp", backend)
+	if err != nil {
+		// replicas may have changed, list them again for the next connection
+		b.invalidate()
+		logrus.Warnf("service %q: %v", b.service, err)
+		return
+	}
+	defer upstream.Close() //nolint:errcheck
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		proxyHalf(upstream, conn)
+	}()
+	go func() {
+		defer wg.Done()
+		proxyHalf(conn, upstream)
+	}()
+	wg.Wait()
+}
+
+// proxyHalf copies src to dst, then signals dst no more data will be written.
+// Connections that can't be half-closed are closed, which also ends the opposite direction.
+func proxyHalf(dst, src net.Conn) {
+	_, _ = io.Copy(dst, src)
+	if cw, ok := dst.(interface{ CloseWrite() error }); ok {
+		_ = cw.CloseWrite()
+		return
+	}
+	_ = dst.Close()
+}
+
+// backends lists the addresses running replicas publish the balanced port on, reusing the last list for balancerRefresh
+func (b *portBalancer) backends(ctx context.Context) ([]string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.cached != nil && time.Since(b.cachedAt) < balancerRefresh {
+		return b.cached, nil
+	}
+	containers, err := b.s.getContainers(ctx, b.project, oneOffExclude, false, b.service)
+	if err != nil {
+		return nil, err
+	}
+	bindIP := b.bindIP
+	if bindIP == "" {
+		bindIP = "0.0.0.0"
+	}
+	backends := []string{}
+	for _, ctr := range containers.sorted() {
+		for _, p := range ctr.Ports {
+			if uint32(p.PrivatePort) == b.port.Target && p.Type == "tcp" && p.PublicPort != 0 && p.IP == bindIP {
+				backends = append(backends, net.JoinHostPort(b.dialHost, strconv.Itoa(int(p.PublicPort))))
+			}
+		}
+	}
+	b.cached, b.cachedAt = backends, time.Now()
+	return backends, nil
+}
+
+func (b *portBalancer) invalidate() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.cached = nil
+}
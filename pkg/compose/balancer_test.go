@@ -0,0 +1,142 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/cli/cli/context/docker"
+	"github.com/docker/docker/api/types/container"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+
+	compose "github.com/docker/compose/v2/pkg/api"
+)
+
+func TestPortBalancer(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	api, cli := prepareMocks(mockCtrl)
+	tested := &composeService{
+		dockerCli: cli,
+	}
+
+	var replicas []container.Summary
+	for _, id := range []string{"1", "2"} {
+		l, err := net.Listen("tcp", balancerBackendIP+":0")
+		assert.NilError(t, err)
+		defer l.Close() //nolint:errcheck
+		go func() {
+			conn, err := l.Accept()
+			if err == nil {
+				// only answer once the request is complete, which requires the balancer to half-close
+				request, _ := io.ReadAll(conn)
+				_, _ = conn.Write([]byte(id + ":" + string(request)))
+				_ = conn.Close()
+			}
+		}()
+		ctr := testContainer("web", id, false)
+		ctr.Ports = []container.Port{{
+			IP:          balancerBackendIP,
+			PrivatePort: 80,
+			PublicPort:  uint16(l.Addr().(*net.TCPAddr).Port),
+			Type:        "tcp",
+		}}
+		ctr.Labels[compose.ContainerNumberLabel] = id
+		replicas = append(replicas, ctr)
+	}
+	api.EXPECT().ContainerList(gomock.Any(), gomock.Any()).Return(replicas, nil).Times(1)
+
+	b := &portBalancer{
+		s:        tested,
+		project:  testProject,
+		service:  "web",
+		port:     types.ServicePortConfig{Target: 80, Published: "8080", Mode: portModeBalanced},
+		bindIP:   balancerBackendIP,
+		dialHost: balancerBackendIP,
+	}
+	var served []string
+	for range replicas {
+		served = append(served, balance(t, b, "ping"))
+	}
+	assert.DeepEqual(t, served, []string{"1:ping", "2:ping"})
+}
+
+// balance sends request through b on a tcp connection, half-closes it and returns the whole response
+func balance(t *testing.T, b *portBalancer, request string) string {
+	l, err := net.Listen("tcp", balancerBackendIP+":0")
+	assert.NilError(t, err)
+	defer l.Close() //nolint:errcheck
+	go func() {
+		conn, err := l.Accept()
+		if err == nil {
+			b.forward(context.TODO(), conn)
+		}
+	}()
+	client, err := net.Dial("tcp", l.Addr().String())
+	assert.NilError(t, err)
+	defer client.Close() //nolint:errcheck
+	_, err = client.Write([]byte(request))
+	assert.NilError(t, err)
+	assert.NilError(t, client.(*net.TCPConn).CloseWrite())
+	content, err := io.ReadAll(client)
+	assert.NilError(t, err)
+	return string(content)
+}
+
+func TestBalancerBackend(t *testing.T) {
+	tests := []struct {
+		host     string
+		bindIP   string
+		dialHost string
+		err      string
+	}{
+		{host: "unix:///var/run/docker.sock", bindIP: balancerBackendIP, dialHost: balancerBackendIP},
+		{host: "tcp://remote.example.com:2376", dialHost: "remote.example.com"},
+		{host: "ssh://user@remote.example.com", err: "port mode balanced requires a local engine or one reached over tcp://"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			_, cli := prepareMocks(mockCtrl)
+			cli.EXPECT().DockerEndpoint().Return(docker.Endpoint{EndpointMeta: docker.EndpointMeta{Host: tt.host}}).AnyTimes()
+			tested := &composeService{dockerCli: cli}
+			bindIP, dialHost, err := tested.balancerBackend()
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, bindIP, tt.bindIP)
+			assert.Equal(t, dialHost, tt.dialHost)
+		})
+	}
+}
+
+func TestCheckBalancedPortsDetached(t *testing.T) {
+	tested := &composeService{}
+	project := &types.Project{Services: types.Services{
+		"web": {Name: "web", Ports: []types.ServicePortConfig{{Target: 80, Published: "8080", Mode: portModeBalanced}}},
+	}}
+	err := tested.checkBalancedPorts(project, compose.UpOptions{})
+	assert.ErrorContains(t, err, "use `up` without --detach")
+}
@@ -110,6 +110,27 @@ func (s *composeService) build(ctx context.Context, project *types.Project, opti
 		return imageIDs, err
 	}
 
+	cache, err := projectPackageCache(project)
+	if err != nil {
+		return nil, err
+	}
+	if cache != nil && !s.dryRun && !options.Print {
+		address, err := s.ensurePackageCache(ctx, project, cache)
+		if err != nil {
+			return nil, err
+		}
+		withPackageCacheBuildArgs(project, address, cache)
+		for name := range serviceToBuild {
+			serviceToBuild[name] = project.Services[name]
+		}
+	}
+
+	if !s.dryRun && !options.Print {
+		if err := s.ensureBuildNetworks(ctx, project, serviceToBuild); err != nil {
+			return nil, err
+		}
+	}
+
 	bake, err := buildWithBake(s.dockerCli)
 	if err != nil {
 		return nil, err
@@ -270,7 +291,7 @@ func (s *composeService) build(ctx context.Context, project *types.Project, opti
 	return imageIDs, err
 }
 
-func (s *composeService) ensureImagesExists(ctx context.Context, project *types.Project, buildOpts *api.BuildOptions, quietPull bool) error {
+func (s *composeService) ensureImagesExists(ctx context.Context, project *types.Project, buildOpts *api.BuildOptions, quietPull bool, stream *pullStream) error {
 	for name, service := range project.Services {
 		if service.Provider == nil && service.Image == "" && service.Build == nil {
 			return fmt.Errorf("invalid service %q. Must specify either image or build", name)
@@ -284,7 +305,7 @@ func (s *composeService) ensureImagesExists(ctx context.Context, project *types.
 
 	err = tracing.SpanWrapFunc("project/pull", tracing.ProjectOptions(ctx, project),
 		func(ctx context.Context) error {
-			return s.pullRequiredImages(ctx, project, images, quietPull)
+			return s.pullRequiredImages(ctx, project, images, quietPull, stream)
 		},
 	)(ctx)
 	if err != nil {
@@ -383,8 +404,8 @@ func (s *composeService) getLocalImagesDigests(ctx context.Context, project *typ
 // keys that already exist.
 // Next, any keys without a value are resolved using the project environment.
 //
-// Finally, standard proxy variables based on the Docker client configuration are added, but will not overwrite
-// any values if already present.
+// Finally, standard proxy variables based on the project `x-proxy` configuration then on the Docker client
+// configuration are added, but will not overwrite any values if already present.
 func resolveAndMergeBuildArgs(dockerCli command.Cli, project *types.Project, service types.ServiceConfig, opts api.BuildOptions) types.MappingWithEquals {
 	result := make(types.MappingWithEquals).
 		OverrideBy(service.Build.Args).
@@ -393,10 +414,12 @@ func resolveAndMergeBuildArgs(dockerCli command.Cli, project *types.Project, ser
 
 	// proxy arguments do NOT override and should NOT have env resolution applied,
 	// so they're handled last
-	for k, v := range storeutil.GetProxyConfig(dockerCli) {
-		if _, ok := result[k]; !ok {
-			v := v
-			result[k] = &v
+	for _, proxy := range []map[string]string{projectProxy(project, service), storeutil.GetProxyConfig(dockerCli)} {
+		for k, v := range proxy {
+			if _, ok := result[k]; !ok {
+				v := v
+				result[k] = &v
+			}
 		}
 	}
 	return result
@@ -450,6 +473,9 @@ func (s *composeService) toBuildOptions(project *types.Project, service types.Se
 	if service.Build.Privileged {
 		allow = append(allow, entitlements.EntitlementSecurityInsecure.String())
 	}
+	if service.Build.Network == "host" {
+		allow = append(allow, entitlements.EntitlementNetworkHost.String())
+	}
 
 	imageLabels := getImageBuildLabels(project, service)
 
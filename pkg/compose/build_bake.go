@@ -20,6 +20,7 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"crypto/sha1"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -146,6 +147,7 @@ func (s *composeService) doBuildBake(ctx context.Context, project *types.Project
 	var (
 		group          bakeGroup
 		privileged     bool
+		hostNetwork    bool
 		read           []string
 		expectedImages = make(map[string]string, len(serviceToBeBuild)) // service name -> expected image
 	)
@@ -171,6 +173,9 @@ func (s *composeService) doBuildBake(ctx context.Context, project *types.Project
 		if slices.Contains(build.Entitlements, "security.insecure") {
 			privileged = true
 		}
+		if build.Network == "host" {
+			hostNetwork = true
+		}
 		if build.Privileged {
 			entitlements = append(entitlements, "security.insecure")
 			privileged = true
@@ -213,6 +218,7 @@ func (s *composeService) doBuildBake(ctx context.Context, project *types.Project
 			SSH:          toBakeSSH(append(build.SSH, options.SSHs...)),
 			Pull:         options.Pull,
 			NoCache:      options.NoCache,
+			NetworkMode:  build.Network,
 			ShmSize:      build.ShmSize,
 			Ulimits:      toBakeUlimits(build.Ulimits),
 			Entitlements: entitlements,
@@ -236,6 +242,14 @@ func (s *composeService) doBuildBake(ctx context.Context, project *types.Project
 	}
 	logrus.Debugf("bake build config:\n%s", string(b))
 
+	if s.dryRun {
+		close(ch) // nothing to display as bake won't run
+		if err := eg.Wait(); err != nil {
+			return nil, err
+		}
+		return s.dryRunBakeResults(ctx, expectedImages), nil
+	}
+
 	metadata, err := os.CreateTemp(os.TempDir(), "compose")
 	if err != nil {
 		return nil, err
@@ -256,6 +270,9 @@ func (s *composeService) doBuildBake(ctx context.Context, project *types.Project
 		if privileged {
 			args = append(args, "--allow", "security.insecure")
 		}
+		if hostNetwork {
+			args = append(args, "--allow", "network.host")
+		}
 	}
 
 	if options.Builder != "" {
@@ -348,6 +365,16 @@ func (s *composeService) doBuildBake(ctx context.Context, project *types.Project
 	return results, nil
 }
 
+func (s *composeService) dryRunBakeResults(ctx context.Context, expectedImages map[string]string) map[string]string {
+	cw := progress.ContextWriter(ctx)
+	results := map[string]string{}
+	for service, name := range expectedImages {
+		results[name] = fmt.Sprintf("dryRun-%x", sha1.Sum([]byte(service)))
+		cw.Event(progress.BuiltEvent(name))
+	}
+	return results
+}
+
 func additionalContexts(contexts types.Mapping) map[string]string {
 	ac := map[string]string{}
 	for k, v := range contexts {
@@ -0,0 +1,59 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+
+	"github.com/compose-spec/compose-go/v2/types"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+// ensureBuildNetworks creates the project networks referenced by `build.network` before the build
+// phase, so a build can reach a sibling service, and rewrites the reference to the engine network name.
+// Builtin modes and networks not declared by the project are passed to the builder as-is.
+func (s *composeService) ensureBuildNetworks(ctx context.Context, project *types.Project, services types.Services) error {
+	for name, service := range services {
+		if service.Build == nil {
+			continue
+		}
+		key := service.Build.Network
+		switch key {
+		case "", "none", "host", "default":
+			continue
+		}
+		nw, ok := project.Networks[key]
+		if !ok {
+			continue
+		}
+		if !nw.External {
+			nw.CustomLabels = nw.CustomLabels.
+				Add(api.NetworkLabel, key).
+				Add(api.ProjectLabel, project.Name).
+				Add(api.VersionLabel, api.ComposeVersion)
+		}
+		if _, err := s.ensureNetwork(ctx, project, key, &nw); err != nil {
+			return err
+		}
+		build := *service.Build
+		build.Network = nw.Name
+		service.Build = &build
+		services[name] = service
+	}
+	return nil
+}
@@ -17,11 +17,16 @@
 package compose
 
 import (
+	"context"
 	"slices"
 	"testing"
 
 	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/docker/api/types/network"
+	"go.uber.org/mock/gomock"
 	"gotest.tools/v3/assert"
+
+	compose "github.com/docker/compose/v2/pkg/api"
 )
 
 func Test_addBuildDependencies(t *testing.T) {
@@ -55,3 +60,38 @@ func Test_addBuildDependencies(t *testing.T) {
 	slices.Sort(expected)
 	assert.DeepEqual(t, services, expected)
 }
+
+func TestEnsureBuildNetworks(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	api, cli := prepareMocks(mockCtrl)
+	tested := composeService{
+		dockerCli: cli,
+	}
+
+	project := &types.Project{
+		Name: "test",
+		Networks: types.Networks{
+			"registry": {Name: "test_registry"},
+		},
+	}
+	services := types.Services{
+		"app":     {Name: "app", Build: &types.BuildConfig{Network: "registry"}},
+		"host":    {Name: "host", Build: &types.BuildConfig{Network: "host"}},
+		"outside": {Name: "outside", Build: &types.BuildConfig{Network: "shared"}},
+	}
+
+	api.EXPECT().NetworkInspect(gomock.Any(), "test_registry", gomock.Any()).Return(network.Inspect{
+		ID:   "abc123",
+		Name: "test_registry",
+		Labels: map[string]string{
+			compose.ProjectLabel: "test",
+			compose.NetworkLabel: "registry",
+		},
+	}, nil)
+
+	err := tested.ensureBuildNetworks(context.TODO(), project, services)
+	assert.NilError(t, err)
+	assert.Equal(t, services["app"].Build.Network, "test_registry")
+	assert.Equal(t, services["host"].Build.Network, "host")
+	assert.Equal(t, services["outside"].Build.Network, "shared")
+}
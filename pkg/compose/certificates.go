@@ -0,0 +1,210 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/distribution/reference"
+	"github.com/docker/buildx/util/imagetools"
+	"github.com/docker/docker/api/types/container"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+
+	"github.com/docker/compose/v2/pkg/remote"
+)
+
+const (
+	// caCertificatesExtension lists additional CA certificates, as files or OCI artifacts, to be trusted by containers.
+	// When set at project level, it applies to all services
+	caCertificatesExtension = "x-ca-certificates"
+	// caCertificatesBundle is the full CA bundle, including additional certificates, written in containers
+	caCertificatesBundle = "/etc/ssl/certs/compose-ca-certificates.crt"
+)
+
+// systemCABundles are the locations of the CA bundle used by common Linux distributions
+var systemCABundles = []string{
+	"/etc/ssl/certs/ca-certificates.crt", // Debian, Ubuntu, Alpine, Arch
+	"/etc/pki/tls/certs/ca-bundle.crt",   // Fedora, RHEL, CentOS
+	"/etc/ssl/ca-bundle.pem",             // OpenSUSE
+}
+
+// caBundleVariables are the environment variables common runtimes and tools rely on to locate the CA bundle
+var caBundleVariables = []string{
+	"SSL_CERT_FILE",
+	"CURL_CA_BUNDLE",
+	"REQUESTS_CA_BUNDLE",
+	"NODE_EXTRA_CA_CERTS",
+}
+
+// caCertificates returns the additional CA certificates declared for service, at project and service level
+func caCertificates(project *types.Project, service types.ServiceConfig) []string {
+	var sources []string
+	for _, extensions := range []types.Extensions{project.Extensions, service.Extensions} {
+		values, _ := extensions[caCertificatesExtension].([]any)
+		for _, value := range values {
+			source, ok := value.(string)
+			if !ok {
+				continue
+			}
+			if !strings.HasPrefix(source, remote.OciPrefix) && !filepath.IsAbs(source) {
+				source = filepath.Join(project.WorkingDir, source)
+			}
+			sources = append(sources, source)
+		}
+	}
+	return sources
+}
+
+// caCertificatesEnv sets variables for runtimes to use the CA bundle including additional certificates
+func caCertificatesEnv(project *types.Project, service types.ServiceConfig) types.MappingWithEquals {
+	env := types.MappingWithEquals{}
+	if len(caCertificates(project, service)) == 0 {
+		return env
+	}
+	for _, name := range caBundleVariables {
+		bundle := caCertificatesBundle
+		env[name] = &bundle
+	}
+	return env
+}
+
+// injectCACertificates appends additional CA certificates to the container's system CA bundle
+func (s *composeService) injectCACertificates(ctx context.Context, project *types.Project, service types.ServiceConfig, id string) error {
+	sources := caCertificates(project, service)
+	if len(sources) == 0 {
+		return nil
+	}
+	if service.ReadOnly {
+		return fmt.Errorf("cannot install CA certificates in read-only service %s", service.Name)
+	}
+
+	var certificates bytes.Buffer
+	for _, source := range sources {
+		content, err := s.loadCACertificate(ctx, source)
+		if err != nil {
+			return fmt.Errorf("loading CA certificate %s: %w", source, err)
+		}
+		certificates.Write(content)
+		if !bytes.HasSuffix(content, []byte("\n")) {
+			certificates.WriteString("\n")
+		}
+	}
+	if s.dryRun {
+		return nil
+	}
+
+	targets := []string{caCertificatesBundle}
+	var bundle []byte
+	for _, path := range systemCABundles {
+		content, err := s.readContainerFile(ctx, id, path)
+		if err != nil {
+			continue
+		}
+		bundle = content
+		targets = append(targets, path)
+		break
+	}
+	if bundle == nil {
+		logrus.Warnf("service %q: no system CA bundle found, only %s will include additional CA certificates", service.Name, caCertificatesBundle)
+	} else if !bytes.HasSuffix(bundle, []byte("\n")) {
+		bundle = append(bundle, '\n')
+	}
+	bundle = append(bundle, certificates.Bytes()...)
+
+	mode := types.FileMode(0o644)
+	for _, target := range targets {
+		b, err := createTar(string(bundle), types.FileReferenceConfig{
+			Target: target,
+			Mode:   &mode,
+		})
+		if err != nil {
+			return err
+		}
+		err = s.apiClient().CopyToContainer(ctx, id, "/", &b, container.CopyToContainerOptions{})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadCACertificate reads certificate from a local file or from layers of an OCI artifact
+func (s *composeService) loadCACertificate(ctx context.Context, source string) ([]byte, error) {
+	if !strings.HasPrefix(source, remote.OciPrefix) {
+		return os.ReadFile(source)
+	}
+
+	ref, err := reference.ParseDockerRef(strings.TrimPrefix(source, remote.OciPrefix))
+	if err != nil {
+		return nil, err
+	}
+	resolver := imagetools.New(imagetools.Opt{
+		Auth: s.configFile(),
+	})
+	content, _, err := resolver.Get(ctx, ref.String())
+	if err != nil {
+		return nil, err
+	}
+	var manifest v1.Manifest
+	err = json.Unmarshal(content, &manifest)
+	if err != nil {
+		return nil, err
+	}
+	var certificates []byte
+	for _, layer := range manifest.Layers {
+		digested, err := reference.WithDigest(reference.TrimNamed(ref), layer.Digest)
+		if err != nil {
+			return nil, err
+		}
+		blob, _, err := resolver.Get(ctx, digested.String())
+		if err != nil {
+			return nil, err
+		}
+		certificates = append(certificates, blob...)
+	}
+	return certificates, nil
+}
+
+// readContainerFile returns content of a regular file in container
+func (s *composeService) readContainerFile(ctx context.Context, id string, path string) ([]byte, error) {
+	content, _, err := s.apiClient().CopyFromContainer(ctx, id, path)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close() //nolint:errcheck
+
+	reader := tar.NewReader(content)
+	header, err := reader.Next()
+	if err != nil {
+		return nil, err
+	}
+	if header.Typeflag != tar.TypeReg {
+		return nil, errors.New("not a regular file")
+	}
+	return io.ReadAll(reader)
+}
@@ -0,0 +1,50 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+)
+
+func TestCACertificates(t *testing.T) {
+	project := &types.Project{
+		WorkingDir: "/project",
+		Extensions: types.Extensions{
+			caCertificatesExtension: []any{"certs/proxy.pem", "oci://registry.example.com/certs:latest"},
+		},
+	}
+	service := types.ServiceConfig{
+		Name: "web",
+		Extensions: types.Extensions{
+			caCertificatesExtension: []any{"/etc/corp/root.pem"},
+		},
+	}
+	assert.DeepEqual(t, caCertificates(project, service), []string{
+		"/project/certs/proxy.pem",
+		"oci://registry.example.com/certs:latest",
+		"/etc/corp/root.pem",
+	})
+
+	env := caCertificatesEnv(project, service)
+	assert.Equal(t, *env["SSL_CERT_FILE"], caCertificatesBundle)
+	assert.Equal(t, *env["NODE_EXTRA_CA_CERTS"], caCertificatesBundle)
+
+	assert.Equal(t, len(caCertificatesEnv(&types.Project{}, types.ServiceConfig{})), 0)
+}
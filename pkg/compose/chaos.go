@@ -0,0 +1,254 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/docker/api/types/container"
+
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/progress"
+)
+
+func (s *composeService) Chaos(ctx context.Context, project *types.Project, options api.ChaosOptions) error {
+	if err := checkChaosSteps(project, options.Steps); err != nil {
+		return err
+	}
+	return progress.RunWithTitle(ctx, func(ctx context.Context) error {
+		for _, step := range options.Steps {
+			if err := s.chaosStep(ctx, project, step, options.Image); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, s.stdinfo(), "Injecting faults")
+}
+
+// checkChaosSteps validates all steps before any fault is injected
+func checkChaosSteps(project *types.Project, steps []api.ChaosStep) error {
+	for i, step := range steps {
+		var want int
+		switch step.Action {
+		case api.ChaosKill:
+			want = 1
+		case api.ChaosPause:
+			want = 1
+		case api.ChaosPartition:
+			want = 2
+		case api.ChaosWait:
+			want = 0
+		default:
+			return fmt.Errorf("step %d: unsupported chaos action %q", i+1, step.Action)
+		}
+		if len(step.Services) != want {
+			return fmt.Errorf("step %d: %s requires %d service(s), got %d", i+1, step.Action, want, len(step.Services))
+		}
+		if step.Action == api.ChaosPartition && step.Services[0] == step.Services[1] {
+			return fmt.Errorf("step %d: can't partition service %s from itself", i+1, step.Services[0])
+		}
+		if step.Action != api.ChaosKill && step.Duration <= 0 {
+			return fmt.Errorf("step %d: %s requires a duration", i+1, step.Action)
+		}
+		for _, service := range step.Services {
+			if _, err := project.GetService(service); err != nil {
+				return fmt.Errorf("step %d: %w", i+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *composeService) chaosStep(ctx context.Context, project *types.Project, step api.ChaosStep, image string) error {
+	switch step.Action {
+	case api.ChaosKill:
+		return s.chaosKill(ctx, project, step)
+	case api.ChaosPause:
+		return s.chaosPause(ctx, project, step)
+	case api.ChaosPartition:
+		return s.chaosPartition(ctx, project, step, image)
+	default:
+		w := progress.ContextWriter(ctx)
+		eventName := "Waiting " + step.Duration.String()
+		w.Event(progress.Event{ID: eventName, Status: progress.Working})
+		if err := sleepContext(ctx, step.Duration); err != nil {
+			return err
+		}
+		w.Event(progress.Event{ID: eventName, Status: progress.Done})
+		return nil
+	}
+}
+
+// chaosContainers returns the running containers of service
+func (s *composeService) chaosContainers(ctx context.Context, project *types.Project, service string) (Containers, error) {
+	containers, err := s.getContainers(ctx, project.Name, oneOffExclude, false, service)
+	if err != nil {
+		return nil, err
+	}
+	containers = containers.filter(isRunning())
+	if len(containers) == 0 {
+		return nil, fmt.Errorf("service %q has no running container", service)
+	}
+	return containers, nil
+}
+
+func (s *composeService) chaosKill(ctx context.Context, project *types.Project, step api.ChaosStep) error {
+	containers, err := s.chaosContainers(ctx, project, step.Services[0])
+	if err != nil {
+		return err
+	}
+	ctr := containers[rand.IntN(len(containers))] //nolint:gosec
+	signal := step.Signal
+	if signal == "" {
+		signal = "SIGKILL"
+	}
+	w := progress.ContextWriter(ctx)
+	eventName := getContainerProgressName(ctr)
+	w.Event(progress.Event{ID: eventName, Text: "Killing", StatusText: signal, Status: progress.Working})
+	if err := s.apiClient().ContainerKill(ctx, ctr.ID, signal); err != nil {
+		w.Event(progress.ErrorEvent(eventName))
+		return err
+	}
+	w.Event(progress.Event{ID: eventName, Text: "Killed", StatusText: signal, Status: progress.Done})
+	return nil
+}
+
+func (s *composeService) chaosPause(ctx context.Context, project *types.Project, step api.ChaosStep) (err error) {
+	containers, err := s.chaosContainers(ctx, project, step.Services[0])
+	if err != nil {
+		return err
+	}
+	w := progress.ContextWriter(ctx)
+	var paused Containers
+	defer func() {
+		// restore even if the step was interrupted
+		restoreCtx := context.WithoutCancel(ctx)
+		for _, ctr := range paused {
+			eventName := getContainerProgressName(ctr)
+			if unpauseErr := s.apiClient().ContainerUnpause(restoreCtx, ctr.ID); unpauseErr != nil {
+				w.Event(progress.ErrorEvent(eventName))
+				err = errors.Join(err, unpauseErr)
+				continue
+			}
+			w.Event(progress.Event{ID: eventName, Text: "Unpaused", Status: progress.Done})
+		}
+	}()
+	for _, ctr := range containers {
+		eventName := getContainerProgressName(ctr)
+		if err := s.apiClient().ContainerPause(ctx, ctr.ID); err != nil {
+			w.Event(progress.ErrorEvent(eventName))
+			return err
+		}
+		paused = append(paused, ctr)
+		w.Event(progress.Event{ID: eventName, Text: "Paused", StatusText: "for " + step.Duration.String(), Status: progress.Working})
+	}
+	return sleepContext(ctx, step.Duration)
+}
+
+func (s *composeService) chaosPartition(ctx context.Context, project *types.Project, step api.ChaosStep, image string) (err error) {
+	source, err := s.chaosContainers(ctx, project, step.Services[0])
+	if err != nil {
+		return err
+	}
+	target, err := s.chaosContainers(ctx, project, step.Services[1])
+	if err != nil {
+		return err
+	}
+	var addresses []string
+	for _, ctr := range target {
+		addresses = append(addresses, containerAddresses(ctr)...)
+	}
+	if len(addresses) == 0 {
+		return fmt.Errorf("service %q containers have no IPv4 address", step.Services[1])
+	}
+
+	w := progress.ContextWriter(ctx)
+	eventName := fmt.Sprintf("Partition %s/%s", step.Services[0], step.Services[1])
+	w.Event(progress.Event{ID: eventName, Text: "Partitioning", Status: progress.Working})
+	var partitioned Containers
+	defer func() {
+		// restore even if the step was interrupted
+		restoreCtx := context.WithoutCancel(ctx)
+		for _, ctr := range partitioned {
+			restoreErr := s.runNetworkHelper(restoreCtx, project, step.Services[0], ctr.ID, image, partitionCommand("-D", addresses))
+			err = errors.Join(err, restoreErr)
+		}
+		if err != nil {
+			w.Event(progress.ErrorEvent(eventName))
+			return
+		}
+		w.Event(progress.Event{ID: eventName, Text: "Restored", Status: progress.Done})
+	}()
+	for _, ctr := range source {
+		// rules inserted before a failure get deleted too
+		partitioned = append(partitioned, ctr)
+		if err := s.runNetworkHelper(ctx, project, step.Services[0], ctr.ID, image, partitionCommand("-I", addresses)); err != nil {
+			return err
+		}
+	}
+	w.Event(progress.Event{ID: eventName, Text: "Partitioned", StatusText: "for " + step.Duration.String(), Status: progress.Working})
+	return sleepContext(ctx, step.Duration)
+}
+
+// containerAddresses lists the IPv4 addresses of ctr on all networks it is attached to
+func containerAddresses(ctr container.Summary) []string {
+	var addresses []string
+	if ctr.NetworkSettings == nil {
+		return nil
+	}
+	for _, settings := range ctr.NetworkSettings.Networks {
+		if settings != nil && settings.IPAddress != "" {
+			addresses = append(addresses, settings.IPAddress)
+		}
+	}
+	slices.Sort(addresses)
+	return addresses
+}
+
+// partitionCommand returns the command inserting (-I) or deleting (-D) iptables rules dropping traffic exchanged
+// with addresses. Deletion ignores rules which don't exist, as an insertion may have failed partway
+func partitionCommand(op string, addresses []string) []string {
+	var rules []string
+	for _, address := range addresses {
+		rules = append(rules,
+			fmt.Sprintf("iptables %s INPUT -s %s -j DROP", op, address),
+			fmt.Sprintf("iptables %s OUTPUT -d %s -j DROP", op, address))
+	}
+	if op == "-D" {
+		for i, rule := range rules {
+			rules[i] = "(" + rule + " || true)"
+		}
+	}
+	return []string{"sh", "-c", strings.Join(rules, " && ")}
+}
+
+// sleepContext waits for d, or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
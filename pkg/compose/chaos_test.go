@@ -0,0 +1,174 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+
+	compose "github.com/docker/compose/v2/pkg/api"
+)
+
+func TestCheckChaosSteps(t *testing.T) {
+	project := &types.Project{Services: types.Services{
+		"web": {Name: "web"},
+		"db":  {Name: "db"},
+	}}
+	tests := []struct {
+		name string
+		step compose.ChaosStep
+		err  string
+	}{
+		{name: "kill", step: compose.ChaosStep{Action: compose.ChaosKill, Services: []string{"web"}}},
+		{name: "partition", step: compose.ChaosStep{Action: compose.ChaosPartition, Services: []string{"web", "db"}, Duration: time.Second}},
+		{name: "wait", step: compose.ChaosStep{Action: compose.ChaosWait, Duration: time.Second}},
+		{
+			name: "unknown action",
+			step: compose.ChaosStep{Action: "reboot", Services: []string{"web"}},
+			err:  `step 1: unsupported chaos action "reboot"`,
+		},
+		{
+			name: "pause without duration",
+			step: compose.ChaosStep{Action: compose.ChaosPause, Services: []string{"web"}},
+			err:  "step 1: pause requires a duration",
+		},
+		{
+			name: "partition from itself",
+			step: compose.ChaosStep{Action: compose.ChaosPartition, Services: []string{"web", "web"}, Duration: time.Second},
+			err:  "step 1: can't partition service web from itself",
+		},
+		{
+			name: "unknown service",
+			step: compose.ChaosStep{Action: compose.ChaosKill, Services: []string{"cache"}},
+			err:  `step 1: no such service: cache`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkChaosSteps(project, []compose.ChaosStep{tt.step})
+			if tt.err == "" {
+				assert.NilError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestPartitionCommand(t *testing.T) {
+	ctr := container.Summary{NetworkSettings: &container.NetworkSettingsSummary{Networks: map[string]*network.EndpointSettings{
+		"front": {IPAddress: "172.18.0.3"},
+		"back":  {IPAddress: "172.19.0.2"},
+	}}}
+	addresses := containerAddresses(ctr)
+	assert.DeepEqual(t, addresses, []string{"172.18.0.3", "172.19.0.2"})
+	assert.DeepEqual(t, partitionCommand("-I", addresses), []string{"sh", "-c",
+		"iptables -I INPUT -s 172.18.0.3 -j DROP && iptables -I OUTPUT -d 172.18.0.3 -j DROP && " +
+			"iptables -I INPUT -s 172.19.0.2 -j DROP && iptables -I OUTPUT -d 172.19.0.2 -j DROP"})
+	assert.DeepEqual(t, partitionCommand("-D", addresses[:1]), []string{"sh", "-c",
+		"(iptables -D INPUT -s 172.18.0.3 -j DROP || true) && (iptables -D OUTPUT -d 172.18.0.3 -j DROP || true)"})
+}
+
+func TestChaosPauseRestoresOnCancel(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	api, cli := prepareMocks(mockCtrl)
+	tested := composeService{
+		dockerCli: cli,
+	}
+
+	running := testContainer("web", "123", false)
+	running.State = ContainerRunning
+	stopped := testContainer("web", "456", false)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	api.EXPECT().ContainerList(gomock.Any(), gomock.Any()).Return([]container.Summary{running, stopped}, nil)
+	api.EXPECT().ContainerPause(gomock.Any(), "123").DoAndReturn(func(context.Context, string) error {
+		cancel()
+		return nil
+	})
+	api.EXPECT().ContainerUnpause(gomock.Any(), "123").Return(nil)
+
+	err := tested.chaosPause(ctx, &types.Project{Name: testProject}, compose.ChaosStep{
+		Action:   compose.ChaosPause,
+		Services: []string{"web"},
+		Duration: time.Hour,
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestChaosPartitionRestoresOnFailure(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	api, cli := prepareMocks(mockCtrl)
+	tested := composeService{
+		dockerCli: cli,
+	}
+
+	web := testContainer("web", "123", false)
+	web.State = ContainerRunning
+	db := testContainer("db", "456", false)
+	db.State = ContainerRunning
+	db.NetworkSettings = &container.NetworkSettingsSummary{Networks: map[string]*network.EndpointSettings{
+		"default": {IPAddress: "172.18.0.3"},
+	}}
+	api.EXPECT().ContainerList(gomock.Any(), gomock.Any()).Return([]container.Summary{web}, nil)
+	api.EXPECT().ContainerList(gomock.Any(), gomock.Any()).Return([]container.Summary{db}, nil)
+	api.EXPECT().ImageInspect(gomock.Any(), defaultNetworkHelperImage).Return(image.InspectResponse{}, nil).AnyTimes()
+
+	var commands []string
+	api.EXPECT().ContainerCreate(gomock.Any(), gomock.Any(), gomock.Any(), nil, nil, "").DoAndReturn(
+		func(_ context.Context, config *container.Config, _ *container.HostConfig, _ *network.NetworkingConfig, _ any, _ string) (container.CreateResponse, error) {
+			commands = append(commands, config.Cmd[2])
+			return container.CreateResponse{ID: "helper"}, nil
+		}).Times(2)
+	api.EXPECT().ContainerStart(gomock.Any(), "helper", gomock.Any()).Return(nil).Times(2)
+	api.EXPECT().ContainerWait(gomock.Any(), "helper", gomock.Any()).DoAndReturn(
+		func(context.Context, string, container.WaitCondition) (<-chan container.WaitResponse, <-chan error) {
+			// inserting rules fails partway, deleting them succeeds
+			result := make(chan container.WaitResponse, 1)
+			if len(commands) == 1 {
+				result <- container.WaitResponse{StatusCode: 1}
+			} else {
+				result <- container.WaitResponse{}
+			}
+			return result, nil
+		}).Times(2)
+	api.EXPECT().ContainerLogs(gomock.Any(), "helper", gomock.Any()).Return(io.NopCloser(strings.NewReader("")), nil)
+	api.EXPECT().ContainerRemove(gomock.Any(), "helper", gomock.Any()).Return(nil).Times(2)
+
+	err := tested.chaosPartition(context.Background(), &types.Project{Name: testProject}, compose.ChaosStep{
+		Action:   compose.ChaosPartition,
+		Services: []string{"web", "db"},
+		Duration: time.Hour,
+	}, "")
+	assert.ErrorContains(t, err, "sh exited with code 1")
+	assert.Equal(t, len(commands), 2)
+	assert.Assert(t, strings.HasPrefix(commands[1], "(iptables -D INPUT -s 172.18.0.3 -j DROP || true)"))
+}
@@ -37,6 +37,7 @@ import (
 	"github.com/docker/docker/api/types/volume"
 	"github.com/docker/docker/client"
 	"github.com/jonboulle/clockwork"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
 
 	"github.com/docker/compose/v2/internal/desktop"
 	"github.com/docker/compose/v2/internal/experimental"
@@ -59,6 +60,7 @@ func NewComposeService(dockerCli command.Cli) api.Service {
 		clock:          clockwork.NewRealClock(),
 		maxConcurrency: -1,
 		dryRun:         false,
+		engineInfo:     &engineInfoCache{},
 	}
 }
 
@@ -70,6 +72,7 @@ type composeService struct {
 	clock          clockwork.Clock
 	maxConcurrency int
 	dryRun         bool
+	engineInfo     *engineInfoCache
 }
 
 // Close releases any connections/resources held by the underlying clients.
@@ -306,22 +309,45 @@ func (s *composeService) isSWarmEnabled(ctx context.Context) (bool, error) {
 type runtimeVersionCache struct {
 	once sync.Once
 	val  string
+	os   string
+	arch string
 	err  error
 }
 
 var runtimeVersion runtimeVersionCache
 
-func (s *composeService) RuntimeVersion(ctx context.Context) (string, error) {
+func (s *composeService) loadRuntimeVersion(ctx context.Context) {
 	runtimeVersion.once.Do(func() {
 		version, err := s.dockerCli.Client().ServerVersion(ctx)
 		if err != nil {
 			runtimeVersion.err = err
 		}
 		runtimeVersion.val = version.APIVersion
+		runtimeVersion.os = version.Os
+		runtimeVersion.arch = version.Arch
 	})
+}
+
+func (s *composeService) RuntimeVersion(ctx context.Context) (string, error) {
+	s.loadRuntimeVersion(ctx)
 	return runtimeVersion.val, runtimeVersion.err
 }
 
+// RuntimeOS returns the operating system of the containers run by the engine, "linux" or "windows"
+func (s *composeService) RuntimeOS(ctx context.Context) (string, error) {
+	s.loadRuntimeVersion(ctx)
+	return runtimeVersion.os, runtimeVersion.err
+}
+
+// RuntimePlatform returns the native platform of the containers run by the engine
+func (s *composeService) RuntimePlatform(ctx context.Context) (specs.Platform, error) {
+	s.loadRuntimeVersion(ctx)
+	return specs.Platform{
+		OS:           runtimeVersion.os,
+		Architecture: runtimeVersion.arch,
+	}, runtimeVersion.err
+}
+
 func (s *composeService) isDesktopIntegrationActive() bool {
 	return s.desktopCli != nil
 }
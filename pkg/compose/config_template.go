@@ -0,0 +1,112 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+// configTemplateExtension marks a config `content` as a Go template, rendered for each service using it
+const configTemplateExtension = "x-template"
+
+// configTemplateData is the data configs templates are rendered with
+type configTemplateData struct {
+	// Project is the project name
+	Project string
+	// Service is the name of the service the config is rendered for
+	Service string
+	// Services describes all project services by name
+	Services map[string]configTemplateService
+	// Env is the project environment
+	Env map[string]string
+}
+
+type configTemplateService struct {
+	Name     string
+	Image    string
+	Scale    int
+	Ports    []types.ServicePortConfig
+	Networks []string
+	Labels   map[string]string
+}
+
+var configTemplateFuncs = template.FuncMap{
+	"join": strings.Join,
+	"seq": func(n int) []int {
+		s := make([]int, n)
+		for i := range s {
+			s[i] = i + 1
+		}
+		return s
+	},
+}
+
+func isTemplateConfig(config types.ConfigObjConfig) bool {
+	enabled, ok := config.Extensions[configTemplateExtension].(bool)
+	return ok && enabled && config.Content != ""
+}
+
+// renderConfigTemplate renders a config content for service
+func renderConfigTemplate(project *types.Project, service string, name string, content string) (string, error) {
+	tmpl, err := template.New(name).Funcs(configTemplateFuncs).Option("missingkey=error").Parse(content)
+	if err != nil {
+		return "", fmt.Errorf("config %q: %w", name, err)
+	}
+	data := configTemplateData{
+		Project:  project.Name,
+		Service:  service,
+		Services: map[string]configTemplateService{},
+		Env:      project.Environment,
+	}
+	for _, s := range project.Services {
+		data.Services[s.Name] = configTemplateService{
+			Name:     s.Name,
+			Image:    api.GetImageNameOrDefault(s, project.Name),
+			Scale:    s.GetScale(),
+			Ports:    s.Ports,
+			Networks: s.NetworksByPriority(),
+			Labels:   s.Labels,
+		}
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("config %q: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// checkConfigTemplates renders configs templates before any container is created, so errors are reported early
+func checkConfigTemplates(project *types.Project) error {
+	for _, service := range project.Services {
+		for _, ref := range service.Configs {
+			config := project.Configs[ref.Source]
+			if !isTemplateConfig(config) {
+				continue
+			}
+			if _, err := renderConfigTemplate(project, service.Name, config.Name, config.Content); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
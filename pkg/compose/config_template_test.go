@@ -0,0 +1,64 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+)
+
+func TestRenderConfigTemplate(t *testing.T) {
+	project := &types.Project{
+		Name: "myproject",
+		Services: types.Services{
+			"proxy": {Name: "proxy", Image: "nginx"},
+			"api": {
+				Name:  "api",
+				Build: &types.BuildConfig{Context: "."},
+				Scale: intPtr(2),
+				Ports: []types.ServicePortConfig{{Target: 8080, Protocol: "tcp"}},
+			},
+		},
+		Environment: types.Mapping{"DOMAIN": "example.com"},
+	}
+	content := `# {{ .Service }} in {{ .Project }} for {{ .Env.DOMAIN }}
+{{- $project := .Project }}
+{{- with .Services.api }}
+{{- $api := . }}
+upstream {{ .Name }} {
+{{- range $i := seq .Scale }}
+  server {{ $project }}-{{ $api.Name }}-{{ $i }}:{{ (index $api.Ports 0).Target }};
+{{- end }}
+}
+# {{ .Image }}
+{{- end }}
+`
+	rendered, err := renderConfigTemplate(project, "proxy", "upstreams", content)
+	assert.NilError(t, err)
+	assert.Equal(t, rendered, `# proxy in myproject for example.com
+upstream api {
+  server myproject-api-1:8080;
+  server myproject-api-2:8080;
+}
+# myproject-api
+`)
+
+	_, err = renderConfigTemplate(project, "proxy", "broken", "{{ .Env.MISSING }}")
+	assert.ErrorContains(t, err, `config "broken"`)
+}
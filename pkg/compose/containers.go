@@ -134,10 +134,19 @@ func isRunning() containerPredicate {
 	}
 }
 
-// isOrphaned is a predicate to select containers without a matching service definition in compose project
+// isStopped tells container has no process running
+func isStopped(c container.Summary) bool {
+	return c.State == ContainerCreated || c.State == ContainerExited || c.State == ContainerDead
+}
+
+// isOrphaned is a predicate to select containers without a matching service definition in compose project.
+// Containers labeled with api.ProtectLabel are never considered orphaned.
 func isOrphaned(project *types.Project) containerPredicate {
 	services := append(project.ServiceNames(), project.DisabledServiceNames()...)
 	return func(c container.Summary) bool {
+		if isProtected(c) {
+			return false
+		}
 		// One-off container
 		v, ok := c.Labels[api.OneoffLabel]
 		if ok && v == "True" {
@@ -149,6 +158,12 @@ func isOrphaned(project *types.Project) containerPredicate {
 	}
 }
 
+// isProtected tells container has been exempted from orphan cleanup
+func isProtected(c container.Summary) bool {
+	protected, _ := strconv.ParseBool(c.Labels[api.ProtectLabel])
+	return protected
+}
+
 func isNotOneOff(c container.Summary) bool {
 	v, ok := c.Labels[api.OneoffLabel]
 	return !ok || v == "False"
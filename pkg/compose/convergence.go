@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"slices"
 	"sort"
 	"strconv"
@@ -61,6 +62,8 @@ type convergence struct {
 	networks   map[string]string
 	volumes    map[string]string
 	stateMutex sync.Mutex
+	explain    bool
+	pulls      *pullStream
 }
 
 func (c *convergence) getObservedState(serviceName string) Containers {
@@ -93,6 +96,7 @@ func newConvergence(services []string, state Containers, networks map[string]str
 }
 
 func (c *convergence) apply(ctx context.Context, project *types.Project, options api.CreateOptions) error {
+	c.explain = options.ExplainRecreate
 	return InDependencyOrder(ctx, project, func(ctx context.Context, name string) error {
 		service, err := project.GetService(name)
 		if err != nil {
@@ -113,6 +117,9 @@ func (c *convergence) ensureService(ctx context.Context, project *types.Project,
 	if service.Provider != nil {
 		return c.service.runPlugin(ctx, project, service, "up")
 	}
+	if err := c.pulls.withPulledImage(ctx, &service); err != nil {
+		return err
+	}
 	expected, err := getScale(service)
 	if err != nil {
 		return err
@@ -120,6 +127,7 @@ func (c *convergence) ensureService(ctx context.Context, project *types.Project,
 	containers := c.getObservedState(service.Name)
 	actual := len(containers)
 	updated := make(Containers, expected)
+	recreated := false
 
 	eg, _ := errgroup.WithContext(ctx)
 
@@ -168,10 +176,15 @@ func (c *convergence) ensureService(ctx context.Context, project *types.Project,
 			return err
 		}
 		if mustRecreate {
+			if c.explain {
+				progress.ContextWriter(ctx).TailMsgf("%s recreated: %s", getContainerProgressName(container),
+					strings.Join(c.recreateReasons(service, container, recreate), "; "))
+			}
 			err := c.stopDependentContainers(ctx, project, service)
 			if err != nil {
 				return err
 			}
+			recreated = true
 
 			i, container := i, container
 			eg.Go(tracing.SpanWrapFuncForErrGroup(ctx, "container/recreate", tracing.ContainerOptions(container), func(ctx context.Context) error {
@@ -195,7 +208,10 @@ func (c *convergence) ensureService(ctx context.Context, project *types.Project,
 		default:
 			container := container
 			eg.Go(tracing.EventWrapFuncForErrGroup(ctx, "service/start", tracing.ContainerOptions(container), func(ctx context.Context) error {
-				return c.service.startContainer(ctx, container)
+				if err := c.service.startContainer(ctx, container); err != nil {
+					return err
+				}
+				return c.service.applyNetem(ctx, project, service, container)
 			}))
 		}
 		updated[i] = container
@@ -223,7 +239,73 @@ func (c *convergence) ensureService(ctx context.Context, project *types.Project,
 
 	err = eg.Wait()
 	c.setObservedState(service.Name, updated)
-	return err
+	if err != nil || !recreated {
+		return err
+	}
+	return c.recreateNamespaceJoiners(ctx, project, service.Name, inherit, timeout)
+}
+
+// recreateNamespaceJoiners recreates containers for services not selected by this run which join a namespace of
+// owner service, as those still reference the container owner service just replaced
+func (c *convergence) recreateNamespaceJoiners(ctx context.Context, project *types.Project, owner string, inherit bool, timeout *time.Duration) error {
+	for _, joiner := range namespaceJoiners(project, owner) {
+		containers := c.getObservedState(joiner.Name)
+		if len(containers) == 0 {
+			continue
+		}
+		err := c.resolveServiceReferences(&joiner)
+		if err != nil {
+			return err
+		}
+		updated := make(Containers, len(containers))
+		eg, egCtx := errgroup.WithContext(ctx)
+		for i, ctr := range containers {
+			service := joiner
+			if service.CustomLabels[api.ImageDigestLabel] == "" {
+				// image was not resolved by this run, keep the one container was created with
+				service.CustomLabels = maps.Clone(service.CustomLabels).Add(api.ImageDigestLabel, ctr.Labels[api.ImageDigestLabel])
+			}
+			eg.Go(tracing.SpanWrapFuncForErrGroup(egCtx, "container/recreate", tracing.ContainerOptions(ctr), func(ctx context.Context) error {
+				recreated, err := c.service.recreateContainer(ctx, project, service, ctr, inherit, timeout)
+				updated[i] = recreated
+				if err != nil || ctr.State != ContainerRunning {
+					return err
+				}
+				if err := c.service.startContainer(ctx, recreated); err != nil {
+					return err
+				}
+				return c.service.applyNetem(ctx, project, service, recreated)
+			}))
+		}
+		err = eg.Wait()
+		c.setObservedState(joiner.Name, updated)
+		if err != nil {
+			return err
+		}
+		if err := c.recreateNamespaceJoiners(ctx, project, joiner.Name, inherit, timeout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// namespaceJoiners lists disabled services sharing network, IPC or PID namespace of owner service. Only services
+// disabled by services selection are considered, as others miss the labels to create containers with
+func namespaceJoiners(project *types.Project, owner string) []types.ServiceConfig {
+	var joiners []types.ServiceConfig
+	for _, name := range slices.Sorted(maps.Keys(project.DisabledServices)) {
+		service := project.DisabledServices[name]
+		if service.CustomLabels[api.ServiceLabel] == "" {
+			continue
+		}
+		for _, mode := range []string{service.NetworkMode, service.Ipc, service.Pid} {
+			if getDependentServiceFromMode(mode) == owner {
+				joiners = append(joiners, service)
+				break
+			}
+		}
+	}
+	return joiners
 }
 
 func (c *convergence) stopDependentContainers(ctx context.Context, project *types.Project, service types.ServiceConfig) error {
@@ -675,10 +757,7 @@ func (s *composeService) createMobyContainer(ctx context.Context,
 	if err != nil {
 		return created, err
 	}
-	platform := service.Platform
-	if platform == "" {
-		platform = project.Environment["DOCKER_DEFAULT_PLATFORM"]
-	}
+	platform, _ := api.ServicePlatform(project, service)
 	var plat *specs.Platform
 	if platform != "" {
 		var p specs.Platform
@@ -743,6 +822,11 @@ func (s *composeService) createMobyContainer(ctx context.Context,
 	}
 
 	err = s.injectConfigs(ctx, project, service, created.ID)
+	if err != nil {
+		return created, err
+	}
+
+	err = s.injectCACertificates(ctx, project, service, created.ID)
 	return created, err
 }
 
@@ -879,11 +963,18 @@ func (s *composeService) startService(ctx context.Context,
 		}
 		eventName := getContainerProgressName(ctr)
 		w.Event(progress.StartingEvent(eventName))
+		if err = s.restoreRestartPolicy(ctx, service, ctr); err != nil {
+			return err
+		}
 		err = s.apiClient().ContainerStart(ctx, ctr.ID, containerType.StartOptions{})
 		if err != nil {
 			return err
 		}
 
+		if err = s.applyNetem(ctx, project, service, ctr); err != nil {
+			return err
+		}
+
 		for _, hook := range service.PostStart {
 			err = s.runHook(ctx, ctr, service, hook, listener)
 			if err != nil {
@@ -63,6 +63,23 @@ func intPtr(i int) *int {
 	return &i
 }
 
+func TestNamespaceJoiners(t *testing.T) {
+	labels := types.Labels{api.ServiceLabel: "x"}
+	project := &types.Project{
+		DisabledServices: types.Services{
+			"vpn-client": {Name: "vpn-client", NetworkMode: "service:vpn", CustomLabels: labels},
+			"shm-reader": {Name: "shm-reader", Ipc: "service:vpn", CustomLabels: labels},
+			"profiled":   {Name: "profiled", Pid: "service:vpn"},
+			"other":      {Name: "other", NetworkMode: "service:db", CustomLabels: labels},
+		},
+	}
+	var names []string
+	for _, joiner := range namespaceJoiners(project, "vpn") {
+		names = append(names, joiner.Name)
+	}
+	assert.DeepEqual(t, names, []string{"shm-reader", "vpn-client"})
+}
+
 func TestServiceLinks(t *testing.T) {
 	const dbContainerName = "/" + testProject + "-db-1"
 	const webContainerName = "/" + testProject + "-web-1"
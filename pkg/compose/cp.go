@@ -286,6 +286,11 @@ func (s *composeService) copyFromContainer(ctx context.Context, containerID, src
 		return err
 	}
 
+	// Don't write to the local filesystem if running in Dry Run mode
+	if s.dryRun {
+		return nil
+	}
+
 	srcInfo := archive.CopyInfo{
 		Path:       srcPath,
 		Exists:     true,
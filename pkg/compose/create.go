@@ -77,7 +77,54 @@ func (s *composeService) create(ctx context.Context, project *types.Project, opt
 		return err
 	}
 
-	err = s.ensureImagesExists(ctx, project, options.Build, options.QuietPull)
+	err = s.checkRootlessCompatibility(ctx, project)
+	if err != nil {
+		return err
+	}
+
+	err = s.checkKernelSettings(ctx, project)
+	if err != nil {
+		return err
+	}
+
+	err = checkConfigTemplates(project)
+	if err != nil {
+		return err
+	}
+
+	err = applyNetworkDNS(project)
+	if err != nil {
+		return err
+	}
+
+	err = checkDNSSettings(project)
+	if err != nil {
+		return err
+	}
+
+	for _, service := range project.Services {
+		if _, err := getServiceNetem(service); err != nil {
+			return err
+		}
+	}
+
+	err = s.checkSecretEnv(project)
+	if err != nil {
+		return err
+	}
+
+	s.checkDesktopResources(ctx, project)
+
+	var stream *pullStream
+	if options.PullStreaming {
+		stream = newPullStream()
+	}
+	err = s.ensureImagesExists(ctx, project, options.Build, options.QuietPull, stream)
+	if err != nil {
+		return err
+	}
+
+	err = s.checkImagesPlatform(ctx, project)
 	if err != nil {
 		return err
 	}
@@ -89,6 +136,11 @@ func (s *composeService) create(ctx context.Context, project *types.Project, opt
 		return err
 	}
 
+	err = s.startPackageCache(ctx, project, networks)
+	if err != nil {
+		return err
+	}
+
 	volumes, err := s.ensureProjectVolumes(ctx, project, options.AssumeYes)
 	if err != nil {
 		return err
@@ -101,19 +153,51 @@ func (s *composeService) create(ctx context.Context, project *types.Project, opt
 	}
 	orphans := observedState.filter(isOrphaned(project))
 	if len(orphans) > 0 && !options.IgnoreOrphans {
-		if options.RemoveOrphans {
+		remove := options.RemoveOrphans
+		if remove && options.ConfirmRemoveOrphans {
+			remove, err = s.confirmRemoveOrphans(orphans, options.AssumeYes)
+			if err != nil {
+				return err
+			}
+		}
+		if remove {
 			err := s.removeContainers(ctx, orphans, nil, nil, false)
 			if err != nil {
 				return err
 			}
-		} else {
+		} else if !options.RemoveOrphans {
 			logrus.Warnf("Found orphan containers (%s) for this project. If "+
 				"you removed or renamed this service in your compose "+
 				"file, you can run this command with the "+
 				"--remove-orphans flag to clean it up.", orphans.names())
 		}
 	}
-	return newConvergence(options.Services, observedState, networks, volumes, s).apply(ctx, project, options)
+	c := newConvergence(options.Services, observedState, networks, volumes, s)
+	c.pulls = stream
+	return c.apply(ctx, project, options)
+}
+
+// confirmRemoveOrphans lists orphan containers and asks the user to confirm their removal
+func (s *composeService) confirmRemoveOrphans(orphans Containers, assumeYes bool) (bool, error) {
+	var msg strings.Builder
+	msg.WriteString("The following orphan containers will be removed:\n")
+	for _, c := range orphans {
+		fmt.Fprintf(&msg, "  %s (service %q, %s)\n", getCanonicalContainerName(c), c.Labels[api.ServiceLabel], c.Status)
+	}
+	if assumeYes {
+		_, _ = fmt.Fprint(s.stdinfo(), msg.String())
+		return true, nil
+	}
+	msg.WriteString("Remove them?")
+	confirm, err := prompt.NewPrompt(s.stdin(), s.stdout()).Confirm(msg.String(), false)
+	if err != nil {
+		return false, err
+	}
+	if !confirm {
+		logrus.Warnf("Orphan containers (%s) were kept. Label them with %s=true to exempt them from orphan cleanup.",
+			strings.Join(orphans.names(), ", "), api.ProtectLabel)
+	}
+	return confirm, nil
 }
 
 func prepareNetworks(project *types.Project) {
@@ -184,8 +268,15 @@ func (s *composeService) getCreateConfigs(ctx context.Context,
 		stdinOpen = service.StdinOpen
 	)
 
-	proxyConfig := types.MappingWithEquals(s.configFile().ParseProxyConfig(s.apiClient().DaemonHost(), nil))
+	proxyConfig := types.MappingWithEquals(s.configFile().ParseProxyConfig(s.apiClient().DaemonHost(), nil)).
+		OverrideBy(projectProxy(p, service).ToMappingWithEquals())
 	env := proxyConfig.OverrideBy(service.Environment)
+	env = caCertificatesEnv(p, service).OverrideBy(env)
+	secretEnv, err := s.resolveSecretEnv(ctx, p, service)
+	if err != nil {
+		return createConfigs{}, err
+	}
+	env = env.OverrideBy(secretEnv)
 
 	var mainNwName string
 	var mainNw *types.ServiceNetworkConfig
@@ -199,7 +290,12 @@ func (s *composeService) getCreateConfigs(ctx context.Context,
 		return createConfigs{}, err
 	}
 
-	healthcheck, err := s.ToMobyHealthCheck(ctx, service.HealthCheck)
+	healthcheck, err := s.serviceHealthCheck(ctx, p, service)
+	if err != nil {
+		return createConfigs{}, err
+	}
+
+	extraHosts, err := s.withHostGateway(ctx, p, service)
 	if err != nil {
 		return createConfigs{}, err
 	}
@@ -234,6 +330,13 @@ func (s *composeService) getCreateConfigs(ctx context.Context,
 			tmpfs[arr[0]] = ""
 		}
 	}
+	osType, err := s.RuntimeOS(ctx)
+	if err != nil {
+		return createConfigs{}, err
+	}
+	if osType == osWindows {
+		service.Volumes = adaptVolumesForWindows(service)
+	}
 	binds, mounts, err := s.buildContainerVolumes(ctx, *p, service, inherit)
 	if err != nil {
 		return createConfigs{}, err
@@ -252,7 +355,13 @@ func (s *composeService) getCreateConfigs(ctx context.Context,
 	if err != nil {
 		return createConfigs{}, err
 	}
-	portBindings := buildContainerPortBindingOptions(service)
+	var balancerIP string
+	if len(balancedPorts(service)) > 0 {
+		if balancerIP, _, err = s.balancerBackend(); err != nil {
+			return createConfigs{}, err
+		}
+	}
+	portBindings := buildContainerPortBindingOptions(service, balancerIP)
 
 	// MISC
 	resources := getDeployResources(service)
@@ -290,7 +399,7 @@ func (s *composeService) getCreateConfigs(ctx context.Context,
 		DNS:            service.DNS,
 		DNSSearch:      service.DNSSearch,
 		DNSOptions:     service.DNSOpts,
-		ExtraHosts:     service.ExtraHosts.AsList(":"),
+		ExtraHosts:     extraHosts.AsList(":"),
 		SecurityOpt:    securityOpts,
 		StorageOpt:     service.StorageOpt,
 		UsernsMode:     container.UsernsMode(service.UserNSMode),
@@ -311,6 +420,16 @@ func (s *composeService) getCreateConfigs(ctx context.Context,
 		hostConfig.ReadonlyPaths = []string{}
 	}
 
+	if err := checkIsolation(service, osType); err != nil {
+		return createConfigs{}, err
+	}
+	if err := checkNamedPipes(service, osType); err != nil {
+		return createConfigs{}, err
+	}
+	if osType == osWindows {
+		adaptHostConfigForWindows(service, &hostConfig)
+	}
+
 	cfgs := createConfigs{
 		Container: &containerConfig,
 		Host:      &hostConfig,
@@ -473,6 +592,16 @@ func (s *composeService) prepareLabels(labels types.Labels, service types.Servic
 	}
 	labels[api.ConfigHashLabel] = hash
 
+	fields, err := ServiceFieldHashes(service)
+	if err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	labels[api.ConfigFieldsHashLabel] = string(b)
+
 	if number > 0 {
 		// One-off containers are not indexed
 		labels[api.ContainerNumberLabel] = strconv.Itoa(number)
@@ -769,7 +898,7 @@ func buildContainerPorts(s types.ServiceConfig) nat.PortSet {
 	return ports
 }
 
-func buildContainerPortBindingOptions(s types.ServiceConfig) nat.PortMap {
+func buildContainerPortBindingOptions(s types.ServiceConfig, balancerIP string) nat.PortMap {
 	bindings := nat.PortMap{}
 	for _, port := range s.Ports {
 		p := nat.Port(fmt.Sprintf("%d/%s", port.Target, port.Protocol))
@@ -777,6 +906,10 @@ func buildContainerPortBindingOptions(s types.ServiceConfig) nat.PortMap {
 			HostIP:   port.HostIP,
 			HostPort: port.Published,
 		}
+		if port.Mode == portModeBalanced {
+			// compose listens on published port, replicas get an ephemeral one
+			binding = nat.PortBinding{HostIP: balancerIP}
+		}
 		bindings[p] = append(bindings[p], binding)
 	}
 	return bindings
@@ -1085,7 +1218,7 @@ func buildContainerSecretMounts(p types.Project, s types.ServiceConfig) ([]mount
 			return nil, errors.New("Docker Compose does not support secrets.*.template_driver") //nolint:staticcheck
 		}
 
-		if definedSecret.Environment != "" {
+		if definedSecret.Environment != "" || isHotReloadSecret(definedSecret) {
 			continue
 		}
 
@@ -1249,7 +1382,13 @@ func buildImageOptions(image *types.ServiceVolumeImage) *mount.ImageOptions {
 
 func (s *composeService) ensureNetwork(ctx context.Context, project *types.Project, name string, n *types.NetworkConfig) (string, error) {
 	if n.External {
-		return s.resolveExternalNetwork(ctx, n)
+		id, err := s.resolveExternalNetwork(ctx, n)
+		if errdefs.IsConflict(err) {
+			// Maybe another project created same network with x-create-if-missing
+			// let's retry once
+			return s.resolveExternalNetwork(ctx, n)
+		}
+		return id, err
 	}
 
 	id, err := s.resolveOrCreateNetwork(ctx, project, name, n)
@@ -1454,6 +1593,13 @@ func (s *composeService) resolveExternalNetwork(ctx context.Context, n *types.Ne
 	case 1:
 		return networks[0].ID, nil
 	case 0:
+		options, err := createIfMissing(n)
+		if err != nil {
+			return "", err
+		}
+		if options != nil {
+			return s.createExternalNetwork(ctx, n, options)
+		}
 		enabled, err := s.isSWarmEnabled(ctx)
 		if err != nil {
 			return "", err
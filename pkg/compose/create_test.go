@@ -17,19 +17,25 @@
 package compose
 
 import (
+	"bytes"
 	"context"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"testing"
 
 	composeloader "github.com/compose-spec/compose-go/v2/loader"
+	"github.com/docker/cli/cli/streams"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/image"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
 	"go.uber.org/mock/gomock"
 	"gotest.tools/v3/assert/cmp"
 
 	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/mocks"
 	"github.com/docker/docker/api/types/network"
 
 	composetypes "github.com/compose-spec/compose-go/v2/types"
@@ -457,3 +463,170 @@ volumes:
 		})
 	}
 }
+
+func TestCheckIsolation(t *testing.T) {
+	service := composetypes.ServiceConfig{Name: "test", Isolation: "hyperv"}
+	assert.NilError(t, checkIsolation(service, "windows"))
+	assert.ErrorContains(t, checkIsolation(service, "linux"), "only supported by Windows containers")
+
+	service.Isolation = "default"
+	assert.NilError(t, checkIsolation(service, "linux"))
+
+	service.Isolation = "sandbox"
+	assert.ErrorContains(t, checkIsolation(service, "windows"), `invalid isolation "sandbox"`)
+}
+
+func TestCheckNamedPipes(t *testing.T) {
+	service := composetypes.ServiceConfig{
+		Name: "test",
+		Volumes: []composetypes.ServiceVolumeConfig{
+			{
+				Type:   composetypes.VolumeTypeNamedPipe,
+				Source: "\\\\.\\pipe\\docker_engine",
+				Target: "\\\\.\\pipe\\docker_engine",
+			},
+		},
+	}
+	assert.NilError(t, checkNamedPipes(service, "windows"))
+	assert.ErrorContains(t, checkNamedPipes(service, "linux"), "can only be mounted into Windows containers")
+}
+
+func TestAdaptHostConfigForWindows(t *testing.T) {
+	pidsLimit := int64(10)
+	hostConfig := container.HostConfig{
+		CapAdd:     []string{"NET_ADMIN"},
+		Privileged: true,
+		Sysctls:    map[string]string{"net.core.somaxconn": "1024"},
+		ShmSize:    64,
+		Tmpfs:      map[string]string{"/tmp": ""},
+		Isolation:  "process",
+		Mounts: []mountTypes.Mount{
+			{Type: mountTypes.TypeTmpfs, Target: "/run"},
+			{Type: mountTypes.TypeNamedPipe, Source: "\\\\.\\pipe\\docker_engine", Target: "\\\\.\\pipe\\docker_engine"},
+			{Type: mountTypes.TypeBind, Source: "C:\\secrets\\db", Target: "/run/secrets/db", ReadOnly: true},
+		},
+		Resources: container.Resources{
+			PidsLimit: &pidsLimit,
+			Memory:    1024,
+		},
+	}
+	adaptHostConfigForWindows(composetypes.ServiceConfig{Name: "test"}, &hostConfig)
+	assert.DeepEqual(t, hostConfig, container.HostConfig{
+		Isolation: "process",
+		Mounts: []mountTypes.Mount{
+			{Type: mountTypes.TypeNamedPipe, Source: "\\\\.\\pipe\\docker_engine", Target: "\\\\.\\pipe\\docker_engine"},
+			{Type: mountTypes.TypeBind, Source: "C:\\secrets\\db", Target: "C:\\run\\secrets\\db", ReadOnly: true},
+		},
+		Resources: container.Resources{
+			Memory: 1024,
+		},
+	})
+}
+
+func TestAdaptVolumesForWindows(t *testing.T) {
+	service := composetypes.ServiceConfig{
+		Name: "test",
+		Volumes: []composetypes.ServiceVolumeConfig{
+			{Type: composetypes.VolumeTypeBind, Source: "C:\\data", Target: "/data", Bind: &composetypes.ServiceVolumeBind{SELinux: "z", CreateHostPath: true}},
+			{Type: composetypes.VolumeTypeBind, Source: "D:\\logs", Target: "d:/logs"},
+			{Type: composetypes.VolumeTypeVolume, Source: "cache", Target: "C:\\cache"},
+			{Type: composetypes.VolumeTypeNamedPipe, Source: "//./pipe/docker_engine", Target: "//./pipe/docker_engine"},
+		},
+	}
+	assert.DeepEqual(t, adaptVolumesForWindows(service), []composetypes.ServiceVolumeConfig{
+		{Type: composetypes.VolumeTypeBind, Source: "C:\\data", Target: "C:\\data", Bind: &composetypes.ServiceVolumeBind{CreateHostPath: true}},
+		{Type: composetypes.VolumeTypeBind, Source: "D:\\logs", Target: "d:\\logs"},
+		{Type: composetypes.VolumeTypeVolume, Source: "cache", Target: "C:\\cache"},
+		{Type: composetypes.VolumeTypeNamedPipe, Source: "\\\\.\\pipe\\docker_engine", Target: "\\\\.\\pipe\\docker_engine"},
+	})
+	// the service definition is left untouched
+	assert.Equal(t, service.Volumes[0].Target, "/data")
+	assert.Equal(t, service.Volumes[0].Bind.SELinux, "z")
+}
+
+func TestCheckImagePlatform(t *testing.T) {
+	arm64 := specs.Platform{OS: "linux", Architecture: "arm64"}
+	amd64 := specs.Platform{OS: "linux", Architecture: "amd64"}
+	armv7 := specs.Platform{OS: "linux", Architecture: "arm", Variant: "v7"}
+
+	_, ok := checkImagePlatform(composetypes.ServiceConfig{Name: "web"}, "nginx", arm64, "", arm64)
+	assert.Check(t, ok)
+
+	_, ok = checkImagePlatform(composetypes.ServiceConfig{Name: "web"}, "nginx", armv7, "", arm64)
+	assert.Check(t, ok, "engine runs compatible platforms")
+
+	msg, ok := checkImagePlatform(composetypes.ServiceConfig{Name: "web"}, "nginx", amd64, "", arm64)
+	assert.Check(t, !ok)
+	assert.Equal(t, msg, ` - service "web": image nginx is for platform linux/amd64, not linux/arm64. Set `+"`platform: linux/amd64`"+` to run it with emulation, or use an image available for linux/arm64`)
+
+	_, ok = checkImagePlatform(composetypes.ServiceConfig{Name: "web", Platform: "linux/amd64"}, "nginx", amd64, "", arm64)
+	assert.Check(t, ok)
+
+	_, ok = checkImagePlatform(composetypes.ServiceConfig{Name: "web"}, "nginx", amd64, "linux/amd64", arm64)
+	assert.Check(t, ok, "DOCKER_DEFAULT_PLATFORM is honored")
+
+	msg, ok = checkImagePlatform(composetypes.ServiceConfig{Name: "web", Platform: "linux/arm64"}, "nginx", amd64, "", arm64)
+	assert.Check(t, !ok)
+	assert.Equal(t, msg, ` - service "web": image nginx is for platform linux/amd64, not linux/arm64. Use an image available for linux/arm64`)
+}
+
+func TestBuildContainerSecretMountsHotReload(t *testing.T) {
+	project := composetypes.Project{
+		Secrets: composetypes.Secrets{
+			"cert": {
+				File:       "/certs/cert.pem",
+				Extensions: map[string]any{secretHotReloadExtension: true},
+			},
+			"key": {File: "/certs/key.pem"},
+		},
+		Services: composetypes.Services{
+			"web": {
+				Name: "web",
+				Secrets: []composetypes.ServiceSecretConfig{
+					{Source: "cert"},
+					{Source: "key"},
+				},
+			},
+			"other": {Name: "other"},
+		},
+	}
+	mounts, err := buildContainerSecretMounts(project, project.Services["web"])
+	assert.NilError(t, err)
+	assert.Equal(t, len(mounts), 1)
+	assert.Equal(t, mounts[0].Target, "/run/secrets/key")
+
+	assert.DeepEqual(t, hotReloadSecrets(&project), map[string][]string{"/certs/cert.pem": {"cert"}})
+}
+
+func TestIsOrphaned(t *testing.T) {
+	project := &composetypes.Project{
+		Services: composetypes.Services{"service1": {Name: "service1"}},
+	}
+	orphan := testContainer("removed", "123", false)
+	protected := testContainer("helper", "456", false)
+	protected.Labels[api.ProtectLabel] = "true"
+
+	containers := Containers{testContainer("service1", "789", false), orphan, protected}
+	assert.DeepEqual(t, containers.filter(isOrphaned(project)), Containers{orphan})
+}
+
+func TestConfirmRemoveOrphans(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	orphans := Containers{testContainer("removed", "123", false)}
+	orphans[0].Status = "Exited (0) 2 hours ago"
+
+	for _, answer := range []string{"y", "n"} {
+		t.Run(answer, func(t *testing.T) {
+			cli := mocks.NewMockCli(mockCtrl)
+			out := &bytes.Buffer{}
+			cli.EXPECT().In().Return(streams.NewIn(io.NopCloser(strings.NewReader(answer + "\n")))).AnyTimes()
+			cli.EXPECT().Out().Return(streams.NewOut(out)).AnyTimes()
+			tested := composeService{dockerCli: cli}
+
+			confirm, err := tested.confirmRemoveOrphans(orphans, false)
+			assert.NilError(t, err)
+			assert.Equal(t, confirm, answer == "y")
+			assert.Assert(t, cmp.Contains(out.String(), `123 (service "removed", Exited (0) 2 hours ago)`))
+		})
+	}
+}
@@ -0,0 +1,127 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/cli/cli"
+	cmd "github.com/docker/cli/cli/command/container"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/stringid"
+
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/progress"
+)
+
+// DefaultDebugImage is the toolbox image used by Debug when none is set
+const DefaultDebugImage = "busybox:stable"
+
+func (s *composeService) Debug(ctx context.Context, projectName string, options api.DebugOptions) (int, error) {
+	projectName = strings.ToLower(projectName)
+	if options.Image == "" {
+		options.Image = DefaultDebugImage
+	}
+	if err := s.stdin().CheckTty(options.Interactive, options.Tty); err != nil {
+		return 0, err
+	}
+	target, err := s.getSpecifiedContainer(ctx, projectName, oneOffExclude, false, options.Service, options.Index)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := s.apiClient().ImageInspect(ctx, options.Image); errdefs.IsNotFound(err) {
+		err = progress.RunWithTitle(ctx, func(ctx context.Context) error {
+			toolbox := types.ServiceConfig{Name: "debug", Image: options.Image}
+			_, err := s.pullServiceImage(ctx, toolbox, s.configFile(), progress.ContextWriter(ctx), false, "")
+			return err
+		}, s.stdinfo(), "Pulling")
+		if err != nil {
+			return 0, err
+		}
+	} else if err != nil {
+		return 0, err
+	}
+
+	slug := stringid.GenerateRandomID()
+	name := strings.Join([]string{projectName, options.Service, "debug", stringid.TruncateID(slug)}, api.Separator)
+	config, hostConfig := debugContainerConfig(projectName, target.ID, slug, options)
+	created, err := s.apiClient().ContainerCreate(ctx, config, hostConfig, nil, nil, name)
+	if err != nil {
+		return 0, err
+	}
+
+	// remove cancellable context signal handler so we can forward signals to container without compose to exit
+	signal.Reset()
+
+	sigc := make(chan os.Signal, 128)
+	signal.Notify(sigc)
+	go cmd.ForwardAllSignals(ctx, s.apiClient(), created.ID, sigc)
+	defer signal.Stop(sigc)
+
+	err = cmd.RunStart(ctx, s.dockerCli, &cmd.StartOptions{
+		OpenStdin:  options.Interactive,
+		Attach:     true,
+		Containers: []string{created.ID},
+	})
+	var stErr cli.StatusError
+	if errors.As(err, &stErr) {
+		return stErr.StatusCode, nil
+	}
+	return 0, err
+}
+
+// debugContainerConfig configures a toolbox container joining the network and PID namespaces of target, and
+// mounting its volumes. It is labelled as a one-off container of the project, so it gets removed by `down` if left
+// over, and is removed on exit
+func debugContainerConfig(projectName string, target string, slug string, options api.DebugOptions) (*container.Config, *container.HostConfig) {
+	config := &container.Config{
+		Image:        options.Image,
+		Cmd:          options.Command,
+		Tty:          options.Tty,
+		OpenStdin:    options.Interactive,
+		StdinOnce:    options.Interactive,
+		AttachStdin:  options.Interactive,
+		AttachStdout: true,
+		AttachStderr: true,
+		Labels: map[string]string{
+			api.ProjectLabel: projectName,
+			api.ServiceLabel: options.Service,
+			api.OneoffLabel:  "True",
+			api.SlugLabel:    slug,
+			api.VersionLabel: api.ComposeVersion,
+		},
+	}
+	shared := fmt.Sprintf("container:%s", target)
+	hostConfig := &container.HostConfig{
+		NetworkMode: container.NetworkMode(shared),
+		PidMode:     container.PidMode(shared),
+		VolumesFrom: []string{target},
+		// tracing processes of the service container is a common debugging need
+		CapAdd:     []string{"SYS_PTRACE"},
+		Privileged: options.Privileged,
+		AutoRemove: true,
+	}
+	return config, hostConfig
+}
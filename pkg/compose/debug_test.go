@@ -0,0 +1,48 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"gotest.tools/v3/assert"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+func TestDebugContainerConfig(t *testing.T) {
+	config, hostConfig := debugContainerConfig("myapp", "abc123", "slug", api.DebugOptions{
+		Service:     "web",
+		Image:       "nicolaka/netshoot",
+		Command:     []string{"tcpdump", "-i", "eth0"},
+		Tty:         true,
+		Interactive: true,
+	})
+	assert.Equal(t, config.Image, "nicolaka/netshoot")
+	assert.DeepEqual(t, []string(config.Cmd), []string{"tcpdump", "-i", "eth0"})
+	assert.Check(t, config.OpenStdin && config.AttachStdin && config.Tty)
+	assert.Equal(t, config.Labels[api.ProjectLabel], "myapp")
+	assert.Equal(t, config.Labels[api.ServiceLabel], "web")
+	assert.Equal(t, config.Labels[api.OneoffLabel], "True")
+
+	assert.Equal(t, hostConfig.NetworkMode, container.NetworkMode("container:abc123"))
+	assert.Equal(t, hostConfig.PidMode, container.PidMode("container:abc123"))
+	assert.DeepEqual(t, hostConfig.VolumesFrom, []string{"abc123"})
+	assert.Check(t, hostConfig.AutoRemove)
+	assert.Check(t, !hostConfig.Privileged)
+}
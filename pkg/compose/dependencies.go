@@ -19,11 +19,13 @@ package compose
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 
 	"github.com/compose-spec/compose-go/v2/types"
 	"github.com/docker/compose/v2/pkg/api"
+	"golang.org/x/exp/maps"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/docker/compose/v2/pkg/utils"
@@ -81,6 +83,10 @@ func InDependencyOrder(ctx context.Context, project *types.Project, fn func(cont
 	if err != nil {
 		return err
 	}
+	err = graph.applyStartPriority(project)
+	if err != nil {
+		return err
+	}
 	t := upDirectionTraversal(fn)
 	for _, option := range options {
 		option(t)
@@ -293,6 +299,121 @@ func NewGraph(project *types.Project, initialStatus ServiceStatus) (*Graph, erro
 	return graph, nil
 }
 
+// startPriorityExtension sets the priority of a service at startup, relative to services at the same dependency depth
+const startPriorityExtension = "x-start-priority"
+
+func startPriority(service types.ServiceConfig) (int, error) {
+	v, ok := service.Extensions[startPriorityExtension]
+	if !ok {
+		return 0, nil
+	}
+	switch p := v.(type) {
+	case int:
+		return p, nil
+	case float64:
+		if p == float64(int(p)) {
+			return int(p), nil
+		}
+	}
+	return 0, fmt.Errorf("service %q: %s must be an integer", service.Name, startPriorityExtension)
+}
+
+// depths computes for each vertex the length of the longest dependency chain it relies on
+func (g *Graph) depths() map[string]int {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	depths := map[string]int{}
+	var depth func(v *Vertex) int
+	depth = func(v *Vertex) int {
+		if d, ok := depths[v.Key]; ok {
+			return d
+		}
+		d := 0
+		for _, child := range v.Children {
+			d = max(d, depth(child)+1)
+		}
+		depths[v.Key] = d
+		return d
+	}
+	for _, v := range g.Vertices {
+		depth(v)
+	}
+	return depths
+}
+
+// applyStartPriority adds dependencies between services at the same depth, so that those with a higher
+// x-start-priority get started first. As such services never depend on each other, this can't introduce a cycle
+func (g *Graph) applyStartPriority(project *types.Project) error {
+	priorities := map[string]int{}
+	for _, service := range project.Services {
+		p, err := startPriority(service)
+		if err != nil {
+			return err
+		}
+		if p != 0 {
+			priorities[service.Name] = p
+		}
+	}
+	if len(priorities) == 0 {
+		return nil
+	}
+
+	// index vertices by depth, then by priority
+	tiers := map[int]map[int][]string{}
+	for key, depth := range g.depths() {
+		if tiers[depth] == nil {
+			tiers[depth] = map[int][]string{}
+		}
+		p := priorities[key]
+		tiers[depth][p] = append(tiers[depth][p], key)
+	}
+	for _, byPriority := range tiers {
+		levels := maps.Keys(byPriority)
+		sort.Sort(sort.Reverse(sort.IntSlice(levels)))
+		for i := 1; i < len(levels); i++ {
+			for _, source := range byPriority[levels[i]] {
+				for _, destination := range byPriority[levels[i-1]] {
+					if err := g.AddEdge(source, destination); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// StartOrder returns services in the order they get started: by dependency depth, then x-start-priority, then name.
+// Services which don't depend on each other might actually be started concurrently
+func StartOrder(project *types.Project) ([]string, error) {
+	graph, err := NewGraph(project, ServiceStopped)
+	if err != nil {
+		return nil, err
+	}
+	depths := graph.depths()
+	priorities := map[string]int{}
+	for _, service := range project.Services {
+		priorities[service.Name], err = startPriority(service)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	names := project.ServiceNames()
+	sort.Slice(names, func(i, j int) bool {
+		left, right := names[i], names[j]
+		if depths[left] != depths[right] {
+			return depths[left] < depths[right]
+		}
+		if priorities[left] != priorities[right] {
+			return priorities[left] > priorities[right]
+		}
+		return left < right
+	})
+	return names, nil
+}
+
 // NewVertex is the constructor function for the Vertex
 func NewVertex(key string, service string, initialStatus ServiceStatus) *Vertex {
 	return &Vertex{
@@ -123,6 +123,45 @@ func TestInDependencyReverseDownCommandOrder(t *testing.T) {
 	require.Equal(t, []string{"test1", "test2", "test3"}, order)
 }
 
+func TestInDependencyOrderWithStartPriority(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	project := &types.Project{
+		Services: types.Services{
+			"db": {
+				Name:       "db",
+				Extensions: types.Extensions{startPriorityExtension: 10},
+			},
+			"cache": {
+				Name: "cache",
+			},
+			"app": {
+				Name: "app",
+				DependsOn: types.DependsOnConfig{
+					"db": types.ServiceDependency{Required: true},
+				},
+			},
+		},
+	}
+
+	var mu sync.Mutex
+	var order []string
+	err := InDependencyOrder(ctx, project, func(ctx context.Context, service string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, service)
+		return nil
+	})
+	require.NoError(t, err, "Error during iteration")
+	require.Equal(t, "db", order[0])
+	require.ElementsMatch(t, []string{"cache", "app"}, order[1:])
+
+	startOrder, err := StartOrder(project)
+	require.NoError(t, err)
+	require.Equal(t, []string{"db", "cache", "app"}, startOrder)
+}
+
 func TestBuildGraph(t *testing.T) {
 	testCases := []struct {
 		desc             string
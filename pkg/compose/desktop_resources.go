@@ -0,0 +1,83 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/docker/api/types/system"
+	"github.com/docker/go-units"
+	"github.com/sirupsen/logrus"
+)
+
+// checkDesktopResources warns when services reserve more memory or CPUs than the Docker Desktop VM is allocated, as
+// containers then get killed for lack of memory or starve, while the same project runs fine on a larger Linux host
+func (s *composeService) checkDesktopResources(ctx context.Context, project *types.Project) {
+	if s.dryRun {
+		return
+	}
+	memory, cpus := projectReservations(project)
+	if memory == 0 && cpus == 0 {
+		return
+	}
+	info, err := s.getEngineInfo(ctx)
+	if err != nil {
+		logrus.Debugf("can't get engine resources: %v", err)
+		return
+	}
+	if info.OperatingSystem != "Docker Desktop" && !s.isDesktopIntegrationActive() {
+		return
+	}
+	for _, warning := range desktopResourcesWarnings(memory, cpus, info) {
+		logrus.Warn(warning)
+	}
+}
+
+// projectReservations sums memory and CPUs reserved by all replicas of the project services
+func projectReservations(project *types.Project) (int64, float32) {
+	var memory int64
+	var cpus float32
+	for _, service := range project.Services {
+		replicas := service.GetScale()
+		reserved := int64(service.MemReservation)
+		if service.Deploy != nil && service.Deploy.Resources.Reservations != nil {
+			r := service.Deploy.Resources.Reservations
+			if r.MemoryBytes > 0 {
+				reserved = int64(r.MemoryBytes)
+			}
+			cpus += float32(r.NanoCPUs) * float32(replicas)
+		}
+		memory += reserved * int64(replicas)
+	}
+	return memory, cpus
+}
+
+func desktopResourcesWarnings(memory int64, cpus float32, info system.Info) []string {
+	var warnings []string
+	if info.MemTotal > 0 && memory > info.MemTotal {
+		warnings = append(warnings, fmt.Sprintf("services reserve %s of memory, but the Docker Desktop VM only has %s. "+
+			"Containers may be killed for lack of memory, raise the memory limit in Docker Desktop settings, under Resources",
+			units.BytesSize(float64(memory)), units.BytesSize(float64(info.MemTotal))))
+	}
+	if info.NCPU > 0 && cpus > float32(info.NCPU) {
+		warnings = append(warnings, fmt.Sprintf("services reserve %g CPUs, but the Docker Desktop VM only has %d. "+
+			"Raise the CPU limit in Docker Desktop settings, under Resources", cpus, info.NCPU))
+	}
+	return warnings
+}
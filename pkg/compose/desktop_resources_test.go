@@ -0,0 +1,55 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/docker/api/types/system"
+	"gotest.tools/v3/assert"
+)
+
+func TestDesktopResourcesWarnings(t *testing.T) {
+	replicas := 3
+	project := &types.Project{
+		Services: types.Services{
+			"api": {
+				Name:  "api",
+				Scale: &replicas,
+				Deploy: &types.DeployConfig{Resources: types.Resources{
+					Reservations: &types.Resource{MemoryBytes: types.UnitBytes(2 << 30), NanoCPUs: 1.5},
+				}},
+			},
+			"db": {
+				Name:           "db",
+				MemReservation: types.UnitBytes(1 << 30),
+			},
+			"worker": {Name: "worker"},
+		},
+	}
+	memory, cpus := projectReservations(project)
+	assert.Equal(t, memory, int64(7<<30))
+	assert.Equal(t, cpus, float32(4.5))
+
+	warnings := desktopResourcesWarnings(memory, cpus, system.Info{MemTotal: 4 << 30, NCPU: 4})
+	assert.DeepEqual(t, warnings, []string{
+		"services reserve 7GiB of memory, but the Docker Desktop VM only has 4GiB. Containers may be killed for lack of memory, raise the memory limit in Docker Desktop settings, under Resources",
+		"services reserve 4.5 CPUs, but the Docker Desktop VM only has 4. Raise the CPU limit in Docker Desktop settings, under Resources",
+	})
+	assert.Equal(t, len(desktopResourcesWarnings(memory, cpus, system.Info{MemTotal: 8 << 30, NCPU: 8})), 0)
+}
@@ -0,0 +1,44 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"strings"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+func (s *composeService) Diff(ctx context.Context, projectName string, service string, options api.DiffOptions) ([]api.FileChange, error) {
+	projectName = strings.ToLower(projectName)
+	ctr, err := s.getSpecifiedContainer(ctx, projectName, oneOffInclude, true, service, options.Index)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := s.apiClient().ContainerDiff(ctx, ctr.ID)
+	if err != nil {
+		return nil, err
+	}
+	diff := make([]api.FileChange, 0, len(changes))
+	for _, change := range changes {
+		diff = append(diff, api.FileChange{
+			Kind: change.Kind.String(),
+			Path: change.Path,
+		})
+	}
+	return diff, nil
+}
@@ -0,0 +1,364 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/cli/opts"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/progress"
+)
+
+// networkDNSExtension sets DNS configuration for containers attached to a network, unless the service declares its own
+const networkDNSExtension = "x-dns"
+
+const (
+	defaultDNSProbeImage  = "busybox:stable"
+	defaultDNSProbeLookup = "docker.com"
+	dnsProbeTimeout       = 15 * time.Second
+)
+
+type networkDNS struct {
+	Servers []string `yaml:"servers"`
+	Options []string `yaml:"options"`
+	Search  []string `yaml:"search"`
+}
+
+func getNetworkDNS(name string, n types.NetworkConfig) (*networkDNS, error) {
+	v, ok := n.Extensions[networkDNSExtension]
+	if !ok {
+		return nil, nil
+	}
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var dns networkDNS
+	decoder := yaml.NewDecoder(bytes.NewReader(b))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&dns); err != nil {
+		return nil, fmt.Errorf("network %s: invalid %s: %w", name, networkDNSExtension, err)
+	}
+	return &dns, nil
+}
+
+// applyNetworkDNS sets DNS configuration declared by networks on the services attached to them. As the engine
+// configures DNS per container, networks a service is attached to must not declare distinct configurations.
+func applyNetworkDNS(project *types.Project) error {
+	configs := map[string]*networkDNS{}
+	for name, n := range project.Networks {
+		dns, err := getNetworkDNS(name, n)
+		if err != nil {
+			return err
+		}
+		if dns != nil {
+			configs[name] = dns
+		}
+	}
+	if len(configs) == 0 {
+		return nil
+	}
+	for name, service := range project.Services {
+		var (
+			from string
+			dns  *networkDNS
+		)
+		for _, network := range service.NetworksByPriority() {
+			c, ok := configs[network]
+			if !ok {
+				continue
+			}
+			if dns != nil {
+				if !slices.Equal(dns.Servers, c.Servers) || !slices.Equal(dns.Options, c.Options) || !slices.Equal(dns.Search, c.Search) {
+					return fmt.Errorf("service %q is attached to networks %s and %s declaring distinct %s", name, from, network, networkDNSExtension)
+				}
+				continue
+			}
+			from, dns = network, c
+		}
+		if dns == nil {
+			continue
+		}
+		if len(service.DNS) == 0 {
+			service.DNS = dns.Servers
+		}
+		if len(service.DNSOpts) == 0 {
+			service.DNSOpts = dns.Options
+		}
+		if len(service.DNSSearch) == 0 {
+			service.DNSSearch = dns.Search
+		}
+		project.Services[name] = service
+	}
+	return nil
+}
+
+// checkDNSSettings validates services DNS configuration, and reports all violations at once. Resolver options
+// unknown to Compose only get a warning, as the resolver in the container image may support those
+func checkDNSSettings(project *types.Project) error {
+	var violations, warnings []string
+	for name, service := range project.Services {
+		errs, warns := dnsViolations(service)
+		for _, v := range errs {
+			violations = append(violations, fmt.Sprintf("service %q %s", name, v))
+		}
+		for _, w := range warns {
+			warnings = append(warnings, fmt.Sprintf("service %q %s", name, w))
+		}
+	}
+	slices.Sort(warnings)
+	for _, w := range warnings {
+		logrus.Warn(w)
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	slices.Sort(violations)
+	return errors.New("invalid DNS configuration:\n  " + strings.Join(violations, "\n  "))
+}
+
+// dnsViolations returns the invalid DNS settings of service, and warnings for those which can't be validated
+func dnsViolations(service types.ServiceConfig) ([]string, []string) {
+	var violations, warnings []string
+	for _, server := range service.DNS {
+		if _, err := opts.ValidateIPAddress(server); err != nil {
+			violations = append(violations, fmt.Sprintf("dns %s: not an IP address", server))
+		}
+	}
+	for _, search := range service.DNSSearch {
+		if _, err := opts.ValidateDNSSearch(search); err != nil {
+			violations = append(violations, fmt.Sprintf("dns_search %s: %s", search, err))
+		}
+	}
+	for _, option := range service.DNSOpts {
+		known, err := validateDNSOption(option)
+		switch {
+		case err != nil:
+			violations = append(violations, fmt.Sprintf("dns_opt %s: %s", option, err))
+		case !known:
+			warnings = append(warnings, fmt.Sprintf("dns_opt %s: unknown resolver option, ignored by resolvers which don't support it", option))
+		}
+	}
+	configured := len(service.DNS) > 0 || len(service.DNSSearch) > 0 || len(service.DNSOpts) > 0
+	if configured && (strings.HasPrefix(service.NetworkMode, types.ServicePrefix) || strings.HasPrefix(service.NetworkMode, types.ContainerPrefix)) {
+		violations = append(violations, fmt.Sprintf("can't set DNS configuration when joining network namespace %s", service.NetworkMode))
+	}
+	return violations, warnings
+}
+
+// validateDNSOption checks the value of option if it is a resolver option known by glibc resolv.conf, and returns
+// false for other options
+func validateDNSOption(option string) (bool, error) {
+	name, value, hasValue := strings.Cut(option, ":")
+	switch name {
+	case "ndots", "timeout", "attempts":
+		if !hasValue {
+			return true, fmt.Errorf("%s requires a value", name)
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return true, fmt.Errorf("%s must be a positive number", name)
+		}
+		return true, nil
+	case "debug", "rotate", "no-check-names", "inet6", "ip6-bytestring", "ip6-dotint", "no-ip6-dotint", "edns0",
+		"single-request", "single-request-reopen", "no-tld-query", "use-vc", "no-reload", "trust-ad", "no-aaaa":
+		if hasValue {
+			return true, fmt.Errorf("%s doesn't accept a value", name)
+		}
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+func (s *composeService) DNSCheck(ctx context.Context, project *types.Project, options api.DNSCheckOptions) ([]api.DNSCheckResult, error) {
+	var results []api.DNSCheckResult
+	err := progress.RunWithTitle(ctx, func(ctx context.Context) error {
+		var err error
+		results, err = s.dnsCheck(ctx, project, options)
+		return err
+	}, s.stdinfo(), "Checking DNS")
+	return results, err
+}
+
+func (s *composeService) dnsCheck(ctx context.Context, project *types.Project, options api.DNSCheckOptions) ([]api.DNSCheckResult, error) {
+	if err := applyNetworkDNS(project); err != nil {
+		return nil, err
+	}
+	if err := checkDNSSettings(project); err != nil {
+		return nil, err
+	}
+	if options.Image == "" {
+		options.Image = defaultDNSProbeImage
+	}
+	if options.Lookup == "" {
+		options.Lookup = defaultDNSProbeLookup
+	}
+	services := options.Services
+	if len(services) == 0 {
+		services = project.ServiceNames()
+	}
+	slices.Sort(services)
+
+	if _, err := s.apiClient().ImageInspect(ctx, options.Image); errdefs.IsNotFound(err) {
+		probe := types.ServiceConfig{Name: "dns-check", Image: options.Image}
+		if _, err := s.pullServiceImage(ctx, probe, s.configFile(), progress.ContextWriter(ctx), true, defaultPlatform(project)); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	var results []api.DNSCheckResult
+	for _, name := range services {
+		service, err := project.GetService(name)
+		if err != nil {
+			return nil, err
+		}
+		networkName, networkMode, skipped := s.dnsProbeNetwork(ctx, project, service)
+		if skipped != "" {
+			results = append(results, api.DNSCheckResult{
+				Service: name,
+				Network: networkName,
+				Status:  api.DNSCheckSkipped,
+				Output:  skipped,
+			})
+			continue
+		}
+		// probe the resolver the service is configured with, then each custom server so an unreachable one is
+		// pinpointed
+		servers := append([]string{""}, service.DNS...)
+		for _, server := range servers {
+			result := api.DNSCheckResult{
+				Service: name,
+				Network: networkName,
+				Server:  server,
+				Status:  api.DNSCheckOK,
+			}
+			start := time.Now()
+			output, err := s.runDNSProbe(ctx, service, networkMode, server, options)
+			result.Duration = time.Since(start)
+			result.Output = output
+			if err != nil {
+				result.Status = api.DNSCheckFailed
+				if output == "" {
+					result.Output = err.Error()
+				}
+			}
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+// dnsProbeNetwork selects the network a probe for service must join, or the reason no probe can be run. Services
+// using a network mode, like host or the engine default bridge network, are probed with the same network mode,
+// while those sharing the network namespace of another service or container are skipped
+func (s *composeService) dnsProbeNetwork(ctx context.Context, project *types.Project, service types.ServiceConfig) (string, string, string) {
+	switch {
+	case service.NetworkMode == "host", service.NetworkMode == "none", service.NetworkMode == "bridge", service.NetworkMode == "default":
+		return service.NetworkMode, service.NetworkMode, ""
+	case service.NetworkMode != "":
+		return service.NetworkMode, "", fmt.Sprintf("service shares network namespace %s", service.NetworkMode)
+	case len(service.Networks) == 0:
+		return "", "", "service isn't attached to any network"
+	}
+	key := service.NetworksByPriority()[0]
+	name := key
+	if n, ok := project.Networks[key]; ok && n.Name != "" {
+		name = n.Name
+	}
+	if _, err := s.apiClient().NetworkInspect(ctx, name, network.InspectOptions{}); err != nil {
+		return name, "", fmt.Sprintf("network %s isn't available, run 'docker compose up' first: %s", name, err)
+	}
+	return name, name, ""
+}
+
+// runDNSProbe runs nslookup in a container configured with service DNS settings, and returns its output
+func (s *composeService) runDNSProbe(ctx context.Context, service types.ServiceConfig, networkMode string, server string, options api.DNSCheckOptions) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, dnsProbeTimeout)
+	defer cancel()
+
+	cmd := []string{"nslookup", options.Lookup}
+	if server != "" {
+		cmd = append(cmd, server)
+	}
+	hostConfig := &container.HostConfig{
+		NetworkMode: container.NetworkMode(networkMode),
+		DNS:         service.DNS,
+		DNSOptions:  service.DNSOpts,
+		DNSSearch:   service.DNSSearch,
+	}
+	if len(service.ExtraHosts) > 0 {
+		hostConfig.ExtraHosts = service.ExtraHosts.AsList(":")
+	}
+	created, err := s.apiClient().ContainerCreate(ctx, &container.Config{
+		Image: options.Image,
+		Cmd:   cmd,
+	}, hostConfig, nil, nil, "")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = s.apiClient().ContainerRemove(context.WithoutCancel(ctx), created.ID, container.RemoveOptions{Force: true})
+	}()
+
+	if err := s.apiClient().ContainerStart(ctx, created.ID, container.StartOptions{}); err != nil {
+		return "", err
+	}
+	var exitCode int64
+	waitC, errC := s.apiClient().ContainerWait(ctx, created.ID, container.WaitConditionNotRunning)
+	select {
+	case result := <-waitC:
+		exitCode = result.StatusCode
+	case err := <-errC:
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", fmt.Errorf("no answer after %s", dnsProbeTimeout)
+		}
+		return "", err
+	}
+
+	logs, err := s.apiClient().ContainerLogs(ctx, created.ID, container.LogsOptions{ShowStdout: true, ShowStderr: true})
+	if err != nil {
+		return "", err
+	}
+	defer logs.Close() //nolint:errcheck
+	var output bytes.Buffer
+	if _, err := stdcopy.StdCopy(&output, &output, logs); err != nil {
+		return "", err
+	}
+	if exitCode != 0 {
+		return strings.TrimSpace(output.String()), fmt.Errorf("nslookup exited with code %d", exitCode)
+	}
+	return strings.TrimSpace(output.String()), nil
+}
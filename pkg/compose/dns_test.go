@@ -0,0 +1,191 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/errdefs"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+
+	compose "github.com/docker/compose/v2/pkg/api"
+)
+
+func TestApplyNetworkDNS(t *testing.T) {
+	project := &types.Project{
+		Networks: types.Networks{
+			"corp": {
+				Extensions: types.Extensions{networkDNSExtension: map[string]any{
+					"servers": []any{"10.0.0.53"},
+					"search":  []any{"corp.example.com"},
+				}},
+			},
+			"public": {},
+		},
+		Services: types.Services{
+			"api": {
+				Name:     "api",
+				Networks: map[string]*types.ServiceNetworkConfig{"corp": nil, "public": nil},
+			},
+			"custom": {
+				Name:     "custom",
+				DNS:      []string{"1.1.1.1"},
+				Networks: map[string]*types.ServiceNetworkConfig{"corp": nil},
+			},
+			"web": {
+				Name:     "web",
+				Networks: map[string]*types.ServiceNetworkConfig{"public": nil},
+			},
+		},
+	}
+	assert.NilError(t, applyNetworkDNS(project))
+	assert.DeepEqual(t, project.Services["api"].DNS, types.StringList{"10.0.0.53"})
+	assert.DeepEqual(t, project.Services["api"].DNSSearch, types.StringList{"corp.example.com"})
+	assert.DeepEqual(t, project.Services["custom"].DNS, types.StringList{"1.1.1.1"})
+	assert.DeepEqual(t, project.Services["custom"].DNSSearch, types.StringList{"corp.example.com"})
+	assert.Assert(t, project.Services["web"].DNS == nil)
+
+	project.Networks["public"] = types.NetworkConfig{
+		Extensions: types.Extensions{networkDNSExtension: map[string]any{"servers": []any{"8.8.8.8"}}},
+	}
+	assert.ErrorContains(t, applyNetworkDNS(project), "declaring distinct x-dns")
+
+	project.Networks["public"] = types.NetworkConfig{
+		Extensions: types.Extensions{networkDNSExtension: map[string]any{"server": []any{"8.8.8.8"}}},
+	}
+	assert.ErrorContains(t, applyNetworkDNS(project), "network public: invalid x-dns")
+}
+
+func TestCheckDNSSettings(t *testing.T) {
+	project := &types.Project{
+		Services: types.Services{
+			"ok": {
+				Name:      "ok",
+				DNS:       []string{"10.0.0.53", "fd00::53"},
+				DNSOpts:   []string{"ndots:2", "rotate"},
+				DNSSearch: []string{"example.com"},
+			},
+		},
+	}
+	assert.NilError(t, checkDNSSettings(project))
+
+	violations, warnings := dnsViolations(types.ServiceConfig{DNSOpts: []string{"fast", "ndots:1"}})
+	assert.Check(t, len(violations) == 0)
+	assert.DeepEqual(t, warnings, []string{"dns_opt fast: unknown resolver option, ignored by resolvers which don't support it"})
+
+	project.Services["bad"] = types.ServiceConfig{
+		Name:        "bad",
+		DNS:         []string{"dns.example.com"},
+		DNSOpts:     []string{"ndots", "rotate:1", "fast"},
+		NetworkMode: "service:ok",
+	}
+	err := checkDNSSettings(project)
+	assert.Equal(t, err.Error(), `invalid DNS configuration:
+  service "bad" can't set DNS configuration when joining network namespace service:ok
+  service "bad" dns dns.example.com: not an IP address
+  service "bad" dns_opt ndots: ndots requires a value
+  service "bad" dns_opt rotate:1: rotate doesn't accept a value`)
+}
+
+func TestDNSCheck(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	api, cli := prepareMocks(mockCtrl)
+	tested := composeService{
+		dockerCli: cli,
+	}
+	project := &types.Project{
+		Name: "test",
+		Networks: types.Networks{
+			"default": {Name: "test_default"},
+			"missing": {Name: "test_missing"},
+		},
+		Services: types.Services{
+			"db": {
+				Name:     "db",
+				Networks: map[string]*types.ServiceNetworkConfig{"missing": nil},
+			},
+			"legacy": {
+				Name:        "legacy",
+				NetworkMode: "bridge",
+			},
+			"sidecar": {
+				Name:        "sidecar",
+				NetworkMode: "service:web",
+			},
+			"web": {
+				Name:     "web",
+				DNS:      []string{"10.0.0.53"},
+				Networks: map[string]*types.ServiceNetworkConfig{"default": nil},
+			},
+		},
+	}
+
+	api.EXPECT().ImageInspect(gomock.Any(), "busybox:stable").Return(image.InspectResponse{}, nil)
+	api.EXPECT().NetworkInspect(gomock.Any(), "test_missing", gomock.Any()).Return(network.Inspect{}, errdefs.NotFound(errors.New("not found")))
+	api.EXPECT().NetworkInspect(gomock.Any(), "test_default", gomock.Any()).Return(network.Inspect{}, nil)
+	api.EXPECT().ContainerCreate(gomock.Any(), &container.Config{Image: "busybox:stable", Cmd: []string{"nslookup", "docker.com"}}, &container.HostConfig{
+		NetworkMode: "bridge",
+	}, nil, nil, "").Return(container.CreateResponse{ID: "bridge"}, nil)
+	api.EXPECT().ContainerStart(gomock.Any(), "bridge", gomock.Any())
+	bridgeC := make(chan container.WaitResponse, 1)
+	bridgeC <- container.WaitResponse{}
+	api.EXPECT().ContainerWait(gomock.Any(), "bridge", container.WaitConditionNotRunning).Return(bridgeC, make(chan error))
+	api.EXPECT().ContainerLogs(gomock.Any(), "bridge", gomock.Any()).Return(io.NopCloser(strings.NewReader("")), nil)
+	api.EXPECT().ContainerRemove(gomock.Any(), "bridge", container.RemoveOptions{Force: true})
+	for _, cmd := range [][]string{{"nslookup", "docker.com"}, {"nslookup", "docker.com", "10.0.0.53"}} {
+		id := strings.Join(cmd, "-")
+		api.EXPECT().ContainerCreate(gomock.Any(), &container.Config{Image: "busybox:stable", Cmd: cmd}, &container.HostConfig{
+			NetworkMode: "test_default",
+			DNS:         []string{"10.0.0.53"},
+		}, nil, nil, "").Return(container.CreateResponse{ID: id}, nil)
+		api.EXPECT().ContainerStart(gomock.Any(), id, gomock.Any())
+		statusCode := int64(0)
+		if len(cmd) == 3 {
+			statusCode = 1
+		}
+		waitC := make(chan container.WaitResponse, 1)
+		waitC <- container.WaitResponse{StatusCode: statusCode}
+		api.EXPECT().ContainerWait(gomock.Any(), id, container.WaitConditionNotRunning).Return(waitC, make(chan error))
+		api.EXPECT().ContainerLogs(gomock.Any(), id, gomock.Any()).Return(io.NopCloser(strings.NewReader("")), nil)
+		api.EXPECT().ContainerRemove(gomock.Any(), id, container.RemoveOptions{Force: true})
+	}
+
+	results, err := tested.dnsCheck(context.Background(), project, compose.DNSCheckOptions{})
+	assert.NilError(t, err)
+	for i := range results {
+		results[i].Duration = 0
+	}
+	assert.DeepEqual(t, results, []compose.DNSCheckResult{
+		{Service: "db", Network: "test_missing", Status: compose.DNSCheckSkipped,
+			Output: "network test_missing isn't available, run 'docker compose up' first: not found"},
+		{Service: "legacy", Network: "bridge", Status: compose.DNSCheckOK},
+		{Service: "sidecar", Network: "service:web", Status: compose.DNSCheckSkipped,
+			Output: "service shares network namespace service:web"},
+		{Service: "web", Network: "test_default", Status: compose.DNSCheckOK},
+		{Service: "web", Network: "test_default", Server: "10.0.0.53", Status: compose.DNSCheckFailed,
+			Output: "nslookup exited with code 1"},
+	})
+}
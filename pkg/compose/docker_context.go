@@ -0,0 +1,89 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"sync"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/config"
+	"github.com/docker/cli/cli/context/docker"
+	"github.com/docker/cli/cli/flags"
+	"github.com/docker/docker/client"
+	"github.com/sirupsen/logrus"
+)
+
+// contextCli reaches the engine of another docker context, keeping the streams and configuration of the CLI it wraps
+type contextCli struct {
+	command.Cli
+	name   string
+	target *command.DockerCli
+}
+
+func (c contextCli) Client() client.APIClient {
+	return c.target.Client()
+}
+
+func (c contextCli) CurrentContext() string {
+	return c.name
+}
+
+func (c contextCli) DockerEndpoint() docker.Endpoint {
+	return c.target.DockerEndpoint()
+}
+
+func (c contextCli) ServerInfo() command.ServerInfo {
+	return c.target.ServerInfo()
+}
+
+// UseDockerContext makes compose target the engine set by docker context name, without changing the CLI current context
+func (s *composeService) UseDockerContext(name string) error {
+	if name == "" || name == s.dockerCli.CurrentContext() {
+		return nil
+	}
+	target, err := command.NewDockerCli()
+	if err != nil {
+		return err
+	}
+	options := flags.NewClientOptions()
+	options.Context = name
+	options.ConfigDir = config.Dir()
+	options.LogLevel = logrus.GetLevel().String()
+	if err := target.Initialize(options); err != nil {
+		return err
+	}
+	s.dockerCli = contextCli{Cli: s.dockerCli, name: name, target: target}
+	s.resetEngineState()
+	if s.dryRun {
+		// wrap engine for the selected context with the dry-run client
+		_, err = s.DryRunMode(context.Background(), true)
+	}
+	return err
+}
+
+// DockerCli returns the docker CLI reaching the engine compose targets
+func (s *composeService) DockerCli() command.Cli {
+	return s.dockerCli
+}
+
+// resetEngineState drops engine details queried so far, as they describe the previously targeted engine
+func (s *composeService) resetEngineState() {
+	s.engineInfo = &engineInfoCache{}
+	runtimeVersion = runtimeVersionCache{}
+	swarmEnabled.once, swarmEnabled.val, swarmEnabled.err = sync.Once{}, false, nil
+}
@@ -0,0 +1,69 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/config"
+	"github.com/docker/cli/cli/context/docker"
+	"github.com/docker/cli/cli/context/store"
+	"github.com/docker/cli/cli/flags"
+	"github.com/docker/docker/api/types/system"
+	"gotest.tools/v3/assert"
+)
+
+func TestUseDockerContext(t *testing.T) {
+	dir := t.TempDir()
+	previous := config.Dir()
+	config.SetDir(dir)
+	t.Cleanup(func() { config.SetDir(previous) })
+
+	out := &bytes.Buffer{}
+	cli, err := command.NewDockerCli(command.WithOutputStream(out), command.WithErrorStream(out))
+	assert.NilError(t, err)
+	options := flags.NewClientOptions()
+	options.ConfigDir = dir
+	assert.NilError(t, cli.Initialize(options))
+	assert.NilError(t, cli.ContextStore().CreateOrUpdate(store.Metadata{
+		Name:      "remote",
+		Metadata:  command.DockerContext{},
+		Endpoints: map[string]any{docker.DockerEndpoint: docker.EndpointMeta{Host: "tcp://remote.example.com:2376"}},
+	}))
+
+	s := &composeService{dockerCli: cli, engineInfo: &engineInfoCache{}}
+	s.engineInfo.once.Do(func() { s.engineInfo.val = system.Info{Name: "local"} })
+	runtimeVersion.once.Do(func() { runtimeVersion.os = "windows" })
+	t.Cleanup(func() { runtimeVersion = runtimeVersionCache{} })
+
+	assert.NilError(t, s.UseDockerContext("remote"))
+	assert.Equal(t, s.dockerCli.CurrentContext(), "remote")
+	assert.Equal(t, s.dockerCli.DockerEndpoint().Host, "tcp://remote.example.com:2376")
+	assert.Equal(t, s.dockerCli.Client().DaemonHost(), "tcp://remote.example.com:2376")
+	// streams of the original CLI are kept
+	assert.Equal(t, s.stdout(), cli.Out())
+	assert.Equal(t, s.stderr(), cli.Err())
+	// engine details are queried again from the selected engine
+	assert.Equal(t, s.engineInfo.val.Name, "")
+	assert.Equal(t, runtimeVersion.os, "")
+
+	// already targeted
+	assert.NilError(t, s.UseDockerContext("remote"))
+	assert.Equal(t, s.dockerCli.CurrentContext(), "remote")
+}
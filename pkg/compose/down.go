@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/compose-spec/compose-go/v2/types"
@@ -38,9 +39,27 @@ import (
 type downOp func() error
 
 func (s *composeService) Down(ctx context.Context, projectName string, options api.DownOptions) error {
-	return progress.Run(ctx, func(ctx context.Context) error {
-		return s.down(ctx, strings.ToLower(projectName), options)
+	var hooks map[string][]projectHook
+	if len(options.Services) == 0 {
+		var err error
+		hooks, err = getProjectHooks(options.Project)
+		if err != nil {
+			return err
+		}
+	}
+	err := progress.Run(ctx, func(ctx context.Context) error {
+		if err := s.runProjectHooks(ctx, options.Project, hooks, hookPreDown, nil); err != nil {
+			return err
+		}
+		if err := s.down(ctx, strings.ToLower(projectName), options); err != nil {
+			return err
+		}
+		return s.runProjectHooks(ctx, options.Project, hooks, hookPostDown, nil)
 	}, s.stdinfo())
+	if err != nil {
+		s.runFailureHooks(ctx, options.Project, hooks, err)
+	}
+	return err
 }
 
 func (s *composeService) down(ctx context.Context, projectName string, options api.DownOptions) error { //nolint:gocyclo
@@ -81,25 +100,51 @@ func (s *composeService) down(ctx context.Context, projectName string, options a
 		resourceToRemove = true
 	}
 
-	err = InReverseDependencyOrder(ctx, project, func(c context.Context, service string) error {
-		serv := project.Services[service]
-		if serv.Provider != nil {
-			return s.runPlugin(ctx, project, serv, "down")
+	if len(options.Services) == 0 {
+		// host processes depend on services, so they are stopped first
+		if err := s.stopHostProcesses(ctx, projectName, true, options.Timeout); err != nil {
+			return err
 		}
-		serviceContainers := containers.filter(isService(service))
-		err := s.removeContainers(ctx, serviceContainers, &serv, options.Timeout, options.Volumes)
-		return err
-	}, WithRootNodesAndDown(options.Services))
-	if err != nil {
-		return err
 	}
 
+	// orphans don't depend on project services, so they are removed meanwhile
+	teardown, _ := errgroup.WithContext(ctx)
 	orphans := containers.filter(isOrphaned(project))
 	if options.RemoveOrphans && len(orphans) > 0 {
-		err := s.removeContainers(ctx, orphans, nil, options.Timeout, false)
-		if err != nil {
-			return err
+		teardown.Go(func() error {
+			if options.Force {
+				return s.forceRemoveContainers(ctx, orphans, false)
+			}
+			return s.removeContainers(ctx, orphans, nil, options.Timeout, false)
+		})
+	}
+	if len(options.Services) == 0 {
+		teardown.Go(func() error {
+			return s.removeTTLSupervisor(ctx, projectName)
+		})
+	}
+	if cache, _ := projectPackageCache(project); cache != nil && len(options.Services) == 0 {
+		teardown.Go(func() error {
+			return s.removePackageCache(ctx, projectName)
+		})
+	}
+	teardown.Go(func() error {
+		if options.Force {
+			return s.forceDown(ctx, project, containers, options)
 		}
+		return InReverseDependencyOrder(ctx, project, func(c context.Context, service string) error {
+			serv := project.Services[service]
+			if serv.Provider != nil {
+				return s.runPlugin(ctx, project, serv, "down")
+			}
+			serviceContainers := containers.filter(isService(service))
+			err := s.removeContainers(ctx, serviceContainers, &serv, options.Timeout, options.Volumes)
+			return err
+		}, WithRootNodesAndDown(options.Services))
+	})
+	err = teardown.Wait()
+	if err != nil {
+		return err
 	}
 
 	ops := s.ensureNetworksDown(ctx, project, w)
@@ -127,6 +172,42 @@ func (s *composeService) down(ctx context.Context, projectName string, options a
 	return eg.Wait()
 }
 
+// forceDown removes containers of all services to tear down at once, regardless of dependencies, without stopping
+// them gracefully
+func (s *composeService) forceDown(ctx context.Context, project *types.Project, containers Containers, options api.DownOptions) error {
+	// dependency order has no effect when containers are killed, only collect services to tear down
+	var (
+		mutex    sync.Mutex
+		services []string
+	)
+	err := InReverseDependencyOrder(ctx, project, func(_ context.Context, service string) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		services = append(services, service)
+		return nil
+	}, WithRootNodesAndDown(options.Services))
+	if err != nil {
+		return err
+	}
+
+	eg, ctx := errgroup.WithContext(ctx)
+	var toRemove Containers
+	for _, name := range services {
+		service := project.Services[name]
+		if service.Provider != nil {
+			eg.Go(func() error {
+				return s.runPlugin(ctx, project, service, "down")
+			})
+			continue
+		}
+		toRemove = append(toRemove, containers.filter(isService(name))...)
+	}
+	eg.Go(func() error {
+		return s.forceRemoveContainers(ctx, toRemove, options.Volumes)
+	})
+	return eg.Wait()
+}
+
 func checkSelectedServices(options api.DownOptions, project *types.Project) ([]string, error) {
 	var services []string
 	for _, service := range options.Services {
@@ -341,19 +422,39 @@ func (s *composeService) removeContainers(ctx context.Context, containers []cont
 	return eg.Wait()
 }
 
+// forceRemoveContainers removes containers, killing those still running without waiting for a graceful stop
+func (s *composeService) forceRemoveContainers(ctx context.Context, containers []containerType.Summary, volumes bool) error {
+	w := progress.ContextWriter(ctx)
+	eg, _ := errgroup.WithContext(ctx)
+	for _, ctr := range containers {
+		eg.Go(func() error {
+			return s.removeContainer(ctx, w, ctr, volumes)
+		})
+	}
+	return eg.Wait()
+}
+
 func (s *composeService) stopAndRemoveContainer(ctx context.Context, ctr containerType.Summary, service *types.ServiceConfig, timeout *time.Duration, volumes bool) error {
 	w := progress.ContextWriter(ctx)
 	eventName := getContainerProgressName(ctr)
-	err := s.stopContainer(ctx, w, service, ctr, timeout)
-	if errdefs.IsNotFound(err) {
-		w.Event(progress.RemovedEvent(eventName))
-		return nil
-	}
-	if err != nil {
-		return err
+	if !isStopped(ctr) {
+		// stopped containers have no process to stop gracefully, so there's no stop timeout to wait for
+		err := s.stopContainer(ctx, w, service, ctr, timeout)
+		if errdefs.IsNotFound(err) {
+			w.Event(progress.RemovedEvent(eventName))
+			return nil
+		}
+		if err != nil {
+			return err
+		}
 	}
+	return s.removeContainer(ctx, w, ctr, volumes)
+}
+
+func (s *composeService) removeContainer(ctx context.Context, w progress.Writer, ctr containerType.Summary, volumes bool) error {
+	eventName := getContainerProgressName(ctr)
 	w.Event(progress.RemovingEvent(eventName))
-	err = s.apiClient().ContainerRemove(ctx, ctr.ID, containerType.RemoveOptions{
+	err := s.apiClient().ContainerRemove(ctx, ctr.ID, containerType.RemoveOptions{
 		Force:         true,
 		RemoveVolumes: volumes,
 	})
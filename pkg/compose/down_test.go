@@ -18,6 +18,7 @@ package compose
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -47,11 +48,13 @@ func TestDown(t *testing.T) {
 		dockerCli: cli,
 	}
 
+	api.EXPECT().ContainerRemove(gomock.Any(), ttlSupervisorName(strings.ToLower(testProject)), container.RemoveOptions{Force: true}).
+		Return(errdefs.NotFound(errors.New("no such container")))
 	api.EXPECT().ContainerList(gomock.Any(), projectFilterListOpt(false)).Return(
 		[]container.Summary{
-			testContainer("service1", "123", false),
-			testContainer("service2", "456", false),
-			testContainer("service2", "789", false),
+			testRunningContainer("service1", "123", false),
+			testRunningContainer("service2", "456", false),
+			testRunningContainer("service2", "789", false),
 			testContainer("service_orphan", "321", true),
 		}, nil)
 	api.EXPECT().VolumeList(
@@ -106,9 +109,9 @@ func TestDownWithGivenServices(t *testing.T) {
 
 	api.EXPECT().ContainerList(gomock.Any(), projectFilterListOpt(false)).Return(
 		[]container.Summary{
-			testContainer("service1", "123", false),
-			testContainer("service2", "456", false),
-			testContainer("service2", "789", false),
+			testRunningContainer("service1", "123", false),
+			testRunningContainer("service2", "456", false),
+			testRunningContainer("service2", "789", false),
 			testContainer("service_orphan", "321", true),
 		}, nil)
 	api.EXPECT().VolumeList(
@@ -158,9 +161,9 @@ func TestDownWithSpecifiedServiceButTheServicesAreNotRunning(t *testing.T) {
 
 	api.EXPECT().ContainerList(gomock.Any(), projectFilterListOpt(false)).Return(
 		[]container.Summary{
-			testContainer("service1", "123", false),
-			testContainer("service2", "456", false),
-			testContainer("service2", "789", false),
+			testRunningContainer("service1", "123", false),
+			testRunningContainer("service2", "456", false),
+			testRunningContainer("service2", "789", false),
 			testContainer("service_orphan", "321", true),
 		}, nil)
 	api.EXPECT().VolumeList(
@@ -193,10 +196,12 @@ func TestDownRemoveOrphans(t *testing.T) {
 		dockerCli: cli,
 	}
 
+	api.EXPECT().ContainerRemove(gomock.Any(), ttlSupervisorName(strings.ToLower(testProject)), container.RemoveOptions{Force: true}).
+		Return(errdefs.NotFound(errors.New("no such container")))
 	api.EXPECT().ContainerList(gomock.Any(), projectFilterListOpt(true)).Return(
 		[]container.Summary{
-			testContainer("service1", "123", false),
-			testContainer("service2", "789", false),
+			testRunningContainer("service1", "123", false),
+			testRunningContainer("service2", "789", false),
 			testContainer("service_orphan", "321", true),
 		}, nil)
 	api.EXPECT().VolumeList(
@@ -216,7 +221,7 @@ func TestDownRemoveOrphans(t *testing.T) {
 	stopOptions := container.StopOptions{}
 	api.EXPECT().ContainerStop(gomock.Any(), "123", stopOptions).Return(nil)
 	api.EXPECT().ContainerStop(gomock.Any(), "789", stopOptions).Return(nil)
-	api.EXPECT().ContainerStop(gomock.Any(), "321", stopOptions).Return(nil)
+	// exited one-off container has no process to stop
 
 	api.EXPECT().ContainerRemove(gomock.Any(), "123", container.RemoveOptions{Force: true}).Return(nil)
 	api.EXPECT().ContainerRemove(gomock.Any(), "789", container.RemoveOptions{Force: true}).Return(nil)
@@ -244,8 +249,10 @@ func TestDownRemoveVolumes(t *testing.T) {
 		dockerCli: cli,
 	}
 
+	api.EXPECT().ContainerRemove(gomock.Any(), ttlSupervisorName(strings.ToLower(testProject)), container.RemoveOptions{Force: true}).
+		Return(errdefs.NotFound(errors.New("no such container")))
 	api.EXPECT().ContainerList(gomock.Any(), projectFilterListOpt(false)).Return(
-		[]container.Summary{testContainer("service1", "123", false)}, nil)
+		[]container.Summary{testRunningContainer("service1", "123", false)}, nil)
 	api.EXPECT().VolumeList(
 		gomock.Any(),
 		volume.ListOptions{
@@ -291,6 +298,9 @@ func TestDownRemoveImages(t *testing.T) {
 		dockerCli: cli,
 	}
 
+	api.EXPECT().ContainerRemove(gomock.Any(), ttlSupervisorName(strings.ToLower(testProject)), container.RemoveOptions{Force: true}).
+		Return(errdefs.NotFound(errors.New("no such container"))).
+		Times(2)
 	api.EXPECT().ContainerList(gomock.Any(), projectFilterListOpt(false)).
 		Return([]container.Summary{
 			testContainer("service1", "123", false),
@@ -380,8 +390,10 @@ func TestDownRemoveImages_NoLabel(t *testing.T) {
 		dockerCli: cli,
 	}
 
-	ctr := testContainer("service1", "123", false)
+	ctr := testRunningContainer("service1", "123", false)
 
+	api.EXPECT().ContainerRemove(gomock.Any(), ttlSupervisorName(strings.ToLower(testProject)), container.RemoveOptions{Force: true}).
+		Return(errdefs.NotFound(errors.New("no such container")))
 	api.EXPECT().ContainerList(gomock.Any(), projectFilterListOpt(false)).Return(
 		[]container.Summary{ctr}, nil)
 
@@ -417,6 +429,45 @@ func TestDownRemoveImages_NoLabel(t *testing.T) {
 	assert.NilError(t, err)
 }
 
+func TestDownForce(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	api, cli := prepareMocks(mockCtrl)
+	tested := composeService{
+		dockerCli: cli,
+	}
+
+	api.EXPECT().ContainerRemove(gomock.Any(), ttlSupervisorName(strings.ToLower(testProject)), container.RemoveOptions{Force: true}).
+		Return(errdefs.NotFound(errors.New("no such container")))
+	api.EXPECT().ContainerList(gomock.Any(), projectFilterListOpt(false)).Return(
+		[]container.Summary{
+			testRunningContainer("service1", "123", false),
+			testContainer("service2", "456", false),
+		}, nil)
+	api.EXPECT().VolumeList(
+		gomock.Any(),
+		volume.ListOptions{
+			Filters: filters.NewArgs(projectFilter(strings.ToLower(testProject))),
+		}).
+		Return(volume.ListResponse{}, nil)
+	api.EXPECT().NetworkList(gomock.Any(), network.ListOptions{Filters: filters.NewArgs(projectFilter(strings.ToLower(testProject)))}).
+		Return(nil, nil)
+
+	// no ContainerStop, running containers get killed on removal
+	api.EXPECT().ContainerRemove(gomock.Any(), "123", container.RemoveOptions{Force: true}).Return(nil)
+	api.EXPECT().ContainerRemove(gomock.Any(), "456", container.RemoveOptions{Force: true}).Return(nil)
+
+	err := tested.Down(context.Background(), strings.ToLower(testProject), compose.DownOptions{Force: true})
+	assert.NilError(t, err)
+}
+
+func testRunningContainer(service string, id string, oneOff bool) container.Summary {
+	ctr := testContainer(service, id, oneOff)
+	ctr.State = ContainerRunning
+	return ctr
+}
+
 func prepareMocks(mockCtrl *gomock.Controller) (*mocks.MockAPIClient, *mocks.MockCli) {
 	api := mocks.NewMockAPIClient(mockCtrl)
 	cli := mocks.NewMockCli(mockCtrl)
@@ -29,6 +29,32 @@ import (
 
 func (s *composeService) Exec(ctx context.Context, projectName string, options api.RunOptions) (int, error) {
 	projectName = strings.ToLower(projectName)
+	// a session is recorded when started or attached to, including detached, as it keeps running without client
+	if options.AuditLog != "" && (!options.Detach || options.Session != "" || options.AttachSession != "") {
+		env := s.execTranscriptEnv(projectName, options)
+		if options.AttachSession != "" {
+			// environment was recorded when the session started
+			env = func(context.Context) ([]string, error) { return nil, nil }
+		}
+		return s.withTranscript(ctx, "exec", projectName, options, env, func(s *composeService) (int, error) {
+			return s.execute(ctx, projectName, options)
+		})
+	}
+	return s.execute(ctx, projectName, options)
+}
+
+// execute runs the exec command, in a session if set
+func (s *composeService) execute(ctx context.Context, projectName string, options api.RunOptions) (int, error) {
+	if options.AttachSession != "" {
+		return s.resumeExecSession(ctx, projectName, options.AttachSession)
+	}
+	if options.Session != "" {
+		return s.runExecSession(ctx, projectName, options)
+	}
+	return s.runExec(ctx, projectName, options)
+}
+
+func (s *composeService) runExec(ctx context.Context, projectName string, options api.RunOptions) (int, error) {
 	target, err := s.getExecTarget(ctx, projectName, options)
 	if err != nil {
 		return 0, err
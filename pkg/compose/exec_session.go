@@ -0,0 +1,218 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/docker/cli/cli"
+	cmd "github.com/docker/cli/cli/command/container"
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// sessionName validates exec session names, which are part of the name of the container running them
+var sessionName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// runExecSession runs the exec command in a one-off container sharing the process, network and IPC namespaces and the
+// volumes of the target container. Unlike an exec process, a container can be detached from and attached to again, so
+// the session survives client disconnects. As it runs from the service image, the command doesn't see changes made to
+// the target container filesystem outside of volumes, nor its tmpfs mounts
+func (s *composeService) runExecSession(ctx context.Context, projectName string, options api.RunOptions) (int, error) {
+	if !sessionName.MatchString(options.Session) {
+		return 0, fmt.Errorf("invalid session name %q, only [a-zA-Z0-9][a-zA-Z0-9_.-] are allowed", options.Session)
+	}
+	if len(options.Command) == 0 {
+		return 0, errors.New("a command is required to run a session")
+	}
+	existing, err := s.getExecSession(ctx, projectName, options.Session)
+	if err != nil {
+		return 0, err
+	}
+	if existing != nil {
+		return 0, fmt.Errorf("session %q already exists, resume it with --attach-session %s", options.Session, options.Session)
+	}
+
+	target, err := s.getExecTarget(ctx, projectName, options)
+	if err != nil {
+		return 0, err
+	}
+	inspected, err := s.apiClient().ContainerInspect(ctx, target.ID)
+	if err != nil {
+		return 0, err
+	}
+	name := strings.Join([]string{projectName, options.Service, "session", options.Session}, api.Separator)
+	created, err := s.apiClient().ContainerCreate(ctx, execSessionConfig(inspected, options), execSessionHostConfig(inspected, options), nil, nil, name)
+	if err != nil {
+		return 0, err
+	}
+	return s.attachExecSession(ctx, created.ID, options.Session, !options.Detach, true)
+}
+
+// execSessionHostConfig joins the namespaces of target, and grants the container running an exec session the
+// capabilities, security options and devices target has, like an exec process would get
+func execSessionHostConfig(target container.InspectResponse, options api.RunOptions) *container.HostConfig {
+	hostConfig := &container.HostConfig{
+		PidMode:     container.PidMode("container:" + target.ID),
+		NetworkMode: container.NetworkMode("container:" + target.ID),
+		IpcMode:     container.IpcMode("container:" + target.ID),
+		VolumesFrom: []string{target.ID},
+		Privileged:  options.Privileged,
+	}
+	if target.HostConfig != nil {
+		hostConfig.CapAdd = slices.Clone(target.HostConfig.CapAdd)
+		hostConfig.CapDrop = slices.Clone(target.HostConfig.CapDrop)
+		hostConfig.SecurityOpt = slices.Clone(target.HostConfig.SecurityOpt)
+		hostConfig.Devices = slices.Clone(target.HostConfig.Devices)
+	}
+	return hostConfig
+}
+
+// execSessionConfig configures the container running an exec session like an exec process in target: same image,
+// environment, user and working directory unless overridden. It is labeled as a one-off container of the target
+// service, so it shows up in `ps --all` and gets removed by `down --remove-orphans`
+func execSessionConfig(target container.InspectResponse, options api.RunOptions) *container.Config {
+	labels := maps.Clone(target.Config.Labels)
+	delete(labels, api.ContainerNumberLabel)
+	labels[api.OneoffLabel] = "True"
+	labels[api.SessionLabel] = options.Session
+
+	config := &container.Config{
+		Image:        target.Image,
+		Entrypoint:   options.Command[:1],
+		Cmd:          options.Command[1:],
+		Env:          append(slices.Clone(target.Config.Env), options.Environment...),
+		User:         target.Config.User,
+		WorkingDir:   target.Config.WorkingDir,
+		Tty:          options.Tty,
+		OpenStdin:    true,
+		AttachStdin:  options.Interactive,
+		AttachStdout: true,
+		AttachStderr: true,
+		Labels:       labels,
+	}
+	if options.User != "" {
+		config.User = options.User
+	}
+	if options.WorkingDir != "" {
+		config.WorkingDir = options.WorkingDir
+	}
+	return config
+}
+
+// resumeExecSession attaches to a running exec session
+func (s *composeService) resumeExecSession(ctx context.Context, projectName string, name string) (int, error) {
+	session, err := s.getExecSession(ctx, projectName, name)
+	if err != nil {
+		return 0, err
+	}
+	if session == nil {
+		return 0, fmt.Errorf("no session %q found for project %s", name, projectName)
+	}
+	if session.State != ContainerRunning {
+		// the command exited while no client was attached, report its status and clean up
+		inspected, err := s.apiClient().ContainerInspect(ctx, session.ID)
+		if err != nil {
+			return 0, err
+		}
+		if err := s.apiClient().ContainerRemove(ctx, session.ID, container.RemoveOptions{Force: true}); err != nil {
+			return 0, err
+		}
+		return inspected.State.ExitCode, fmt.Errorf("session %q has ended", name)
+	}
+	return s.attachExecSession(ctx, session.ID, name, true, false)
+}
+
+// getExecSession returns the container running the named session, or nil if there's none
+func (s *composeService) getExecSession(ctx context.Context, projectName string, name string) (*container.Summary, error) {
+	containers, err := s.apiClient().ContainerList(ctx, container.ListOptions{
+		Filters: filters.NewArgs(
+			projectFilter(projectName),
+			filters.Arg("label", fmt.Sprintf("%s=%s", api.SessionLabel, name)),
+		),
+		All: true,
+	})
+	if err != nil || len(containers) == 0 {
+		return nil, err
+	}
+	return &containers[0], nil
+}
+
+// attachExecSession starts the session container unless it's already running, and attaches to it unless detached.
+// Signals aren't forwarded, so a client disconnect doesn't terminate the session. Once its command exited, the
+// container is removed
+func (s *composeService) attachExecSession(ctx context.Context, id string, name string, attach bool, start bool) (int, error) {
+	if start {
+		// docker start --attach forwards signals to non-tty containers, start and attach separately instead
+		if err := s.apiClient().ContainerStart(ctx, id, container.StartOptions{}); err != nil {
+			return 0, err
+		}
+	}
+	var err error
+	exitCode := 0
+	if attach {
+		err = cmd.RunAttach(ctx, s.dockerCli, id, &cmd.AttachOptions{Proxy: false})
+		var stErr cli.StatusError
+		if errors.As(err, &stErr) {
+			exitCode, err = stErr.StatusCode, nil
+		} else if err != nil {
+			err = s.replayExecSession(ctx, id, err)
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	inspected, err := s.apiClient().ContainerInspect(context.WithoutCancel(ctx), id)
+	if err != nil {
+		return exitCode, err
+	}
+	if inspected.State.Running {
+		_, _ = fmt.Fprintf(s.stderr(), "Session %s is running, resume it with `docker compose exec --attach-session %s`\n", name, name)
+		return 0, nil
+	}
+	return inspected.State.ExitCode, s.apiClient().ContainerRemove(context.WithoutCancel(ctx), id, container.RemoveOptions{Force: true})
+}
+
+// replayExecSession copies the output of a session whose command exited before it could be attached to. attachErr
+// is returned as is if the session is still running
+func (s *composeService) replayExecSession(ctx context.Context, id string, attachErr error) error {
+	inspected, err := s.apiClient().ContainerInspect(ctx, id)
+	if err != nil || inspected.State.Running {
+		return attachErr
+	}
+	logs, err := s.apiClient().ContainerLogs(ctx, id, container.LogsOptions{ShowStdout: true, ShowStderr: true})
+	if err != nil {
+		return err
+	}
+	defer logs.Close() //nolint:errcheck
+	if inspected.Config.Tty {
+		_, err = io.Copy(s.stdout(), logs)
+	} else {
+		_, err = stdcopy.StdCopy(s.stdout(), s.stderr(), logs)
+	}
+	return err
+}
@@ -0,0 +1,145 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/docker/cli/cli/streams"
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/mocks"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/strslice"
+	"github.com/docker/docker/pkg/stdcopy"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+)
+
+func TestExecSessionConfig(t *testing.T) {
+	target := container.InspectResponse{
+		ContainerJSONBase: &container.ContainerJSONBase{Image: "sha256:1234"},
+		Config: &container.Config{
+			Env:        []string{"PATH=/usr/bin", "LOG_LEVEL=info"},
+			User:       "app",
+			WorkingDir: "/srv",
+			Labels: map[string]string{
+				api.ProjectLabel:         "demo",
+				api.ServiceLabel:         "web",
+				api.ConfigHashLabel:      "abcd",
+				api.ContainerNumberLabel: "1",
+				api.OneoffLabel:          "False",
+			},
+		},
+	}
+	config := execSessionConfig(target, api.RunOptions{
+		Service:     "web",
+		Command:     []string{"python", "migrate.py"},
+		Environment: []string{"LOG_LEVEL=debug"},
+		WorkingDir:  "/srv/tools",
+		Tty:         true,
+		Interactive: true,
+		Session:     "migration",
+	})
+
+	assert.Equal(t, config.Image, "sha256:1234")
+	assert.DeepEqual(t, config.Entrypoint, strslice.StrSlice{"python"})
+	assert.DeepEqual(t, config.Cmd, strslice.StrSlice{"migrate.py"})
+	assert.DeepEqual(t, config.Env, []string{"PATH=/usr/bin", "LOG_LEVEL=info", "LOG_LEVEL=debug"})
+	assert.Equal(t, config.User, "app")
+	assert.Equal(t, config.WorkingDir, "/srv/tools")
+	assert.Assert(t, config.Tty && config.OpenStdin && !config.StdinOnce)
+	assert.DeepEqual(t, config.Labels, map[string]string{
+		api.ProjectLabel:    "demo",
+		api.ServiceLabel:    "web",
+		api.ConfigHashLabel: "abcd",
+		api.OneoffLabel:     "True",
+		api.SessionLabel:    "migration",
+	})
+	// the target container labels are left untouched
+	assert.Equal(t, target.Config.Labels[api.ContainerNumberLabel], "1")
+}
+
+func TestExecSessionHostConfig(t *testing.T) {
+	target := container.InspectResponse{
+		ContainerJSONBase: &container.ContainerJSONBase{
+			ID: "123",
+			HostConfig: &container.HostConfig{
+				CapAdd:      strslice.StrSlice{"NET_ADMIN"},
+				CapDrop:     strslice.StrSlice{"MKNOD"},
+				SecurityOpt: []string{"seccomp=unconfined"},
+				Resources: container.Resources{
+					Devices: []container.DeviceMapping{{PathOnHost: "/dev/fuse", PathInContainer: "/dev/fuse", CgroupPermissions: "rwm"}},
+				},
+			},
+		},
+	}
+	hostConfig := execSessionHostConfig(target, api.RunOptions{})
+	assert.Equal(t, hostConfig.PidMode, container.PidMode("container:123"))
+	assert.DeepEqual(t, hostConfig.VolumesFrom, []string{"123"})
+	assert.DeepEqual(t, hostConfig.CapAdd, strslice.StrSlice{"NET_ADMIN"})
+	assert.DeepEqual(t, hostConfig.CapDrop, strslice.StrSlice{"MKNOD"})
+	assert.DeepEqual(t, hostConfig.SecurityOpt, []string{"seccomp=unconfined"})
+	assert.DeepEqual(t, hostConfig.Devices, target.HostConfig.Devices)
+	assert.Assert(t, !hostConfig.Privileged)
+}
+
+func TestExecSessionRequiresCommand(t *testing.T) {
+	tested := composeService{}
+	_, err := tested.runExecSession(context.TODO(), "demo", api.RunOptions{Service: "web", Session: "migration"})
+	assert.ErrorContains(t, err, "a command is required to run a session")
+}
+
+func TestSessionName(t *testing.T) {
+	assert.Assert(t, sessionName.MatchString("migration-2.1_a"))
+	assert.Assert(t, !sessionName.MatchString("-migration"))
+	assert.Assert(t, !sessionName.MatchString("db/migration"))
+}
+
+func TestAttachExecSessionNoTty(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	apiClient := mocks.NewMockAPIClient(mockCtrl)
+	cli := mocks.NewMockCli(mockCtrl)
+	var stdout, stderr bytes.Buffer
+	cli.EXPECT().Client().Return(apiClient).AnyTimes()
+	cli.EXPECT().Out().Return(streams.NewOut(&stdout)).AnyTimes()
+	cli.EXPECT().Err().Return(streams.NewOut(&stderr)).AnyTimes()
+	tested := composeService{dockerCli: cli}
+
+	var logs bytes.Buffer
+	_, _ = stdcopy.NewStdWriter(&logs, stdcopy.Stdout).Write([]byte("migrated\n"))
+	_, _ = stdcopy.NewStdWriter(&logs, stdcopy.Stderr).Write([]byte("1 warning\n"))
+
+	// the container is started without `docker start --attach`, which would forward signals to it
+	apiClient.EXPECT().ContainerStart(gomock.Any(), "123", container.StartOptions{}).Return(nil)
+	apiClient.EXPECT().ContainerWait(gomock.Any(), "123", gomock.Any()).Return(nil, nil)
+	apiClient.EXPECT().ContainerInspect(gomock.Any(), "123").Return(container.InspectResponse{
+		ContainerJSONBase: &container.ContainerJSONBase{ID: "123", State: &container.State{ExitCode: 3}},
+		Config:            &container.Config{OpenStdin: true},
+	}, nil).AnyTimes()
+	// the command exited before the client attached, its output is replayed
+	apiClient.EXPECT().ContainerLogs(gomock.Any(), "123", gomock.Any()).Return(io.NopCloser(&logs), nil)
+	apiClient.EXPECT().ContainerRemove(gomock.Any(), "123", container.RemoveOptions{Force: true}).Return(nil)
+
+	exitCode, err := tested.attachExecSession(context.TODO(), "123", "migration", true, true)
+	assert.NilError(t, err)
+	assert.Equal(t, exitCode, 3)
+	assert.Equal(t, stdout.String(), "migrated\n")
+	assert.Equal(t, stderr.String(), "1 warning\n")
+}
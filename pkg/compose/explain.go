@@ -0,0 +1,98 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/compose/v2/pkg/api"
+	containerType "github.com/docker/docker/api/types/container"
+)
+
+// recreateReasons explains why mustRecreate selected the actual container to be recreated
+func (c *convergence) recreateReasons(expected types.ServiceConfig, actual containerType.Summary, policy string) []string {
+	if policy == api.RecreateForce {
+		return []string{"recreate is forced"}
+	}
+	var reasons []string
+	configHash, err := ServiceHash(expected)
+	if err == nil && actual.Labels[api.ConfigHashLabel] != configHash {
+		reasons = append(reasons, configChanges(expected, configHash, actual))
+	}
+	if digest := expected.CustomLabels[api.ImageDigestLabel]; actual.Labels[api.ImageDigestLabel] != digest {
+		reasons = append(reasons, fmt.Sprintf("image changed from %s to %s", shortDigest(actual.Labels[api.ImageDigestLabel]), shortDigest(digest)))
+	}
+	if c.networks != nil && actual.State == "running" && checkExpectedNetworks(expected, actual, c.networks) {
+		reasons = append(reasons, "not connected to the expected networks")
+	}
+	if c.volumes != nil && checkExpectedVolumes(expected, actual, c.volumes) {
+		reasons = append(reasons, "not using the expected volumes")
+	}
+	return reasons
+}
+
+// configChanges lists service attributes which changed since actual container was created
+func configChanges(expected types.ServiceConfig, configHash string, actual containerType.Summary) string {
+	var previous map[string]string
+	if err := json.Unmarshal([]byte(actual.Labels[api.ConfigFieldsHashLabel]), &previous); err != nil {
+		return fmt.Sprintf("config hash changed from %s to %s, container was created without per-attribute hashes",
+			shortDigest(actual.Labels[api.ConfigHashLabel]), shortDigest(configHash))
+	}
+	current, err := ServiceFieldHashes(expected)
+	if err != nil {
+		return "config hash changed"
+	}
+	return "config changed: " + strings.Join(changedFields(previous, current), ", ")
+}
+
+func changedFields(previous, current map[string]string) []string {
+	var changed []string
+	for name, hash := range current {
+		old, ok := previous[name]
+		switch {
+		case !ok:
+			changed = append(changed, name+" (added)")
+		case old != hash:
+			changed = append(changed, name)
+		}
+	}
+	for name := range previous {
+		if _, ok := current[name]; !ok {
+			changed = append(changed, name+" (removed)")
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+func shortDigest(digest string) string {
+	if digest == "" {
+		return "<none>"
+	}
+	_, hex, ok := strings.Cut(digest, ":")
+	if !ok {
+		hex = digest
+	}
+	if len(hex) > 12 {
+		hex = hex[:12]
+	}
+	return hex
+}
@@ -0,0 +1,109 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/compose/v2/pkg/progress"
+	"github.com/docker/docker/api/types/network"
+	"gopkg.in/yaml.v3"
+)
+
+// createIfMissingExtension lets Compose create an external network when it doesn't exist, with the options set by
+// the extension. The network is shared with other projects, so Compose never removes it
+const createIfMissingExtension = "x-create-if-missing"
+
+// externalNetworkOptions are the options an external network is created with
+type externalNetworkOptions struct {
+	Driver     string           `yaml:"driver"`
+	DriverOpts types.Options    `yaml:"driver_opts"`
+	Ipam       types.IPAMConfig `yaml:"ipam"`
+	Internal   bool             `yaml:"internal"`
+	Attachable bool             `yaml:"attachable"`
+	EnableIPv4 *bool            `yaml:"enable_ipv4"`
+	EnableIPv6 *bool            `yaml:"enable_ipv6"`
+	Labels     types.Labels     `yaml:"labels"`
+}
+
+// createIfMissing returns the options to create an external network with, if enabled
+func createIfMissing(n *types.NetworkConfig) (*externalNetworkOptions, error) {
+	v, ok := n.Extensions[createIfMissingExtension]
+	if !ok {
+		return nil, nil
+	}
+	switch v := v.(type) {
+	case bool:
+		if !v {
+			return nil, nil
+		}
+		return &externalNetworkOptions{}, nil
+	case map[string]any:
+		b, err := yaml.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		var options externalNetworkOptions
+		decoder := yaml.NewDecoder(bytes.NewReader(b))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&options); err != nil {
+			return nil, fmt.Errorf("network %s: invalid %s: %w", n.Name, createIfMissingExtension, err)
+		}
+		return &options, nil
+	default:
+		return nil, fmt.Errorf("network %s: %s must be a boolean or a mapping", n.Name, createIfMissingExtension)
+	}
+}
+
+func (s *composeService) createExternalNetwork(ctx context.Context, n *types.NetworkConfig, options *externalNetworkOptions) (string, error) {
+	createOpts := network.CreateOptions{
+		Labels:     options.Labels,
+		Driver:     options.Driver,
+		Options:    options.DriverOpts,
+		Internal:   options.Internal,
+		Attachable: options.Attachable,
+		EnableIPv4: options.EnableIPv4,
+		EnableIPv6: options.EnableIPv6,
+	}
+	if options.Ipam.Driver != "" || len(options.Ipam.Config) > 0 {
+		createOpts.IPAM = &network.IPAM{
+			Driver: options.Ipam.Driver,
+		}
+		for _, pool := range options.Ipam.Config {
+			createOpts.IPAM.Config = append(createOpts.IPAM.Config, network.IPAMConfig{
+				Subnet:     pool.Subnet,
+				IPRange:    pool.IPRange,
+				Gateway:    pool.Gateway,
+				AuxAddress: pool.AuxiliaryAddresses,
+			})
+		}
+	}
+
+	networkEventName := fmt.Sprintf("Network %s", n.Name)
+	w := progress.ContextWriter(ctx)
+	w.Event(progress.CreatingEvent(networkEventName))
+	resp, err := s.apiClient().NetworkCreate(ctx, n.Name, createOpts)
+	if err != nil {
+		w.Event(progress.ErrorEvent(networkEventName))
+		return "", fmt.Errorf("failed to create network %s: %w", n.Name, err)
+	}
+	w.Event(progress.CreatedEvent(networkEventName))
+	return resp.ID, nil
+}
@@ -0,0 +1,77 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/errdefs"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+)
+
+func TestCreateIfMissing(t *testing.T) {
+	options, err := createIfMissing(&types.NetworkConfig{Name: "shared"})
+	assert.NilError(t, err)
+	assert.Assert(t, options == nil)
+
+	options, err = createIfMissing(&types.NetworkConfig{
+		Name: "shared",
+		Extensions: types.Extensions{createIfMissingExtension: map[string]any{
+			"driver":     "bridge",
+			"attachable": true,
+			"ipam": map[string]any{
+				"config": []any{map[string]any{"subnet": "172.28.0.0/16"}},
+			},
+		}},
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, options.Driver, "bridge")
+	assert.Equal(t, options.Attachable, true)
+	assert.Equal(t, options.Ipam.Config[0].Subnet, "172.28.0.0/16")
+
+	_, err = createIfMissing(&types.NetworkConfig{
+		Name:       "shared",
+		Extensions: types.Extensions{createIfMissingExtension: map[string]any{"drvier": "bridge"}},
+	})
+	assert.ErrorContains(t, err, "network shared: invalid x-create-if-missing")
+}
+
+func TestEnsureExternalNetworkCreateIfMissing(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	api, cli := prepareMocks(mockCtrl)
+	tested := composeService{
+		dockerCli: cli,
+	}
+	n := &types.NetworkConfig{
+		Name:       "shared",
+		External:   true,
+		Extensions: types.Extensions{createIfMissingExtension: map[string]any{"driver": "bridge"}},
+	}
+
+	api.EXPECT().NetworkList(gomock.Any(), gomock.Any()).Return(nil, nil)
+	api.EXPECT().NetworkInspect(gomock.Any(), "shared", gomock.Any()).Return(network.Inspect{}, errdefs.NotFound(nil))
+	api.EXPECT().NetworkCreate(gomock.Any(), "shared", network.CreateOptions{Driver: "bridge"}).
+		Return(network.CreateResponse{ID: "abc123"}, nil)
+
+	id, err := tested.ensureNetwork(context.Background(), &types.Project{Name: "test"}, "shared", n)
+	assert.NilError(t, err)
+	assert.Equal(t, id, "abc123")
+}
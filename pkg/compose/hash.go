@@ -18,13 +18,133 @@ package compose
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
 
 	"github.com/compose-spec/compose-go/v2/types"
 	"github.com/opencontainers/go-digest"
+	"github.com/sirupsen/logrus"
 )
 
 // ServiceHash computes the configuration hash for a service.
 func ServiceHash(o types.ServiceConfig) (string, error) {
+	bytes, err := json.Marshal(hashedService(o))
+	if err != nil {
+		return "", err
+	}
+	return digest.SHA256.FromBytes(bytes).Encoded(), nil
+}
+
+// ServiceFieldHashes computes a short hash for each attribute of the service configuration involved in ServiceHash,
+// so a configuration change can be explained by the attributes which changed.
+func ServiceFieldHashes(o types.ServiceConfig) (map[string]string, error) {
+	bytes, err := json.Marshal(hashedService(o))
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(bytes, &fields); err != nil {
+		return nil, err
+	}
+	hashes := make(map[string]string, len(fields))
+	for name, value := range fields {
+		hashes[name] = digest.SHA256.FromBytes(value).Encoded()[:12]
+	}
+	return hashes, nil
+}
+
+// ServiceContentHash computes a hash of the local content a service relies on but ServiceHash doesn't cover: bind mount
+// sources, and configs and secrets defined by a file, inline content or an environment variable.
+func ServiceContentHash(project *types.Project, service types.ServiceConfig) (string, error) {
+	digester := digest.SHA256.Digester()
+	w := digester.Hash()
+	for _, v := range service.Volumes {
+		if v.Type != types.VolumeTypeBind {
+			continue
+		}
+		if err := hashPath(w, v.Source); err != nil {
+			return "", err
+		}
+	}
+	objects := make([]types.FileObjectConfig, 0, len(service.Configs)+len(service.Secrets))
+	for _, c := range service.Configs {
+		objects = append(objects, types.FileObjectConfig(project.Configs[c.Source]))
+	}
+	for _, s := range service.Secrets {
+		objects = append(objects, types.FileObjectConfig(project.Secrets[s.Source]))
+	}
+	for _, o := range objects {
+		switch {
+		case o.Content != "":
+			_, _ = fmt.Fprintf(w, "content %s\n", o.Content)
+		case o.Environment != "":
+			_, _ = fmt.Fprintf(w, "environment %s=%s\n", o.Environment, project.Environment[o.Environment])
+		case o.File != "":
+			if err := hashPath(w, o.File); err != nil {
+				return "", err
+			}
+		}
+	}
+	return digester.Digest().Encoded(), nil
+}
+
+// hashPath writes the path relative to root, mode and content of root and the files it contains to w, so that the hash
+// doesn't depend on the project location. A missing path is recorded as such, as the engine creates missing bind mount
+// sources, and so are paths which can't be read, which are skipped with a warning.
+func hashPath(w io.Writer, root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		rel, relErr := filepath.Rel(root, path)
+		if relErr != nil {
+			return relErr
+		}
+		rel = filepath.ToSlash(rel)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			_, _ = fmt.Fprintf(w, "%s missing\n", rel)
+			return nil
+		case errors.Is(err, fs.ErrPermission):
+			logrus.Warnf("%s can't be read, changes to its content won't be detected", path)
+			_, _ = fmt.Fprintf(w, "%s unreadable\n", rel)
+			return nil
+		case err != nil:
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(w, "%s %s\n", rel, info.Mode())
+		switch {
+		case info.Mode()&fs.ModeSymlink != 0:
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(w, "-> %s\n", target)
+		case info.Mode().IsRegular():
+			f, err := os.Open(path)
+			if errors.Is(err, fs.ErrPermission) {
+				logrus.Warnf("%s can't be read, changes to its content won't be detected", path)
+				_, _ = fmt.Fprintln(w, "unreadable")
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			defer f.Close() //nolint:errcheck
+			if _, err := io.Copy(w, f); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func hashedService(o types.ServiceConfig) types.ServiceConfig {
 	// remove the Build config when generating the service hash
 	o.Build = nil
 	o.PullPolicy = ""
@@ -34,12 +154,7 @@ func ServiceHash(o types.ServiceConfig) (string, error) {
 	}
 	o.DependsOn = nil
 	o.Profiles = nil
-
-	bytes, err := json.Marshal(o)
-	if err != nil {
-		return "", err
-	}
-	return digest.SHA256.FromBytes(bytes).Encoded(), nil
+	return o
 }
 
 // NetworkHash computes the configuration hash for a network.
@@ -17,6 +17,9 @@
 package compose
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/compose-spec/compose-go/v2/types"
@@ -41,3 +44,83 @@ func serviceConfig(replicas int) types.ServiceConfig {
 		Image: "bar",
 	}
 }
+
+func TestServiceFieldHashes(t *testing.T) {
+	previous, err := ServiceFieldHashes(types.ServiceConfig{
+		Name:        "db",
+		Image:       "postgres:16",
+		Environment: types.NewMappingWithEquals([]string{"POSTGRES_DB=app"}),
+		User:        "postgres",
+	})
+	assert.NilError(t, err)
+	current, err := ServiceFieldHashes(types.ServiceConfig{
+		Name:        "db",
+		Image:       "postgres:16",
+		Environment: types.NewMappingWithEquals([]string{"POSTGRES_DB=other"}),
+		WorkingDir:  "/data",
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, changedFields(previous, current), []string{"environment", "user (removed)", "working_dir (added)"})
+}
+
+func TestServiceContentHash(t *testing.T) {
+	dir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "app.conf"), []byte("debug: false"), 0o600))
+	assert.NilError(t, os.Mkdir(filepath.Join(dir, "static"), 0o700))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "static", "index.html"), []byte("<html/>"), 0o600))
+
+	project := &types.Project{
+		Configs: types.Configs{
+			"app": {File: filepath.Join(dir, "app.conf")},
+		},
+		Secrets: types.Secrets{
+			"token": {Environment: "TOKEN"},
+		},
+		Environment: types.Mapping{"TOKEN": "s3cr3t"},
+	}
+	service := types.ServiceConfig{
+		Name: "web",
+		Volumes: []types.ServiceVolumeConfig{
+			{Type: types.VolumeTypeBind, Source: filepath.Join(dir, "static"), Target: "/srv"},
+			{Type: types.VolumeTypeBind, Source: filepath.Join(dir, "missing"), Target: "/data"},
+			{Type: types.VolumeTypeVolume, Source: "cache", Target: "/cache"},
+		},
+		Configs: []types.ServiceConfigObjConfig{{Source: "app"}},
+		Secrets: []types.ServiceSecretConfig{{Source: "token"}},
+	}
+
+	hash1, err := ServiceContentHash(project, service)
+	assert.NilError(t, err)
+	hash2, err := ServiceContentHash(project, service)
+	assert.NilError(t, err)
+	assert.Equal(t, hash1, hash2)
+
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "static", "index.html"), []byte("<html></html>"), 0o600))
+	hash3, err := ServiceContentHash(project, service)
+	assert.NilError(t, err)
+	assert.Assert(t, hash3 != hash1)
+
+	project.Environment["TOKEN"] = "rotated"
+	hash4, err := ServiceContentHash(project, service)
+	assert.NilError(t, err)
+	assert.Assert(t, hash4 != hash3)
+}
+
+func TestHashPathRelative(t *testing.T) {
+	hash := func(root string) string {
+		var b strings.Builder
+		assert.NilError(t, hashPath(&b, root))
+		return b.String()
+	}
+	first, second := t.TempDir(), t.TempDir()
+	for _, dir := range []string{first, second} {
+		assert.NilError(t, os.WriteFile(filepath.Join(dir, "index.html"), []byte("<html/>"), 0o600))
+	}
+	assert.Equal(t, hash(first), hash(second))
+
+	if os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced for root")
+	}
+	assert.NilError(t, os.Chmod(filepath.Join(second, "index.html"), 0o000))
+	assert.Check(t, strings.Contains(hash(second), "unreadable"))
+}
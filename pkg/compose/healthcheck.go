@@ -0,0 +1,125 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/docker/api/types/container"
+)
+
+const (
+	// healthcheckOverrideExtension sets how a service healthcheck applies to the image HEALTHCHECK
+	healthcheckOverrideExtension = "x-override"
+	// healthcheckMerge makes attributes not set by service inherit from image HEALTHCHECK
+	healthcheckMerge = "merge"
+	// healthcheckReplace ignores image HEALTHCHECK, attributes not set by service get engine defaults
+	healthcheckReplace = "replace"
+	// healthcheckDisableIfUnset disables image HEALTHCHECK unless service sets a test
+	healthcheckDisableIfUnset = "disable-if-unset"
+)
+
+// engine defaults, applied when a healthcheck is set without inheriting attributes from image
+const (
+	defaultHealthInterval = 30 * time.Second
+	defaultHealthTimeout  = 30 * time.Second
+	defaultHealthRetries  = 3
+	// engine replaces a zero start period by the image one, this is the lowest value it accepts
+	minimalHealthStartPeriod = time.Millisecond
+)
+
+// serviceHealthCheck returns the healthcheck to create service containers with, applying x-override against the
+// service image HEALTHCHECK
+func (s *composeService) serviceHealthCheck(ctx context.Context, project *types.Project, service types.ServiceConfig) (*container.HealthConfig, error) {
+	check, err := s.ToMobyHealthCheck(ctx, service.HealthCheck)
+	if err != nil || service.HealthCheck == nil {
+		return check, err
+	}
+	mode, ok := service.HealthCheck.Extensions[healthcheckOverrideExtension]
+	if !ok {
+		return check, nil
+	}
+	inspect, err := s.apiClient().ImageInspect(ctx, api.GetImageNameOrDefault(service, project.Name))
+	if err != nil {
+		return nil, err
+	}
+	var image *container.HealthConfig
+	if inspect.Config != nil {
+		image = inspect.Config.Healthcheck
+	}
+	return overrideHealthCheck(fmt.Sprint(mode), check, image)
+}
+
+// overrideHealthCheck resolves check against image healthcheck according to mode
+func overrideHealthCheck(mode string, check, image *container.HealthConfig) (*container.HealthConfig, error) {
+	if image == nil {
+		image = &container.HealthConfig{}
+	}
+	resolved := *check
+	switch mode {
+	case healthcheckMerge:
+		if len(resolved.Test) == 0 {
+			resolved.Test = image.Test
+		}
+		if resolved.Interval == 0 {
+			resolved.Interval = image.Interval
+		}
+		if resolved.Timeout == 0 {
+			resolved.Timeout = image.Timeout
+		}
+		if resolved.StartPeriod == 0 {
+			resolved.StartPeriod = image.StartPeriod
+		}
+		if resolved.StartInterval == 0 {
+			resolved.StartInterval = image.StartInterval
+		}
+		if resolved.Retries == 0 {
+			resolved.Retries = image.Retries
+		}
+	case healthcheckReplace:
+		if len(resolved.Test) == 0 {
+			return nil, fmt.Errorf("healthcheck.test is required with %s: %s", healthcheckOverrideExtension, mode)
+		}
+		if resolved.Interval == 0 {
+			resolved.Interval = defaultHealthInterval
+		}
+		if resolved.Timeout == 0 {
+			resolved.Timeout = defaultHealthTimeout
+		}
+		if resolved.Retries == 0 {
+			resolved.Retries = defaultHealthRetries
+		}
+		if resolved.StartPeriod == 0 && image.StartPeriod != 0 {
+			resolved.StartPeriod = minimalHealthStartPeriod
+		}
+		if resolved.StartInterval == 0 && image.StartInterval != 0 {
+			resolved.StartInterval = resolved.Interval
+		}
+	case healthcheckDisableIfUnset:
+		if len(resolved.Test) == 0 {
+			return &container.HealthConfig{Test: []string{"NONE"}}, nil
+		}
+	default:
+		return nil, fmt.Errorf("invalid healthcheck %s %q, expected %s, %s or %s", healthcheckOverrideExtension, mode,
+			healthcheckMerge, healthcheckReplace, healthcheckDisableIfUnset)
+	}
+	return &resolved, nil
+}
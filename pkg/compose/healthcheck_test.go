@@ -0,0 +1,69 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"gotest.tools/v3/assert"
+)
+
+func TestOverrideHealthCheck(t *testing.T) {
+	image := &container.HealthConfig{
+		Test:        []string{"CMD", "healthy"},
+		Interval:    time.Minute,
+		Timeout:     10 * time.Second,
+		StartPeriod: 5 * time.Second,
+		Retries:     5,
+	}
+
+	merged, err := overrideHealthCheck(healthcheckMerge, &container.HealthConfig{Interval: time.Second}, image)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, merged, &container.HealthConfig{
+		Test:        []string{"CMD", "healthy"},
+		Interval:    time.Second,
+		Timeout:     10 * time.Second,
+		StartPeriod: 5 * time.Second,
+		Retries:     5,
+	})
+
+	replaced, err := overrideHealthCheck(healthcheckReplace, &container.HealthConfig{Test: []string{"CMD", "check"}}, image)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, replaced, &container.HealthConfig{
+		Test:        []string{"CMD", "check"},
+		Interval:    defaultHealthInterval,
+		Timeout:     defaultHealthTimeout,
+		StartPeriod: minimalHealthStartPeriod,
+		Retries:     defaultHealthRetries,
+	})
+
+	_, err = overrideHealthCheck(healthcheckReplace, &container.HealthConfig{}, image)
+	assert.ErrorContains(t, err, "healthcheck.test is required")
+
+	disabled, err := overrideHealthCheck(healthcheckDisableIfUnset, &container.HealthConfig{Interval: time.Second}, image)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, disabled, &container.HealthConfig{Test: []string{"NONE"}})
+
+	kept, err := overrideHealthCheck(healthcheckDisableIfUnset, &container.HealthConfig{Test: []string{"CMD", "check"}}, nil)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, kept, &container.HealthConfig{Test: []string{"CMD", "check"}})
+
+	_, err = overrideHealthCheck("unknown", &container.HealthConfig{}, image)
+	assert.ErrorContains(t, err, `invalid healthcheck x-override "unknown"`)
+}
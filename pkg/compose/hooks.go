@@ -0,0 +1,249 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+
+	"github.com/docker/compose/v2/internal/features"
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/progress"
+)
+
+// projectHooksExtension declares commands Compose runs around project lifecycle events, either on the host or in a
+// service container, replacing the scripts commonly wrapped around compose commands
+const projectHooksExtension = "x-hooks"
+
+const (
+	hookPreUp     = "pre-up"
+	hookPostUp    = "post-up"
+	hookPreDown   = "pre-down"
+	hookPostDown  = "post-down"
+	hookOnFailure = "on-failure"
+)
+
+// projectHook is a command run on a lifecycle event, on the host unless Service is set
+type projectHook struct {
+	Command     []string
+	Service     string
+	User        string
+	WorkingDir  string
+	Environment []string
+}
+
+// projectHookConfig is the x-hooks entry a projectHook is parsed from
+type projectHookConfig struct {
+	Command     any    `yaml:"command"`
+	Service     string `yaml:"service"`
+	User        string `yaml:"user"`
+	WorkingDir  string `yaml:"working_dir"`
+	Environment any    `yaml:"environment"`
+}
+
+// getProjectHooks returns the hooks declared by project, indexed by event
+func getProjectHooks(project *types.Project) (map[string][]projectHook, error) {
+	if project == nil {
+		return nil, nil
+	}
+	v, ok := project.Extensions[projectHooksExtension]
+	if !ok {
+		return nil, nil
+	}
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var configs map[string][]projectHookConfig
+	decoder := yaml.NewDecoder(bytes.NewReader(b))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&configs); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", projectHooksExtension, err)
+	}
+
+	hooks := map[string][]projectHook{}
+	for event, entries := range configs {
+		switch event {
+		case hookPreUp, hookPostUp, hookPreDown, hookPostDown, hookOnFailure:
+		default:
+			return nil, fmt.Errorf("invalid %s: unsupported event %q", projectHooksExtension, event)
+		}
+		for _, c := range entries {
+			hook := projectHook{
+				Service:     c.Service,
+				User:        c.User,
+				WorkingDir:  c.WorkingDir,
+				Environment: extensionEnvironment(c.Environment),
+			}
+			if c.Service == "" {
+				hook.Command = extensionCommand(c.Command, shellCommand)
+				if c.User != "" {
+					return nil, fmt.Errorf("invalid %s: %s hook sets user but runs on the host", projectHooksExtension, event)
+				}
+				if hook.WorkingDir == "" {
+					hook.WorkingDir = project.WorkingDir
+				} else if !filepath.IsAbs(hook.WorkingDir) {
+					hook.WorkingDir = filepath.Join(project.WorkingDir, hook.WorkingDir)
+				}
+			} else {
+				hook.Command = extensionCommand(c.Command, func(command string) []string {
+					return []string{"/bin/sh", "-c", command}
+				})
+				if _, ok := project.Services[c.Service]; !ok {
+					return nil, fmt.Errorf("invalid %s: %s hook runs in undefined service %q", projectHooksExtension, event, c.Service)
+				}
+			}
+			if len(hook.Command) == 0 {
+				return nil, fmt.Errorf("invalid %s: %s hook command is required", projectHooksExtension, event)
+			}
+			hooks[event] = append(hooks[event], hook)
+		}
+	}
+	return hooks, checkHostHooksEnabled(project, hooks)
+}
+
+// checkHostHooksEnabled refuses hooks running on the host unless enabled, as they let a Compose file run arbitrary
+// commands on the machine. The feature is resolved from the process environment only, as the project environment
+// includes its .env file, which would let a project enable it for itself
+func checkHostHooksEnabled(project *types.Project, hooks map[string][]projectHook) error {
+	for _, entries := range hooks {
+		if !slices.ContainsFunc(entries, func(hook projectHook) bool { return hook.Service == "" }) {
+			continue
+		}
+		enabled, err := features.NewFlags(nil).Enabled(features.ProjectHooks)
+		if err != nil || enabled {
+			return err
+		}
+		return fmt.Errorf("%s running on the host require feature %s, set %s=%s to enable it", projectHooksExtension,
+			features.ProjectHooks.Name, features.EnvFeatures, features.ProjectHooks.Name)
+	}
+	return nil
+}
+
+// hookEnvironment exposes project metadata to hooks run on event
+func hookEnvironment(project *types.Project, event string, cause error) []string {
+	env := []string{
+		"COMPOSE_HOOK=" + event,
+		"COMPOSE_PROJECT_NAME=" + project.Name,
+		"COMPOSE_PROJECT_DIRECTORY=" + project.WorkingDir,
+		"COMPOSE_FILE=" + strings.Join(project.ComposeFiles, string(os.PathListSeparator)),
+	}
+	if cause != nil {
+		env = append(env, "COMPOSE_HOOK_ERROR="+cause.Error())
+	}
+	return env
+}
+
+// runProjectHooks runs hooks declared for event in order, and stops on the first one failing. cause is the error
+// on-failure hooks are run for
+func (s *composeService) runProjectHooks(ctx context.Context, project *types.Project, hooks map[string][]projectHook, event string, cause error) error {
+	w := progress.ContextWriter(ctx)
+	for i, hook := range hooks[event] {
+		eventName := fmt.Sprintf("Hook %s %d", event, i+1)
+		w.Event(progress.NewEvent(eventName, progress.Working, "Running"))
+		if s.dryRun {
+			w.Event(progress.NewEvent(eventName, progress.Done, "Done"))
+			continue
+		}
+		env := append(hookEnvironment(project, event, cause), hook.Environment...)
+		var err error
+		if hook.Service != "" {
+			err = s.runServiceHook(ctx, project, hook, env)
+		} else {
+			err = runHostHook(ctx, hook, env)
+		}
+		if err != nil {
+			w.Event(progress.ErrorMessageEvent(eventName, "Error"))
+			return fmt.Errorf("%s hook: %w", event, err)
+		}
+		w.Event(progress.NewEvent(eventName, progress.Done, "Done"))
+	}
+	return nil
+}
+
+// runHostHook runs hook on the host, output is reported on failure and logged at debug level otherwise
+func runHostHook(ctx context.Context, hook projectHook, env []string) error {
+	cmd := exec.CommandContext(ctx, hook.Command[0], hook.Command[1:]...)
+	cmd.Dir = hook.WorkingDir
+	cmd.Env = append(os.Environ(), env...)
+	out, err := cmd.CombinedOutput()
+	output := strings.TrimSpace(string(out))
+	if err != nil {
+		if output == "" {
+			return fmt.Errorf("%s: %w", strings.Join(hook.Command, " "), err)
+		}
+		return fmt.Errorf("%s: %w\n%s", strings.Join(hook.Command, " "), err, output)
+	}
+	if output != "" {
+		logrus.Debugf("%s: %s", strings.Join(hook.Command, " "), output)
+	}
+	return nil
+}
+
+// runServiceHook runs hook in the first running container of its service
+func (s *composeService) runServiceHook(ctx context.Context, project *types.Project, hook projectHook, env []string) error {
+	containers, err := s.getContainers(ctx, project.Name, oneOffExclude, false, hook.Service)
+	if err != nil {
+		return err
+	}
+	if len(containers) == 0 {
+		return fmt.Errorf("service %q has no running container", hook.Service)
+	}
+	var output []string
+	err = s.runHook(ctx, containers[0], project.Services[hook.Service], types.ServiceHook{
+		Command:     hook.Command,
+		User:        hook.User,
+		WorkingDir:  hook.WorkingDir,
+		Environment: types.NewMappingWithEquals(env),
+	}, func(event api.ContainerEvent) {
+		output = append(output, event.Line)
+	})
+	if err != nil {
+		if len(output) == 0 {
+			return err
+		}
+		return fmt.Errorf("%w\n%s", err, strings.Join(output, "\n"))
+	}
+	if len(output) > 0 {
+		logrus.Debugf("%s: %s", hook.Service, strings.Join(output, "\n"))
+	}
+	return nil
+}
+
+// runFailureHooks runs on-failure hooks after command failed with cause. Their own failure is only reported, as
+// cause is the error which matters
+func (s *composeService) runFailureHooks(ctx context.Context, project *types.Project, hooks map[string][]projectHook, cause error) {
+	if len(hooks[hookOnFailure]) == 0 {
+		return
+	}
+	err := progress.Run(ctx, func(ctx context.Context) error {
+		return s.runProjectHooks(ctx, project, hooks, hookOnFailure, cause)
+	}, s.stdinfo())
+	if err != nil {
+		logrus.Warn(err)
+	}
+}
@@ -0,0 +1,107 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+
+	"github.com/docker/compose/v2/internal/features"
+)
+
+func TestGetProjectHooks(t *testing.T) {
+	t.Setenv(features.EnvFeatures, features.ProjectHooks.Name)
+	project := &types.Project{
+		Name:       "test",
+		WorkingDir: "/project",
+		Services:   types.Services{"db": {Name: "db"}},
+		Extensions: types.Extensions{projectHooksExtension: map[string]any{
+			"pre-up": []any{
+				map[string]any{"command": []any{"./check.sh", "--strict"}, "working_dir": "scripts"},
+			},
+			"post-up": []any{
+				map[string]any{"service": "db", "command": "psql -f /seed.sql", "user": "postgres", "environment": map[string]any{"PGDATABASE": "app"}},
+			},
+		}},
+	}
+	hooks, err := getProjectHooks(project)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, hooks, map[string][]projectHook{
+		hookPreUp: {{
+			Command:    []string{"./check.sh", "--strict"},
+			WorkingDir: filepath.Join("/project", "scripts"),
+		}},
+		hookPostUp: {{
+			Command:     []string{"/bin/sh", "-c", "psql -f /seed.sql"},
+			Service:     "db",
+			User:        "postgres",
+			Environment: []string{"PGDATABASE=app"},
+		}},
+	})
+
+	// the project environment, including its .env file, can't enable hooks running on the host
+	t.Setenv(features.EnvFeatures, "")
+	project.Environment = types.Mapping{features.EnvFeatures: features.ProjectHooks.Name}
+	_, err = getProjectHooks(project)
+	assert.ErrorContains(t, err, "x-hooks running on the host require feature hooks")
+
+	for _, invalid := range []map[string]any{
+		{"pre-start": []any{map[string]any{"command": "true"}}},
+		{"pre-up": []any{map[string]any{"service": "web", "command": "true"}}},
+		{"pre-up": []any{map[string]any{"user": "root", "command": "true"}}},
+		{"pre-up": []any{map[string]any{"service": "db"}}},
+	} {
+		project.Extensions[projectHooksExtension] = invalid
+		_, err = getProjectHooks(project)
+		assert.ErrorContains(t, err, "invalid x-hooks", "%v", invalid)
+	}
+}
+
+func TestRunProjectHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("relies on a posix shell")
+	}
+	dir := t.TempDir()
+	project := &types.Project{
+		Name:         "test",
+		WorkingDir:   dir,
+		ComposeFiles: []string{filepath.Join(dir, "compose.yaml")},
+	}
+	hooks := map[string][]projectHook{
+		hookOnFailure: {
+			{Command: shellCommand(`echo "$COMPOSE_HOOK $COMPOSE_PROJECT_NAME $COMPOSE_HOOK_ERROR $CUSTOM" > hook.log`), WorkingDir: dir, Environment: []string{"CUSTOM=1"}},
+			{Command: shellCommand("echo oops; exit 3"), WorkingDir: dir},
+			{Command: shellCommand("touch never"), WorkingDir: dir},
+		},
+	}
+	tested := composeService{}
+	err := tested.runProjectHooks(context.TODO(), project, hooks, hookOnFailure, errors.New("boom"))
+	assert.ErrorContains(t, err, "on-failure hook: /bin/sh -c echo oops; exit 3: exit status 3\noops")
+
+	b, err := os.ReadFile(filepath.Join(dir, "hook.log"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(b), "on-failure test boom 1\n")
+	_, err = os.Stat(filepath.Join(dir, "never"))
+	assert.Check(t, os.IsNotExist(err))
+}
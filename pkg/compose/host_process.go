@@ -0,0 +1,517 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/go-units"
+	"github.com/opencontainers/go-digest"
+	"gopkg.in/yaml.v3"
+
+	"github.com/docker/compose/v2/internal/features"
+	"github.com/docker/compose/v2/internal/locker"
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/progress"
+)
+
+// hostProcessExtension declares commands Compose runs on the host alongside the project containers, for a component
+// which can't run in a container yet. As services require an image, host processes are declared by a top-level
+// extension rather than as services
+const hostProcessExtension = "x-host-process"
+
+// hostProcessStopTimeout is the time a host process is given to exit after SIGTERM, before being killed
+const hostProcessStopTimeout = 10 * time.Second
+
+// hostProcess is a command Compose runs on the host
+type hostProcess struct {
+	Name        string   `json:"-"`
+	Command     []string `json:"command"`
+	WorkingDir  string   `json:"working_dir,omitempty"`
+	Environment []string `json:"environment,omitempty"`
+	DependsOn   []string `json:"depends_on,omitempty"`
+}
+
+// hostProcessConfig is the x-host-process entry a hostProcess is parsed from
+type hostProcessConfig struct {
+	Command     any      `yaml:"command"`
+	WorkingDir  string   `yaml:"working_dir"`
+	Environment any      `yaml:"environment"`
+	DependsOn   []string `yaml:"depends_on"`
+}
+
+// hostProcessState is recorded when a host process is started, so later commands can manage it
+type hostProcessState struct {
+	PID     int       `json:"pid"`
+	Hash    string    `json:"hash"`
+	Command []string  `json:"command"`
+	Started time.Time `json:"started"`
+	// ProcessStart is the start time of the process as reported by the OS, telling it apart from a process which
+	// got the same pid after it exited or the host rebooted
+	ProcessStart string `json:"process_start,omitempty"`
+}
+
+// running returns true if the recorded process is still running
+func (state hostProcessState) running() bool {
+	if state.PID <= 0 || state.ProcessStart == "" || !hostProcessAlive(state.PID) {
+		return false
+	}
+	start, err := hostProcessStartTime(state.PID)
+	return err == nil && start == state.ProcessStart
+}
+
+// getHostProcesses returns the host processes declared by project, sorted by name
+func getHostProcesses(project *types.Project) ([]hostProcess, error) {
+	v, ok := project.Extensions[hostProcessExtension]
+	if !ok {
+		return nil, nil
+	}
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var configs map[string]hostProcessConfig
+	decoder := yaml.NewDecoder(bytes.NewReader(b))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&configs); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", hostProcessExtension, err)
+	}
+
+	processes := make([]hostProcess, 0, len(configs))
+	for name, c := range configs {
+		if _, ok := project.Services[name]; ok {
+			return nil, fmt.Errorf("%s %q conflicts with service of the same name", hostProcessExtension, name)
+		}
+		p := hostProcess{
+			Name:       name,
+			WorkingDir: project.WorkingDir,
+			DependsOn:  c.DependsOn,
+		}
+		p.Command = extensionCommand(c.Command, shellCommand)
+		if len(p.Command) == 0 {
+			return nil, fmt.Errorf("%s %q: command is required", hostProcessExtension, name)
+		}
+		if c.WorkingDir != "" {
+			p.WorkingDir = c.WorkingDir
+			if !filepath.IsAbs(p.WorkingDir) {
+				p.WorkingDir = filepath.Join(project.WorkingDir, p.WorkingDir)
+			}
+		}
+		p.Environment = extensionEnvironment(c.Environment)
+		for _, dep := range p.DependsOn {
+			if _, ok := project.Services[dep]; !ok {
+				return nil, fmt.Errorf("%s %q depends on undefined service %q", hostProcessExtension, name, dep)
+			}
+		}
+		processes = append(processes, p)
+	}
+	sort.Slice(processes, func(i, j int) bool {
+		return processes[i].Name < processes[j].Name
+	})
+	return processes, nil
+}
+
+// extensionCommand parses a command declared by an extension, either as a list of arguments or as a string run by
+// shell
+func extensionCommand(v any, shell func(string) []string) []string {
+	var command []string
+	switch cmd := v.(type) {
+	case string:
+		command = shell(cmd)
+	case []any:
+		for _, arg := range cmd {
+			command = append(command, fmt.Sprint(arg))
+		}
+	}
+	return command
+}
+
+// extensionEnvironment parses environment variables declared by an extension, either as a mapping or as a list of
+// KEY=VALUE entries
+func extensionEnvironment(v any) []string {
+	var environment []string
+	switch env := v.(type) {
+	case map[string]any:
+		for k, v := range env {
+			environment = append(environment, fmt.Sprintf("%s=%v", k, v))
+		}
+		sort.Strings(environment)
+	case []any:
+		for _, e := range env {
+			environment = append(environment, fmt.Sprint(e))
+		}
+	}
+	return environment
+}
+
+func (p hostProcess) hash() (string, error) {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return "", err
+	}
+	return digest.SHA256.FromBytes(b).Encoded(), nil
+}
+
+func hostProcessEventName(name string) string {
+	return "Process " + name
+}
+
+// readHostProcesses returns the state of host processes started for project, indexed by name
+func readHostProcesses(projectName string) (map[string]hostProcessState, error) {
+	dir, err := locker.ProcessDir(projectName)
+	if err != nil {
+		return nil, err
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	states := map[string]hostProcessState{}
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		var state hostProcessState
+		if err := json.Unmarshal(b, &state); err != nil {
+			return nil, fmt.Errorf("reading host process state %s: %w", f, err)
+		}
+		states[strings.TrimSuffix(filepath.Base(f), ".json")] = state
+	}
+	return states, nil
+}
+
+// startHostProcesses starts the host processes declared by project once the services they depend on are running,
+// and restarts those whose configuration changed
+func (s *composeService) startHostProcesses(ctx context.Context, project *types.Project) error {
+	processes, err := getHostProcesses(project)
+	if err != nil || len(processes) == 0 {
+		return err
+	}
+	// resolved from the process environment only, so the project .env file can't enable the feature for itself
+	enabled, err := features.NewFlags(nil).Enabled(features.HostProcess)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return fmt.Errorf("%s requires feature %s, set %s=%s to enable it", hostProcessExtension,
+			features.HostProcess.Name, features.EnvFeatures, features.HostProcess.Name)
+	}
+	if s.dryRun {
+		return nil
+	}
+
+	dir, err := locker.ProcessDir(project.Name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	states, err := readHostProcesses(project.Name)
+	if err != nil {
+		return err
+	}
+	containers, err := s.getContainers(ctx, project.Name, oneOffExclude, true)
+	if err != nil {
+		return err
+	}
+
+	w := progress.ContextWriter(ctx)
+	for _, p := range processes {
+		eventName := hostProcessEventName(p.Name)
+		hash, err := p.hash()
+		if err != nil {
+			return err
+		}
+		if state, ok := states[p.Name]; ok && state.running() {
+			if state.Hash == hash {
+				w.Event(progress.RunningEvent(eventName))
+				continue
+			}
+			if err := stopHostProcess(ctx, dir, p.Name, state, hostProcessStopTimeout); err != nil {
+				return err
+			}
+		}
+
+		depends := types.DependsOnConfig{}
+		for _, dep := range p.DependsOn {
+			depends[dep] = types.ServiceDependency{Condition: ServiceConditionRunningOrHealthy, Required: true}
+		}
+		if err := s.waitDependencies(ctx, project, p.Name, depends, containers, 0); err != nil {
+			return err
+		}
+
+		w.Event(progress.StartingEvent(eventName))
+		state, err := runHostProcess(dir, p, hash)
+		if err != nil {
+			w.Event(progress.ErrorMessageEvent(eventName, err.Error()))
+			return fmt.Errorf("starting %s %q: %w", hostProcessExtension, p.Name, err)
+		}
+		if err := writeHostProcessState(dir, p.Name, state); err != nil {
+			return err
+		}
+		w.Event(progress.StartedEvent(eventName))
+	}
+	return nil
+}
+
+// runHostProcess starts p detached from compose, with output appended to its log file
+func runHostProcess(dir string, p hostProcess, hash string) (hostProcessState, error) {
+	logs, err := os.OpenFile(filepath.Join(dir, p.Name+".log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return hostProcessState{}, err
+	}
+	defer logs.Close() //nolint:errcheck
+
+	cmd := exec.Command(p.Command[0], p.Command[1:]...)
+	cmd.Dir = p.WorkingDir
+	cmd.Env = append(os.Environ(), p.Environment...)
+	cmd.Stdout = logs
+	cmd.Stderr = logs
+	cmd.SysProcAttr = hostProcessAttr()
+	if err := cmd.Start(); err != nil {
+		return hostProcessState{}, err
+	}
+	// read the start time before the process can be reaped, so it's available even if the process already exited
+	start, err := hostProcessStartTime(cmd.Process.Pid)
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return hostProcessState{}, fmt.Errorf("reading process start time: %w", err)
+	}
+	// reap the process if it exits while compose is still running
+	go cmd.Wait() //nolint:errcheck
+	return hostProcessState{
+		PID:          cmd.Process.Pid,
+		Hash:         hash,
+		Command:      p.Command,
+		Started:      time.Now(),
+		ProcessStart: start,
+	}, nil
+}
+
+// stopHostProcesses stops host processes started for project, and removes their state and logs if remove is set
+func (s *composeService) stopHostProcesses(ctx context.Context, projectName string, remove bool, timeout *time.Duration) error {
+	states, err := readHostProcesses(projectName)
+	if err != nil || len(states) == 0 {
+		return err
+	}
+	if s.dryRun {
+		return nil
+	}
+	dir, err := locker.ProcessDir(projectName)
+	if err != nil {
+		return err
+	}
+	stopTimeout := hostProcessStopTimeout
+	if timeout != nil {
+		stopTimeout = *timeout
+	}
+	w := progress.ContextWriter(ctx)
+	for _, name := range slices.Sorted(maps.Keys(states)) {
+		eventName := hostProcessEventName(name)
+		switch state := states[name]; {
+		case state.running():
+			w.Event(progress.StoppingEvent(eventName))
+			if err := stopHostProcess(ctx, dir, name, state, stopTimeout); err != nil {
+				return err
+			}
+			w.Event(progress.StoppedEvent(eventName))
+		case state.PID > 0:
+			// the process exited, and its pid may now belong to an unrelated process
+			state.PID = 0
+			if err := writeHostProcessState(dir, name, state); err != nil {
+				return err
+			}
+		}
+		if remove {
+			for _, ext := range []string{".json", ".log"} {
+				if err := os.Remove(filepath.Join(dir, name+ext)); err != nil && !errors.Is(err, os.ErrNotExist) {
+					return err
+				}
+			}
+			w.Event(progress.RemovedEvent(eventName))
+		}
+	}
+	return nil
+}
+
+// stopHostProcess sends SIGTERM to a running host process, then kills it if it doesn't exit within timeout
+func stopHostProcess(ctx context.Context, dir string, name string, state hostProcessState, timeout time.Duration) error {
+	if err := signalHostProcess(state.PID, false); err != nil {
+		return fmt.Errorf("stopping %s %q: %w", hostProcessExtension, name, err)
+	}
+	deadline := time.Now().Add(timeout)
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for state.running() {
+		if time.Now().After(deadline) {
+			if err := signalHostProcess(state.PID, true); err != nil {
+				return fmt.Errorf("killing %s %q: %w", hostProcessExtension, name, err)
+			}
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	state.PID = 0
+	return writeHostProcessState(dir, name, state)
+}
+
+// writeHostProcessState records the state of the named host process
+func writeHostProcessState(dir string, name string, state hostProcessState) error {
+	b, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, name+".json"), b, 0o600)
+}
+
+// hostProcessSummaries lists host processes started for project in the same format as containers
+func hostProcessSummaries(projectName string) ([]api.ContainerSummary, error) {
+	states, err := readHostProcesses(projectName)
+	if err != nil {
+		return nil, err
+	}
+	summaries := make([]api.ContainerSummary, 0, len(states))
+	for _, name := range slices.Sorted(maps.Keys(states)) {
+		state := states[name]
+		summary := api.ContainerSummary{
+			Name:    name,
+			Names:   []string{name},
+			Command: strings.Join(state.Command, " "),
+			Project: projectName,
+			Service: name,
+			Created: state.Started.Unix(),
+			State:   "exited",
+			Status:  "Exited",
+			Labels:  map[string]string{api.ProjectLabel: projectName, api.ServiceLabel: name},
+		}
+		if state.running() {
+			summary.State = "running"
+			summary.Status = fmt.Sprintf("Up %s (pid %d)", units.HumanDuration(time.Since(state.Started)), state.PID)
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+// logHostProcesses sends the logs of host processes started for project to consumer, following them until ctx is done
+// if follow is set
+func (s *composeService) logHostProcesses(ctx context.Context, projectName string, consumer api.LogConsumer, tail string, follow bool) error {
+	states, err := readHostProcesses(projectName)
+	if err != nil || len(states) == 0 {
+		return err
+	}
+	dir, err := locker.ProcessDir(projectName)
+	if err != nil {
+		return err
+	}
+	lines := -1
+	if tail != "" && tail != "all" {
+		lines, err = strconv.Atoi(tail)
+		if err != nil {
+			return fmt.Errorf("invalid tail %q: %w", tail, err)
+		}
+	}
+	errs := make(chan error, len(states))
+	for name := range states {
+		consumer.Register(name)
+		go func() {
+			errs <- followHostProcessLog(ctx, filepath.Join(dir, name+".log"), name, consumer, lines, follow)
+		}()
+	}
+	for range states {
+		if err := <-errs; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// followHostProcessLog sends lines of a host process log file to consumer, only the last tail ones if tail isn't negative
+func followHostProcessLog(ctx context.Context, path string, name string, consumer api.LogConsumer, tail int, follow bool) error {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close() //nolint:errcheck
+
+	reader := bufio.NewReader(f)
+	var (
+		last    []string
+		pending string
+	)
+	for {
+		line, err := reader.ReadString('\n')
+		pending += line
+		if err == nil {
+			line, pending = strings.TrimSuffix(pending, "\n"), ""
+			if tail < 0 {
+				consumer.Log(name, line)
+				continue
+			}
+			last = append(last, line)
+			if len(last) > tail {
+				last = last[1:]
+			}
+			continue
+		}
+		if !errors.Is(err, io.EOF) {
+			return err
+		}
+		// once past the tail, all new lines are sent
+		for _, l := range last {
+			consumer.Log(name, l)
+		}
+		last, tail = nil, -1
+		if !follow {
+			if pending != "" {
+				consumer.Log(name, pending)
+			}
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+}
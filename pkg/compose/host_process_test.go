@@ -0,0 +1,178 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/docker/api/types/container"
+	"go.uber.org/mock/gomock"
+	"gopkg.in/yaml.v3"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/poll"
+)
+
+func TestGetHostProcesses(t *testing.T) {
+	project := &types.Project{
+		WorkingDir: "/src",
+		Services: types.Services{
+			"api": {Name: "api"},
+		},
+		Extensions: types.Extensions{
+			hostProcessExtension: map[string]any{
+				"frontend": map[string]any{
+					"command":     "npm run dev",
+					"working_dir": "./frontend",
+					"environment": map[string]any{"PORT": 3000, "API_URL": "http://localhost:8080"},
+					"depends_on":  []any{"api"},
+				},
+				"agent": map[string]any{
+					"command": []any{"agent", "--verbose"},
+				},
+			},
+		},
+	}
+	processes, err := getHostProcesses(project)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, processes, []hostProcess{
+		{
+			Name:       "agent",
+			Command:    []string{"agent", "--verbose"},
+			WorkingDir: "/src",
+		},
+		{
+			Name:        "frontend",
+			Command:     shellCommand("npm run dev"),
+			WorkingDir:  filepath.Join("/src", "frontend"),
+			Environment: []string{"API_URL=http://localhost:8080", "PORT=3000"},
+			DependsOn:   []string{"api"},
+		},
+	})
+
+	for config, expected := range map[string]string{
+		`{"api": {"command": "true"}}`:                         `conflicts with service`,
+		`{"agent": {"command": "true", "depends_on": ["db"]}}`: `depends on undefined service "db"`,
+		`{"agent": {"working_dir": "/tmp"}}`:                   `command is required`,
+		`{"agent": {"cmd": "true"}}`:                           `field cmd not found`,
+	} {
+		var v map[string]any
+		assert.NilError(t, yaml.Unmarshal([]byte(config), &v))
+		project.Extensions[hostProcessExtension] = v
+		_, err := getHostProcesses(project)
+		assert.ErrorContains(t, err, expected)
+	}
+}
+
+func TestHostProcessLifecycle(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("relies on a posix shell")
+	}
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	t.Setenv("COMPOSE_FEATURES", "host-process")
+	mockCtrl := gomock.NewController(t)
+	api, cli := prepareMocks(mockCtrl)
+	tested := composeService{
+		dockerCli: cli,
+	}
+	api.EXPECT().ContainerList(gomock.Any(), gomock.Any()).Return([]container.Summary{}, nil)
+
+	project := &types.Project{
+		Name:       "test",
+		WorkingDir: t.TempDir(),
+		Extensions: types.Extensions{
+			hostProcessExtension: map[string]any{
+				"ticker": map[string]any{
+					"command":     "echo $GREETING; exec sleep 30",
+					"environment": []any{"GREETING=hello"},
+				},
+			},
+		},
+	}
+	ctx := context.TODO()
+	assert.NilError(t, tested.startHostProcesses(ctx, project))
+
+	summaries, err := hostProcessSummaries("test")
+	assert.NilError(t, err)
+	assert.Equal(t, len(summaries), 1)
+	assert.Equal(t, summaries[0].Name, "ticker")
+	assert.Equal(t, summaries[0].State, "running")
+
+	consumer := &testLogConsumer{}
+	poll.WaitOn(t, func(poll.LogT) poll.Result {
+		consumer.logs = nil
+		if err := tested.logHostProcesses(ctx, "test", consumer, "all", false); err != nil {
+			return poll.Error(err)
+		}
+		if strings.Join(consumer.LogsForContainer("ticker"), "\n") != "hello" {
+			return poll.Continue("waiting for output")
+		}
+		return poll.Success()
+	}, poll.WithTimeout(5*time.Second))
+
+	timeout := 5 * time.Second
+	assert.NilError(t, tested.stopHostProcesses(ctx, "test", true, &timeout))
+	summaries, err = hostProcessSummaries("test")
+	assert.NilError(t, err)
+	assert.Equal(t, len(summaries), 0)
+	_, err = os.Stat(filepath.Join(os.Getenv("XDG_RUNTIME_DIR"), "test.processes", "ticker.log"))
+	assert.Assert(t, os.IsNotExist(err))
+}
+
+func TestHostProcessReusedPID(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	dir := filepath.Join(os.Getenv("XDG_RUNTIME_DIR"), "test.processes")
+	assert.NilError(t, os.MkdirAll(dir, 0o700))
+
+	start, err := hostProcessStartTime(os.Getpid())
+	assert.NilError(t, err)
+	assert.Assert(t, hostProcessState{PID: os.Getpid(), ProcessStart: start}.running())
+
+	// the recorded process exited and its pid now belongs to this test
+	stale := hostProcessState{PID: os.Getpid(), Command: []string{"sleep", "30"}, ProcessStart: "stale"}
+	assert.Assert(t, !stale.running())
+	assert.Assert(t, !hostProcessState{PID: os.Getpid()}.running())
+	assert.NilError(t, writeHostProcessState(dir, "ticker", stale))
+
+	timeout := time.Second
+	assert.NilError(t, (&composeService{}).stopHostProcesses(context.TODO(), "test", false, &timeout))
+	states, err := readHostProcesses("test")
+	assert.NilError(t, err)
+	assert.Equal(t, states["ticker"].PID, 0)
+}
+
+func TestHostProcessFeatureDisabled(t *testing.T) {
+	project := &types.Project{
+		Name: "test",
+		Extensions: types.Extensions{
+			hostProcessExtension: map[string]any{
+				"agent": map[string]any{"command": "true"},
+			},
+		},
+		// the project environment, including its .env file, can't enable the feature
+		Environment: types.Mapping{"COMPOSE_FEATURES": "host-process"},
+	}
+	t.Setenv("COMPOSE_FEATURES", "")
+	err := (&composeService{}).startHostProcesses(context.TODO(), project)
+	assert.ErrorContains(t, err, "COMPOSE_FEATURES=host-process")
+}
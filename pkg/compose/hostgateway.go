@@ -0,0 +1,110 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// hostGatewayExtension makes host.docker.internal, and optional aliases, resolve to the host for all services.
+	// Can be set to true, or to a mapping with `aliases`
+	hostGatewayExtension = "x-host-gateway"
+	// dockerHostName is the conventional hostname containers use to reach the host
+	dockerHostName = "host.docker.internal"
+	// hostGateway is the engine special value for the host address in extra_hosts
+	hostGateway = "host-gateway"
+	// rootlessHostLoopback is the address slirp4netns exposes host loopback on for rootless engines
+	rootlessHostLoopback = "10.0.2.2"
+)
+
+// hostGatewayNames returns the hostnames to resolve to the host, if enabled by project
+func hostGatewayNames(project *types.Project) ([]string, error) {
+	v, ok := project.Extensions[hostGatewayExtension]
+	if !ok {
+		return nil, nil
+	}
+	switch v := v.(type) {
+	case bool:
+		if !v {
+			return nil, nil
+		}
+		return []string{dockerHostName}, nil
+	case map[string]any:
+		names := []string{dockerHostName}
+		aliases, ok := v["aliases"].([]any)
+		if !ok && v["aliases"] != nil {
+			return nil, fmt.Errorf("%s: aliases must be a list", hostGatewayExtension)
+		}
+		for _, alias := range aliases {
+			names = append(names, fmt.Sprint(alias))
+		}
+		return names, nil
+	default:
+		return nil, fmt.Errorf("%s must be a boolean or a mapping", hostGatewayExtension)
+	}
+}
+
+// getHostGatewayAddress returns the address containers reach the host on. Engine resolves host-gateway to the
+// bridge gateway, or to the host on Docker Desktop, but a rootless engine runs in its own network namespace.
+// Relies on the engine info cached by composeService, so that each engine is only queried once
+func (s *composeService) getHostGatewayAddress(ctx context.Context) (string, error) {
+	info, err := s.getEngineInfo(ctx)
+	if err != nil {
+		return "", err
+	}
+	if isRootless(info) {
+		logrus.Debugf("rootless engine, %s requires rootlesskit host loopback to be enabled", rootlessHostLoopback)
+		return rootlessHostLoopback, nil
+	}
+	return hostGateway, nil
+}
+
+// withHostGateway adds extra_hosts entries for the host gateway names not explicitly set by service
+func (s *composeService) withHostGateway(ctx context.Context, project *types.Project, service types.ServiceConfig) (types.HostsList, error) {
+	names, err := hostGatewayNames(project)
+	if err != nil || len(names) == 0 {
+		return service.ExtraHosts, err
+	}
+	switch {
+	case service.NetworkMode == "host", service.NetworkMode == "none",
+		strings.HasPrefix(service.NetworkMode, types.NetworkModeServicePrefix),
+		strings.HasPrefix(service.NetworkMode, types.NetworkModeContainerPrefix):
+		// service doesn't manage its own /etc/hosts
+		return service.ExtraHosts, nil
+	}
+
+	address, err := s.getHostGatewayAddress(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hosts := types.HostsList{}
+	for name, ips := range service.ExtraHosts {
+		hosts[name] = ips
+	}
+	for _, name := range names {
+		if _, ok := hosts[name]; !ok {
+			hosts[name] = []string{address}
+		}
+	}
+	return hosts, nil
+}
@@ -0,0 +1,64 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/docker/api/types/system"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+)
+
+func TestWithHostGateway(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	api, cli := prepareMocks(mockCtrl)
+	tested := &composeService{
+		dockerCli: cli,
+	}
+	api.EXPECT().Info(gomock.Any()).Return(system.Info{}, nil).AnyTimes()
+
+	project := &types.Project{
+		Extensions: types.Extensions{
+			hostGatewayExtension: map[string]any{"aliases": []any{"dev.host"}},
+		},
+	}
+	hosts, err := tested.withHostGateway(context.TODO(), project, types.ServiceConfig{
+		Name:       "web",
+		ExtraHosts: types.HostsList{"dev.host": {"192.168.1.10"}},
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, hosts, types.HostsList{
+		"host.docker.internal": {"host-gateway"},
+		"dev.host":             {"192.168.1.10"},
+	})
+
+	hosts, err = tested.withHostGateway(context.TODO(), project, types.ServiceConfig{
+		Name:        "sidecar",
+		NetworkMode: "service:web",
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, len(hosts), 0)
+
+	hosts, err = tested.withHostGateway(context.TODO(), &types.Project{}, types.ServiceConfig{Name: "web"})
+	assert.NilError(t, err)
+	assert.Equal(t, len(hosts), 0)
+}
@@ -0,0 +1,504 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func (s *composeService) ImportK8s(_ context.Context, options api.ImportK8sOptions) (*types.Project, error) {
+	manifests := k8sManifests{configMaps: map[string]corev1.ConfigMap{}}
+	for _, p := range options.Manifests {
+		if err := manifests.load(p); err != nil {
+			return nil, err
+		}
+	}
+	project, warnings := manifests.convert(options.ProjectName)
+	for _, w := range warnings {
+		logrus.Warn(w)
+	}
+	return project, nil
+}
+
+// k8sManifests are the Kubernetes resources converted into a Compose project
+type k8sManifests struct {
+	deployments []appsv1.Deployment
+	services    []corev1.Service
+	configMaps  map[string]corev1.ConfigMap
+	warnings    []string
+}
+
+// load reads the resources declared by a manifest file, or by the yaml and json files of a directory
+func (m *k8sManifests) load(p string) error {
+	fi, err := os.Stat(p)
+	if err != nil {
+		return err
+	}
+	if !fi.IsDir() {
+		return m.loadFile(p)
+	}
+	return filepath.WalkDir(p, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		switch filepath.Ext(file) {
+		case ".yaml", ".yml", ".json":
+			return m.loadFile(file)
+		}
+		return nil
+	})
+}
+
+func (m *k8sManifests) loadFile(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close() //nolint:errcheck
+	decoder := yaml.NewDecoder(f)
+	for {
+		var doc map[string]any
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if doc == nil {
+			continue
+		}
+		// documents are converted to json, so they can be decoded by Kubernetes API types
+		b, err := json.Marshal(doc)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if err := m.add(b); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+	}
+}
+
+func (m *k8sManifests) add(b []byte) error {
+	var meta struct {
+		metav1.TypeMeta `json:",inline"`
+		Metadata        metav1.ObjectMeta `json:"metadata"`
+		Items           []json.RawMessage `json:"items"`
+	}
+	if err := json.Unmarshal(b, &meta); err != nil {
+		return err
+	}
+	switch meta.Kind {
+	case "List", "DeploymentList", "ServiceList", "ConfigMapList":
+		for _, item := range meta.Items {
+			if err := m.add(item); err != nil {
+				return err
+			}
+		}
+	case "Deployment":
+		var d appsv1.Deployment
+		if err := json.Unmarshal(b, &d); err != nil {
+			return fmt.Errorf("deployment %s: %w", meta.Metadata.Name, err)
+		}
+		m.deployments = append(m.deployments, d)
+	case "Service":
+		var svc corev1.Service
+		if err := json.Unmarshal(b, &svc); err != nil {
+			return fmt.Errorf("service %s: %w", meta.Metadata.Name, err)
+		}
+		m.services = append(m.services, svc)
+	case "ConfigMap":
+		var cm corev1.ConfigMap
+		if err := json.Unmarshal(b, &cm); err != nil {
+			return fmt.Errorf("configmap %s: %w", meta.Metadata.Name, err)
+		}
+		m.configMaps[k8sKey(cm.Namespace, cm.Name)] = cm
+	default:
+		m.warn("ignoring %s %s: unsupported kind", meta.Kind, meta.Metadata.Name)
+	}
+	return nil
+}
+
+func (m *k8sManifests) warn(format string, args ...any) {
+	m.warnings = append(m.warnings, fmt.Sprintf(format, args...))
+}
+
+// k8sKey identifies a resource by namespace and name
+func k8sKey(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+// convert builds a Compose project from the loaded resources, along with warnings about the settings which could not
+// be converted
+func (m *k8sManifests) convert(name string) (*types.Project, []string) {
+	project := &types.Project{
+		Name:     name,
+		Services: types.Services{},
+		Configs:  types.Configs{},
+		Volumes:  types.Volumes{},
+	}
+	// names of the services created for the containers of each deployment
+	containers := map[string]map[string]string{}
+	for _, d := range m.deployments {
+		containers[k8sKey(d.Namespace, d.Name)] = m.convertDeployment(project, d)
+	}
+	for _, svc := range m.services {
+		m.convertService(project, svc, containers)
+	}
+	if len(project.Configs) == 0 {
+		project.Configs = nil
+	}
+	if len(project.Volumes) == 0 {
+		project.Volumes = nil
+	}
+	return project, m.warnings
+}
+
+// convertDeployment adds a service per container of the deployment pod, and returns their names by container name
+func (m *k8sManifests) convertDeployment(project *types.Project, d appsv1.Deployment) map[string]string {
+	pod := d.Spec.Template.Spec
+	names := map[string]string{}
+	// a single container takes the name of the deployment, init containers and sidecars are named after it
+	serviceName := func(c corev1.Container, init bool) string {
+		if !init && len(pod.Containers) == 1 {
+			return d.Name
+		}
+		return d.Name + "-" + c.Name
+	}
+
+	var inits []string
+	for _, c := range pod.InitContainers {
+		service := m.convertContainer(project, d, c)
+		service.Name = serviceName(c, true)
+		service.Restart = types.RestartPolicyNo
+		// init containers run in sequence, each one once the previous one completed
+		if len(inits) > 0 {
+			service.DependsOn = types.DependsOnConfig{
+				inits[len(inits)-1]: {Condition: types.ServiceConditionCompletedSuccessfully, Required: true},
+			}
+		}
+		project.Services[service.Name] = service
+		inits = append(inits, service.Name)
+	}
+	for _, c := range pod.Containers {
+		service := m.convertContainer(project, d, c)
+		service.Name = serviceName(c, false)
+		if len(inits) > 0 {
+			service.DependsOn = types.DependsOnConfig{
+				inits[len(inits)-1]: {Condition: types.ServiceConditionCompletedSuccessfully, Required: true},
+			}
+		}
+		if d.Spec.Replicas != nil && *d.Spec.Replicas != 1 {
+			replicas := int(*d.Spec.Replicas)
+			if service.Deploy == nil {
+				service.Deploy = &types.DeployConfig{}
+			}
+			service.Deploy.Replicas = &replicas
+		}
+		if pod.HostNetwork {
+			service.NetworkMode = "host"
+		}
+		project.Services[service.Name] = service
+		names[c.Name] = service.Name
+	}
+
+	if len(pod.NodeSelector) > 0 || pod.Affinity != nil || len(pod.Tolerations) > 0 {
+		m.warn("deployment %s: scheduling constraints are not supported", d.Name)
+	}
+	return names
+}
+
+func (m *k8sManifests) convertContainer(project *types.Project, d appsv1.Deployment, c corev1.Container) types.ServiceConfig {
+	service := types.ServiceConfig{
+		Image:      c.Image,
+		Entrypoint: c.Command,
+		Command:    c.Args,
+		WorkingDir: c.WorkingDir,
+		Tty:        c.TTY,
+		StdinOpen:  c.Stdin,
+	}
+	where := fmt.Sprintf("deployment %s container %s", d.Name, c.Name)
+
+	env := types.MappingWithEquals{}
+	for _, from := range c.EnvFrom {
+		switch {
+		case from.ConfigMapRef != nil:
+			cm, ok := m.configMaps[k8sKey(d.Namespace, from.ConfigMapRef.Name)]
+			if !ok {
+				m.warn("%s: configmap %s not found", where, from.ConfigMapRef.Name)
+				continue
+			}
+			for k, v := range cm.Data {
+				env[from.Prefix+k] = &v
+			}
+		case from.SecretRef != nil:
+			m.warn("%s: environment from secret %s is not supported", where, from.SecretRef.Name)
+		}
+	}
+	for _, e := range c.Env {
+		switch {
+		case e.ValueFrom == nil:
+			env[e.Name] = &e.Value
+		case e.ValueFrom.ConfigMapKeyRef != nil:
+			ref := e.ValueFrom.ConfigMapKeyRef
+			if v, ok := m.configMaps[k8sKey(d.Namespace, ref.Name)].Data[ref.Key]; ok {
+				env[e.Name] = &v
+			} else {
+				m.warn("%s: key %s of configmap %s not found for variable %s", where, ref.Key, ref.Name, e.Name)
+			}
+		default:
+			m.warn("%s: variable %s: only values and configmap keys are supported", where, e.Name)
+		}
+	}
+	if len(env) > 0 {
+		service.Environment = env
+	}
+
+	for _, p := range c.Ports {
+		port := strconv.Itoa(int(p.ContainerPort))
+		if p.Protocol != "" && p.Protocol != corev1.ProtocolTCP {
+			port += "/" + strings.ToLower(string(p.Protocol))
+		}
+		service.Expose = append(service.Expose, port)
+	}
+
+	if limits := c.Resources.Limits; len(limits) > 0 {
+		limit := &types.Resource{}
+		if cpu, ok := limits[corev1.ResourceCPU]; ok {
+			limit.NanoCPUs = types.NanoCPUs(float32(cpu.MilliValue()) / 1000)
+		}
+		if memory, ok := limits[corev1.ResourceMemory]; ok {
+			limit.MemoryBytes = types.UnitBytes(memory.Value())
+		}
+		service.Deploy = &types.DeployConfig{Resources: types.Resources{Limits: limit}}
+	}
+
+	probe := c.ReadinessProbe
+	if probe == nil {
+		probe = c.LivenessProbe
+	}
+	if probe != nil {
+		if probe.Exec != nil {
+			service.HealthCheck = k8sHealthCheck(probe)
+		} else {
+			m.warn("%s: only exec probes can be converted to a healthcheck", where)
+		}
+	}
+	if c.StartupProbe != nil || c.Lifecycle != nil {
+		m.warn("%s: startup probe and lifecycle hooks are not supported", where)
+	}
+
+	for _, mount := range c.VolumeMounts {
+		m.convertVolumeMount(project, &service, d, mount, where)
+	}
+	return service
+}
+
+// k8sHealthCheck converts an exec probe
+func k8sHealthCheck(probe *corev1.Probe) *types.HealthCheckConfig {
+	seconds := func(s int32) *types.Duration {
+		if s == 0 {
+			return nil
+		}
+		d := types.Duration(time.Duration(s) * time.Second)
+		return &d
+	}
+	healthcheck := &types.HealthCheckConfig{
+		Test:        append(types.HealthCheckTest{"CMD"}, probe.Exec.Command...),
+		Interval:    seconds(probe.PeriodSeconds),
+		Timeout:     seconds(probe.TimeoutSeconds),
+		StartPeriod: seconds(probe.InitialDelaySeconds),
+	}
+	if probe.FailureThreshold > 0 {
+		retries := uint64(probe.FailureThreshold)
+		healthcheck.Retries = &retries
+	}
+	return healthcheck
+}
+
+func (m *k8sManifests) convertVolumeMount(project *types.Project, service *types.ServiceConfig, d appsv1.Deployment, mount corev1.VolumeMount, where string) {
+	i := slices.IndexFunc(d.Spec.Template.Spec.Volumes, func(v corev1.Volume) bool { return v.Name == mount.Name })
+	if i < 0 {
+		m.warn("%s: volume %s not found", where, mount.Name)
+		return
+	}
+	source := d.Spec.Template.Spec.Volumes[i].VolumeSource
+	switch {
+	case source.ConfigMap != nil:
+		cm, ok := m.configMaps[k8sKey(d.Namespace, source.ConfigMap.Name)]
+		if !ok {
+			m.warn("%s: configmap %s not found", where, source.ConfigMap.Name)
+			return
+		}
+		// each key of the configmap is a file of the mounted directory
+		files := map[string]string{}
+		for k := range cm.Data {
+			files[k] = k
+		}
+		if len(source.ConfigMap.Items) > 0 {
+			files = map[string]string{}
+			for _, item := range source.ConfigMap.Items {
+				files[item.Key] = item.Path
+			}
+		}
+		for key, file := range files {
+			content, ok := cm.Data[key]
+			if !ok {
+				m.warn("%s: key %s of configmap %s not found", where, key, cm.Name)
+				continue
+			}
+			target := path.Join(mount.MountPath, file)
+			if mount.SubPath != "" {
+				if mount.SubPath != file {
+					continue
+				}
+				target = mount.MountPath
+			}
+			name := cm.Name + "-" + strings.ReplaceAll(key, "/", "-")
+			project.Configs[name] = types.ConfigObjConfig{Name: name, Content: content}
+			service.Configs = append(service.Configs, types.ServiceConfigObjConfig{Source: name, Target: target})
+		}
+		slices.SortFunc(service.Configs, func(a, b types.ServiceConfigObjConfig) int { return strings.Compare(a.Target, b.Target) })
+	case source.EmptyDir != nil:
+		if source.EmptyDir.Medium == corev1.StorageMediumMemory {
+			service.Volumes = append(service.Volumes, types.ServiceVolumeConfig{Type: types.VolumeTypeTmpfs, Target: mount.MountPath})
+			return
+		}
+		service.Volumes = append(service.Volumes, types.ServiceVolumeConfig{Type: types.VolumeTypeVolume, Target: mount.MountPath})
+	case source.PersistentVolumeClaim != nil:
+		claim := source.PersistentVolumeClaim.ClaimName
+		project.Volumes[claim] = types.VolumeConfig{Name: claim}
+		service.Volumes = append(service.Volumes, types.ServiceVolumeConfig{
+			Type:     types.VolumeTypeVolume,
+			Source:   claim,
+			Target:   mount.MountPath,
+			ReadOnly: mount.ReadOnly || source.PersistentVolumeClaim.ReadOnly,
+		})
+	case source.HostPath != nil:
+		service.Volumes = append(service.Volumes, types.ServiceVolumeConfig{
+			Type:     types.VolumeTypeBind,
+			Source:   source.HostPath.Path,
+			Target:   mount.MountPath,
+			ReadOnly: mount.ReadOnly,
+		})
+	default:
+		m.warn("%s: volume %s has an unsupported source", where, mount.Name)
+	}
+}
+
+// convertService publishes the ports of NodePort and LoadBalancer services, and makes the service name resolve to
+// the containers it selects
+func (m *k8sManifests) convertService(project *types.Project, svc corev1.Service, containers map[string]map[string]string) {
+	if svc.Spec.Type == corev1.ServiceTypeExternalName {
+		m.warn("service %s: ExternalName services are not supported", svc.Name)
+		return
+	}
+	var selected *appsv1.Deployment
+	for _, d := range m.deployments {
+		if d.Namespace == svc.Namespace && len(svc.Spec.Selector) > 0 && k8sSelects(svc.Spec.Selector, d.Spec.Template.Labels) {
+			selected = &d
+			break
+		}
+	}
+	if selected == nil {
+		m.warn("service %s: no deployment matches its selector", svc.Name)
+		return
+	}
+	names := containers[k8sKey(selected.Namespace, selected.Name)]
+
+	publish := svc.Spec.Type == corev1.ServiceTypeNodePort || svc.Spec.Type == corev1.ServiceTypeLoadBalancer
+	aliased := map[string]bool{}
+	for _, p := range svc.Spec.Ports {
+		container, target := k8sTargetContainer(selected.Spec.Template.Spec, p)
+		if container == "" {
+			m.warn("service %s: no container of deployment %s exposes port %s", svc.Name, selected.Name, p.TargetPort.String())
+			continue
+		}
+		service := project.Services[names[container]]
+		if publish {
+			service.Ports = append(service.Ports, types.ServicePortConfig{
+				Target:    uint32(target),
+				Published: strconv.Itoa(int(p.Port)),
+				Protocol:  strings.ToLower(string(p.Protocol)),
+			})
+		}
+		if service.Name != svc.Name && !aliased[service.Name] {
+			if service.Networks == nil {
+				service.Networks = map[string]*types.ServiceNetworkConfig{}
+			}
+			network := service.Networks["default"]
+			if network == nil {
+				network = &types.ServiceNetworkConfig{}
+				service.Networks["default"] = network
+			}
+			network.Aliases = append(network.Aliases, svc.Name)
+			aliased[service.Name] = true
+		}
+		project.Services[service.Name] = service
+	}
+}
+
+// k8sSelects tells if labels match all the keys of selector
+func k8sSelects(selector, labels map[string]string) bool {
+	for k, v := range selector {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// k8sTargetContainer returns the container a service port is routed to, and the target port number
+func k8sTargetContainer(pod corev1.PodSpec, p corev1.ServicePort) (string, int32) {
+	target := p.TargetPort.IntVal
+	if p.TargetPort.StrVal == "" && target == 0 {
+		target = p.Port
+	}
+	for _, c := range pod.Containers {
+		for _, port := range c.Ports {
+			if (p.TargetPort.StrVal != "" && port.Name == p.TargetPort.StrVal) ||
+				(p.TargetPort.StrVal == "" && port.ContainerPort == target) {
+				return c.Name, port.ContainerPort
+			}
+		}
+	}
+	// ports don't need to be declared by containers to be reachable
+	if p.TargetPort.StrVal == "" && len(pod.Containers) == 1 {
+		return pod.Containers[0].Name, target
+	}
+	return "", 0
+}
@@ -0,0 +1,144 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const k8sManifest = `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-config
+data:
+  LOG_LEVEL: debug
+  nginx.conf: "worker_processes 1;"
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 2
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      initContainers:
+        - name: migrate
+          image: acme/migrate
+      containers:
+        - name: nginx
+          image: nginx
+          args: ["-g", "daemon off;"]
+          ports:
+            - name: http
+              containerPort: 80
+          env:
+            - name: LOG_LEVEL
+              valueFrom:
+                configMapKeyRef:
+                  name: web-config
+                  key: LOG_LEVEL
+            - name: PASSWORD
+              valueFrom:
+                secretKeyRef:
+                  name: web-secret
+                  key: password
+          resources:
+            limits:
+              cpu: 500m
+              memory: 128Mi
+          readinessProbe:
+            exec:
+              command: ["curl", "-f", "http://localhost"]
+            periodSeconds: 5
+          volumeMounts:
+            - name: config
+              mountPath: /etc/nginx/nginx.conf
+              subPath: nginx.conf
+            - name: data
+              mountPath: /data
+      volumes:
+        - name: config
+          configMap:
+            name: web-config
+        - name: data
+          persistentVolumeClaim:
+            claimName: web-data
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: frontend
+spec:
+  type: LoadBalancer
+  selector:
+    app: web
+  ports:
+    - port: 8080
+      targetPort: http
+---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: web
+`
+
+func TestImportK8s(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "web.yaml")
+	assert.NilError(t, os.WriteFile(file, []byte(k8sManifest), 0o600))
+
+	manifests := k8sManifests{configMaps: map[string]corev1.ConfigMap{}}
+	assert.NilError(t, manifests.load(filepath.Dir(file)))
+	project, warnings := manifests.convert("acme")
+
+	assert.Equal(t, project.Name, "acme")
+	assert.DeepEqual(t, project.ServiceNames(), []string{"web", "web-migrate"})
+
+	web := project.Services["web"]
+	assert.Equal(t, web.Image, "nginx")
+	assert.DeepEqual(t, web.Command, types.ShellCommand{"-g", "daemon off;"})
+	assert.Equal(t, *web.Deploy.Replicas, 2)
+	assert.Equal(t, web.Deploy.Resources.Limits.NanoCPUs, types.NanoCPUs(0.5))
+	assert.Equal(t, web.Deploy.Resources.Limits.MemoryBytes, types.UnitBytes(128*1024*1024))
+	assert.Equal(t, *web.Environment["LOG_LEVEL"], "debug")
+	assert.DeepEqual(t, web.HealthCheck.Test, types.HealthCheckTest{"CMD", "curl", "-f", "http://localhost"})
+	assert.DeepEqual(t, web.Ports, []types.ServicePortConfig{{Target: 80, Published: "8080"}})
+	assert.DeepEqual(t, web.Networks["default"].Aliases, []string{"frontend"})
+	assert.Equal(t, web.DependsOn["web-migrate"].Condition, types.ServiceConditionCompletedSuccessfully)
+	assert.DeepEqual(t, web.Configs, []types.ServiceConfigObjConfig{{Source: "web-config-nginx.conf", Target: "/etc/nginx/nginx.conf"}})
+	assert.Equal(t, project.Configs["web-config-nginx.conf"].Content, "worker_processes 1;")
+	assert.DeepEqual(t, web.Volumes, []types.ServiceVolumeConfig{{Type: types.VolumeTypeVolume, Source: "web-data", Target: "/data"}})
+	assert.Equal(t, project.Services["web-migrate"].Restart, types.RestartPolicyNo)
+
+	assert.DeepEqual(t, warnings, []string{
+		"ignoring Ingress web: unsupported kind",
+		"deployment web container nginx: variable PASSWORD: only values and configmap keys are supported",
+	})
+}
@@ -0,0 +1,93 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/cli/opts"
+	"github.com/docker/go-units"
+)
+
+// checkKernelSettings validates sysctls and ulimits requested by services against what the engine accepts, and
+// reports all violations at once, so they don't fail one by one as containers get created
+func (s *composeService) checkKernelSettings(ctx context.Context, project *types.Project) error {
+	var violations []string
+	withSysctls := false
+	for name, service := range project.Services {
+		for _, v := range kernelSettingsViolations(service) {
+			violations = append(violations, fmt.Sprintf("service %q %s", name, v))
+		}
+		withSysctls = withSysctls || len(service.Sysctls) > 0
+	}
+	if withSysctls && !s.dryRun {
+		info, err := s.getEngineInfo(ctx)
+		if err != nil {
+			return err
+		}
+		if info.OSType == "windows" {
+			for name, service := range project.Services {
+				if len(service.Sysctls) > 0 {
+					violations = append(violations, fmt.Sprintf("service %q sets sysctls, which Windows engine doesn't support", name))
+				}
+			}
+		}
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	slices.Sort(violations)
+	return errors.New("invalid sysctls or ulimits:\n  " + strings.Join(violations, "\n  "))
+}
+
+func kernelSettingsViolations(service types.ServiceConfig) []string {
+	var violations []string
+	for key, value := range service.Sysctls {
+		if _, err := opts.ValidateSysctl(key + "=" + value); err != nil {
+			violations = append(violations, fmt.Sprintf("sysctl %s: not namespaced, only kernel IPC parameters, fs.mqueue.* and net.* can be set", key))
+			continue
+		}
+		if strings.HasPrefix(key, "net.") {
+			switch {
+			case service.NetworkMode == "host":
+				violations = append(violations, fmt.Sprintf("sysctl %s: can't be set in host network namespace", key))
+			case strings.HasPrefix(service.NetworkMode, types.ServicePrefix), strings.HasPrefix(service.NetworkMode, types.ContainerPrefix):
+				violations = append(violations, fmt.Sprintf("sysctl %s: can't be set when joining network namespace %s", key, service.NetworkMode))
+			}
+		} else if service.Ipc == "host" {
+			violations = append(violations, fmt.Sprintf("sysctl %s: can't be set in host IPC namespace", key))
+		}
+	}
+	for name, u := range service.Ulimits {
+		soft, hard := u.Single, u.Single
+		if u.Soft != 0 {
+			soft = u.Soft
+		}
+		if u.Hard != 0 {
+			hard = u.Hard
+		}
+		if _, err := units.ParseUlimit(fmt.Sprintf("%s=%d:%d", name, soft, hard)); err != nil {
+			violations = append(violations, fmt.Sprintf("ulimit %s: %s", name, err))
+		}
+	}
+	return violations
+}
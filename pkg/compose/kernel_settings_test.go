@@ -0,0 +1,55 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+)
+
+func TestKernelSettingsViolations(t *testing.T) {
+	service := types.ServiceConfig{
+		Name:        "test",
+		NetworkMode: "host",
+		Ipc:         "host",
+		Sysctls: types.Mapping{
+			"net.core.somaxconn": "1024",
+			"kernel.shmmax":      "1024",
+			"vm.swappiness":      "10",
+		},
+		Ulimits: map[string]*types.UlimitsConfig{
+			"nofile": {Soft: 2048, Hard: 1024},
+			"nproc":  {Single: 512},
+			"foo":    {Single: 1},
+		},
+	}
+	violations := kernelSettingsViolations(service)
+	assert.Equal(t, len(violations), 5)
+	assert.Assert(t, slices.Contains(violations, "sysctl vm.swappiness: not namespaced, only kernel IPC parameters, fs.mqueue.* and net.* can be set"))
+	assert.Assert(t, slices.Contains(violations, "sysctl net.core.somaxconn: can't be set in host network namespace"))
+	assert.Assert(t, slices.Contains(violations, "sysctl kernel.shmmax: can't be set in host IPC namespace"))
+
+	service.NetworkMode = "service:db"
+	service.Ipc = ""
+	service.Ulimits = nil
+	violations = kernelSettingsViolations(service)
+	assert.Equal(t, len(violations), 2)
+	assert.Assert(t, slices.Contains(violations, "sysctl net.core.somaxconn: can't be set when joining network namespace service:db"))
+}
@@ -66,7 +66,11 @@ func (s *composeService) kill(ctx context.Context, projectName string, options a
 		eg.Go(func() error {
 			eventName := getContainerProgressName(ctr)
 			w.Event(progress.KillingEvent(eventName))
-			err := s.apiClient().ContainerKill(ctx, ctr.ID, options.Signal)
+			signal := options.Signal
+			if sig, ok := options.ServiceSignals[ctr.Labels[api.ServiceLabel]]; ok {
+				signal = sig
+			}
+			err := s.apiClient().ContainerKill(ctx, ctr.ID, signal)
 			if err != nil {
 				w.Event(progress.ErrorMessageEvent(eventName, "Error while Killing"))
 				return err
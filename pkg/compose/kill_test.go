@@ -102,6 +102,42 @@ func TestKillSignal(t *testing.T) {
 	assert.NilError(t, err)
 }
 
+func TestKillServiceSignals(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	api, cli := prepareMocks(mockCtrl)
+	tested := composeService{
+		dockerCli: cli,
+	}
+
+	name := strings.ToLower(testProject)
+
+	ctx := context.Background()
+	api.EXPECT().ContainerList(ctx, container.ListOptions{
+		Filters: filters.NewArgs(projectFilter(name), hasConfigHashLabel()),
+	}).Return(
+		[]container.Summary{testContainer("service1", "123", false), testContainer("service2", "789", false)}, nil)
+	api.EXPECT().VolumeList(
+		gomock.Any(),
+		volume.ListOptions{
+			Filters: filters.NewArgs(projectFilter(strings.ToLower(testProject))),
+		}).
+		Return(volume.ListResponse{}, nil)
+	api.EXPECT().NetworkList(gomock.Any(), network.ListOptions{Filters: filters.NewArgs(projectFilter(strings.ToLower(testProject)))}).
+		Return([]network.Summary{
+			{ID: "abc123", Name: "testProject_default"},
+		}, nil)
+	api.EXPECT().ContainerKill(anyCancellableContext(), "123", "SIGKILL").Return(nil)
+	api.EXPECT().ContainerKill(anyCancellableContext(), "789", "SIGHUP").Return(nil)
+
+	err := tested.kill(ctx, name, compose.KillOptions{
+		Signal:         "SIGKILL",
+		ServiceSignals: map[string]string{"service2": "SIGHUP"},
+	})
+	assert.NilError(t, err)
+}
+
 func testContainer(service string, id string, oneOff bool) container.Summary {
 	// canonical docker names in the API start with a leading slash, some
 	// parts of Compose code will attempt to strip this off, so make sure
@@ -60,7 +60,26 @@ func (s *composeService) Logs(
 		containers = containers.filter(isService(options.Services...))
 	}
 
+	// host process logs have no timestamp to be merged by
+	hostConsumer := consumer
+	if options.MergeByTimestamp {
+		window := logMergeWindow
+		if !options.Follow {
+			window = 0
+		}
+		merger := newTimestampMerger(consumer, options.Timestamps, window)
+		defer merger.Close()
+		consumer = merger
+		// the engine prefixes log lines with a timestamp, merger removes it unless requested
+		options.Timestamps = true
+	}
+
 	eg, ctx := errgroup.WithContext(ctx)
+	if options.HostProcesses {
+		eg.Go(func() error {
+			return s.logHostProcesses(ctx, projectName, hostConsumer, options.Tail, options.Follow)
+		})
+	}
 	for _, ctr := range containers {
 		eg.Go(func() error {
 			err := s.logContainers(ctx, consumer, ctr, options)
@@ -122,7 +141,7 @@ func (s *composeService) Logs(
 					Service:   c.Labels[api.ServiceLabel],
 				})
 				return nil
-			})
+			}, nil)
 			printer.Stop()
 			return err
 		})
@@ -0,0 +1,166 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"container/heap"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+// logMergeWindow is how long log lines are buffered while following logs, waiting for lines with an earlier
+// timestamp to be received from other containers
+const logMergeWindow = 250 * time.Millisecond
+
+type mergedLogLine struct {
+	container string
+	message   string
+	timestamp time.Time
+	received  time.Time
+	seq       int
+}
+
+type mergedLogLines []mergedLogLine
+
+func (l mergedLogLines) Len() int { return len(l) }
+
+func (l mergedLogLines) Less(i, j int) bool {
+	if l[i].timestamp.Equal(l[j].timestamp) {
+		return l[i].seq < l[j].seq
+	}
+	return l[i].timestamp.Before(l[j].timestamp)
+}
+
+func (l mergedLogLines) Swap(i, j int) { l[i], l[j] = l[j], l[i] }
+
+func (l *mergedLogLines) Push(x any) { *l = append(*l, x.(mergedLogLine)) }
+
+func (l *mergedLogLines) Pop() any {
+	old := *l
+	line := old[len(old)-1]
+	*l = old[:len(old)-1]
+	return line
+}
+
+// timestampMerger is a LogConsumer which interleaves log lines from all containers by their timestamp, rather than
+// by arrival order. Lines are expected to be prefixed by the engine timestamp, which is removed unless timestamps
+// are to be displayed. With a window set, lines are forwarded once they have been buffered for this duration,
+// otherwise lines are all sorted and forwarded on Close.
+type timestampMerger struct {
+	consumer   api.LogConsumer
+	timestamps bool
+	window     time.Duration
+	mu         sync.Mutex
+	lines      mergedLogLines
+	seq        int
+	last       map[string]time.Time
+	done       chan struct{}
+	closed     sync.Once
+}
+
+func newTimestampMerger(consumer api.LogConsumer, timestamps bool, window time.Duration) *timestampMerger {
+	m := &timestampMerger{
+		consumer:   consumer,
+		timestamps: timestamps,
+		window:     window,
+		last:       map[string]time.Time{},
+		done:       make(chan struct{}),
+	}
+	if window > 0 {
+		go m.run()
+	}
+	return m
+}
+
+func (m *timestampMerger) run() {
+	ticker := time.NewTicker(m.window / 4)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.done:
+			return
+		case now := <-ticker.C:
+			m.mu.Lock()
+			for m.lines.Len() > 0 && now.Sub(m.lines[0].received) >= m.window {
+				m.forward(heap.Pop(&m.lines).(mergedLogLine))
+			}
+			m.mu.Unlock()
+		}
+	}
+}
+
+func (m *timestampMerger) Log(containerName, message string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	line := mergedLogLine{
+		container: containerName,
+		message:   message,
+		received:  time.Now(),
+		seq:       m.seq,
+	}
+	m.seq++
+	if ts, msg, ok := strings.Cut(message, " "); ok {
+		if t, err := time.Parse(time.RFC3339Nano, ts); err == nil {
+			line.timestamp = t
+			m.last[containerName] = t
+			if !m.timestamps {
+				line.message = msg
+			}
+		}
+	}
+	if line.timestamp.IsZero() {
+		// not a timestamped line, keep it after the last one received from the same container
+		line.timestamp = m.last[containerName]
+	}
+	heap.Push(&m.lines, line)
+}
+
+func (m *timestampMerger) Err(containerName, message string) {
+	m.consumer.Err(containerName, message)
+}
+
+func (m *timestampMerger) Status(container, msg string) {
+	m.flush()
+	m.consumer.Status(container, msg)
+}
+
+func (m *timestampMerger) Register(container string) {
+	m.consumer.Register(container)
+}
+
+// Close forwards all buffered lines and stops merging
+func (m *timestampMerger) Close() {
+	m.closed.Do(func() {
+		close(m.done)
+	})
+	m.flush()
+}
+
+func (m *timestampMerger) flush() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for m.lines.Len() > 0 {
+		m.forward(heap.Pop(&m.lines).(mergedLogLine))
+	}
+}
+
+func (m *timestampMerger) forward(line mergedLogLine) {
+	m.consumer.Log(line.container, line.message)
+}
@@ -0,0 +1,69 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+type orderedLogConsumer struct {
+	testLogConsumer
+	lines []string
+}
+
+func (l *orderedLogConsumer) Log(containerName, message string) {
+	l.lines = append(l.lines, containerName+" "+message)
+}
+
+func TestTimestampMerger(t *testing.T) {
+	consumer := &orderedLogConsumer{}
+	merger := newTimestampMerger(consumer, false, 0)
+	merger.Log("api", "2025-01-01T10:00:00.300000000Z request handled")
+	merger.Log("api", "2025-01-01T10:00:00.400000000Z response sent")
+	merger.Log("db", "2025-01-01T10:00:00.100000000Z connection accepted")
+	merger.Log("db", "continued")
+	merger.Log("db", "2025-01-01T10:00:00.350000000Z query done")
+	assert.Equal(t, len(consumer.lines), 0)
+
+	merger.Close()
+	assert.DeepEqual(t, consumer.lines, []string{
+		"db connection accepted",
+		"db continued",
+		"api request handled",
+		"db query done",
+		"api response sent",
+	})
+}
+
+func TestTimestampMergerWindow(t *testing.T) {
+	consumer := &orderedLogConsumer{}
+	merger := newTimestampMerger(consumer, true, 20*time.Millisecond)
+	defer merger.Close()
+	merger.Log("api", "2025-01-01T10:00:00.3Z request handled")
+	merger.Log("db", "2025-01-01T10:00:00.1Z connection accepted")
+
+	time.Sleep(100 * time.Millisecond)
+	merger.mu.Lock()
+	defer merger.mu.Unlock()
+	assert.DeepEqual(t, consumer.lines, []string{
+		"db 2025-01-01T10:00:00.1Z connection accepted",
+		"api 2025-01-01T10:00:00.3Z request handled",
+	})
+}
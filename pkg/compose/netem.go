@@ -0,0 +1,230 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/stdcopy"
+	"gopkg.in/yaml.v3"
+
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/progress"
+)
+
+// netemExtension declares traffic shaping rules applied to the network interface of a service container
+const netemExtension = "x-netem"
+
+const (
+	// defaultNetworkHelperImage provides tc, iptables and socat to manipulate container network namespaces. Pinned
+	// to a release, so that helpers behave the same whenever the image gets pulled
+	defaultNetworkHelperImage = "nicolaka/netshoot:v0.13"
+	defaultNetemInterface     = "eth0"
+)
+
+var (
+	netemRatePattern      = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?([kmgt]?(bit|bps)|bit|bps)$`)
+	netemInterfacePattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]{1,15}$`)
+)
+
+type serviceNetem struct {
+	Delay     string `yaml:"delay"`
+	Jitter    string `yaml:"jitter"`
+	Loss      string `yaml:"loss"`
+	Rate      string `yaml:"rate"`
+	Interface string `yaml:"interface"`
+	Image     string `yaml:"image"`
+}
+
+// getServiceNetem returns the traffic shaping rules declared by service, or nil
+func getServiceNetem(service types.ServiceConfig) (*serviceNetem, error) {
+	v, ok := service.Extensions[netemExtension]
+	if !ok {
+		return nil, nil
+	}
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var netem serviceNetem
+	decoder := yaml.NewDecoder(bytes.NewReader(b))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&netem); err != nil {
+		return nil, fmt.Errorf("service %s: invalid %s: %w", service.Name, netemExtension, err)
+	}
+	if service.NetworkMode == "host" || service.NetworkMode == "none" {
+		return nil, fmt.Errorf("service %s: %s can't be used with network_mode %s", service.Name, netemExtension, service.NetworkMode)
+	}
+	if _, err := netem.args(); err != nil {
+		return nil, fmt.Errorf("service %s: invalid %s: %w", service.Name, netemExtension, err)
+	}
+	return &netem, nil
+}
+
+// args returns the tc command line replacing the root queuing discipline of the interface with netem rules
+func (n serviceNetem) args() ([]string, error) {
+	device := n.Interface
+	if device == "" {
+		device = defaultNetemInterface
+	}
+	if !netemInterfacePattern.MatchString(device) {
+		return nil, fmt.Errorf("invalid interface name %q", device)
+	}
+	args := []string{"tc", "qdisc", "replace", "dev", device, "root", "netem"}
+	if n.Delay != "" {
+		delay, err := netemDuration(n.Delay)
+		if err != nil {
+			return nil, fmt.Errorf("delay: %w", err)
+		}
+		args = append(args, "delay", delay)
+		if n.Jitter != "" {
+			jitter, err := netemDuration(n.Jitter)
+			if err != nil {
+				return nil, fmt.Errorf("jitter: %w", err)
+			}
+			args = append(args, jitter)
+		}
+	} else if n.Jitter != "" {
+		return nil, errors.New("jitter requires a delay")
+	}
+	if n.Loss != "" {
+		loss, err := strconv.ParseFloat(strings.TrimSuffix(n.Loss, "%"), 64)
+		if err != nil || loss < 0 || loss > 100 {
+			return nil, fmt.Errorf("loss must be a percentage, got %q", n.Loss)
+		}
+		args = append(args, "loss", strconv.FormatFloat(loss, 'f', -1, 64)+"%")
+	}
+	if n.Rate != "" {
+		rate := strings.ToLower(n.Rate)
+		if !netemRatePattern.MatchString(rate) {
+			return nil, fmt.Errorf("rate must be a number followed by a unit like kbit or mbit, got %q", n.Rate)
+		}
+		args = append(args, "rate", rate)
+	}
+	if len(args) == 7 {
+		return nil, errors.New("at least one of delay, loss or rate must be set")
+	}
+	return args, nil
+}
+
+// netemDuration converts a Go duration to the microseconds tc expects
+func netemDuration(value string) (string, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return "", err
+	}
+	if d < 0 {
+		return "", fmt.Errorf("%s is negative", value)
+	}
+	return fmt.Sprintf("%dus", d.Microseconds()), nil
+}
+
+// applyNetem runs a helper container in the network namespace of ctr to apply the traffic shaping rules declared
+// by service. As rules are bound to the network namespace, they must be applied again each time ctr starts
+func (s *composeService) applyNetem(ctx context.Context, project *types.Project, service types.ServiceConfig, ctr container.Summary) error {
+	netem, err := getServiceNetem(service)
+	if err != nil || netem == nil {
+		return err
+	}
+	args, err := netem.args()
+	if err != nil {
+		return err
+	}
+	if err := s.runNetworkHelper(ctx, project, service.Name, ctr.ID, netem.Image, args); err != nil {
+		return fmt.Errorf("service %s: failed to apply %s rules: %w", service.Name, netemExtension, err)
+	}
+	return nil
+}
+
+// networkHelperImage pulls image, or defaultNetworkHelperImage if not set, unless already available
+func (s *composeService) networkHelperImage(ctx context.Context, service string, image string, platform string) (string, error) {
+	if image == "" {
+		image = defaultNetworkHelperImage
+	}
+	if _, err := s.apiClient().ImageInspect(ctx, image); errdefs.IsNotFound(err) {
+		helper := types.ServiceConfig{Name: service + "-helper", Image: image}
+		if _, err := s.pullServiceImage(ctx, helper, s.configFile(), progress.ContextWriter(ctx), true, platform); err != nil {
+			return "", err
+		}
+	} else if err != nil {
+		return "", err
+	}
+	return image, nil
+}
+
+// runNetworkHelper runs cmd in a short-lived container granted NET_ADMIN in the network namespace of container
+// ctrID, and returns its output as error if it fails. image defaults to defaultNetworkHelperImage
+func (s *composeService) runNetworkHelper(ctx context.Context, project *types.Project, service string, ctrID string, image string, cmd []string) error {
+	image, err := s.networkHelperImage(ctx, service, image, defaultPlatform(project))
+	if err != nil {
+		return err
+	}
+
+	created, err := s.apiClient().ContainerCreate(ctx, &container.Config{
+		Image: image,
+		Cmd:   cmd,
+		Labels: map[string]string{
+			api.ProjectLabel: project.Name,
+			api.ServiceLabel: service,
+			api.OneoffLabel:  "True",
+		},
+	}, &container.HostConfig{
+		NetworkMode: container.NetworkMode(types.ContainerPrefix + ctrID),
+		CapAdd:      []string{"NET_ADMIN"},
+	}, nil, nil, "")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = s.apiClient().ContainerRemove(context.WithoutCancel(ctx), created.ID, container.RemoveOptions{Force: true})
+	}()
+
+	if err := s.apiClient().ContainerStart(ctx, created.ID, container.StartOptions{}); err != nil {
+		return err
+	}
+	var exitCode int64
+	waitC, errC := s.apiClient().ContainerWait(ctx, created.ID, container.WaitConditionNotRunning)
+	select {
+	case result := <-waitC:
+		exitCode = result.StatusCode
+	case err := <-errC:
+		return err
+	}
+	if exitCode == 0 {
+		return nil
+	}
+	logs, err := s.apiClient().ContainerLogs(ctx, created.ID, container.LogsOptions{ShowStdout: true, ShowStderr: true})
+	if err != nil {
+		return err
+	}
+	defer logs.Close() //nolint:errcheck
+	var output bytes.Buffer
+	if _, err := stdcopy.StdCopy(&output, &output, logs); err != nil {
+		return err
+	}
+	return fmt.Errorf("%s exited with code %d: %s", cmd[0], exitCode, strings.TrimSpace(output.String()))
+}
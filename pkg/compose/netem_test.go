@@ -0,0 +1,102 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+)
+
+func TestGetServiceNetem(t *testing.T) {
+	tests := []struct {
+		name    string
+		service types.ServiceConfig
+		args    []string
+		err     string
+	}{
+		{
+			name:    "no extension",
+			service: types.ServiceConfig{Name: "api"},
+		},
+		{
+			name: "all rules",
+			service: types.ServiceConfig{Name: "api", Extensions: types.Extensions{netemExtension: map[string]any{
+				"delay": "100ms", "jitter": "20ms", "loss": "1.5%", "rate": "1Mbit",
+			}}},
+			args: []string{"tc", "qdisc", "replace", "dev", "eth0", "root", "netem", "delay", "100000us", "20000us", "loss", "1.5%", "rate", "1mbit"},
+		},
+		{
+			name: "custom interface",
+			service: types.ServiceConfig{Name: "api", Extensions: types.Extensions{netemExtension: map[string]any{
+				"loss": 10, "interface": "eth1",
+			}}},
+			args: []string{"tc", "qdisc", "replace", "dev", "eth1", "root", "netem", "loss", "10%"},
+		},
+		{
+			name: "jitter without delay",
+			service: types.ServiceConfig{Name: "api", Extensions: types.Extensions{netemExtension: map[string]any{
+				"jitter": "20ms",
+			}}},
+			err: "service api: invalid x-netem: jitter requires a delay",
+		},
+		{
+			name: "invalid rate",
+			service: types.ServiceConfig{Name: "api", Extensions: types.Extensions{netemExtension: map[string]any{
+				"rate": "fast",
+			}}},
+			err: `service api: invalid x-netem: rate must be a number followed by a unit like kbit or mbit, got "fast"`,
+		},
+		{
+			name:    "no rule",
+			service: types.ServiceConfig{Name: "api", Extensions: types.Extensions{netemExtension: map[string]any{}}},
+			err:     "service api: invalid x-netem: at least one of delay, loss or rate must be set",
+		},
+		{
+			name: "unknown attribute",
+			service: types.ServiceConfig{Name: "api", Extensions: types.Extensions{netemExtension: map[string]any{
+				"latency": "100ms",
+			}}},
+			err: "service api: invalid x-netem",
+		},
+		{
+			name: "host network",
+			service: types.ServiceConfig{Name: "api", NetworkMode: "host", Extensions: types.Extensions{netemExtension: map[string]any{
+				"delay": "100ms",
+			}}},
+			err: "service api: x-netem can't be used with network_mode host",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			netem, err := getServiceNetem(tt.service)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			assert.NilError(t, err)
+			if tt.args == nil {
+				assert.Assert(t, netem == nil)
+				return
+			}
+			args, err := netem.args()
+			assert.NilError(t, err)
+			assert.DeepEqual(t, args, tt.args)
+		})
+	}
+}
@@ -0,0 +1,344 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/distribution/reference"
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/progress"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/errdefs"
+)
+
+const (
+	// packageCacheExtension declares at project level a caching proxy Compose runs alongside the project, so packages
+	// downloaded by builds and services are cached. Can be set to true, or to a mapping with `image`, `port` and
+	// `path`. Services can set `x-package-cache: false` to opt out
+	packageCacheExtension = "x-package-cache"
+	// packageCacheAlias is the hostname services reach the package cache by
+	packageCacheAlias = "package-cache"
+	// packageCacheLabel marks the package cache container of a project
+	packageCacheLabel = "com.docker.compose.package-cache"
+
+	// squidRepository is the repository of the default package cache image, which Compose configures whatever the tag
+	squidRepository = "ubuntu/squid"
+
+	defaultPackageCacheImage = squidRepository + ":6.6-24.04_beta"
+	defaultPackageCachePort  = 3128
+	defaultPackageCachePath  = "/var/spool/squid"
+)
+
+// squidConfig configures the default package cache image to keep downloaded packages on disk
+const squidConfig = `http_port %d
+http_access allow all
+cache_dir ufs %s 10000 16 256
+maximum_object_size 1 GB
+refresh_pattern -i \.(deb|udeb|rpm|apk|whl|tgz|gz|xz|zst)$ 129600 100%% 129600
+refresh_pattern . 0 20%% 4320
+`
+
+type packageCache struct {
+	Image string
+	Port  int
+	// Path is where the cache is stored inside the container, mounted from a volume
+	Path string
+}
+
+// projectPackageCache returns the package cache configuration, if enabled by project
+func projectPackageCache(project *types.Project) (*packageCache, error) {
+	v, ok := project.Extensions[packageCacheExtension]
+	if !ok {
+		return nil, nil
+	}
+	cache := &packageCache{
+		Image: defaultPackageCacheImage,
+		Port:  defaultPackageCachePort,
+		Path:  defaultPackageCachePath,
+	}
+	switch v := v.(type) {
+	case bool:
+		if !v {
+			return nil, nil
+		}
+		return cache, nil
+	case map[string]any:
+		for key, value := range v {
+			switch key {
+			case "image":
+				cache.Image = fmt.Sprint(value)
+			case "path":
+				cache.Path = fmt.Sprint(value)
+			case "port":
+				port, err := strconv.Atoi(fmt.Sprint(value))
+				if err != nil {
+					return nil, fmt.Errorf("%s: invalid port %v", packageCacheExtension, value)
+				}
+				cache.Port = port
+			default:
+				return nil, fmt.Errorf("%s: unsupported attribute %q", packageCacheExtension, key)
+			}
+		}
+		return cache, nil
+	default:
+		return nil, fmt.Errorf("%s must be a boolean or a mapping", packageCacheExtension)
+	}
+}
+
+// usesPackageCache tells if service didn't opt out from the project package cache
+func usesPackageCache(service types.ServiceConfig) bool {
+	enabled, ok := service.Extensions[packageCacheExtension].(bool)
+	return !ok || enabled
+}
+
+func packageCacheContainerName(projectName string) string {
+	return strings.Join([]string{projectName, packageCacheAlias}, api.Separator)
+}
+
+// packageCacheVariables returns the proxy variables to route downloads through package cache
+func packageCacheVariables(proxy string, noProxy []string) types.Mapping {
+	variables := types.Mapping{}
+	for _, name := range []string{"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY"} {
+		value := proxy
+		if name == "NO_PROXY" {
+			value = strings.Join(noProxy, ",")
+		}
+		variables[name] = value
+		variables[strings.ToLower(name)] = value
+	}
+	return variables
+}
+
+// withPackageCacheBuildArgs sets proxy build args to the package cache address on the default bridge network, which
+// builds run on, unless service opted out or set them explicitly
+func withPackageCacheBuildArgs(project *types.Project, address string, cache *packageCache) {
+	variables := packageCacheVariables(fmt.Sprintf("http://%s:%d", address, cache.Port), []string{"localhost", "127.0.0.1"})
+	for name, service := range project.Services {
+		if service.Build == nil || !usesPackageCache(service) {
+			continue
+		}
+		build := *service.Build
+		build.Args = variables.ToMappingWithEquals().OverrideBy(service.Build.Args)
+		service.Build = &build
+		project.Services[name] = service
+	}
+}
+
+// withPackageCacheEnvironment sets proxy variables to the package cache alias on project networks, unless service
+// opted out, set them explicitly, or doesn't manage its own network
+func withPackageCacheEnvironment(project *types.Project, cache *packageCache) {
+	// services don't reach each other through the package cache
+	noProxy := append([]string{"localhost", "127.0.0.1"}, slices.Sorted(maps.Keys(project.Services))...)
+	variables := packageCacheVariables(fmt.Sprintf("http://%s:%d", packageCacheAlias, cache.Port), noProxy)
+	for name, service := range project.Services {
+		if !usesPackageCache(service) || !joinsProjectNetworks(service) {
+			continue
+		}
+		service.Environment = variables.ToMappingWithEquals().OverrideBy(service.Environment)
+		project.Services[name] = service
+	}
+}
+
+func joinsProjectNetworks(service types.ServiceConfig) bool {
+	switch {
+	case service.NetworkMode == "host", service.NetworkMode == "none",
+		strings.HasPrefix(service.NetworkMode, types.NetworkModeServicePrefix),
+		strings.HasPrefix(service.NetworkMode, types.NetworkModeContainerPrefix):
+		return false
+	}
+	return true
+}
+
+// startPackageCache runs the package cache, if enabled by project, and sets services to use it
+func (s *composeService) startPackageCache(ctx context.Context, project *types.Project, networks map[string]string) error {
+	cache, err := projectPackageCache(project)
+	if err != nil || cache == nil || s.dryRun {
+		return err
+	}
+	if _, err := s.ensurePackageCache(ctx, project, cache); err != nil {
+		return err
+	}
+	if err := s.connectPackageCache(ctx, project, networks); err != nil {
+		return err
+	}
+	withPackageCacheEnvironment(project, cache)
+	return nil
+}
+
+// ensurePackageCache runs the package cache container of the project, and returns its address on the default
+// bridge network
+func (s *composeService) ensurePackageCache(ctx context.Context, project *types.Project, cache *packageCache) (string, error) {
+	name := packageCacheContainerName(project.Name)
+	inspect, err := s.apiClient().ContainerInspect(ctx, name)
+	if errdefs.IsNotFound(err) {
+		err = s.createPackageCache(ctx, project, cache)
+		if err != nil {
+			return "", err
+		}
+		inspect, err = s.apiClient().ContainerInspect(ctx, name)
+	}
+	if err != nil {
+		return "", err
+	}
+	if !inspect.State.Running {
+		w := progress.ContextWriter(ctx)
+		eventName := "Container " + name
+		w.Event(progress.StartingEvent(eventName))
+		if err := s.apiClient().ContainerStart(ctx, inspect.ID, container.StartOptions{}); err != nil {
+			return "", err
+		}
+		w.Event(progress.StartedEvent(eventName))
+		inspect, err = s.apiClient().ContainerInspect(ctx, inspect.ID)
+		if err != nil {
+			return "", err
+		}
+	}
+	bridge, ok := inspect.NetworkSettings.Networks[network.NetworkBridge]
+	if !ok || bridge.IPAddress == "" {
+		return "", fmt.Errorf("package cache container %s isn't connected to the default bridge network", name)
+	}
+	return bridge.IPAddress, nil
+}
+
+func (s *composeService) createPackageCache(ctx context.Context, project *types.Project, cache *packageCache) error {
+	w := progress.ContextWriter(ctx)
+	if _, err := s.apiClient().ImageInspect(ctx, cache.Image); errdefs.IsNotFound(err) {
+		service := types.ServiceConfig{Name: packageCacheAlias, Image: cache.Image}
+		if _, err := s.pullServiceImage(ctx, service, s.configFile(), w, true, defaultPlatform(project)); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+
+	labels := map[string]string{
+		api.ProjectLabel:  project.Name,
+		packageCacheLabel: "true",
+	}
+	// cache outlives the container, so it is kept on `down`
+	vol, err := s.apiClient().VolumeCreate(ctx, volume.CreateOptions{
+		Name:   strings.Join([]string{project.Name, packageCacheAlias}, "_"),
+		Labels: labels,
+	})
+	if err != nil {
+		return err
+	}
+
+	name := packageCacheContainerName(project.Name)
+	eventName := "Container " + name
+	w.Event(progress.CreatingEvent(eventName))
+	created, err := s.apiClient().ContainerCreate(ctx, &container.Config{
+		Image:  cache.Image,
+		Labels: labels,
+	}, &container.HostConfig{
+		NetworkMode: network.NetworkBridge,
+		Mounts: []mount.Mount{{
+			Type:   mount.TypeVolume,
+			Source: vol.Name,
+			Target: cache.Path,
+		}},
+	}, nil, nil, name)
+	if err != nil {
+		return err
+	}
+	if isSquidImage(cache.Image) {
+		config, err := squidConfigArchive(cache)
+		if err != nil {
+			return err
+		}
+		err = s.apiClient().CopyToContainer(ctx, created.ID, "/etc/squid", config, container.CopyToContainerOptions{})
+		if err != nil {
+			return err
+		}
+	}
+	w.Event(progress.CreatedEvent(eventName))
+	return nil
+}
+
+// isSquidImage tells if image is from the repository of the default package cache image
+func isSquidImage(image string) bool {
+	named, err := reference.ParseNormalizedNamed(image)
+	return err == nil && reference.FamiliarName(named) == squidRepository
+}
+
+func squidConfigArchive(cache *packageCache) (*bytes.Buffer, error) {
+	config := fmt.Sprintf(squidConfig, cache.Port, cache.Path)
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err := tw.WriteHeader(&tar.Header{
+		Name: "squid.conf",
+		Mode: 0o644,
+		Size: int64(len(config)),
+	}); err != nil {
+		return nil, err
+	}
+	if _, err := tw.Write([]byte(config)); err != nil {
+		return nil, err
+	}
+	return &buf, tw.Close()
+}
+
+// connectPackageCache attaches package cache to the project networks, so services reach it by its alias
+func (s *composeService) connectPackageCache(ctx context.Context, project *types.Project, networks map[string]string) error {
+	inspect, err := s.apiClient().ContainerInspect(ctx, packageCacheContainerName(project.Name))
+	if err != nil {
+		return err
+	}
+	connected := map[string]bool{}
+	for _, endpoint := range inspect.NetworkSettings.Networks {
+		connected[endpoint.NetworkID] = true
+	}
+	for name, id := range networks {
+		nw := project.Networks[name]
+		if connected[id] || nw.Driver == "host" || nw.Driver == "none" {
+			continue
+		}
+		err := s.apiClient().NetworkConnect(ctx, id, inspect.ID, &network.EndpointSettings{
+			Aliases: []string{packageCacheAlias},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removePackageCache removes the package cache container, keeping the cache volume
+func (s *composeService) removePackageCache(ctx context.Context, projectName string) error {
+	name := packageCacheContainerName(projectName)
+	err := s.apiClient().ContainerRemove(ctx, name, container.RemoveOptions{Force: true})
+	if errdefs.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	progress.ContextWriter(ctx).Event(progress.RemovedEvent("Container " + name))
+	return nil
+}
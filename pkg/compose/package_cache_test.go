@@ -0,0 +1,96 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+)
+
+func TestProjectPackageCache(t *testing.T) {
+	cache, err := projectPackageCache(&types.Project{})
+	assert.NilError(t, err)
+	assert.Assert(t, cache == nil)
+
+	cache, err = projectPackageCache(&types.Project{Extensions: types.Extensions{packageCacheExtension: true}})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, *cache, packageCache{Image: defaultPackageCacheImage, Port: defaultPackageCachePort, Path: defaultPackageCachePath})
+
+	cache, err = projectPackageCache(&types.Project{Extensions: types.Extensions{packageCacheExtension: map[string]any{
+		"image": "example/cache",
+		"port":  8080,
+		"path":  "/cache",
+	}}})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, *cache, packageCache{Image: "example/cache", Port: 8080, Path: "/cache"})
+
+	_, err = projectPackageCache(&types.Project{Extensions: types.Extensions{packageCacheExtension: map[string]any{"size": "10G"}}})
+	assert.Error(t, err, `x-package-cache: unsupported attribute "size"`)
+}
+
+func TestIsSquidImage(t *testing.T) {
+	assert.Check(t, isSquidImage(defaultPackageCacheImage))
+	assert.Check(t, isSquidImage("docker.io/ubuntu/squid:latest"))
+	assert.Check(t, !isSquidImage("example/cache"))
+	assert.Check(t, !isSquidImage("registry.example.com/ubuntu/squid"))
+}
+
+func TestWithPackageCache(t *testing.T) {
+	explicit := "http://proxy:3128"
+	project := &types.Project{
+		Services: types.Services{
+			"api": {
+				Name:  "api",
+				Build: &types.BuildConfig{Context: "."},
+			},
+			"worker": {
+				Name:        "worker",
+				Build:       &types.BuildConfig{Context: ".", Args: types.MappingWithEquals{"HTTP_PROXY": &explicit}},
+				Environment: types.MappingWithEquals{"HTTP_PROXY": &explicit},
+			},
+			"tool": {
+				Name:        "tool",
+				NetworkMode: "host",
+			},
+			"internal": {
+				Name:       "internal",
+				Build:      &types.BuildConfig{Context: "."},
+				Extensions: types.Extensions{packageCacheExtension: false},
+			},
+		},
+	}
+	cache := &packageCache{Port: 3128}
+	withPackageCacheBuildArgs(project, "172.17.0.2", cache)
+	withPackageCacheEnvironment(project, cache)
+
+	api := project.Services["api"]
+	assert.Equal(t, *api.Build.Args["HTTP_PROXY"], "http://172.17.0.2:3128")
+	assert.Equal(t, *api.Build.Args["no_proxy"], "localhost,127.0.0.1")
+	assert.Equal(t, *api.Environment["http_proxy"], "http://package-cache:3128")
+	assert.Equal(t, *api.Environment["NO_PROXY"], "localhost,127.0.0.1,api,internal,tool,worker")
+
+	worker := project.Services["worker"]
+	assert.Equal(t, *worker.Build.Args["HTTP_PROXY"], explicit)
+	assert.Equal(t, *worker.Environment["HTTP_PROXY"], explicit)
+	assert.Equal(t, *worker.Environment["HTTPS_PROXY"], "http://package-cache:3128")
+
+	assert.Equal(t, len(project.Services["tool"].Environment), 0)
+	assert.Equal(t, len(project.Services["internal"].Build.Args), 0)
+	assert.Equal(t, len(project.Services["internal"].Environment), 0)
+}
@@ -0,0 +1,116 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/containerd/platforms"
+	"github.com/docker/docker/errdefs"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+// checkImagesPlatform verifies all service images match the platform they will run on, i.e. the one set by
+// service `platform`, the default platform or the engine native platform, so that all mismatches get reported
+// at once before any container is created. As the engine may still run those with emulation, mismatches are
+// only reported as a warning
+func (s *composeService) checkImagesPlatform(ctx context.Context, project *types.Project) error {
+	native, err := s.RuntimePlatform(ctx)
+	if err != nil {
+		return err
+	}
+	fallback := defaultPlatform(project)
+
+	var mismatches []string
+	for _, service := range project.Services {
+		if service.Provider != nil {
+			continue
+		}
+		image := api.GetImageNameOrDefault(service, project.Name)
+		inspect, err := s.apiClient().ImageInspect(ctx, image)
+		if errdefs.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if inspect.Os == "" || inspect.Architecture == "" {
+			continue
+		}
+		actual := specs.Platform{
+			OS:           inspect.Os,
+			Architecture: inspect.Architecture,
+			Variant:      inspect.Variant,
+		}
+		if msg, ok := checkImagePlatform(service, image, actual, fallback, native); !ok {
+			mismatches = append(mismatches, msg)
+		}
+	}
+	if len(mismatches) == 0 {
+		return nil
+	}
+	sort.Strings(mismatches)
+	logrus.Warn("some service images don't match the platform they are expected to run on, and will rely on emulation:\n" + strings.Join(mismatches, "\n"))
+	return nil
+}
+
+// defaultPlatform returns the platform services without a `platform` attribute run on
+func defaultPlatform(project *types.Project) string {
+	platform, _ := api.DefaultPlatform(project.Environment)
+	return platform
+}
+
+// checkImagePlatform checks platform of service image matches the one service is expected to run on, otherwise
+// returns a message describing mismatch with a suggested fix
+func checkImagePlatform(service types.ServiceConfig, image string, actual specs.Platform, defaultPlatform string, native specs.Platform) (string, bool) {
+	var expected specs.Platform
+	switch {
+	case service.Platform != "" || defaultPlatform != "":
+		platform := service.Platform
+		if platform == "" {
+			platform = defaultPlatform
+		}
+		p, err := platforms.Parse(platform)
+		if err != nil {
+			// invalid platform is reported when image is pulled or built
+			return "", true
+		}
+		expected = p
+	case native.OS != "" && native.Architecture != "":
+		expected = native
+	default:
+		return "", true
+	}
+
+	// engine can run images for compatible platforms, e.g. arm/v7 on arm64
+	if platforms.Only(expected).Match(actual) {
+		return "", true
+	}
+	if service.Platform != "" {
+		return fmt.Sprintf(" - service %q: image %s is for platform %s, not %s. Use an image available for %s",
+			service.Name, image, platforms.Format(actual), platforms.Format(expected), platforms.Format(expected)), false
+	}
+	return fmt.Sprintf(" - service %q: image %s is for platform %s, not %s. Set `platform: %s` to run it with emulation, or use an image available for %s",
+		service.Name, image, platforms.Format(actual), platforms.Format(expected), platforms.Format(actual), platforms.Format(expected)), false
+}
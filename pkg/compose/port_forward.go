@@ -0,0 +1,141 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/sirupsen/logrus"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+func (s *composeService) PortForward(ctx context.Context, projectName string, options api.PortForwardOptions) error {
+	ctr, err := s.getSpecifiedContainer(ctx, projectName, oneOffExclude, false, options.Service, options.Index)
+	if err != nil {
+		return err
+	}
+	image, err := s.networkHelperImage(ctx, options.Service, options.Image, "")
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", portForwardAddress(options))
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		_ = listener.Close()
+	}()
+	_, _ = fmt.Fprintf(s.stdout(), "Forwarding from %s -> %s:%d\n", listener.Addr(), options.Service, options.Port)
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer conn.Close() //nolint:errcheck
+			if err := s.forwardConnection(ctx, projectName, options, ctr.ID, image, conn); err != nil {
+				logrus.Warnf("forwarding connection from %s: %v", conn.RemoteAddr(), err)
+			}
+		}()
+	}
+}
+
+// portForwardAddress returns the host address to listen on
+func portForwardAddress(options api.PortForwardOptions) string {
+	address := options.Address
+	if address == "" {
+		address = "127.0.0.1"
+	}
+	port := options.LocalPort
+	if port == 0 {
+		port = options.Port
+	}
+	return net.JoinHostPort(address, strconv.Itoa(int(port)))
+}
+
+// forwardConnection relays conn to the forwarded port through a socat container running in the network namespace of
+// container ctrID, so the port doesn't need to be published
+func (s *composeService) forwardConnection(ctx context.Context, projectName string, options api.PortForwardOptions, ctrID string, image string, conn net.Conn) error {
+	created, err := s.apiClient().ContainerCreate(ctx, &container.Config{
+		Image:        image,
+		Cmd:          []string{"socat", "-", fmt.Sprintf("TCP:127.0.0.1:%d", options.Port)},
+		AttachStdin:  true,
+		AttachStdout: true,
+		AttachStderr: true,
+		OpenStdin:    true,
+		StdinOnce:    true,
+		Labels: map[string]string{
+			api.ProjectLabel: projectName,
+			api.ServiceLabel: options.Service,
+			api.OneoffLabel:  "True",
+		},
+	}, &container.HostConfig{
+		NetworkMode: container.NetworkMode(types.ContainerPrefix + ctrID),
+	}, nil, nil, "")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = s.apiClient().ContainerRemove(context.WithoutCancel(ctx), created.ID, container.RemoveOptions{Force: true})
+	}()
+
+	cnx, err := s.apiClient().ContainerAttach(ctx, created.ID, container.AttachOptions{
+		Stream: true,
+		Stdin:  true,
+		Stdout: true,
+		Stderr: true,
+	})
+	if err != nil {
+		return err
+	}
+	defer cnx.Close()
+	if err := s.apiClient().ContainerStart(ctx, created.ID, container.StartOptions{}); err != nil {
+		return err
+	}
+
+	go func() {
+		_, _ = io.Copy(cnx.Conn, conn)
+		_ = cnx.CloseWrite()
+	}()
+	stderr := logrus.WithField("service", options.Service).WriterLevel(logrus.DebugLevel)
+	defer stderr.Close() //nolint:errcheck
+	_, err = stdcopy.StdCopy(conn, stderr, cnx.Reader)
+	if errors.Is(err, net.ErrClosed) || ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
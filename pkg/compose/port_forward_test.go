@@ -0,0 +1,31 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+func TestPortForwardAddress(t *testing.T) {
+	assert.Equal(t, portForwardAddress(api.PortForwardOptions{Port: 80}), "127.0.0.1:80")
+	assert.Equal(t, portForwardAddress(api.PortForwardOptions{Port: 80, LocalPort: 8080, Address: "0.0.0.0"}), "0.0.0.0:8080")
+	assert.Equal(t, portForwardAddress(api.PortForwardOptions{Port: 5432, Address: "::1"}), "[::1]:5432")
+}
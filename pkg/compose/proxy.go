@@ -0,0 +1,84 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/sirupsen/logrus"
+)
+
+// proxyExtension declares at project level the proxy configuration propagated to services build args and
+// runtime environment. Services can set `x-proxy: false` to opt out
+const proxyExtension = "x-proxy"
+
+// proxyVariables maps x-proxy attributes to the standard proxy variables
+var proxyVariables = map[string]string{
+	"http":     "HTTP_PROXY",
+	"https":    "HTTPS_PROXY",
+	"ftp":      "FTP_PROXY",
+	"no_proxy": "NO_PROXY",
+	"all":      "ALL_PROXY",
+}
+
+// projectProxy returns the proxy variables declared by project, unless service opted out
+func projectProxy(project *types.Project, service types.ServiceConfig) types.Mapping {
+	if enabled, ok := service.Extensions[proxyExtension].(bool); ok && !enabled {
+		return nil
+	}
+	config, ok := project.Extensions[proxyExtension].(map[string]any)
+	if !ok {
+		return nil
+	}
+	proxy := types.Mapping{}
+	for key, value := range config {
+		name, ok := proxyVariables[key]
+		if !ok {
+			logrus.Warnf("%s: unsupported attribute %q", proxyExtension, key)
+			continue
+		}
+		s, ok := value.(string)
+		if !ok || s == "" {
+			continue
+		}
+		// like docker CLI, set both uppercase and lowercase variables as tools don't agree on the convention
+		proxy[name] = s
+		proxy[strings.ToLower(name)] = s
+	}
+	return proxy
+}
+
+// checkEngineProxy warns when project declares a proxy the engine, which pulls images, isn't configured for
+func (s *composeService) checkEngineProxy(ctx context.Context, project *types.Project) {
+	config, ok := project.Extensions[proxyExtension].(map[string]any)
+	if !ok {
+		return
+	}
+	info, err := s.apiClient().Info(ctx)
+	if err != nil {
+		logrus.Debugf("failed to check engine proxy configuration: %v", err)
+		return
+	}
+	if _, ok := config["http"]; ok && info.HTTPProxy == "" {
+		logrus.Warnf("%s declares an HTTP proxy but Docker engine isn't configured with one, images will be pulled without proxy", proxyExtension)
+	}
+	if _, ok := config["https"]; ok && info.HTTPSProxy == "" {
+		logrus.Warnf("%s declares an HTTPS proxy but Docker engine isn't configured with one, images will be pulled without proxy", proxyExtension)
+	}
+}
@@ -0,0 +1,48 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+)
+
+func TestProjectProxy(t *testing.T) {
+	project := &types.Project{
+		Extensions: types.Extensions{
+			proxyExtension: map[string]any{
+				"http":     "http://proxy:3128",
+				"no_proxy": "localhost,.corp",
+			},
+		},
+	}
+	assert.DeepEqual(t, projectProxy(project, types.ServiceConfig{Name: "web"}), types.Mapping{
+		"HTTP_PROXY": "http://proxy:3128",
+		"http_proxy": "http://proxy:3128",
+		"NO_PROXY":   "localhost,.corp",
+		"no_proxy":   "localhost,.corp",
+	})
+
+	optOut := types.ServiceConfig{
+		Name:       "internal",
+		Extensions: types.Extensions{proxyExtension: false},
+	}
+	assert.Equal(t, len(projectProxy(project, optOut)), 0)
+	assert.Equal(t, len(projectProxy(&types.Project{}, types.ServiceConfig{Name: "web"})), 0)
+}
@@ -18,6 +18,7 @@ package compose
 
 import (
 	"context"
+	"fmt"
 	"sort"
 	"strings"
 
@@ -99,6 +100,11 @@ func (s *composeService) Ps(ctx context.Context, projectName string, options api
 				}
 			}
 
+			status := container.Status
+			if isCrashLooping(inspect) {
+				status = fmt.Sprintf("Failed (crash loop, restarted %d times)", inspect.RestartCount)
+			}
+
 			summary[i] = api.ContainerSummary{
 				ID:           container.ID,
 				Name:         getCanonicalContainerName(container),
@@ -108,7 +114,7 @@ func (s *composeService) Ps(ctx context.Context, projectName string, options api
 				Service:      container.Labels[api.ServiceLabel],
 				Command:      container.Command,
 				State:        container.State,
-				Status:       container.Status,
+				Status:       status,
 				Created:      container.Created,
 				Labels:       container.Labels,
 				SizeRw:       container.SizeRw,
@@ -123,5 +129,19 @@ func (s *composeService) Ps(ctx context.Context, projectName string, options api
 			return nil
 		})
 	}
-	return summary, eg.Wait()
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	if options.HostProcesses && len(options.Services) == 0 {
+		processes, err := hostProcessSummaries(projectName)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range processes {
+			if options.All || p.State == "running" {
+				summary = append(summary, p)
+			}
+		}
+	}
+	return summary, nil
 }
@@ -20,10 +20,17 @@ import (
 	"bytes"
 	"context"
 	"crypto/sha256"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"maps"
+	"net/url"
 	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/DefangLabs/secret-detector/pkg/scanner"
 	"github.com/DefangLabs/secret-detector/pkg/secrets"
@@ -38,65 +45,93 @@ import (
 	"github.com/docker/compose/v2/pkg/compose/transform"
 	"github.com/docker/compose/v2/pkg/progress"
 	"github.com/docker/compose/v2/pkg/prompt"
+	"github.com/docker/compose/v2/pkg/remote"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
 )
 
 func (s *composeService) Publish(ctx context.Context, project *types.Project, repository string, options api.PublishOptions) error {
-	return progress.RunWithTitle(ctx, func(ctx context.Context) error {
-		return s.publish(ctx, project, repository, options)
+	var layers []ocipush.Pushable
+	err := progress.RunWithTitle(ctx, func(ctx context.Context) error {
+		var err error
+		layers, err = s.publish(ctx, project, repository, options)
+		return err
 	}, s.stdinfo(), "Publishing")
+	if err != nil || !s.dryRun || layers == nil {
+		return err
+	}
+	return printPublishPreview(s.stdout(), layers, options.Annotations)
 }
 
-func (s *composeService) publish(ctx context.Context, project *types.Project, repository string, options api.PublishOptions) error {
+func (s *composeService) publish(ctx context.Context, project *types.Project, repository string, options api.PublishOptions) ([]ocipush.Pushable, error) {
+	if options.TagOnly {
+		return nil, s.tagArtifact(ctx, repository)
+	}
 	accept, err := s.preChecks(project, options)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if !accept {
-		return nil
+		return nil, nil
 	}
-	err = s.Push(ctx, project, api.PushOptions{IgnoreFailures: true, ImageMandatory: true})
+	// with content, services can be built from the packaged build context
+	withBuildContext := options.WithContent || options.IncludeBuildContext
+	err = s.Push(ctx, project, api.PushOptions{IgnoreFailures: true, ImageMandatory: !withBuildContext})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	named, err := reference.ParseDockerRef(repository)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	resolver := imagetools.New(imagetools.Opt{
 		Auth: s.configFile(),
 	})
 
-	var layers []ocipush.Pushable
-	extFiles := map[string]string{}
-	for _, file := range project.ComposeFiles {
-		data, err := processFile(ctx, file, project, extFiles)
+	layers, err := composeFileLayers(ctx, project, options.Stdin)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.WithEnvironment {
+		layers = append(layers, envFileLayers(project)...)
+	}
+
+	if withBuildContext {
+		contents, err := contentLayers(project, !options.WithContent)
 		if err != nil {
-			return err
+			return nil, err
 		}
-
-		layerDescriptor := ocipush.DescriptorForComposeFile(file, data)
-		layers = append(layers, ocipush.Pushable{
-			Descriptor: layerDescriptor,
-			Data:       data,
-		})
+		layers = append(layers, contents...)
 	}
 
-	extLayers, err := processExtends(ctx, project, extFiles)
+	variants, err := variantLayers(options.Variants)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	layers = append(layers, extLayers...)
+	layers = append(layers, variants...)
 
-	if options.WithEnvironment {
-		layers = append(layers, envFileLayers(project)...)
+	if options.SetupFile != "" {
+		data, err := os.ReadFile(options.SetupFile)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := remote.ParseSetup(data); err != nil {
+			return nil, err
+		}
+		layers = append(layers, ocipush.Pushable{
+			Descriptor: ocipush.DescriptorForSetupFile(data),
+			Data:       data,
+		})
 	}
 
 	if options.ResolveImageDigests {
 		yaml, err := s.generateImageDigestsOverride(ctx, project)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		layerDescriptor := ocipush.DescriptorForComposeFile("image-digests.yaml", yaml)
@@ -106,21 +141,30 @@ func (s *composeService) publish(ctx context.Context, project *types.Project, re
 		})
 	}
 
+	if options.Provenance {
+		layer, err := ocipush.ProvenanceLayer(provenanceSource(ctx, project.WorkingDir), layers, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, layer)
+	}
+
 	w := progress.ContextWriter(ctx)
 	w.Event(progress.Event{
 		ID:     repository,
 		Text:   "publishing",
 		Status: progress.Working,
 	})
+	var descriptor v1.Descriptor
 	if !s.dryRun {
-		err = ocipush.PushManifest(ctx, resolver, named, layers, options.OCIVersion)
+		descriptor, err = ocipush.PushManifest(ctx, resolver, named, layers, options.OCIVersion, options.Annotations)
 		if err != nil {
 			w.Event(progress.Event{
 				ID:     repository,
 				Text:   "publishing",
 				Status: progress.Error,
 			})
-			return err
+			return nil, err
 		}
 	}
 	w.Event(progress.Event{
@@ -128,9 +172,198 @@ func (s *composeService) publish(ctx context.Context, project *types.Project, re
 		Text:   "published",
 		Status: progress.Done,
 	})
+	if options.Sign || options.SignKey != "" {
+		return layers, s.signArtifact(ctx, repository, named, descriptor, options.SignKey)
+	}
+	return layers, nil
+}
+
+// signArtifact signs the published manifest, and reports the signature reference
+func (s *composeService) signArtifact(ctx context.Context, repository string, named reference.Named, descriptor v1.Descriptor, key string) error {
+	w := progress.ContextWriter(ctx)
+	eventName := "Signature"
+	w.Event(progress.Event{
+		ID:       eventName,
+		ParentID: repository,
+		Text:     "Signing",
+		Status:   progress.Working,
+	})
+	if s.dryRun {
+		w.Event(progress.Event{
+			ID:       eventName,
+			ParentID: repository,
+			Text:     "Signed",
+			Status:   progress.Done,
+		})
+		return nil
+	}
+	digested, err := reference.WithDigest(reference.TrimNamed(named), descriptor.Digest)
+	if err != nil {
+		return err
+	}
+	signature, err := ocipush.Sign(ctx, digested, key, s.stderr())
+	if err != nil {
+		w.Event(progress.ErrorEvent(eventName))
+		return err
+	}
+	w.Event(progress.Event{
+		ID:         eventName,
+		ParentID:   repository,
+		Text:       "Signed",
+		StatusText: reference.FamiliarString(signature),
+		Status:     progress.Done,
+	})
+	return nil
+}
+
+// tagArtifact adds a tag to an artifact already published by digest, without pushing content again
+func (s *composeService) tagArtifact(ctx context.Context, repository string) error {
+	tagged, digested, err := parseTagOnlyReference(repository)
+	if err != nil {
+		return err
+	}
+
+	resolver := imagetools.New(imagetools.Opt{
+		Auth: s.configFile(),
+	})
+
+	content, descriptor, err := resolver.Get(ctx, digested.String())
+	if err != nil {
+		return err
+	}
+	var manifest v1.Manifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return err
+	}
+	if !ocipush.IsComposeArtifact(manifest) {
+		return fmt.Errorf("%s is not a compose project OCI artifact", digested.String())
+	}
+
+	w := progress.ContextWriter(ctx)
+	eventName := reference.FamiliarString(tagged)
+	w.Event(progress.Event{
+		ID:     eventName,
+		Text:   "Tagging",
+		Status: progress.Working,
+	})
+	if !s.dryRun {
+		if err := resolver.Push(ctx, tagged, descriptor, content); err != nil {
+			w.Event(progress.ErrorEvent(eventName))
+			return err
+		}
+	}
+	w.Event(progress.Event{
+		ID:     eventName,
+		Text:   "Tagged",
+		Status: progress.Done,
+	})
 	return nil
 }
 
+// parseTagOnlyReference splits a REPOSITORY:TAG@DIGEST reference into the tag to set and the artifact to tag
+func parseTagOnlyReference(repository string) (reference.NamedTagged, reference.Canonical, error) {
+	named, err := reference.ParseNormalizedNamed(repository)
+	if err != nil {
+		return nil, nil, err
+	}
+	tag, isTagged := named.(reference.Tagged)
+	dgst, isDigested := named.(reference.Digested)
+	if !isTagged || !isDigested {
+		return nil, nil, fmt.Errorf("--tag-only requires a REPOSITORY:TAG@DIGEST reference, got %s", repository)
+	}
+	tagged, err := reference.WithTag(reference.TrimNamed(named), tag.Tag())
+	if err != nil {
+		return nil, nil, err
+	}
+	digested, err := reference.WithDigest(reference.TrimNamed(named), dgst.Digest())
+	if err != nil {
+		return nil, nil, err
+	}
+	return tagged, digested, nil
+}
+
+// provenanceSource describes the git repository dir belongs to, or returns nil if it isn't a git working tree
+func provenanceSource(ctx context.Context, dir string) *ocipush.ProvenanceSource {
+	git := func(args ...string) (string, error) {
+		out, err := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...).Output()
+		return strings.TrimSpace(string(out)), err
+	}
+	commit, err := git("rev-parse", "HEAD")
+	if err != nil {
+		logrus.Debugf("no git source for provenance: %v", err)
+		return nil
+	}
+	source := &ocipush.ProvenanceSource{Commit: commit}
+	if remote, err := git("config", "--get", "remote.origin.url"); err == nil {
+		source.Repository = redactRemoteURL(remote)
+	}
+	if status, err := git("status", "--porcelain"); err == nil {
+		source.Dirty = status != ""
+	}
+	return source
+}
+
+// redactRemoteURL removes credentials from a git remote URL so they don't get published
+func redactRemoteURL(remote string) string {
+	u, err := url.Parse(remote)
+	if err != nil || u.User == nil || u.Scheme == "" {
+		return remote
+	}
+	u.User = nil
+	return u.String()
+}
+
+// isInMemoryProject tells if project wasn't loaded from Compose files on disk, like when read from stdin or
+// built programmatically
+func isInMemoryProject(project *types.Project) bool {
+	return len(project.ComposeFiles) == 0 || slices.Contains(project.ComposeFiles, "-")
+}
+
+// composeFileLayers packages the project Compose files. stdin is the raw content of the Compose file read from stdin,
+// published rather than the model which has already been interpolated
+func composeFileLayers(ctx context.Context, project *types.Project, stdin []byte) ([]ocipush.Pushable, error) {
+	if isInMemoryProject(project) && stdin == nil {
+		// extends and includes have already been resolved while loading the model
+		data, err := project.MarshalYAML()
+		if err != nil {
+			return nil, err
+		}
+		return []ocipush.Pushable{{
+			Descriptor: ocipush.DescriptorForComposeFile("compose.yaml", data),
+			Data:       data,
+		}}, nil
+	}
+
+	var layers []ocipush.Pushable
+	extFiles := map[string]string{}
+	for _, file := range project.ComposeFiles {
+		content, name := stdin, "compose.yaml"
+		if file != "-" {
+			var err error
+			if content, err = os.ReadFile(file); err != nil {
+				return nil, err
+			}
+			name = file
+		}
+		data, err := processContent(ctx, file, content, project, extFiles)
+		if err != nil {
+			return nil, err
+		}
+
+		layerDescriptor := ocipush.DescriptorForComposeFile(name, data)
+		layers = append(layers, ocipush.Pushable{
+			Descriptor: layerDescriptor,
+			Data:       data,
+		})
+	}
+
+	extLayers, err := processExtends(ctx, project, extFiles)
+	if err != nil {
+		return nil, err
+	}
+	return append(layers, extLayers...), nil
+}
+
 func processExtends(ctx context.Context, project *types.Project, extFiles map[string]string) ([]ocipush.Pushable, error) {
 	var layers []ocipush.Pushable
 	moreExtFiles := map[string]string{}
@@ -168,7 +401,11 @@ func processFile(ctx context.Context, file string, project *types.Project, extFi
 	if err != nil {
 		return nil, err
 	}
+	return processContent(ctx, file, f, project, extFiles)
+}
 
+// processContent rewrites the local files services of a Compose file extend, and records them in extFiles
+func processContent(ctx context.Context, file string, f []byte, project *types.Project, extFiles map[string]string) ([]byte, error) {
 	base, err := loader.LoadWithContext(ctx, types.ConfigDetails{
 		WorkingDir:  project.WorkingDir,
 		Environment: project.Environment,
@@ -233,11 +470,19 @@ func (s *composeService) generateImageDigestsOverride(ctx context.Context, proje
 
 //nolint:gocyclo
 func (s *composeService) preChecks(project *types.Project, options api.PublishOptions) (bool, error) {
-	if ok, err := s.checkOnlyBuildSection(project); !ok || err != nil {
+	// consumers rely on the readiness conditions to wait for the application, they must be valid
+	if _, err := readinessConditions(project); err != nil {
 		return false, err
 	}
-	if ok, err := s.checkForBindMount(project); !ok || err != nil {
-		return false, err
+	if !options.WithContent {
+		if !options.IncludeBuildContext {
+			if ok, err := s.checkOnlyBuildSection(project); !ok || err != nil {
+				return false, err
+			}
+		}
+		if ok, err := s.checkForBindMount(project); !ok || err != nil {
+			return false, err
+		}
 	}
 	if options.AssumeYes {
 		return true, nil
@@ -325,6 +570,29 @@ func acceptPublishSensitiveData(cli command.Cli) (bool, error) {
 	return confirm, err
 }
 
+// variantLayers reads the Compose files overriding the project for a platform or an environment
+func variantLayers(variants map[string]string) ([]ocipush.Pushable, error) {
+	var layers []ocipush.Pushable
+	for _, name := range slices.Sorted(maps.Keys(variants)) {
+		if err := remote.ValidateVariant(name); err != nil {
+			return nil, err
+		}
+		data, err := os.ReadFile(variants[name])
+		if err != nil {
+			return nil, err
+		}
+		var model map[string]any
+		if err := yaml.Unmarshal(data, &model); err != nil {
+			return nil, fmt.Errorf("variant %q: %w", name, err)
+		}
+		layers = append(layers, ocipush.Pushable{
+			Descriptor: ocipush.DescriptorForVariant(name, variants[name], data),
+			Data:       data,
+		})
+	}
+	return layers, nil
+}
+
 func envFileLayers(project *types.Project) []ocipush.Pushable {
 	var layers []ocipush.Pushable
 	for _, service := range project.Services {
@@ -376,7 +644,20 @@ func (s *composeService) checkForSensitiveData(project *types.Project) ([]secret
 	var allFindings []secrets.DetectedSecret
 	scan := scanner.NewDefaultScanner()
 	// Check all compose files
-	for _, file := range project.ComposeFiles {
+	composeFiles := project.ComposeFiles
+	if isInMemoryProject(project) {
+		composeFiles = nil
+		in, err := project.MarshalYAML()
+		if err != nil {
+			return nil, err
+		}
+		findings, err := scan.ScanReader(bytes.NewReader(in))
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan compose model: %w", err)
+		}
+		allFindings = append(allFindings, findings...)
+	}
+	for _, file := range composeFiles {
 		in, err := composeFileAsByteReader(file, project)
 		if err != nil {
 			return nil, err
@@ -0,0 +1,251 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/cli/cli/command/image/build"
+	"github.com/docker/docker/builder/remotecontext/urlutil"
+	"github.com/moby/go-archive"
+	"github.com/moby/patternmatcher"
+	"github.com/sirupsen/logrus"
+
+	"github.com/docker/compose/v2/internal/ocipush"
+)
+
+// publishedContent is a local file or directory referenced by the project, to be packaged in the published artifact
+type publishedContent struct {
+	// path relative to the project directory
+	path string
+	// excludes lists patterns of files not to package, read from .dockerignore for build contexts
+	excludes []string
+}
+
+// contentLayers packages local files the project references, build contexts, bind mounts sources and config files,
+// as tar layers so the published artifact is self-contained. Secrets and env files are never packaged.
+// With buildOnly, only build contexts and the Dockerfiles they rely on are packaged
+func contentLayers(project *types.Project, buildOnly bool) ([]ocipush.Pushable, error) {
+	contents, err := collectContent(project, buildOnly)
+	if err != nil {
+		return nil, err
+	}
+	var layers []ocipush.Pushable
+	for _, content := range contents {
+		source := filepath.Join(project.WorkingDir, content.path)
+		stat, err := os.Stat(source)
+		if err != nil {
+			return nil, err
+		}
+		dir, options := source, &archive.TarOptions{
+			Compression:     archive.Gzip,
+			ExcludePatterns: content.excludes,
+			ChownOpts:       &archive.ChownOpts{},
+		}
+		if !stat.IsDir() {
+			dir = filepath.Dir(source)
+			options.IncludeFiles = []string{filepath.Base(source)}
+		}
+		tar, err := archive.TarWithOptions(dir, options)
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(tar)
+		_ = tar.Close()
+		if err != nil {
+			return nil, err
+		}
+		rel, err := filepath.Rel(project.WorkingDir, dir)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, ocipush.Pushable{
+			Descriptor: ocipush.DescriptorForContent(filepath.ToSlash(rel), data),
+			Data:       data,
+		})
+	}
+	return layers, nil
+}
+
+// collectContent lists local paths referenced by project, skipping those already included by a parent directory.
+// Directories are packaged without the files their .dockerignore excludes, and files which may hold secrets, env files
+// and secrets sources, are never packaged whatever references them
+func collectContent(project *types.Project, buildOnly bool) ([]publishedContent, error) {
+	sensitive := sensitivePaths(project)
+	contents := map[string]publishedContent{}
+	// optional paths, like bind mounts sources the engine creates on demand, are skipped when missing
+	add := func(path string, referrer string, optional bool) error {
+		stat, err := os.Stat(path)
+		if err != nil {
+			if os.IsNotExist(err) && optional {
+				return nil
+			}
+			return err
+		}
+		rel, err := filepath.Rel(project.WorkingDir, path)
+		if err != nil || !filepath.IsLocal(rel) {
+			return fmt.Errorf("%s references %s, outside of the project directory, which can't be published", referrer, path)
+		}
+		if slices.Contains(sensitive, rel) {
+			logrus.Warnf("%s references %s, which is not published as it may hold secrets", referrer, rel)
+			return nil
+		}
+		content := contents[rel]
+		content.path = rel
+		if stat.IsDir() {
+			excludes, err := build.ReadDockerignore(path)
+			if err != nil {
+				return err
+			}
+			for _, exclude := range excludes {
+				if !slices.Contains(content.excludes, exclude) {
+					content.excludes = append(content.excludes, exclude)
+				}
+			}
+		}
+		contents[rel] = content
+		return nil
+	}
+
+	for _, service := range project.Services {
+		referrer := fmt.Sprintf("service %q", service.Name)
+		if service.Build != nil && service.Build.Context != "" && !urlutil.IsGitURL(service.Build.Context) && !urlutil.IsURL(service.Build.Context) {
+			if err := add(service.Build.Context, referrer, false); err != nil {
+				return nil, err
+			}
+			dockerfile := dockerFilePath(service.Build.Context, service.Build.Dockerfile)
+			if rel, err := filepath.Rel(service.Build.Context, dockerfile); err == nil && !filepath.IsLocal(rel) {
+				// Dockerfile is outside of build context
+				if err := add(dockerfile, referrer, false); err != nil {
+					return nil, err
+				}
+			}
+			for _, additional := range service.Build.AdditionalContexts {
+				if strings.Contains(additional, "://") || strings.HasPrefix(additional, types.ServicePrefix) || !filepath.IsAbs(additional) {
+					continue
+				}
+				if err := add(additional, referrer, false); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if buildOnly {
+			continue
+		}
+		for _, volume := range service.Volumes {
+			if volume.Type != types.VolumeTypeBind {
+				continue
+			}
+			if err := add(volume.Source, referrer, true); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for name, config := range project.Configs {
+		if buildOnly || config.File == "" {
+			continue
+		}
+		if err := add(config.File, fmt.Sprintf("config %q", name), false); err != nil {
+			return nil, err
+		}
+	}
+
+	paths := make([]string, 0, len(contents))
+	for path := range contents {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	var result []publishedContent
+	for _, path := range paths {
+		content := contents[path]
+		i := slices.IndexFunc(result, func(c publishedContent) bool {
+			return c.path == "." || strings.HasPrefix(path, c.path+string(filepath.Separator))
+		})
+		if i < 0 {
+			result = append(result, content)
+			continue
+		}
+		parent := &result[i]
+		nested, err := filepath.Rel(parent.path, path)
+		if err != nil {
+			return nil, err
+		}
+		matcher, err := patternmatcher.New(parent.excludes)
+		if err != nil {
+			return nil, err
+		}
+		if excluded, err := matcher.MatchesOrParentMatches(nested); err != nil || excluded {
+			// the parent directory doesn't package it, so it gets packaged on its own
+			result = append(result, content)
+			continue
+		}
+		for _, exclude := range content.excludes {
+			parent.excludes = append(parent.excludes, nestedPattern(nested, exclude))
+		}
+	}
+
+	for i, content := range result {
+		if content.path == "." {
+			logrus.Warn("The whole project directory is published, make sure .dockerignore excludes files which must not be shared")
+		}
+		// sensitive files are excluded last, so exception patterns can't include them back
+		for _, path := range sensitive {
+			if rel, err := filepath.Rel(content.path, path); err == nil && filepath.IsLocal(rel) {
+				result[i].excludes = append(result[i].excludes, filepath.ToSlash(rel))
+			}
+		}
+	}
+	return result, nil
+}
+
+// sensitivePaths lists files of the project directory which may hold secrets, relative to it: the .env file and git
+// metadata, env files and secrets sources
+func sensitivePaths(project *types.Project) []string {
+	paths := []string{".env", ".git"}
+	addPath := func(path string) {
+		if path == "" {
+			return
+		}
+		if rel, err := filepath.Rel(project.WorkingDir, path); err == nil && filepath.IsLocal(rel) && !slices.Contains(paths, rel) {
+			paths = append(paths, rel)
+		}
+	}
+	for _, service := range project.Services {
+		for _, envFile := range service.EnvFiles {
+			addPath(envFile.Path)
+		}
+	}
+	for _, secret := range project.Secrets {
+		addPath(secret.File)
+	}
+	return paths
+}
+
+// nestedPattern rewrites a .dockerignore pattern of a nested directory, so it applies from its parent directory
+func nestedPattern(dir string, pattern string) string {
+	if exception, ok := strings.CutPrefix(pattern, "!"); ok {
+		return "!" + path.Join(filepath.ToSlash(dir), exception)
+	}
+	return path.Join(filepath.ToSlash(dir), pattern)
+}
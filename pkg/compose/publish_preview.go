@@ -0,0 +1,102 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/compose-spec/compose-go/v2/dotenv"
+	"github.com/compose-spec/compose-go/v2/template"
+	"github.com/docker/compose/v2/internal/ocipush"
+	"github.com/docker/go-units"
+	"gopkg.in/yaml.v3"
+)
+
+// printPublishPreview lists the layers a dry-run would have published, with the annotations set on them and on the
+// manifest, so users can check no unexpected content is about to be published
+func printPublishPreview(out io.Writer, layers []ocipush.Pushable, annotations map[string]string) error {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	_, _ = fmt.Fprintln(w, "LAYER\tMEDIA TYPE\tSIZE\tANNOTATIONS")
+	for _, layer := range layers {
+		var set []string
+		for _, key := range slices.Sorted(maps.Keys(layer.Descriptor.Annotations)) {
+			if key != "com.docker.compose.version" {
+				set = append(set, key+"="+layer.Descriptor.Annotations[key])
+			}
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", ocipush.LayerName(layer.Descriptor), layer.Descriptor.MediaType,
+			units.HumanSize(float64(layer.Descriptor.Size)), strings.Join(set, ","))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	for _, key := range slices.Sorted(maps.Keys(annotations)) {
+		_, _ = fmt.Fprintf(out, "manifest annotation %s=%s\n", key, annotations[key])
+	}
+	warnings, err := interpolationWarnings(layers)
+	if err != nil {
+		return err
+	}
+	for _, warning := range warnings {
+		_, _ = fmt.Fprintf(out, "WARNING: %s\n", warning)
+	}
+	return nil
+}
+
+// interpolationWarnings reports variables the published Compose files require without a default value, as they
+// must be set where the artifact is deployed unless a published .env file sets them
+func interpolationWarnings(layers []ocipush.Pushable) ([]string, error) {
+	published := map[string]bool{}
+	for _, layer := range layers {
+		if layer.Descriptor.MediaType == ocipush.ComposeEnvFileMediaType && ocipush.LayerName(layer.Descriptor) == ".env" {
+			env, err := dotenv.UnmarshalBytesWithLookup(layer.Data, nil)
+			if err != nil {
+				return nil, fmt.Errorf(".env: %w", err)
+			}
+			for name := range env {
+				published[name] = true
+			}
+		}
+	}
+	var warnings []string
+	for _, layer := range layers {
+		switch layer.Descriptor.MediaType {
+		case ocipush.ComposeYAMLMediaType, ocipush.ComposeVariantMediaType:
+		default:
+			continue
+		}
+		var model map[string]any
+		if err := yaml.Unmarshal(layer.Data, &model); err != nil {
+			return nil, fmt.Errorf("%s: %w", ocipush.LayerName(layer.Descriptor), err)
+		}
+		variables := template.ExtractVariables(model, template.DefaultPattern)
+		for _, name := range slices.Sorted(maps.Keys(variables)) {
+			variable := variables[name]
+			if variable.DefaultValue != "" || variable.PresenceValue != "" || published[name] {
+				continue
+			}
+			warnings = append(warnings, fmt.Sprintf("%s references variable %s without a default value, it must be set where the artifact is deployed",
+				ocipush.LayerName(layer.Descriptor), name))
+		}
+	}
+	return warnings, nil
+}
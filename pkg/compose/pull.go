@@ -23,8 +23,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"sort"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/compose-spec/compose-go/v2/types"
@@ -33,10 +33,12 @@ import (
 	"github.com/docker/cli/cli/config/configfile"
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
 	"github.com/docker/docker/pkg/jsonmessage"
 	"github.com/docker/docker/registry"
 	"github.com/hashicorp/go-multierror"
 	"github.com/opencontainers/go-digest"
+	"github.com/sirupsen/logrus"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/docker/compose/v2/pkg/api"
@@ -54,6 +56,16 @@ func (s *composeService) pull(ctx context.Context, project *types.Project, opts
 	if err != nil {
 		return err
 	}
+	s.checkEngineProxy(ctx, project)
+
+	var peer client.APIClient
+	if opts.FromHost != "" {
+		peer, err = newPeerClient(opts.FromHost)
+		if err != nil {
+			return err
+		}
+		defer peer.Close() //nolint:errcheck
+	}
 
 	w := progress.ContextWriter(ctx)
 	eg, ctx := errgroup.WithContext(ctx)
@@ -66,7 +78,8 @@ func (s *composeService) pull(ctx context.Context, project *types.Project, opts
 	)
 
 	i := 0
-	for name, service := range project.Services {
+	for _, name := range pullOrder(project) {
+		service := project.Services[name]
 		if service.Image == "" {
 			w.Event(progress.Event{
 				ID:     name,
@@ -93,6 +106,18 @@ func (s *composeService) pull(ctx context.Context, project *types.Project, opts
 				})
 				continue
 			}
+		case api.PullPolicyMissingDigestCheck:
+			if _, ok := images[service.Image]; ok {
+				changed, err := s.imageDigestChanged(ctx, service)
+				if err == nil && !changed {
+					w.Event(progress.Event{
+						ID:     name,
+						Status: progress.Done,
+						Text:   "Skipped - Image is up to date",
+					})
+					continue
+				}
+			}
 		}
 
 		if service.Build != nil && opts.IgnoreBuildable {
@@ -117,7 +142,21 @@ func (s *composeService) pull(ctx context.Context, project *types.Project, opts
 
 		idx := i
 		eg.Go(func() error {
-			_, err := s.pullServiceImage(ctx, service, s.configFile(), w, opts.Quiet, project.Environment["DOCKER_DEFAULT_PLATFORM"])
+			if peer != nil {
+				loaded, err := s.loadServiceImageFromPeer(ctx, peer, service, w)
+				if err != nil {
+					w.Event(progress.Event{
+						ID:         name,
+						Status:     progress.Warning,
+						Text:       "Failed to load from peer",
+						StatusText: getUnwrappedErrorMessage(err),
+					})
+				}
+				if loaded {
+					return nil
+				}
+			}
+			_, err := s.pullServiceImage(ctx, service, s.configFile(), w, opts.Quiet, defaultPlatform(project))
 			if err != nil {
 				pullErrors[idx] = err
 				if service.Build != nil {
@@ -255,6 +294,36 @@ func (s *composeService) pullServiceImage(ctx context.Context, service types.Ser
 	return inspected.ID, nil
 }
 
+// imageDigestChanged checks if registry serves a distinct digest than the one local service image was pulled with
+func (s *composeService) imageDigestChanged(ctx context.Context, service types.ServiceConfig) (bool, error) {
+	ref, err := reference.ParseNormalizedNamed(service.Image)
+	if err != nil {
+		return false, err
+	}
+	remote, err := ImageDigestResolver(ctx, s.configFile(), s.apiClient())(ref)
+	if err != nil {
+		return false, err
+	}
+
+	inspect, err := s.apiClient().ImageInspect(ctx, service.Image)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	for _, repoDigest := range inspect.RepoDigests {
+		named, err := reference.ParseNormalizedNamed(repoDigest)
+		if err != nil {
+			continue
+		}
+		if canonical, ok := named.(reference.Canonical); ok && named.Name() == ref.Name() && canonical.Digest() == remote {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
 // ImageDigestResolver creates a func able to resolve image digest from a docker ref,
 func ImageDigestResolver(ctx context.Context, file *configfile.ConfigFile, apiClient client.APIClient) func(named reference.Named) (digest.Digest, error) {
 	return func(named reference.Named) (digest.Digest, error) {
@@ -290,30 +359,53 @@ func encodedAuth(ref reference.Named, configFile driver.Auth) (string, error) {
 	return base64.URLEncoding.EncodeToString(buf), nil
 }
 
-func (s *composeService) pullRequiredImages(ctx context.Context, project *types.Project, images map[string]api.ImageSummary, quietPull bool) error {
-	needPull := map[string]types.ServiceConfig{}
-	for name, service := range project.Services {
+func (s *composeService) pullRequiredImages(ctx context.Context, project *types.Project, images map[string]api.ImageSummary, quietPull bool, stream *pullStream) error {
+	var (
+		needPull []types.ServiceConfig
+		streamed []types.ServiceConfig
+	)
+	for _, name := range pullOrder(project) {
+		service := project.Services[name]
 		pull, err := mustPull(service, images)
 		if err != nil {
 			return err
 		}
-		if pull {
-			needPull[name] = service
+		if !pull && service.PullPolicy == api.PullPolicyMissingDigestCheck {
+			if _, ok := images[service.Image]; ok {
+				pull, err = s.imageDigestChanged(ctx, service)
+				if err != nil {
+					logrus.Warnf("service %q: failed to check image digest, using local image: %v", name, err)
+				}
+			}
+		}
+		switch {
+		case pull && stream != nil && !isServiceImageToBuild(service, project.Services):
+			// container creation will wait for this pull to complete
+			streamed = append(streamed, service)
+		case pull:
+			needPull = append(needPull, service)
 		}
 		for i, vol := range service.Volumes {
 			if vol.Type == types.VolumeTypeImage {
 				if _, ok := images[vol.Source]; !ok {
 					// Hack: create a fake ServiceConfig so we pull missing volume image
 					n := fmt.Sprintf("%s:volume %d", name, i)
-					needPull[n] = types.ServiceConfig{
+					needPull = append(needPull, types.ServiceConfig{
 						Name:  n,
 						Image: vol.Source,
-					}
+					})
 				}
 			}
 		}
 
 	}
+	if len(needPull) == 0 && len(streamed) == 0 {
+		return nil
+	}
+	s.checkEngineProxy(ctx, project)
+	if len(streamed) > 0 {
+		stream.start(ctx, s, project, streamed, quietPull)
+	}
 	if len(needPull) == 0 {
 		return nil
 	}
@@ -322,14 +414,11 @@ func (s *composeService) pullRequiredImages(ctx context.Context, project *types.
 		w := progress.ContextWriter(ctx)
 		eg, ctx := errgroup.WithContext(ctx)
 		eg.SetLimit(s.maxConcurrency)
-		pulledImages := map[string]api.ImageSummary{}
-		var mutex sync.Mutex
-		for name, service := range needPull {
+		pulledImages := make([]api.ImageSummary, len(needPull))
+		for i, service := range needPull {
 			eg.Go(func() error {
-				id, err := s.pullServiceImage(ctx, service, s.configFile(), w, quietPull, project.Environment["DOCKER_DEFAULT_PLATFORM"])
-				mutex.Lock()
-				defer mutex.Unlock()
-				pulledImages[name] = api.ImageSummary{
+				id, err := s.pullServiceImage(ctx, service, s.configFile(), w, quietPull, defaultPlatform(project))
+				pulledImages[i] = api.ImageSummary{
 					ID:          id,
 					Repository:  service.Image,
 					LastTagTime: time.Now(),
@@ -351,6 +440,16 @@ func (s *composeService) pullRequiredImages(ctx context.Context, project *types.
 	}, s.stdinfo())
 }
 
+// pullOrder returns services in the order they get started, so images for earliest-starting services arrive first
+func pullOrder(project *types.Project) []string {
+	order, err := StartOrder(project)
+	if err != nil {
+		order = project.ServiceNames()
+		sort.Strings(order)
+	}
+	return order
+}
+
 func mustPull(service types.ServiceConfig, images map[string]api.ImageSummary) (bool, error) {
 	if service.Provider != nil {
 		return false, nil
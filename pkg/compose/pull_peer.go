@@ -0,0 +1,116 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/cli/cli/connhelper"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/jsonmessage"
+
+	"github.com/docker/compose/v2/pkg/progress"
+)
+
+// newPeerClient creates an API client for the docker engine at host, typically a ssh:// address
+func newPeerClient(host string) (client.APIClient, error) {
+	helper, err := connhelper.GetConnectionHelper(host)
+	if err != nil {
+		return nil, err
+	}
+	opts := []client.Opt{client.WithAPIVersionNegotiation()}
+	if helper != nil {
+		opts = append(opts, client.WithHost(helper.Host), client.WithDialContext(helper.Dialer))
+	} else {
+		opts = append(opts, client.WithHost(host))
+	}
+	return client.NewClientWithOpts(opts...)
+}
+
+// loadServiceImageFromPeer streams service image from peer engine into the local one, as `docker save | docker load`
+// would do. It returns false if peer doesn't have the image, so that caller can fall back to pulling from registry
+func (s *composeService) loadServiceImageFromPeer(ctx context.Context, peer client.APIClient, service types.ServiceConfig, w progress.Writer) (bool, error) {
+	w.Event(progress.Event{
+		ID:     service.Name,
+		Status: progress.Working,
+		Text:   "Loading from peer",
+	})
+	remote, err := peer.ImageInspect(ctx, service.Image)
+	if errdefs.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	local, err := s.apiClient().ImageInspect(ctx, service.Image)
+	if err == nil && local.ID == remote.ID {
+		w.Event(progress.Event{
+			ID:     service.Name,
+			Status: progress.Done,
+			Text:   "Skipped - Image is up to date",
+		})
+		return true, nil
+	}
+
+	if !s.dryRun {
+		err = s.loadImage(ctx, peer, service.Image)
+		if err != nil {
+			return false, err
+		}
+	}
+	w.Event(progress.Event{
+		ID:     service.Name,
+		Status: progress.Done,
+		Text:   "Loaded from peer",
+	})
+	return true, nil
+}
+
+func (s *composeService) loadImage(ctx context.Context, peer client.APIClient, image string) error {
+	stream, err := peer.ImageSave(ctx, []string{image})
+	if err != nil {
+		return err
+	}
+	defer stream.Close() //nolint:errcheck
+
+	response, err := s.apiClient().ImageLoad(ctx, stream, client.ImageLoadWithQuiet(true))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close() //nolint:errcheck
+
+	dec := json.NewDecoder(response.Body)
+	for {
+		var jm jsonmessage.JSONMessage
+		if err := dec.Decode(&jm); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if jm.Error != nil {
+			return fmt.Errorf("failed to load %s: %s", image, jm.Error.Message)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/progress"
+)
+
+// pullStream pulls service images in background, in start order, so containers get created as soon as their own
+// image is available, rather than after all images have been pulled
+type pullStream struct {
+	pulls map[string]*streamedPull
+}
+
+type streamedPull struct {
+	done chan struct{}
+	id   string
+	err  error
+}
+
+func newPullStream() *pullStream {
+	return &pullStream{
+		pulls: map[string]*streamedPull{},
+	}
+}
+
+// start pulls images for services, which must be sorted by start order
+func (p *pullStream) start(ctx context.Context, s *composeService, project *types.Project, services []types.ServiceConfig, quietPull bool) {
+	for _, service := range services {
+		p.pulls[service.Name] = &streamedPull{done: make(chan struct{})}
+	}
+	w := progress.ContextWriter(ctx)
+	go func() {
+		var eg errgroup.Group
+		eg.SetLimit(s.maxConcurrency)
+		for _, service := range services {
+			pull := p.pulls[service.Name]
+			// Go blocks while the concurrency limit is reached, so pulls get started in order
+			eg.Go(func() error {
+				defer close(pull.done)
+				pull.id, pull.err = s.pullServiceImage(ctx, service, s.configFile(), w, quietPull, defaultPlatform(project))
+				return nil
+			})
+		}
+		_ = eg.Wait()
+	}()
+}
+
+// wait blocks until the image for service has been pulled, and returns the pulled image ID. A service which image
+// isn't pulled by stream is returned immediately
+func (p *pullStream) wait(ctx context.Context, service string) (string, error) {
+	if p == nil {
+		return "", nil
+	}
+	pull, ok := p.pulls[service]
+	if !ok {
+		return "", nil
+	}
+	select {
+	case <-pull.done:
+		return pull.id, pull.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// withPulledImage waits for service image and sets the image digest label used to detect outdated containers
+func (p *pullStream) withPulledImage(ctx context.Context, service *types.ServiceConfig) error {
+	id, err := p.wait(ctx, service.Name)
+	if err != nil {
+		return err
+	}
+	if id != "" {
+		service.CustomLabels = service.CustomLabels.Add(api.ImageDigestLabel, id)
+	}
+	return nil
+}
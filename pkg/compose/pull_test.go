@@ -0,0 +1,118 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/errdefs"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/mocks"
+	"github.com/docker/compose/v2/pkg/progress"
+)
+
+func TestLoadServiceImageFromPeer(t *testing.T) {
+	service := types.ServiceConfig{Name: "web", Image: "nginx:1.27"}
+	ctx := context.Background()
+	w := progress.ContextWriter(ctx)
+
+	t.Run("image missing on peer", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		_, cli := prepareMocks(mockCtrl)
+		peer := mocks.NewMockAPIClient(mockCtrl)
+		tested := composeService{dockerCli: cli}
+
+		peer.EXPECT().ImageInspect(ctx, "nginx:1.27").Return(image.InspectResponse{}, errdefs.NotFound(io.EOF))
+
+		loaded, err := tested.loadServiceImageFromPeer(ctx, peer, service, w)
+		assert.NilError(t, err)
+		assert.Check(t, !loaded)
+	})
+
+	t.Run("image up to date", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		apiClient, cli := prepareMocks(mockCtrl)
+		peer := mocks.NewMockAPIClient(mockCtrl)
+		tested := composeService{dockerCli: cli}
+
+		peer.EXPECT().ImageInspect(ctx, "nginx:1.27").Return(image.InspectResponse{ID: "sha256:123"}, nil)
+		apiClient.EXPECT().ImageInspect(ctx, "nginx:1.27").Return(image.InspectResponse{ID: "sha256:123"}, nil)
+
+		loaded, err := tested.loadServiceImageFromPeer(ctx, peer, service, w)
+		assert.NilError(t, err)
+		assert.Check(t, loaded)
+	})
+
+	t.Run("image loaded from peer", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		apiClient, cli := prepareMocks(mockCtrl)
+		peer := mocks.NewMockAPIClient(mockCtrl)
+		tested := composeService{dockerCli: cli}
+
+		archive := io.NopCloser(strings.NewReader("archive"))
+		peer.EXPECT().ImageInspect(ctx, "nginx:1.27").Return(image.InspectResponse{ID: "sha256:456"}, nil)
+		apiClient.EXPECT().ImageInspect(ctx, "nginx:1.27").Return(image.InspectResponse{ID: "sha256:123"}, nil)
+		peer.EXPECT().ImageSave(ctx, []string{"nginx:1.27"}).Return(archive, nil)
+		apiClient.EXPECT().ImageLoad(ctx, archive, gomock.Any()).Return(image.LoadResponse{
+			Body: io.NopCloser(strings.NewReader(`{"stream":"Loaded image: nginx:1.27"}`)),
+		}, nil)
+
+		loaded, err := tested.loadServiceImageFromPeer(ctx, peer, service, w)
+		assert.NilError(t, err)
+		assert.Check(t, loaded)
+	})
+}
+
+func TestPullOrder(t *testing.T) {
+	project := &types.Project{
+		Services: types.Services{
+			"web": {Name: "web", DependsOn: types.DependsOnConfig{"api": {Condition: types.ServiceConditionStarted, Required: true}}},
+			"api": {Name: "api", DependsOn: types.DependsOnConfig{"db": {Condition: types.ServiceConditionHealthy, Required: true}}},
+			"db":  {Name: "db"},
+		},
+	}
+	assert.DeepEqual(t, pullOrder(project), []string{"db", "api", "web"})
+}
+
+func TestPullStreamWait(t *testing.T) {
+	ctx := context.Background()
+	stream := newPullStream()
+	pull := &streamedPull{done: make(chan struct{}), id: "sha256:123"}
+	stream.pulls["db"] = pull
+	close(pull.done)
+
+	service := types.ServiceConfig{Name: "db"}
+	assert.NilError(t, stream.withPulledImage(ctx, &service))
+	assert.Equal(t, service.CustomLabels[api.ImageDigestLabel], "sha256:123")
+
+	id, err := stream.wait(ctx, "web")
+	assert.NilError(t, err)
+	assert.Equal(t, id, "")
+
+	var none *pullStream
+	id, err = none.wait(ctx, "db")
+	assert.NilError(t, err)
+	assert.Equal(t, id, "")
+}
@@ -0,0 +1,72 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gopkg.in/yaml.v3"
+)
+
+// readinessExtension declares the condition each service must meet for the application to be ready. `up --wait`
+// then waits for these rather than for all services, so authors of published applications define what ready means
+const readinessExtension = "x-ready"
+
+// readinessConditions returns the conditions declared by the project for services enabled, or nil if it declares
+// none of them
+func readinessConditions(project *types.Project) (types.DependsOnConfig, error) {
+	v, ok := project.Extensions[readinessExtension]
+	if !ok {
+		return nil, nil
+	}
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var declared map[string]string
+	decoder := yaml.NewDecoder(bytes.NewReader(b))
+	if err := decoder.Decode(&declared); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", readinessExtension, err)
+	}
+	depends := types.DependsOnConfig{}
+	for _, name := range slices.Sorted(maps.Keys(declared)) {
+		condition := declared[name]
+		switch condition {
+		case types.ServiceConditionStarted, types.ServiceConditionHealthy, types.ServiceConditionCompletedSuccessfully:
+		default:
+			return nil, fmt.Errorf("invalid %s: unsupported condition %q for service %s", readinessExtension, condition, name)
+		}
+		if _, ok := project.Services[name]; !ok {
+			if _, ok := project.DisabledServices[name]; ok {
+				continue
+			}
+			return nil, fmt.Errorf("invalid %s: no such service: %s", readinessExtension, name)
+		}
+		depends[name] = types.ServiceDependency{
+			Condition: condition,
+			Required:  true,
+		}
+	}
+	if len(depends) == 0 {
+		return nil, nil
+	}
+	return depends, nil
+}
@@ -0,0 +1,60 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+)
+
+func TestReadinessConditions(t *testing.T) {
+	project := &types.Project{
+		Services: types.Services{
+			"web":     {Name: "web"},
+			"migrate": {Name: "migrate"},
+			"db":      {Name: "db"},
+		},
+		DisabledServices: types.Services{
+			"debug": {Name: "debug"},
+		},
+	}
+	depends, err := readinessConditions(project)
+	assert.NilError(t, err)
+	assert.Check(t, depends == nil)
+
+	project.Extensions = types.Extensions{readinessExtension: map[string]any{
+		"web":     "service_healthy",
+		"migrate": "service_completed_successfully",
+		"debug":   "service_started",
+	}}
+	depends, err = readinessConditions(project)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, depends, types.DependsOnConfig{
+		"web":     {Condition: types.ServiceConditionHealthy, Required: true},
+		"migrate": {Condition: types.ServiceConditionCompletedSuccessfully, Required: true},
+	})
+
+	project.Extensions[readinessExtension] = map[string]any{"web": "service_ready"}
+	_, err = readinessConditions(project)
+	assert.Error(t, err, `invalid x-ready: unsupported condition "service_ready" for service web`)
+
+	project.Extensions[readinessExtension] = map[string]any{"api": "service_started"}
+	_, err = readinessConditions(project)
+	assert.Error(t, err, "invalid x-ready: no such service: api")
+}
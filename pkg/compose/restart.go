@@ -93,6 +93,10 @@ func (s *composeService) restart(ctx context.Context, projectName string, option
 				if err != nil {
 					return err
 				}
+				// traffic shaping rules are lost with the container network interface
+				if err := s.applyNetem(ctx, project, config, ctr); err != nil {
+					return err
+				}
 				w.Event(progress.StartedEvent(eventName))
 				return nil
 			})
@@ -0,0 +1,129 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	containerType "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+// crashLoopLogLines is the number of log lines shown to diagnose a crash looping service
+const crashLoopLogLines = 20
+
+// restartBreaker tracks restarts per service, to detect crash loops
+type restartBreaker struct {
+	limit    api.RestartLimit
+	restarts map[string][]time.Time
+}
+
+// newRestartBreaker returns a breaker enforcing limit, or nil if restarts are not limited
+func newRestartBreaker(limit api.RestartLimit) *restartBreaker {
+	if limit.Restarts <= 0 {
+		return nil
+	}
+	return &restartBreaker{limit: limit, restarts: map[string][]time.Time{}}
+}
+
+// record registers a restart of service at the given time, and tells if the service exceeded the restart limit
+func (b *restartBreaker) record(service string, at time.Time) bool {
+	if b == nil {
+		return false
+	}
+	recent := []time.Time{at}
+	for _, t := range b.restarts[service] {
+		if at.Sub(t) < b.limit.Window {
+			recent = append(recent, t)
+		}
+	}
+	b.restarts[service] = recent
+	return len(recent) > b.limit.Restarts
+}
+
+// breakRestartLoop disables the restart policy of a crash looping container and stops it, then reports the last lines
+// it logged to listener
+func (s *composeService) breakRestartLoop(ctx context.Context, inspected containerType.InspectResponse, name string, service string, limit api.RestartLimit, listener api.ContainerEventListener) error {
+	_, err := s.apiClient().ContainerUpdate(ctx, inspected.ID, containerType.UpdateConfig{
+		RestartPolicy: containerType.RestartPolicy{Name: containerType.RestartPolicyDisabled},
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.apiClient().ContainerStop(ctx, inspected.ID, containerType.StopOptions{}); err != nil {
+		return err
+	}
+
+	report := func(line string) {
+		listener(api.ContainerEvent{Type: api.ContainerEventErr, Container: name, ID: inspected.ID, Service: service, Line: line})
+	}
+	report(fmt.Sprintf("service %s restarted more than %d times within %s, giving up restarting it. Last logs:", service, limit.Restarts, limit.Window))
+	logs, err := s.apiClient().ContainerLogs(ctx, inspected.ID, containerType.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Tail:       fmt.Sprint(crashLoopLogLines),
+	})
+	if err != nil {
+		return err
+	}
+	defer logs.Close() //nolint:errcheck
+	var output bytes.Buffer
+	if inspected.Config != nil && inspected.Config.Tty {
+		_, err = io.Copy(&output, logs)
+	} else {
+		_, err = stdcopy.StdCopy(&output, &output, logs)
+	}
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(strings.TrimRight(output.String(), "\n"), "\n") {
+		report("  " + line)
+	}
+	return nil
+}
+
+// isCrashLooping tells if the container was stopped as crash looping: its restart policy was disabled after it
+// restarted, which the engine only does according to a restart policy
+func isCrashLooping(inspected containerType.InspectResponse) bool {
+	return inspected.HostConfig != nil && inspected.HostConfig.RestartPolicy.IsNone() && inspected.RestartCount > 0 &&
+		inspected.State != nil && !inspected.State.Running
+}
+
+// restoreRestartPolicy restores the restart policy of a container stopped as crash looping before it is started again
+func (s *composeService) restoreRestartPolicy(ctx context.Context, service types.ServiceConfig, ctr containerType.Summary) error {
+	policy := getRestartPolicy(service)
+	if policy.IsNone() {
+		return nil
+	}
+	inspected, err := s.apiClient().ContainerInspect(ctx, ctr.ID)
+	if err != nil {
+		return err
+	}
+	if !isCrashLooping(inspected) {
+		return nil
+	}
+	_, err = s.apiClient().ContainerUpdate(ctx, ctr.ID, containerType.UpdateConfig{RestartPolicy: policy})
+	return err
+}
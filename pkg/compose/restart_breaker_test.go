@@ -0,0 +1,59 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"testing"
+	"time"
+
+	containerType "github.com/docker/docker/api/types/container"
+	"gotest.tools/v3/assert"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+func TestRestartBreaker(t *testing.T) {
+	assert.Assert(t, newRestartBreaker(api.RestartLimit{}) == nil)
+	var disabled *restartBreaker
+	assert.Assert(t, !disabled.record("web", time.Now()))
+
+	breaker := newRestartBreaker(api.RestartLimit{Restarts: 3, Window: time.Minute})
+	start := time.Now()
+	for i := range 3 {
+		assert.Assert(t, !breaker.record("web", start.Add(time.Duration(i)*time.Second)))
+	}
+	assert.Assert(t, !breaker.record("db", start))
+	assert.Assert(t, breaker.record("web", start.Add(10*time.Second)))
+
+	// restarts older than the window are forgotten
+	assert.Assert(t, !breaker.record("db", start.Add(2*time.Minute)))
+	assert.Assert(t, !breaker.record("web", start.Add(2*time.Minute)))
+}
+
+func TestIsCrashLooping(t *testing.T) {
+	inspected := func(policy containerType.RestartPolicyMode, restarts int, running bool) containerType.InspectResponse {
+		return containerType.InspectResponse{ContainerJSONBase: &containerType.ContainerJSONBase{
+			HostConfig:   &containerType.HostConfig{RestartPolicy: containerType.RestartPolicy{Name: policy}},
+			RestartCount: restarts,
+			State:        &containerType.State{Running: running},
+		}}
+	}
+	assert.Assert(t, isCrashLooping(inspected(containerType.RestartPolicyDisabled, 6, false)))
+	assert.Assert(t, !isCrashLooping(inspected(containerType.RestartPolicyDisabled, 0, false)))
+	assert.Assert(t, !isCrashLooping(inspected(containerType.RestartPolicyAlways, 6, false)))
+	assert.Assert(t, !isCrashLooping(inspected(containerType.RestartPolicyDisabled, 6, true)))
+}
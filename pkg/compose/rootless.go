@@ -0,0 +1,175 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/docker/api/types/system"
+	"github.com/sirupsen/logrus"
+)
+
+// unprivilegedPortStart is the sysctl defining the lowest port an unprivileged process can bind
+const unprivilegedPortStart = "/proc/sys/net/ipv4/ip_unprivileged_port_start"
+
+// engineInfoCache holds the engine Info, queried once per composeService
+type engineInfoCache struct {
+	once sync.Once
+	val  system.Info
+	err  error
+}
+
+func (s *composeService) getEngineInfo(ctx context.Context) (system.Info, error) {
+	if s.engineInfo == nil {
+		return s.apiClient().Info(ctx)
+	}
+	s.engineInfo.once.Do(func() {
+		s.engineInfo.val, s.engineInfo.err = s.apiClient().Info(ctx)
+	})
+	return s.engineInfo.val, s.engineInfo.err
+}
+
+func isRootless(info system.Info) bool {
+	return slices.ContainsFunc(info.SecurityOptions, func(opt string) bool {
+		return strings.Contains(opt, "name=rootless")
+	})
+}
+
+// checkRootlessCompatibility adjusts project for options a rootless engine doesn't support, and reports them all at
+// once with a warning
+func (s *composeService) checkRootlessCompatibility(ctx context.Context, project *types.Project) error {
+	if s.dryRun {
+		return nil
+	}
+	info, err := s.getEngineInfo(ctx)
+	if err != nil || !isRootless(info) {
+		return err
+	}
+
+	var adjusted []string
+	for name, service := range project.Services {
+		for _, option := range unsupportedLimits(info, &service) {
+			adjusted = append(adjusted, fmt.Sprintf("service %q: %s ignored", name, option))
+		}
+		project.Services[name] = service
+	}
+	slices.Sort(adjusted)
+	if len(adjusted) > 0 {
+		logrus.Warnf("rootless engine lacks cgroup controllers (cgroup driver %q, version %s), resource limits are ignored:\n  %s",
+			info.CgroupDriver, info.CgroupVersion, strings.Join(adjusted, "\n  "))
+	}
+
+	// the unprivileged port range can only be read from the engine host
+	if host := s.dockerCli.DockerEndpoint().Host; !isLocalEngine(host) {
+		logrus.Debugf("engine %s isn't local, skipping privileged ports check", host)
+		return nil
+	}
+	portStart := lowestUnprivilegedPort()
+	if privileged := privilegedPorts(project, portStart); len(privileged) > 0 {
+		logrus.Warnf("rootless engine may fail to publish ports below %d:\n  %s\n"+
+			"unless rootlesskit has CAP_NET_BIND_SERVICE, set sysctl net.ipv4.ip_unprivileged_port_start, see "+
+			"https://docs.docker.com/engine/security/rootless/#exposing-privileged-ports",
+			portStart, strings.Join(privileged, "\n  "))
+	}
+	return nil
+}
+
+// privilegedPorts lists the ports published by project services below portStart
+func privilegedPorts(project *types.Project, portStart int) []string {
+	var privileged []string
+	for name, service := range project.Services {
+		for _, port := range service.Ports {
+			if published := publishedPort(port); published > 0 && published < portStart {
+				privileged = append(privileged, fmt.Sprintf("service %q publishes privileged port %d", name, published))
+			}
+		}
+	}
+	slices.Sort(privileged)
+	return privileged
+}
+
+// isLocalEngine tells if the engine at host runs on this machine, i.e. is reached through a unix socket or a named pipe
+func isLocalEngine(host string) bool {
+	return strings.HasPrefix(host, "unix://") || strings.HasPrefix(host, "npipe://")
+}
+
+// unsupportedLimits resets the service resource limits the engine can't enforce, and returns the matching attributes
+func unsupportedLimits(info system.Info, service *types.ServiceConfig) []string {
+	var options []string
+	var limits, reservations types.Resource
+	if service.Deploy != nil && service.Deploy.Resources.Limits != nil {
+		limits = *service.Deploy.Resources.Limits
+	}
+	if service.Deploy != nil && service.Deploy.Resources.Reservations != nil {
+		reservations = *service.Deploy.Resources.Reservations
+	}
+	if !info.MemoryLimit && (service.MemLimit != 0 || service.MemReservation != 0 || service.MemSwapLimit != 0 ||
+		limits.MemoryBytes != 0 || reservations.MemoryBytes != 0) {
+		options = append(options, "memory limits")
+		service.MemLimit, service.MemReservation, service.MemSwapLimit = 0, 0, 0
+		limits.MemoryBytes, reservations.MemoryBytes = 0, 0
+	}
+	if !info.CPUCfsQuota && (service.CPUS != 0 || service.CPUQuota != 0 || service.CPUPeriod != 0 || limits.NanoCPUs != 0) {
+		options = append(options, "cpu limits")
+		service.CPUS, service.CPUQuota, service.CPUPeriod = 0, 0, 0
+		limits.NanoCPUs = 0
+	}
+	if !info.PidsLimit && (service.PidsLimit != 0 || limits.Pids != 0) {
+		options = append(options, "pids limit")
+		service.PidsLimit = 0
+		limits.Pids = 0
+	}
+	if len(options) > 0 && service.Deploy != nil {
+		deploy := *service.Deploy
+		if deploy.Resources.Limits != nil {
+			deploy.Resources.Limits = &limits
+		}
+		if deploy.Resources.Reservations != nil {
+			deploy.Resources.Reservations = &reservations
+		}
+		service.Deploy = &deploy
+	}
+	return options
+}
+
+func publishedPort(port types.ServicePortConfig) int {
+	start, _, _ := strings.Cut(port.Published, "-")
+	p, err := strconv.Atoi(start)
+	if err != nil {
+		return 0
+	}
+	return p
+}
+
+func lowestUnprivilegedPort() int {
+	b, err := os.ReadFile(unprivilegedPortStart)
+	if err != nil {
+		return 1024
+	}
+	p, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err != nil {
+		return 1024
+	}
+	return p
+}
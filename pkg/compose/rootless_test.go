@@ -0,0 +1,77 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/docker/api/types/system"
+	"gotest.tools/v3/assert"
+)
+
+func TestUnsupportedLimits(t *testing.T) {
+	deploy := &types.DeployConfig{
+		Resources: types.Resources{
+			Limits: &types.Resource{NanoCPUs: 1.5, MemoryBytes: 1024, Pids: 10},
+		},
+	}
+	service := types.ServiceConfig{
+		Name:      "web",
+		MemLimit:  2048,
+		PidsLimit: 20,
+		Deploy:    deploy,
+	}
+	info := system.Info{MemoryLimit: false, CPUCfsQuota: true, PidsLimit: false}
+
+	options := unsupportedLimits(info, &service)
+	assert.DeepEqual(t, options, []string{"memory limits", "pids limit"})
+	assert.Equal(t, service.MemLimit, types.UnitBytes(0))
+	assert.Equal(t, service.PidsLimit, int64(0))
+	assert.DeepEqual(t, *service.Deploy.Resources.Limits, types.Resource{NanoCPUs: 1.5})
+	// original deploy config is left unchanged
+	assert.Equal(t, deploy.Resources.Limits.Pids, int64(10))
+
+	assert.Equal(t, len(unsupportedLimits(system.Info{MemoryLimit: true, CPUCfsQuota: true, PidsLimit: true}, &service)), 0)
+}
+
+func TestPublishedPort(t *testing.T) {
+	assert.Equal(t, publishedPort(types.ServicePortConfig{Published: "80"}), 80)
+	assert.Equal(t, publishedPort(types.ServicePortConfig{Published: "8000-8010"}), 8000)
+	assert.Equal(t, publishedPort(types.ServicePortConfig{}), 0)
+}
+
+func TestPrivilegedPorts(t *testing.T) {
+	project := &types.Project{
+		Services: types.Services{
+			"web": {Name: "web", Ports: []types.ServicePortConfig{{Published: "80"}, {Published: "8080"}}},
+			"dns": {Name: "dns", Ports: []types.ServicePortConfig{{Published: "53-54"}, {Target: 53}}},
+		},
+	}
+	assert.DeepEqual(t, privilegedPorts(project, 1024), []string{
+		`service "dns" publishes privileged port 53`,
+		`service "web" publishes privileged port 80`,
+	})
+	assert.Equal(t, len(privilegedPorts(project, 0)), 0)
+}
+
+func TestIsLocalEngine(t *testing.T) {
+	assert.Check(t, isLocalEngine("unix:///run/user/1000/docker.sock"))
+	assert.Check(t, isLocalEngine("npipe:////./pipe/docker_engine"))
+	assert.Check(t, !isLocalEngine("tcp://10.0.0.2:2376"))
+	assert.Check(t, !isLocalEngine("ssh://user@host"))
+}
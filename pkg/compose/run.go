@@ -32,6 +32,15 @@ import (
 )
 
 func (s *composeService) RunOneOffContainer(ctx context.Context, project *types.Project, opts api.RunOptions) (int, error) {
+	if opts.AuditLog != "" && !opts.Detach {
+		return s.withTranscript(ctx, "run", project.Name, opts, s.runTranscriptEnv(project, opts), func(s *composeService) (int, error) {
+			return s.runOneOffContainer(ctx, project, opts)
+		})
+	}
+	return s.runOneOffContainer(ctx, project, opts)
+}
+
+func (s *composeService) runOneOffContainer(ctx context.Context, project *types.Project, opts api.RunOptions) (int, error) {
 	containerID, err := s.prepareRun(ctx, project, opts)
 	if err != nil {
 		return 0, err
@@ -83,7 +92,7 @@ func (s *composeService) prepareRun(ctx context.Context, project *types.Project,
 		Add(api.SlugLabel, slug).
 		Add(api.OneoffLabel, "True")
 
-	if err := s.ensureImagesExists(ctx, project, opts.Build, opts.QuietPull); err != nil { // all dependencies already checked, but might miss service img
+	if err := s.ensureImagesExists(ctx, project, opts.Build, opts.QuietPull, nil); err != nil { // all dependencies already checked, but might miss service img
 		return "", err
 	}
 
@@ -146,17 +155,21 @@ func applyRunOptions(project *types.Project, service *types.ServiceConfig, opts
 		}
 	}
 	if len(opts.Environment) > 0 {
-		cmdEnv := types.NewMappingWithEquals(opts.Environment)
-		serviceOverrideEnv := cmdEnv.Resolve(func(s string) (string, bool) {
-			v, ok := envResolver(project.Environment)(s)
-			return v, ok
-		}).RemoveEmpty()
 		if service.Environment == nil {
 			service.Environment = types.MappingWithEquals{}
 		}
-		service.Environment.OverrideBy(serviceOverrideEnv)
+		service.Environment.OverrideBy(runEnvironment(project, opts))
 	}
 	for k, v := range opts.Labels {
 		service.Labels = service.Labels.Add(k, v)
 	}
 }
+
+// runEnvironment returns the environment variables set by the run command line, resolving those without a value from
+// the project environment
+func runEnvironment(project *types.Project, opts api.RunOptions) types.MappingWithEquals {
+	return types.NewMappingWithEquals(opts.Environment).Resolve(func(s string) (string, bool) {
+		v, ok := envResolver(project.Environment)(s)
+		return v, ok
+	}).RemoveEmpty()
+}
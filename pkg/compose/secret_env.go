@@ -0,0 +1,209 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/cli/cli/config"
+)
+
+// secretEnvExtension maps service environment variables to references into a secret store, as SCHEME://PATH#KEY.
+// Secrets are resolved when containers are created, so their values never appear in the Compose model
+const secretEnvExtension = "x-secret-env"
+
+// secretProvidersFile configures, in the docker config directory, the commands resolving secret references by scheme
+const secretProvidersFile = "secret-providers.json"
+
+// getServiceSecretEnv returns the secret references declared by service, indexed by environment variable
+func getServiceSecretEnv(service types.ServiceConfig) (map[string]*url.URL, error) {
+	v, ok := service.Extensions[secretEnvExtension]
+	if !ok {
+		return nil, nil
+	}
+	declared, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("service %s: %s must be a mapping of environment variables to secret references", service.Name, secretEnvExtension)
+	}
+	refs := map[string]*url.URL{}
+	for name, value := range declared {
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("service %s: %s.%s must be a secret reference", service.Name, secretEnvExtension, name)
+		}
+		ref, err := url.Parse(s)
+		if err != nil || ref.Scheme == "" || ref.Host == "" {
+			return nil, fmt.Errorf("service %s: invalid secret reference %q for %s, expected SCHEME://PATH#KEY", service.Name, s, name)
+		}
+		if _, ok := service.Environment[name]; ok {
+			return nil, fmt.Errorf("service %s: %s is declared by both environment and %s", service.Name, name, secretEnvExtension)
+		}
+		refs[name] = ref
+	}
+	return refs, nil
+}
+
+// checkSecretEnv validates secret references declared by services, so none is left without a provider to resolve it
+func (s *composeService) checkSecretEnv(project *types.Project) error {
+	var providers map[string][]string
+	for _, service := range project.Services {
+		refs, err := getServiceSecretEnv(service)
+		if err != nil {
+			return err
+		}
+		if len(refs) == 0 {
+			continue
+		}
+		if providers == nil {
+			providers, err = s.secretProviders()
+			if err != nil {
+				return err
+			}
+		}
+		for name, ref := range refs {
+			if _, ok := providers[ref.Scheme]; !ok && ref.Scheme != "vault" {
+				return fmt.Errorf("service %s: no secret provider configured for %s to resolve %s", service.Name, ref.Scheme, name)
+			}
+		}
+	}
+	return nil
+}
+
+// resolveSecretEnv resolves the secret references declared by service into environment variables
+func (s *composeService) resolveSecretEnv(ctx context.Context, project *types.Project, service types.ServiceConfig) (types.MappingWithEquals, error) {
+	refs, err := getServiceSecretEnv(service)
+	if err != nil || len(refs) == 0 {
+		return nil, err
+	}
+	providers, err := s.secretProviders()
+	if err != nil {
+		return nil, err
+	}
+	env := types.MappingWithEquals{}
+	for name, ref := range refs {
+		var value string
+		if command, ok := providers[ref.Scheme]; ok {
+			value, err = commandSecret(ctx, command, ref)
+		} else if ref.Scheme == "vault" {
+			value, err = vaultSecret(ctx, project.Environment, ref)
+		} else {
+			err = fmt.Errorf("no secret provider configured for %s", ref.Scheme)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("service %s: resolving %s: %w", service.Name, name, err)
+		}
+		env[name] = &value
+	}
+	return env, nil
+}
+
+// secretProviders reads the commands resolving secret references, indexed by scheme
+func (s *composeService) secretProviders() (map[string][]string, error) {
+	dir := config.Dir()
+	if file := s.configFile(); file != nil && file.Filename != "" {
+		dir = filepath.Dir(file.Filename)
+	}
+	file := filepath.Join(dir, "compose", secretProvidersFile)
+	b, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return map[string][]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var providers map[string][]string
+	if err := json.Unmarshal(b, &providers); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	for scheme, command := range providers {
+		if len(command) == 0 {
+			return nil, fmt.Errorf("%s: no command set for %s secrets", file, scheme)
+		}
+	}
+	return providers, nil
+}
+
+// commandSecret runs command with ref as last argument, and returns its output as the secret value
+func commandSecret(ctx context.Context, command []string, ref *url.URL) (string, error) {
+	cmd := exec.CommandContext(ctx, command[0], append(slices.Clone(command[1:]), ref.String())...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w: %s", command[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
+// vaultSecret reads a key of a HashiCorp Vault secret, referenced as vault://PATH#KEY, from the server set by
+// VAULT_ADDR with VAULT_TOKEN. Both KV v1 and v2 secret engines are supported
+func vaultSecret(ctx context.Context, environment types.Mapping, ref *url.URL) (string, error) {
+	addr, token := environment["VAULT_ADDR"], environment["VAULT_TOKEN"]
+	if addr == "" || token == "" {
+		return "", fmt.Errorf("VAULT_ADDR and VAULT_TOKEN must be set to resolve %s", ref.Redacted())
+	}
+	if ref.Fragment == "" {
+		return "", fmt.Errorf("%s must select a key of the secret, as vault://PATH#KEY", ref.Redacted())
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(addr, "/")+"/v1/"+ref.Host+ref.Path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if namespace := environment["VAULT_NAMESPACE"]; namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned %s for %s", resp.Status, ref.Host+ref.Path)
+	}
+	var secret struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return "", err
+	}
+	data := secret.Data
+	if nested, ok := data["data"].(map[string]any); ok {
+		// KV v2 wraps the secret with its metadata
+		data = nested
+	}
+	value, ok := data[ref.Fragment]
+	if !ok {
+		return "", fmt.Errorf("secret %s has no key %s", ref.Host+ref.Path, ref.Fragment)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	b, err := json.Marshal(value)
+	return string(b), err
+}
@@ -0,0 +1,108 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+)
+
+func TestGetServiceSecretEnv(t *testing.T) {
+	refs, err := getServiceSecretEnv(types.ServiceConfig{Name: "api"})
+	assert.NilError(t, err)
+	assert.Assert(t, refs == nil)
+
+	refs, err = getServiceSecretEnv(types.ServiceConfig{Name: "api", Extensions: types.Extensions{secretEnvExtension: map[string]any{
+		"API_KEY": "vault://secret/data/api#key",
+	}}})
+	assert.NilError(t, err)
+	assert.Equal(t, refs["API_KEY"].Scheme, "vault")
+	assert.Equal(t, refs["API_KEY"].Fragment, "key")
+
+	_, err = getServiceSecretEnv(types.ServiceConfig{Name: "api", Extensions: types.Extensions{secretEnvExtension: map[string]any{
+		"API_KEY": "s3cr3t",
+	}}})
+	assert.Error(t, err, `service api: invalid secret reference "s3cr3t" for API_KEY, expected SCHEME://PATH#KEY`)
+
+	value := "plain"
+	_, err = getServiceSecretEnv(types.ServiceConfig{
+		Name:        "api",
+		Environment: types.MappingWithEquals{"API_KEY": &value},
+		Extensions:  types.Extensions{secretEnvExtension: map[string]any{"API_KEY": "vault://secret/api#key"}},
+	})
+	assert.Error(t, err, "service api: API_KEY is declared by both environment and x-secret-env")
+}
+
+func TestVaultSecret(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "t0ken" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/api":
+			_, _ = w.Write([]byte(`{"data": {"data": {"key": "s3cr3t", "port": 8443}, "metadata": {"version": 3}}}`))
+		case "/v1/kv/api":
+			_, _ = w.Write([]byte(`{"data": {"key": "v1-s3cr3t"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	env := types.Mapping{"VAULT_ADDR": server.URL, "VAULT_TOKEN": "t0ken"}
+
+	resolve := func(env types.Mapping, ref string) (string, error) {
+		u, err := url.Parse(ref)
+		assert.NilError(t, err)
+		return vaultSecret(context.Background(), env, u)
+	}
+	value, err := resolve(env, "vault://secret/data/api#key")
+	assert.NilError(t, err)
+	assert.Equal(t, value, "s3cr3t")
+	value, err = resolve(env, "vault://secret/data/api#port")
+	assert.NilError(t, err)
+	assert.Equal(t, value, "8443")
+	value, err = resolve(env, "vault://kv/api#key")
+	assert.NilError(t, err)
+	assert.Equal(t, value, "v1-s3cr3t")
+
+	_, err = resolve(env, "vault://secret/data/api#missing")
+	assert.Error(t, err, "secret secret/data/api has no key missing")
+	_, err = resolve(env, "vault://secret/data/api")
+	assert.ErrorContains(t, err, "must select a key of the secret")
+	_, err = resolve(env, "vault://secret/data/other#key")
+	assert.Error(t, err, "vault returned 404 Not Found for secret/data/other")
+	_, err = resolve(types.Mapping{}, "vault://secret/data/api#key")
+	assert.ErrorContains(t, err, "VAULT_ADDR and VAULT_TOKEN must be set")
+}
+
+func TestCommandSecret(t *testing.T) {
+	ref, err := url.Parse("op://vault/item/password")
+	assert.NilError(t, err)
+	value, err := commandSecret(context.Background(), []string{"echo", "-n"}, ref)
+	assert.NilError(t, err)
+	assert.Equal(t, value, "op://vault/item/password")
+
+	_, err = commandSecret(context.Background(), []string{"false"}, ref)
+	assert.ErrorContains(t, err, "false: exit status 1")
+}
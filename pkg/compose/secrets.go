@@ -21,48 +21,182 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"os"
 	"strconv"
 	"time"
 
 	"github.com/compose-spec/compose-go/v2/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/sirupsen/logrus"
+
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/watch"
+)
+
+const (
+	// secretHotReloadExtension marks a file secret to be copied into containers rather than bind mounted,
+	// so that it can be updated in running containers when file changes on disk
+	secretHotReloadExtension = "x-hot-reload"
+	// secretsReloadSignalExtension sets the signal sent to service containers after a secret has been updated
+	secretsReloadSignalExtension = "x-secrets-reload-signal"
 )
 
+func isHotReloadSecret(secret types.SecretConfig) bool {
+	if secret.File == "" {
+		return false
+	}
+	hotReload, ok := secret.Extensions[secretHotReloadExtension].(bool)
+	return ok && hotReload
+}
+
 func (s *composeService) injectSecrets(ctx context.Context, project *types.Project, service types.ServiceConfig, id string) error {
 	for _, config := range service.Secrets {
 		file := project.Secrets[config.Source]
-		if file.Environment == "" {
+		if file.Environment == "" && !isHotReloadSecret(file) {
 			continue
 		}
-
-		if service.ReadOnly {
-			return fmt.Errorf("cannot create secret %q in read-only service %s: `file` is the sole supported option", file.Name, service.Name)
+		err := s.injectSecret(ctx, project, service, config, id)
+		if err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		if config.Target == "" {
-			config.Target = "/run/secrets/" + config.Source
-		} else if !isAbsTarget(config.Target) {
-			config.Target = "/run/secrets/" + config.Target
+func (s *composeService) injectSecret(ctx context.Context, project *types.Project, service types.ServiceConfig, config types.ServiceSecretConfig, id string) error {
+	file := project.Secrets[config.Source]
+	if service.ReadOnly {
+		return fmt.Errorf("cannot create secret %q in read-only service %s: `file` is the sole supported option", file.Name, service.Name)
+	}
+
+	if config.Target == "" {
+		config.Target = "/run/secrets/" + config.Source
+	} else if !isAbsTarget(config.Target) {
+		config.Target = "/run/secrets/" + config.Target
+	}
+
+	content := file.Content
+	switch {
+	case file.File != "":
+		b, err := os.ReadFile(file.File)
+		if err != nil {
+			return err
 		}
+		content = string(b)
+	case content == "":
+		env, ok := project.Environment[file.Environment]
+		if !ok {
+			return fmt.Errorf("environment variable %q required by secret %q is not set", file.Environment, file.Name)
+		}
+		content = env
+	}
+	b, err := createTar(content, types.FileReferenceConfig(config))
+	if err != nil {
+		return err
+	}
 
-		content := file.Content
-		if content == "" {
-			env, ok := project.Environment[file.Environment]
-			if !ok {
-				return fmt.Errorf("environment variable %q required by secret %q is not set", file.Environment, file.Name)
+	return s.apiClient().CopyToContainer(ctx, id, "/", &b, container.CopyToContainerOptions{
+		CopyUIDGID: config.UID != "" || config.GID != "",
+	})
+}
+
+// hotReloadSecrets maps files on disk to the hot-reload secrets they are the source for
+func hotReloadSecrets(project *types.Project) map[string][]string {
+	files := map[string][]string{}
+	for name, secret := range project.Secrets {
+		if !isHotReloadSecret(secret) {
+			continue
+		}
+		used := false
+		for _, service := range project.Services {
+			for _, config := range service.Secrets {
+				used = used || config.Source == name
 			}
-			content = env
 		}
-		b, err := createTar(content, types.FileReferenceConfig(config))
-		if err != nil {
-			return err
+		if used {
+			files[secret.File] = append(files[secret.File], name)
 		}
+	}
+	return files
+}
 
-		err = s.apiClient().CopyToContainer(ctx, id, "/", &b, container.CopyToContainerOptions{
-			CopyUIDGID: config.UID != "" || config.GID != "",
-		})
-		if err != nil {
+// watchSecrets updates hot-reload secrets in running containers when their source file changes
+func (s *composeService) watchSecrets(ctx context.Context, project *types.Project, options api.WatchOptions) error {
+	files := hotReloadSecrets(project)
+	if len(files) == 0 {
+		return nil
+	}
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	backend, err := watch.DefaultBackend()
+	if err != nil {
+		return err
+	}
+	watcher, err := watch.NewWatcherWithBackends(map[watch.Backend][]string{backend: paths})
+	if err != nil {
+		return err
+	}
+	err = watcher.Start()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := watcher.Close(); err != nil {
+			logrus.Debugf("Error closing secrets watcher: %v", err)
+		}
+	}()
+
+	batchEvents := watch.BatchDebounceEvents(ctx, s.clock, watcher.Events())
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-watcher.Errors():
 			return err
+		case batch := <-batchEvents:
+			secrets := map[string]bool{}
+			for _, event := range batch {
+				for _, name := range files[string(event)] {
+					secrets[name] = true
+				}
+			}
+			for name := range secrets {
+				err := s.reloadSecret(ctx, project, name, options)
+				if err != nil {
+					options.LogTo.Err(api.WatchLogger, fmt.Sprintf("Failed to reload secret %s: %v", name, err))
+				}
+			}
+		}
+	}
+}
+
+// reloadSecret copies updated secret content into containers for services using it, then sends the reload signal if set
+func (s *composeService) reloadSecret(ctx context.Context, project *types.Project, name string, options api.WatchOptions) error {
+	for _, service := range project.Services {
+		for _, config := range service.Secrets {
+			if config.Source != name {
+				continue
+			}
+			containers, err := s.getContainers(ctx, project.Name, oneOffExclude, false, service.Name)
+			if err != nil {
+				return err
+			}
+			signal, _ := service.Extensions[secretsReloadSignalExtension].(string)
+			for _, ctr := range containers {
+				err := s.injectSecret(ctx, project, service, config, ctr.ID)
+				if err != nil {
+					return err
+				}
+				if signal != "" {
+					err = s.apiClient().ContainerKill(ctx, ctr.ID, signal)
+					if err != nil {
+						return err
+					}
+				}
+			}
+			options.LogTo.Log(api.WatchLogger, fmt.Sprintf("Secret %s updated in service %q", name, service.Name))
 		}
 	}
 	return nil
@@ -82,6 +216,13 @@ func (s *composeService) injectConfigs(ctx context.Context, project *types.Proje
 		if content == "" {
 			continue
 		}
+		if isTemplateConfig(file) {
+			var err error
+			content, err = renderConfigTemplate(project, service.Name, file.Name, content)
+			if err != nil {
+				return err
+			}
+		}
 
 		if service.ReadOnly {
 			return fmt.Errorf("cannot create config %q in read-only service %s: `file` is the sole supported option", file.Name, service.Name)
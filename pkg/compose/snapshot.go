@@ -0,0 +1,471 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/progress"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	snapshotManifestFile = "snapshot.json"
+	snapshotConfigFile   = "compose.yaml"
+	snapshotImagesFile   = "images.tar"
+	snapshotVolumesDir   = "volumes"
+)
+
+// snapshotManifest describes the project resources saved in a snapshot bundle
+type snapshotManifest struct {
+	Project    string              `json:"project"`
+	Created    time.Time           `json:"created"`
+	Containers []snapshotContainer `json:"containers"`
+	Volumes    []snapshotVolume    `json:"volumes,omitempty"`
+	Networks   []snapshotNetwork   `json:"networks,omitempty"`
+}
+
+type snapshotContainer struct {
+	ID         string                               `json:"id"`
+	Name       string                               `json:"name"`
+	Image      string                               `json:"image"`
+	Running    bool                                 `json:"running"`
+	Config     *container.Config                    `json:"config"`
+	HostConfig *container.HostConfig                `json:"hostConfig"`
+	Networks   map[string]*network.EndpointSettings `json:"networks,omitempty"`
+}
+
+type snapshotVolume struct {
+	Name    string            `json:"name"`
+	Driver  string            `json:"driver"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Options map[string]string `json:"options,omitempty"`
+	// Container is the ID of the container used to copy volume content, mounting volume on Path
+	Container string `json:"container"`
+	Path      string `json:"path"`
+}
+
+type snapshotNetwork struct {
+	Name    string                `json:"name"`
+	Options network.CreateOptions `json:"options"`
+}
+
+func (s *composeService) Snapshot(ctx context.Context, projectName string, options api.SnapshotOptions) error {
+	return progress.RunWithTitle(ctx, func(ctx context.Context) error {
+		return s.snapshot(ctx, projectName, options)
+	}, s.stdinfo(), "Saving snapshot")
+}
+
+func (s *composeService) snapshot(ctx context.Context, projectName string, options api.SnapshotOptions) error {
+	projectName = strings.ToLower(projectName)
+	containers, err := s.getContainers(ctx, projectName, oneOffExclude, true)
+	if err != nil {
+		return err
+	}
+	if len(containers) == 0 {
+		return fmt.Errorf("no container found for project %q", projectName)
+	}
+	containers.sorted()
+
+	now := time.Now()
+	tag := now.Format("20060102150405")
+	if options.Output == "" {
+		options.Output = fmt.Sprintf("%s-snapshot-%s", projectName, tag)
+	}
+	if s.dryRun {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Join(options.Output, snapshotVolumesDir), 0o700); err != nil {
+		return err
+	}
+
+	// pause running containers so filesystems and volumes are captured in a consistent state
+	var paused []string
+	unpause := func() {
+		for _, id := range paused {
+			_ = s.apiClient().ContainerUnpause(context.WithoutCancel(ctx), id)
+		}
+		paused = nil
+	}
+	defer unpause()
+	for _, ctr := range containers {
+		if ctr.State == ContainerRunning {
+			if err := s.apiClient().ContainerPause(ctx, ctr.ID); err != nil {
+				return err
+			}
+			paused = append(paused, ctr.ID)
+		}
+	}
+
+	manifest := snapshotManifest{
+		Project: projectName,
+		Created: now,
+	}
+	w := progress.ContextWriter(ctx)
+	var images []string
+	for _, ctr := range containers {
+		eventName := getContainerProgressName(ctr)
+		w.Event(progress.NewEvent(eventName, progress.Working, "Saving"))
+		inspect, err := s.apiClient().ContainerInspect(ctx, ctr.ID)
+		if err != nil {
+			return err
+		}
+		ref := fmt.Sprintf("%s-snapshot/%s:%s", projectName, strings.ToLower(getContainerNameWithoutProject(ctr)), tag)
+		if _, err := s.apiClient().ContainerCommit(ctx, ctr.ID, container.CommitOptions{
+			Reference: ref,
+			Comment:   fmt.Sprintf("snapshot of %s", getCanonicalContainerName(ctr)),
+		}); err != nil {
+			return err
+		}
+		images = append(images, ref)
+
+		networks := map[string]*network.EndpointSettings{}
+		for name, endpoint := range inspect.NetworkSettings.Networks {
+			// only keep user settings, runtime ones will be set by engine
+			networks[name] = &network.EndpointSettings{
+				Aliases:    endpoint.Aliases,
+				Links:      endpoint.Links,
+				IPAMConfig: endpoint.IPAMConfig,
+				DriverOpts: endpoint.DriverOpts,
+			}
+		}
+		manifest.Containers = append(manifest.Containers, snapshotContainer{
+			ID:         ctr.ID,
+			Name:       getCanonicalContainerName(ctr),
+			Image:      ref,
+			Running:    ctr.State == ContainerRunning,
+			Config:     inspect.Config,
+			HostConfig: inspect.HostConfig,
+			Networks:   networks,
+		})
+		w.Event(progress.NewEvent(eventName, progress.Done, "Saved"))
+	}
+
+	volumes, err := s.apiClient().VolumeList(ctx, volume.ListOptions{
+		Filters: filters.NewArgs(projectFilter(projectName)),
+	})
+	if err != nil {
+		return err
+	}
+	for _, vol := range volumes.Volumes {
+		ctr, target, ok := volumeMountedBy(containers, vol.Name)
+		if !ok {
+			logrus.Warnf("volume %q isn't used by any container, its content is not saved", vol.Name)
+			continue
+		}
+		eventName := fmt.Sprintf("Volume %s", vol.Name)
+		w.Event(progress.NewEvent(eventName, progress.Working, "Saving"))
+		if err := s.saveVolumeContent(ctx, ctr.ID, target, filepath.Join(options.Output, snapshotVolumesDir, vol.Name+".tar")); err != nil {
+			return err
+		}
+		manifest.Volumes = append(manifest.Volumes, snapshotVolume{
+			Name:      vol.Name,
+			Driver:    vol.Driver,
+			Labels:    vol.Labels,
+			Options:   vol.Options,
+			Container: ctr.ID,
+			Path:      target,
+		})
+		w.Event(progress.NewEvent(eventName, progress.Done, "Saved"))
+	}
+	unpause()
+
+	networks, err := s.apiClient().NetworkList(ctx, network.ListOptions{
+		Filters: filters.NewArgs(projectFilter(projectName)),
+	})
+	if err != nil {
+		return err
+	}
+	for _, n := range networks {
+		manifest.Networks = append(manifest.Networks, snapshotNetwork{
+			Name: n.Name,
+			Options: network.CreateOptions{
+				Driver:     n.Driver,
+				EnableIPv6: &n.EnableIPv6,
+				IPAM:       &n.IPAM,
+				Internal:   n.Internal,
+				Attachable: n.Attachable,
+				Options:    n.Options,
+				Labels:     n.Labels,
+			},
+		})
+	}
+
+	if err := s.saveSnapshotImages(ctx, images, filepath.Join(options.Output, snapshotImagesFile)); err != nil {
+		return err
+	}
+
+	if options.Project != nil {
+		config, err := options.Project.MarshalYAML()
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(options.Output, snapshotConfigFile), config, 0o600); err != nil {
+			return err
+		}
+	}
+
+	b, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(options.Output, snapshotManifestFile), b, 0o600); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(s.stdinfo(), "Snapshot saved to %s\n", options.Output)
+	return nil
+}
+
+// volumeMountedBy selects a container mounting a volume, and the path it is mounted on
+func volumeMountedBy(containers Containers, name string) (container.Summary, string, bool) {
+	for _, ctr := range containers {
+		for _, m := range ctr.Mounts {
+			if m.Type == "volume" && m.Name == name {
+				return ctr, m.Destination, true
+			}
+		}
+	}
+	return container.Summary{}, "", false
+}
+
+func (s *composeService) saveVolumeContent(ctx context.Context, containerID string, target string, file string) error {
+	content, _, err := s.apiClient().CopyFromContainer(ctx, containerID, target)
+	if err != nil {
+		return err
+	}
+	defer content.Close() //nolint:errcheck
+	return writeSnapshotFile(file, content)
+}
+
+func (s *composeService) saveSnapshotImages(ctx context.Context, images []string, file string) error {
+	content, err := s.apiClient().ImageSave(ctx, images)
+	if err != nil {
+		return err
+	}
+	defer content.Close() //nolint:errcheck
+	if err := writeSnapshotFile(file, content); err != nil {
+		return err
+	}
+	// images are saved in the bundle, no need to keep them around
+	for _, ref := range images {
+		if _, err := s.apiClient().ImageRemove(ctx, ref, image.RemoveOptions{}); err != nil {
+			logrus.Debugf("failed to remove snapshot image %s: %v", ref, err)
+		}
+	}
+	return nil
+}
+
+func writeSnapshotFile(file string, content io.Reader) error {
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close() //nolint:errcheck
+	_, err = io.Copy(f, content)
+	return err
+}
+
+func (s *composeService) RestoreSnapshot(ctx context.Context, projectName string, options api.RestoreSnapshotOptions) error {
+	return progress.RunWithTitle(ctx, func(ctx context.Context) error {
+		return s.restoreSnapshot(ctx, projectName, options)
+	}, s.stdinfo(), "Restoring snapshot")
+}
+
+func (s *composeService) restoreSnapshot(ctx context.Context, projectName string, options api.RestoreSnapshotOptions) error { //nolint:gocyclo
+	projectName = strings.ToLower(projectName)
+	b, err := os.ReadFile(filepath.Join(options.Input, snapshotManifestFile))
+	if err != nil {
+		return fmt.Errorf("%s is not a snapshot bundle: %w", options.Input, err)
+	}
+	var manifest snapshotManifest
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		return fmt.Errorf("invalid snapshot bundle %s: %w", options.Input, err)
+	}
+	if manifest.Project != projectName {
+		return fmt.Errorf("snapshot %s was taken for project %q", options.Input, manifest.Project)
+	}
+	if s.dryRun {
+		return nil
+	}
+
+	images, err := os.Open(filepath.Join(options.Input, snapshotImagesFile))
+	if err != nil {
+		return err
+	}
+	defer images.Close() //nolint:errcheck
+	loaded, err := s.apiClient().ImageLoad(ctx, images, client.ImageLoadWithQuiet(true))
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(io.Discard, loaded.Body)
+	_ = loaded.Body.Close()
+	if err != nil {
+		return err
+	}
+
+	w := progress.ContextWriter(ctx)
+	current, err := s.getContainers(ctx, projectName, oneOffInclude, true)
+	if err != nil {
+		return err
+	}
+	for _, ctr := range current {
+		eventName := getContainerProgressName(ctr)
+		w.Event(progress.RemovingEvent(eventName))
+		if err := s.apiClient().ContainerRemove(ctx, ctr.ID, container.RemoveOptions{Force: true}); err != nil {
+			return err
+		}
+		w.Event(progress.RemovedEvent(eventName))
+	}
+
+	for _, n := range manifest.Networks {
+		_, err := s.apiClient().NetworkInspect(ctx, n.Name, network.InspectOptions{})
+		if errdefs.IsNotFound(err) {
+			_, err = s.apiClient().NetworkCreate(ctx, n.Name, n.Options)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, v := range manifest.Volumes {
+		// recreate volume so it only holds the saved content
+		if err := s.apiClient().VolumeRemove(ctx, v.Name, false); err != nil && !errdefs.IsNotFound(err) {
+			return err
+		}
+		if _, err := s.apiClient().VolumeCreate(ctx, volume.CreateOptions{
+			Name:       v.Name,
+			Driver:     v.Driver,
+			DriverOpts: v.Options,
+			Labels:     v.Labels,
+		}); err != nil {
+			return err
+		}
+	}
+
+	ids := map[string]string{}
+	for _, c := range snapshotCreationOrder(manifest.Containers) {
+		eventName := "Container " + c.Name
+		w.Event(progress.CreatingEvent(eventName))
+		config := *c.Config
+		config.Image = c.Image
+		hostConfig := *c.HostConfig
+		hostConfig.NetworkMode = container.NetworkMode(renameContainerRef(string(hostConfig.NetworkMode), ids))
+		hostConfig.IpcMode = container.IpcMode(renameContainerRef(string(hostConfig.IpcMode), ids))
+		hostConfig.PidMode = container.PidMode(renameContainerRef(string(hostConfig.PidMode), ids))
+		if hostConfig.NetworkMode.IsContainer() {
+			// hostname is inherited from the container owning network namespace
+			config.Hostname = ""
+			config.Domainname = ""
+		}
+
+		primary := string(hostConfig.NetworkMode)
+		networking := &network.NetworkingConfig{}
+		if endpoint, ok := c.Networks[primary]; ok {
+			networking.EndpointsConfig = map[string]*network.EndpointSettings{primary: endpoint}
+		}
+		created, err := s.apiClient().ContainerCreate(ctx, &config, &hostConfig, networking, nil, c.Name)
+		if err != nil {
+			return err
+		}
+		ids[c.ID] = created.ID
+		for name, endpoint := range c.Networks {
+			if name == primary {
+				continue
+			}
+			if err := s.apiClient().NetworkConnect(ctx, name, created.ID, endpoint); err != nil {
+				return err
+			}
+		}
+		w.Event(progress.CreatedEvent(eventName))
+	}
+
+	for _, v := range manifest.Volumes {
+		if err := s.restoreVolumeContent(ctx, ids[v.Container], v.Path, filepath.Join(options.Input, snapshotVolumesDir, v.Name+".tar")); err != nil {
+			return err
+		}
+	}
+
+	for _, c := range snapshotCreationOrder(manifest.Containers) {
+		if !c.Running {
+			continue
+		}
+		eventName := "Container " + c.Name
+		w.Event(progress.StartingEvent(eventName))
+		if err := s.apiClient().ContainerStart(ctx, ids[c.ID], container.StartOptions{}); err != nil {
+			return err
+		}
+		w.Event(progress.StartedEvent(eventName))
+	}
+	return nil
+}
+
+func (s *composeService) restoreVolumeContent(ctx context.Context, containerID string, target string, file string) error {
+	content, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer content.Close() //nolint:errcheck
+	// archive holds the target directory itself, so it is extracted in parent directory
+	return s.apiClient().CopyToContainer(ctx, containerID, path.Dir(target), content, container.CopyToContainerOptions{})
+}
+
+// snapshotCreationOrder sorts containers so the ones joining another container namespace are created last
+func snapshotCreationOrder(containers []snapshotContainer) []snapshotContainer {
+	joining := func(c snapshotContainer) bool {
+		return c.HostConfig.NetworkMode.IsContainer() || c.HostConfig.IpcMode.IsContainer() || c.HostConfig.PidMode.IsContainer()
+	}
+	sorted := slices.Clone(containers)
+	slices.SortStableFunc(sorted, func(a, b snapshotContainer) int {
+		switch {
+		case joining(a) == joining(b):
+			return 0
+		case joining(a):
+			return 1
+		default:
+			return -1
+		}
+	})
+	return sorted
+}
+
+// renameContainerRef updates a `container:ID` namespace reference to the ID of the restored container
+func renameContainerRef(mode string, ids map[string]string) string {
+	if ref, ok := strings.CutPrefix(mode, types.ContainerPrefix); ok {
+		if id, ok := ids[ref]; ok {
+			return types.ContainerPrefix + id
+		}
+	}
+	return mode
+}
@@ -0,0 +1,45 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"gotest.tools/v3/assert"
+)
+
+func TestSnapshotCreationOrder(t *testing.T) {
+	containers := []snapshotContainer{
+		{ID: "sidecar", HostConfig: &container.HostConfig{NetworkMode: "container:app"}},
+		{ID: "app", HostConfig: &container.HostConfig{NetworkMode: "myproject_default"}},
+		{ID: "debug", HostConfig: &container.HostConfig{PidMode: "container:app"}},
+		{ID: "db", HostConfig: &container.HostConfig{NetworkMode: "myproject_default"}},
+	}
+	var ids []string
+	for _, c := range snapshotCreationOrder(containers) {
+		ids = append(ids, c.ID)
+	}
+	assert.DeepEqual(t, ids, []string{"app", "db", "sidecar", "debug"})
+}
+
+func TestRenameContainerRef(t *testing.T) {
+	ids := map[string]string{"old": "new"}
+	assert.Equal(t, renameContainerRef("container:old", ids), "container:new")
+	assert.Equal(t, renameContainerRef("container:other", ids), "container:other")
+	assert.Equal(t, renameContainerRef("host", ids), "host")
+}
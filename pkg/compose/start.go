@@ -42,6 +42,12 @@ func (s *composeService) Start(ctx context.Context, projectName string, options
 }
 
 func (s *composeService) start(ctx context.Context, projectName string, options api.StartOptions, listener api.ContainerEventListener) error {
+	return s.startServices(ctx, projectName, options, listener, nil)
+}
+
+// startServices starts the project services, then runs started, if set, once they are all started or ready with
+// --wait. With a listener, it then blocks until attached containers exit
+func (s *composeService) startServices(ctx context.Context, projectName string, options api.StartOptions, listener api.ContainerEventListener, started func(context.Context) error) error {
 	project := options.Project
 	if project == nil {
 		var containers Containers
@@ -106,7 +112,7 @@ func (s *composeService) start(ctx context.Context, projectName string, options
 						Service:   ctr.Labels[api.ServiceLabel],
 					})
 					return nil
-				})
+				}, newRestartBreaker(options.RestartLimit))
 		})
 	}
 
@@ -135,11 +141,17 @@ func (s *composeService) start(ctx context.Context, projectName string, options
 	}
 
 	if options.Wait {
-		depends := types.DependsOnConfig{}
-		for _, s := range project.Services {
-			depends[s.Name] = types.ServiceDependency{
-				Condition: getDependencyCondition(s, project),
-				Required:  true,
+		depends, err := readinessConditions(project)
+		if err != nil {
+			return err
+		}
+		if depends == nil {
+			depends = types.DependsOnConfig{}
+			for _, s := range project.Services {
+				depends[s.Name] = types.ServiceDependency{
+					Condition: getDependencyCondition(s, project),
+					Required:  true,
+				}
 			}
 		}
 		if options.WaitTimeout > 0 {
@@ -157,6 +169,11 @@ func (s *composeService) start(ctx context.Context, projectName string, options
 		}
 	}
 
+	if started != nil {
+		if err := started(ctx); err != nil {
+			return err
+		}
+	}
 	return eg.Wait()
 }
 
@@ -180,6 +197,7 @@ type containerWatchFn func(ctr containerType.Summary, t time.Time) error
 func (s *composeService) watchContainers(ctx context.Context, //nolint:gocyclo
 	projectName string, services, required []string,
 	listener api.ContainerEventListener, containers Containers, onStart, onRecreate containerWatchFn,
+	breaker *restartBreaker,
 ) error {
 	if len(containers) == 0 {
 		return nil
@@ -288,6 +306,13 @@ func (s *composeService) watchContainers(ctx context.Context, //nolint:gocyclo
 					// however the container is already running before it restarts
 					willRestart = true
 				}
+				if inspected.State.Restarting && breaker.record(service, event.Timestamp) {
+					err := s.breakRestartLoop(ctx, inspected, name, service, breaker.limit, listener)
+					if err != nil {
+						return err
+					}
+					willRestart = false
+				}
 
 				eType := api.ContainerEventExit
 				if utils.Contains(replaced, container.ID) {
@@ -0,0 +1,52 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/docker/api/types/container"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+func TestStartServicesAttached(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	apiClient, cli := prepareMocks(mockCtrl)
+	tested := composeService{dockerCli: cli}
+	project := &types.Project{Name: "test", Services: types.Services{}}
+	apiClient.EXPECT().ContainerList(gomock.Any(), gomock.Any()).Return([]container.Summary{}, nil).AnyTimes()
+
+	listener := func(api.ContainerEvent) {}
+	var started int
+	err := tested.startServices(context.TODO(), project.Name, api.StartOptions{Project: project}, listener, func(context.Context) error {
+		started++
+		return nil
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, started, 1)
+
+	err = tested.startServices(context.TODO(), project.Name, api.StartOptions{Project: project}, listener, func(context.Context) error {
+		return errors.New("post-up hook failed")
+	})
+	assert.ErrorContains(t, err, "post-up hook failed")
+}
@@ -0,0 +1,112 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/sirupsen/logrus"
+)
+
+// stdinRouter forwards terminal input to the first container of a service while attached
+type stdinRouter struct {
+	compose *composeService
+	project string
+	mu      sync.Mutex
+	conns   map[string]io.WriteCloser
+}
+
+func newStdinRouter(s *composeService, projectName string) *stdinRouter {
+	return &stdinRouter{
+		compose: s,
+		project: projectName,
+		conns:   map[string]io.WriteCloser{},
+	}
+}
+
+// Write sends data to service stdin, attaching to the service container on first use
+func (r *stdinRouter) Write(ctx context.Context, service string, data []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	conn, ok := r.conns[service]
+	if !ok {
+		ctr, err := r.compose.getSpecifiedContainer(ctx, r.project, oneOffExclude, false, service, 1)
+		if err != nil {
+			return err
+		}
+		cnx, err := r.compose.apiClient().ContainerAttach(ctx, ctr.ID, container.AttachOptions{
+			Stream: true,
+			Stdin:  true,
+		})
+		if err != nil {
+			return err
+		}
+		conn = ContainerStdin{HijackedResponse: cnx}
+		r.conns[service] = conn
+	}
+	if _, err := conn.Write(data); err != nil {
+		_ = conn.Close()
+		delete(r.conns, service)
+		return fmt.Errorf("could not send input to service %q: %w", service, err)
+	}
+	return nil
+}
+
+// Close detaches from all containers, closing their stdin
+func (r *stdinRouter) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for service, conn := range r.conns {
+		_ = conn.Close()
+		delete(r.conns, service)
+	}
+}
+
+// forward copies reader to service stdin until reader is closed
+func (r *stdinRouter) forward(ctx context.Context, service string, reader io.Reader) {
+	buf := make([]byte, 4096)
+	for {
+		n, err := reader.Read(buf)
+		if n > 0 {
+			if werr := r.Write(ctx, service, buf[:n]); werr != nil {
+				logrus.Warn(werr.Error())
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// stdinServices lists the attached services which keep stdin open, so they can receive terminal input
+func stdinServices(project *types.Project, attachTo []string) []string {
+	var services []string
+	for _, name := range attachTo {
+		service, err := project.GetService(name)
+		if err == nil && service.StdinOpen {
+			services = append(services, name)
+		}
+	}
+	slices.Sort(services)
+	return services
+}
@@ -46,6 +46,10 @@ func (s *composeService) stop(ctx context.Context, projectName string, options a
 	}
 
 	if len(options.Services) == 0 {
+		// host processes depend on services, so they are stopped first
+		if err := s.stopHostProcesses(ctx, projectName, false, options.Timeout); err != nil {
+			return err
+		}
 		options.Services = project.ServiceNames()
 	}
 
@@ -0,0 +1,360 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"os/user"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/distribution/reference"
+	"github.com/docker/buildx/util/imagetools"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/streams"
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/remote"
+)
+
+const (
+	// transcriptArtifactType is the OCI artifact type for a run/exec transcript pushed to a registry
+	transcriptArtifactType = "application/vnd.docker.compose.transcript"
+	// transcriptMediaType is the media type for the transcript layer
+	transcriptMediaType = "application/vnd.docker.compose.transcript.v1+json"
+	// transcriptOutputLimit is the size of output recorded by a transcript, output exceeding it is dropped
+	transcriptOutputLimit = 1 << 20
+)
+
+// transcript records a run or exec invocation for audit
+type transcript struct {
+	Time      time.Time `json:"time"`
+	Operation string    `json:"operation"`
+	Project   string    `json:"project"`
+	Service   string    `json:"service"`
+	User      string    `json:"user,omitempty"`
+	Command   []string  `json:"command,omitempty"`
+	// Session is the name of the exec session started or attached to, if any
+	Session string `json:"session,omitempty"`
+	// Environment lists the names of the variables set for the command, values aren't recorded as they can be secrets
+	Environment []string `json:"environment,omitempty"`
+	Output      string   `json:"output"`
+	// OutputTruncated is set when output exceeded transcriptOutputLimit
+	OutputTruncated bool `json:"outputTruncated,omitempty"`
+	// Tty is set when the command ran with a terminal attached, in which case output isn't captured
+	Tty      bool   `json:"tty,omitempty"`
+	ExitCode int    `json:"exitCode"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// transcriptEnvFunc returns the names of the variables a recorded command ran with differing from its image defaults
+type transcriptEnvFunc func(ctx context.Context) ([]string, error)
+
+// transcriptCli copies output written to the Docker CLI streams
+type transcriptCli struct {
+	command.Cli
+	out *streams.Out
+	err *streams.Out
+}
+
+func (c transcriptCli) Out() *streams.Out {
+	return c.out
+}
+
+func (c transcriptCli) Err() *streams.Out {
+	return c.err
+}
+
+// outputCapture keeps output written from stdout and stderr copies, up to limit bytes
+type outputCapture struct {
+	mu        sync.Mutex
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+// Write always reports p as written, so output keeps being sent to the streams once limit is reached
+func (c *outputCapture) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if remaining := c.limit - c.buf.Len(); len(p) > remaining {
+		c.truncated = true
+		c.buf.Write(p[:max(remaining, 0)])
+		return len(p), nil
+	}
+	c.buf.Write(p)
+	return len(p), nil
+}
+
+// content returns output captured so far, and whether some was dropped
+func (c *outputCapture) content() (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.buf.String(), c.truncated
+}
+
+// teeStream returns a stream copying output written to stream to w. As the Docker CLI only gets the file descriptor
+// of a stream created from a file, the copy can't be used to resize a TTY or set a terminal in raw mode, so streams
+// connected to a terminal are returned as is when a TTY is allocated
+func teeStream(stream *streams.Out, w io.Writer, tty bool) *streams.Out {
+	if tty && stream.IsTerminal() {
+		return stream
+	}
+	return streams.NewOut(io.MultiWriter(stream, w))
+}
+
+// withTranscript runs fn with a composeService capturing output, then records the invocation to options.AuditLog,
+// including when fn fails
+func (s *composeService) withTranscript(ctx context.Context, operation string, projectName string, options api.RunOptions, env transcriptEnvFunc, fn func(*composeService) (int, error)) (exitCode int, err error) {
+	output := &outputCapture{limit: transcriptOutputLimit}
+	capture := *s
+	capture.dockerCli = transcriptCli{
+		Cli: s.dockerCli,
+		out: teeStream(s.dockerCli.Out(), output, options.Tty),
+		err: teeStream(s.dockerCli.Err(), output, options.Tty),
+	}
+	tty := capture.dockerCli.Out() == s.dockerCli.Out()
+
+	start := time.Now()
+	defer func() {
+		if s.dryRun {
+			return
+		}
+		// record the invocation even when interrupted
+		ctx := context.WithoutCancel(ctx)
+		record := transcript{
+			Time:      start.UTC(),
+			Operation: operation,
+			Project:   projectName,
+			Service:   options.Service,
+			Session:   options.Session,
+			Command:   options.Command,
+			Tty:       tty,
+			ExitCode:  exitCode,
+			Duration:  time.Since(start).String(),
+		}
+		if options.AttachSession != "" {
+			record.Session = options.AttachSession
+		}
+		record.Output, record.OutputTruncated = output.content()
+		if err != nil {
+			record.Error = err.Error()
+		}
+		if u, err := user.Current(); err == nil {
+			record.User = u.Username
+		}
+		environment, envErr := env(ctx)
+		if envErr != nil {
+			logrus.Warnf("can't resolve %s environment for the audit log: %v", operation, envErr)
+			environment = envNames(options.Environment)
+		}
+		record.Environment = environment
+		if writeErr := s.writeTranscript(ctx, options.AuditLog, record); err == nil {
+			err = writeErr
+		}
+	}()
+	return fn(&capture)
+}
+
+// envDiff returns the names of the variables of environment which aren't set to the same value by base, sorted
+func envDiff(base []string, environment map[string]string) []string {
+	defaults := map[string]string{}
+	for _, v := range base {
+		key, value, _ := strings.Cut(v, "=")
+		defaults[key] = value
+	}
+	var diff []string
+	for key, value := range environment {
+		if d, ok := defaults[key]; !ok || d != value {
+			diff = append(diff, key)
+		}
+	}
+	sort.Strings(diff)
+	return diff
+}
+
+// envNames returns the sorted names of KEY=VALUE variables
+func envNames(environment []string) []string {
+	var names []string
+	for _, v := range environment {
+		key, _, _ := strings.Cut(v, "=")
+		names = append(names, key)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// overrideEnv returns the variables of base overridden by those set by overrides
+func overrideEnv(base []string, overrides types.MappingWithEquals) map[string]string {
+	environment := map[string]string{}
+	for _, v := range base {
+		key, value, _ := strings.Cut(v, "=")
+		environment[key] = value
+	}
+	for key, value := range overrides {
+		if value != nil {
+			environment[key] = *value
+		}
+	}
+	return environment
+}
+
+// runTranscriptEnv returns the environment a one-off container of service runs with, as set by the service and
+// command line, compared to the image defaults. Values resolved on container creation, like secret references, are
+// left out
+func (s *composeService) runTranscriptEnv(project *types.Project, opts api.RunOptions) transcriptEnvFunc {
+	return func(ctx context.Context) ([]string, error) {
+		service, err := project.GetService(opts.Service)
+		if err != nil {
+			return nil, err
+		}
+		image, err := s.apiClient().ImageInspect(ctx, api.GetImageNameOrDefault(service, project.Name))
+		if err != nil {
+			return nil, err
+		}
+		var base []string
+		if image.Config != nil {
+			base = image.Config.Env
+		}
+		overrides := types.MappingWithEquals{}.OverrideBy(service.Environment).OverrideBy(runEnvironment(project, opts))
+		return envDiff(base, overrideEnv(base, overrides)), nil
+	}
+}
+
+// execTranscriptEnv returns the environment a command executed in the target container runs with, compared to the
+// container image defaults
+func (s *composeService) execTranscriptEnv(projectName string, opts api.RunOptions) transcriptEnvFunc {
+	return func(ctx context.Context) ([]string, error) {
+		target, err := s.getExecTarget(ctx, projectName, opts)
+		if err != nil {
+			return nil, err
+		}
+		ctr, err := s.apiClient().ContainerInspect(ctx, target.ID)
+		if err != nil {
+			return nil, err
+		}
+		image, err := s.apiClient().ImageInspect(ctx, ctr.Image)
+		if err != nil {
+			return nil, err
+		}
+		var base, containerEnv []string
+		if image.Config != nil {
+			base = image.Config.Env
+		}
+		if ctr.Config != nil {
+			containerEnv = ctr.Config.Env
+		}
+		return envDiff(base, overrideEnv(containerEnv, types.NewMappingWithEquals(opts.Environment))), nil
+	}
+}
+
+// transcriptTag returns the tag of a transcript pushed to the repository of named
+func transcriptTag(named reference.Named, record transcript, content []byte) string {
+	tag := record.Time.Format("20060102T150405Z") + "-" + digest.FromBytes(content).Encoded()[:12]
+	if tagged, ok := named.(reference.Tagged); ok {
+		// tags are limited to 128 characters
+		prefix := tagged.Tag()
+		if len(prefix)+1+len(tag) > 128 {
+			prefix = prefix[:128-1-len(tag)]
+		}
+		tag = prefix + "-" + tag
+	}
+	return tag
+}
+
+// writeTranscript appends record to the audit log file as a JSON line, or pushes it as an OCI artifact
+func (s *composeService) writeTranscript(ctx context.Context, auditLog string, record transcript) error {
+	content, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if strings.HasPrefix(auditLog, remote.OciPrefix) {
+		return s.pushTranscript(ctx, strings.TrimPrefix(auditLog, remote.OciPrefix), record, content)
+	}
+
+	f, err := os.OpenFile(auditLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close() //nolint:errcheck
+	_, err = f.Write(append(content, '\n'))
+	return err
+}
+
+// pushTranscript pushes record as an OCI artifact, tagged with invocation time and a digest prefix of the transcript
+// for concurrent invocations not to overwrite each other. A tag set by repository is used as prefix
+func (s *composeService) pushTranscript(ctx context.Context, repository string, record transcript, content []byte) error {
+	named, err := reference.ParseNormalizedNamed(repository)
+	if err != nil {
+		return err
+	}
+	named, err = reference.WithTag(reference.TrimNamed(named), transcriptTag(named, record, content))
+	if err != nil {
+		return err
+	}
+
+	resolver := imagetools.New(imagetools.Opt{
+		Auth: s.configFile(),
+	})
+	layer := v1.Descriptor{
+		MediaType: transcriptMediaType,
+		Digest:    digest.FromBytes(content),
+		Size:      int64(len(content)),
+		Annotations: map[string]string{
+			"com.docker.compose.version": api.ComposeVersion,
+		},
+	}
+	if err := resolver.Push(ctx, named, layer, content); err != nil {
+		return err
+	}
+	if err := resolver.Push(ctx, named, v1.DescriptorEmptyJSON, v1.DescriptorEmptyJSON.Data); err != nil {
+		return err
+	}
+
+	manifest, err := json.Marshal(v1.Manifest{
+		Versioned:    specs.Versioned{SchemaVersion: 2},
+		MediaType:    v1.MediaTypeImageManifest,
+		ArtifactType: transcriptArtifactType,
+		Config:       v1.DescriptorEmptyJSON,
+		Layers:       []v1.Descriptor{layer},
+		Annotations: map[string]string{
+			v1.AnnotationCreated:         record.Time.Format(time.RFC3339),
+			"com.docker.compose.project": record.Project,
+			"com.docker.compose.service": record.Service,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return resolver.Push(ctx, named, v1.Descriptor{
+		MediaType: v1.MediaTypeImageManifest,
+		Digest:    digest.FromBytes(manifest),
+		Size:      int64(len(manifest)),
+	}, manifest)
+}
@@ -0,0 +1,222 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/distribution/reference"
+	"github.com/docker/cli/cli/streams"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/opencontainers/go-digest"
+	"go.uber.org/mock/gomock"
+	"gotest.tools/v3/assert"
+
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/mocks"
+)
+
+func TestWriteTranscript(t *testing.T) {
+	auditLog := filepath.Join(t.TempDir(), "audit.log")
+	s := &composeService{}
+	for _, exitCode := range []int{0, 1} {
+		err := s.writeTranscript(context.TODO(), auditLog, transcript{
+			Operation: "exec",
+			Project:   "myproject",
+			Service:   "db",
+			Command:   []string{"psql", "-c", "VACUUM"},
+			Output:    "VACUUM\n",
+			ExitCode:  exitCode,
+		})
+		assert.NilError(t, err)
+	}
+
+	content, err := os.ReadFile(auditLog)
+	assert.NilError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	assert.Equal(t, len(lines), 2)
+
+	var record transcript
+	assert.NilError(t, json.Unmarshal([]byte(lines[1]), &record))
+	assert.Equal(t, record.Service, "db")
+	assert.Equal(t, record.Output, "VACUUM\n")
+	assert.Equal(t, record.ExitCode, 1)
+}
+
+func TestWithTranscriptOnFailure(t *testing.T) {
+	auditLog := filepath.Join(t.TempDir(), "audit.log")
+	cli := mocks.NewMockCli(gomock.NewController(t))
+	cli.EXPECT().Out().Return(streams.NewOut(io.Discard)).AnyTimes()
+	cli.EXPECT().Err().Return(streams.NewOut(io.Discard)).AnyTimes()
+	s := &composeService{dockerCli: cli}
+
+	env := func(ctx context.Context) ([]string, error) {
+		// still recorded once the command is interrupted
+		assert.NilError(t, ctx.Err())
+		return []string{"DEBUG"}, nil
+	}
+	ctx, cancel := context.WithCancel(context.TODO())
+	exitCode, err := s.withTranscript(ctx, "exec", "myproject", api.RunOptions{Service: "db", AuditLog: auditLog}, env,
+		func(s *composeService) (int, error) {
+			_, _ = fmt.Fprintln(s.stdout(), "connecting")
+			cancel()
+			return 1, errors.New("connection refused")
+		})
+	assert.Equal(t, exitCode, 1)
+	assert.ErrorContains(t, err, "connection refused")
+
+	content, err := os.ReadFile(auditLog)
+	assert.NilError(t, err)
+	var record transcript
+	assert.NilError(t, json.Unmarshal(content, &record))
+	assert.Equal(t, record.Output, "connecting\n")
+	assert.Equal(t, record.Error, "connection refused")
+	assert.DeepEqual(t, record.Environment, []string{"DEBUG"})
+}
+
+func TestExecSessionTranscript(t *testing.T) {
+	auditLog := filepath.Join(t.TempDir(), "audit.log")
+	apiClient, cli := prepareMocks(gomock.NewController(t))
+	apiClient.EXPECT().ContainerList(gomock.Any(), gomock.Any()).Return(nil, nil)
+	s := &composeService{dockerCli: cli}
+
+	// attaching to a session is recorded, even when it fails
+	_, err := s.Exec(context.TODO(), "myproject", api.RunOptions{AttachSession: "migration", AuditLog: auditLog})
+	assert.ErrorContains(t, err, `no session "migration" found`)
+
+	content, err := os.ReadFile(auditLog)
+	assert.NilError(t, err)
+	var record transcript
+	assert.NilError(t, json.Unmarshal(content, &record))
+	assert.Equal(t, record.Operation, "exec")
+	assert.Equal(t, record.Session, "migration")
+	assert.Equal(t, record.Error, `no session "migration" found for project myproject`)
+}
+
+func TestExecSessionStartTranscript(t *testing.T) {
+	auditLog := filepath.Join(t.TempDir(), "audit.log")
+	apiClient, cli := prepareMocks(gomock.NewController(t))
+	s := &composeService{dockerCli: cli}
+
+	apiClient.EXPECT().ContainerList(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, opts container.ListOptions) ([]container.Summary, error) {
+			if opts.All {
+				// no session with this name yet
+				return nil, nil
+			}
+			return []container.Summary{{ID: "123"}}, nil
+		}).AnyTimes()
+	apiClient.EXPECT().ContainerInspect(gomock.Any(), "123").Return(container.InspectResponse{
+		ContainerJSONBase: &container.ContainerJSONBase{ID: "123", Image: "sha256:1234"},
+		Config:            &container.Config{Env: []string{"PATH=/usr/bin"}, Labels: map[string]string{}},
+	}, nil).AnyTimes()
+	apiClient.EXPECT().ImageInspect(gomock.Any(), "sha256:1234").Return(image.InspectResponse{
+		Config: &container.Config{Env: []string{"PATH=/usr/bin"}},
+	}, nil)
+	apiClient.EXPECT().ContainerCreate(gomock.Any(), gomock.Any(), gomock.Any(), nil, nil, "myproject-web-session-migration").
+		Return(container.CreateResponse{ID: "456"}, nil)
+	apiClient.EXPECT().ContainerStart(gomock.Any(), "456", container.StartOptions{}).Return(nil)
+	apiClient.EXPECT().ContainerInspect(gomock.Any(), "456").Return(container.InspectResponse{
+		ContainerJSONBase: &container.ContainerJSONBase{ID: "456", State: &container.State{Running: true}},
+	}, nil)
+
+	// starting a detached session is recorded with the command and environment names
+	_, err := s.Exec(context.TODO(), "myproject", api.RunOptions{
+		Service:     "web",
+		Command:     []string{"python", "migrate.py"},
+		Environment: []string{"DEBUG=1"},
+		Detach:      true,
+		Session:     "migration",
+		AuditLog:    auditLog,
+	})
+	assert.NilError(t, err)
+
+	content, err := os.ReadFile(auditLog)
+	assert.NilError(t, err)
+	var record transcript
+	assert.NilError(t, json.Unmarshal(content, &record))
+	assert.Equal(t, record.Session, "migration")
+	assert.DeepEqual(t, record.Command, []string{"python", "migrate.py"})
+	assert.DeepEqual(t, record.Environment, []string{"DEBUG"})
+}
+
+func TestOutputCapture(t *testing.T) {
+	output := &outputCapture{limit: 8}
+	n, err := output.Write([]byte("hello "))
+	assert.NilError(t, err)
+	assert.Equal(t, n, 6)
+	n, err = output.Write([]byte("world"))
+	assert.NilError(t, err)
+	assert.Equal(t, n, 5)
+	_, _ = output.Write([]byte("!"))
+
+	content, truncated := output.content()
+	assert.Equal(t, content, "hello wo")
+	assert.Check(t, truncated)
+}
+
+func TestTranscriptTag(t *testing.T) {
+	record := transcript{Time: time.Date(2026, 10, 15, 8, 30, 0, 0, time.UTC)}
+	content := []byte(`{"operation":"exec"}`)
+	suffix := "20261015T083000Z-" + digest.FromBytes(content).Encoded()[:12]
+
+	named, err := reference.ParseNormalizedNamed("registry.example.com/ops/audit")
+	assert.NilError(t, err)
+	assert.Equal(t, transcriptTag(named, record, content), suffix)
+
+	named, err = reference.ParseNormalizedNamed("registry.example.com/ops/audit:migrations")
+	assert.NilError(t, err)
+	assert.Equal(t, transcriptTag(named, record, content), "migrations-"+suffix)
+
+	// a transcript with another content gets another tag
+	assert.Check(t, transcriptTag(named, record, []byte("{}")) != "migrations-"+suffix)
+
+	named, err = reference.ParseNormalizedNamed("registry.example.com/ops/audit:" + strings.Repeat("x", 128))
+	assert.NilError(t, err)
+	assert.Equal(t, len(transcriptTag(named, record, content)), 128)
+}
+
+func TestTeeStream(t *testing.T) {
+	var original, copied strings.Builder
+	stream := streams.NewOut(&original)
+	_, _ = fmt.Fprint(teeStream(stream, &copied, true), "output")
+	assert.Equal(t, original.String(), "output")
+	assert.Equal(t, copied.String(), "output")
+
+	// a terminal is kept as is for the TTY to be resized and set in raw mode
+	stream.SetIsTerminal(true)
+	assert.Equal(t, teeStream(stream, &copied, true), stream)
+	assert.Check(t, teeStream(stream, &copied, false) != stream)
+}
+
+func TestEnvDiff(t *testing.T) {
+	base := []string{"PATH=/usr/bin", "LANG=C"}
+	environment := overrideEnv([]string{"PATH=/usr/bin", "LANG=C", "DB_HOST=db"}, types.NewMappingWithEquals([]string{"LANG=en_US", "UNSET"}))
+	assert.DeepEqual(t, envDiff(base, environment), []string{"DB_HOST", "LANG"})
+	assert.DeepEqual(t, envNames([]string{"TOKEN=secret", "DEBUG"}), []string{"DEBUG", "TOKEN"})
+}
@@ -0,0 +1,154 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/errdefs"
+
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/progress"
+)
+
+const (
+	// ttlLabel records on the teardown supervisor container of a project the time the project expires
+	ttlLabel = "com.docker.compose.ttl"
+	// ttlSupervisorImage runs the docker CLI to tear the project down. Pinned to a release, as the supervisor
+	// outlives the Compose process and must not change behavior when the image gets updated
+	ttlSupervisorImage = "docker:27.5.1-cli"
+	// desktopEngineSocket is the engine socket inside the Docker Desktop VM, whatever the socket used by the host
+	desktopEngineSocket = "/var/run/docker.sock"
+	ttlSupervisorAlias  = "ttl"
+)
+
+// ttlTeardownScript waits for the expiry time, then removes the project containers and networks, and finally the
+// supervisor container itself. Volumes are kept, like `down` does by default. As it compares the wall clock to the
+// expiry time, teardown still happens on time if the supervisor is restarted
+const ttlTeardownScript = `until [ "$(date +%s)" -ge "$EXPIRES" ]; do sleep 10; done
+self=$(hostname)
+docker ps -aq --filter "label=com.docker.compose.project=$PROJECT" | grep -v "^$self" | xargs -r docker rm -f
+docker network ls -q --filter "label=com.docker.compose.project=$PROJECT" | xargs -r docker network rm
+docker rm -f "$self"`
+
+func ttlSupervisorName(projectName string) string {
+	return strings.Join([]string{projectName, ttlSupervisorAlias}, api.Separator)
+}
+
+// ttlEngineSocket returns the path of the engine socket on the engine host, for the supervisor to bind mount. It can
+// only be derived from a local unix socket, or Docker Desktop which always exposes its socket inside its VM
+func ttlEngineSocket(host string, desktop bool) (string, error) {
+	if desktop {
+		return desktopEngineSocket, nil
+	}
+	if path, ok := strings.CutPrefix(host, "unix://"); ok {
+		return path, nil
+	}
+	return "", fmt.Errorf("--ttl requires an engine reached through a unix socket, can't locate the socket of engine %s", host)
+}
+
+// ttlSupervisorConfig returns the configuration of the container tearing down project once expires is reached,
+// using the engine socket bind mounted from socket
+func ttlSupervisorConfig(projectName string, expires time.Time, socket string) (*container.Config, *container.HostConfig) {
+	return &container.Config{
+		Image: ttlSupervisorImage,
+		Cmd:   []string{"sh", "-c", ttlTeardownScript},
+		Env: []string{
+			"PROJECT=" + projectName,
+			"EXPIRES=" + strconv.FormatInt(expires.Unix(), 10),
+		},
+		Labels: map[string]string{
+			api.ProjectLabel: projectName,
+			ttlLabel:         expires.UTC().Format(time.RFC3339),
+		},
+	}, &container.HostConfig{
+		NetworkMode:   network.NetworkBridge,
+		RestartPolicy: container.RestartPolicy{Name: container.RestartPolicyUnlessStopped},
+		Mounts: []mount.Mount{{
+			Type:   mount.TypeBind,
+			Source: socket,
+			Target: "/var/run/docker.sock",
+		}},
+	}
+}
+
+// scheduleTeardown runs a supervisor container removing the project once ttl is elapsed, replacing the one a previous
+// `up --ttl` may have scheduled
+func (s *composeService) scheduleTeardown(ctx context.Context, project *types.Project, ttl time.Duration) error {
+	info, err := s.getEngineInfo(ctx)
+	if err != nil {
+		return err
+	}
+	socket, err := ttlEngineSocket(s.dockerCli.DockerEndpoint().Host, info.OperatingSystem == "Docker Desktop" || s.isDesktopIntegrationActive())
+	if err != nil {
+		return err
+	}
+	if err := s.removeTTLSupervisor(ctx, project.Name); err != nil {
+		return err
+	}
+	w := progress.ContextWriter(ctx)
+	if _, err := s.apiClient().ImageInspect(ctx, ttlSupervisorImage); errdefs.IsNotFound(err) {
+		service := types.ServiceConfig{Name: ttlSupervisorAlias, Image: ttlSupervisorImage}
+		if _, err := s.pullServiceImage(ctx, service, s.configFile(), w, true, ""); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+
+	expires := time.Now().Add(ttl)
+	name := ttlSupervisorName(project.Name)
+	eventName := "Container " + name
+	w.Event(progress.CreatingEvent(eventName))
+	config, hostConfig := ttlSupervisorConfig(project.Name, expires, socket)
+	created, err := s.apiClient().ContainerCreate(ctx, config, hostConfig, nil, nil, name)
+	if err != nil {
+		return err
+	}
+	if err := s.apiClient().ContainerStart(ctx, created.ID, container.StartOptions{}); err != nil {
+		return err
+	}
+	w.Event(progress.Event{
+		ID:         eventName,
+		Text:       "Teardown scheduled",
+		StatusText: expires.Format(time.DateTime),
+		Status:     progress.Done,
+	})
+	return nil
+}
+
+// removeTTLSupervisor cancels the scheduled teardown of the project, if any
+func (s *composeService) removeTTLSupervisor(ctx context.Context, projectName string) error {
+	name := ttlSupervisorName(projectName)
+	err := s.apiClient().ContainerRemove(ctx, name, container.RemoveOptions{Force: true})
+	if errdefs.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	progress.ContextWriter(ctx).Event(progress.RemovedEvent("Container " + name))
+	return nil
+}
@@ -0,0 +1,58 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"gotest.tools/v3/assert"
+
+	compose "github.com/docker/compose/v2/pkg/api"
+)
+
+func TestTTLSupervisorConfig(t *testing.T) {
+	expires := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	config, hostConfig := ttlSupervisorConfig("demo", expires, "/run/user/1000/docker.sock")
+
+	assert.Equal(t, ttlSupervisorName("demo"), "demo-ttl")
+	assert.DeepEqual(t, config.Env, []string{"PROJECT=demo", "EXPIRES=1709294400"})
+	assert.DeepEqual(t, config.Labels, map[string]string{
+		compose.ProjectLabel: "demo",
+		ttlLabel:             "2024-03-01T12:00:00Z",
+	})
+	// supervisor isn't a service container, so it's ignored by commands managing them
+	_, ok := config.Labels[compose.ConfigHashLabel]
+	assert.Assert(t, !ok)
+	assert.Equal(t, hostConfig.RestartPolicy.Name, container.RestartPolicyUnlessStopped)
+	assert.Equal(t, hostConfig.Mounts[0].Source, "/run/user/1000/docker.sock")
+	assert.Equal(t, hostConfig.Mounts[0].Target, "/var/run/docker.sock")
+}
+
+func TestTTLEngineSocket(t *testing.T) {
+	socket, err := ttlEngineSocket("unix:///run/user/1000/docker.sock", false)
+	assert.NilError(t, err)
+	assert.Equal(t, socket, "/run/user/1000/docker.sock")
+
+	socket, err = ttlEngineSocket("unix:///Users/me/.docker/run/docker.sock", true)
+	assert.NilError(t, err)
+	assert.Equal(t, socket, "/var/run/docker.sock")
+
+	_, err = ttlEngineSocket("tcp://10.0.0.2:2376", false)
+	assert.ErrorContains(t, err, "--ttl requires an engine reached through a unix socket")
+}
@@ -36,14 +36,48 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-func (s *composeService) Up(ctx context.Context, project *types.Project, options api.UpOptions) error { //nolint:gocyclo
+func (s *composeService) Up(ctx context.Context, project *types.Project, options api.UpOptions) error {
+	var hooks map[string][]projectHook
+	if len(options.Create.Services) == 0 {
+		var err error
+		hooks, err = getProjectHooks(project)
+		if err != nil {
+			return err
+		}
+	}
+	err := s.up(ctx, project, options, hooks)
+	if err != nil {
+		s.runFailureHooks(ctx, project, hooks, err)
+	}
+	return err
+}
+
+func (s *composeService) up(ctx context.Context, project *types.Project, options api.UpOptions, hooks map[string][]projectHook) error { //nolint:gocyclo
+	if err := s.checkBalancedPorts(project, options); err != nil {
+		return err
+	}
 	err := progress.Run(ctx, tracing.SpanWrapFunc("project/up", tracing.ProjectOptions(ctx, project), func(ctx context.Context) error {
+		if err := s.runProjectHooks(ctx, project, hooks, hookPreUp, nil); err != nil {
+			return err
+		}
 		err := s.create(ctx, project, options.Create)
 		if err != nil {
 			return err
 		}
+		if options.TTL > 0 {
+			if err := s.scheduleTeardown(ctx, project, options.TTL); err != nil {
+				return err
+			}
+		}
 		if options.Start.Attach == nil {
-			return s.start(ctx, project.Name, options.Start, nil)
+			err = s.start(ctx, project.Name, options.Start, nil)
+			if err != nil || len(options.Create.Services) > 0 {
+				return err
+			}
+			if err := s.startHostProcesses(ctx, project); err != nil {
+				return err
+			}
+			return s.runProjectHooks(ctx, project, hooks, hookPostUp, nil)
 		}
 		return nil
 	}), s.stdinfo())
@@ -59,6 +93,12 @@ func (s *composeService) Up(ctx context.Context, project *types.Project, options
 		return err
 	}
 
+	balancersCtx, stopBalancers := context.WithCancel(ctx)
+	defer stopBalancers()
+	if err := s.startBalancers(balancersCtx, project); err != nil {
+		return err
+	}
+
 	var eg multierror.Group
 
 	// if we get a second signal during shutdown, we kill the services
@@ -71,6 +111,8 @@ func (s *composeService) Up(ctx context.Context, project *types.Project, options
 	defer signal.Stop(signalChan)
 	var isTerminated atomic.Bool
 	printer := newLogPrinter(options.Start.Attach)
+	stdin := newStdinRouter(s, project.Name)
+	defer stdin.Close()
 
 	var kEvents <-chan keyboard.KeyEvent
 	if options.Start.NavigationMenu {
@@ -83,9 +125,16 @@ func (s *composeService) Up(ctx context.Context, project *types.Project, options
 			isWatchConfigured := s.shouldWatch(project)
 			isDockerDesktopActive := s.isDesktopIntegrationActive()
 			tracing.KeyboardMetrics(ctx, options.Start.NavigationMenu, isDockerDesktopActive, isWatchConfigured)
-			formatter.NewKeyboardManager(ctx, isDockerDesktopActive, isWatchConfigured, signalChan, s.watch)
+			formatter.NewKeyboardManager(ctx, isDockerDesktopActive, isWatchConfigured, signalChan, s.watch, s.pause, s.unPause)
+			if services := stdinServices(project, options.Start.AttachTo); len(services) > 0 {
+				formatter.KeyboardManager.EnableStdin(services, options.Start.Stdin, stdin.Write)
+			}
 		}
 	}
+	if options.Start.Stdin != "" && !options.Start.NavigationMenu {
+		// not bound to the eg group, as reading from stdin never completes
+		go stdin.forward(ctx, options.Start.Stdin, s.stdin())
+	}
 
 	doneCh := make(chan bool)
 	eg.Go(func() error {
@@ -168,8 +217,26 @@ func (s *composeService) Up(ctx context.Context, project *types.Project, options
 		})
 	}
 
+	if len(options.Create.Services) == 0 {
+		// host processes wait for the services they depend on, which get started below
+		hostCtx, stopHostLogs := context.WithCancel(ctx)
+		defer stopHostLogs()
+		go func() {
+			if err := s.startHostProcesses(hostCtx, project); err != nil {
+				logrus.Error(err)
+				return
+			}
+			_ = s.logHostProcesses(hostCtx, project.Name, options.Start.Attach, "0", true)
+		}()
+	}
+
 	// We use the parent context without cancellation as we manage sigterm to stop the stack
-	err = s.start(context.WithoutCancel(ctx), project.Name, options.Start, printer.HandleEvent)
+	err = s.startServices(context.WithoutCancel(ctx), project.Name, options.Start, printer.HandleEvent, func(ctx context.Context) error {
+		if len(options.Create.Services) > 0 {
+			return nil
+		}
+		return s.runProjectHooks(ctx, project, hooks, hookPostUp, nil)
+	})
 	if err != nil && !isTerminated.Load() { // Ignore error if the process is terminated
 		return err
 	}
@@ -24,13 +24,13 @@ import (
 	"path"
 	"path/filepath"
 	"slices"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/compose-spec/compose-go/v2/types"
 	"github.com/compose-spec/compose-go/v2/utils"
 	ccli "github.com/docker/cli/cli/command/container"
+	"github.com/docker/compose/v2/internal/features"
 	pathutil "github.com/docker/compose/v2/internal/paths"
 	"github.com/docker/compose/v2/internal/sync"
 	"github.com/docker/compose/v2/internal/tracing"
@@ -50,11 +50,9 @@ import (
 // Currently, an implementation that batches files and transfers them using
 // the Moby `Untar` API.
 func (s *composeService) getSyncImplementation(project *types.Project) (sync.Syncer, error) {
-	var useTar bool
-	if useTarEnv, ok := os.LookupEnv("COMPOSE_EXPERIMENTAL_WATCH_TAR"); ok {
-		useTar, _ = strconv.ParseBool(useTarEnv)
-	} else {
-		useTar = true
+	useTar, err := features.NewFlags(project.Environment).Enabled(features.WatchTar)
+	if err != nil {
+		return nil, err
 	}
 	if !useTar {
 		return nil, errors.New("no available sync implementation")
@@ -72,7 +70,7 @@ func (s *composeService) shouldWatch(project *types.Project) bool {
 			shouldWatch = true
 		}
 	}
-	return shouldWatch
+	return shouldWatch || len(hotReloadSecrets(project)) > 0
 }
 
 func (s *composeService) Watch(ctx context.Context, project *types.Project, services []string, options api.WatchOptions) error {
@@ -139,9 +137,13 @@ func (s *composeService) watch(ctx context.Context, syncChannel chan bool, proje
 	eg, ctx := errgroup.WithContext(ctx)
 	options.LogTo.Register(api.WatchLogger)
 
+	defaultBackend, err := watch.DefaultBackend()
+	if err != nil {
+		return err
+	}
 	var (
 		rules []watchRule
-		paths []string
+		paths = map[watch.Backend][]string{}
 	)
 	for serviceName, service := range project.Services {
 		config, err := loadDevelopmentConfig(service, project)
@@ -186,7 +188,11 @@ func (s *composeService) watch(ctx context.Context, syncChannel chan bool, proje
 					}
 				}
 			}
-			paths = append(paths, trigger.Path)
+			backend, err := watchBackend(trigger, defaultBackend)
+			if err != nil {
+				return fmt.Errorf("service %q: %w", service.Name, err)
+			}
+			paths[backend] = append(paths[backend], trigger.Path)
 		}
 
 		serviceWatchRules, err := getWatchRules(config, service)
@@ -196,29 +202,37 @@ func (s *composeService) watch(ctx context.Context, syncChannel chan bool, proje
 		rules = append(rules, serviceWatchRules...)
 	}
 
-	if len(paths) == 0 {
+	hotReload := len(hotReloadSecrets(project)) > 0
+	if len(paths) == 0 && !hotReload {
 		return fmt.Errorf("none of the selected services is configured for watch, consider setting an 'develop' section")
 	}
 
-	watcher, err := watch.NewWatcher(paths)
-	if err != nil {
-		return err
-	}
-
-	err = watcher.Start()
-	if err != nil {
-		return err
-	}
+	if len(paths) > 0 {
+		watcher, err := watch.NewWatcherWithBackends(paths)
+		if err != nil {
+			return err
+		}
 
-	defer func() {
-		if err := watcher.Close(); err != nil {
-			logrus.Debugf("Error closing watcher: %v", err)
+		err = watcher.Start()
+		if err != nil {
+			return err
 		}
-	}()
 
-	eg.Go(func() error {
-		return s.watchEvents(ctx, project, options, watcher, syncer, rules)
-	})
+		defer func() {
+			if err := watcher.Close(); err != nil {
+				logrus.Debugf("Error closing watcher: %v", err)
+			}
+		}()
+
+		eg.Go(func() error {
+			return s.watchEvents(ctx, project, options, watcher, syncer, rules)
+		})
+	}
+	if hotReload {
+		eg.Go(func() error {
+			return s.watchSecrets(ctx, project, options)
+		})
+	}
 	options.LogTo.Log(api.WatchLogger, "Watch enabled")
 
 	for {
@@ -283,6 +297,19 @@ func isSync(trigger types.Trigger) bool {
 	return trigger.Action == types.WatchActionSync || trigger.Action == types.WatchActionSyncRestart
 }
 
+// watchBackend returns the backend set on trigger by `x-backend`, or the default one
+func watchBackend(trigger types.Trigger, defaultBackend watch.Backend) (watch.Backend, error) {
+	var backend string
+	ok, err := trigger.Extensions.Get("x-backend", &backend)
+	if err != nil {
+		return "", fmt.Errorf("invalid x-backend for watch path %s: %w", trigger.Path, err)
+	}
+	if !ok {
+		return defaultBackend, nil
+	}
+	return watch.ParseBackend(backend)
+}
+
 func (s *composeService) watchEvents(ctx context.Context, project *types.Project, options api.WatchOptions, watcher watch.Notify, syncer sync.Syncer, rules []watchRule) error {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -18,10 +18,14 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/config"
+	"github.com/docker/cli/cli/flags"
 	"github.com/docker/cli/cli/streams"
 	"github.com/docker/compose/v2/internal/sync"
 	"github.com/docker/compose/v2/pkg/api"
@@ -30,6 +34,7 @@ import (
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/system"
 	"github.com/jonboulle/clockwork"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
@@ -94,6 +99,8 @@ func TestWatch_Sync(t *testing.T) {
 	}, nil).Times(1)
 	apiClient.EXPECT().ImageRemove(gomock.Any(), "123", image.RemoveOptions{}).Times(1)
 	apiClient.EXPECT().ImageRemove(gomock.Any(), "456", image.RemoveOptions{}).Times(1)
+	// rebuild creates containers, which checks engine capabilities
+	apiClient.EXPECT().Info(gomock.Any()).Return(system.Info{}, nil).AnyTimes()
 	//
 	cli.EXPECT().Client().Return(apiClient).AnyTimes()
 
@@ -174,6 +181,42 @@ func TestWatch_Sync(t *testing.T) {
 	// TODO: there's not a great way to assert that the rebuild attempt happened
 }
 
+func TestWatchSyncDryRun(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	apiClient := mocks.NewMockAPIClient(mockCtrl)
+	ctr := testContainer("test", "123456789012", false)
+	apiClient.EXPECT().ContainerList(gomock.Any(), gomock.Any()).Return([]container.Summary{ctr}, nil).AnyTimes()
+	// files are checked against the container, but neither copied nor deleted
+	apiClient.EXPECT().ContainerStatPath(gomock.Any(), ctr.ID, "/").Return(container.PathStat{}, nil).Times(1)
+
+	dir := t.TempDir()
+	previous := config.Dir()
+	config.SetDir(dir)
+	t.Cleanup(func() { config.SetDir(previous) })
+	dockerCli, err := command.NewDockerCli()
+	assert.NilError(t, err)
+	options := flags.NewClientOptions()
+	options.ConfigDir = dir
+	assert.NilError(t, dockerCli.Initialize(options))
+	dryRunClient, err := api.NewDryRunClient(apiClient, dockerCli)
+	assert.NilError(t, err)
+
+	cli := mocks.NewMockCli(mockCtrl)
+	cli.EXPECT().Client().Return(dryRunClient).AnyTimes()
+	cli.EXPECT().Err().Return(streams.NewOut(os.Stderr)).AnyTimes()
+	s := &composeService{dockerCli: cli, dryRun: true}
+
+	changed := filepath.Join(dir, "changed")
+	assert.NilError(t, os.WriteFile(changed, []byte("content"), 0o600))
+	syncer, err := s.getSyncImplementation(&types.Project{Name: "myProjectName"})
+	assert.NilError(t, err)
+	err = syncer.Sync(context.Background(), "test", []*sync.PathMapping{
+		{HostPath: changed, ContainerPath: "/work/changed"},
+		{HostPath: filepath.Join(dir, "deleted"), ContainerPath: "/work/deleted"},
+	})
+	assert.NilError(t, err)
+}
+
 type fakeSyncer struct {
 	synced chan []*sync.PathMapping
 }
@@ -188,3 +231,19 @@ func (f *fakeSyncer) Sync(ctx context.Context, service string, paths []*sync.Pat
 	f.synced <- paths
 	return nil
 }
+
+func TestWatchBackend(t *testing.T) {
+	trigger := types.Trigger{Path: "/src"}
+	backend, err := watchBackend(trigger, watch.BackendAuto)
+	assert.NilError(t, err)
+	assert.Equal(t, backend, watch.BackendAuto)
+
+	trigger.Extensions = types.Extensions{"x-backend": "polling"}
+	backend, err = watchBackend(trigger, watch.BackendAuto)
+	assert.NilError(t, err)
+	assert.Equal(t, backend, watch.BackendPolling)
+
+	trigger.Extensions = types.Extensions{"x-backend": "kqueue"}
+	_, err = watchBackend(trigger, watch.BackendAuto)
+	assert.ErrorContains(t, err, `unsupported watch backend "kqueue"`)
+}
@@ -0,0 +1,201 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compose
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/sirupsen/logrus"
+)
+
+const osWindows = "windows"
+
+// checkIsolation validates the isolation technology requested by service is supported by engine
+func checkIsolation(service types.ServiceConfig, osType string) error {
+	isolation := container.Isolation(service.Isolation)
+	switch {
+	case isolation.IsDefault():
+		return nil
+	case osType != "" && osType != osWindows:
+		return fmt.Errorf("service %q: isolation %q is only supported by Windows containers", service.Name, service.Isolation)
+	case isolation.IsProcess(), isolation.IsHyperV():
+		return nil
+	default:
+		return fmt.Errorf("service %q: invalid isolation %q, expected one of 'default', 'process' or 'hyperv'", service.Name, service.Isolation)
+	}
+}
+
+// checkNamedPipes validates named pipe mounts are only used with a Windows engine
+func checkNamedPipes(service types.ServiceConfig, osType string) error {
+	if osType == "" || osType == osWindows {
+		return nil
+	}
+	for _, v := range service.Volumes {
+		if v.Type == types.VolumeTypeNamedPipe {
+			return fmt.Errorf("service %q: named pipe %s can only be mounted into Windows containers", service.Name, v.Source)
+		}
+	}
+	return nil
+}
+
+// adaptVolumesForWindows returns the volumes of service with targets Windows containers accept: unix absolute paths are
+// set on the C: drive, and paths with a drive letter and named pipes are written with backslashes. Bind options
+// Windows doesn't support are removed with a warning. service volumes are left untouched
+func adaptVolumesForWindows(service types.ServiceConfig) []types.ServiceVolumeConfig {
+	volumes := make([]types.ServiceVolumeConfig, 0, len(service.Volumes))
+	for _, v := range service.Volumes {
+		v.Target = windowsPath(v.Target)
+		if v.Type == types.VolumeTypeNamedPipe {
+			v.Source = windowsPath(v.Source)
+		}
+		if v.Bind != nil && (v.Bind.SELinux != "" || v.Bind.Propagation != "") {
+			logrus.Warnf("service %q: SELinux relabeling and propagation of bind mount %s are not supported by Windows containers, they will be ignored", service.Name, v.Target)
+			bind := *v.Bind
+			bind.SELinux = ""
+			bind.Propagation = ""
+			v.Bind = &bind
+		}
+		volumes = append(volumes, v)
+	}
+	return volumes
+}
+
+// windowsPath converts a unix absolute path to a path on the C: drive, as Windows containers require a drive letter.
+// Paths with a drive letter and named pipes only get their separators converted to backslashes
+func windowsPath(p string) string {
+	switch {
+	case isWindowsAbs(p), strings.HasPrefix(p, `\\`), strings.HasPrefix(p, "//"):
+		return strings.ReplaceAll(p, "/", `\`)
+	case isUnixAbs(p):
+		return "C:" + strings.ReplaceAll(p, "/", `\`)
+	default:
+		return p
+	}
+}
+
+// adaptHostConfigForWindows removes from hostConfig the options Windows containers do not support, so that engine
+// doesn't reject the container with an obscure error. A warning is emitted for each option being ignored
+func adaptHostConfigForWindows(service types.ServiceConfig, hostConfig *container.HostConfig) {
+	ignore := func(option string) {
+		logrus.Warnf("service %q: %s is not supported by Windows containers, it will be ignored", service.Name, option)
+	}
+
+	if len(hostConfig.CapAdd) > 0 {
+		ignore("cap_add")
+		hostConfig.CapAdd = nil
+	}
+	if len(hostConfig.CapDrop) > 0 {
+		ignore("cap_drop")
+		hostConfig.CapDrop = nil
+	}
+	if hostConfig.Privileged {
+		ignore("privileged")
+		hostConfig.Privileged = false
+	}
+	if hostConfig.Init != nil && *hostConfig.Init {
+		ignore("init")
+		hostConfig.Init = nil
+	}
+	if len(hostConfig.Sysctls) > 0 {
+		ignore("sysctls")
+		hostConfig.Sysctls = nil
+	}
+	if hostConfig.ShmSize != 0 {
+		ignore("shm_size")
+		hostConfig.ShmSize = 0
+	}
+	if len(hostConfig.SecurityOpt) > 0 {
+		ignore("security_opt")
+		hostConfig.SecurityOpt = nil
+	}
+	if hostConfig.OomScoreAdj != 0 {
+		ignore("oom_score_adj")
+		hostConfig.OomScoreAdj = 0
+	}
+	if hostConfig.PidMode != "" {
+		ignore("pid")
+		hostConfig.PidMode = ""
+	}
+	if hostConfig.UsernsMode != "" {
+		ignore("userns_mode")
+		hostConfig.UsernsMode = ""
+	}
+	if hostConfig.CgroupnsMode != "" {
+		ignore("cgroup")
+		hostConfig.CgroupnsMode = ""
+	}
+	if hostConfig.IpcMode != "" {
+		ignore("ipc")
+		hostConfig.IpcMode = ""
+	}
+	if hostConfig.ReadonlyRootfs {
+		ignore("read_only")
+		hostConfig.ReadonlyRootfs = false
+	}
+	if len(hostConfig.GroupAdd) > 0 {
+		ignore("group_add")
+		hostConfig.GroupAdd = nil
+	}
+	if len(hostConfig.Tmpfs) > 0 {
+		ignore("tmpfs")
+		hostConfig.Tmpfs = nil
+	}
+	hostConfig.MaskedPaths = nil
+	hostConfig.ReadonlyPaths = nil
+
+	var mounts []mount.Mount
+	for _, m := range hostConfig.Mounts {
+		if m.Type == mount.TypeTmpfs {
+			ignore(fmt.Sprintf("tmpfs mount %s", m.Target))
+			continue
+		}
+		// secrets and configs are mounted to unix paths
+		m.Target = windowsPath(m.Target)
+		mounts = append(mounts, m)
+	}
+	hostConfig.Mounts = mounts
+
+	resources := &hostConfig.Resources
+	if len(resources.Ulimits) > 0 {
+		ignore("ulimits")
+		resources.Ulimits = nil
+	}
+	if resources.PidsLimit != nil {
+		ignore("pids_limit")
+		resources.PidsLimit = nil
+	}
+	if resources.OomKillDisable != nil && *resources.OomKillDisable {
+		ignore("oom_kill_disable")
+		resources.OomKillDisable = nil
+	}
+	if resources.MemorySwappiness != nil {
+		ignore("mem_swappiness")
+		resources.MemorySwappiness = nil
+	}
+	if resources.MemorySwap != 0 {
+		ignore("memswap_limit")
+		resources.MemorySwap = 0
+	}
+	if resources.CgroupParent != "" {
+		ignore("cgroup_parent")
+		resources.CgroupParent = ""
+	}
+}
@@ -0,0 +1,284 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package composetest runs Compose projects from Go tests, the way net/http/httptest runs HTTP servers: a project is
+// started under a name unique to the test, its logs are written to the test log and it is removed when the test ends.
+package composetest
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/cli"
+	"github.com/compose-spec/compose-go/v2/loader"
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/flags"
+
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/compose"
+)
+
+// DefaultTimeout is the time given to services to become running or healthy
+const DefaultTimeout = 2 * time.Minute
+
+// Option customizes how a project is started
+type Option func(*options)
+
+type options struct {
+	services []string
+	env      []string
+	profiles []string
+	build    bool
+	timeout  time.Duration
+	logs     bool
+}
+
+// WithServices only starts services, and the ones they depend on
+func WithServices(services ...string) Option {
+	return func(o *options) {
+		o.services = append(o.services, services...)
+	}
+}
+
+// WithEnv sets KEY=VALUE variables used to interpolate the Compose files, in addition to the process environment
+func WithEnv(env ...string) Option {
+	return func(o *options) {
+		o.env = append(o.env, env...)
+	}
+}
+
+// WithProfiles enables profiles
+func WithProfiles(profiles ...string) Option {
+	return func(o *options) {
+		o.profiles = append(o.profiles, profiles...)
+	}
+}
+
+// WithBuild builds images before starting services, like `up --build`
+func WithBuild() Option {
+	return func(o *options) {
+		o.build = true
+	}
+}
+
+// WithTimeout overrides DefaultTimeout
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithoutLogs doesn't write service logs to the test log
+func WithoutLogs() Option {
+	return func(o *options) {
+		o.logs = false
+	}
+}
+
+// Project is a Compose project running for a test
+type Project struct {
+	*types.Project
+	t       testing.TB
+	service api.Service
+}
+
+// Up loads the Compose files, or the default ones from the working directory if none is set, and starts the project
+// under a unique name. It returns once services are running or healthy, failing the test if they don't within the
+// timeout. Containers, networks and volumes are removed when the test completes.
+func Up(t testing.TB, files []string, opts ...Option) *Project {
+	t.Helper()
+	o := options{timeout: DefaultTimeout, logs: true}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	dockerCli, err := command.NewDockerCli(command.WithCombinedStreams(&testWriter{t: t}))
+	if err != nil {
+		t.Fatalf("creating docker client: %v", err)
+	}
+	if err := dockerCli.Initialize(flags.NewClientOptions()); err != nil {
+		t.Fatalf("initializing docker client: %v", err)
+	}
+
+	project, err := load(context.Background(), projectName(t), files, o)
+	if err != nil {
+		t.Fatalf("loading compose project: %v", err)
+	}
+
+	p := &Project{Project: project, t: t, service: compose.NewComposeService(dockerCli)}
+	ctx, stopLogs := context.WithCancel(context.Background())
+	var logs sync.WaitGroup
+	t.Cleanup(func() {
+		stopLogs()
+		logs.Wait()
+		p.down()
+	})
+
+	var build *api.BuildOptions
+	if o.build {
+		build = &api.BuildOptions{Services: o.services}
+	}
+	err = p.service.Up(context.Background(), project, api.UpOptions{
+		Create: api.CreateOptions{
+			Build:         build,
+			Services:      o.services,
+			RemoveOrphans: true,
+			Recreate:      api.RecreateDiverged,
+		},
+		Start: api.StartOptions{
+			Project:     project,
+			Services:    o.services,
+			Wait:        true,
+			WaitTimeout: o.timeout,
+		},
+	})
+	if o.logs {
+		logs.Add(1)
+		go func() {
+			defer logs.Done()
+			_ = p.service.Logs(ctx, project.Name, logConsumer{t: t}, api.LogOptions{Project: project, Services: o.services, Follow: true})
+		}()
+	}
+	if err != nil {
+		t.Fatalf("starting compose project %s: %v", project.Name, err)
+	}
+	return p
+}
+
+// load loads the project as the compose CLI does, labelling services so they can be managed by the compose API
+func load(ctx context.Context, name string, files []string, o options) (*types.Project, error) {
+	projectOptions, err := cli.NewProjectOptions(files,
+		cli.WithName(name),
+		cli.WithOsEnv,
+		cli.WithEnv(o.env),
+		cli.WithDotEnv,
+		cli.WithDefaultConfigPath,
+		cli.WithProfiles(o.profiles),
+	)
+	if err != nil {
+		return nil, err
+	}
+	project, err := projectOptions.LoadProject(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for name, s := range project.Services {
+		s.CustomLabels = map[string]string{
+			api.ProjectLabel:     project.Name,
+			api.ServiceLabel:     name,
+			api.VersionLabel:     api.ComposeVersion,
+			api.WorkingDirLabel:  project.WorkingDir,
+			api.ConfigFilesLabel: strings.Join(project.ComposeFiles, ","),
+			api.OneoffLabel:      "False",
+		}
+		project.Services[name] = s
+	}
+	return project.WithSelectedServices(o.services)
+}
+
+// Service returns the compose API managing the project, for tests to act on running services
+func (p *Project) Service() api.Service {
+	return p.service
+}
+
+// Endpoint returns the host:port address port of service is published on, failing the test if it isn't published
+func (p *Project) Endpoint(service string, port uint16) string {
+	p.t.Helper()
+	host, published, err := p.service.Port(context.Background(), p.Name, service, port, api.PortOptions{})
+	if err != nil {
+		p.t.Fatalf("port %d of service %s: %v", port, service, err)
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "localhost"
+	}
+	return net.JoinHostPort(host, strconv.Itoa(published))
+}
+
+func (p *Project) down() {
+	p.t.Helper()
+	err := p.service.Down(context.Background(), p.Name, api.DownOptions{
+		Project:       p.Project,
+		RemoveOrphans: true,
+		Volumes:       true,
+	})
+	if err != nil {
+		p.t.Errorf("removing compose project %s: %v", p.Name, err)
+	}
+}
+
+// projectName derives a project name from the test name, with a random suffix so tests running in parallel, or
+// repeatedly with -count, don't share containers
+func projectName(t testing.TB) string {
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	name := loader.NormalizeProjectName(strings.ReplaceAll(t.Name(), "/", "-"))
+	if name == "" {
+		name = "test"
+	}
+	return fmt.Sprintf("%s-%s", name, hex.EncodeToString(suffix))
+}
+
+// logConsumer writes service logs to the test log
+type logConsumer struct {
+	t testing.TB
+}
+
+func (l logConsumer) Log(container, message string) {
+	l.t.Logf("%s | %s", container, message)
+}
+
+func (l logConsumer) Err(container, message string) {
+	l.t.Logf("%s | %s", container, message)
+}
+
+func (l logConsumer) Status(container, message string) {
+	l.t.Logf("%s %s", container, message)
+}
+
+func (l logConsumer) Register(string) {}
+
+// testWriter writes the compose progress output to the test log, line by line
+type testWriter struct {
+	t   testing.TB
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (w *testWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf.Write(p)
+	for {
+		line, err := w.buf.ReadString('\n')
+		if err != nil {
+			// keep incomplete line until the next write
+			w.buf.Reset()
+			w.buf.WriteString(line)
+			return len(p), nil
+		}
+		w.t.Log(strings.TrimRight(line, "\r\n"))
+	}
+}
@@ -0,0 +1,52 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package composetest
+
+import (
+	"regexp"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestProjectName(t *testing.T) {
+	t.Run("Sub Test", func(t *testing.T) {
+		name := projectName(t)
+		assert.Assert(t, regexp.MustCompile(`^testprojectname-sub_test-[0-9a-f]{8}$`).MatchString(name), name)
+		assert.Assert(t, name != projectName(t))
+	})
+}
+
+type recorder struct {
+	testing.TB
+	lines []string
+}
+
+func (r *recorder) Log(args ...any) {
+	r.lines = append(r.lines, args[0].(string))
+}
+
+func TestTestWriter(t *testing.T) {
+	r := &recorder{TB: t}
+	w := &testWriter{t: r}
+	_, err := w.Write([]byte("Container a Creat"))
+	assert.NilError(t, err)
+	assert.Assert(t, r.lines == nil)
+	_, err = w.Write([]byte("ed\r\nContainer a Started\nContainer b"))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, r.lines, []string{"Container a Created", "Container a Started"})
+}
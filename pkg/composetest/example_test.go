@@ -0,0 +1,35 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package composetest_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/docker/compose/v2/pkg/composetest"
+)
+
+func ExampleUp() {
+	var t *testing.T // the *testing.T received by the test function
+
+	project := composetest.Up(t, []string{"testdata/compose.yaml"}, composetest.WithServices("web"))
+	resp, err := http.Get("http://" + project.Endpoint("web", 80))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+}
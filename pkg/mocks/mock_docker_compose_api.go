@@ -41,6 +41,20 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// Apply mocks base method.
+func (m *MockService) Apply(ctx context.Context, project *types.Project, options api.ApplyOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Apply", ctx, project, options)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Apply indicates an expected call of Apply.
+func (mr *MockServiceMockRecorder) Apply(ctx, project, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Apply", reflect.TypeOf((*MockService)(nil).Apply), ctx, project, options)
+}
+
 // Attach mocks base method.
 func (m *MockService) Attach(ctx context.Context, projectName string, options api.AttachOptions) error {
 	m.ctrl.T.Helper()
@@ -69,6 +83,20 @@ func (mr *MockServiceMockRecorder) Build(ctx, project, options any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Build", reflect.TypeOf((*MockService)(nil).Build), ctx, project, options)
 }
 
+// Chaos mocks base method.
+func (m *MockService) Chaos(ctx context.Context, project *types.Project, options api.ChaosOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Chaos", ctx, project, options)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Chaos indicates an expected call of Chaos.
+func (mr *MockServiceMockRecorder) Chaos(ctx, project, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Chaos", reflect.TypeOf((*MockService)(nil).Chaos), ctx, project, options)
+}
+
 // Commit mocks base method.
 func (m *MockService) Commit(ctx context.Context, projectName string, options api.CommitOptions) error {
 	m.ctrl.T.Helper()
@@ -97,6 +125,20 @@ func (mr *MockServiceMockRecorder) Copy(ctx, projectName, options any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Copy", reflect.TypeOf((*MockService)(nil).Copy), ctx, projectName, options)
 }
 
+// CopyArtifact mocks base method.
+func (m *MockService) CopyArtifact(ctx context.Context, source, destination string, options api.CopyArtifactOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CopyArtifact", ctx, source, destination, options)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CopyArtifact indicates an expected call of CopyArtifact.
+func (mr *MockServiceMockRecorder) CopyArtifact(ctx, source, destination, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CopyArtifact", reflect.TypeOf((*MockService)(nil).CopyArtifact), ctx, source, destination, options)
+}
+
 // Create mocks base method.
 func (m *MockService) Create(ctx context.Context, project *types.Project, options api.CreateOptions) error {
 	m.ctrl.T.Helper()
@@ -111,6 +153,51 @@ func (mr *MockServiceMockRecorder) Create(ctx, project, options any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockService)(nil).Create), ctx, project, options)
 }
 
+// DNSCheck mocks base method.
+func (m *MockService) DNSCheck(ctx context.Context, project *types.Project, options api.DNSCheckOptions) ([]api.DNSCheckResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DNSCheck", ctx, project, options)
+	ret0, _ := ret[0].([]api.DNSCheckResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DNSCheck indicates an expected call of DNSCheck.
+func (mr *MockServiceMockRecorder) DNSCheck(ctx, project, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DNSCheck", reflect.TypeOf((*MockService)(nil).DNSCheck), ctx, project, options)
+}
+
+// Debug mocks base method.
+func (m *MockService) Debug(ctx context.Context, projectName string, options api.DebugOptions) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Debug", ctx, projectName, options)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Debug indicates an expected call of Debug.
+func (mr *MockServiceMockRecorder) Debug(ctx, projectName, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Debug", reflect.TypeOf((*MockService)(nil).Debug), ctx, projectName, options)
+}
+
+// Diff mocks base method.
+func (m *MockService) Diff(ctx context.Context, projectName, service string, options api.DiffOptions) ([]api.FileChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Diff", ctx, projectName, service, options)
+	ret0, _ := ret[0].([]api.FileChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Diff indicates an expected call of Diff.
+func (mr *MockServiceMockRecorder) Diff(ctx, projectName, service, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Diff", reflect.TypeOf((*MockService)(nil).Diff), ctx, projectName, service, options)
+}
+
 // Down mocks base method.
 func (m *MockService) Down(ctx context.Context, projectName string, options api.DownOptions) error {
 	m.ctrl.T.Helper()
@@ -213,6 +300,21 @@ func (mr *MockServiceMockRecorder) Images(ctx, projectName, options any) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Images", reflect.TypeOf((*MockService)(nil).Images), ctx, projectName, options)
 }
 
+// ImportK8s mocks base method.
+func (m *MockService) ImportK8s(ctx context.Context, options api.ImportK8sOptions) (*types.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportK8s", ctx, options)
+	ret0, _ := ret[0].(*types.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportK8s indicates an expected call of ImportK8s.
+func (mr *MockServiceMockRecorder) ImportK8s(ctx, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportK8s", reflect.TypeOf((*MockService)(nil).ImportK8s), ctx, options)
+}
+
 // Kill mocks base method.
 func (m *MockService) Kill(ctx context.Context, projectName string, options api.KillOptions) error {
 	m.ctrl.T.Helper()
@@ -298,6 +400,20 @@ func (mr *MockServiceMockRecorder) Port(ctx, projectName, service, port, options
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Port", reflect.TypeOf((*MockService)(nil).Port), ctx, projectName, service, port, options)
 }
 
+// PortForward mocks base method.
+func (m *MockService) PortForward(ctx context.Context, projectName string, options api.PortForwardOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PortForward", ctx, projectName, options)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PortForward indicates an expected call of PortForward.
+func (mr *MockServiceMockRecorder) PortForward(ctx, projectName, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PortForward", reflect.TypeOf((*MockService)(nil).PortForward), ctx, projectName, options)
+}
+
 // Ps mocks base method.
 func (m *MockService) Ps(ctx context.Context, projectName string, options api.PsOptions) ([]api.ContainerSummary, error) {
 	m.ctrl.T.Helper()
@@ -383,6 +499,20 @@ func (mr *MockServiceMockRecorder) Restart(ctx, projectName, options any) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restart", reflect.TypeOf((*MockService)(nil).Restart), ctx, projectName, options)
 }
 
+// RestoreSnapshot mocks base method.
+func (m *MockService) RestoreSnapshot(ctx context.Context, projectName string, options api.RestoreSnapshotOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreSnapshot", ctx, projectName, options)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreSnapshot indicates an expected call of RestoreSnapshot.
+func (mr *MockServiceMockRecorder) RestoreSnapshot(ctx, projectName, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreSnapshot", reflect.TypeOf((*MockService)(nil).RestoreSnapshot), ctx, projectName, options)
+}
+
 // RunOneOffContainer mocks base method.
 func (m *MockService) RunOneOffContainer(ctx context.Context, project *types.Project, opts api.RunOptions) (int, error) {
 	m.ctrl.T.Helper()
@@ -412,6 +542,20 @@ func (mr *MockServiceMockRecorder) Scale(ctx, project, options any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Scale", reflect.TypeOf((*MockService)(nil).Scale), ctx, project, options)
 }
 
+// Snapshot mocks base method.
+func (m *MockService) Snapshot(ctx context.Context, projectName string, options api.SnapshotOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Snapshot", ctx, projectName, options)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Snapshot indicates an expected call of Snapshot.
+func (mr *MockServiceMockRecorder) Snapshot(ctx, projectName, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Snapshot", reflect.TypeOf((*MockService)(nil).Snapshot), ctx, projectName, options)
+}
+
 // Start mocks base method.
 func (m *MockService) Start(ctx context.Context, projectName string, options api.StartOptions) error {
 	m.ctrl.T.Helper()
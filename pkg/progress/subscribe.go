@@ -0,0 +1,101 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package progress
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Update is a progress event delivered to subscribers, completed with timing information
+type Update struct {
+	Event
+	// Time is when the event was emitted
+	Time time.Time
+	// Started is when the first event for the same ID was emitted
+	Started time.Time
+	// Elapsed is the time spent since Started, set once the event reaches a final status
+	Elapsed time.Duration
+}
+
+// resourceKinds are the prefixes compose uses for progress event IDs
+var resourceKinds = []string{"Container", "Network", "Volume", "Image", "Secret", "Config"}
+
+// Resource splits the event ID into the kind of resource it relates to, such as "Container", and its name. Kind is
+// empty for events which don't relate to a typed resource, typically service builds and pulls named after the service
+func (u Update) Resource() (kind string, name string) {
+	if k, n, ok := strings.Cut(u.ID, " "); ok {
+		for _, known := range resourceKinds {
+			if k == known {
+				return k, n
+			}
+		}
+	}
+	return "", u.ID
+}
+
+// Subscribe returns a context which delivers progress events of the compose operations ran with it to the returned
+// channel, so applications embedding compose can render progress natively. Subscribers must drain the channel, as
+// operations wait for events to be received, and call the returned function once done to release the subscription
+// and close the channel. Set Mode to ModeQuiet to also disable the text rendering.
+func Subscribe(ctx context.Context) (context.Context, <-chan Update, func()) {
+	var (
+		mu      sync.Mutex
+		sending sync.WaitGroup
+		started = map[string]time.Time{}
+		closed  bool
+		updates = make(chan Update)
+		done    = make(chan struct{})
+	)
+	ctx = WithEventObserver(ctx, func(e Event) {
+		now := time.Now()
+		mu.Lock()
+		if closed {
+			mu.Unlock()
+			return
+		}
+		first, ok := started[e.ID]
+		if !ok {
+			first = now
+			started[e.ID] = now
+		}
+		sending.Add(1)
+		mu.Unlock()
+		defer sending.Done()
+		update := Update{Event: e, Time: now, Started: first}
+		if e.Status != Working {
+			update.Elapsed = now.Sub(first)
+		}
+		select {
+		case updates <- update:
+		case <-done:
+		}
+	})
+	var once sync.Once
+	return ctx, updates, func() {
+		once.Do(func() {
+			mu.Lock()
+			closed = true
+			mu.Unlock()
+			close(done)
+			sending.Wait()
+			close(updates)
+		})
+	}
+}
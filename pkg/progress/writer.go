@@ -52,6 +52,38 @@ func ContextWriter(ctx context.Context) Writer {
 	return s
 }
 
+type observerKey struct{}
+
+// WithEventObserver registers a function receiving a copy of every progress event, in addition to the writer
+// rendering them and to observers already registered on ctx. The observer may be called concurrently.
+func WithEventObserver(ctx context.Context, observer func(Event)) context.Context {
+	if previous, ok := ctx.Value(observerKey{}).(func(Event)); ok {
+		next := observer
+		observer = func(e Event) {
+			previous(e)
+			next(e)
+		}
+	}
+	return context.WithValue(ctx, observerKey{}, observer)
+}
+
+type observedWriter struct {
+	Writer
+	observe func(Event)
+}
+
+func (w observedWriter) Event(e Event) {
+	w.observe(e)
+	w.Writer.Event(e)
+}
+
+func (w observedWriter) Events(events []Event) {
+	for _, e := range events {
+		w.observe(e)
+	}
+	w.Writer.Events(events)
+}
+
 type progressFunc func(context.Context) error
 
 type progressFuncWithStatus func(context.Context) (string, error)
@@ -79,6 +111,9 @@ func RunWithStatus(ctx context.Context, pf progressFuncWithStatus, out *streams.
 	if err != nil {
 		return "", err
 	}
+	if observe, ok := ctx.Value(observerKey{}).(func(Event)); ok {
+		w = observedWriter{Writer: w, observe: observe}
+	}
 	eg.Go(func() error {
 		return w.Start(context.Background())
 	})
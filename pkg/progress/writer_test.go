@@ -17,9 +17,13 @@
 package progress
 
 import (
+	"bytes"
 	"context"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/docker/cli/cli/streams"
 	"gotest.tools/v3/assert"
 )
 
@@ -29,3 +33,59 @@ func TestNoopWriter(t *testing.T) {
 
 	assert.Equal(t, writer, &noopWriter{})
 }
+
+func TestEventObserver(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		seen []string
+	)
+	ctx := WithEventObserver(context.TODO(), func(e Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		seen = append(seen, e.ID+" "+e.StatusText)
+	})
+	out := streams.NewOut(&bytes.Buffer{})
+	err := Run(ctx, func(ctx context.Context) error {
+		w := ContextWriter(ctx)
+		w.Event(CreatingEvent("Container test-1"))
+		w.Events([]Event{CreatedEvent("Container test-1"), StartedEvent("Container test-2")})
+		return nil
+	}, out)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, seen, []string{"Container test-1 Creating", "Container test-1 Created", "Container test-2 Started"})
+}
+
+func TestSubscribe(t *testing.T) {
+	ctx, updates, unsubscribe := Subscribe(context.TODO())
+	var received []Update
+	collected := make(chan struct{})
+	go func() {
+		defer close(collected)
+		for u := range updates {
+			received = append(received, u)
+		}
+	}()
+
+	out := streams.NewOut(&bytes.Buffer{})
+	err := Run(ctx, func(ctx context.Context) error {
+		w := ContextWriter(ctx)
+		w.Event(CreatingEvent("Container test-1"))
+		w.Event(CreatedEvent("Container test-1"))
+		w.Event(Event{ID: "web", Text: "Pulled", Status: Done})
+		return nil
+	}, out)
+	assert.NilError(t, err)
+	unsubscribe()
+	<-collected
+
+	assert.Equal(t, len(received), 3)
+	kind, name := received[0].Resource()
+	assert.Equal(t, kind, "Container")
+	assert.Equal(t, name, "test-1")
+	assert.Equal(t, received[0].Elapsed, time.Duration(0))
+	assert.Equal(t, received[1].Started, received[0].Started)
+	assert.Equal(t, received[1].Elapsed, received[1].Time.Sub(received[0].Started))
+	kind, name = received[2].Resource()
+	assert.Equal(t, kind, "")
+	assert.Equal(t, name, "web")
+}
@@ -17,8 +17,11 @@
 package prompt
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/docker/cli/cli/streams"
@@ -30,6 +33,8 @@ import (
 // UI - prompt user input
 type UI interface {
 	Confirm(message string, defaultValue bool) (bool, error)
+	Input(message string, defaultValue string) (string, error)
+	Password(message string) (string, error)
 }
 
 func NewPrompt(stdin *streams.In, stdout *streams.Out) UI {
@@ -86,6 +91,35 @@ func (u User) Confirm(message string, defaultValue bool) (bool, error) {
 	return b, err
 }
 
+// Input asks for text input
+func (u User) Input(message string, defaultValue string) (string, error) {
+	qs := &survey.Input{
+		Message: message,
+		Default: defaultValue,
+	}
+	var s string
+	err := survey.AskOne(qs, &s, func(options *survey.AskOptions) error {
+		options.Stdio.In = u.stdin
+		options.Stdio.Out = u.stdout
+		return nil
+	})
+	return s, err
+}
+
+// Password asks for text input without echoing it
+func (u User) Password(message string) (string, error) {
+	qs := &survey.Password{
+		Message: message,
+	}
+	var s string
+	err := survey.AskOne(qs, &s, func(options *survey.AskOptions) error {
+		options.Stdio.In = u.stdin
+		options.Stdio.Out = u.stdout
+		return nil
+	})
+	return s, err
+}
+
 // Pipe - aggregates prompt methods
 type Pipe struct {
 	stdout io.Writer
@@ -99,3 +133,22 @@ func (u Pipe) Confirm(message string, defaultValue bool) (bool, error) {
 	_, _ = fmt.Fscanln(u.stdin, &answer)
 	return utils.StringToBool(answer), nil
 }
+
+// Input asks for text input
+func (u Pipe) Input(message string, defaultValue string) (string, error) {
+	_, _ = fmt.Fprint(u.stdout, message)
+	answer, err := bufio.NewReader(u.stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	answer = strings.TrimRight(answer, "\r\n")
+	if answer == "" {
+		return defaultValue, nil
+	}
+	return answer, nil
+}
+
+// Password asks for text input
+func (u Pipe) Password(message string) (string, error) {
+	return u.Input(message, "")
+}
@@ -0,0 +1,99 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package remote
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/go-version"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// AnnotationsFile stores the manifest annotations of a compose OCI artifact, next to the compose file
+const AnnotationsFile = "compose-annotations.json"
+
+// AnnotationMinVersion is the manifest annotation declaring the minimum version of Compose an artifact requires
+const AnnotationMinVersion = "com.docker.compose.min-version"
+
+// ArtifactInfo describes a compose OCI artifact a project was loaded from, as declared by its manifest annotations
+type ArtifactInfo struct {
+	Reference   string            `json:"reference"`
+	Digest      string            `json:"digest"`
+	Version     string            `json:"version,omitempty"`
+	Description string            `json:"description,omitempty"`
+	License     string            `json:"license,omitempty"`
+	MinVersion  string            `json:"minVersion,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// writeAnnotationsFile stores the manifest annotations of the artifact pulled into local
+func writeAnnotationsFile(local string, annotations map[string]string) error {
+	if len(annotations) == 0 {
+		return nil
+	}
+	b, err := json.Marshal(annotations)
+	if err != nil {
+		return err
+	}
+	return writeLocalFile(local, AnnotationsFile, b)
+}
+
+// readArtifactInfo describes the artifact loaded from path into local. Artifacts cached by older versions have no
+// annotations recorded
+func readArtifactInfo(path, local, digest string) (ArtifactInfo, error) {
+	info := ArtifactInfo{Reference: path, Digest: digest}
+	b, err := os.ReadFile(filepath.Join(local, AnnotationsFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return info, nil
+	}
+	if err != nil {
+		return info, err
+	}
+	if err := json.Unmarshal(b, &info.Annotations); err != nil {
+		return info, err
+	}
+	info.Version = info.Annotations[v1.AnnotationVersion]
+	info.Description = info.Annotations[v1.AnnotationDescription]
+	info.License = info.Annotations[v1.AnnotationLicenses]
+	info.MinVersion = info.Annotations[AnnotationMinVersion]
+	return info, nil
+}
+
+// minVersionWarning returns a warning when the artifact requires a version of Compose newer than current, or
+// declares an invalid minimum version. Development builds have no version to compare with
+func minVersionWarning(info ArtifactInfo, current string) string {
+	if info.MinVersion == "" {
+		return ""
+	}
+	required, err := version.NewVersion(info.MinVersion)
+	if err != nil {
+		return fmt.Sprintf("%s declares an invalid %s annotation %q: %v", info.Reference, AnnotationMinVersion, info.MinVersion, err)
+	}
+	if current == "" {
+		return ""
+	}
+	running, err := version.NewVersion(current)
+	if err != nil || !running.LessThan(required) {
+		return ""
+	}
+	return fmt.Sprintf("%s requires Docker Compose %s or later, but this is version %s. Some features may not work as expected", info.Reference, info.MinVersion, current)
+}
@@ -0,0 +1,64 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package remote
+
+import (
+	"strings"
+	"testing"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+)
+
+func TestReadArtifactInfo(t *testing.T) {
+	local := t.TempDir()
+	info, err := readArtifactInfo("oci://example.com/app:1.0", local, "sha256:abcd")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, info, ArtifactInfo{Reference: "oci://example.com/app:1.0", Digest: "sha256:abcd"})
+
+	annotations := map[string]string{
+		v1.AnnotationVersion:     "1.0.2",
+		v1.AnnotationDescription: "Payments stack",
+		v1.AnnotationLicenses:    "Apache-2.0",
+		AnnotationMinVersion:     "2.30.0",
+	}
+	assert.NilError(t, writeAnnotationsFile(local, annotations))
+	info, err = readArtifactInfo("oci://example.com/app:1.0", local, "sha256:abcd")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, info, ArtifactInfo{
+		Reference:   "oci://example.com/app:1.0",
+		Digest:      "sha256:abcd",
+		Version:     "1.0.2",
+		Description: "Payments stack",
+		License:     "Apache-2.0",
+		MinVersion:  "2.30.0",
+		Annotations: annotations,
+	})
+}
+
+func TestMinVersionWarning(t *testing.T) {
+	info := ArtifactInfo{Reference: "oci://example.com/app:1.0", MinVersion: "2.30.0"}
+	assert.Equal(t, minVersionWarning(info, "2.30.1"), "")
+	assert.Equal(t, minVersionWarning(info, "2.30.0"), "")
+	assert.Equal(t, minVersionWarning(info, ""), "")
+	assert.Equal(t, minVersionWarning(info, "2.29.7"),
+		"oci://example.com/app:1.0 requires Docker Compose 2.30.0 or later, but this is version 2.29.7. Some features may not work as expected")
+	assert.Equal(t, minVersionWarning(ArtifactInfo{Reference: "oci://example.com/app:1.0"}, "2.29.7"), "")
+
+	info.MinVersion = "latest"
+	assert.Assert(t, strings.Contains(minVersionWarning(info, "2.29.7"), `declares an invalid com.docker.compose.min-version annotation "latest"`))
+}
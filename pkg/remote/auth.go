@@ -0,0 +1,149 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/docker/buildx/util/imagetools"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/config/credentials"
+	clitypes "github.com/docker/cli/cli/config/types"
+)
+
+// IncludeAuth maps a remote resource prefix to the credential helper used to access it. Helpers for oci:// resources
+// are docker credential helpers (docker-credential-<name>), helpers for git resources are git credential helpers
+type IncludeAuth map[string]string
+
+// ParseIncludeAuth parses a comma-separated list of PREFIX=HELPER auth hints
+func ParseIncludeAuth(value string) (IncludeAuth, error) {
+	hints := IncludeAuth{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		prefix, helper, ok := strings.Cut(entry, "=")
+		if !ok || prefix == "" || helper == "" {
+			return nil, fmt.Errorf("invalid include auth %q, expected PREFIX=HELPER", entry)
+		}
+		hints[prefix] = helper
+	}
+	return hints, nil
+}
+
+// Helper returns the credential helper configured by the longest prefix matching path
+func (a IncludeAuth) Helper(path string) string {
+	var match, helper string
+	for prefix, h := range a {
+		if strings.HasPrefix(path, prefix) && len(prefix) > len(match) {
+			match, helper = prefix, h
+		}
+	}
+	return helper
+}
+
+// RegistryAuth maps a registry host, optionally with a port, to the credentials used to pull oci:// resources from
+// it, rather than the ones set by the Docker CLI configuration
+type RegistryAuth map[string]RegistryCredentials
+
+// RegistryCredentials designates either a docker credential helper, or a static token sent as bearer token
+type RegistryCredentials struct {
+	Helper string
+	Token  string
+}
+
+// ParseRegistryAuth parses a comma-separated list of HOST=helper:NAME or HOST=token:TOKEN entries
+func ParseRegistryAuth(value string) (RegistryAuth, error) {
+	registries := RegistryAuth{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		// entries are not reported in errors, as they may hold a token
+		host, credentials, ok := strings.Cut(entry, "=")
+		if !ok || host == "" {
+			return nil, errors.New("invalid registry auth, expected HOST=helper:NAME or HOST=token:TOKEN entries")
+		}
+		kind, secret, _ := strings.Cut(credentials, ":")
+		if secret == "" {
+			kind = ""
+		}
+		switch kind {
+		case "helper":
+			registries[host] = RegistryCredentials{Helper: secret}
+		case "token":
+			registries[host] = RegistryCredentials{Token: secret}
+		default:
+			return nil, fmt.Errorf("invalid registry auth for %s, expected helper:NAME or token:TOKEN", host)
+		}
+	}
+	return registries, nil
+}
+
+// lookup returns the credentials set for a registry host, Docker Hub being designated by docker.io
+func (a RegistryAuth) lookup(host string) (RegistryCredentials, bool) {
+	switch host {
+	case "registry-1.docker.io", "index.docker.io", "https://index.docker.io/v1/":
+		host = "docker.io"
+	}
+	c, ok := a[host]
+	return c, ok
+}
+
+// registryHelperAuth resolves credentials of registries with a credential helper set by RegistryAuth using that
+// helper, and the ones of other registries with fallback
+type registryHelperAuth struct {
+	dockerCli  command.Cli
+	registries RegistryAuth
+	fallback   imagetools.Auth
+}
+
+func (a registryHelperAuth) GetAuthConfig(registryHostname string) (clitypes.AuthConfig, error) {
+	if c, ok := a.registries.lookup(registryHostname); ok && c.Helper != "" {
+		return credentialHelperAuth{dockerCli: a.dockerCli, helper: c.Helper}.GetAuthConfig(registryHostname)
+	}
+	if a.fallback == nil {
+		return clitypes.AuthConfig{}, nil
+	}
+	return a.fallback.GetAuthConfig(registryHostname)
+}
+
+// credentialHelperAuth resolves registry credentials using a specific docker credential helper
+type credentialHelperAuth struct {
+	dockerCli command.Cli
+	helper    string
+}
+
+func (a credentialHelperAuth) GetAuthConfig(registryHostname string) (clitypes.AuthConfig, error) {
+	return credentials.NewNativeStore(a.dockerCli.ConfigFile(), a.helper).Get(registryHostname)
+}
+
+// gitCredentialHelperEnv configures git environment so helper is the only credential helper used
+func gitCredentialHelperEnv(env map[string]string, helper string) {
+	count, _ := strconv.Atoi(env["GIT_CONFIG_COUNT"])
+	// an empty value resets the list of credential helpers inherited from git configuration
+	for i, value := range []string{"", helper} {
+		env[fmt.Sprintf("GIT_CONFIG_KEY_%d", count+i)] = "credential.helper"
+		env[fmt.Sprintf("GIT_CONFIG_VALUE_%d", count+i)] = value
+	}
+	env["GIT_CONFIG_COUNT"] = strconv.Itoa(count + 2)
+}
@@ -0,0 +1,82 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package remote
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestIncludeAuth(t *testing.T) {
+	auth, err := ParseIncludeAuth("oci://registry.corp.local=ecr-login, oci://registry.corp.local/team=pass, https://github.com/acme/=store")
+	assert.NilError(t, err)
+	assert.Equal(t, auth.Helper("oci://registry.corp.local/app:1.0"), "ecr-login")
+	assert.Equal(t, auth.Helper("oci://registry.corp.local/team/app:1.0"), "pass")
+	assert.Equal(t, auth.Helper("https://github.com/acme/fragments.git#main"), "store")
+	assert.Equal(t, auth.Helper("oci://docker.io/acme/app"), "")
+
+	_, err = ParseIncludeAuth("oci://registry.corp.local")
+	assert.ErrorContains(t, err, "expected PREFIX=HELPER")
+}
+
+func TestRegistryAuth(t *testing.T) {
+	registries, err := ParseRegistryAuth("123456789012.dkr.ecr.us-east-1.amazonaws.com=helper:ecr-login, ghcr.io=token:s3cr3t:x, docker.io=helper:pass")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, registries, RegistryAuth{
+		"123456789012.dkr.ecr.us-east-1.amazonaws.com": {Helper: "ecr-login"},
+		"ghcr.io":   {Token: "s3cr3t:x"},
+		"docker.io": {Helper: "pass"},
+	})
+	c, ok := registries.lookup("registry-1.docker.io")
+	assert.Check(t, ok)
+	assert.Equal(t, c.Helper, "pass")
+	_, ok = registries.lookup("quay.io")
+	assert.Check(t, !ok)
+
+	auth := registryHelperAuth{registries: registries}
+	ac, err := auth.GetAuthConfig("quay.io")
+	assert.NilError(t, err)
+	assert.Equal(t, ac.Username, "")
+
+	for _, value := range []string{"ghcr.io", "ghcr.io=token:", "ghcr.io=password:s3cr3t", "=token:s3cr3t"} {
+		_, err = ParseRegistryAuth(value)
+		assert.Check(t, err != nil, value)
+		if err != nil {
+			assert.Check(t, !strings.Contains(err.Error(), "s3cr3t"), "error reports the token: %v", err)
+		}
+	}
+}
+
+func TestGitCredentialHelperEnv(t *testing.T) {
+	env := map[string]string{
+		"GIT_CONFIG_COUNT":   "1",
+		"GIT_CONFIG_KEY_0":   "core.sshCommand",
+		"GIT_CONFIG_VALUE_0": "ssh",
+	}
+	gitCredentialHelperEnv(env, "store")
+	assert.DeepEqual(t, env, map[string]string{
+		"GIT_CONFIG_COUNT":   "3",
+		"GIT_CONFIG_KEY_0":   "core.sshCommand",
+		"GIT_CONFIG_VALUE_0": "ssh",
+		"GIT_CONFIG_KEY_1":   "credential.helper",
+		"GIT_CONFIG_VALUE_1": "",
+		"GIT_CONFIG_KEY_2":   "credential.helper",
+		"GIT_CONFIG_VALUE_2": "store",
+	})
+}
@@ -0,0 +1,183 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/loader"
+	"github.com/docker/compose/v2/internal/features"
+	"github.com/opencontainers/go-digest"
+)
+
+// NewBucketRemoteLoader creates a loader for Compose files stored in s3:// and gcs:// object store buckets
+func NewBucketRemoteLoader(offline bool, inputs *Inputs, flags *features.Flags) loader.ResourceLoader {
+	client := &http.Client{Transport: http.DefaultTransport}
+	return bucketRemoteLoader{
+		offline: offline,
+		inputs:  inputs,
+		flags:   flags,
+		stores: map[string]objectStore{
+			"s3":  s3Store{client: client},
+			"gcs": gcsStore{client: client},
+		},
+		known: map[string]string{},
+	}
+}
+
+// objectStore downloads objects from the buckets of a cloud storage service
+type objectStore interface {
+	// fetch downloads key from bucket, unless etag is still the current ETag of the object
+	fetch(ctx context.Context, bucket, key, etag string) (object, error)
+}
+
+// object is the content of an object store entry
+type object struct {
+	content []byte
+	etag    string
+	// notModified is set when the object still has the ETag sent with the request, and content was not downloaded
+	notModified bool
+}
+
+type bucketRemoteLoader struct {
+	offline bool
+	inputs  *Inputs
+	flags   *features.Flags
+	stores  map[string]objectStore
+	known   map[string]string
+}
+
+func (g bucketRemoteLoader) Accept(path string) bool {
+	scheme, _, ok := strings.Cut(path, "://")
+	if !ok {
+		return false
+	}
+	_, ok = g.stores[scheme]
+	return ok
+}
+
+func (g bucketRemoteLoader) Load(ctx context.Context, path string) (string, error) {
+	enabled, err := g.flags.Enabled(features.BucketRemote)
+	if err != nil {
+		return "", err
+	}
+	if !enabled {
+		return "", fmt.Errorf("object store remote resource is disabled by feature %s", features.BucketRemote.Name)
+	}
+
+	if g.offline {
+		return "", nil
+	}
+
+	local, ok := g.known[path]
+	if !ok {
+		scheme, bucket, key, err := parseBucketRef(path)
+		if err != nil {
+			return "", err
+		}
+		cache, err := cacheDir()
+		if err != nil {
+			return "", fmt.Errorf("initializing remote resource cache: %w", err)
+		}
+
+		var etag string
+		if locked := g.inputs.lockedVersion(path); locked != "" {
+			expected, err := digest.Parse(locked)
+			if err != nil {
+				return "", fmt.Errorf("invalid digest locked for %s: %w", path, err)
+			}
+			local = filepath.Join(cache, expected.Encoded())
+		} else if local, err = lookupCache(CacheBucket, path); err != nil {
+			return "", err
+		}
+		// a cached copy is only downloaded again once the object has been modified
+		if local != "" {
+			if _, err := os.Stat(filepath.Join(local, "compose.yaml")); err == nil {
+				if b, err := os.ReadFile(filepath.Join(local, ".etag")); err == nil {
+					etag = string(b)
+				}
+			}
+		}
+
+		obj, err := g.stores[scheme].fetch(ctx, bucket, key, etag)
+		if err != nil {
+			return "", err
+		}
+		if !obj.notModified {
+			resolved := digest.SHA256.FromBytes(obj.content)
+			if locked := g.inputs.lockedVersion(path); locked != "" && resolved.String() != locked {
+				return "", fmt.Errorf("checksum mismatch for %s: expected %s, got %s", path, locked, resolved)
+			}
+			local = filepath.Join(cache, resolved.Encoded())
+			if err := os.MkdirAll(local, 0o700); err != nil {
+				return "", err
+			}
+			if err := os.WriteFile(filepath.Join(local, "compose.yaml"), obj.content, 0o600); err != nil {
+				return "", err
+			}
+			if obj.etag != "" {
+				if err := os.WriteFile(filepath.Join(local, ".etag"), []byte(obj.etag), 0o600); err != nil {
+					return "", err
+				}
+			}
+		}
+		g.known[path] = local
+		recordCacheSource(local, CacheBucket, path)
+		g.inputs.record(path, digest.NewDigestFromEncoded(digest.SHA256, filepath.Base(local)).String())
+	}
+	return filepath.Join(local, "compose.yaml"), nil
+}
+
+func (g bucketRemoteLoader) Dir(path string) string {
+	return g.known[path]
+}
+
+// parseBucketRef splits a <scheme>://<bucket>/<key> reference
+func parseBucketRef(path string) (string, string, string, error) {
+	scheme, rest, _ := strings.Cut(path, "://")
+	bucket, key, _ := strings.Cut(rest, "/")
+	if bucket == "" || key == "" || strings.HasSuffix(key, "/") {
+		return "", "", "", fmt.Errorf("invalid object reference %s, expected %s://<bucket>/<key>", path, scheme)
+	}
+	return scheme, bucket, key, nil
+}
+
+var _ loader.ResourceLoader = bucketRemoteLoader{}
+
+// fetchObject sends a request for an object store entry, conditional on etag when set
+func fetchObject(client *http.Client, req *http.Request, ref string) (object, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return object{}, err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	switch resp.StatusCode {
+	case http.StatusOK:
+		content, err := io.ReadAll(resp.Body)
+		return object{content: content, etag: resp.Header.Get("ETag")}, err
+	case http.StatusNotModified:
+		return object{notModified: true}, nil
+	default:
+		return object{}, fmt.Errorf("failed to download %s: %s", ref, resp.Status)
+	}
+}
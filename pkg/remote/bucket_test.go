@@ -0,0 +1,142 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/docker/compose/v2/internal/features"
+	"github.com/opencontainers/go-digest"
+	"gotest.tools/v3/assert"
+)
+
+func TestBucketRemoteLoaderS3(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials"))
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_REGION", "eu-west-3")
+
+	content := "services:\n  web:\n    image: nginx\n"
+	downloads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/platform/app/compose.yaml" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") ||
+			!strings.Contains(r.Header.Get("Authorization"), "/eu-west-3/s3/aws4_request") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads++
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(content))
+	}))
+	defer server.Close()
+	t.Setenv("AWS_ENDPOINT_URL_S3", server.URL)
+
+	inputs := NewInputs()
+	l := NewBucketRemoteLoader(false, inputs, features.NewFlags(nil))
+	assert.Assert(t, l.Accept("s3://platform/app/compose.yaml"))
+	assert.Assert(t, l.Accept("gcs://platform/app/compose.yaml"))
+	assert.Assert(t, !l.Accept("https://example.com/compose.yaml"))
+
+	local, err := l.Load(context.TODO(), "s3://platform/app/compose.yaml")
+	assert.NilError(t, err)
+	b, err := os.ReadFile(local)
+	assert.NilError(t, err)
+	assert.Equal(t, string(b), content)
+	assert.Equal(t, inputs.Resolved()["s3://platform/app/compose.yaml"], digest.SHA256.FromString(content).String())
+
+	// an unmodified object is served from the cache
+	cached, err := NewBucketRemoteLoader(false, nil, features.NewFlags(nil)).Load(context.TODO(), "s3://platform/app/compose.yaml")
+	assert.NilError(t, err)
+	assert.Equal(t, cached, local)
+	assert.Equal(t, downloads, 1)
+
+	_, err = l.Load(context.TODO(), "s3://platform/missing.yaml")
+	assert.ErrorContains(t, err, "failed to download s3://platform/missing.yaml: 404 Not Found")
+}
+
+func TestBucketRemoteLoaderGCS(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	content := "services:\n  web:\n    image: nginx\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/storage/v1/b/platform/o/app%2Fcompose.yaml" || r.URL.Query().Get("alt") != "media" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(content))
+	}))
+	defer server.Close()
+	t.Setenv("STORAGE_EMULATOR_HOST", strings.TrimPrefix(server.URL, "http://"))
+
+	local, err := NewBucketRemoteLoader(false, nil, features.NewFlags(nil)).Load(context.TODO(), "gcs://platform/app/compose.yaml")
+	assert.NilError(t, err)
+	b, err := os.ReadFile(local)
+	assert.NilError(t, err)
+	assert.Equal(t, string(b), content)
+
+	_, err = NewBucketRemoteLoader(false, nil, features.NewFlags(nil)).Load(context.TODO(), "gcs://platform")
+	assert.ErrorContains(t, err, "invalid object reference gcs://platform, expected gcs://<bucket>/<key>")
+}
+
+func TestGCSStoreCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			assert.NilError(t, r.ParseForm())
+			assert.Equal(t, r.PostForm.Get("refresh_token"), "refresh")
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"access_token":"access","token_type":"Bearer","expires_in":3600}`))
+		case "/storage/v1/b/platform/o/compose.yaml":
+			if r.Header.Get("Authorization") != "Bearer access" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("ETag", "CJ+yzt")
+			_, _ = w.Write([]byte("services: {}\n"))
+		}
+	}))
+	defer server.Close()
+
+	file := filepath.Join(t.TempDir(), "credentials.json")
+	err := os.WriteFile(file, []byte(`{"type":"authorized_user","client_id":"id","client_secret":"secret","refresh_token":"refresh","token_uri":"`+server.URL+`/token"}`), 0o600)
+	assert.NilError(t, err)
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", file)
+
+	obj, err := gcsStore{client: server.Client(), endpoint: server.URL}.fetch(context.TODO(), "platform", "compose.yaml", "")
+	assert.NilError(t, err)
+	assert.Equal(t, string(obj.content), "services: {}\n")
+	assert.Equal(t, obj.etag, "CJ+yzt")
+
+	assert.NilError(t, os.WriteFile(file, []byte(`{"type":"unknown"}`), 0o600))
+	_, err = gcsStore{client: server.Client(), endpoint: server.URL}.fetch(context.TODO(), "platform", "compose.yaml", "")
+	assert.ErrorContains(t, err, `unknown credential type: "unknown"`)
+}
@@ -17,8 +17,15 @@
 package remote
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 )
 
 func cacheDir() (string, error) {
@@ -34,3 +41,272 @@ func cacheDir() (string, error) {
 	err = os.MkdirAll(path, 0o700)
 	return path, err
 }
+
+// Kinds of remote resources stored in the cache
+const (
+	CacheGit    = "git"
+	CacheOCI    = "oci"
+	CacheHTTP   = "http"
+	CacheBucket = "bucket"
+)
+
+// CacheEntry is a remote resource stored in the local cache
+type CacheEntry struct {
+	// Name of the entry in the cache, the commit of git resources or the digest of other resources
+	Name string `json:"name"`
+	// Type is the kind of remote resource
+	Type string `json:"type,omitempty"`
+	// Source is the reference the resource was last loaded from. It is unknown for entries cached by older versions
+	Source string `json:"source,omitempty"`
+	// Size is the disk usage of the entry, in bytes
+	Size int64 `json:"size"`
+	// Created is when the resource was downloaded
+	Created time.Time `json:"created"`
+	// LastUsed is when the resource was last loaded from the cache, or when it was downloaded if never loaded since
+	LastUsed time.Time `json:"lastUsed"`
+}
+
+// cacheSource is the file recording where a cache entry comes from, stored alongside the entry
+type cacheSource struct {
+	Type   string `json:"type"`
+	Source string `json:"source"`
+}
+
+// recordCacheSource records the reference a cache entry was loaded from, so it can be listed. Failing to do so only
+// affects listing, so errors are ignored
+func recordCacheSource(local, kind, source string) {
+	b, err := json.Marshal(cacheSource{Type: kind, Source: source})
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(local+".json", b, 0o600)
+	touchCache(local)
+}
+
+// touchCache records the cache entry in local was just used, so the least recently used entries are pruned first.
+// The time is recorded by a separate file, as the one recording the source tells when the resource was resolved.
+// Failing to do so only affects pruning order, so errors are ignored
+func touchCache(local string) {
+	now := time.Now()
+	if err := os.Chtimes(local+".used", now, now); errors.Is(err, fs.ErrNotExist) {
+		_ = os.WriteFile(local+".used", nil, 0o600)
+	}
+}
+
+// ListCache lists remote resources stored in the local cache, most recent first
+func ListCache() ([]CacheEntry, error) {
+	cache, err := cacheDir()
+	if err != nil {
+		return nil, err
+	}
+	files, err := os.ReadDir(cache)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []CacheEntry
+	for _, f := range files {
+		// inputs holds records of remote inputs per project, not a cached resource
+		if !f.IsDir() || f.Name() == "inputs" {
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			return nil, err
+		}
+		entry := CacheEntry{
+			Name:     f.Name(),
+			Created:  info.ModTime(),
+			LastUsed: info.ModTime(),
+		}
+		if used, err := os.Stat(filepath.Join(cache, f.Name()+".used")); err == nil {
+			entry.LastUsed = used.ModTime()
+		}
+		if b, err := os.ReadFile(filepath.Join(cache, f.Name()+".json")); err == nil {
+			var source cacheSource
+			if json.Unmarshal(b, &source) == nil {
+				entry.Type, entry.Source = source.Type, source.Source
+			}
+		}
+		entry.Size, err = diskUsage(filepath.Join(cache, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Created.After(entries[j].Created)
+	})
+	return entries, nil
+}
+
+// lookupCache returns the local copy of the resource of the given kind most recently loaded from source, or an empty
+// string if it was never cached
+func lookupCache(kind, source string) (string, error) {
+	local, _, err := lookupCacheEntry(kind, source)
+	return local, err
+}
+
+// lookupCacheEntry returns the local copy of the resource of the given kind most recently loaded from source, and
+// when it was loaded
+func lookupCacheEntry(kind, source string) (string, time.Time, error) {
+	cache, err := cacheDir()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	files, err := os.ReadDir(cache)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", time.Time{}, nil
+	}
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	var (
+		local  string
+		latest time.Time
+	)
+	for _, f := range files {
+		name, ok := strings.CutSuffix(f.Name(), ".json")
+		if !ok || f.IsDir() {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(cache, f.Name()))
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		var recorded cacheSource
+		if json.Unmarshal(b, &recorded) != nil || recorded.Type != kind || recorded.Source != source {
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		if _, err := os.Stat(filepath.Join(cache, name)); err != nil {
+			continue
+		}
+		if local == "" || info.ModTime().After(latest) {
+			local, latest = filepath.Join(cache, name), info.ModTime()
+		}
+	}
+	return local, latest, nil
+}
+
+// minCachePrefix is the shortest name prefix designating a cache entry
+const minCachePrefix = 4
+
+// Match tells if ref designates the entry, by source reference or by a prefix of its name of at least
+// minCachePrefix characters
+func (e CacheEntry) Match(ref string) bool {
+	if ref == "" {
+		return false
+	}
+	if e.matchSource(ref) {
+		return true
+	}
+	prefix := strings.TrimPrefix(ref, "sha256:")
+	return len(prefix) >= minCachePrefix && strings.HasPrefix(e.Name, prefix)
+}
+
+func (e CacheEntry) matchSource(ref string) bool {
+	source, _, _ := strings.Cut(e.Source, "#")
+	return e.Source == ref || source == ref
+}
+
+// FindCache returns the entries ref designates. A source reference designates all the entries cached for it, while
+// a name prefix must be at least minCachePrefix characters long and designate a single entry
+func FindCache(entries []CacheEntry, ref string) ([]CacheEntry, error) {
+	var found []CacheEntry
+	for _, entry := range entries {
+		if entry.matchSource(ref) {
+			found = append(found, entry)
+		}
+	}
+	if len(found) > 0 {
+		return found, nil
+	}
+	if prefix := strings.TrimPrefix(ref, "sha256:"); len(prefix) < minCachePrefix {
+		return nil, fmt.Errorf("%q is neither a cached source nor a name prefix of at least %d characters", ref, minCachePrefix)
+	}
+	for _, entry := range entries {
+		if entry.Match(ref) {
+			found = append(found, entry)
+		}
+	}
+	if len(found) > 1 {
+		return nil, fmt.Errorf("%q is ambiguous, it matches %d cached resources", ref, len(found))
+	}
+	return found, nil
+}
+
+// RemoveCache removes an entry from the local cache
+func RemoveCache(entry CacheEntry) error {
+	cache, err := cacheDir()
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(filepath.Join(cache, entry.Name)); err != nil {
+		return err
+	}
+	for _, sidecar := range []string{".json", ".used"} {
+		if err := os.Remove(filepath.Join(cache, entry.Name+sidecar)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// PruneCache removes the least recently used entries from the cache until it takes up to maxSize bytes, and returns
+// them. Entries inputs resolved to are kept, as they are in use by the project being loaded
+func PruneCache(maxSize int64, inputs map[string]string) ([]CacheEntry, error) {
+	entries, err := ListCache()
+	if err != nil {
+		return nil, err
+	}
+	inUse := map[string]bool{}
+	for _, version := range inputs {
+		inUse[strings.TrimPrefix(version, "sha256:")] = true
+	}
+	var size int64
+	for _, entry := range entries {
+		size += entry.Size
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].LastUsed.Before(entries[j].LastUsed)
+	})
+	var pruned []CacheEntry
+	for _, entry := range entries {
+		if size <= maxSize {
+			break
+		}
+		if inUse[entry.Name] {
+			continue
+		}
+		if err := RemoveCache(entry); err != nil {
+			return pruned, err
+		}
+		size -= entry.Size
+		pruned = append(pruned, entry)
+	}
+	return pruned, nil
+}
+
+func diskUsage(path string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
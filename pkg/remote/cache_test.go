@@ -0,0 +1,112 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package remote
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	entries, err := ListCache()
+	assert.NilError(t, err)
+	assert.Equal(t, len(entries), 0)
+
+	cache, err := cacheDir()
+	assert.NilError(t, err)
+	local := filepath.Join(cache, "0123456789abcdef")
+	assert.NilError(t, os.MkdirAll(local, 0o700))
+	assert.NilError(t, os.WriteFile(filepath.Join(local, "compose.yaml"), []byte("services: {}\n"), 0o600))
+	recordCacheSource(local, CacheOCI, "oci://example.com/app:1.0")
+	assert.NilError(t, os.MkdirAll(filepath.Join(cache, "fedcba9876543210"), 0o700))
+	assert.NilError(t, RecordInputs("test", map[string]string{"oci://example.com/app:1.0": "sha256:0123456789abcdef"}))
+
+	entries, err = ListCache()
+	assert.NilError(t, err)
+	assert.Equal(t, len(entries), 2)
+	var entry CacheEntry
+	for _, e := range entries {
+		if e.Name == "0123456789abcdef" {
+			entry = e
+		}
+	}
+	assert.Equal(t, entry.Type, CacheOCI)
+	assert.Equal(t, entry.Source, "oci://example.com/app:1.0")
+	assert.Equal(t, entry.Size, int64(len("services: {}\n")))
+
+	assert.Check(t, entry.Match("oci://example.com/app:1.0"))
+	assert.Check(t, entry.Match("sha256:0123"))
+	assert.Check(t, !entry.Match("oci://example.com/app:2.0"))
+	assert.Check(t, !entry.Match(""))
+	assert.Check(t, !entry.Match("sha256:"))
+	assert.Check(t, !entry.Match("012"))
+
+	found, err := FindCache(entries, "oci://example.com/app:1.0")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, found, []CacheEntry{entry})
+	found, err = FindCache(entries, "sha256:fedc")
+	assert.NilError(t, err)
+	assert.Equal(t, len(found), 1)
+	_, err = FindCache(entries, "sha256:")
+	assert.ErrorContains(t, err, "name prefix of at least 4 characters")
+	entries = append(entries, CacheEntry{Name: "0123fedcba"})
+	_, err = FindCache(entries, "0123")
+	assert.ErrorContains(t, err, `"0123" is ambiguous, it matches 2 cached resources`)
+
+	assert.NilError(t, RemoveCache(entry))
+	entries, err = ListCache()
+	assert.NilError(t, err)
+	assert.Equal(t, len(entries), 1)
+	assert.Equal(t, entries[0].Name, "fedcba9876543210")
+	_, err = os.Stat(local + ".json")
+	assert.Check(t, os.IsNotExist(err))
+}
+
+func TestPruneCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	cache, err := cacheDir()
+	assert.NilError(t, err)
+	now := time.Now()
+	for i, name := range []string{"aaaa", "bbbb", "cccc", "dddd"} {
+		local := filepath.Join(cache, name)
+		assert.NilError(t, os.MkdirAll(local, 0o700))
+		assert.NilError(t, os.WriteFile(filepath.Join(local, "compose.yaml"), make([]byte, 100), 0o600))
+		recordCacheSource(local, CacheOCI, "oci://example.com/"+name)
+		used := now.Add(time.Duration(i) * time.Hour)
+		assert.NilError(t, os.Chtimes(local+".used", used, used))
+	}
+
+	// aaaa is the least recently used entry, but is in use by the project
+	pruned, err := PruneCache(200, map[string]string{"oci://example.com/aaaa": "sha256:aaaa"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{pruned[0].Name, pruned[1].Name}, []string{"bbbb", "cccc"})
+	_, err = os.Stat(filepath.Join(cache, "bbbb.used"))
+	assert.Check(t, os.IsNotExist(err))
+
+	entries, err := ListCache()
+	assert.NilError(t, err)
+	assert.Equal(t, len(entries), 2)
+
+	pruned, err = PruneCache(200, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, len(pruned), 0)
+}
@@ -0,0 +1,94 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	gcsEndpoint      = "https://storage.googleapis.com"
+	gcsReadOnlyScope = "https://www.googleapis.com/auth/devstorage.read_only"
+)
+
+// gcsStore downloads objects from Google Cloud Storage, or the emulator set by STORAGE_EMULATOR_HOST. Credentials are
+// resolved as Application Default Credentials: the file set by GOOGLE_APPLICATION_CREDENTIALS, the one written by
+// `gcloud auth application-default login`, then the metadata server of the instance. Credentials files can declare
+// service accounts, users, workload identity federation or impersonated service accounts
+type gcsStore struct {
+	client *http.Client
+	// endpoint overrides the Cloud Storage API endpoint
+	endpoint string
+}
+
+func (s gcsStore) fetch(ctx context.Context, bucket, key, etag string) (object, error) {
+	ref := "gcs://" + bucket + "/" + key
+	endpoint, authenticate := s.endpoint, true
+	if endpoint == "" {
+		endpoint = gcsEndpoint
+		// like Google Cloud client libraries, the emulator is used without credentials
+		if emulator := os.Getenv("STORAGE_EMULATOR_HOST"); emulator != "" {
+			endpoint, authenticate = "http://"+emulator, false
+		}
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return object{}, fmt.Errorf("invalid Cloud Storage endpoint %q: %w", endpoint, err)
+	}
+	u = u.JoinPath("storage", "v1", "b", bucket, "o")
+	// the object name is a single path segment, with slashes escaped
+	u.RawPath = u.EscapedPath() + "/" + url.PathEscape(key)
+	u.Path += "/" + key
+	u.RawQuery = "alt=media"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return object{}, err
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if authenticate {
+		tokens, err := googleTokenSource(ctx, s.client)
+		if err != nil {
+			return object{}, fmt.Errorf("resolving Google Cloud credentials for %s: %w", ref, err)
+		}
+		token, err := tokens.Token()
+		if err != nil {
+			return object{}, fmt.Errorf("retrieving Google Cloud access token for %s: %w", ref, err)
+		}
+		token.SetAuthHeader(req)
+	}
+	return fetchObject(s.client, req, ref)
+}
+
+// googleTokenSource returns a source of access tokens for Application Default Credentials
+func googleTokenSource(ctx context.Context, client *http.Client) (oauth2.TokenSource, error) {
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, client)
+	creds, err := google.FindDefaultCredentials(ctx, gcsReadOnlyScope)
+	if err != nil {
+		return nil, err
+	}
+	return creds.TokenSource, nil
+}
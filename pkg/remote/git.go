@@ -25,34 +25,30 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
-	"strconv"
+	"strings"
 
 	"github.com/compose-spec/compose-go/v2/cli"
 	"github.com/compose-spec/compose-go/v2/loader"
 	"github.com/compose-spec/compose-go/v2/types"
 	"github.com/docker/cli/cli/command"
+	"github.com/docker/compose/v2/internal/features"
 	"github.com/docker/compose/v2/pkg/api"
 	"github.com/moby/buildkit/util/gitutil"
 	"github.com/sirupsen/logrus"
 )
 
+// GIT_REMOTE_ENABLED is the environment variable which disables git remote resources
+//
+// Deprecated: use COMPOSE_FEATURES or features.GitRemote
 const GIT_REMOTE_ENABLED = "COMPOSE_EXPERIMENTAL_GIT_REMOTE"
 
-func gitRemoteLoaderEnabled() (bool, error) {
-	if v := os.Getenv(GIT_REMOTE_ENABLED); v != "" {
-		enabled, err := strconv.ParseBool(v)
-		if err != nil {
-			return false, fmt.Errorf("COMPOSE_EXPERIMENTAL_GIT_REMOTE environment variable expects boolean value: %w", err)
-		}
-		return enabled, err
-	}
-	return true, nil
-}
-
-func NewGitRemoteLoader(dockerCli command.Cli, offline bool) loader.ResourceLoader {
+func NewGitRemoteLoader(dockerCli command.Cli, offline bool, auth IncludeAuth, inputs *Inputs, flags *features.Flags) loader.ResourceLoader {
 	return gitRemoteLoader{
 		dockerCli: dockerCli,
 		offline:   offline,
+		auth:      auth,
+		inputs:    inputs,
+		flags:     flags,
 		known:     map[string]string{},
 	}
 }
@@ -60,32 +56,51 @@ func NewGitRemoteLoader(dockerCli command.Cli, offline bool) loader.ResourceLoad
 type gitRemoteLoader struct {
 	dockerCli command.Cli
 	offline   bool
+	auth      IncludeAuth
+	inputs    *Inputs
+	flags     *features.Flags
 	known     map[string]string
 }
 
 func (g gitRemoteLoader) Accept(path string) bool {
-	_, err := gitutil.ParseGitRef(path)
+	_, err := parseGitRef(path)
 	return err == nil
 }
 
+// parseGitRef parses a git remote reference. It supports the github.com/ORG/REPO#REF:SUBDIR shorthand, which buildkit
+// accepts but doesn't split into commit and subdirectory
+func parseGitRef(path string) (*gitutil.GitRef, error) {
+	if rest, ok := strings.CutPrefix(path, "github.com/"); ok {
+		repo, fragment, hasFragment := strings.Cut(rest, "#")
+		path = "https://github.com/" + strings.TrimSuffix(repo, ".git") + ".git"
+		if hasFragment {
+			path += "#" + fragment
+		}
+	}
+	return gitutil.ParseGitRef(path)
+}
+
 var commitSHA = regexp.MustCompile(`^[a-f0-9]{40}$`)
 
 func (g gitRemoteLoader) Load(ctx context.Context, path string) (string, error) {
-	enabled, err := gitRemoteLoaderEnabled()
+	enabled, err := g.flags.Enabled(features.GitRemote)
 	if err != nil {
 		return "", err
 	}
 	if !enabled {
-		return "", fmt.Errorf("git remote resource is disabled by %q", GIT_REMOTE_ENABLED)
+		return "", fmt.Errorf("git remote resource is disabled by feature %s", features.GitRemote.Name)
 	}
 
-	ref, err := gitutil.ParseGitRef(path)
+	ref, err := parseGitRef(path)
 	if err != nil {
 		return "", err
 	}
 
 	local, ok := g.known[path]
 	if !ok {
+		if locked := g.inputs.lockedVersion(path); locked != "" {
+			ref.Commit = locked
+		}
 		if ref.Commit == "" {
 			ref.Commit = "HEAD" // default branch
 		}
@@ -105,12 +120,14 @@ func (g gitRemoteLoader) Load(ctx context.Context, path string) (string, error)
 			if g.offline {
 				return "", nil
 			}
-			err = g.checkout(ctx, local, ref)
+			err = g.checkout(ctx, local, ref, g.auth.Helper(path))
 			if err != nil {
 				return "", err
 			}
 		}
 		g.known[path] = local
+		recordCacheSource(local, CacheGit, path)
+		g.inputs.record(path, ref.Commit)
 	}
 	if ref.SubDir != "" {
 		local = filepath.Join(local, ref.SubDir)
@@ -132,7 +149,7 @@ func (g gitRemoteLoader) Dir(path string) string {
 func (g gitRemoteLoader) resolveGitRef(ctx context.Context, path string, ref *gitutil.GitRef) error {
 	if !commitSHA.MatchString(ref.Commit) {
 		cmd := exec.CommandContext(ctx, "git", "ls-remote", "--exit-code", ref.Remote, ref.Commit)
-		cmd.Env = g.gitCommandEnv()
+		cmd.Env = g.gitCommandEnv(g.auth.Helper(path))
 		out, err := cmd.Output()
 		if err != nil {
 			if cmd.ProcessState.ExitCode() == 2 {
@@ -152,7 +169,7 @@ func (g gitRemoteLoader) resolveGitRef(ctx context.Context, path string, ref *gi
 	return nil
 }
 
-func (g gitRemoteLoader) checkout(ctx context.Context, path string, ref *gitutil.GitRef) error {
+func (g gitRemoteLoader) checkout(ctx context.Context, path string, ref *gitutil.GitRef, credentialHelper string) error {
 	err := os.MkdirAll(path, 0o700)
 	if err != nil {
 		return err
@@ -170,7 +187,7 @@ func (g gitRemoteLoader) checkout(ctx context.Context, path string, ref *gitutil
 	}
 
 	cmd = exec.CommandContext(ctx, "git", "fetch", "--depth=1", "origin", ref.Commit)
-	cmd.Env = g.gitCommandEnv()
+	cmd.Env = g.gitCommandEnv(credentialHelper)
 	cmd.Dir = path
 
 	err = g.run(cmd)
@@ -200,7 +217,7 @@ func (g gitRemoteLoader) run(cmd *exec.Cmd) error {
 	return cmd.Run()
 }
 
-func (g gitRemoteLoader) gitCommandEnv() []string {
+func (g gitRemoteLoader) gitCommandEnv(credentialHelper string) []string {
 	env := types.NewMapping(os.Environ())
 	if env["GIT_TERMINAL_PROMPT"] == "" {
 		// Disable prompting for passwords by Git until user explicitly asks for it.
@@ -210,6 +227,9 @@ func (g gitRemoteLoader) gitCommandEnv() []string {
 		// Disable any ssh connection pooling by Git and do not attempt to prompt the user.
 		env["GIT_SSH_COMMAND"] = "ssh -o ControlMaster=no -o BatchMode=yes"
 	}
+	if credentialHelper != "" {
+		gitCredentialHelperEnv(env, credentialHelper)
+	}
 	v := env.Values()
 	return v
 }
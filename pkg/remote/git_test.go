@@ -0,0 +1,49 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package remote
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseGitRef(t *testing.T) {
+	tests := []struct {
+		path   string
+		remote string
+		commit string
+		subDir string
+	}{
+		{path: "git://github.com/acme/app.git#main:deploy", remote: "git://github.com/acme/app.git", commit: "main", subDir: "deploy"},
+		{path: "https://github.com/acme/app.git#v1.2", remote: "https://github.com/acme/app.git", commit: "v1.2"},
+		{path: "github.com/acme/app#v1.2:deploy/prod", remote: "https://github.com/acme/app.git", commit: "v1.2", subDir: "deploy/prod"},
+		{path: "github.com/acme/app.git", remote: "https://github.com/acme/app.git"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			ref, err := parseGitRef(tt.path)
+			assert.NilError(t, err)
+			assert.Equal(t, ref.Remote, tt.remote)
+			assert.Equal(t, ref.Commit, tt.commit)
+			assert.Equal(t, ref.SubDir, tt.subDir)
+		})
+	}
+
+	_, err := parseGitRef("./compose.yaml")
+	assert.Assert(t, err != nil)
+}
@@ -0,0 +1,434 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/loader"
+	"github.com/docker/compose/v2/internal/features"
+	"github.com/opencontainers/go-digest"
+	"gopkg.in/yaml.v3"
+)
+
+// NewHTTPRemoteLoader creates a loader for Compose files served over http(s). checksum is the expected sha256 of
+// files, the project files set by the user, when they don't set one with a `#sha256=` fragment. It doesn't apply to
+// the resources they include. Files they reference by a relative path are cached with them, and recorded as inputs
+func NewHTTPRemoteLoader(offline bool, checksum string, files []string, inputs *Inputs, flags *features.Flags) loader.ResourceLoader {
+	return httpRemoteLoader{
+		offline:  offline,
+		checksum: checksum,
+		files:    files,
+		inputs:   inputs,
+		flags:    flags,
+		client:   &http.Client{Transport: http.DefaultTransport},
+		known:    map[string]string{},
+	}
+}
+
+type httpRemoteLoader struct {
+	offline  bool
+	checksum string
+	files    []string
+	inputs   *Inputs
+	flags    *features.Flags
+	client   *http.Client
+	known    map[string]string
+}
+
+func (g httpRemoteLoader) Accept(path string) bool {
+	return strings.HasPrefix(path, "https://") || strings.HasPrefix(path, "http://")
+}
+
+func (g httpRemoteLoader) Load(ctx context.Context, path string) (string, error) {
+	enabled, err := g.flags.Enabled(features.HTTPRemote)
+	if err != nil {
+		return "", err
+	}
+	if !enabled {
+		return "", fmt.Errorf("http remote resource is disabled by feature %s", features.HTTPRemote.Name)
+	}
+
+	if g.offline {
+		return "", nil
+	}
+
+	local, ok := g.known[path]
+	if !ok {
+		checksum := ""
+		if slices.Contains(g.files, path) {
+			checksum = g.checksum
+		}
+		url, expected, err := parseHTTPRef(path, checksum)
+		if err != nil {
+			return "", err
+		}
+		if locked := g.inputs.lockedVersion(path); expected == "" && locked != "" {
+			if expected, err = digest.Parse(locked); err != nil {
+				return "", fmt.Errorf("invalid digest locked for %s: %w", path, err)
+			}
+		}
+
+		cache, err := cacheDir()
+		if err != nil {
+			return "", fmt.Errorf("initializing remote resource cache: %w", err)
+		}
+
+		// content is cached by digest, so a pinned resource doesn't need to be downloaded again. A cache entry is
+		// complete once written, and never written again
+		var index map[string]digest.Digest
+		if expected != "" {
+			local = filepath.Join(cache, expected.Encoded())
+			index, err = readHTTPIndex(local)
+			if err != nil {
+				return "", err
+			}
+		}
+		if index == nil || !g.matchLocked(url, index) {
+			local, index, err = g.fetch(ctx, cache, url, expected)
+			if err != nil {
+				return "", err
+			}
+		}
+		if err := g.checkRelatives(url, local, index); err != nil {
+			return "", err
+		}
+		g.known[path] = local
+		recordCacheSource(local, CacheHTTP, path)
+		g.inputs.record(path, index[composeYAML].String())
+		for rel, sum := range index {
+			if rel != composeYAML {
+				g.inputs.record(resolveRelative(url, rel), sum.String())
+			}
+		}
+	}
+	return filepath.Join(local, composeYAML), nil
+}
+
+func (g httpRemoteLoader) Dir(path string) string {
+	return g.known[path]
+}
+
+const composeYAML = "compose.yaml"
+
+// httpIndexFile records the digests of the files of an http cache entry, indexed by their path relative to the entry
+const httpIndexFile = "compose-files.json"
+
+// readHTTPIndex returns the digests of the files of the cache entry in local, or nil if there's no complete entry
+func readHTTPIndex(local string) (map[string]digest.Digest, error) {
+	b, err := os.ReadFile(filepath.Join(local, httpIndexFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var index map[string]digest.Digest
+	if err := json.Unmarshal(b, &index); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", filepath.Join(local, httpIndexFile), err)
+	}
+	return index, nil
+}
+
+// fetch downloads the Compose file at url and the files it references into a new cache entry, and returns it with
+// the digests of its files. Files are downloaded to a staging directory, so an existing entry is never written to: when
+// one already exists for the Compose file with other content for referenced files, which changed since it was cached,
+// the new content is cached by the digest of its index
+func (g httpRemoteLoader) fetch(ctx context.Context, cache string, url string, expected digest.Digest) (string, map[string]digest.Digest, error) {
+	if err := os.MkdirAll(cache, 0o700); err != nil {
+		return "", nil, err
+	}
+	staging, err := os.MkdirTemp(cache, ".http-")
+	if err != nil {
+		return "", nil, err
+	}
+	defer os.RemoveAll(staging) //nolint:errcheck
+
+	content, err := g.download(ctx, url)
+	if err != nil {
+		return "", nil, err
+	}
+	resolved := digest.SHA256.FromBytes(content)
+	if expected != "" && resolved != expected {
+		return "", nil, fmt.Errorf("checksum mismatch for %s: expected %s, got %s", url, expected, resolved)
+	}
+	if err := os.WriteFile(filepath.Join(staging, composeYAML), content, 0o600); err != nil {
+		return "", nil, err
+	}
+	index := map[string]digest.Digest{composeYAML: resolved}
+	if err := g.fetchRelatives(ctx, url, staging, composeYAML, index); err != nil {
+		return "", nil, err
+	}
+	b, err := json.Marshal(index)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := os.WriteFile(filepath.Join(staging, httpIndexFile), b, 0o600); err != nil {
+		return "", nil, err
+	}
+
+	local := filepath.Join(cache, resolved.Encoded())
+	existing, err := readHTTPIndex(local)
+	if err != nil {
+		return "", nil, err
+	}
+	switch {
+	case existing == nil:
+		// entries cached by previous versions have no index, and are replaced
+		if err := os.RemoveAll(local); err != nil {
+			return "", nil, err
+		}
+	case maps.Equal(existing, index):
+		return local, index, nil
+	default:
+		local = filepath.Join(cache, digest.SHA256.FromBytes(b).Encoded())
+		if _, err := os.Stat(local); err == nil {
+			return local, index, nil
+		}
+	}
+	return local, index, os.Rename(staging, local)
+}
+
+// matchLocked tells if files referenced by the Compose file at url, as indexed by a cache entry, are in the versions
+// they are locked to, if any
+func (g httpRemoteLoader) matchLocked(url string, index map[string]digest.Digest) bool {
+	for rel, sum := range index {
+		if locked := g.inputs.lockedVersion(resolveRelative(url, rel)); rel != composeYAML && locked != "" && locked != sum.String() {
+			return false
+		}
+	}
+	return true
+}
+
+// checkRelatives verifies files of the cache entry in local match the digests recorded by index. url is the Compose
+// file the entry was downloaded from
+func (g httpRemoteLoader) checkRelatives(url string, local string, index map[string]digest.Digest) error {
+	for rel, expected := range index {
+		if !filepath.IsLocal(filepath.FromSlash(rel)) {
+			return fmt.Errorf("invalid %s: %s is outside of the cache entry", filepath.Join(local, httpIndexFile), rel)
+		}
+		content, err := os.ReadFile(filepath.Join(local, filepath.FromSlash(rel)))
+		if err != nil {
+			return err
+		}
+		if actual := digest.SHA256.FromBytes(content); actual != expected {
+			return fmt.Errorf("checksum mismatch for cached copy of %s: expected %s, got %s", resolveRelative(url, rel), expected, actual)
+		}
+	}
+	return nil
+}
+
+// resolveRelative returns the URL of file rel, relative to the Compose file at base
+func resolveRelative(base string, rel string) string {
+	if rel == composeYAML {
+		return base
+	}
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return rel
+	}
+	return baseURL.ResolveReference(&url.URL{Path: rel}).String()
+}
+
+// fetchRelatives downloads the files referenced by file with a relative path into dir, resolving them against the
+// URL of the project file, so the cache directory mirrors the remote one and relative paths resolve as they would
+// next to it. file is relative to dir, and referenced files are fetched recursively. The digests of fetched files are
+// recorded by index, and verified against the versions they are locked to, if any
+func (g httpRemoteLoader) fetchRelatives(ctx context.Context, base string, dir string, file string, index map[string]digest.Digest) error {
+	composeFiles, envFiles, err := relativeReferences(filepath.Join(dir, filepath.FromSlash(file)))
+	if err != nil {
+		return err
+	}
+	for _, ref := range append(composeFiles, envFiles...) {
+		rel := path.Join(path.Dir(file), ref)
+		if _, ok := index[rel]; ok {
+			continue
+		}
+		if !filepath.IsLocal(filepath.FromSlash(rel)) || rel == httpIndexFile {
+			return fmt.Errorf("%s references %s, which can't be resolved next to %s", file, ref, base)
+		}
+		source := resolveRelative(base, rel)
+		content, err := g.download(ctx, source)
+		if err != nil {
+			return err
+		}
+		index[rel] = digest.SHA256.FromBytes(content)
+		if locked := g.inputs.lockedVersion(source); locked != "" && locked != index[rel].String() {
+			return fmt.Errorf("checksum mismatch for %s: locked to %s, got %s", source, locked, index[rel])
+		}
+		target := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(target), 0o700); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, content, 0o600); err != nil {
+			return err
+		}
+		if !slices.Contains(composeFiles, ref) {
+			continue
+		}
+		if err := g.fetchRelatives(ctx, base, dir, rel, index); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// relativeReferences lists files a compose file references by a relative path: compose files it includes or services
+// extend, and env files. Paths relying on interpolation or set to remote resources are ignored
+func relativeReferences(file string) (composeFiles []string, envFiles []string, err error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close() //nolint:errcheck
+
+	add := func(refs *[]string, nodes ...yaml.Node) {
+		for _, node := range nodes {
+			var paths []string
+			switch node.Kind {
+			case yaml.ScalarNode:
+				paths = []string{node.Value}
+			case yaml.SequenceNode:
+				for _, item := range node.Content {
+					if item.Kind == yaml.ScalarNode {
+						paths = append(paths, item.Value)
+					}
+				}
+			}
+			for _, p := range paths {
+				if p == "" || strings.ContainsAny(p, "$#~") || strings.Contains(p, "://") || filepath.IsAbs(p) || path.IsAbs(p) {
+					continue
+				}
+				if !slices.Contains(*refs, p) {
+					*refs = append(*refs, p)
+				}
+			}
+		}
+	}
+	decoder := yaml.NewDecoder(f)
+	for {
+		var model struct {
+			Include  []yaml.Node `yaml:"include"`
+			Services map[string]struct {
+				Extends yaml.Node `yaml:"extends"`
+				EnvFile yaml.Node `yaml:"env_file"`
+			} `yaml:"services"`
+		}
+		err := decoder.Decode(&model)
+		if errors.Is(err, io.EOF) {
+			return composeFiles, envFiles, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, include := range model.Include {
+			if include.Kind != yaml.MappingNode {
+				add(&composeFiles, include)
+				continue
+			}
+			var entry struct {
+				Path    yaml.Node `yaml:"path"`
+				EnvFile yaml.Node `yaml:"env_file"`
+			}
+			if err := include.Decode(&entry); err != nil {
+				return nil, nil, err
+			}
+			add(&composeFiles, entry.Path)
+			add(&envFiles, entry.EnvFile)
+		}
+		for _, service := range model.Services {
+			var extends struct {
+				File yaml.Node `yaml:"file"`
+			}
+			if service.Extends.Kind == yaml.MappingNode {
+				if err := service.Extends.Decode(&extends); err != nil {
+					return nil, nil, err
+				}
+				add(&composeFiles, extends.File)
+			}
+			if service.EnvFile.Kind != yaml.SequenceNode {
+				add(&envFiles, service.EnvFile)
+				continue
+			}
+			for _, envFile := range service.EnvFile.Content {
+				if envFile.Kind != yaml.MappingNode {
+					add(&envFiles, *envFile)
+					continue
+				}
+				var entry struct {
+					Path yaml.Node `yaml:"path"`
+				}
+				if err := envFile.Decode(&entry); err != nil {
+					return nil, nil, err
+				}
+				add(&envFiles, entry.Path)
+			}
+		}
+	}
+}
+
+func (g httpRemoteLoader) download(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// parseHTTPRef splits path into the URL to download and the expected digest of its content, set by a `#sha256=`
+// fragment or falling back to checksum
+func parseHTTPRef(path string, checksum string) (string, digest.Digest, error) {
+	url, fragment, _ := strings.Cut(path, "#")
+	if fragment != "" {
+		sum, ok := strings.CutPrefix(fragment, "sha256=")
+		if !ok {
+			return "", "", fmt.Errorf("unsupported fragment in %s, expected #sha256=<checksum>", path)
+		}
+		checksum = sum
+	}
+	if checksum == "" {
+		return url, "", nil
+	}
+	expected := digest.NewDigestFromEncoded(digest.SHA256, strings.ToLower(checksum))
+	if err := expected.Validate(); err != nil {
+		return "", "", fmt.Errorf("invalid sha256 checksum %q: %w", checksum, err)
+	}
+	return url, expected, nil
+}
+
+var _ loader.ResourceLoader = httpRemoteLoader{}
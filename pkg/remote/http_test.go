@@ -0,0 +1,156 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/compose/v2/internal/features"
+	"github.com/opencontainers/go-digest"
+	"gotest.tools/v3/assert"
+)
+
+func TestHTTPRemoteLoader(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	content := "services:\n  web:\n    image: nginx\n"
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/compose.yaml" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(content))
+	}))
+	defer server.Close()
+	sum := digest.SHA256.FromString(content)
+
+	inputs := NewInputs()
+	l := NewHTTPRemoteLoader(false, "", nil, inputs, features.NewFlags(nil))
+	assert.Assert(t, l.Accept(server.URL+"/compose.yaml"))
+	assert.Assert(t, !l.Accept("oci://registry.corp.local/app"))
+
+	path := server.URL + "/compose.yaml#sha256=" + sum.Encoded()
+	local, err := l.Load(context.TODO(), path)
+	assert.NilError(t, err)
+	b, err := os.ReadFile(local)
+	assert.NilError(t, err)
+	assert.Equal(t, string(b), content)
+	assert.Equal(t, inputs.Resolved()[path], sum.String())
+
+	// pinned content is served from the cache
+	pinned := NewHTTPRemoteLoader(false, sum.Encoded(), []string{server.URL + "/compose.yaml"}, nil, features.NewFlags(nil))
+	_, err = pinned.Load(context.TODO(), server.URL+"/compose.yaml")
+	assert.NilError(t, err)
+	assert.Equal(t, requests, 1)
+
+	// the checksum only applies to project files, not to the resources they include
+	_, err = pinned.Load(context.TODO(), server.URL+"/missing.yaml")
+	assert.ErrorContains(t, err, "404 Not Found")
+
+	_, err = l.Load(context.TODO(), server.URL+"/compose.yaml#sha256="+digest.SHA256.FromString("tampered").Encoded())
+	assert.ErrorContains(t, err, "checksum mismatch")
+
+	_, err = l.Load(context.TODO(), server.URL+"/missing.yaml")
+	assert.ErrorContains(t, err, "404 Not Found")
+
+	_, err = l.Load(context.TODO(), server.URL+"/compose.yaml#md5=abc")
+	assert.ErrorContains(t, err, "expected #sha256=<checksum>")
+}
+
+func TestHTTPRemoteLoaderRelativePaths(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	files := map[string]string{
+		"/app/compose.yaml":    "include:\n  - path: db/compose.yaml\n    env_file: db.env\nservices:\n  web:\n    extends:\n      file: common.yaml\n      service: base\n    env_file:\n      - path: web.env\n        required: false\n      - ${CONFIG}/extra.env\n",
+		"/app/db/compose.yaml": "services:\n  db:\n    image: postgres\n    env_file: ../shared/db.env\n",
+		"/app/db.env":          "POSTGRES_DB=app\n",
+		"/app/shared/db.env":   "POSTGRES_USER=app\n",
+		"/app/common.yaml":     "services:\n  base:\n    image: nginx\n",
+		"/app/web.env":         "DEBUG=1\n",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, ok := files[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	l := NewHTTPRemoteLoader(false, "", nil, nil, features.NewFlags(nil))
+	local, err := l.Load(context.TODO(), server.URL+"/app/compose.yaml")
+	assert.NilError(t, err)
+	dir := filepath.Dir(local)
+	assert.Equal(t, l.Dir(server.URL+"/app/compose.yaml"), dir)
+	for _, file := range []string{"db/compose.yaml", "db.env", "shared/db.env", "common.yaml", "web.env"} {
+		b, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(file)))
+		assert.NilError(t, err, file)
+		assert.Equal(t, string(b), files["/app/"+file])
+	}
+
+	// a pinned Compose file is served from the cache with the files it references, as they were first downloaded
+	sum := digest.SHA256.FromString(files["/app/compose.yaml"])
+	requests := 0
+	files["/app/web.env"] = "DEBUG=0\n"
+	inputs := NewInputs()
+	pinned := NewHTTPRemoteLoader(false, "", nil, inputs, features.NewFlags(nil)).(httpRemoteLoader)
+	pinned.client = &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		requests++
+		return http.DefaultTransport.RoundTrip(r)
+	})}
+	local, err = pinned.Load(context.TODO(), server.URL+"/app/compose.yaml#sha256="+sum.Encoded())
+	assert.NilError(t, err)
+	assert.Equal(t, requests, 0)
+	b, err := os.ReadFile(filepath.Join(filepath.Dir(local), "web.env"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(b), "DEBUG=1\n")
+	assert.Equal(t, inputs.Resolved()[server.URL+"/app/shared/db.env"], digest.SHA256.FromString(files["/app/shared/db.env"]).String())
+
+	// files locked to another version are downloaded again, to a new entry
+	locked := NewInputs()
+	locked.Lock(map[string]string{server.URL + "/app/web.env": digest.SHA256.FromString("DEBUG=0\n").String()})
+	local, err = NewHTTPRemoteLoader(false, "", nil, locked, features.NewFlags(nil)).Load(context.TODO(), server.URL+"/app/compose.yaml#sha256="+sum.Encoded())
+	assert.NilError(t, err)
+	assert.Assert(t, filepath.Dir(local) != dir)
+	b, err = os.ReadFile(filepath.Join(filepath.Dir(local), "web.env"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(b), "DEBUG=0\n")
+	locked.Lock(map[string]string{server.URL + "/app/web.env": digest.SHA256.FromString("DEBUG=2\n").String()})
+	_, err = NewHTTPRemoteLoader(false, "", nil, locked, features.NewFlags(nil)).Load(context.TODO(), server.URL+"/app/compose.yaml#sha256="+sum.Encoded())
+	assert.ErrorContains(t, err, "checksum mismatch for "+server.URL+"/app/web.env")
+
+	// tampering with a cached file is detected
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "common.yaml"), []byte("services: {}\n"), 0o600))
+	_, err = NewHTTPRemoteLoader(false, "", nil, nil, features.NewFlags(nil)).Load(context.TODO(), server.URL+"/app/compose.yaml#sha256="+sum.Encoded())
+	assert.ErrorContains(t, err, "checksum mismatch for cached copy of "+server.URL+"/app/common.yaml")
+
+	files["/app/compose.yaml"] = "include:\n  - ../outside.yaml\n"
+	_, err = NewHTTPRemoteLoader(false, "", nil, nil, features.NewFlags(nil)).Load(context.TODO(), server.URL+"/app/compose.yaml")
+	assert.ErrorContains(t, err, "can't be resolved next to")
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
@@ -0,0 +1,176 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package remote
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Inputs records the version remote resources resolved to while loading a project: a commit for git resources, a
+// manifest digest for OCI artifacts, and a layer digest for env files they include
+type Inputs struct {
+	mutex     sync.Mutex
+	resolved  map[string]string
+	locked    map[string]string
+	artifacts map[string]ArtifactInfo
+}
+
+func NewInputs() *Inputs {
+	return &Inputs{resolved: map[string]string{}, artifacts: map[string]ArtifactInfo{}}
+}
+
+func (i *Inputs) record(path string, digest string) {
+	if i == nil {
+		return
+	}
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	i.resolved[path] = digest
+}
+
+func (i *Inputs) recordArtifact(info ArtifactInfo) {
+	if i == nil {
+		return
+	}
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	i.artifacts[info.Reference] = info
+}
+
+// Lock pins remote resources to the versions recorded by locked, indexed by path, so they are loaded in these versions
+// rather than resolved again
+func (i *Inputs) Lock(locked map[string]string) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	i.locked = maps.Clone(locked)
+}
+
+// lockedVersion returns the version path is pinned to, if any
+func (i *Inputs) lockedVersion(path string) string {
+	if i == nil {
+		return ""
+	}
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	return i.locked[path]
+}
+
+// Resolved returns remote inputs resolved so far, indexed by path
+func (i *Inputs) Resolved() map[string]string {
+	if i == nil {
+		return map[string]string{}
+	}
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	return maps.Clone(i.resolved)
+}
+
+// Artifacts returns the compose OCI artifacts loaded so far, sorted by reference
+func (i *Inputs) Artifacts() []ArtifactInfo {
+	if i == nil {
+		return nil
+	}
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	artifacts := slices.Collect(maps.Values(i.artifacts))
+	slices.SortFunc(artifacts, func(a, b ArtifactInfo) int {
+		return strings.Compare(a.Reference, b.Reference)
+	})
+	return artifacts
+}
+
+func inputsFile(projectName string) (string, error) {
+	cache, err := cacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cache, "inputs", projectName+".json"), nil
+}
+
+// RecordedInputs returns remote inputs recorded by the last run of project, or nil if none was recorded
+func RecordedInputs(projectName string) (map[string]string, error) {
+	path, err := inputsFile(projectName)
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var inputs map[string]string
+	err = json.Unmarshal(b, &inputs)
+	return inputs, err
+}
+
+// RecordInputs saves remote inputs project was loaded with
+func RecordInputs(projectName string, inputs map[string]string) error {
+	path, err := inputsFile(projectName)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(inputs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0o600)
+}
+
+// CheckFrozenInputs returns an error listing remote inputs which changed since they were recorded
+func CheckFrozenInputs(projectName string, current map[string]string) error {
+	recorded, err := RecordedInputs(projectName)
+	if err != nil {
+		return err
+	}
+	if recorded == nil {
+		if len(current) == 0 {
+			return nil
+		}
+		return fmt.Errorf("no remote inputs recorded for project %s, run 'docker compose up' once without --frozen to record them", projectName)
+	}
+	var changes []string
+	for _, path := range slices.Sorted(maps.Keys(current)) {
+		before, ok := recorded[path]
+		switch {
+		case !ok:
+			changes = append(changes, fmt.Sprintf("%s added", path))
+		case before != current[path]:
+			changes = append(changes, fmt.Sprintf("%s changed from %s to %s", path, before, current[path]))
+		}
+	}
+	for _, path := range slices.Sorted(maps.Keys(recorded)) {
+		if _, ok := current[path]; !ok {
+			changes = append(changes, fmt.Sprintf("%s removed", path))
+		}
+	}
+	if len(changes) > 0 {
+		return fmt.Errorf("remote inputs changed since last run (--frozen):\n%s", strings.Join(changes, "\n"))
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package remote
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestFrozenInputs(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	err := CheckFrozenInputs("test", map[string]string{"oci://example.com/app:1.0": "sha256:aaa"})
+	assert.ErrorContains(t, err, "no remote inputs recorded for project test")
+
+	inputs := NewInputs()
+	inputs.record("oci://example.com/app:1.0", "sha256:aaa")
+	inputs.record("oci://example.com/app:1.0#.env", "sha256:bbb")
+	assert.NilError(t, RecordInputs("test", inputs.Resolved()))
+
+	recorded, err := RecordedInputs("test")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, recorded, inputs.Resolved())
+	assert.NilError(t, CheckFrozenInputs("test", inputs.Resolved()))
+
+	err = CheckFrozenInputs("test", map[string]string{
+		"oci://example.com/app:1.0":            "sha256:ccc",
+		"https://github.com/acme/app.git#main": "0123456789abcdef0123456789abcdef01234567",
+	})
+	assert.Error(t, err, `remote inputs changed since last run (--frozen):
+https://github.com/acme/app.git#main added
+oci://example.com/app:1.0 changed from sha256:aaa to sha256:ccc
+oci://example.com/app:1.0#.env removed`)
+}
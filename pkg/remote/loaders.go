@@ -0,0 +1,87 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/compose-spec/compose-go/v2/loader"
+	"github.com/docker/cli/cli/command"
+)
+
+// LoaderFactory creates a loader for remote resources, for the docker CLI running compose
+type LoaderFactory func(dockerCli command.Cli) loader.ResourceLoader
+
+var (
+	loadersMu sync.Mutex
+	factories = map[string]LoaderFactory{}
+)
+
+// RegisterLoader makes a loader available for remote resources referenced as scheme://..., so tools embedding compose
+// can support additional schemes. The loader is only asked to load references with this scheme, after built-in
+// loaders declined them. RegisterLoader panics if scheme is empty or already registered
+func RegisterLoader(scheme string, factory LoaderFactory) {
+	loadersMu.Lock()
+	defer loadersMu.Unlock()
+	if scheme == "" || strings.Contains(scheme, "://") {
+		panic(fmt.Sprintf("remote: invalid loader scheme %q", scheme))
+	}
+	if factory == nil {
+		panic("remote: RegisterLoader factory is nil")
+	}
+	if _, dup := factories[scheme]; dup {
+		panic(fmt.Sprintf("remote: RegisterLoader called twice for scheme %q", scheme))
+	}
+	factories[scheme] = factory
+}
+
+// RegisteredLoaders creates the loaders registered by RegisterLoader, ordered by scheme
+func RegisteredLoaders(dockerCli command.Cli) []loader.ResourceLoader {
+	loadersMu.Lock()
+	defer loadersMu.Unlock()
+	var loaders []loader.ResourceLoader
+	for _, scheme := range slices.Sorted(maps.Keys(factories)) {
+		loaders = append(loaders, schemeLoader{
+			prefix: scheme + "://",
+			loader: factories[scheme](dockerCli),
+		})
+	}
+	return loaders
+}
+
+// schemeLoader restricts a registered loader to the references with its scheme
+type schemeLoader struct {
+	prefix string
+	loader loader.ResourceLoader
+}
+
+func (s schemeLoader) Accept(path string) bool {
+	return strings.HasPrefix(path, s.prefix) && s.loader.Accept(path)
+}
+
+func (s schemeLoader) Load(ctx context.Context, path string) (string, error) {
+	return s.loader.Load(ctx, path)
+}
+
+func (s schemeLoader) Dir(path string) string {
+	return s.loader.Dir(path)
+}
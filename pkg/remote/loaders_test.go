@@ -0,0 +1,72 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/loader"
+	"github.com/docker/cli/cli/command"
+	"gotest.tools/v3/assert"
+)
+
+type fakeLoader struct {
+	dir string
+}
+
+func (f fakeLoader) Accept(path string) bool {
+	return !strings.HasSuffix(path, "/ignored.yaml")
+}
+
+func (f fakeLoader) Load(_ context.Context, path string) (string, error) {
+	return filepath.Join(f.dir, filepath.Base(path)), nil
+}
+
+func (f fakeLoader) Dir(string) string {
+	return f.dir
+}
+
+func TestRegisterLoader(t *testing.T) {
+	defer func() {
+		loadersMu.Lock()
+		delete(factories, "s3")
+		loadersMu.Unlock()
+	}()
+	dir := t.TempDir()
+	RegisterLoader("s3", func(command.Cli) loader.ResourceLoader {
+		return fakeLoader{dir: dir}
+	})
+	assert.Assert(t, func() (panicked bool) {
+		defer func() { panicked = recover() != nil }()
+		RegisterLoader("s3", func(command.Cli) loader.ResourceLoader { return nil })
+		return false
+	}(), "registering a scheme twice must panic")
+
+	loaders := RegisteredLoaders(nil)
+	assert.Equal(t, len(loaders), 1)
+	s3 := loaders[0]
+	assert.Assert(t, s3.Accept("s3://bucket/compose.yaml"))
+	assert.Assert(t, !s3.Accept("s3://bucket/ignored.yaml"))
+	assert.Assert(t, !s3.Accept("oci://example.com/app:1.0"))
+	path, err := s3.Load(context.TODO(), "s3://bucket/compose.yaml")
+	assert.NilError(t, err)
+	assert.Equal(t, path, filepath.Join(dir, "compose.yaml"))
+	assert.Equal(t, s3.Dir("s3://bucket/compose.yaml"), dir)
+}
@@ -0,0 +1,57 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package remote
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/docker/buildx/util/resolver"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+// registryConfig configures the resolver to try mirrors before the registries they mirror, like the engine does to
+// pull images. Mirrors declared with an http:// scheme are accessed without TLS
+func registryConfig(mirrors api.RegistryMirrors) map[string]resolver.RegistryConfig {
+	if len(mirrors) == 0 {
+		return nil
+	}
+	config := map[string]resolver.RegistryConfig{}
+	plainHTTP := true
+	for registry, hosts := range mirrors {
+		rc := config[registry]
+		for _, mirror := range hosts {
+			host := mirror
+			if strings.Contains(mirror, "://") {
+				u, err := url.Parse(mirror)
+				if err != nil {
+					continue
+				}
+				host = u.Host
+				if u.Scheme == "http" {
+					mc := config[host]
+					mc.PlainHTTP = &plainHTTP
+					config[host] = mc
+				}
+			}
+			rc.Mirrors = append(rc.Mirrors, host)
+		}
+		config[registry] = rc
+	}
+	return config
+}
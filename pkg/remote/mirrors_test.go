@@ -0,0 +1,38 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package remote
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+func TestRegistryConfig(t *testing.T) {
+	assert.Assert(t, registryConfig(nil) == nil)
+
+	config := registryConfig(api.RegistryMirrors{
+		"docker.io": {"mirror.corp.local:5000", "https://mirror.gcr.io"},
+		"ghcr.io":   {"http://cache.corp.local"},
+	})
+	assert.DeepEqual(t, config["docker.io"].Mirrors, []string{"mirror.corp.local:5000", "mirror.gcr.io"})
+	assert.DeepEqual(t, config["ghcr.io"].Mirrors, []string{"cache.corp.local"})
+	assert.Assert(t, config["cache.corp.local"].PlainHTTP != nil && *config["cache.corp.local"].PlainHTTP)
+	assert.Assert(t, config["mirror.gcr.io"].PlainHTTP == nil)
+}
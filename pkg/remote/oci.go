@@ -17,51 +17,104 @@
 package remote
 
 import (
+	"archive/tar"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
-	"strconv"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/compose-spec/compose-go/v2/loader"
 	"github.com/distribution/reference"
 	"github.com/docker/buildx/store/storeutil"
-	"github.com/docker/buildx/util/imagetools"
 	"github.com/docker/cli/cli/command"
+	"github.com/docker/compose/v2/internal/features"
 	"github.com/docker/compose/v2/internal/ocipush"
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/progress"
+	"github.com/moby/go-archive"
+	"github.com/opencontainers/go-digest"
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+	"gopkg.in/yaml.v3"
 )
 
 const (
+	// OCI_REMOTE_ENABLED is the environment variable which disables OCI remote resources
+	//
+	// Deprecated: use COMPOSE_FEATURES or features.OCIRemote
 	OCI_REMOTE_ENABLED = "COMPOSE_EXPERIMENTAL_OCI_REMOTE"
 	OciPrefix          = "oci://"
 )
 
-func ociRemoteLoaderEnabled() (bool, error) {
-	if v := os.Getenv(OCI_REMOTE_ENABLED); v != "" {
-		enabled, err := strconv.ParseBool(v)
-		if err != nil {
-			return false, fmt.Errorf("COMPOSE_EXPERIMENTAL_OCI_REMOTE environment variable expects boolean value: %w", err)
-		}
-		return enabled, err
-	}
-	return true, nil
+// OCIRemoteLoaderOptions configures the loader for oci:// resources
+type OCIRemoteLoaderOptions struct {
+	// Offline only serves artifacts from the cache
+	Offline bool
+	// CacheTTL is how long an artifact pulled by tag is used from the cache without resolving the tag again, zero to
+	// always resolve it
+	CacheTTL time.Duration
+	// Rewrites sets the location to retrieve artifacts from, per registry
+	Rewrites api.RegistryRewrites
+	// Mirrors sets the mirrors tried before the registry itself
+	Mirrors api.RegistryMirrors
+	// Transports sets TLS and proxy settings per registry
+	Transports api.RegistryTransports
+	// Auth sets credentials per included resource
+	Auth IncludeAuth
+	// RegistryAuth sets credentials per registry, taking precedence over the docker configuration
+	RegistryAuth RegistryAuth
+	// Verify is the raw cosign verification policy, as parsed by ParseVerifyPolicy: it is only evaluated on load so an
+	// invalid policy fails the load rather than being ignored
+	Verify string
+	// Variants selects the artifact variants merged on top of the base Compose file
+	Variants VariantSelector
+	// Inputs records artifacts resolved by the loader
+	Inputs *Inputs
+	// Flags resolves whether oci:// resources are enabled
+	Flags *features.Flags
 }
 
-func NewOCIRemoteLoader(dockerCli command.Cli, offline bool) loader.ResourceLoader {
+// NewOCIRemoteLoader creates a loader for oci:// resources
+func NewOCIRemoteLoader(dockerCli command.Cli, options OCIRemoteLoaderOptions) loader.ResourceLoader {
 	return ociRemoteLoader{
-		dockerCli: dockerCli,
-		offline:   offline,
-		known:     map[string]string{},
+		dockerCli:  dockerCli,
+		offline:    options.Offline,
+		cacheTTL:   options.CacheTTL,
+		rewrites:   options.Rewrites,
+		mirrors:    options.Mirrors,
+		transports: options.Transports,
+		auth:       options.Auth,
+		registries: options.RegistryAuth,
+		verify:     options.Verify,
+		variants:   options.Variants,
+		inputs:     options.Inputs,
+		flags:      options.Flags,
+		known:      map[string]string{},
 	}
 }
 
 type ociRemoteLoader struct {
-	dockerCli command.Cli
-	offline   bool
-	known     map[string]string
+	dockerCli  command.Cli
+	offline    bool
+	cacheTTL   time.Duration
+	rewrites   api.RegistryRewrites
+	mirrors    api.RegistryMirrors
+	transports api.RegistryTransports
+	auth       IncludeAuth
+	registries RegistryAuth
+	verify     string
+	variants   VariantSelector
+	inputs     *Inputs
+	flags      *features.Flags
+	known      map[string]string
 }
 
 func (g ociRemoteLoader) Accept(path string) bool {
@@ -69,97 +122,362 @@ func (g ociRemoteLoader) Accept(path string) bool {
 }
 
 func (g ociRemoteLoader) Load(ctx context.Context, path string) (string, error) {
-	enabled, err := ociRemoteLoaderEnabled()
+	enabled, err := g.flags.Enabled(features.OCIRemote)
 	if err != nil {
 		return "", err
 	}
 	if !enabled {
-		return "", fmt.Errorf("OCI remote resource is disabled by %q", OCI_REMOTE_ENABLED)
-	}
-
-	if g.offline {
-		return "", nil
+		return "", fmt.Errorf("OCI remote resource is disabled by feature %s", features.OCIRemote.Name)
 	}
 
 	local, ok := g.known[path]
 	if !ok {
-		ref, err := reference.ParseDockerRef(path[len(OciPrefix):])
+		if g.offline {
+			local, err = g.loadOffline(path)
+		} else {
+			local, err = g.loadArtifact(ctx, path)
+		}
 		if err != nil {
 			return "", err
 		}
-
-		opt, err := storeutil.GetImageConfig(g.dockerCli, nil)
-		if err != nil {
+		if err := g.recordArtifact(path, local); err != nil {
 			return "", err
 		}
-		resolver := imagetools.New(opt)
-
-		content, descriptor, err := resolver.Get(ctx, ref.String())
+		err = g.resolveIncludes(ctx, local, []string{path}, map[string]bool{})
 		if err != nil {
 			return "", err
 		}
+	}
+	return g.selectVariants(ctx, local)
+}
+
+// parseReference parses the artifact reference of path, pinned to the digest it is locked to unless already digested
+func (g ociRemoteLoader) parseReference(path string) (reference.Named, error) {
+	ref, err := reference.ParseDockerRef(path[len(OciPrefix):])
+	if err != nil {
+		return nil, err
+	}
+	locked := g.inputs.lockedVersion(path)
+	if _, ok := ref.(reference.Digested); ok || locked == "" {
+		return ref, nil
+	}
+	d, err := digest.Parse(locked)
+	if err != nil {
+		return nil, fmt.Errorf("invalid digest locked for %s: %w", path, err)
+	}
+	return reference.WithDigest(ref, d)
+}
 
-		cache, err := cacheDir()
+// loadArtifact returns the cached copy of an artifact pulled by tag less than cacheTTL ago, or pulls it
+func (g ociRemoteLoader) loadArtifact(ctx context.Context, path string) (string, error) {
+	if g.cacheTTL <= 0 {
+		return g.pullArtifact(ctx, path)
+	}
+	ref, err := g.parseReference(path)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := ref.(reference.Digested); ok || g.verify != "" {
+		// a cached copy may have been pulled without verification, resolve the artifact again to verify it
+		return g.pullArtifact(ctx, path)
+	}
+	local, resolved, err := lookupCacheEntry(CacheOCI, path)
+	if err != nil {
+		return "", err
+	}
+	if local == "" || time.Since(resolved) > g.cacheTTL {
+		return g.pullArtifact(ctx, path)
+	}
+	logrus.Debugf("using %s resolved %s ago", path, time.Since(resolved).Round(time.Second))
+	g.known[path] = local
+	touchCache(local)
+	g.inputs.record(path, digest.NewDigestFromEncoded(digest.SHA256, filepath.Base(local)).String())
+	return local, nil
+}
+
+// pullArtifact resolves path against the registry, and pulls the artifact into the cache unless already there
+func (g ociRemoteLoader) pullArtifact(ctx context.Context, path string) (string, error) {
+	policy, err := ParseVerifyPolicy(g.verify)
+	if err != nil {
+		return "", err
+	}
+	ref, err := g.parseReference(path)
+	if err != nil {
+		return "", err
+	}
+	ref, err = g.rewrites.RewriteNamed(ref)
+	if err != nil {
+		return "", err
+	}
+
+	opt, err := storeutil.GetImageConfig(g.dockerCli, nil)
+	if err != nil {
+		return "", err
+	}
+	// a helper set for the path prefix takes precedence over credentials set for the registry
+	registries := g.registries
+	if helper := g.auth.Helper(path); helper != "" {
+		opt.Auth = credentialHelperAuth{dockerCli: g.dockerCli, helper: helper}
+		registries = nil
+	} else if len(registries) > 0 {
+		opt.Auth = registryHelperAuth{dockerCli: g.dockerCli, registries: registries, fallback: opt.Auth}
+	}
+	resolver := newRegistryResolver(opt.Auth, g.mirrors, g.transports, registries)
+
+	content, descriptor, err := resolver.Get(ctx, ref.String())
+	if err != nil {
+		return "", err
+	}
+
+	cache, err := cacheDir()
+	if err != nil {
+		return "", fmt.Errorf("initializing remote resource cache: %w", err)
+	}
+
+	var manifest v1.Manifest
+	err = json.Unmarshal(content, &manifest)
+	if err != nil {
+		return "", err
+	}
+
+	local := filepath.Join(cache, descriptor.Digest.Hex())
+	_, err = os.Stat(local)
+	pull := os.IsNotExist(err)
+	if policy != nil || pull {
+		err = progress.RunWithTitle(ctx, func(ctx context.Context) error {
+			return g.pull(ctx, policy, local, pull, manifest, ref, descriptor, resolver)
+		}, g.dockerCli.Err(), "Pulling")
 		if err != nil {
-			return "", fmt.Errorf("initializing remote resource cache: %w", err)
+			return "", err
 		}
+	}
+	g.known[path] = local
+	recordCacheSource(local, CacheOCI, path)
+	g.inputs.record(path, descriptor.Digest.String())
+	for _, layer := range manifest.Layers {
+		if envFile, ok := layer.Annotations["com.docker.compose.envfile"]; ok {
+			g.inputs.record(path+"#"+envFile, layer.Digest.String())
+		}
+	}
+	return local, nil
+}
 
-		local = filepath.Join(cache, descriptor.Digest.Hex())
-		if _, err = os.Stat(local); os.IsNotExist(err) {
-			var manifest v1.Manifest
-			err = json.Unmarshal(content, &manifest)
-			if err != nil {
-				return "", err
-			}
+// recordArtifact records the annotations of the artifact loaded from path into local, warning when it requires a
+// newer version of Compose
+func (g ociRemoteLoader) recordArtifact(path, local string) error {
+	info, err := readArtifactInfo(path, local, digest.NewDigestFromEncoded(digest.SHA256, filepath.Base(local)).String())
+	if err != nil {
+		return fmt.Errorf("reading %s annotations: %w", path, err)
+	}
+	if warning := minVersionWarning(info, api.ComposeVersion); warning != "" {
+		logrus.Warn(warning)
+	}
+	g.inputs.recordArtifact(info)
+	return nil
+}
 
-			err = g.pullComposeFiles(ctx, local, manifest, ref, resolver)
+// resolveIncludes loads artifacts included by oci:// reference from the compose file in local, recursively, so nested
+// includes are pulled in the shared cache and include cycles get reported with the chain of references causing them.
+// chain lists the references which led to local, resolved the set of artifacts whose includes were already resolved
+func (g ociRemoteLoader) resolveIncludes(ctx context.Context, local string, chain []string, resolved map[string]bool) error {
+	if resolved[local] {
+		return nil
+	}
+	refs, err := ociIncludes(composeFile(local))
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		if slices.Contains(chain, ref) {
+			return fmt.Errorf("include cycle detected: %s -> %s", strings.Join(chain, " -> "), ref)
+		}
+		nested, ok := g.known[ref]
+		if !ok {
+			if g.offline {
+				nested, err = g.loadOffline(ref)
+			} else {
+				nested, err = g.loadArtifact(ctx, ref)
+			}
 			if err != nil {
-				// we need to clean up the directory to be sure we won't let empty files present
-				_ = os.RemoveAll(local)
-				return "", err
+				return fmt.Errorf("%s included by %s: %w", ref, chain[len(chain)-1], err)
+			}
+			if err := g.recordArtifact(ref, nested); err != nil {
+				return err
 			}
 		}
-		g.known[path] = local
+		if err := g.resolveIncludes(ctx, nested, append(slices.Clone(chain), ref), resolved); err != nil {
+			return err
+		}
 	}
-	return filepath.Join(local, "compose.yaml"), nil
+	resolved[local] = true
+	return nil
 }
 
-func (g ociRemoteLoader) Dir(path string) string {
-	return g.known[path]
+// ociIncludes lists oci:// references included by a compose file. References relying on interpolation are left to
+// the compose loader
+func ociIncludes(file string) ([]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() //nolint:errcheck
+
+	var refs []string
+	decoder := yaml.NewDecoder(f)
+	for {
+		var model struct {
+			Include []yaml.Node `yaml:"include"`
+		}
+		err := decoder.Decode(&model)
+		if errors.Is(err, io.EOF) {
+			return refs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, include := range model.Include {
+			var paths []string
+			switch include.Kind {
+			case yaml.ScalarNode:
+				paths = []string{include.Value}
+			case yaml.MappingNode:
+				var entry struct {
+					Path yaml.Node `yaml:"path"`
+				}
+				if err := include.Decode(&entry); err != nil {
+					return nil, err
+				}
+				switch entry.Path.Kind {
+				case yaml.ScalarNode:
+					paths = []string{entry.Path.Value}
+				case yaml.SequenceNode:
+					if err := entry.Path.Decode(&paths); err != nil {
+						return nil, err
+					}
+				}
+			}
+			for _, path := range paths {
+				if strings.HasPrefix(path, OciPrefix) && !strings.Contains(path, "$") {
+					refs = append(refs, path)
+				}
+			}
+		}
+	}
 }
 
-func (g ociRemoteLoader) pullComposeFiles(ctx context.Context, local string, manifest v1.Manifest, ref reference.Named, resolver *imagetools.Resolver) error { //nolint:gocyclo
-	err := os.MkdirAll(local, 0o700)
+// loadOffline returns the last known local copy of an artifact, as the registry can't be reached to resolve path.
+// Signatures can't be checked without the registry either, so it fails when verification is required
+func (g ociRemoteLoader) loadOffline(path string) (string, error) {
+	policy, err := ParseVerifyPolicy(g.verify)
 	if err != nil {
-		return err
+		return "", err
 	}
-	composeFile := filepath.Join(local, "compose.yaml")
-	f, err := os.Create(composeFile)
+	if policy != nil {
+		return "", fmt.Errorf("%s can't be verified offline, unset COMPOSE_OCI_VERIFY to use the cached copy", path)
+	}
+	cache, err := cacheDir()
 	if err != nil {
-		return err
+		return "", fmt.Errorf("initializing remote resource cache: %w", err)
 	}
-	defer f.Close() //nolint:errcheck
-	if (manifest.ArtifactType != "" && manifest.ArtifactType != ocipush.ComposeProjectArtifactType) ||
-		(manifest.ArtifactType == "" && manifest.Config.MediaType != ocipush.ComposeEmptyConfigMediaType) {
-		return fmt.Errorf("%s is not a compose project OCI artifact, but %s", ref.String(), manifest.ArtifactType)
+	var local string
+	ref, err := g.parseReference(path)
+	if err != nil {
+		return "", err
+	}
+	if digested, ok := ref.(reference.Digested); ok {
+		// digest pins the artifact content, so cached copy is up-to-date by design
+		if _, err := os.Stat(filepath.Join(cache, digested.Digest().Encoded())); err == nil {
+			local = filepath.Join(cache, digested.Digest().Encoded())
+		}
+	}
+	if local == "" {
+		local, err = lookupCache(CacheOCI, path)
+		if err != nil {
+			return "", err
+		}
+		if local == "" {
+			return "", fmt.Errorf("%s is not available offline as it was never pulled", path)
+		}
+		logrus.Warnf("Offline mode: using last known copy of %s, which may be outdated", path)
 	}
+	g.known[path] = local
+	touchCache(local)
+	g.inputs.record(path, digest.NewDigestFromEncoded(digest.SHA256, filepath.Base(local)).String())
+	return local, nil
+}
 
-	for i, layer := range manifest.Layers {
-		digested, err := reference.WithDigest(ref, layer.Digest)
+func (g ociRemoteLoader) Dir(path string) string {
+	return g.known[path]
+}
+
+// pull verifies the artifact signature according to policy, and downloads compose files into local unless they are
+// already cached, reporting progress
+func (g ociRemoteLoader) pull(ctx context.Context, policy *VerifyPolicy, local string, download bool, manifest v1.Manifest, ref reference.Named, descriptor v1.Descriptor, resolver *registryResolver) error {
+	w := progress.ContextWriter(ctx)
+	eventName := reference.FamiliarString(ref)
+	w.Event(progress.Event{ID: eventName, Text: "Pulling", Status: progress.Working})
+	if policy != nil {
+		digested, err := reference.WithDigest(reference.TrimNamed(ref), descriptor.Digest)
 		if err != nil {
 			return err
 		}
-		content, _, err := resolver.Get(ctx, digested.String())
+		w.Event(progress.Event{ID: "Signature", ParentID: eventName, Text: "Verifying", Status: progress.Working})
+		if err := policy.verify(ctx, digested); err != nil {
+			w.Event(progress.Event{ID: "Signature", ParentID: eventName, Text: "Verification failed", Status: progress.Error})
+			w.Event(progress.ErrorEvent(eventName))
+			return err
+		}
+		w.Event(progress.Event{ID: "Signature", ParentID: eventName, Text: "Verified", Status: progress.Done})
+	}
+	if download {
+		if err := g.pullComposeFiles(ctx, local, manifest, ref, resolver); err != nil {
+			// we need to clean up the directory to be sure we won't let empty files present
+			_ = os.RemoveAll(local)
+			w.Event(progress.ErrorEvent(eventName))
+			return err
+		}
+	}
+	w.Event(progress.Event{ID: eventName, Text: "Pulled", Status: progress.Done})
+	return nil
+}
+
+func (g ociRemoteLoader) pullComposeFiles(ctx context.Context, local string, manifest v1.Manifest, ref reference.Named, resolver *registryResolver) error { //nolint:gocyclo
+	err := os.MkdirAll(local, 0o700)
+	if err != nil {
+		return err
+	}
+	if !ocipush.IsComposeArtifact(manifest) {
+		return fmt.Errorf("%s is not a compose project OCI artifact, but %s", ref.String(), manifest.ArtifactType)
+	}
+	// a project bundle provides the Compose file, otherwise Compose file layers are merged into compose.yaml
+	bundled := slices.ContainsFunc(manifest.Layers, func(layer v1.Descriptor) bool {
+		return layer.MediaType == ocipush.ComposeBundleMediaType
+	})
+	var f *os.File
+	if !bundled {
+		f, err = os.Create(filepath.Join(local, "compose.yaml"))
 		if err != nil {
 			return err
 		}
+		defer f.Close() //nolint:errcheck
+	}
 
+	contents, err := downloadLayers(ctx, manifest, ref, resolver)
+	if err != nil {
+		return err
+	}
+	variants := map[string]string{}
+	for i, layer := range manifest.Layers {
+		content := contents[i]
 		switch layer.MediaType {
 		case ocipush.ComposeYAMLMediaType:
 			target := f
 			_, extends := layer.Annotations["com.docker.compose.extends"]
+			if !extends && bundled {
+				return fmt.Errorf("%s declares both a project bundle and Compose file layers", ref.String())
+			}
 			if extends {
-				target, err = os.Create(filepath.Join(local, layer.Annotations["com.docker.compose.file"]))
+				target, err = openLocalFile(local, layer.Annotations["com.docker.compose.file"], os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
 				if err != nil {
 					return err
 				}
@@ -171,10 +489,113 @@ func (g ociRemoteLoader) pullComposeFiles(ctx context.Context, local string, man
 			if err := writeEnvFile(layer, local, content); err != nil {
 				return err
 			}
+		case ocipush.ComposeSetupMediaType:
+			if err := writeLocalFile(local, SetupFile, content); err != nil {
+				return err
+			}
+		case ocipush.ComposeContentMediaType:
+			if err := writeContent(layer, local, content); err != nil {
+				return err
+			}
+		case ocipush.ComposeProvenanceMediaType:
+			if err := writeLocalFile(local, ProvenanceFile, content); err != nil {
+				return err
+			}
+		case ocipush.ComposeBundleMediaType:
+			if f != nil {
+				return fmt.Errorf("%s declares several project bundles", ref.String())
+			}
+			entry, err := writeBundle(layer, local, content)
+			if err != nil {
+				return err
+			}
+			f, err = openLocalFile(local, entry, os.O_WRONLY|os.O_APPEND)
+			if err != nil {
+				return fmt.Errorf("%s bundle entry: %w", ref.String(), err)
+			}
+			defer f.Close() //nolint:errcheck
+		case ocipush.ComposeVariantMediaType:
+			if err := writeVariant(layer, local, content, variants); err != nil {
+				return err
+			}
 		case ocipush.ComposeEmptyConfigMediaType:
 		}
 	}
-	return nil
+	if len(variants) > 0 {
+		index, err := json.Marshal(variants)
+		if err != nil {
+			return err
+		}
+		if err := writeLocalFile(local, VariantsFile, index); err != nil {
+			return err
+		}
+	}
+	if err := writeAnnotationsFile(local, manifest.Annotations); err != nil {
+		return err
+	}
+	return writeAnnotations(f, manifest.Annotations)
+}
+
+// AnnotationsExtension is the top-level extension exposing annotations set on the published artifact manifest
+const AnnotationsExtension = "x-oci-annotations"
+
+// writeAnnotations appends the manifest annotations set by the publisher to the Compose file as a top-level
+// extension, so they are visible in the project model. Creation date set by Compose is skipped
+func writeAnnotations(f *os.File, annotations map[string]string) error {
+	custom := map[string]string{}
+	for key, value := range annotations {
+		if key != v1.AnnotationCreated {
+			custom[key] = value
+		}
+	}
+	if len(custom) == 0 {
+		return nil
+	}
+	content, err := yaml.Marshal(map[string]any{AnnotationsExtension: custom})
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write([]byte("\n---\n")); err != nil {
+		return err
+	}
+	_, err = f.Write(content)
+	return err
+}
+
+// maxLayerDownloads bounds the number of layers downloaded concurrently
+const maxLayerDownloads = 4
+
+// downloadLayers fetches the artifact layers concurrently, and returns their verified content in manifest order
+func downloadLayers(ctx context.Context, manifest v1.Manifest, ref reference.Named, resolver *registryResolver) ([][]byte, error) {
+	w := progress.ContextWriter(ctx)
+	parent := reference.FamiliarString(ref)
+	contents := make([][]byte, len(manifest.Layers))
+	eg, ctx := errgroup.WithContext(ctx)
+	eg.SetLimit(maxLayerDownloads)
+	for i, layer := range manifest.Layers {
+		eg.Go(func() error {
+			digested, err := reference.WithDigest(ref, layer.Digest)
+			if err != nil {
+				return err
+			}
+			eventName := layer.Digest.Encoded()[:12]
+			w.Event(progress.Event{ID: eventName, ParentID: parent, Text: "Downloading", Status: progress.Working, Total: layer.Size})
+			content, _, err := resolver.Get(ctx, digested.String())
+			if err != nil {
+				w.Event(progress.Event{ID: eventName, ParentID: parent, Text: "Download failed", Status: progress.Error})
+				return err
+			}
+			w.Event(progress.Event{ID: eventName, ParentID: parent, Text: "Verifying Checksum", Status: progress.Working, Current: int64(len(content)), Total: layer.Size, Percent: 100})
+			if err := checkLayerDigest(layer, content); err != nil {
+				w.Event(progress.Event{ID: eventName, ParentID: parent, Text: "Checksum mismatch", Status: progress.Error})
+				return fmt.Errorf("%s: %w", ref.String(), err)
+			}
+			w.Event(progress.Event{ID: eventName, ParentID: parent, Text: "Pull complete", Status: progress.Done})
+			contents[i] = content
+			return nil
+		})
+	}
+	return contents, eg.Wait()
 }
 
 func writeComposeFile(layer v1.Descriptor, i int, f *os.File, content []byte) error {
@@ -188,20 +609,137 @@ func writeComposeFile(layer v1.Descriptor, i int, f *os.File, content []byte) er
 	return err
 }
 
+// checkLayerDigest verifies content downloaded for layer matches its digest
+func checkLayerDigest(layer v1.Descriptor, content []byte) error {
+	if err := layer.Digest.Validate(); err != nil {
+		return err
+	}
+	if actual := layer.Digest.Algorithm().FromBytes(content); actual != layer.Digest {
+		return fmt.Errorf("layer %s doesn't match its digest, got %s", layer.Digest, actual)
+	}
+	return nil
+}
+
 func writeEnvFile(layer v1.Descriptor, local string, content []byte) error {
 	envfilePath, ok := layer.Annotations["com.docker.compose.envfile"]
 	if !ok {
 		return fmt.Errorf("missing annotation com.docker.compose.envfile in layer %q", layer.Digest)
 	}
-	otherFile, err := os.Create(filepath.Join(local, envfilePath))
+	return writeLocalFile(local, envfilePath, content)
+}
+
+// openLocalFile opens name in the directory local an artifact is pulled into. It refuses paths leaving local, and
+// symbolic links an archive layer may have extracted there, so writes can't reach other files on the host
+func openLocalFile(local, name string, flag int) (*os.File, error) {
+	if !filepath.IsLocal(name) {
+		return nil, fmt.Errorf("%s is outside of the artifact directory", name)
+	}
+	path := filepath.Join(local, name)
+	if info, err := os.Lstat(path); err == nil && !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("refusing to write %s in the artifact directory, as it is not a regular file", name)
+	}
+	return os.OpenFile(path, flag, 0o600)
+}
+
+// writeLocalFile writes content to name in the directory local an artifact is pulled into, see openLocalFile
+func writeLocalFile(local, name string, content []byte) error {
+	f, err := openLocalFile(local, name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
 	if err != nil {
 		return err
 	}
-	_, err = otherFile.Write(content)
+	if _, err := f.Write(content); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// untarLayer extracts an archive layer into dest, after checking links it declares can't be followed out of dest
+func untarLayer(layer v1.Descriptor, dest string, content []byte) error {
+	if err := checkArchiveLinks(layer, content); err != nil {
+		return err
+	}
+	return archive.Untar(bytes.NewReader(content), dest, &archive.TarOptions{NoLchown: true})
+}
+
+// checkArchiveLinks rejects archives with links to absolute paths or traversing parent directories. Checking a link
+// target stays in the archive isn't enough, as it may be resolved through another link: only accepting links pointing
+// down the tree guarantees none can be followed out of it
+func checkArchiveLinks(layer v1.Descriptor, content []byte) error {
+	stream, err := archive.DecompressStream(bytes.NewReader(content))
 	if err != nil {
 		return err
 	}
-	return nil
+	defer stream.Close() //nolint:errcheck
+	reader := tar.NewReader(stream)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeSymlink && header.Typeflag != tar.TypeLink {
+			continue
+		}
+		target := filepath.FromSlash(header.Linkname)
+		if !filepath.IsLocal(target) || slices.Contains(strings.Split(filepath.ToSlash(target), "/"), "..") {
+			return fmt.Errorf("layer %q links %s to %s, outside of the archive", layer.Digest, header.Name, header.Linkname)
+		}
+	}
+}
+
+// BundleFile records the entry Compose file of an artifact published as a project bundle, next to the compose file
+const BundleFile = "compose-bundle.json"
+
+type bundleIndex struct {
+	Entry string `json:"entry"`
+}
+
+// writeBundle extracts a project bundle into local, and returns its entry Compose file
+func writeBundle(layer v1.Descriptor, local string, content []byte) (string, error) {
+	entry := layer.Annotations["com.docker.compose.file"]
+	if entry == "" {
+		entry = "compose.yaml"
+	}
+	if filepath.Base(entry) != entry || !filepath.IsLocal(entry) {
+		return "", fmt.Errorf("layer %q entry %s must be a file at the root of the bundle", layer.Digest, entry)
+	}
+	if err := untarLayer(layer, local, content); err != nil {
+		return "", err
+	}
+	index, err := json.Marshal(bundleIndex{Entry: entry})
+	if err != nil {
+		return "", err
+	}
+	return entry, writeLocalFile(local, BundleFile, index)
+}
+
+// composeFile returns the Compose file of an artifact pulled into local, the entry of its bundle if published as such
+func composeFile(local string) string {
+	var index bundleIndex
+	if data, err := os.ReadFile(filepath.Join(local, BundleFile)); err == nil && json.Unmarshal(data, &index) == nil && index.Entry != "" {
+		return filepath.Join(local, filepath.Base(index.Entry))
+	}
+	return filepath.Join(local, "compose.yaml")
+}
+
+// writeContent extracts local files packaged by publish --with-content relative to the project directory
+func writeContent(layer v1.Descriptor, local string, content []byte) error {
+	path, ok := layer.Annotations["com.docker.compose.content"]
+	if !ok {
+		return fmt.Errorf("missing annotation com.docker.compose.content in layer %q", layer.Digest)
+	}
+	path = filepath.FromSlash(path)
+	if !filepath.IsLocal(path) {
+		return fmt.Errorf("layer %q content path %s is outside of the project directory", layer.Digest, path)
+	}
+	target := filepath.Join(local, path)
+	if err := os.MkdirAll(target, 0o755); err != nil {
+		return err
+	}
+	return untarLayer(layer, target, content)
 }
 
 var _ loader.ResourceLoader = ociRemoteLoader{}
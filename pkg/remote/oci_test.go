@@ -0,0 +1,339 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package remote
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/cli"
+	"github.com/distribution/reference"
+	"github.com/docker/compose/v2/internal/features"
+	"github.com/docker/compose/v2/internal/ocipush"
+	"github.com/moby/go-archive"
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+)
+
+func TestOCIRemoteLoaderOffline(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	cache, err := cacheDir()
+	assert.NilError(t, err)
+	sum := digest.SHA256.FromString("manifest")
+	local := filepath.Join(cache, sum.Encoded())
+	assert.NilError(t, os.MkdirAll(local, 0o700))
+	assert.NilError(t, os.WriteFile(filepath.Join(local, "compose.yaml"), []byte("services: {}\n"), 0o600))
+
+	l := NewOCIRemoteLoader(nil, OCIRemoteLoaderOptions{Offline: true, Flags: features.NewFlags(nil)})
+	_, err = l.Load(context.TODO(), "oci://example.com/app:1.0")
+	assert.ErrorContains(t, err, "oci://example.com/app:1.0 is not available offline as it was never pulled")
+
+	// a digest designates the cached copy, even if pulled by another reference
+	path, err := l.Load(context.TODO(), "oci://example.com/app@"+sum.String())
+	assert.NilError(t, err)
+	assert.Equal(t, path, filepath.Join(local, "compose.yaml"))
+
+	// signatures can't be verified without the registry
+	verified := NewOCIRemoteLoader(nil, OCIRemoteLoaderOptions{Offline: true, Verify: "key=cosign.pub", Flags: features.NewFlags(nil)})
+	_, err = verified.Load(context.TODO(), "oci://example.com/app@"+sum.String())
+	assert.ErrorContains(t, err, "can't be verified offline")
+
+	recordCacheSource(local, CacheOCI, "oci://example.com/app:1.0")
+	inputs := NewInputs()
+	l = NewOCIRemoteLoader(nil, OCIRemoteLoaderOptions{Offline: true, Inputs: inputs, Flags: features.NewFlags(nil)})
+	path, err = l.Load(context.TODO(), "oci://example.com/app:1.0")
+	assert.NilError(t, err)
+	assert.Equal(t, path, filepath.Join(local, "compose.yaml"))
+	assert.Equal(t, l.Dir("oci://example.com/app:1.0"), local)
+	assert.Equal(t, inputs.Resolved()["oci://example.com/app:1.0"], sum.String())
+}
+
+func TestOCIRemoteLoaderCacheTTL(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	cache, err := cacheDir()
+	assert.NilError(t, err)
+	sum := digest.SHA256.FromString("manifest")
+	local := filepath.Join(cache, sum.Encoded())
+	assert.NilError(t, os.MkdirAll(local, 0o700))
+	assert.NilError(t, os.WriteFile(filepath.Join(local, "compose.yaml"), []byte("services: {}\n"), 0o600))
+	recordCacheSource(local, CacheOCI, "oci://example.com/app:1.0")
+
+	inputs := NewInputs()
+	l := NewOCIRemoteLoader(nil, OCIRemoteLoaderOptions{CacheTTL: time.Hour, Inputs: inputs, Flags: features.NewFlags(nil)})
+	path, err := l.Load(context.TODO(), "oci://example.com/app:1.0")
+	assert.NilError(t, err)
+	assert.Equal(t, path, filepath.Join(local, "compose.yaml"))
+	assert.Equal(t, inputs.Resolved()["oci://example.com/app:1.0"], sum.String())
+
+	// an invalid verification policy makes pulls fail before reaching the registry. As the cached copy may not have
+	// been verified, verification requires the artifact to be resolved again
+	l = NewOCIRemoteLoader(nil, OCIRemoteLoaderOptions{CacheTTL: time.Hour, Verify: "invalid", Flags: features.NewFlags(nil)})
+	_, err = l.Load(context.TODO(), "oci://example.com/app:1.0")
+	assert.ErrorContains(t, err, `invalid verify setting "invalid"`)
+
+	// digests are always resolved as they don't need revalidation
+	l = NewOCIRemoteLoader(nil, OCIRemoteLoaderOptions{CacheTTL: time.Hour, Verify: "invalid", Flags: features.NewFlags(nil)})
+	_, err = l.Load(context.TODO(), "oci://example.com/app@"+sum.String())
+	assert.ErrorContains(t, err, `invalid verify setting "invalid"`)
+
+	expired := time.Now().Add(-2 * time.Hour)
+	assert.NilError(t, os.Chtimes(local+".json", expired, expired))
+	l = NewOCIRemoteLoader(nil, OCIRemoteLoaderOptions{CacheTTL: time.Hour, Verify: "invalid", Flags: features.NewFlags(nil)})
+	_, err = l.Load(context.TODO(), "oci://example.com/app:1.0")
+	assert.ErrorContains(t, err, `invalid verify setting "invalid"`)
+
+	l = NewOCIRemoteLoader(nil, OCIRemoteLoaderOptions{Verify: "invalid", Flags: features.NewFlags(nil)})
+	_, err = l.Load(context.TODO(), "oci://example.com/app:1.0")
+	assert.ErrorContains(t, err, `invalid verify setting "invalid"`)
+}
+
+func TestCheckLayerDigest(t *testing.T) {
+	layer := v1.Descriptor{Digest: digest.FromString("services: {}\n")}
+	assert.NilError(t, checkLayerDigest(layer, []byte("services: {}\n")))
+	assert.ErrorContains(t, checkLayerDigest(layer, []byte("services: {tampered: {}}\n")), "doesn't match its digest")
+	assert.ErrorContains(t, checkLayerDigest(v1.Descriptor{Digest: "sha256:invalid"}, nil), "invalid")
+}
+
+func TestDownloadLayers(t *testing.T) {
+	blobs := map[string][]byte{}
+	var layers []v1.Descriptor
+	for i := range 10 {
+		content := []byte(fmt.Sprintf("VAR_%d=value\n", i))
+		layer := v1.Descriptor{MediaType: ocipush.ComposeEnvFileMediaType, Digest: digest.FromBytes(content), Size: int64(len(content))}
+		blobs["/v2/app/blobs/"+layer.Digest.String()] = content
+		layers = append(layers, layer)
+	}
+	var (
+		mu               sync.Mutex
+		running, highest int
+	)
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, ok := blobs[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		mu.Lock()
+		running++
+		highest = max(highest, running)
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(content)
+		}
+	}))
+	defer registry.Close()
+
+	ref, err := reference.ParseNormalizedNamed(strings.TrimPrefix(registry.URL, "http://") + "/app:1.0")
+	assert.NilError(t, err)
+	contents, err := downloadLayers(context.TODO(), v1.Manifest{Layers: layers}, ref, newRegistryResolver(nil, nil, nil, nil))
+	assert.NilError(t, err)
+	for i, content := range contents {
+		assert.Equal(t, string(content), fmt.Sprintf("VAR_%d=value\n", i))
+	}
+	assert.Assert(t, highest > 1 && highest <= maxLayerDownloads, highest)
+
+	layers[3].Digest = digest.FromString("tampered")
+	_, err = downloadLayers(context.TODO(), v1.Manifest{Layers: layers}, ref, newRegistryResolver(nil, nil, nil, nil))
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestOCIRemoteLoaderNestedIncludes(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	cache, err := cacheDir()
+	assert.NilError(t, err)
+	artifact := func(ref, content string) string {
+		local := filepath.Join(cache, digest.SHA256.FromString(ref).Encoded())
+		assert.NilError(t, os.MkdirAll(local, 0o700))
+		assert.NilError(t, os.WriteFile(filepath.Join(local, "compose.yaml"), []byte(content), 0o600))
+		recordCacheSource(local, CacheOCI, ref)
+		return local
+	}
+	artifact("oci://example.com/app:1.0", `include:
+  - oci://example.com/db:1.0
+  - path: [oci://example.com/cache:1.0]
+  - oci://example.com/${MONITORING}:1.0
+services:
+  app:
+    image: app
+`)
+	db := artifact("oci://example.com/db:1.0", "include:\n  - oci://example.com/cache:1.0\nservices:\n  db:\n    image: db\n")
+	artifact("oci://example.com/cache:1.0", "services:\n  cache:\n    image: cache\n")
+
+	l := NewOCIRemoteLoader(nil, OCIRemoteLoaderOptions{Offline: true, Flags: features.NewFlags(nil)})
+	_, err = l.Load(context.TODO(), "oci://example.com/app:1.0")
+	assert.NilError(t, err)
+	assert.Equal(t, l.Dir("oci://example.com/db:1.0"), db)
+	assert.Assert(t, l.Dir("oci://example.com/cache:1.0") != "")
+
+	artifact("oci://example.com/cache:1.0", "include:\n  - oci://example.com/app:1.0\n")
+	l = NewOCIRemoteLoader(nil, OCIRemoteLoaderOptions{Offline: true, Flags: features.NewFlags(nil)})
+	_, err = l.Load(context.TODO(), "oci://example.com/app:1.0")
+	assert.ErrorContains(t, err, "include cycle detected: oci://example.com/app:1.0 -> oci://example.com/db:1.0 -> oci://example.com/cache:1.0 -> oci://example.com/app:1.0")
+
+	artifact("oci://example.com/cache:1.0", "include:\n  - oci://example.com/missing:1.0\n")
+	l = NewOCIRemoteLoader(nil, OCIRemoteLoaderOptions{Offline: true, Flags: features.NewFlags(nil)})
+	_, err = l.Load(context.TODO(), "oci://example.com/app:1.0")
+	assert.ErrorContains(t, err, "oci://example.com/missing:1.0 included by oci://example.com/cache:1.0")
+}
+
+func TestWriteAnnotations(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "compose.yaml")
+	f, err := os.Create(file)
+	assert.NilError(t, err)
+	_, err = f.WriteString("services:\n  app:\n    image: alpine\n")
+	assert.NilError(t, err)
+	assert.NilError(t, writeAnnotations(f, map[string]string{
+		v1.AnnotationCreated: "2024-01-01T00:00:00Z",
+		"com.example.team":   "payments",
+	}))
+	assert.NilError(t, f.Close())
+
+	project, err := cli.ProjectFromOptions(context.TODO(), &cli.ProjectOptions{
+		Name:        "test",
+		ConfigPaths: []string{file},
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, project.Extensions[AnnotationsExtension], map[string]any{"com.example.team": "payments"})
+	assert.Equal(t, project.Services["app"].Image, "alpine")
+}
+
+func TestPullComposeFilesBundle(t *testing.T) {
+	project := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(project, "compose.prod.yaml"), []byte("services:\n  app:\n    build: ./app\n"), 0o600))
+	assert.NilError(t, os.MkdirAll(filepath.Join(project, "app"), 0o700))
+	assert.NilError(t, os.WriteFile(filepath.Join(project, "app", "Dockerfile"), []byte("FROM scratch\n"), 0o600))
+	tar, err := archive.TarWithOptions(project, &archive.TarOptions{Compression: archive.Gzip})
+	assert.NilError(t, err)
+	bundle, err := io.ReadAll(tar)
+	assert.NilError(t, err)
+	assert.NilError(t, tar.Close())
+
+	layer := v1.Descriptor{
+		MediaType:   ocipush.ComposeBundleMediaType,
+		Digest:      digest.FromBytes(bundle),
+		Size:        int64(len(bundle)),
+		Annotations: map[string]string{"com.docker.compose.file": "compose.prod.yaml"},
+	}
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/app/blobs/"+layer.Digest.String() {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(bundle)))
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(bundle)
+		}
+	}))
+	defer registry.Close()
+	ref, err := reference.ParseNormalizedNamed(strings.TrimPrefix(registry.URL, "http://") + "/app:1.0")
+	assert.NilError(t, err)
+
+	manifest := v1.Manifest{
+		ArtifactType: ocipush.ComposeProjectArtifactType,
+		Layers:       []v1.Descriptor{layer},
+		Annotations:  map[string]string{"com.example.team": "payments"},
+	}
+	local := filepath.Join(t.TempDir(), "artifact")
+	assert.NilError(t, ociRemoteLoader{}.pullComposeFiles(context.TODO(), local, manifest, ref, newRegistryResolver(nil, nil, nil, nil)))
+	assert.Equal(t, composeFile(local), filepath.Join(local, "compose.prod.yaml"))
+	_, err = os.Stat(filepath.Join(local, "app", "Dockerfile"))
+	assert.NilError(t, err)
+	content, err := os.ReadFile(composeFile(local))
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), "services:\n  app:\n    build: ./app\n\n---\nx-oci-annotations:\n    com.example.team: payments\n")
+
+	manifest.Layers = append(manifest.Layers, v1.Descriptor{MediaType: ocipush.ComposeYAMLMediaType, Digest: layer.Digest, Size: layer.Size})
+	err = ociRemoteLoader{}.pullComposeFiles(context.TODO(), t.TempDir(), manifest, ref, newRegistryResolver(nil, nil, nil, nil))
+	assert.ErrorContains(t, err, "declares both a project bundle and Compose file layers")
+
+	layer.Annotations["com.docker.compose.file"] = "../compose.yaml"
+	_, err = writeBundle(layer, t.TempDir(), bundle)
+	assert.ErrorContains(t, err, "must be a file at the root of the bundle")
+}
+
+func TestPullComposeFilesBundleLinks(t *testing.T) {
+	host := filepath.Join(t.TempDir(), "bashrc")
+	assert.NilError(t, os.WriteFile(host, []byte("export PATH\n"), 0o600))
+	bundle := func(links map[string]string) []byte {
+		var buf bytes.Buffer
+		w := tar.NewWriter(&buf)
+		assert.NilError(t, w.WriteHeader(&tar.Header{Name: "compose.yaml", Mode: 0o600, Size: int64(len("services: {}\n"))}))
+		_, err := w.Write([]byte("services: {}\n"))
+		assert.NilError(t, err)
+		for name, target := range links {
+			assert.NilError(t, w.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeSymlink, Linkname: target}))
+		}
+		assert.NilError(t, w.Close())
+		return buf.Bytes()
+	}
+	layer := v1.Descriptor{MediaType: ocipush.ComposeBundleMediaType}
+
+	for _, target := range []string{host, "../bashrc", "docs/../compose.yaml"} {
+		_, err := writeBundle(layer, t.TempDir(), bundle(map[string]string{"link": target}))
+		assert.ErrorContains(t, err, "outside of the archive")
+	}
+
+	// links inside the bundle are kept, but files pulled next to the bundle are never written through them
+	local := t.TempDir()
+	_, err := writeBundle(layer, local, bundle(map[string]string{"docs": "compose.yaml", AnnotationsFile: "compose.yaml"}))
+	assert.NilError(t, err)
+	err = writeAnnotationsFile(local, map[string]string{"com.example.team": "payments"})
+	assert.ErrorContains(t, err, "not a regular file")
+	content, err := os.ReadFile(filepath.Join(local, "compose.yaml"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), "services: {}\n")
+}
+
+func TestOCIRemoteLoaderLocked(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	cache, err := cacheDir()
+	assert.NilError(t, err)
+	sum := digest.SHA256.FromString("manifest")
+	local := filepath.Join(cache, sum.Encoded())
+	assert.NilError(t, os.MkdirAll(local, 0o700))
+	assert.NilError(t, os.WriteFile(filepath.Join(local, "compose.yaml"), []byte("services: {}\n"), 0o600))
+
+	// the locked digest designates the cached copy, though the tag was never pulled
+	inputs := NewInputs()
+	inputs.Lock(map[string]string{"oci://example.com/app:1.0": sum.String()})
+	l := NewOCIRemoteLoader(nil, OCIRemoteLoaderOptions{Offline: true, Inputs: inputs, Flags: features.NewFlags(nil)})
+	path, err := l.Load(context.TODO(), "oci://example.com/app:1.0")
+	assert.NilError(t, err)
+	assert.Equal(t, path, filepath.Join(local, "compose.yaml"))
+	assert.Equal(t, inputs.Resolved()["oci://example.com/app:1.0"], sum.String())
+
+	inputs.Lock(map[string]string{"oci://example.com/app:2.0": "invalid"})
+	_, err = l.Load(context.TODO(), "oci://example.com/app:2.0")
+	assert.ErrorContains(t, err, "invalid digest locked for oci://example.com/app:2.0")
+}
@@ -0,0 +1,183 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package remote
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/containerd/containerd/v2/core/remotes/docker"
+	"github.com/containerd/log"
+	"github.com/distribution/reference"
+	"github.com/docker/buildx/util/imagetools"
+	"github.com/docker/buildx/util/resolver"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+// registryResolver fetches content from registries like imagetools.Resolver does, but keeps the HTTP client set for
+// each host, so mirrors and registries with custom transport settings can be reached
+type registryResolver struct {
+	hosts docker.RegistryHosts
+}
+
+func newRegistryResolver(auth imagetools.Auth, mirrors api.RegistryMirrors, transports api.RegistryTransports, registries RegistryAuth) *registryResolver {
+	defaults := resolver.NewRegistryConfig(registryConfig(mirrors))
+	authorizer := docker.NewDockerAuthorizer(docker.WithAuthCreds(authCredentials(auth)), docker.WithAuthClient(http.DefaultClient))
+	return &registryResolver{
+		hosts: func(domain string) ([]docker.RegistryHost, error) {
+			hosts, err := defaults(domain)
+			if err != nil {
+				return nil, err
+			}
+			for i, host := range hosts {
+				hosts[i].Authorizer = authorizer
+				transport, ok := transports[host.Host]
+				if !ok && host.Host == "registry-1.docker.io" {
+					transport, ok = transports["docker.io"]
+				}
+				if !ok {
+					continue
+				}
+				client, err := transportClient(transport)
+				if err != nil {
+					return nil, fmt.Errorf("registry %s: %w", host.Host, err)
+				}
+				hosts[i].Client = client
+				hosts[i].Authorizer = docker.NewDockerAuthorizer(docker.WithAuthCreds(authCredentials(auth)), docker.WithAuthClient(client))
+			}
+			for i, host := range hosts {
+				if c, ok := registries.lookup(host.Host); ok && c.Token != "" {
+					hosts[i].Authorizer = tokenAuthorizer(c.Token)
+				}
+			}
+			return hosts, nil
+		},
+	}
+}
+
+// tokenAuthorizer sends a static bearer token with requests to a registry
+type tokenAuthorizer string
+
+func (t tokenAuthorizer) Authorize(_ context.Context, req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+string(t))
+	return nil
+}
+
+func (t tokenAuthorizer) AddResponses(context.Context, []*http.Response) error {
+	return errors.New("registry rejected the token set for it")
+}
+
+// Get resolves ref and returns the content it points to
+func (r *registryResolver) Get(ctx context.Context, ref string) ([]byte, v1.Descriptor, error) {
+	named, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return nil, v1.Descriptor{}, err
+	}
+	// discard containerd logger, which reports each host failing to resolve ref at info level
+	logger := logrus.New()
+	logger.Out = io.Discard
+	ctx = log.WithLogger(ctx, logrus.NewEntry(logger))
+
+	res := docker.NewResolver(docker.ResolverOptions{Hosts: r.hosts})
+	name, descriptor, err := res.Resolve(ctx, reference.TagNameOnly(named).String())
+	if err != nil {
+		return nil, v1.Descriptor{}, err
+	}
+	fetcher, err := res.Fetcher(ctx, name)
+	if err != nil {
+		return nil, v1.Descriptor{}, err
+	}
+	rc, err := fetcher.Fetch(ctx, descriptor)
+	if err != nil {
+		return nil, v1.Descriptor{}, err
+	}
+	defer rc.Close() //nolint:errcheck
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, rc); err != nil {
+		return nil, v1.Descriptor{}, err
+	}
+	return buf.Bytes(), descriptor, nil
+}
+
+// authCredentials looks up auth for the registry host a request is sent to, Docker Hub credentials being stored under
+// the index address
+func authCredentials(auth imagetools.Auth) func(string) (string, string, error) {
+	return func(host string) (string, string, error) {
+		if auth == nil {
+			return "", "", nil
+		}
+		if host == "registry-1.docker.io" {
+			host = "https://index.docker.io/v1/"
+		}
+		ac, err := auth.GetAuthConfig(host)
+		if err != nil {
+			return "", "", err
+		}
+		if ac.IdentityToken != "" {
+			return "", ac.IdentityToken, nil
+		}
+		return ac.Username, ac.Password, nil
+	}
+}
+
+// transportClient creates an HTTP client trusting the CA bundle, presenting the client certificate and using the
+// proxy declared by transport
+func transportClient(transport api.RegistryTransport) (*http.Client, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if transport.CA != "" {
+		pem, err := os.ReadFile(transport.CA)
+		if err != nil {
+			return nil, err
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", transport.CA)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if transport.Cert != "" {
+		cert, err := tls.LoadX509KeyPair(transport.Cert, transport.Key)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.TLSClientConfig = tlsConfig
+	if transport.Proxy != "" {
+		proxy, err := url.Parse(transport.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		t.Proxy = http.ProxyURL(proxy)
+	}
+	return &http.Client{Transport: t}, nil
+}
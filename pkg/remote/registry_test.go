@@ -0,0 +1,101 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+func TestTransportClient(t *testing.T) {
+	registry := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer registry.Close()
+
+	client, err := transportClient(api.RegistryTransport{})
+	assert.NilError(t, err)
+	_, err = client.Get(registry.URL)
+	assert.ErrorContains(t, err, "certificate")
+
+	ca := filepath.Join(t.TempDir(), "ca.pem")
+	assert.NilError(t, os.WriteFile(ca, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: registry.Certificate().Raw}), 0o600))
+	client, err = transportClient(api.RegistryTransport{CA: ca})
+	assert.NilError(t, err)
+	resp, err := client.Get(registry.URL)
+	assert.NilError(t, err)
+	assert.NilError(t, resp.Body.Close())
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	assert.NilError(t, os.WriteFile(empty, nil, 0o600))
+	_, err = transportClient(api.RegistryTransport{CA: empty})
+	assert.ErrorContains(t, err, "no certificate found in")
+}
+
+func TestTransportClientProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer proxy.Close()
+
+	client, err := transportClient(api.RegistryTransport{Proxy: proxy.URL})
+	assert.NilError(t, err)
+	resp, err := client.Get("http://registry.corp.local/v2/")
+	assert.NilError(t, err)
+	assert.NilError(t, resp.Body.Close())
+	assert.Equal(t, proxied, "http://registry.corp.local/v2/")
+}
+
+func TestRegistryResolverHosts(t *testing.T) {
+	ca := filepath.Join(t.TempDir(), "ca.pem")
+	r := newRegistryResolver(nil, api.RegistryMirrors{"docker.io": {"mirror.corp.local"}}, api.RegistryTransports{
+		"docker.io":           {Proxy: "http://proxy.corp.local:3128"},
+		"registry.corp.local": {CA: ca},
+	}, RegistryAuth{"ghcr.io": {Token: "s3cr3t"}})
+
+	hosts, err := r.hosts("docker.io")
+	assert.NilError(t, err)
+	assert.Equal(t, len(hosts), 2)
+	assert.Equal(t, hosts[0].Host, "mirror.corp.local")
+	_, custom := hosts[0].Client.Transport.(*http.Transport)
+	assert.Assert(t, !custom)
+	assert.Equal(t, hosts[1].Host, "registry-1.docker.io")
+	proxy, err := hosts[1].Client.Transport.(*http.Transport).Proxy(&http.Request{})
+	assert.NilError(t, err)
+	assert.Equal(t, proxy.String(), "http://proxy.corp.local:3128")
+
+	_, err = r.hosts("registry.corp.local")
+	assert.ErrorContains(t, err, "registry registry.corp.local:")
+
+	hosts, err = r.hosts("ghcr.io")
+	assert.NilError(t, err)
+	req := &http.Request{Header: http.Header{}}
+	assert.NilError(t, hosts[0].Authorizer.Authorize(context.TODO(), req))
+	assert.Equal(t, req.Header.Get("Authorization"), "Bearer s3cr3t")
+}
@@ -0,0 +1,99 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+// emptyPayloadHash is the sha256 of the empty body of GET requests, as signed for S3
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// s3Store downloads objects from Amazon S3, or S3 compatible services set by AWS_ENDPOINT_URL_S3 or
+// AWS_ENDPOINT_URL. Credentials and region are resolved by the standard AWS chain: environment, shared configuration
+// and credentials files, web identity, then container and instance metadata
+type s3Store struct {
+	client *http.Client
+}
+
+func (s s3Store) fetch(ctx context.Context, bucket, key, etag string) (object, error) {
+	ref := "s3://" + bucket + "/" + key
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return object{}, fmt.Errorf("loading AWS configuration: %w", err)
+	}
+	region := cfg.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	u, err := s3ObjectURL(cfg, region, bucket, key)
+	if err != nil {
+		return object{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return object{}, err
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	creds, err := cfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		return object{}, fmt.Errorf("retrieving AWS credentials for %s: %w", ref, err)
+	}
+	req.Header.Set("X-Amz-Content-Sha256", emptyPayloadHash)
+	signer := v4.NewSigner(func(o *v4.SignerOptions) {
+		// S3 expects object keys to be escaped once, as sent
+		o.DisableURIPathEscaping = true
+	})
+	if err := signer.SignHTTP(ctx, creds, req, emptyPayloadHash, "s3", region, time.Now()); err != nil {
+		return object{}, err
+	}
+	return fetchObject(s.client, req, ref)
+}
+
+// s3ObjectURL returns the URL of an object, using path-style addressing for custom endpoints and for bucket names
+// which can't be used as a host name
+func s3ObjectURL(cfg aws.Config, region, bucket, key string) (*url.URL, error) {
+	endpoint := os.Getenv("AWS_ENDPOINT_URL_S3")
+	if endpoint == "" && cfg.BaseEndpoint != nil {
+		endpoint = *cfg.BaseEndpoint
+	}
+	if endpoint != "" {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid S3 endpoint %q: %w", endpoint, err)
+		}
+		return u.JoinPath(bucket, key), nil
+	}
+	if strings.Contains(bucket, ".") {
+		return &url.URL{Scheme: "https", Host: fmt.Sprintf("s3.%s.amazonaws.com", region), Path: "/" + bucket + "/" + key}, nil
+	}
+	return &url.URL{Scheme: "https", Host: fmt.Sprintf("%s.s3.%s.amazonaws.com", bucket, region), Path: "/" + key}, nil
+}
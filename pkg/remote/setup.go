@@ -0,0 +1,153 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package remote
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/dotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// SetupFile is the file setup steps of a compose OCI artifact are stored in, next to the compose file
+const SetupFile = "compose-setup.yaml"
+
+// ProvenanceFile is the file the provenance statement of a compose OCI artifact is stored in, next to the compose file
+const ProvenanceFile = "compose-provenance.json"
+
+// Setup describes steps to run before an application published as an OCI artifact can be started
+type Setup struct {
+	// Prompts are variables the user is asked for, stored in the .env file of the project directory
+	Prompts []SetupPrompt `yaml:"prompts,omitempty"`
+	// Directories are created in the project directory
+	Directories []string `yaml:"directories,omitempty"`
+}
+
+// SetupPrompt is a variable to ask user for
+type SetupPrompt struct {
+	Name    string `yaml:"name"`
+	Message string `yaml:"message,omitempty"`
+	Default string `yaml:"default,omitempty"`
+	Secret  bool   `yaml:"secret,omitempty"`
+}
+
+var variableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ParseSetup parses and validates setup steps
+func ParseSetup(content []byte) (*Setup, error) {
+	var setup Setup
+	decoder := yaml.NewDecoder(strings.NewReader(string(content)))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&setup); err != nil {
+		return nil, fmt.Errorf("invalid setup: %w", err)
+	}
+	for _, p := range setup.Prompts {
+		if !variableName.MatchString(p.Name) {
+			return nil, fmt.Errorf("invalid setup: %q is not a valid variable name", p.Name)
+		}
+	}
+	for _, dir := range setup.Directories {
+		if !filepath.IsLocal(dir) {
+			return nil, fmt.Errorf("invalid setup: directory %q must be relative to the project directory", dir)
+		}
+	}
+	return &setup, nil
+}
+
+// LoadSetup reads setup steps pulled with the compose artifact in dir, if any
+func LoadSetup(dir string) (*Setup, error) {
+	content, err := os.ReadFile(filepath.Join(dir, SetupFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ParseSetup(content)
+}
+
+// Run sets variables not defined by environment nor by the .env file in workingDir, from answers or by calling ask,
+// and appends them to the .env file. ask is nil when user can't be prompted, then variables without an answer get
+// their default value. Directories are created in workingDir
+func (s Setup) Run(workingDir string, environment map[string]string, answers map[string]string, ask func(SetupPrompt) (string, error)) error {
+	envFile := filepath.Join(workingDir, ".env")
+	content, err := os.ReadFile(envFile)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	existing, err := dotenv.UnmarshalBytesWithLookup(content, nil)
+	if err != nil {
+		return err
+	}
+
+	var lines []string
+	for _, p := range s.Prompts {
+		if _, ok := environment[p.Name]; ok {
+			continue
+		}
+		if _, ok := existing[p.Name]; ok {
+			continue
+		}
+		value, ok := answers[p.Name]
+		if !ok {
+			switch {
+			case ask != nil:
+				v, err := ask(p)
+				if err != nil {
+					return err
+				}
+				value = v
+			case p.Default != "":
+				value = p.Default
+			default:
+				return fmt.Errorf("no answer for %s, set it with --answers", p.Name)
+			}
+		}
+		lines = append(lines, fmt.Sprintf("%s=%s\n", p.Name, quoteEnvValue(value)))
+	}
+
+	if len(lines) > 0 {
+		if len(content) > 0 && content[len(content)-1] != '\n' {
+			lines = append([]string{"\n"}, lines...)
+		}
+		f, err := os.OpenFile(envFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return err
+		}
+		defer f.Close() //nolint:errcheck
+		if _, err := f.WriteString(strings.Join(lines, "")); err != nil {
+			return err
+		}
+	}
+
+	for _, dir := range s.Directories {
+		if err := os.MkdirAll(filepath.Join(workingDir, dir), 0o755); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// quoteEnvValue double-quotes value so it is read back as-is from an .env file
+func quoteEnvValue(value string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "\n", `\n`)
+	return `"` + r.Replace(value) + `"`
+}
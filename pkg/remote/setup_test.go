@@ -0,0 +1,95 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package remote
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/dotenv"
+	"gotest.tools/v3/assert"
+)
+
+func TestParseSetup(t *testing.T) {
+	setup, err := ParseSetup([]byte(`
+prompts:
+  - name: DB_PASSWORD
+    message: Database password
+    secret: true
+directories:
+  - data/db
+`))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, setup, &Setup{
+		Prompts:     []SetupPrompt{{Name: "DB_PASSWORD", Message: "Database password", Secret: true}},
+		Directories: []string{"data/db"},
+	})
+
+	_, err = ParseSetup([]byte("prompts:\n  - name: 1NVALID\n"))
+	assert.ErrorContains(t, err, `"1NVALID" is not a valid variable name`)
+
+	_, err = ParseSetup([]byte("directories:\n  - ../outside\n"))
+	assert.ErrorContains(t, err, "must be relative to the project directory")
+
+	_, err = ParseSetup([]byte("commands: [rm -rf /]\n"))
+	assert.ErrorContains(t, err, "field commands not found")
+}
+
+func TestSetupRun(t *testing.T) {
+	dir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, ".env"), []byte("EXISTING=1"), 0o600))
+
+	setup := Setup{
+		Prompts: []SetupPrompt{
+			{Name: "EXISTING"},
+			{Name: "FROM_ENV"},
+			{Name: "ANSWERED"},
+			{Name: "ASKED"},
+		},
+		Directories: []string{"data/db"},
+	}
+	var asked []string
+	err := setup.Run(dir, map[string]string{"FROM_ENV": "x"}, map[string]string{"ANSWERED": `a "quoted" $value`},
+		func(p SetupPrompt) (string, error) {
+			asked = append(asked, p.Name)
+			return "multi\nline", nil
+		})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, asked, []string{"ASKED"})
+
+	env, err := dotenv.Read(filepath.Join(dir, ".env"))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, env, map[string]string{
+		"EXISTING": "1",
+		"ANSWERED": `a "quoted" $value`,
+		"ASKED":    "multi\nline",
+	})
+	stat, err := os.Stat(filepath.Join(dir, "data", "db"))
+	assert.NilError(t, err)
+	assert.Assert(t, stat.IsDir())
+
+	// values are not asked again once set
+	err = setup.Run(dir, map[string]string{"FROM_ENV": "x"}, nil, nil)
+	assert.NilError(t, err)
+}
+
+func TestSetupRunWithoutAnswer(t *testing.T) {
+	setup := Setup{Prompts: []SetupPrompt{{Name: "WITH_DEFAULT", Default: "d"}, {Name: "REQUIRED"}}}
+	err := setup.Run(t.TempDir(), nil, nil, nil)
+	assert.ErrorContains(t, err, "no answer for REQUIRED")
+}
@@ -0,0 +1,177 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/containerd/platforms"
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+)
+
+// VariantsFile indexes the variants of a compose OCI artifact by name, next to the compose file
+const VariantsFile = "compose-variants.json"
+
+// variantsDir holds the Compose files of the artifact variants
+const variantsDir = "variants"
+
+// VariantSelector selects the variants of compose OCI artifacts merged on top of their base Compose file
+type VariantSelector struct {
+	// Platform selects the variant published for a platform. Engine platform is used when not set
+	Platform string
+	// Names selects environment variants, merged in order
+	Names []string
+}
+
+var variantNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// ValidateVariant checks name designates either a platform, like linux/arm64, or an environment
+func ValidateVariant(name string) error {
+	if isPlatformVariant(name) {
+		if _, err := platforms.Parse(name); err != nil {
+			return fmt.Errorf("invalid platform variant %q: %w", name, err)
+		}
+		return nil
+	}
+	if !variantNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid variant name %q, must match %s", name, variantNamePattern)
+	}
+	return nil
+}
+
+func isPlatformVariant(name string) bool {
+	return strings.Contains(name, "/")
+}
+
+// writeVariant stores a variant layer, and registers it into the variants index
+func writeVariant(layer v1.Descriptor, local string, content []byte, index map[string]string) error {
+	name := layer.Annotations["com.docker.compose.variant"]
+	if err := ValidateVariant(name); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Join(local, variantsDir), 0o700); err != nil {
+		return err
+	}
+	file := filepath.Join(variantsDir, layer.Digest.Encoded()+".yaml")
+	if err := writeLocalFile(local, file, content); err != nil {
+		return err
+	}
+	index[name] = filepath.ToSlash(file)
+	return nil
+}
+
+// selectVariants returns the Compose file to load from an artifact pulled into local: the base Compose file, or a
+// file merging the selected variants on top of it
+func (g ociRemoteLoader) selectVariants(ctx context.Context, local string) (string, error) {
+	base := composeFile(local)
+	data, err := os.ReadFile(filepath.Join(local, VariantsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return base, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	var index map[string]string
+	if err := json.Unmarshal(data, &index); err != nil {
+		return "", fmt.Errorf("invalid %s: %w", VariantsFile, err)
+	}
+	selected, err := g.selectedVariants(ctx, index)
+	if err != nil || len(selected) == 0 {
+		return base, err
+	}
+	logrus.Debugf("applying variants %s", strings.Join(selected, ", "))
+
+	content, err := os.ReadFile(base)
+	if err != nil {
+		return "", err
+	}
+	for _, name := range selected {
+		variant, err := os.ReadFile(filepath.Join(local, filepath.FromSlash(index[name])))
+		if err != nil {
+			return "", err
+		}
+		content = append(content, "\n---\n"...)
+		content = append(content, variant...)
+	}
+	file := fmt.Sprintf("compose.%s.yaml", digest.FromString(strings.Join(selected, ",")).Encoded()[:12])
+	if err := writeLocalFile(local, file, content); err != nil {
+		return "", err
+	}
+	return filepath.Join(local, file), nil
+}
+
+// selectedVariants lists the variants declared by index to apply: the one matching the target platform first, then
+// the environment variants selected by name
+func (g ociRemoteLoader) selectedVariants(ctx context.Context, index map[string]string) ([]string, error) {
+	var selected, environments []string
+	for name := range index {
+		if !isPlatformVariant(name) {
+			environments = append(environments, name)
+		}
+	}
+	if len(environments) < len(index) {
+		if platform := g.targetPlatform(ctx); platform != "" {
+			target, err := platforms.Parse(platform)
+			if err != nil {
+				return nil, err
+			}
+			for name := range index {
+				if p, err := platforms.Parse(name); err == nil && isPlatformVariant(name) && samePlatform(p, target) {
+					selected = append(selected, name)
+				}
+			}
+		}
+	}
+	if len(environments) == 0 {
+		return selected, nil
+	}
+	for _, name := range g.variants.Names {
+		if !slices.Contains(environments, name) {
+			slices.Sort(environments)
+			return nil, fmt.Errorf("variant %q is not declared by the artifact, available variants: %s", name, strings.Join(environments, ", "))
+		}
+		selected = append(selected, name)
+	}
+	return selected, nil
+}
+
+func samePlatform(a, b v1.Platform) bool {
+	return platforms.FormatAll(platforms.Normalize(a)) == platforms.FormatAll(platforms.Normalize(b))
+}
+
+// targetPlatform is the platform selected to run the application, or the engine platform
+func (g ociRemoteLoader) targetPlatform(ctx context.Context) string {
+	if g.variants.Platform != "" || g.dockerCli == nil {
+		return g.variants.Platform
+	}
+	version, err := g.dockerCli.Client().ServerVersion(ctx)
+	if err != nil {
+		logrus.Debugf("can't get engine platform to select variant: %v", err)
+		return ""
+	}
+	return platforms.Format(v1.Platform{OS: version.Os, Architecture: version.Arch})
+}
@@ -0,0 +1,92 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/cli"
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+)
+
+func TestValidateVariant(t *testing.T) {
+	assert.NilError(t, ValidateVariant("production"))
+	assert.NilError(t, ValidateVariant("linux/arm64"))
+	assert.NilError(t, ValidateVariant("linux/arm/v7"))
+	assert.ErrorContains(t, ValidateVariant("../production"), "invalid platform variant")
+	assert.ErrorContains(t, ValidateVariant(".production"), "invalid variant name")
+	assert.ErrorContains(t, ValidateVariant(""), "invalid variant name")
+}
+
+func TestSelectVariants(t *testing.T) {
+	local := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(local, "compose.yaml"), []byte("services:\n  app:\n    image: app\n"), 0o600))
+	index := map[string]string{}
+	for name, content := range map[string]string{
+		"linux/arm64": "services:\n  app:\n    image: app:arm64\n",
+		"linux/amd64": "services:\n  app:\n    image: app:amd64\n",
+		"production":  "services:\n  app:\n    environment:\n      MODE: production\n",
+		"debug":       "services:\n  app:\n    environment:\n      DEBUG: \"true\"\n",
+	} {
+		layer := v1.Descriptor{
+			Digest:      digest.FromString(content),
+			Annotations: map[string]string{"com.docker.compose.variant": name},
+		}
+		assert.NilError(t, writeVariant(layer, local, []byte(content), index))
+	}
+	load := func(variants VariantSelector) (*cli.ProjectOptions, error) {
+		file, err := ociRemoteLoader{variants: variants}.selectVariants(context.TODO(), local)
+		if err != nil {
+			return nil, err
+		}
+		return cli.NewProjectOptions([]string{file}, cli.WithName("test"))
+	}
+
+	// without variants index, the base Compose file is loaded
+	file, err := ociRemoteLoader{variants: VariantSelector{Platform: "linux/arm64"}}.selectVariants(context.TODO(), local)
+	assert.NilError(t, err)
+	assert.Equal(t, file, filepath.Join(local, "compose.yaml"))
+
+	data, err := json.Marshal(index)
+	assert.NilError(t, err)
+	assert.NilError(t, os.WriteFile(filepath.Join(local, VariantsFile), data, 0o600))
+
+	options, err := load(VariantSelector{Platform: "linux/arm64/v8", Names: []string{"production", "debug"}})
+	assert.NilError(t, err)
+	project, err := options.LoadProject(context.TODO())
+	assert.NilError(t, err)
+	app := project.Services["app"]
+	assert.Equal(t, app.Image, "app:arm64")
+	assert.Equal(t, *app.Environment["MODE"], "production")
+	assert.Equal(t, *app.Environment["DEBUG"], "true")
+
+	options, err = load(VariantSelector{Platform: "windows/amd64"})
+	assert.NilError(t, err)
+	project, err = options.LoadProject(context.TODO())
+	assert.NilError(t, err)
+	assert.Equal(t, project.Services["app"].Image, "app")
+	assert.Equal(t, len(project.Services["app"].Environment), 0)
+
+	_, err = load(VariantSelector{Names: []string{"staging"}})
+	assert.ErrorContains(t, err, `variant "staging" is not declared by the artifact, available variants: debug, production`)
+}
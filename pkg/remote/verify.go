@@ -0,0 +1,122 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package remote
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/distribution/reference"
+	"github.com/sirupsen/logrus"
+)
+
+// VerifyPolicy configures the cosign verification of oci:// compose artifacts before they are loaded
+type VerifyPolicy struct {
+	// Key is the public key (path, URL or KMS reference) signatures must be verified with
+	Key string
+	// Identity, or IdentityRegexp, and Issuer constrain the certificate of keyless signatures. Identity must match
+	// exactly, while IdentityRegexp must match the whole certificate identity
+	Identity       string
+	IdentityRegexp string
+	Issuer         string
+	// Attestation, if set, requires an attestation of this predicate type rather than a plain signature
+	Attestation string
+}
+
+// ParseVerifyPolicy parses a comma-separated list of KEY=VALUE verification settings. Supported keys are `key`,
+// `identity`, `identity-regexp`, `issuer` and `attestation`. An empty value disables verification and returns nil
+func ParseVerifyPolicy(value string) (*VerifyPolicy, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "0" || strings.EqualFold(value, "false") {
+		return nil, nil
+	}
+	policy := &VerifyPolicy{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(entry, "=")
+		if !ok || v == "" {
+			return nil, fmt.Errorf("invalid verify setting %q, expected KEY=VALUE", entry)
+		}
+		switch k {
+		case "key":
+			policy.Key = v
+		case "identity":
+			policy.Identity = v
+		case "identity-regexp":
+			policy.IdentityRegexp = v
+		case "issuer":
+			policy.Issuer = v
+		case "attestation":
+			policy.Attestation = v
+		default:
+			return nil, fmt.Errorf("unsupported verify setting %q", k)
+		}
+	}
+	identity := policy.Identity != "" || policy.IdentityRegexp != ""
+	keyless := identity || policy.Issuer != ""
+	switch {
+	case policy.Key != "" && keyless:
+		return nil, errors.New("verify key and keyless identity are mutually exclusive")
+	case policy.Identity != "" && policy.IdentityRegexp != "":
+		return nil, errors.New("verify identity and identity-regexp are mutually exclusive")
+	case policy.Key == "" && (!identity || policy.Issuer == ""):
+		return nil, errors.New("verify requires either a key, or both an identity and an issuer")
+	}
+	return policy, nil
+}
+
+// args returns the cosign command line verifying ref
+func (p *VerifyPolicy) args(ref reference.Canonical) []string {
+	args := []string{"verify"}
+	if p.Attestation != "" {
+		args = []string{"verify-attestation", "--type", p.Attestation}
+	}
+	switch {
+	case p.Key != "":
+		args = append(args, "--key", p.Key)
+	case p.IdentityRegexp != "":
+		// cosign searches the regexp within the identity, anchor it so it can't match a prefix or suffix only
+		pattern := "^(?:" + strings.TrimSuffix(strings.TrimPrefix(p.IdentityRegexp, "^"), "$") + ")$"
+		args = append(args, "--certificate-identity-regexp", pattern, "--certificate-oidc-issuer", p.Issuer)
+	default:
+		args = append(args, "--certificate-identity", p.Identity, "--certificate-oidc-issuer", p.Issuer)
+	}
+	return append(args, ref.String())
+}
+
+// verify checks the artifact referenced by digest is signed according to the policy, using the cosign CLI
+func (p *VerifyPolicy) verify(ctx context.Context, ref reference.Canonical) error {
+	if _, err := exec.LookPath("cosign"); err != nil {
+		return fmt.Errorf("cosign is required to verify %s: %w", ref.String(), err)
+	}
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, "cosign", p.args(ref)...)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	logrus.Debugf("Executing cosign with args: %v", cmd.Args[1:])
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("refusing to load untrusted artifact %s: %s: %w", ref.String(), strings.TrimSpace(out.String()), err)
+	}
+	return nil
+}
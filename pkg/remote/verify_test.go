@@ -0,0 +1,67 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package remote
+
+import (
+	"testing"
+
+	"github.com/distribution/reference"
+	"gotest.tools/v3/assert"
+)
+
+func TestVerifyPolicy(t *testing.T) {
+	policy, err := ParseVerifyPolicy("")
+	assert.NilError(t, err)
+	assert.Assert(t, policy == nil)
+
+	ref, err := reference.ParseDockerRef("registry.corp.local/app@sha256:8d2a0c7c5e4e6bb9b9a0c8f1f2f0e5b4a3d2c1b0a9f8e7d6c5b4a3f2e1d0c9b8")
+	assert.NilError(t, err)
+	canonical := ref.(reference.Canonical)
+
+	policy, err = ParseVerifyPolicy("key=cosign.pub")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, policy.args(canonical), []string{"verify", "--key", "cosign.pub", canonical.String()})
+
+	policy, err = ParseVerifyPolicy("identity=https://github.com/acme/app/.github/workflows/release.yml@refs/heads/main,issuer=https://token.actions.githubusercontent.com")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, policy.args(canonical), []string{
+		"verify",
+		"--certificate-identity", "https://github.com/acme/app/.github/workflows/release.yml@refs/heads/main",
+		"--certificate-oidc-issuer", "https://token.actions.githubusercontent.com",
+		canonical.String(),
+	})
+
+	// the regexp must match the whole identity
+	policy, err = ParseVerifyPolicy("identity-regexp=^https://github.com/acme/.*,issuer=https://token.actions.githubusercontent.com,attestation=slsaprovenance")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, policy.args(canonical), []string{
+		"verify-attestation", "--type", "slsaprovenance",
+		"--certificate-identity-regexp", "^(?:https://github.com/acme/.*)$",
+		"--certificate-oidc-issuer", "https://token.actions.githubusercontent.com",
+		canonical.String(),
+	})
+
+	_, err = ParseVerifyPolicy("identity=me@acme.com,identity-regexp=.*@acme.com,issuer=https://accounts.google.com")
+	assert.ErrorContains(t, err, "mutually exclusive")
+
+	_, err = ParseVerifyPolicy("identity=me@acme.com")
+	assert.ErrorContains(t, err, "both an identity and an issuer")
+	_, err = ParseVerifyPolicy("key=cosign.pub,issuer=https://accounts.google.com")
+	assert.ErrorContains(t, err, "mutually exclusive")
+	_, err = ParseVerifyPolicy("cosign.pub")
+	assert.ErrorContains(t, err, "expected KEY=VALUE")
+}
@@ -0,0 +1,225 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package watch
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Backend selects the mechanism used to detect file changes
+type Backend string
+
+const (
+	// BackendAuto uses the native backend and falls back to polling when it hits OS limits
+	BackendAuto Backend = "auto"
+	// BackendNative relies on OS notifications (inotify, FSEvents, ReadDirectoryChangesW)
+	BackendNative Backend = "native"
+	// BackendPolling periodically scans watched paths for changes
+	BackendPolling Backend = "polling"
+)
+
+// BackendEnvVar sets the default backend for paths which don't declare one
+const BackendEnvVar = "COMPOSE_WATCH_BACKEND"
+
+// ParseBackend validates a backend name. `inotify` and `fsevents` are accepted as aliases for the native backend on
+// the platform which supports them.
+func ParseBackend(s string) (Backend, error) {
+	switch strings.ToLower(s) {
+	case "", string(BackendAuto):
+		return BackendAuto, nil
+	case string(BackendNative):
+		return BackendNative, nil
+	case string(BackendPolling), "poll":
+		return BackendPolling, nil
+	case "inotify":
+		if runtime.GOOS != "linux" {
+			return "", fmt.Errorf("watch backend %q is only available on Linux, use %q or %q", s, BackendNative, BackendPolling)
+		}
+		return BackendNative, nil
+	case "fsevents":
+		if runtime.GOOS != "darwin" {
+			return "", fmt.Errorf("watch backend %q is only available on macOS, use %q or %q", s, BackendNative, BackendPolling)
+		}
+		return BackendNative, nil
+	default:
+		return "", fmt.Errorf("unsupported watch backend %q, must be one of auto, native, inotify, fsevents or polling", s)
+	}
+}
+
+// DefaultBackend returns the backend set by COMPOSE_WATCH_BACKEND, or auto
+func DefaultBackend() (Backend, error) {
+	b, err := ParseBackend(os.Getenv(BackendEnvVar))
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %w", BackendEnvVar, err)
+	}
+	return b, nil
+}
+
+// LimitError reports the native backend ran out of an OS resource, typically inotify watches or instances on Linux
+type LimitError struct {
+	// Sysctl is the kernel setting controlling the exhausted resource
+	Sysctl string
+	// Suggested is a reasonable value to raise the limit to
+	Suggested int
+	Err       error
+}
+
+func (e LimitError) Error() string {
+	return fmt.Sprintf("hit OS limits watching files: %v.\n"+
+		"Run 'sysctl %s' to check your inotify limits.\n"+
+		"To raise them, run 'sudo sysctl %s=%d', or set %s=polling", e.Err, e.Sysctl, e.Sysctl, e.Suggested, BackendEnvVar)
+}
+
+func (e LimitError) Unwrap() error {
+	return e.Err
+}
+
+// NewWatcherWithBackends creates a Notify watching each group of paths with the selected backend
+func NewWatcherWithBackends(paths map[Backend][]string) (Notify, error) {
+	backends := make([]Backend, 0, len(paths))
+	for b := range paths {
+		if _, err := ParseBackend(string(b)); err != nil {
+			return nil, err
+		}
+		backends = append(backends, b)
+	}
+	sort.Slice(backends, func(i, j int) bool {
+		return backends[i] < backends[j]
+	})
+	return &multiNotify{
+		paths:    paths,
+		backends: backends,
+		events:   make(chan FileEvent),
+		errors:   make(chan error),
+		done:     make(chan struct{}),
+	}, nil
+}
+
+// multiNotify runs a watcher per backend and merges their events
+type multiNotify struct {
+	paths    map[Backend][]string
+	backends []Backend
+	watchers []Notify
+	events   chan FileEvent
+	errors   chan error
+	done     chan struct{}
+	once     sync.Once
+}
+
+func (m *multiNotify) Start() error {
+	for _, b := range m.backends {
+		w, err := startWatcher(b, m.paths[b])
+		if err != nil {
+			_ = m.Close()
+			return err
+		}
+		m.watchers = append(m.watchers, w)
+		go m.forward(w)
+	}
+	return nil
+}
+
+func startWatcher(backend Backend, paths []string) (Notify, error) {
+	if backend == BackendPolling {
+		return startPolling(paths)
+	}
+	w, err := newWatcher(paths)
+	if err == nil {
+		err = w.Start()
+		if err != nil {
+			_ = w.Close()
+		}
+	}
+	var limit LimitError
+	if err != nil && backend == BackendAuto && errors.As(err, &limit) {
+		logrus.Warnf("%v\nFalling back to polling for %s", err, strings.Join(paths, ", "))
+		return startPolling(paths)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func startPolling(paths []string) (Notify, error) {
+	w, err := newPollingWatcher(paths, pollInterval)
+	if err != nil {
+		return nil, err
+	}
+	return w, w.Start()
+}
+
+func (m *multiNotify) forward(w Notify) {
+	events, errs := w.Events(), w.Errors()
+	for events != nil || errs != nil {
+		select {
+		case <-m.done:
+			return
+		case e, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			select {
+			case m.events <- e:
+			case <-m.done:
+				return
+			}
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			select {
+			case m.errors <- err:
+			case <-m.done:
+				return
+			}
+		}
+	}
+}
+
+func (m *multiNotify) Close() error {
+	var errs []error
+	m.once.Do(func() {
+		close(m.done)
+		for _, w := range m.watchers {
+			if err := w.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	})
+	return errors.Join(errs...)
+}
+
+func (m *multiNotify) Events() chan FileEvent {
+	return m.events
+}
+
+func (m *multiNotify) Errors() chan error {
+	return m.errors
+}
+
+var _ Notify = &multiNotify{}
@@ -0,0 +1,141 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBackend(t *testing.T) {
+	for _, name := range []string{"", "auto", "AUTO"} {
+		b, err := ParseBackend(name)
+		require.NoError(t, err)
+		assert.Equal(t, BackendAuto, b)
+	}
+	b, err := ParseBackend("polling")
+	require.NoError(t, err)
+	assert.Equal(t, BackendPolling, b)
+
+	_, err = ParseBackend("inotify")
+	if runtime.GOOS == "linux" {
+		require.NoError(t, err)
+	} else {
+		assert.ErrorContains(t, err, "only available on Linux")
+	}
+
+	_, err = ParseBackend("kqueue")
+	assert.ErrorContains(t, err, `unsupported watch backend "kqueue"`)
+
+	t.Setenv(BackendEnvVar, "nope")
+	_, err = DefaultBackend()
+	assert.ErrorContains(t, err, "invalid COMPOSE_WATCH_BACKEND")
+}
+
+func TestLimitError(t *testing.T) {
+	err := LimitError{Sysctl: "fs.inotify.max_user_watches", Suggested: 524288, Err: syscall.ENOSPC}
+	assert.ErrorIs(t, err, syscall.ENOSPC)
+	assert.Contains(t, err.Error(), "sudo sysctl fs.inotify.max_user_watches=524288")
+	assert.Contains(t, err.Error(), "COMPOSE_WATCH_BACKEND=polling")
+}
+
+func TestPollingWatcher(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.txt")
+	require.NoError(t, os.WriteFile(existing, []byte("hello"), 0o644))
+
+	w, err := newPollingWatcher([]string{dir}, 10*time.Millisecond)
+	require.NoError(t, err)
+	require.NoError(t, w.Start())
+	t.Cleanup(func() { _ = w.Close() })
+
+	created := filepath.Join(dir, "sub", "created.txt")
+	require.NoError(t, os.MkdirAll(filepath.Dir(created), 0o755))
+	require.NoError(t, os.WriteFile(created, []byte("world"), 0o644))
+	require.NoError(t, os.Remove(existing))
+
+	seen := map[FileEvent]bool{}
+	timeout := time.After(5 * time.Second)
+	for !seen[FileEvent(created)] || !seen[FileEvent(existing)] {
+		select {
+		case e := <-w.Events():
+			seen[e] = true
+		case <-timeout:
+			t.Fatalf("timeout waiting for events, got %v", seen)
+		}
+	}
+	assert.True(t, seen[FileEvent(filepath.Join(dir, "sub"))])
+}
+
+func TestChangedPaths(t *testing.T) {
+	now := time.Now()
+	before := map[string]fileState{
+		"/a":   {modTime: now, size: 1},
+		"/b":   {modTime: now, size: 1},
+		"/dir": {modTime: now, mode: os.ModeDir},
+	}
+	after := map[string]fileState{
+		"/a":   {modTime: now, size: 1},
+		"/b":   {modTime: now.Add(time.Second), size: 1},
+		"/c":   {modTime: now, size: 1},
+		"/dir": {modTime: now.Add(time.Second), mode: os.ModeDir},
+	}
+	assert.Equal(t, []string{"/b", "/c"}, changedPaths(before, after))
+	assert.Equal(t, []string{"/b", "/c"}, changedPaths(after, before))
+}
+
+func TestWatcherWithBackends(t *testing.T) {
+	native := t.TempDir()
+	polled := t.TempDir()
+
+	w, err := NewWatcherWithBackends(map[Backend][]string{
+		BackendNative:  {native},
+		BackendPolling: {polled},
+	})
+	require.NoError(t, err)
+	require.NoError(t, w.Start())
+	t.Cleanup(func() { _ = w.Close() })
+
+	for _, dir := range []string{native, polled} {
+		f := filepath.Join(dir, "file.txt")
+		require.NoError(t, os.WriteFile(f, []byte("x"), 0o644))
+		timeout := time.After(5 * time.Second)
+	L:
+		for {
+			select {
+			case e := <-w.Events():
+				if e == FileEvent(f) {
+					break L
+				}
+			case err := <-w.Errors():
+				t.Fatal(err)
+			case <-timeout:
+				t.Fatalf("timeout waiting for change on %s", f)
+			}
+		}
+	}
+
+	_, err = NewWatcherWithBackends(map[Backend][]string{"kqueue": {native}})
+	assert.ErrorContains(t, err, "unsupported watch backend")
+}
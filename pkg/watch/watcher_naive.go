@@ -20,12 +20,14 @@
 package watch
 
 import (
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"syscall"
 
 	pathutil "github.com/docker/compose/v2/internal/paths"
 	"github.com/sirupsen/logrus"
@@ -206,7 +208,10 @@ func (d *naiveNotify) loop() { //nolint:gocyclo
 			}
 			if shouldWatch {
 				err := d.add(path)
-				if err != nil && !os.IsNotExist(err) {
+				var limit LimitError
+				if errors.As(err, &limit) {
+					logrus.Warnf("Changes to %s won't be detected: %v", path, err)
+				} else if err != nil && !os.IsNotExist(err) {
 					logrus.Infof("Error watching path %s: %s", e.Name, err)
 				}
 			}
@@ -263,6 +268,10 @@ func (d *naiveNotify) shouldSkipDir(path string) bool {
 func (d *naiveNotify) add(path string) error {
 	err := d.watcher.Add(path)
 	if err != nil {
+		if errors.Is(err, syscall.ENOSPC) && runtime.GOOS == "linux" {
+			// inotify_add_watch reports exhausted watches as "no space left on device"
+			return LimitError{Sysctl: "fs.inotify.max_user_watches", Suggested: 524288, Err: err}
+		}
 		return err
 	}
 	d.numWatches++
@@ -274,9 +283,7 @@ func newWatcher(paths []string) (Notify, error) {
 	fsw, err := fsnotify.NewWatcher()
 	if err != nil {
 		if strings.Contains(err.Error(), "too many open files") && runtime.GOOS == "linux" {
-			return nil, fmt.Errorf("hit OS limits creating a watcher.\n" +
-				"Run 'sysctl fs.inotify.max_user_instances' to check your inotify limits.\n" +
-				"To raise them, run 'sudo sysctl fs.inotify.max_user_instances=1024'")
+			return nil, LimitError{Sysctl: "fs.inotify.max_user_instances", Suggested: 1024, Err: err}
 		}
 		return nil, fmt.Errorf("creating file watcher: %w", err)
 	}
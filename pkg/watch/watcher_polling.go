@@ -0,0 +1,171 @@
+/*
+   Copyright 2020 Docker Compose CLI authors
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package watch
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	pathutil "github.com/docker/compose/v2/internal/paths"
+	"github.com/sirupsen/logrus"
+)
+
+const pollInterval = time.Second
+
+// A file watcher that periodically scans the watched paths and compares file metadata.
+// Slower and more expensive than native notifications, but it doesn't depend on OS limits
+// and works on filesystems which don't emit events (network shares, some VM mounts).
+type pollingNotify struct {
+	paths    []string
+	interval time.Duration
+	files    map[string]fileState
+	events   chan FileEvent
+	errors   chan error
+	stop     chan struct{}
+}
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+	mode    fs.FileMode
+}
+
+func newPollingWatcher(paths []string, interval time.Duration) (*pollingNotify, error) {
+	abs := make([]string, 0, len(paths))
+	for _, path := range paths {
+		path, err := filepath.Abs(path)
+		if err != nil {
+			return nil, fmt.Errorf("newPollingWatcher: %w", err)
+		}
+		abs = append(abs, path)
+	}
+	return &pollingNotify{
+		paths:    pathutil.EncompassingPaths(abs),
+		interval: interval,
+		events:   make(chan FileEvent),
+		errors:   make(chan error),
+		stop:     make(chan struct{}),
+	}, nil
+}
+
+func (p *pollingNotify) Start() error {
+	if len(p.paths) == 0 {
+		return nil
+	}
+	p.files = p.scan()
+	go p.loop()
+	return nil
+}
+
+func (p *pollingNotify) loop() {
+	defer close(p.events)
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			current := p.scan()
+			for _, path := range changedPaths(p.files, current) {
+				select {
+				case p.events <- NewFileEvent(path):
+				case <-p.stop:
+					return
+				}
+			}
+			p.files = current
+		}
+	}
+}
+
+// scan collects metadata for all files and directories under the watched paths
+func (p *pollingNotify) scan() map[string]fileState {
+	files := map[string]fileState{}
+	for _, root := range p.paths {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			if path == root && info.IsDir() {
+				// We don't care when directories change at the root of the watched path
+				return nil
+			}
+			files[path] = fileState{
+				modTime: info.ModTime(),
+				size:    info.Size(),
+				mode:    info.Mode(),
+			}
+			return nil
+		})
+		if err != nil {
+			logrus.Debugf("Error scanning %s: %s", root, err)
+		}
+	}
+	return files
+}
+
+// changedPaths lists paths created, removed or modified between two scans, sorted for stable ordering
+func changedPaths(before, after map[string]fileState) []string {
+	var changed []string
+	for path, state := range after {
+		if prev, ok := before[path]; !ok || prev != state {
+			if ok && state.mode.IsDir() && prev.mode == state.mode {
+				// directory mtime changes are reported through the entries they contain
+				continue
+			}
+			changed = append(changed, path)
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+func (p *pollingNotify) Close() error {
+	select {
+	case <-p.stop:
+	default:
+		close(p.stop)
+	}
+	return nil
+}
+
+func (p *pollingNotify) Events() chan FileEvent {
+	return p.events
+}
+
+func (p *pollingNotify) Errors() chan error {
+	return p.errors
+}
+
+var _ Notify = &pollingNotify{}